@@ -26,6 +26,7 @@ import (
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/core/gen"
+	"github.com/palletone/go-palletone/core/groupsign"
 	"github.com/palletone/go-palletone/dag"
 	"github.com/palletone/go-palletone/dag/dagconfig"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -112,6 +113,11 @@ func initGenesis(ctx *cli.Context) error {
 
 	regulateGenesisTimestamp(ctx, genesis)
 
+	// 解析mediator初始公钥之前，先确定链参数选定的群签名方案已注册
+	if _, err := groupsign.Lookup(genesis.InitialParameters.GroupSignScheme); err != nil {
+		utils.Fatalf("invalid genesis file: %v, registered schemes: %v", err, groupsign.Names())
+	}
+
 	validateGenesis(genesis)
 
 	dbPath := dagconfig.DagConfig.DbPath
@@ -117,7 +117,10 @@ func createExampleMediators(ctx *cli.Context, mcLen int) []*mp.MediatorConf {
 	exampleMediators := make([]*mp.MediatorConf, mcLen)
 	for i := 0; i < mcLen; i++ {
 		account, password, _ := createExampleAccount(ctx)
-		secStr, pubStr := core.CreateInitDKS()
+		secStr, pubStr, err := core.CreateInitDKS(core.DefaultGroupSignScheme)
+		if err != nil {
+			utils.Fatalf("Failed to create initial distributed key share: %v", err)
+		}
 
 		exampleMediators[i] = &mp.MediatorConf{
 			Address:     account,
@@ -215,6 +218,12 @@ func initSysContracts() []core.SysContract {
 	list = append(list, core.SysContract{Address: syscontract.DigitalIdentityContractAddress,
 		Name: "Digital Identity", Active: true})
 	list = append(list, core.SysContract{Address: syscontract.VoteTokenContractAddress, Name: "Vote", Active: true})
+	list = append(list, core.SysContract{Address: syscontract.FoundationContractAddress,
+		Name: "Foundation Committee", Active: true})
+	list = append(list, core.SysContract{Address: syscontract.ScheduledPaymentContractAddress,
+		Name: "Scheduled Payment", Active: true})
+	list = append(list, core.SysContract{Address: syscontract.PolicyContractAddress,
+		Name: "Account Policy", Active: true})
 	list = append(list, core.SysContract{Address: syscontract.TestContractAddress, Name: "Test", Active: true})
 
 	return list
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/docker/pkg/reexec"
 	"github.com/palletone/go-palletone/cmd/console"
 	"github.com/palletone/go-palletone/cmd/utils"
 	"github.com/palletone/go-palletone/common/log"
@@ -164,6 +165,8 @@ func init() {
 		// See accountcmd.go:
 		accountCommand,
 		// walletCommand,
+		// See outchaincmd.go:
+		outchainCommand,
 		// See consolecmd.go:
 		consoleCommand, //js控制台命令
 		attachCommand,
@@ -181,6 +184,8 @@ func init() {
 		nodeInfoCommand,          // 获取本节点信息
 		timestampCommand,         // 获取指定时间的时间戳
 		mediatorCommand,          // mediator 管理
+		verifyProofCommand,       // 离线验证交易的包含证明
+		verifyStateProofCommand,  // 离线验证合约状态的证明
 		//certCommand,              //证书管理
 
 	}
@@ -226,6 +231,10 @@ func main() {
 		3. HandleAction(c.Action, context)
 	*/
 	//welcomePalletOne()
+	//用户合约的进程沙箱通过重新执行gptn进入，见vm/processcontroller
+	if reexec.Init() {
+		return
+	}
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
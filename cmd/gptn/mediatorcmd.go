@@ -106,7 +106,10 @@ List all existing mediator addresses.
 
 // author Albert·Gou
 func createInitDKS(ctx *cli.Context) error {
-	secStr, pubStr := core.CreateInitDKS()
+	secStr, pubStr, err := core.CreateInitDKS(core.DefaultGroupSignScheme)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println("Generate a initial distributed key share:")
 	fmt.Println("{")
@@ -0,0 +1,154 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/palletone/go-palletone/cmd/utils"
+	"github.com/palletone/go-palletone/contracts/outchain"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	outchainCommand = cli.Command{
+		Name:     "outchain",
+		Usage:    "Manage the encrypted keystore of out-of-chain private keys",
+		Category: "ACCOUNT COMMANDS",
+		Description: `
+The private keys held by the jury for cross-chain contracts are stored in
+outchain.keystore under the data directory, encrypted with the dedicated
+password configured as Jury.OutChainPassword.
+Private keys left in the plaintext outchain.toml are migrated into the keystore
+when it is unlocked.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export the out-of-chain private keys",
+				Action:    utils.MigrateFlags(outchainExport),
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    gptn outchain export <file>
+
+Decrypts the keystore with its password and writes all private keys,
+encrypted with a new export password, into <file>.
+The first line of the --password file is the keystore password,
+the second line is the export password.`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import out-of-chain private keys exported by another node",
+				Action:    utils.MigrateFlags(outchainImport),
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    gptn outchain import <file>
+
+Merges the private keys of an exported file into the keystore, existing keys are
+never overwritten.
+The first line of the --password file is the keystore password,
+the second line is the export password of <file>.`,
+			},
+			{
+				Name:   "rotate",
+				Usage:  "Re-encrypt the keystore with a new password",
+				Action: utils.MigrateFlags(outchainRotate),
+				Flags: []cli.Flag{
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    gptn outchain rotate
+
+Re-encrypts the keystore with a new password, Jury.OutChainPassword must be
+changed accordingly.
+The first line of the --password file is the old password,
+the second line is the new password.`,
+			},
+		},
+	}
+)
+
+//链外私钥keystore位于节点的数据目录下
+func setOutchainKeyStorePath(ctx *cli.Context) {
+	stack, cfg := makeConfigNode(ctx, false)
+	outchain.SetKeyStorePath(stack.ResolvePath(cfg.Jury.OutChainKeyStore))
+}
+
+func outchainExport(ctx *cli.Context) error {
+	file := ctx.Args().First()
+	if len(file) == 0 {
+		utils.Fatalf("file must be given as argument")
+	}
+	setOutchainKeyStorePath(ctx)
+	passwords := utils.MakePasswordList(ctx)
+	password := getPassPhrase("Please give the password of the outchain keystore.", false, 0, passwords)
+	exportPassword := getPassPhrase("Please give a password to encrypt the exported keys.", true, 1, passwords)
+
+	data, err := outchain.ExportKeyStore(password, exportPassword)
+	if err != nil {
+		utils.Fatalf("Could not export the outchain keystore: %v", err)
+	}
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		utils.Fatalf("Could not write the exported keys: %v", err)
+	}
+	fmt.Printf("Exported outchain keys to %s\n", file)
+	return nil
+}
+
+func outchainImport(ctx *cli.Context) error {
+	file := ctx.Args().First()
+	if len(file) == 0 {
+		utils.Fatalf("file must be given as argument")
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Could not read the exported keys: %v", err)
+	}
+	setOutchainKeyStorePath(ctx)
+	passwords := utils.MakePasswordList(ctx)
+	password := getPassPhrase("Please give the password of the outchain keystore.", false, 0, passwords)
+	importPassword := getPassPhrase("Please give the password of the exported keys.", false, 1, passwords)
+
+	count, err := outchain.ImportKeyStore(password, data, importPassword)
+	if err != nil {
+		utils.Fatalf("Could not import the outchain keys: %v", err)
+	}
+	fmt.Printf("Imported %d outchain keys\n", count)
+	return nil
+}
+
+func outchainRotate(ctx *cli.Context) error {
+	setOutchainKeyStorePath(ctx)
+	passwords := utils.MakePasswordList(ctx)
+	oldPassword := getPassPhrase("Please give the old password of the outchain keystore.", false, 0, passwords)
+	newPassword := getPassPhrase("Please give a new password. Do not forget this password.", true, 1, passwords)
+
+	if err := outchain.RotateKeyStore(oldPassword, newPassword); err != nil {
+		utils.Fatalf("Could not rotate the outchain keystore: %v", err)
+	}
+	fmt.Println("Outchain keystore is re-encrypted with the new password")
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/palletone/go-palletone/cmd/utils"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/light/spv"
+	"github.com/palletone/go-palletone/ptnjson"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	verifyProofCommand = cli.Command{
+		Name:      "verifyproof",
+		Usage:     "Verify a transaction inclusion proof offline",
+		Action:    utils.MigrateFlags(verifyProof),
+		ArgsUsage: "<checkpoint.json> <headers.json> <proof.json> [transitions.json]",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Description: `
+    gptn verifyproof <checkpoint.json> <headers.json> <proof.json> [transitions.json]
+
+Verifies the unit headers following a trusted checkpoint (author signature,
+mediator schedule) and the Merkle proof of a transaction against the TxRoot
+of one of these units, without connecting to any node.
+The files are the results of ptn.getSpvCheckpoint, ptn.getSpvHeaders and
+ptn.getTxInclusionProof of a full node, the checkpoint must be obtained from
+a trusted node before the unit of the transaction.
+If the headers cross a maintenance unit, the optional transitions file is a
+json array of the results of ptn.getSpvMediatorSetTransition signed with
+mediator.signSpvTransition by enough mediators of the preceding term.`,
+	}
+	verifyStateProofCommand = cli.Command{
+		Name:      "verifystateproof",
+		Usage:     "Verify a contract state proof offline",
+		Action:    utils.MigrateFlags(verifyStateProof),
+		ArgsUsage: "<checkpoint.json> <headers.json> <proof.json> [transitions.json]",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Description: `
+    gptn verifystateproof <checkpoint.json> <headers.json> <proof.json> [transitions.json]
+
+Verifies the unit headers following a trusted checkpoint and the Merkle proof
+of a contract state against the StateRoot of one of these units.
+The proof file is the result of contract.getStateProof of a full node, an
+empty value means the state does not exist, the optional transitions file is
+the same as verifyproof.`,
+	}
+)
+
+func readJsonFile(file string, v interface{}) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Could not read %s: %v", file, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		utils.Fatalf("Invalid json of %s: %v", file, err)
+	}
+}
+
+// 读取可选的换届信息文件
+func readTransitions(ctx *cli.Context) []*spv.MediatorSetTransition {
+	transitions := []*spv.MediatorSetTransition{}
+	if len(ctx.Args()) == 4 {
+		readJsonFile(ctx.Args().Get(3), &transitions)
+	}
+	return transitions
+}
+
+func verifyProof(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 && len(ctx.Args()) != 4 {
+		utils.Fatalf("checkpoint, headers and proof files must be given as arguments")
+	}
+	checkpoint := &spv.Checkpoint{}
+	readJsonFile(ctx.Args().Get(0), checkpoint)
+	headers := []*modules.Header{}
+	readJsonFile(ctx.Args().Get(1), &headers)
+	proof := &spv.TxProof{}
+	readJsonFile(ctx.Args().Get(2), proof)
+
+	tx, err := spv.VerifyTxProof(checkpoint, headers, proof, readTransitions(ctx)...)
+	if err != nil {
+		utils.Fatalf("Verify proof failed: %v", err)
+	}
+	var timestamp int64
+	for _, h := range headers {
+		if h.Hash() == proof.UnitHash {
+			timestamp = h.Time
+		}
+	}
+	txJson := ptnjson.ConvertTxWithUnitInfo2FullJson(&modules.TransactionWithUnitInfo{
+		Transaction: tx,
+		UnitHash:    proof.UnitHash,
+		UnitIndex:   proof.UnitIndex,
+		Timestamp:   uint64(timestamp),
+		TxIndex:     proof.TxIndex,
+	}, nil)
+	data, _ := json.MarshalIndent(txJson, "", "  ")
+	fmt.Println(string(data))
+	fmt.Println("Proof verified")
+	return nil
+}
+
+func verifyStateProof(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 && len(ctx.Args()) != 4 {
+		utils.Fatalf("checkpoint, headers and proof files must be given as arguments")
+	}
+	checkpoint := &spv.Checkpoint{}
+	readJsonFile(ctx.Args().Get(0), checkpoint)
+	headers := []*modules.Header{}
+	readJsonFile(ctx.Args().Get(1), &headers)
+	proof := &spv.ContractStateProof{}
+	readJsonFile(ctx.Args().Get(2), proof)
+
+	state, err := spv.VerifyContractStateProof(checkpoint, headers, proof, readTransitions(ctx)...)
+	if err != nil {
+		utils.Fatalf("Verify proof failed: %v", err)
+	}
+	if state == nil {
+		fmt.Printf("State %s of contract %x does not exist\n", proof.Key, []byte(proof.ContractId))
+	} else {
+		fmt.Printf("Value: %s\nVersion: %s\n", string(state.Value), state.Version.String())
+	}
+	fmt.Println("Proof verified")
+	return nil
+}
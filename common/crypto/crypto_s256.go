@@ -21,6 +21,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"fmt"
@@ -126,6 +127,20 @@ func (c *CryptoS256) Verify(pubKey, signature, message []byte) (valid bool, err
 	}
 	return sig.Verify(digest, key), nil
 }
+
+// 签名是low-S的严格DER编码且公钥是压缩格式时返回true。
+// Verify也接受R||S格式的签名和未压缩的公钥，需要比较签名字节的场合（如作恶证据）应先检查编码唯一
+func IsCanonicalSignature(pubKey, signature []byte) bool {
+	key, err := btcec.ParsePubKey(pubKey, btcec.S256())
+	if err != nil || !bytes.Equal(key.SerializeCompressed(), pubKey) {
+		return false
+	}
+	sig, err := btcec.ParseDERSignature(signature, btcec.S256())
+	if err != nil || sig.S.Cmp(secp256k1_halfN) > 0 {
+		return false
+	}
+	return bytes.Equal(sig.Serialize(), signature)
+}
 func (c *CryptoS256) Encrypt(key []byte, plaintext []byte) (ciphertext []byte, err error) {
 	return nil, errors.New("Not implement")
 }
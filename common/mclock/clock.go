@@ -0,0 +1,46 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package mclock
+
+import "time"
+
+// Clock is the source of wall time, timers and asynchronous calls used by the consensus code.
+// Nodes use System, the simulation replaces it with a virtual clock that runs every call in order.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f after the duration
+	AfterFunc(d time.Duration, f func())
+	// Go calls f asynchronously
+	Go(f func())
+}
+
+// System is the Clock of the real time.
+type System struct{}
+
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+func (System) AfterFunc(d time.Duration, f func()) {
+	time.AfterFunc(d, f)
+}
+
+func (System) Go(f func()) {
+	go f()
+}
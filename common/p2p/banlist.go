@@ -0,0 +1,291 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package p2p
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/p2p/discover"
+)
+
+//Misbehavior 节点的不当行为，每种行为扣不同的分数，分数随时间衰减，
+//超过banThreshold后节点被禁止连接autoBanDuration
+type Misbehavior int
+
+const (
+	MisbehaviorProtocolError Misbehavior = iota // 消息解码失败或者协议错误
+	MisbehaviorInvalidUnit                      // 验证失败的单元
+	MisbehaviorInvalidHeader                    // 验证失败的单元头
+	MisbehaviorBadGroupSig                      // 验证失败的群签名
+	MisbehaviorUnrequested                      // 没有请求过的数据
+	MisbehaviorTimeout                          // 请求超时
+)
+
+var misbehaviorPenalties = map[Misbehavior]float64{
+	MisbehaviorProtocolError: 20,
+	MisbehaviorInvalidUnit:   50,
+	MisbehaviorInvalidHeader: 25,
+	MisbehaviorBadGroupSig:   50,
+	MisbehaviorUnrequested:   10,
+	MisbehaviorTimeout:       5,
+}
+
+var misbehaviorNames = map[Misbehavior]string{
+	MisbehaviorProtocolError: "protocol error",
+	MisbehaviorInvalidUnit:   "invalid unit",
+	MisbehaviorInvalidHeader: "invalid header",
+	MisbehaviorBadGroupSig:   "bad group signature",
+	MisbehaviorUnrequested:   "unrequested data",
+	MisbehaviorTimeout:       "timeout",
+}
+
+func (m Misbehavior) String() string {
+	if name, ok := misbehaviorNames[m]; ok {
+		return name
+	}
+	return "unknown misbehavior"
+}
+
+const (
+	banThreshold    = 100              // 分数达到后自动禁止
+	scoreHalfLife   = 10 * time.Minute // 分数减半的时间
+	autoBanDuration = time.Hour        // 自动禁止的时长
+	maxPeerScores   = 4096             // 最多记录的节点分数
+)
+
+//banStore 持久化禁止连接的节点，由discover.Table保存在节点数据库中
+type banStore interface {
+	BanNode(entry *discover.BanEntry) error
+	UnbanNode(id discover.NodeID) error
+	BannedNodes() []*discover.BanEntry
+}
+
+type peerScore struct {
+	score   float64
+	updated time.Time
+}
+
+//按半衰期衰减后的分数
+func (s *peerScore) current(now time.Time) float64 {
+	return s.score * math.Pow(0.5, float64(now.Sub(s.updated))/float64(scoreHalfLife))
+}
+
+//banList 节点分数和禁止连接的节点，按节点ID和IP禁止
+type banList struct {
+	lock   sync.RWMutex
+	scores map[discover.NodeID]*peerScore
+	ids    map[discover.NodeID]*discover.BanEntry
+	ips    map[string]*discover.BanEntry
+	store  banStore
+}
+
+func newBanList() *banList {
+	return &banList{
+		scores: make(map[discover.NodeID]*peerScore),
+		ids:    make(map[discover.NodeID]*discover.BanEntry),
+		ips:    make(map[string]*discover.BanEntry),
+	}
+}
+
+//setStore 从节点数据库中加载禁止的节点，之后的修改都保存到数据库
+func (b *banList) setStore(store banStore) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.store = store
+	for _, entry := range store.BannedNodes() {
+		b.add(entry)
+	}
+}
+
+func (b *banList) add(entry *discover.BanEntry) {
+	b.ids[entry.ID] = entry
+	if len(entry.IP) > 0 {
+		b.ips[entry.IP.String()] = entry
+	}
+}
+
+func (b *banList) ban(entry *discover.BanEntry) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if old, ok := b.ids[entry.ID]; ok && len(old.IP) > 0 {
+		delete(b.ips, old.IP.String())
+	}
+	b.add(entry)
+	delete(b.scores, entry.ID)
+	if b.store != nil {
+		if err := b.store.BanNode(entry); err != nil {
+			log.Warn("Failed to store banned node", "id", entry.ID, "err", err)
+		}
+	}
+}
+
+func (b *banList) unban(id discover.NodeID) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entry, ok := b.ids[id]
+	if !ok {
+		return false
+	}
+	delete(b.ids, id)
+	if len(entry.IP) > 0 {
+		delete(b.ips, entry.IP.String())
+	}
+	if b.store != nil {
+		if err := b.store.UnbanNode(id); err != nil {
+			log.Warn("Failed to delete banned node", "id", id, "err", err)
+		}
+	}
+	return true
+}
+
+//isBanned 节点ID或者IP被禁止，ip可以为nil
+func (b *banList) isBanned(id discover.NodeID, ip net.IP) bool {
+	now := time.Now()
+	b.lock.RLock()
+	entry, ok := b.ids[id]
+	if !ok && len(ip) > 0 {
+		entry, ok = b.ips[ip.String()]
+	}
+	b.lock.RUnlock()
+	if !ok {
+		return false
+	}
+	if entry.Expired(now) {
+		b.unban(entry.ID)
+		return false
+	}
+	return true
+}
+
+func (b *banList) list() []*discover.BanEntry {
+	now := time.Now()
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	entries := make([]*discover.BanEntry, 0, len(b.ids))
+	for _, entry := range b.ids {
+		if !entry.Expired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+//penalize 扣分并返回当前分数
+func (b *banList) penalize(id discover.NodeID, penalty float64) float64 {
+	now := time.Now()
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s, ok := b.scores[id]
+	if !ok {
+		if len(b.scores) >= maxPeerScores {
+			b.pruneScores(now)
+		}
+		s = &peerScore{updated: now}
+		b.scores[id] = s
+	}
+	s.score = s.current(now) + penalty
+	s.updated = now
+	return s.score
+}
+
+//删除已经衰减到很小的分数，仍然太多时全部清空
+func (b *banList) pruneScores(now time.Time) {
+	for id, s := range b.scores {
+		if s.current(now) < 1 {
+			delete(b.scores, id)
+		}
+	}
+	if len(b.scores) >= maxPeerScores {
+		b.scores = make(map[discover.NodeID]*peerScore)
+	}
+}
+
+func (b *banList) score(id discover.NodeID) float64 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if s, ok := b.scores[id]; ok {
+		return s.current(time.Now())
+	}
+	return 0
+}
+
+// Penalize records a misbehavior of the peer. A peer whose score reaches the
+// threshold is disconnected and banned for a while, trusted peers are never
+// banned automatically.
+func (srv *Server) Penalize(p *Peer, m Misbehavior) {
+	score := srv.bans.penalize(p.ID(), misbehaviorPenalties[m])
+	log.Debug("Peer misbehaved", "id", p.ID(), "misbehavior", m, "score", score)
+	if score < banThreshold || p.rw.is(trustedConn) {
+		return
+	}
+	log.Info("Banning misbehaving peer", "id", p.ID(), "addr", p.RemoteAddr(), "misbehavior", m)
+	srv.bans.ban(&discover.BanEntry{
+		ID:      p.ID(),
+		IP:      remoteIP(p.RemoteAddr()),
+		Reason:  m.String(),
+		Expires: uint64(time.Now().Add(autoBanDuration).Unix()),
+	})
+	p.Disconnect(DiscUselessPeer)
+}
+
+// BanPeer bans the node for the duration, zero duration bans it permanently.
+// The node is disconnected if it is connected.
+func (srv *Server) BanPeer(id discover.NodeID, duration time.Duration, reason string) {
+	entry := &discover.BanEntry{ID: id, Reason: reason}
+	if duration > 0 {
+		entry.Expires = uint64(time.Now().Add(duration).Unix())
+	}
+	var connected *Peer
+	for _, p := range srv.Peers() {
+		if p.ID() == id {
+			connected = p
+			entry.IP = remoteIP(p.RemoteAddr())
+		}
+	}
+	srv.bans.ban(entry)
+	if connected != nil {
+		connected.Disconnect(DiscUselessPeer)
+	}
+}
+
+// UnbanPeer lifts the ban of the node, returns false if it is not banned.
+func (srv *Server) UnbanPeer(id discover.NodeID) bool {
+	return srv.bans.unban(id)
+}
+
+// BannedPeers returns the banned nodes.
+func (srv *Server) BannedPeers() []*discover.BanEntry {
+	return srv.bans.list()
+}
+
+// PeerScore returns the current misbehavior score of the node.
+func (srv *Server) PeerScore(id discover.NodeID) float64 {
+	return srv.bans.score(id)
+}
+
+func remoteIP(addr net.Addr) net.IP {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/palletone/go-palletone/common/p2p/discover"
+	"github.com/stretchr/testify/assert"
+)
+
+type memBanStore struct {
+	entries map[discover.NodeID]*discover.BanEntry
+}
+
+func (s *memBanStore) BanNode(entry *discover.BanEntry) error {
+	s.entries[entry.ID] = entry
+	return nil
+}
+
+func (s *memBanStore) UnbanNode(id discover.NodeID) error {
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *memBanStore) BannedNodes() []*discover.BanEntry {
+	var entries []*discover.BanEntry
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestBanList(t *testing.T) {
+	store := &memBanStore{entries: make(map[discover.NodeID]*discover.BanEntry)}
+	id1, id2 := discover.NodeID{1}, discover.NodeID{2}
+	ip := net.IP{10, 0, 0, 1}
+	expired := &discover.BanEntry{ID: discover.NodeID{3}, Expires: uint64(time.Now().Add(-time.Minute).Unix())}
+	store.entries[expired.ID] = expired
+
+	b := newBanList()
+	b.setStore(store)
+	b.ban(&discover.BanEntry{ID: id1, IP: ip, Reason: "test"})
+	assert.True(t, b.isBanned(id1, nil))
+	//同一IP的其他节点也被禁止
+	assert.True(t, b.isBanned(id2, ip))
+	assert.False(t, b.isBanned(id2, net.IP{10, 0, 0, 2}))
+	assert.Equal(t, 1, len(b.list()))
+	assert.NotNil(t, store.entries[id1])
+
+	//过期的禁止自动解除
+	assert.False(t, b.isBanned(expired.ID, nil))
+	assert.Nil(t, store.entries[expired.ID])
+
+	//重新加载后仍然被禁止
+	reloaded := newBanList()
+	reloaded.setStore(store)
+	assert.True(t, reloaded.isBanned(id1, nil))
+
+	assert.True(t, reloaded.unban(id1))
+	assert.False(t, reloaded.unban(id1))
+	assert.False(t, reloaded.isBanned(id2, ip))
+	assert.Equal(t, 0, len(store.entries))
+}
+
+func TestBanListScore(t *testing.T) {
+	b := newBanList()
+	id := discover.NodeID{1}
+	assert.EqualValues(t, 0, b.score(id))
+	assert.EqualValues(t, 20, b.penalize(id, 20))
+	//一个半衰期后分数减半
+	b.scores[id].updated = b.scores[id].updated.Add(-scoreHalfLife)
+	assert.InDelta(t, 10, b.score(id), 0.01)
+	assert.InDelta(t, 60, b.penalize(id, 50), 0.01)
+
+	b.ban(&discover.BanEntry{ID: id})
+	assert.EqualValues(t, 0, b.score(id))
+}
+
+func TestServerPenalize(t *testing.T) {
+	srv := &Server{bans: newBanList()}
+	c := &conn{fd: &fakeAddrConn{remote: &net.TCPAddr{IP: net.IP{10, 0, 0, 1}}}, id: discover.NodeID{1}}
+	p := newPeer(c, nil)
+	close(p.closed)
+	p.penalize = srv.Penalize
+
+	p.Penalize(MisbehaviorInvalidUnit)
+	assert.Equal(t, 0, len(srv.BannedPeers()))
+	p.Penalize(MisbehaviorInvalidUnit)
+	p.Penalize(MisbehaviorTimeout)
+	banned := srv.BannedPeers()
+	assert.Equal(t, 1, len(banned))
+	assert.Equal(t, c.id, banned[0].ID)
+	assert.True(t, srv.bans.isBanned(discover.NodeID{2}, net.IP{10, 0, 0, 1}))
+
+	//可信节点不会被自动禁止
+	assert.True(t, srv.UnbanPeer(c.id))
+	c.flags |= trustedConn
+	for i := 0; i < 5; i++ {
+		p.Penalize(MisbehaviorBadGroupSig)
+	}
+	assert.Equal(t, 0, len(srv.BannedPeers()))
+}
+
+type fakeAddrConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *fakeAddrConn) RemoteAddr() net.Addr { return c.remote }
@@ -23,6 +23,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/binary"
+	"net"
 	"os"
 	"sync"
 	"time"
@@ -56,6 +57,7 @@ type nodeDB struct {
 var (
 	nodeDBVersionKey = []byte("version") // Version of the database to flush if changes
 	nodeDBItemPrefix = []byte("n:")      // Identifier to prefix node entries with
+	nodeDBBanPrefix  = []byte("b:")      // Identifier to prefix banned node entries with
 
 	nodeDBDiscoverRoot      = ":discover"
 	nodeDBDiscoverPing      = nodeDBDiscoverRoot + ":lastping"
@@ -210,6 +212,62 @@ func (db *nodeDB) deleteNode(id NodeID) error {
 	return nil
 }
 
+// BanEntry is a node which is not allowed to connect until Expires.
+type BanEntry struct {
+	ID      NodeID
+	IP      net.IP
+	Reason  string
+	Expires uint64 // Unix time, zero for a permanent ban
+}
+
+// Expired reports whether the ban has ended.
+func (e *BanEntry) Expired(now time.Time) bool {
+	return e.Expires != 0 && uint64(now.Unix()) >= e.Expires
+}
+
+// banNode stores a banned node. Banned nodes use their own prefix so that they
+// are kept when the node expirer drops unseen nodes.
+func (db *nodeDB) banNode(entry *BanEntry) error {
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(banKey(entry.ID), blob, nil)
+}
+
+// unbanNode deletes a banned node.
+func (db *nodeDB) unbanNode(id NodeID) error {
+	return db.lvl.Delete(banKey(id), nil)
+}
+
+func banKey(id NodeID) []byte {
+	key := make([]byte, 0, len(nodeDBBanPrefix)+len(id))
+	return append(append(key, nodeDBBanPrefix...), id[:]...)
+}
+
+// bannedNodes retrieves all banned nodes, expired entries are deleted.
+func (db *nodeDB) bannedNodes() []*BanEntry {
+	var (
+		now     = time.Now()
+		entries []*BanEntry
+	)
+	it := db.lvl.NewIterator(util.BytesPrefix(nodeDBBanPrefix), nil)
+	defer it.Release()
+	for it.Next() {
+		entry := new(BanEntry)
+		if err := rlp.DecodeBytes(it.Value(), entry); err != nil {
+			log.Error("Failed to decode banned node RLP", "err", err)
+			continue
+		}
+		if entry.Expired(now) {
+			db.lvl.Delete(it.Key(), nil)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 // ensureExpirer is a small helper method ensuring that the data expiration
 // mechanism is running. If the expiration goroutine is already running, this
 // method simply returns.
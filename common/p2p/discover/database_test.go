@@ -379,3 +379,36 @@ func TestNodeDBSelfExpiration(t *testing.T) {
 		t.Errorf("self not evacuated")
 	}
 }
+
+func TestNodeDBBannedNodes(t *testing.T) {
+	db, _ := newNodeDB("", configure.UdpVersion, NodeID{})
+	defer db.close()
+
+	id := MustHexID("0x1dd9d65c4552b5eb43d5ad55a2ee3f56c6cbc1c64a5c8d659f51fcd51bace24351232b8d7821617d2b29b54b81cdefb9b3e9c37d7fd5f63270bcc9e1a6f6a439")
+	now := time.Now()
+	if err := db.banNode(&BanEntry{ID: id, IP: net.IP{127, 0, 0, 1}, Reason: "test"}); err != nil {
+		t.Fatalf("failed to ban node: %v", err)
+	}
+	expired := &BanEntry{ID: NodeID{1}, Expires: uint64(now.Add(-time.Minute).Unix())}
+	if err := db.banNode(expired); err != nil {
+		t.Fatalf("failed to ban node: %v", err)
+	}
+	// Banned nodes must survive the expiration of discovered nodes
+	if err := db.updateNode(&Node{ID: id, IP: net.IP{127, 0, 0, 1}}); err != nil {
+		t.Fatalf("failed to update node: %v", err)
+	}
+	if err := db.expireNodes(); err != nil {
+		t.Fatalf("failed to expire nodes: %v", err)
+	}
+	entries := db.bannedNodes()
+	if len(entries) != 1 || entries[0].ID != id || entries[0].Reason != "test" ||
+		!entries[0].IP.Equal(net.IP{127, 0, 0, 1}) {
+		t.Fatalf("banned nodes mismatch: %v", entries)
+	}
+	if err := db.unbanNode(id); err != nil {
+		t.Fatalf("failed to unban node: %v", err)
+	}
+	if entries := db.bannedNodes(); len(entries) != 0 {
+		t.Fatalf("banned nodes not empty: %v", entries)
+	}
+}
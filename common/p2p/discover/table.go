@@ -219,6 +219,21 @@ func (tab *Table) Close() {
 	}
 }
 
+// BanNode persists a banned node in the node database.
+func (tab *Table) BanNode(entry *BanEntry) error {
+	return tab.db.banNode(entry)
+}
+
+// UnbanNode removes a banned node from the node database.
+func (tab *Table) UnbanNode(id NodeID) error {
+	return tab.db.unbanNode(id)
+}
+
+// BannedNodes returns the banned nodes stored in the node database.
+func (tab *Table) BannedNodes() []*BanEntry {
+	return tab.db.bannedNodes()
+}
+
 // setFallbackNodes sets the initial points of contact. These nodes
 // are used to connect to the network if the table is empty and there
 // are no known nodes in the database.
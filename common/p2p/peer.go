@@ -107,6 +107,9 @@ type Peer struct {
 
 	// events receives message send / receive events if set
 	events *event.Feed
+
+	// penalize records misbehaviors of the peer, set by the server
+	penalize func(*Peer, Misbehavior)
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -163,6 +166,14 @@ func (p *Peer) Inbound() bool {
 	return p.rw.flags&inboundConn != 0
 }
 
+// Penalize reports a misbehavior of the peer to the server, which disconnects
+// and bans the peer if it misbehaves too often.
+func (p *Peer) Penalize(m Misbehavior) {
+	if p.penalize != nil {
+		p.penalize(p, m)
+	}
+}
+
 func newPeer(conn *conn, protocols []Protocol) *Peer {
 	protomap := matchProtocols(protocols, conn.caps, conn)
 	p := &Peer{
@@ -205,6 +205,9 @@ type Server struct {
 
 	// AlienRestrict black list
 	alienRestrict *freecache.Cache //palletcache.ICache
+
+	// misbehavior scores and banned nodes
+	bans *banList
 }
 
 type peerOpFunc func(map[discover.NodeID]*Peer)
@@ -479,6 +482,7 @@ func (srv *Server) Start() (err error) {
 		srv.Dialer = TCPDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
 	srv.quit = make(chan struct{})
+	srv.bans = newBanList()
 	srv.addpeer = make(chan *conn)
 	srv.delpeer = make(chan peerDrop)
 	srv.posthandshake = make(chan *conn)
@@ -542,6 +546,8 @@ func (srv *Server) Start() (err error) {
 			return err
 		}
 		srv.ntab = ntab
+		//禁止的节点保存在节点数据库中，重启后仍然有效
+		srv.bans.setStore(ntab)
 	}
 
 	if srv.DiscoveryV5 {
@@ -738,6 +744,7 @@ running:
 			if err == nil {
 				// The handshakes are done and it passed all checks.
 				p := newPeer(c, srv.Protocols)
+				p.penalize = srv.Penalize
 				// If message events are enabled, pass the peerFeed
 				// to the peer
 				if srv.EnableMsgEvents {
@@ -818,6 +825,8 @@ func (srv *Server) encHandshakeChecks(peers map[discover.NodeID]*Peer, inboundCo
 		return DiscAlreadyConnected
 	case c.id == srv.Self().ID:
 		return DiscSelf
+	case srv.bans.isBanned(c.id, nil):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
@@ -897,6 +906,12 @@ func (srv *Server) listenLoop() {
 					continue
 				}
 			}
+			if srv.bans.isBanned(discover.NodeID{}, tcp.IP) {
+				log.Debug("Rejected conn (banned)", "addr", fd.RemoteAddr())
+				fd.Close()
+				slots <- struct{}{}
+				continue
+			}
 		}
 
 		fd = newMeteredConn(fd, true)
@@ -434,6 +434,12 @@ func NewTableBatch(db Database, prefix string) Batch {
 	return &tableBatch{db.NewBatch(), prefix}
 }
 
+// WrapTableBatch returns a Batch object which prefixes all keys with a given string
+// and writes them into an existing batch.
+func WrapTableBatch(batch Batch, prefix string) Batch {
+	return &tableBatch{batch, prefix}
+}
+
 func (dt *table) NewBatch() Batch {
 	return &tableBatch{dt.db.NewBatch(), dt.prefix}
 }
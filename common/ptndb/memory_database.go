@@ -17,9 +17,10 @@
 package ptndb
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
-	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -49,6 +50,14 @@ type MemIterator struct {
 	idx    int
 }
 
+//按key排序，与leveldb的遍历顺序一致
+func newMemIterator(result []KeyValue) *MemIterator {
+	sort.Slice(result, func(a, b int) bool {
+		return bytes.Compare(result[a].Key, result[b].Key) < 0
+	})
+	return &MemIterator{result: result, idx: -1}
+}
+
 func (i *MemIterator) Next() bool {
 	i.idx++
 	return i.idx < len(i.result)
@@ -81,17 +90,12 @@ func (i *MemIterator) Last() bool {
 	// }
 	// return true
 }
+//与leveldb相同，移动到第一个不小于key的位置
 func (i *MemIterator) Seek(key []byte) bool {
-	if i.idx == -1 {
-		return false
-	}
-	for j := 0; j <= i.idx; j++ {
-		val := i.result[j]
-		if reflect.DeepEqual(key, val.Key) {
-			return true
-		}
-	}
-	return false
+	i.idx = sort.Search(len(i.result), func(j int) bool {
+		return bytes.Compare(i.result[j].Key, key) >= 0
+	})
+	return i.idx < len(i.result)
 }
 func (i *MemIterator) Prev() bool {
 	return i.idx != -1
@@ -122,7 +126,7 @@ func (db *MemDatabase) NewIterator() iterator.Iterator {
 		kv := KeyValue{[]byte(key), db.db[key]}
 		result = append(result, kv)
 	}
-	return &MemIterator{result: result, idx: -1}
+	return newMemIterator(result)
 }
 
 // NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
@@ -134,7 +138,7 @@ func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
 			result = append(result, kv)
 		}
 	}
-	return &MemIterator{result: result, idx: -1}
+	return newMemIterator(result)
 }
 func NewMemDatabase() (*MemDatabase, error) {
 	return &MemDatabase{
@@ -63,3 +63,23 @@ func TestMemDatabase_NewIterator(t *testing.T) {
 	assert.True(t, itCount == 6, "Result count not match")
 
 }
+func TestMemIterator_Seek(t *testing.T) {
+	db, _ := NewMemDatabase()
+	for _, key := range []string{"b", "ab", "c", "a", "abc"} {
+		db.Put([]byte(key), []byte(key))
+	}
+	it := db.NewIterator()
+	keys := []string{}
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	assert.Equal(t, []string{"a", "ab", "abc", "b", "c"}, keys)
+
+	it = db.NewIteratorWithPrefix([]byte("a"))
+	assert.True(t, it.Seek([]byte("aa")))
+	assert.Equal(t, "ab", string(it.Key()))
+	assert.True(t, it.Next())
+	assert.Equal(t, "abc", string(it.Key()))
+	assert.False(t, it.Next())
+	assert.False(t, it.Seek([]byte("b")))
+}
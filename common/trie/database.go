@@ -302,6 +302,35 @@ func (db *Database) Commit(node common.Hash, report bool) error {
 	return nil
 }
 
+// CommitTo writes a particular node and all its children into the given batch
+// without flushing it, so that the caller can persist the trie atomically with
+// other data. The nodes stay in the memory cache.
+func (db *Database) CommitTo(node common.Hash, batch ptndb.Putter) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	for hash, preimage := range db.preimages {
+		if err := batch.Put(db.secureKey(hash[:]), preimage); err != nil {
+			return err
+		}
+	}
+	return db.commitTo(node, batch)
+}
+
+func (db *Database) commitTo(hash common.Hash, batch ptndb.Putter) error {
+	// If the node does not exist, it's a previously committed node
+	node, ok := db.nodes[hash]
+	if !ok {
+		return nil
+	}
+	for child := range node.children {
+		if err := db.commitTo(child, batch); err != nil {
+			return err
+		}
+	}
+	return batch.Put(hash[:], node.blob)
+}
+
 // commit is the private locked version of Commit.
 func (db *Database) commit(hash common.Hash, batch ptndb.Batch) error {
 	// If the node does not exist, it's a previously committed node
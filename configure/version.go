@@ -22,10 +22,10 @@ import (
 )
 
 const (
-	VersionMajor = 1       // Major version component of the current release
-	VersionMinor = 0       // Minor version component of the current release
-	VersionPatch = 3       // Patch version component of the current release
-	VersionMeta  = "alpha" // Version metadata to append to the version string
+	VersionMajor = 1      // Major version component of the current release
+	VersionMinor = 0      // Minor version component of the current release
+	VersionPatch = 3      // Patch version component of the current release
+	VersionMeta  = "beta" // Version metadata to append to the version string
 
 )
 
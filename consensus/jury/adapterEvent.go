@@ -102,7 +102,7 @@ func (p *Processor) processAdapterRequestEvent(msgType uint32,
 
 	//broadcast
 	if firstSave { //first receive, broadcast
-		go p.ptn.AdapterBroadcast(AdapterEvent{AType: AdapterEventType(msgType), Event: reqEvt})
+		p.adapterBroadcast(AdapterEvent{AType: AdapterEventType(msgType), Event: reqEvt})
 	}
 
 	return nil, nil
@@ -149,7 +149,7 @@ func (p *Processor) AdapterFunRequest(reqId common.Hash, contractId common.Addre
 		return nil, errors.New("Event invalid")
 	}
 
-	go p.ptn.AdapterBroadcast(AdapterEvent{AType: AdapterEventType(msgType), Event: reqEvt})
+	p.adapterBroadcast(AdapterEvent{AType: AdapterEventType(msgType), Event: reqEvt})
 
 	//save
 	p.saveSig(msgType, reqEvt)
@@ -83,7 +83,7 @@ func processContractPayout(tx *modules.Transaction, ele *modules.ElectionNode) {
 		return
 	}
 	reqId := tx.RequestHash()
-	if has, payout := tx.HasContractPayoutMsg(); has {
+	if payouts := tx.ContractPayoutMsgs(); len(payouts) > 0 {
 		pubkeys, signs := getSignature(tx)
 		redeem := generateJuryRedeemScript(ele.EleList)
 
@@ -94,8 +94,11 @@ func processContractPayout(tx *modules.Transaction, ele *modules.ElectionNode) {
 			return fmt.Sprintf("[%s]processContractPayout, Move sign payload to contract payout unlock script:%s",
 				shortId(reqId.String()), unlockStr)
 		})
-		for _, input := range payout.Inputs {
-			input.SignatureScript = unlock
+		//合约调用合约时被调用合约的付款也由同一个陪审团签名
+		for _, payout := range payouts {
+			for _, input := range payout.Inputs {
+				input.SignatureScript = unlock
+			}
 		}
 	}
 	//remove signature payload
@@ -298,11 +301,12 @@ func runContractCmd(rwM rwset.TxManager, dag iDag, contract *contracts.Contract,
 				msgs := []*modules.Message{}
 				reqPay := msg.Payload.(*modules.ContractInvokeRequestPayload)
 				req := ContractInvokeReq{
-					chainID:  "palletone",
-					deployId: reqPay.ContractId,
-					args:     reqPay.Args,
-					txid:     tx.RequestHash().String(),
-					timeout:  time.Duration(reqPay.Timeout) * time.Second,
+					chainID:   "palletone",
+					deployId:  reqPay.ContractId,
+					args:      reqPay.Args,
+					txid:      tx.RequestHash().String(),
+					timeout:   time.Duration(reqPay.Timeout) * time.Second,
+					costLimit: reqPay.CostLimit,
 				}
 
 				fullArgs, err := handleMsg0(tx, dag, req.args)
@@ -323,9 +327,13 @@ func runContractCmd(rwM rwset.TxManager, dag iDag, contract *contracts.Contract,
 				payload := modules.NewContractInvokePayload(result.ContractId, result.ReadSet, result.WriteSet,
 					result.Payload, modules.ContractError{})
 				if payload != nil {
+					payload.RangeReadSet = result.RangeReadSet
+					payload.Cost = result.Cost
 					msgs = append(msgs, modules.NewMessage(modules.APP_CONTRACT_INVOKE, payload))
 				}
-				toContractPayments, err := resultToContractPayments(dag, result)
+				//Payment在最终交易中排在请求消息和已生成的结果消息之后
+				toContractPayments, err := resultToContractPayments(dag, result,
+					uint32(len(tx.TxMessages)+len(msgs)))
 				if err != nil {
 					return genContractErrorMsg(dag, tx, reqPay.ContractId, err, errMsgEnable)
 				}
@@ -697,35 +705,6 @@ func conversionElectionSeedData(in []byte) []byte {
 	return in
 }
 
-/*
-Preliminary test conclusion
-expectNum:4
-use:
-total    weight    num
-20         4        5
-50         7        7
-100        8        5
-200        15       5
-500        17       6
-1000       18       5
-*/
-func electionWeightValue(total uint64) (val uint64) {
-	if total <= 20 {
-		return 4
-	} else if total > 20 && total <= 50 {
-		return 7
-	} else if total > 50 && total <= 100 {
-		return 8
-	} else if total > 100 && total <= 200 {
-		return 15
-	} else if total > 200 && total <= 500 {
-		return 17
-	} else if total > 500 {
-		return 20
-	}
-	return 4
-}
-
 func shortId(id string) string {
 	if len(id) < 8 {
 		return id
@@ -802,6 +781,7 @@ func checkContractTxFeeValid(dag iDag, tx *modules.Transaction) bool {
 		return false
 	}
 	var timeout uint32
+	var costLimit uint64
 	reqId := tx.RequestHash()
 	txSize := tx.Size().Float64()
 	fees, err := dag.GetTxFee(tx)
@@ -823,7 +803,12 @@ func checkContractTxFeeValid(dag iDag, tx *modules.Transaction) bool {
 			log.Errorf("[%s]checkContractTxFeeValid, getContractTxContractInfo fail", shortId(reqId.String()))
 			return false
 		}
-		timeout = payload.(*modules.ContractInvokeRequestPayload).Timeout
+		invoke := payload.(*modules.ContractInvokeRequestPayload)
+		timeout = invoke.Timeout
+		//与验证时相同，用户合约未指定上限时按默认上限收费
+		if costLimit = invoke.CostLimit; costLimit == 0 && !common.IsSystemContractAddress(invoke.ContractId) {
+			costLimit = dag.GetChainParameters().ContractCostLimit
+		}
 	case modules.APP_CONTRACT_STOP_REQUEST: //todo
 	}
 	timeFee, sizeFee := getContractTxNeedFee(dag, txType, float64(timeout), txSize)
@@ -832,10 +817,16 @@ func checkContractTxFeeValid(dag iDag, tx *modules.Transaction) bool {
 	//	log.Errorf("[%s]checkContractTxFeeValid, getContractTxNeedFee fail", shortId(reqId.String()))
 	//	return false
 	//}
-	val := math.Max(float64(fees.Amount), timeFee+sizeFee) == float64(fees.Amount)
+	needFee := timeFee + sizeFee
+	//指定了cost上限的请求，手续费需要足够支付上限的cost
+	if costFee := float64(costLimit * dag.GetChainParameters().ContractCostUnitFee); costFee > needFee {
+		needFee = costFee
+	}
+	val := math.Max(float64(fees.Amount), needFee) == float64(fees.Amount)
 	if !val {
-		log.Errorf("[%s]checkContractTxFeeValid invalid, fee amount[%f]-fees[%f](%f + %f), txSize[%f], timeout[%d]",
-			shortId(reqId.String()), float64(fees.Amount), timeFee+sizeFee, timeFee, sizeFee, txSize, timeout)
+		log.Errorf("[%s]checkContractTxFeeValid invalid, fee amount[%f]-fees[%f](%f + %f), txSize[%f], timeout[%d], "+
+			"costLimit[%d]", shortId(reqId.String()), float64(fees.Amount), needFee, timeFee, sizeFee, txSize, timeout,
+			costLimit)
 	}
 	return val
 }
@@ -59,6 +59,9 @@ type Config struct {
 	//ContractSigNum int   //user contract jury sig number  //todo  no used
 	//ElectionNum    int   //vrf election jury number       //todo  no used
 	Accounts []*AccountConf // the set of the mediator info
+
+	OutChainKeyStore string // 链外私钥keystore文件，相对路径位于数据目录下
+	OutChainPassword string // 链外私钥keystore的专用密码，与jury账户密码无关，配置了jury账户时必须设置
 }
 
 func (aConf *AccountConf) configToAccount() *JuryAccount {
@@ -77,6 +80,7 @@ var DefaultConfig = Config{
 	Accounts: []*AccountConf{
 		&AccountConf{},
 	},
+	OutChainKeyStore: "outchain.keystore",
 }
 
 func MakeConfig() Config {
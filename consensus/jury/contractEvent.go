@@ -20,7 +20,6 @@ package jury
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/palletone/go-palletone/common/event"
 	"github.com/palletone/go-palletone/common/log"
@@ -71,7 +70,7 @@ func (p *Processor) ProcessContractEvent(event *ContractEvent) error {
 		return p.contractEleEvent(event.Tx)
 	}
 	if broadcast {
-		go p.ptn.ContractBroadcast(*event, false)
+		p.contractBroadcast(*event, false)
 	}
 	return err
 }
@@ -87,7 +86,7 @@ func (p *Processor) contractEleEvent(tx *modules.Transaction) error {
 			reqTx:  tx.GetRequestTx(),
 			rstTx:  nil,
 			valid:  true,
-			tm:     time.Now(),
+			tm:     p.clock.Now(),
 			adaInf: make(map[uint32]*AdapterInf),
 		}
 	}
@@ -113,7 +112,7 @@ func (p *Processor) contractEleEvent(tx *modules.Transaction) error {
 		p.mel[reqId] = &electionVrf{
 			rcvEle: make([]modules.ElectionInf, 0),
 			sigs:   make([]modules.SignatureSet, 0),
-			tm:     time.Now(),
+			tm:     p.clock.Now(),
 		}
 	}
 	if eelsLen < p.electionNum {
@@ -121,7 +120,7 @@ func (p *Processor) contractEleEvent(tx *modules.Transaction) error {
 			ReqId:     reqId,
 			JuryCount: juryCount,
 		}
-		go p.ptn.ElectionBroadcast(ElectionEvent{EType: ELECTION_EVENT_VRF_REQUEST, Event: reqEvent}, true) //todo true
+		p.electionBroadcast(ElectionEvent{EType: ELECTION_EVENT_VRF_REQUEST, Event: reqEvent}, true) //todo true
 	}
 	return nil
 }
@@ -135,7 +134,7 @@ func (p *Processor) contractExecEvent(tx *modules.Transaction, ele *modules.Elec
 	if p.mtx[reqId] == nil {
 		p.mtx[reqId] = &contractTx{
 			rstTx:  nil,
-			tm:     time.Now(),
+			tm:     p.clock.Now(),
 			valid:  true,
 			adaInf: make(map[uint32]*AdapterInf),
 		}
@@ -156,7 +155,7 @@ func (p *Processor) contractExecEvent(tx *modules.Transaction, ele *modules.Elec
 	log.Debugf("[%s]contractExecEvent, add tx reqId:%s", shortId(reqId.String()), reqId.String())
 
 	if !tx.IsSystemContract() { //系统合约在UNIT构建前执行
-		go p.runContractReq(reqId, ele)
+		p.clock.Go(func() { p.runContractReq(reqId, ele) })
 	}
 	return true, nil
 }
@@ -179,7 +178,7 @@ func (p *Processor) contractSigEvent(tx *modules.Transaction, ele *modules.Elect
 		p.mtx[reqId] = &contractTx{
 			reqTx:   tx.GetRequestTx(),
 			eleNode: ele,
-			tm:      time.Now(),
+			tm:      p.clock.Now(),
 			valid:   true,
 			adaInf:  make(map[uint32]*AdapterInf),
 		}
@@ -196,10 +195,11 @@ func (p *Processor) contractSigEvent(tx *modules.Transaction, ele *modules.Elect
 				//签名数量足够，而且当前节点是签名最新的节点，那么合并签名并广播完整交易
 				log.Infof("[%s]runContractReq, localIsMinSignature Ok!", shortId(reqId.String()))
 				processContractPayout(ctx.sigTx, ele)
-				go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_COMMIT, Ele: ele, Tx: ctx.sigTx}, true)
+				p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_COMMIT, Ele: ele, Tx: ctx.sigTx}, true)
 			}
 		}
 	} else if err != nil {
+		ctx.divTx = append(ctx.divTx, tx)
 		return true, err
 	}
 	return true, nil
@@ -216,7 +216,7 @@ func (p *Processor) contractCommitEvent(tx *modules.Transaction) (broadcast bool
 		//log.Debug("contractCommitEvent", "local not find reqId,create it", reqId)
 		p.mtx[reqId] = &contractTx{
 			reqTx:  tx.GetRequestTx(),
-			tm:     time.Now(),
+			tm:     p.clock.Now(),
 			valid:  true,
 			adaInf: make(map[uint32]*AdapterInf),
 		}
@@ -66,15 +66,16 @@ func (req ContractDeployReq) do(rwM rwset.TxManager, v contracts.ContractInf) (i
 }
 
 type ContractInvokeReq struct {
-	chainID  string
-	deployId []byte
-	txid     string //common.Hash
-	args     [][]byte
-	timeout  time.Duration
+	chainID   string
+	deployId  []byte
+	txid      string //common.Hash
+	args      [][]byte
+	timeout   time.Duration
+	costLimit uint64
 }
 
 func (req ContractInvokeReq) do(rwM rwset.TxManager, v contracts.ContractInf) (interface{}, error) {
-	return v.Invoke(rwM, req.chainID, req.deployId, req.txid, req.args, req.timeout)
+	return v.Invoke(rwM, req.chainID, req.deployId, req.txid, req.args, req.timeout, req.costLimit)
 }
 
 type ContractStopReq struct {
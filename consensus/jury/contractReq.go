@@ -27,6 +27,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/common/util"
+	"github.com/palletone/go-palletone/contracts/syscontract"
 
 	"encoding/json"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -104,7 +105,7 @@ func (p *Processor) ContractInstallReq(from, to common.Address, daoAmount, daoFe
 		templateId := tpl.(*modules.ContractTplPayload).TemplateId
 		log.Infof("[%s]ContractInstallReq ok, reqId[%s] templateId[%x]", shortId(reqId.String()), reqId.String(), templateId)
 		//broadcast
-		go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_COMMIT, Tx: tx}, false)
+		p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_COMMIT, Tx: tx}, false)
 		return reqId, templateId, nil
 	}
 	//net mode
@@ -147,7 +148,7 @@ func (p *Processor) ContractDeployReq(from, to common.Address, daoAmount, daoFee
 		shortId(reqId.String()), reqId.String(), templateId, contractId.String())
 
 	//broadcast
-	go p.ptn.ContractBroadcast(ContractEvent{Ele: nil, CType: CONTRACT_EVENT_ELE, Tx: tx}, true)
+	p.contractBroadcast(ContractEvent{Ele: nil, CType: CONTRACT_EVENT_ELE, Tx: tx}, true)
 	return reqId, contractId, err
 }
 
@@ -187,10 +188,39 @@ func (p *Processor) ContractInvokeReq(from, to common.Address, daoAmount, daoFee
 		return fmt.Sprintf("Request data fro debug json:%s,\r\n rlp:%x", string(rjson), rdata)
 	})
 	//broadcast
-	go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
+	p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
 	return reqId, nil
 }
 
+//提交mediator重复出块的证据，交易中同时包含证据消息和保证金合约的没收请求
+func (p *Processor) SlashMediatorReq(from common.Address, daoFee uint64,
+	evidence *modules.MediatorEvidencePayload) (common.Hash, error) {
+	if from == (common.Address{}) || evidence == nil {
+		return common.Hash{}, errors.New("SlashMediatorReq request param is error")
+	}
+	data, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	msgEvidence := modules.NewMessage(modules.APP_MEDIATOR_EVIDENCE, evidence)
+	msgReq := modules.NewMessage(modules.APP_CONTRACT_INVOKE_REQUEST, &modules.ContractInvokeRequestPayload{
+		ContractId: syscontract.DepositContractAddress.Bytes(),
+		Args:       [][]byte{[]byte(modules.SlashMediatorByEvidence), []byte(hex.EncodeToString(data))},
+	})
+	// 证据消息需要放在合约请求之前
+	tx, _, err := p.dag.CreateGenericTransaction(from, from, 0, daoFee, nil, msgEvidence, p.ptn.TxPool())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx.AddMessage(msgReq)
+	tx, err = p.SignAndExecuteAndSendRequest(from, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	log.Infof("[%s]SlashMediatorReq ok, evidence[%s]", shortId(tx.RequestHash().String()), evidence.Hash().String())
+	return tx.RequestHash(), nil
+}
+
 func (p *Processor) ContractInvokeReqToken(from, to, toToken common.Address, daoAmount, daoFee, daoAmountToken uint64,
 	assetToken string, contractId common.Address, args [][]byte, timeout uint32) (common.Hash, error) {
 	if from == (common.Address{}) || to == (common.Address{}) || contractId == (common.Address{}) || args == nil {
@@ -223,7 +253,7 @@ func (p *Processor) ContractInvokeReqToken(from, to, toToken common.Address, dao
 	log.Infof("[%s]ContractInvokeReqToken ok, reqId[%s] contractId[%s]",
 		shortId(reqId.String()), reqId.String(), contractId.Bytes())
 	//broadcast
-	go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
+	p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
 	return reqId, nil
 }
 
@@ -252,14 +282,14 @@ func (p *Processor) ContractStopReq(from, to common.Address, daoAmount, daoFee u
 	log.Infof("[%s]ContractStopReq ok, reqId[%s], contractId[%s], txId[%s]",
 		shortId(reqId.String()), reqId.String(), contractId, hex.EncodeToString(randNum))
 	//broadcast
-	go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
+	p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
 	return reqId, nil
 }
 
 func (p *Processor) ElectionVrfReq(id uint32) ([]byte, error) {
 	reqId := util.RlpHash(id)
 	p.mtx[reqId] = &contractTx{
-		tm:     time.Now(),
+		tm:     p.clock.Now(),
 		valid:  true,
 		adaInf: make(map[uint32]*AdapterInf),
 	}
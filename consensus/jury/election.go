@@ -21,7 +21,6 @@ package jury
 import (
 	"crypto/ecdsa"
 	"fmt"
-	"time"
 	"bytes"
 
 	"github.com/palletone/go-palletone/common"
@@ -48,7 +47,7 @@ type elector struct {
 func newElector(num uint, total uint64, addr common.Address, password string, ks *keystore.KeyStore) *elector {
 	e := &elector{
 		num:      num,
-		weight:   electionWeightValue(total),
+		weight:   alg.ElectionWeight(total),
 		total:    total,
 		addr:     addr,
 		password: password,
@@ -101,6 +100,9 @@ func (e *elector) verifyVrf(proof, data []byte, pubKey []byte) (bool, error) {
 
 func (p *Processor) selectElectionInf(local []modules.ElectionInf,
 	recv []modules.ElectionInf, num int) ([]modules.ElectionInf, bool) {
+	//去掉因信誉过低被排除的陪审员
+	local = filterExcludedJurors(local, p.dag.GetJurorStats)
+	recv = filterExcludedJurors(recv, p.dag.GetJurorStats)
 	if len(local)+len(recv) < num {
 		return nil, false
 	}
@@ -108,9 +110,8 @@ func (p *Processor) selectElectionInf(local []modules.ElectionInf,
 	if len(local) >= num { //use local
 		eels = local[:num]
 	} else {
-		less := num - len(local)
 		eels = append(eels, local...)
-		for i := 0; i < less; i++ {
+		for i := 0; i < len(recv) && len(eels) < num; i++ {
 			ok := true
 			for _, l := range local {
 				if bytes.Equal(l.AddrHash[:], recv[i].AddrHash[:]) {
@@ -237,9 +238,9 @@ func (p *Processor) electionEventBroadcast(event *ElectionEvent) (recved bool, i
 			sigs:    make([]modules.SignatureSet, 0),
 		}
 		p.mel[reqId].vrfReqEd = true
-		p.mel[reqId].tm = time.Now()
+		p.mel[reqId].tm = p.clock.Now()
 	}
-	go p.ptn.ElectionBroadcast(*event, false)
+	p.electionBroadcast(*event, false)
 	return false, p.mel[reqId].invalid, nil
 }
 
@@ -263,6 +264,10 @@ func (p *Processor) checkElectionSigRequestEventValid(evt *ElectionSigRequestEve
 			log.Infof("[%s]checkElectionSigRequestEventValid, index[%d],verifyVrf fail", shortId(reqId.String()), i)
 			return false
 		}
+		if isJurorExcluded(e, p.dag.GetJurorStats) {
+			log.Infof("[%s]checkElectionSigRequestEventValid, index[%d],juror is excluded", shortId(reqId.String()), i)
+			return false
+		}
 	}
 
 	return true
@@ -334,7 +339,7 @@ func (p *Processor) processElectionRequestEvent(reqEvt *ElectionRequestEvent) (e
 			Ele:       modules.ElectionInf{EType: 0, AddrHash: addrHash, Proof: proof, PublicKey: pubKey},
 		}
 		log.Debugf("[%s]processElectionRequestEvent, ok", shortId(reqId.String()))
-		go p.ptn.ElectionBroadcast(ElectionEvent{EType: ELECTION_EVENT_VRF_RESULT, Event: rstEvt}, true)
+		p.electionBroadcast(ElectionEvent{EType: ELECTION_EVENT_VRF_RESULT, Event: rstEvt}, true)
 		return nil
 	}
 	return nil
@@ -430,7 +435,7 @@ func (p *Processor) processElectionSigRequestEvent(evt *ElectionSigRequestEvent)
 		Sig:       modules.SignatureSet{PubKey: pk, Signature: sig},
 	}
 	//广播resultEvt
-	go p.ptn.ElectionBroadcast(ElectionEvent{EType: ELECTION_EVENT_SIG_RESULT, Event: resultEvt}, true)
+	p.electionBroadcast(ElectionEvent{EType: ELECTION_EVENT_SIG_RESULT, Event: resultEvt}, true)
 	return nil
 }
 
@@ -471,6 +476,7 @@ func (p *Processor) processElectionSigResultEvent(evt *ElectionSigResultEvent) e
 	log.Debugf("[%s]processElectionSigResultEvent,sig num=%d, add sig[%s], Threshold=%d",
 		shortId(reqId.String()), len(mel.sigs), evt.Sig.String(), p.dag.ChainThreshold())
 	if len(mel.sigs) >= p.dag.ChainThreshold() {
+		electionTimer.Update(p.clock.Now().Sub(mel.tm))
 		event := ContractEvent{
 			CType: CONTRACT_EVENT_EXEC,
 			Ele:   p.mtx[reqId].eleNode,
@@ -479,7 +485,7 @@ func (p *Processor) processElectionSigResultEvent(evt *ElectionSigResultEvent) e
 		log.Infof("[%s]processElectionSigResultEvent, CONTRACT_EVENT_EXEC", shortId(reqId.String()))
 		log.Info("processElectionSigResultEvent, CONTRACT_EVENT_EXEC", "reqId",
 			shortId(reqId.String()), "event", event)
-		go p.ptn.ContractBroadcast(event, true)
+		p.contractBroadcast(event, true)
 		return nil
 	}
 	return nil
@@ -520,7 +526,7 @@ func (p *Processor) BroadcastElectionSigRequestEvent() {
 			log.Infof("[%s]BroadcastElectionSigRequestEvent ", shortId(reqId.String()))
 			log.Debug("BroadcastElectionSigRequestEvent", "event", event,
 				"len(mtx.eleNode)", len(mtx.eleNode.EleList), "len(ele.rcvEle)", len(ele.rcvEle))
-			go p.ptn.ElectionBroadcast(ElectionEvent{EType: ELECTION_EVENT_SIG_REQUEST, Event: event}, true)
+			p.electionBroadcast(ElectionEvent{EType: ELECTION_EVENT_SIG_REQUEST, Event: event}, true)
 		}
 	}
 }
@@ -31,48 +31,72 @@ import (
 	"github.com/palletone/go-palletone/tokenengine"
 )
 
-//将ContractInvokeResult中合约付款出去的请求转换为UTXO对应的Payment
-func resultToContractPayments(dag iDag, result *modules.ContractInvokeResult) ([]*modules.PaymentPayload, error) {
-	addr := common.NewAddress(result.ContractId, common.ContractHash)
+//将ContractInvokeResult中合约付款出去的请求转换为UTXO对应的Payment，
+//msgIdx是第一个Payment在交易中的消息序号。合约调用合约时，付款可能来自多个合约，
+//合约可以使用同一交易中前面的Payment付给它的Token，这时输入引用交易本身
+func resultToContractPayments(dag iDag, result *modules.ContractInvokeResult,
+	msgIdx uint32) ([]*modules.PaymentPayload, error) {
+	contractAddr := common.NewAddress(result.ContractId, common.ContractHash)
 	payments := []*modules.PaymentPayload{}
-	if result.TokenPayOut != nil && len(result.TokenPayOut) > 0 {
-		payouts := tokenPayOutGroupByAsset(result.TokenPayOut)
-		for ast, aa := range payouts {
-			asset := ast
-			utxos, err := dag.GetAddr1TokenUtxos(addr, &asset)
-			if err != nil {
-				return nil, err
-			}
-			utxo2 := convertMapUtxo(utxos)
-			us := core.Utxos{}
-			for _, u := range utxo2 {
+	//同一交易中付给合约且还没有被花费的输出
+	pending := make(map[payoutKey][]*modules.UtxoWithOutPoint)
+	spent := make(map[modules.OutPoint]bool)
+	for _, group := range tokenPayOutGroup(result.TokenPayOut, contractAddr) {
+		asset := group.Asset
+		utxos, err := dag.GetAddr1TokenUtxos(group.From, &asset)
+		if err != nil {
+			return nil, err
+		}
+		us := core.Utxos{}
+		for _, u := range convertMapUtxo(utxos) {
+			if !spent[u.OutPoint] {
 				us = append(us, u)
 			}
-			totalPayAmt := uint64(0)
-			for _, a := range aa {
-				totalPayAmt += a.Amount
-			}
-			selected, change, err := core.Select_utxo_Greedy(us, totalPayAmt)
-			if err != nil {
-				return nil, err
-			}
-			payment := &modules.PaymentPayload{}
-			for _, s := range selected {
-				sutxo := s.(*modules.UtxoWithOutPoint)
-				in := modules.NewTxIn(&sutxo.OutPoint, nil)
-				payment.AddTxIn(in)
-			}
-			for _, a := range aa {
-				out := modules.NewTxOut(a.Amount, tokenengine.Instance.GenerateLockScript(a.Address), &asset)
-				payment.AddTxOut(out)
+		}
+		key := payoutKey{From: group.From, Asset: asset}
+		for _, u := range pending[key] {
+			us = append(us, u)
+		}
+		totalPayAmt := uint64(0)
+		for _, a := range group.Pays {
+			totalPayAmt += a.Amount
+		}
+		selected, change, err := core.Select_utxo_Greedy(us, totalPayAmt)
+		if err != nil {
+			return nil, err
+		}
+		payment := &modules.PaymentPayload{}
+		for _, s := range selected {
+			sutxo := s.(*modules.UtxoWithOutPoint)
+			in := modules.NewTxIn(&sutxo.OutPoint, nil)
+			payment.AddTxIn(in)
+			spent[sutxo.OutPoint] = true
+		}
+		unspent := []*modules.UtxoWithOutPoint{}
+		for _, u := range pending[key] {
+			if !spent[u.OutPoint] {
+				unspent = append(unspent, u)
 			}
-			//Change
-			if change > 0 {
-				out2 := modules.NewTxOut(change, tokenengine.Instance.GenerateLockScript(addr), &asset)
-				payment.AddTxOut(out2)
+		}
+		pending[key] = unspent
+		for _, a := range group.Pays {
+			out := modules.NewTxOut(a.Amount, tokenengine.Instance.GenerateLockScript(a.Address), &asset)
+			payment.AddTxOut(out)
+		}
+		//Change
+		if change > 0 {
+			out2 := modules.NewTxOut(change, tokenengine.Instance.GenerateLockScript(group.From), &asset)
+			payment.AddTxOut(out2)
+		}
+		for i, a := range group.Pays {
+			if a.Address.GetType() == common.ContractHash {
+				toKey := payoutKey{From: a.Address, Asset: asset}
+				outPoint := modules.NewOutPoint(common.NewSelfHash(), msgIdx+uint32(len(payments)), uint32(i))
+				pending[toKey] = append(pending[toKey],
+					modules.NewUtxoWithOutPoint(&modules.Utxo{Amount: a.Amount, Asset: &asset}, *outPoint))
 			}
-			payments = append(payments, payment)
 		}
+		payments = append(payments, payment)
 	}
 	return payments, nil
 }
@@ -82,26 +106,46 @@ type addrAmount struct {
 	Amount  uint64
 }
 
-func tokenPayOutGroupByAsset(payouts []*modules.TokenPayOut) map[modules.Asset][]*addrAmount {
-	result := make(map[modules.Asset][]*addrAmount)
+type payoutKey struct {
+	From  common.Address
+	Asset modules.Asset
+}
+
+//从同一个合约地址支付同一种Token的付款
+type payoutGroup struct {
+	payoutKey
+	Pays []*addrAmount
+}
+
+//按付款合约和Token分组，同一地址的付款合并，分组按第一次出现的顺序排列。
+//如果合约在付出某种Token之后又收到了这种Token，之后的付款放到新的分组中，以便使用收到的Token
+func tokenPayOutGroup(payouts []*modules.TokenPayOut, defaultFrom common.Address) []*payoutGroup {
+	result := []*payoutGroup{}
+	current := make(map[payoutKey]*payoutGroup)
 	for _, payout := range payouts {
-		asset := *payout.Asset
-		if aa, ok := result[asset]; ok {
-			hasSameAddr := false
-			for _, a := range aa {
-				if a.Address == payout.PayTo {
-					hasSameAddr = true
-					a.Amount += payout.Amount
-					break
-				}
-			}
-			if !hasSameAddr {
-				aa = append(aa, &addrAmount{Address: payout.PayTo, Amount: payout.Amount})
+		from := payout.PayFrom
+		if from == (common.Address{}) {
+			from = defaultFrom
+		}
+		key := payoutKey{From: from, Asset: *payout.Asset}
+		group, ok := current[key]
+		if !ok {
+			group = &payoutGroup{payoutKey: key}
+			current[key] = group
+			result = append(result, group)
+		}
+		hasSameAddr := false
+		for _, a := range group.Pays {
+			if a.Address == payout.PayTo {
+				hasSameAddr = true
+				a.Amount += payout.Amount
+				break
 			}
-			result[asset] = aa
-		} else {
-			result[asset] = []*addrAmount{{Address: payout.PayTo, Amount: payout.Amount}}
 		}
+		if !hasSameAddr {
+			group.Pays = append(group.Pays, &addrAmount{Address: payout.PayTo, Amount: payout.Amount})
+		}
+		delete(current, payoutKey{From: payout.PayTo, Asset: key.Asset})
 	}
 	return result
 }
@@ -38,39 +38,58 @@ var (
 	addr2, _ = common.StringToAddress("P1KP5TZwTY8UowE7X3zSZ3gZDHqwCqcCThR")
 )
 
-func TestTokenPayOutGroupByAsset(t *testing.T) {
-
+func TestTokenPayOutGroup(t *testing.T) {
+	contract := common.NewAddress([]byte{1}, common.ContractHash)
 	//addr3,_:=common.StringToAddress("P1PuhsNTmpsSV36wyoEF49b5dhRdaTQYC2C")
 	//no payout
 	pay0 := []*modules.TokenPayOut{}
-	g0 := tokenPayOutGroupByAsset(pay0)
+	g0 := tokenPayOutGroup(pay0, contract)
 	assert.Equal(t, 0, len(g0))
 	pay1 := []*modules.TokenPayOut{{PayTo: addr1, Amount: 123, Asset: ptn}}
 	//only 1 payout
-	g1 := tokenPayOutGroupByAsset(pay1)
+	g1 := tokenPayOutGroup(pay1, contract)
 	assert.Equal(t, 1, len(g1))
-	assert.EqualValues(t, 123, g1[*ptn][0].Amount)
+	assert.Equal(t, contract, g1[0].From)
+	assert.EqualValues(t, 123, g1[0].Pays[0].Amount)
 	//2 payouts,but same address, same asset
 	pay2 := append(pay1, &modules.TokenPayOut{PayTo: addr1, Amount: 1, Asset: ptn})
-	g2 := tokenPayOutGroupByAsset(pay2)
+	g2 := tokenPayOutGroup(pay2, contract)
 	assert.Equal(t, 1, len(g2))
-	assert.EqualValues(t, 124, g2[*ptn][0].Amount)
+	assert.EqualValues(t, 124, g2[0].Pays[0].Amount)
 	//3payments,addr1:2 pay  addr2: 1 pay
 	pay3 := append(pay2, &modules.TokenPayOut{PayTo: addr2, Amount: 1, Asset: ptn})
-	g3 := tokenPayOutGroupByAsset(pay3)
+	g3 := tokenPayOutGroup(pay3, contract)
 	assert.Equal(t, 1, len(g3))
-	assert.Equal(t, 2, len(g3[*ptn]))
-	for _, aa := range g3[*ptn] {
-		if aa.Address == addr1 {
-			assert.EqualValues(t, 124, aa.Amount)
-		}
-		if aa.Address == addr2 {
-			assert.EqualValues(t, 1, aa.Amount)
-		}
-	}
+	assert.Equal(t, 2, len(g3[0].Pays))
+	assert.Equal(t, addr1, g3[0].Pays[0].Address)
+	assert.EqualValues(t, 124, g3[0].Pays[0].Amount)
+	assert.Equal(t, addr2, g3[0].Pays[1].Address)
+	assert.EqualValues(t, 1, g3[0].Pays[1].Amount)
 	pay4 := append(pay3, &modules.TokenPayOut{PayTo: addr1, Amount: 1, Asset: btc})
-	g4 := tokenPayOutGroupByAsset(pay4)
+	g4 := tokenPayOutGroup(pay4, contract)
 	assert.Equal(t, 2, len(g4))
+	assert.Equal(t, *btc, g4[1].Asset)
+}
+
+func TestTokenPayOutGroupCalledContract(t *testing.T) {
+	caller := common.NewAddress([]byte{1}, common.ContractHash)
+	callee := common.NewAddress([]byte{2}, common.ContractHash)
+	payouts := []*modules.TokenPayOut{
+		//调用合约付给被调用合约
+		{PayFrom: caller, PayTo: callee, Amount: 100, Asset: ptn},
+		//被调用合约付给用户和调用合约
+		{PayFrom: callee, PayTo: addr1, Amount: 30, Asset: ptn},
+		{PayFrom: callee, PayTo: caller, Amount: 70, Asset: ptn},
+		//调用合约用收到的Token付款，放到新的分组
+		{PayFrom: caller, PayTo: addr2, Amount: 70, Asset: ptn},
+	}
+	groups := tokenPayOutGroup(payouts, caller)
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, caller, groups[0].From)
+	assert.Equal(t, callee, groups[1].From)
+	assert.Equal(t, 2, len(groups[1].Pays))
+	assert.Equal(t, caller, groups[2].From)
+	assert.Equal(t, addr2, groups[2].Pays[0].Address)
 }
 
 func TestResultToContractPayments(t *testing.T) {
@@ -91,9 +110,50 @@ func TestResultToContractPayments(t *testing.T) {
 	}
 	result := &modules.ContractInvokeResult{TokenPayOut: payouts}
 
-	payment, err := resultToContractPayments(mdag, result)
+	payment, err := resultToContractPayments(mdag, result, 2)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(payment))
 	d, _ := json.Marshal(payment)
 	t.Log(string(d))
 }
+
+func TestResultToContractPaymentsCalledContract(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	caller := common.NewAddress([]byte{1}, common.ContractHash)
+	callee := common.NewAddress([]byte{2}, common.ContractHash)
+	mdag := dag.NewMockIDag(mockCtrl)
+	callerUtxos := map[modules.OutPoint]*modules.Utxo{
+		{MessageIndex: 0, OutIndex: 1}: {Amount: 100, Asset: ptn},
+	}
+	mdag.EXPECT().GetAddr1TokenUtxos(caller, gomock.Any()).Return(callerUtxos, nil).AnyTimes()
+	//被调用合约上没有Token，只能使用同一交易中收到的Token
+	mdag.EXPECT().GetAddr1TokenUtxos(callee, gomock.Any()).
+		Return(map[modules.OutPoint]*modules.Utxo{}, nil).AnyTimes()
+
+	result := &modules.ContractInvokeResult{ContractId: caller.Bytes(), TokenPayOut: []*modules.TokenPayOut{
+		{PayFrom: caller, PayTo: callee, Amount: 60, Asset: ptn},
+		{PayFrom: callee, PayTo: addr1, Amount: 50, Asset: ptn},
+	}}
+	payments, err := resultToContractPayments(mdag, result, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(payments))
+	//调用合约：付给被调用合约60，找零40
+	assert.Equal(t, 2, len(payments[0].Outputs))
+	assert.EqualValues(t, 60, payments[0].Outputs[0].Value)
+	assert.EqualValues(t, 40, payments[0].Outputs[1].Value)
+	//被调用合约引用交易中第2个消息的第0个输出
+	assert.Equal(t, 1, len(payments[1].Inputs))
+	outPoint := payments[1].Inputs[0].PreviousOutPoint
+	assert.True(t, outPoint.TxHash.IsSelfHash())
+	assert.EqualValues(t, 2, outPoint.MessageIndex)
+	assert.EqualValues(t, 0, outPoint.OutIndex)
+	assert.EqualValues(t, 50, payments[1].Outputs[0].Value)
+	assert.EqualValues(t, 10, payments[1].Outputs[1].Value)
+
+	//收到的Token不够时失败
+	result.TokenPayOut[1].Amount = 61
+	_, err = resultToContractPayments(mdag, result, 2)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,101 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018
+ */
+package jury
+
+import (
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/dag/errors"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+type getJurorStatsFunc func(address common.Address) (*modules.JurorStats, error)
+
+//陪审员是否在上次维护时因信誉过低被排除，该状态只在维护时改变，各节点的判断一致
+func isJurorExcluded(e modules.ElectionInf, getStats getJurorStatsFunc) bool {
+	stats, err := getStats(crypto.PubkeyBytesToAddress(e.PublicKey))
+	if err != nil {
+		return false
+	}
+	return stats.Excluded
+}
+
+//去掉被排除的陪审员，保持原有顺序
+func filterExcludedJurors(eles []modules.ElectionInf, getStats getJurorStatsFunc) []modules.ElectionInf {
+	result := make([]modules.ElectionInf, 0, len(eles))
+	for _, e := range eles {
+		if isJurorExcluded(e, getStats) {
+			log.Debugf("filterExcludedJurors, exclude juror[%s]", e.AddrHash.String())
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+//提交陪审员返回不一致结果的证据，返回证据交易的hash
+func (p *Processor) JurorEvidenceReq(from common.Address, daoFee uint64,
+	evidence *modules.JurorEvidencePayload) (common.Hash, error) {
+	if from == (common.Address{}) || evidence == nil {
+		return common.Hash{}, errors.New("JurorEvidenceReq request param is error")
+	}
+	msg := modules.NewMessage(modules.APP_JUROR_EVIDENCE, evidence)
+	tx, _, err := p.dag.CreateGenericTransaction(from, from, 0, daoFee, nil, msg, p.ptn.TxPool())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	tx, err = p.ptn.SignGenericTransaction(from, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err = p.ptn.TxPool().AddLocal(tx); err != nil {
+		return common.Hash{}, err
+	}
+	log.Infof("JurorEvidenceReq ok, tx[%s], evidence[%s]", tx.Hash().String(), evidence.Hash().String())
+	return tx.Hash(), nil
+}
+
+//结果交易上链后，对与本地结果不一致的签名交易提交证据
+func (p *Processor) submitJurorEvidence(reqId common.Hash, accepted common.Hash, divTxs []*modules.Transaction) {
+	account := p.getLocalJuryAccount()
+	if account == nil {
+		return
+	}
+	fee := p.dag.GetChainParameters().TransferPtnBaseFee
+	for _, tx := range divTxs {
+		pubKeys, signs := getSignature(tx)
+		if len(pubKeys) == 0 || len(signs) == 0 {
+			continue
+		}
+		evidence, err := modules.NewJurorEvidence(accepted, tx,
+			modules.SignatureSet{PubKey: pubKeys[0], Signature: signs[0]})
+		if err != nil {
+			log.Debugf("[%s]submitJurorEvidence, NewJurorEvidence err:%s", shortId(reqId.String()), err.Error())
+			continue
+		}
+		if _, err := evidence.Verify(); err != nil {
+			log.Debugf("[%s]submitJurorEvidence, invalid evidence:%s", shortId(reqId.String()), err.Error())
+			continue
+		}
+		if _, err := p.JurorEvidenceReq(account.Address, fee, evidence); err != nil {
+			log.Debugf("[%s]submitJurorEvidence, JurorEvidenceReq err:%s", shortId(reqId.String()), err.Error())
+		}
+	}
+}
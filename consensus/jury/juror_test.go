@@ -0,0 +1,41 @@
+package jury
+
+import (
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/util"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterExcludedJurors(t *testing.T) {
+	eles := []modules.ElectionInf{}
+	addrs := []common.Address{}
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		pubKey := crypto.CompressPubkey(&key.PublicKey)
+		addr := crypto.PubkeyBytesToAddress(pubKey)
+		addrs = append(addrs, addr)
+		eles = append(eles, modules.ElectionInf{AddrHash: util.RlpHash(addr), PublicKey: pubKey})
+	}
+	stats := map[common.Address]*modules.JurorStats{
+		addrs[0]: {Participated: 60, Timeouts: 40},
+		//信誉已经很低，但还没有经过维护，仍然可以被选举
+		addrs[1]: {Participated: 10, Divergent: 10},
+		addrs[2]: {Participated: 10, Divergent: 10, Excluded: true},
+	}
+	getStats := func(addr common.Address) (*modules.JurorStats, error) {
+		if s, ok := stats[addr]; ok {
+			return s, nil
+		}
+		return modules.NewJurorStats(addr), nil
+	}
+
+	result := filterExcludedJurors(eles, getStats)
+	assert.Equal(t, 3, len(result))
+	assert.Equal(t, eles[0].AddrHash, result[0].AddrHash)
+	assert.Equal(t, eles[1].AddrHash, result[1].AddrHash)
+	assert.Equal(t, eles[3].AddrHash, result[2].AddrHash)
+}
@@ -32,9 +32,11 @@ import (
 	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/common/event"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/mclock"
 	"github.com/palletone/go-palletone/common/p2p"
 	"github.com/palletone/go-palletone/common/util"
 	"github.com/palletone/go-palletone/contracts"
+	"github.com/palletone/go-palletone/contracts/outchain"
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/core/accounts"
 	"github.com/palletone/go-palletone/core/accounts/keystore"
@@ -93,11 +95,15 @@ type iDag interface {
 	GetContractState(id []byte, field string) ([]byte, *modules.StateVersion, error)
 	GetContractStatesByPrefix(id []byte, prefix string) (map[string]*modules.ContractStateValue, error)
 	ChainThreshold() int
+	GetJurorStats(address common.Address) (*modules.JurorStats, error)
 	GetChainParameters() *core.ChainParameters
 	GetMediators() map[common.Address]bool
 	GetMediator(add common.Address) *core.Mediator
 	GetBlacklistAddress() ([]common.Address, *modules.StateVersion, error)
-
+	GetSlotAtTime(when time.Time) uint32
+	GetScheduledMediator(slotNum uint32) common.Address
+	GetNewestUnitTimestamp(token modules.AssetId) (int64, error)
+	GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error)
 }
 
 type electionVrf struct {
@@ -118,6 +124,7 @@ type contractTx struct {
 	rstTx    *modules.Transaction   //contract run result---system
 	sigTx    *modules.Transaction   //contract sig result---user, 0:local, 1,2 other,signature is the same as local value
 	rcvTx    []*modules.Transaction //the local has not received the request contract, the cache has signed the contract
+	divTx    []*modules.Transaction //sig results that are different from local
 	tm       time.Time              //create time
 	valid    bool                   //contract request valid identification
 	reqRcvEd bool                   //contract request received
@@ -130,6 +137,7 @@ type Processor struct {
 	dag       iDag
 	validator validator.Validator
 	contract  *contracts.Contract
+	clock     mclock.Clock //事件广播等异步调用通过clock执行
 
 	local   map[common.Address]*JuryAccount          //[]common.Address //local jury account addr
 	mtx     map[common.Hash]*contractTx              //all contract buffer
@@ -150,6 +158,10 @@ func NewContractProcessor(ptn PalletOne, dag iDag, contract *contracts.Contract,
 	if ptn == nil || dag == nil {
 		return nil, errors.New("NewContractProcessor, param is nil")
 	}
+	//注册链外适配器，配置错误时不启动节点
+	if err := outchain.RegisterAdaptors(); err != nil {
+		return nil, fmt.Errorf("NewContractProcessor, register outchain adaptors failed: %v", err)
+	}
 	acs := make(map[common.Address]*JuryAccount)
 	for _, cfg := range cfg.Accounts {
 		account := cfg.configToAccount()
@@ -161,6 +173,17 @@ func NewContractProcessor(ptn PalletOne, dag iDag, contract *contracts.Contract,
 			}
 		}
 	}
+	//用专用密码解锁链外私钥keystore，jury没有链外私钥就无法执行链外合约，因此解锁失败时不能启动
+	if len(acs) > 0 && !outchain.IsKeyStoreUnlocked() {
+		outchain.SetKeyStorePath(cfg.OutChainKeyStore)
+		if cfg.OutChainPassword == "" {
+			return nil, fmt.Errorf("NewContractProcessor, the password of outchain keystore is not configured, " +
+				"set Jury.OutChainPassword")
+		}
+		if err := outchain.UnlockKeyStore(cfg.OutChainPassword); err != nil {
+			return nil, fmt.Errorf("NewContractProcessor, unlock outchain keystore failed: %v", err)
+		}
+	}
 	cp := dag.GetChainParameters()
 	var contractSigNum int
 	if cp.ContractSignatureNum < 1 {
@@ -177,12 +200,13 @@ func NewContractProcessor(ptn PalletOne, dag iDag, contract *contracts.Contract,
 	log.Debug("NewContractProcessor", "contractEleNum", contractEleNum, "contractSigNum", contractSigNum)
 
 	cache := freecache.NewCache(20 * 1024 * 1024)
-	validator := validator.NewValidate(dag, dag, dag, nil, cache)
+	validator := validator.NewValidate(dag, dag, dag, dag, cache)
 	p := &Processor{
 		name:           "contractProcessor",
 		ptn:            ptn,
 		dag:            dag,
 		contract:       contract,
+		clock:          mclock.System{},
 		local:          acs,
 		locker:         new(sync.Mutex),
 		quit:           make(chan struct{}),
@@ -202,6 +226,24 @@ func (p *Processor) SetContract(contract *contracts.Contract) {
 	p.contract = contract
 }
 
+//SetClock 替换异步调用使用的时钟，模拟测试中使用虚拟时钟
+func (p *Processor) SetClock(clock mclock.Clock) {
+	p.clock = clock
+}
+
+//异步广播事件，事件内容在调用时确定
+func (p *Processor) contractBroadcast(event ContractEvent, local bool) {
+	p.clock.Go(func() { p.ptn.ContractBroadcast(event, local) })
+}
+
+func (p *Processor) electionBroadcast(event ElectionEvent, local bool) {
+	p.clock.Go(func() { p.ptn.ElectionBroadcast(event, local) })
+}
+
+func (p *Processor) adapterBroadcast(event AdapterEvent) {
+	p.clock.Go(func() { p.ptn.AdapterBroadcast(event) })
+}
+
 func (p *Processor) Start(server *p2p.Server) error {
 	//启动消息接收处理线程
 	//合约执行节点更新线程
@@ -267,7 +309,9 @@ func (p *Processor) runContractReq(reqId common.Hash, ele *modules.ElectionNode)
 	reqTx := ctx.reqTx.Clone()
 	p.locker.Unlock()
 
+	start := time.Now()
 	msgs, err := runContractCmd(rwset.RwM, p.dag, p.contract, &reqTx, ele, p.errMsgEnable) //contract exec long time...
+	contractExecTimer.UpdateSince(start)
 	if err != nil {
 		log.Errorf("[%s]runContractReq, runContractCmd reqTx, err：%s", shortId(reqId.String()), err.Error())
 		return err
@@ -306,6 +350,7 @@ func (p *Processor) runContractReq(reqId common.Hash, ele *modules.ElectionNode)
 				ok, err := checkAndAddTxSigMsgData(ctx.sigTx, rtx)
 				if err != nil {
 					log.Debugf("[%s]runContractReq, checkAndAddTxSigMsgData error:%s", shortId(reqId.String()), err.Error())
+					ctx.divTx = append(ctx.divTx, rtx)
 				} else if ok {
 					log.Debugf("[%s]runContractReq, checkAndAddTxSigMsgData ok, tx[%s]", shortId(reqId.String()), rtx.Hash().String())
 				} else {
@@ -321,12 +366,12 @@ func (p *Processor) runContractReq(reqId common.Hash, ele *modules.ElectionNode)
 				//签名数量足够，而且当前节点是签名最新的节点，那么合并签名并广播完整交易
 				log.Infof("[%s]runContractReq, localIsMinSignature Ok!", shortId(reqId.String()))
 				processContractPayout(ctx.sigTx, ele)
-				go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_COMMIT, Ele: ele, Tx: ctx.sigTx}, true)
+				p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_COMMIT, Ele: ele, Tx: ctx.sigTx}, true)
 				return nil
 			}
 		}
 		//广播
-		go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_SIG, Ele: ele, Tx: sigTx}, false)
+		p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_SIG, Ele: ele, Tx: sigTx}, false)
 	}
 	return nil
 }
@@ -730,7 +775,7 @@ func (p *Processor) SignAndExecuteAndSendRequest(from common.Address,
 			return nil, err
 		}
 		//broadcast
-		go p.ptn.ContractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
+		p.contractBroadcast(ContractEvent{CType: CONTRACT_EVENT_EXEC, Ele: p.mtx[reqId].eleNode, Tx: tx}, true)
 		return tx, nil
 	}
 	return nil, errors.New("Not support request")
@@ -755,7 +800,7 @@ func (p *Processor) signAndExecute(contractId common.Address, from common.Addres
 	}
 	p.mtx[reqId] = &contractTx{
 		reqTx:  tx.GetRequestTx(),
-		tm:     time.Now(),
+		tm:     p.clock.Now(),
 		valid:  true,
 		adaInf: make(map[uint32]*AdapterInf),
 	}
@@ -782,20 +827,27 @@ func (p *Processor) ContractTxDeleteLoop() {
 		time.Sleep(time.Second * time.Duration(5))
 		p.locker.Lock()
 		for k, v := range p.mtx {
+			//结果交易已上链，对不一致的结果提交证据
+			if len(v.divTx) > 0 {
+				if txHash, err := p.dag.GetTxHashByReqId(k); err == nil && txHash != (common.Hash{}) {
+					go p.submitJurorEvidence(k, txHash, v.divTx)
+					v.divTx = nil
+				}
+			}
 			if !v.valid {
-				if time.Since(v.tm) > time.Second*120 {
+				if p.clock.Now().Sub(v.tm) > time.Second*120 {
 					log.Infof("[%s]ContractTxDeleteLoop, contract is invalid, delete tx id", shortId(k.String()))
 					delete(p.mtx, k)
 				}
 			} else {
-				if time.Since(v.tm) > time.Second*600 {
+				if p.clock.Now().Sub(v.tm) > time.Second*600 {
 					log.Infof("[%s]ContractTxDeleteLoop, contract is valid, delete tx id", shortId(k.String()))
 					delete(p.mtx, k)
 				}
 			}
 		}
 		for k, v := range p.mel {
-			if time.Since(v.tm) > time.Second*300 {
+			if p.clock.Now().Sub(v.tm) > time.Second*300 {
 				log.Infof("[%s]ContractTxDeleteLoop, delete electionVrf ", shortId(k.String()))
 				delete(p.mel, k)
 			}
@@ -71,6 +71,36 @@ func Selected(expectedNum uint, weight, total uint64, vrf []byte) int {
 	return j
 }
 
+/*
+Preliminary test conclusion
+expectNum:4
+use:
+total    weight    num
+20         4        5
+50         7        7
+100        8        5
+200        15       5
+500        17       6
+1000       18       5
+*/
+//ElectionWeight 根据候选陪审员总数返回选举时使用的权重
+func ElectionWeight(total uint64) (val uint64) {
+	if total <= 20 {
+		return 4
+	} else if total > 20 && total <= 50 {
+		return 7
+	} else if total > 50 && total <= 100 {
+		return 8
+	} else if total > 100 && total <= 200 {
+		return 15
+	} else if total > 200 && total <= 500 {
+		return 17
+	} else if total > 500 {
+		return 20
+	}
+	return 4
+}
+
 //func parallelTrevels(core int, N uint64, hash *big.Rat, binomial Binomial) int {
 //	var wg sync.WaitGroup
 //	groups := N / uint64(core)
@@ -101,9 +101,15 @@ func (mp *MediatorPlugin) localMediatorPubKey(add common.Address) []byte {
 		return pubKey
 	}
 
-	dks, err := dkgr.DistKeyShare()
+	scheme, err := mp.groupSignScheme()
+	if err != nil {
+		log.Debugf(err.Error())
+		return pubKey
+	}
+
+	dks, err := dkgr.KeyShare()
 	if err == nil {
-		pubKey, err = dks.Public().MarshalBinary()
+		pubKey, err = scheme.GroupPubKey(dks)
 		if err != nil {
 			log.Debugf(err.Error())
 			pubKey = nil
@@ -129,11 +135,8 @@ type InitDKSResult struct {
 	PublicKey  string `json:"public_key"`  // 初始群签名公钥
 }
 
-func (a *PublicMediatorAPI) DumpInitDKS() (res InitDKSResult) {
-	sec, pub := core.GenInitPair()
-
-	res.PrivateKey = core.ScalarToStr(sec)
-	res.PublicKey = core.PointToStr(pub)
+func (a *PublicMediatorAPI) DumpInitDKS() (res InitDKSResult, err error) {
+	res.PrivateKey, res.PublicKey, err = core.CreateInitDKS(a.dag.GetChainParameters().GroupSignScheme)
 
 	return
 }
@@ -149,7 +152,7 @@ func NewPrivateMediatorAPI(mp *MediatorPlugin) *PrivateMediatorAPI {
 func (a *PrivateMediatorAPI) StartProduce() bool {
 	if !a.producingEnabled {
 		a.producingEnabled = true
-		go a.launchProduction()
+		a.clock.Go(a.launchProduction)
 
 		return true
 	}
@@ -25,7 +25,6 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/core"
-	"go.dedis.ch/kyber/v3"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -180,13 +179,13 @@ func (medConf *MediatorConf) configToAccount() *MediatorAccount {
 	}
 
 	// 2. 解析 mediator 的 DKS 初始公私钥
-	sec, err := core.StrToScalar(medConf.InitPrivKey)
+	sec, err := core.StrToInitKey(medConf.InitPrivKey)
 	if err != nil {
 		log.Debugf(err.Error())
 		return nil
 	}
 
-	pub, err := core.StrToPoint(medConf.InitPubKey)
+	pub, err := core.StrToInitKey(medConf.InitPubKey)
 	if err != nil {
 		log.Debugf(err.Error())
 		return nil
@@ -205,6 +204,6 @@ func (medConf *MediatorConf) configToAccount() *MediatorAccount {
 type MediatorAccount struct {
 	Address     common.Address
 	Password    string
-	InitPrivKey kyber.Scalar
-	InitPubKey  kyber.Point
+	InitPrivKey []byte
+	InitPubKey  []byte
 }
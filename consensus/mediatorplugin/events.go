@@ -20,8 +20,8 @@ package mediatorplugin
 
 import (
 	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/core/groupsign"
 	"github.com/palletone/go-palletone/dag/modules"
-	"go.dedis.ch/kyber/v3/share/dkg/pedersen"
 )
 
 // NewUnitEvent is posted when a unit has been produced.
@@ -36,11 +36,11 @@ type SigShareEvent struct {
 
 type VSSDealEvent struct {
 	DstIndex uint32
-	Deal     *dkg.Deal
+	Deal     *groupsign.Deal
 }
 
 type VSSResponseEvent struct {
-	Resp *dkg.Response
+	Resp *groupsign.Response
 }
 
 type GroupSigEvent struct {
@@ -0,0 +1,66 @@
+/*
+ *  This file is part of go-palletone.
+ *  go-palletone is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *  go-palletone is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *  You should have received a copy of the GNU General Public License
+ *  along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package mediatorplugin
+
+import (
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+// 接收dag发现的mediator重复出块证据，并由本节点的mediator提交到保证金合约进行处罚
+func (mp *MediatorPlugin) evidenceLoop() {
+	evidenceCh := make(chan modules.MediatorEvidenceEvent)
+	evidenceSub := mp.dag.SubscribeMediatorEvidenceEvent(evidenceCh)
+	defer evidenceSub.Unsubscribe()
+
+	for {
+		select {
+		case event := <-evidenceCh:
+			go mp.submitEvidence(event.Evidence)
+		case <-evidenceSub.Err():
+			return
+		case <-mp.quit:
+			return
+		}
+	}
+}
+
+func (mp *MediatorPlugin) submitEvidence(evidence *modules.MediatorEvidencePayload) {
+	offender, err := evidence.Verify()
+	if err != nil {
+		log.Debugf("invalid mediator evidence: %v", err.Error())
+		return
+	}
+
+	for localMed := range mp.mediators {
+		// 不能由作恶的mediator自己提交
+		if localMed == offender {
+			continue
+		}
+
+		fee := mp.dag.GetChainParameters().TransferPtnBaseFee
+		reqId, err := mp.ptn.ContractProcessor().SlashMediatorReq(localMed, fee, evidence)
+		if err != nil {
+			log.Debugf("mediator(%v) fail to submit evidence of mediator(%v): %v", localMed.Str(),
+				offender.Str(), err.Error())
+			continue
+		}
+
+		log.Infof("mediator(%v) submitted evidence of mediator(%v), reqId: %v", localMed.Str(),
+			offender.Str(), reqId.String())
+		return
+	}
+}
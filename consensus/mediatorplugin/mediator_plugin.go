@@ -38,7 +38,7 @@ func (mp *MediatorPlugin) newChainBanner() {
 		"*   -------------------------   *\n" +
 		"\n")
 
-	if mp.dag.GetSlotAtTime(time.Now()) > 200 {
+	if mp.dag.GetSlotAtTime(mp.clock.Now()) > 200 {
 		log.Debugf("Your genesis seems to have an old timestamp. " +
 			"Please consider using the --genesistime option to give your genesis a recent timestamp.")
 	}
@@ -50,26 +50,24 @@ func (mp *MediatorPlugin) SubscribeNewProducedUnitEvent(ch chan<- NewProducedUni
 
 func (mp *MediatorPlugin) scheduleProductionLoop() {
 	// 1. 计算下一秒的滴答时刻，如果少于50毫秒，则多等一秒开始
-	now := time.Now()
+	now := mp.clock.Now()
 	timeToNextSecond := time.Second - time.Duration(now.Nanosecond())
 	if timeToNextSecond < 50*time.Millisecond {
 		timeToNextSecond += time.Second
 	}
 
 	// 2. 安排unit生产循环
-	// Start to production unit for expiration
-	timeout := time.NewTimer(timeToNextSecond)
-	defer timeout.Stop()
-
 	// production unit until termination is requested
-	select {
-	case <-mp.quit:
-		return
-	case <-mp.stopProduce:
-		return
-	case <-timeout.C:
-		go mp.unitProductionLoop()
-	}
+	mp.clock.AfterFunc(timeToNextSecond, func() {
+		select {
+		case <-mp.quit:
+			return
+		case <-mp.stopProduce:
+			return
+		default:
+			mp.unitProductionLoop()
+		}
+	})
 }
 
 // unit生产的状态类型
@@ -127,7 +125,7 @@ func (mp *MediatorPlugin) unitProductionLoop() ProductionCondition {
 	}
 
 	// 3. 继续循环生产计划
-	go mp.scheduleProductionLoop()
+	mp.scheduleProductionLoop()
 
 	return result
 }
@@ -137,7 +135,7 @@ func (mp *MediatorPlugin) maybeProduceUnit() (ProductionCondition, map[string]st
 	dag := mp.dag
 
 	// 整秒调整，四舍五入
-	nowFine := time.Now()
+	nowFine := mp.clock.Now()
 	now := time.Unix(nowFine.Add(500*time.Millisecond).Unix(), 0)
 
 	// 1. 判断是否满足生产的各个条件
@@ -229,7 +227,7 @@ func (mp *MediatorPlugin) maybeProduceUnit() (ProductionCondition, map[string]st
 	rwset.RwM.Close()
 
 	//广播节点选取签名请求事件
-	go mp.ptn.ContractProcessor().BroadcastElectionSigRequestEvent()
+	mp.clock.Go(mp.ptn.ContractProcessor().BroadcastElectionSigRequestEvent)
 
 	// 2. 生产单元
 	var groupPubKey []byte = nil
@@ -256,11 +254,16 @@ func (mp *MediatorPlugin) maybeProduceUnit() (ProductionCondition, map[string]st
 
 	// 3. 对 unit 进行群签名和广播
 	if mp.groupSigningEnabled {
-		go mp.groupSignUnit(scheduledMediator, unitHash)
+		mp.clock.Go(func() { mp.groupSignUnit(scheduledMediator, unitHash) })
 	}
 
 	// 4. 异步向区块链网络广播新unit
-	go mp.newProducedUnitFeed.Send(NewProducedUnitEvent{Unit: newUnit})
+	mp.clock.Go(func() { mp.newProducedUnitFeed.Send(NewProducedUnitEvent{Unit: newUnit}) })
+
+	// 5. 触发定时支付合约中到期的支付
+	mp.clock.Go(func() {
+		mp.triggerScheduledPayments(scheduledMediator, newUnit.Timestamp(), newUnit.NumberU64())
+	})
 
 	return Produced, detail
 }
@@ -276,21 +279,17 @@ func (mp *MediatorPlugin) groupSignUnit(localMed common.Address, unitHash common
 	mp.recoverBufLock.Unlock()
 
 	// 2. 过了 unit 确认时间后，及时删除群签名分片的相关数据，防止内存溢出
-	go func() {
-		expiration := mp.dag.UnitIrreversibleTime()
-		deleteBuf := time.NewTimer(expiration)
-
-		select {
-		case <-mp.quit:
+	mp.clock.AfterFunc(mp.dag.UnitIrreversibleTime(), func() {
+		if mp.stopped() {
 			return
-		case <-deleteBuf.C:
-			mp.recoverBufLock.Lock()
-			if _, ok := mp.toTBLSRecoverBuf[localMed][unitHash]; ok {
-				log.Debugf("the unit(%v) has expired confirmation time, no longer need the mediator(%v) "+
-					"to recover group-sign", unitHash.TerminalString(), localMed.Str())
-				delete(mp.toTBLSRecoverBuf[localMed], unitHash)
-			}
-			mp.recoverBufLock.Unlock()
 		}
-	}()
+
+		mp.recoverBufLock.Lock()
+		if _, ok := mp.toTBLSRecoverBuf[localMed][unitHash]; ok {
+			log.Debugf("the unit(%v) has expired confirmation time, no longer need the mediator(%v) "+
+				"to recover group-sign", unitHash.TerminalString(), localMed.Str())
+			delete(mp.toTBLSRecoverBuf[localMed], unitHash)
+		}
+		mp.recoverBufLock.Unlock()
+	})
 }
@@ -0,0 +1,105 @@
+/*
+ *  This file is part of go-palletone.
+ *  go-palletone is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *  go-palletone is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU General Public License for more details.
+ *  You should have received a copy of the GNU General Public License
+ *  along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package mediatorplugin
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+// 同一个到期时间的支付请求未执行成功时，重新提交的间隔
+const scheduleRetryInterval = time.Minute
+
+// 本节点的mediator生产unit后，检查定时支付合约中是否有到期的支付，有则由该mediator提交执行请求
+func (mp *MediatorPlugin) triggerScheduledPayments(producer common.Address, unitTime int64, height uint64) {
+	data, _, err := mp.dag.GetContractState(syscontract.ScheduledPaymentContractAddress.Bytes(),
+		modules.ScheduleNextDueKey)
+	if err != nil || len(data) == 0 {
+		return
+	}
+	nextDue, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		log.Debugf("invalid next due time of scheduled payment: %v", string(data))
+		return
+	}
+
+	// 合约通过 GetTxTimestamp(10) 获取时间，最多比最新unit落后10个生产间隔
+	cp := mp.dag.GetChainParameters()
+	now := unitTime - int64(cp.MediatorInterval)*10
+	if nextDue > now {
+		return
+	}
+
+	// 到期的计划可能还没有达到开始高度，合约通过 GetTxHeight(10) 获取高度
+	height = height / 10 * 10
+	data, _, err = mp.dag.GetContractState(syscontract.ScheduledPaymentContractAddress.Bytes(),
+		modules.ScheduleNextDueHeightKey)
+	if err == nil && len(data) > 0 {
+		nextHeight, err := strconv.ParseUint(string(data), 10, 64)
+		if err == nil && height < nextHeight {
+			return
+		}
+	}
+	if !mp.hasDueSchedule(now, height) {
+		return
+	}
+
+	mp.scheduleLock.Lock()
+	if mp.lastScheduleDue == nextDue && mp.clock.Now().Sub(mp.lastScheduleTrigger) < scheduleRetryInterval {
+		mp.scheduleLock.Unlock()
+		return
+	}
+	mp.lastScheduleDue = nextDue
+	mp.lastScheduleTrigger = mp.clock.Now()
+	mp.scheduleLock.Unlock()
+
+	args := [][]byte{[]byte(modules.ExecuteDueSchedules)}
+	reqId, err := mp.ptn.ContractProcessor().ContractInvokeReq(producer, producer, 0, cp.TransferPtnBaseFee,
+		nil, syscontract.ScheduledPaymentContractAddress, args, 0)
+	if err != nil {
+		log.Debugf("mediator(%v) fail to trigger scheduled payments: %v", producer.Str(), err.Error())
+		return
+	}
+
+	log.Infof("mediator(%v) triggered scheduled payments due at %v, reqId: %v", producer.Str(),
+		time.Unix(nextDue, 0).Format("2006-01-02 15:04:05"), reqId.String())
+}
+
+func (mp *MediatorPlugin) hasDueSchedule(now int64, height uint64) bool {
+	kvs, err := mp.dag.GetContractStatesByPrefix(syscontract.ScheduledPaymentContractAddress.Bytes(),
+		modules.SchedulePrefix)
+	if err != nil {
+		return false
+	}
+
+	for _, kv := range kvs {
+		schedule := &modules.Schedule{}
+		if err := json.Unmarshal(kv.Value, schedule); err != nil {
+			continue
+		}
+		if schedule.DueCount(now, height) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
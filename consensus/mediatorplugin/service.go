@@ -20,6 +20,7 @@
 package mediatorplugin
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"sync"
 	"time"
@@ -27,18 +28,17 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/event"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/mclock"
 	"github.com/palletone/go-palletone/common/p2p"
 	"github.com/palletone/go-palletone/common/rpc"
 	"github.com/palletone/go-palletone/consensus/jury"
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/core/accounts"
 	"github.com/palletone/go-palletone/core/accounts/keystore"
+	"github.com/palletone/go-palletone/core/groupsign"
 	"github.com/palletone/go-palletone/core/node"
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/txspool"
-	"go.dedis.ch/kyber/v3"
-	"go.dedis.ch/kyber/v3/pairing/bn256"
-	"go.dedis.ch/kyber/v3/share/dkg/pedersen"
 )
 
 // PalletOne wraps all methods required for producing unit.
@@ -55,7 +55,7 @@ type iDag interface {
 	HeadUnitTime() int64
 
 	GetScheduledMediator(slotNum uint32) common.Address
-	GetActiveMediatorInitPubs() []kyber.Point
+	GetActiveMediatorInitPubs() [][]byte
 	ActiveMediatorsCount() int
 	GetActiveMediatorAddr(index int) common.Address
 	HeadUnitNum() uint64
@@ -83,6 +83,11 @@ type iDag interface {
 
 	IsConsecutiveMediator(nextMediator common.Address) bool
 	MediatorParticipationRate() uint32
+
+	GetChainParameters() *core.ChainParameters
+	GetContractState(contractid []byte, field string) ([]byte, *modules.StateVersion, error)
+	GetContractStatesByPrefix(id []byte, prefix string) (map[string]*modules.ContractStateValue, error)
+	SubscribeMediatorEvidenceEvent(ch chan<- modules.MediatorEvidenceEvent) event.Subscription
 }
 
 type MediatorPlugin struct {
@@ -91,6 +96,9 @@ type MediatorPlugin struct {
 	dag  iDag
 	//srvr *p2p.Server
 
+	// 产块、vss协议和群签名的定时和异步调用都通过clock
+	clock mclock.Clock
+
 	// 标记是否主程序启动时，就开启unit生产功能
 	producingEnabled bool
 	stopProduce      chan struct{}
@@ -117,17 +125,20 @@ type MediatorPlugin struct {
 	newProducedUnitScope event.SubscriptionScope // 零值已准备就绪待用
 
 	// dkg 处理和使用 相关
-	suite               *bn256.Suite
-	activeDKGs          map[common.Address]*dkg.DistKeyGenerator
-	precedingDKGs       map[common.Address]*dkg.DistKeyGenerator
+	activeDKGs          map[common.Address]groupsign.DKG
+	precedingDKGs       map[common.Address]groupsign.DKG
 	lastMaintenanceTime int64
 	dkgLock             *sync.RWMutex
+	// 生成DKG使用的随机数源，为nil时使用系统的随机数
+	dkgRand func(localMed common.Address) cipher.Stream
 
 	// dkg 完成 vss 协议相关
-	dealBuf    map[common.Address]chan *dkg.Deal
-	respBuf    map[common.Address]map[common.Address]chan *dkg.Response
-	vssBufLock *sync.RWMutex
-	stopVSS    chan struct{}
+	// 正在完成vss协议的本地mediator，以及处理response阶段之前收到的response
+	vssMediators map[common.Address]bool
+	respBuf      map[common.Address][]*groupsign.Response
+	respReady    bool
+	vssRound     uint64
+	vssBufLock   *sync.RWMutex
 
 	// 广播和处理 vss 协议 deal
 	vssDealFeed  event.Feed
@@ -149,6 +160,11 @@ type MediatorPlugin struct {
 	// unit 群签名的事件订阅
 	groupSigFeed  event.Feed
 	groupSigScope event.SubscriptionScope
+
+	// 最近一次触发定时支付的到期时间和触发时间，避免重复提交
+	scheduleLock        sync.Mutex
+	lastScheduleDue     int64
+	lastScheduleTrigger time.Time
 }
 
 func (mp *MediatorPlugin) Protocols() []p2p.Protocol {
@@ -181,7 +197,12 @@ func (mp *MediatorPlugin) Start(server *p2p.Server) error {
 
 	// 开启循环生产计划
 	if mp.producingEnabled {
-		go mp.launchProduction()
+		mp.clock.Go(mp.launchProduction)
+	}
+
+	// 监听并提交mediator重复出块的证据
+	if len(mp.mediators) > 0 {
+		go mp.evidenceLoop()
 	}
 
 	// 开始完成 vss 协议
@@ -222,7 +243,7 @@ func (mp *MediatorPlugin) launchProduction() {
 		}
 
 		// 调度生产unit
-		go mp.scheduleProductionLoop()
+		mp.scheduleProductionLoop()
 	}
 }
 
@@ -263,6 +284,11 @@ func RegisterMediatorPluginService(stack *node.Node, cfg *Config) {
 	}
 }
 
+// 链参数选定的群签名方案
+func (mp *MediatorPlugin) groupSignScheme() (groupsign.Scheme, error) {
+	return groupsign.Lookup(mp.dag.GetChainParameters().GroupSignScheme)
+}
+
 func NewMediatorPlugin( /*ctx *node.ServiceContext, */ cfg *Config, ptn PalletOne, dag iDag) (*MediatorPlugin, error) {
 	log.Infof("mediator plugin initialize begin")
 
@@ -273,9 +299,10 @@ func NewMediatorPlugin( /*ctx *node.ServiceContext, */ cfg *Config, ptn PalletOn
 	}
 
 	mp := MediatorPlugin{
-		ptn:  ptn,
-		quit: make(chan struct{}),
-		dag:  dag,
+		ptn:   ptn,
+		quit:  make(chan struct{}),
+		dag:   dag,
+		clock: mclock.System{},
 
 		producingEnabled: cfg.EnableProducing,
 		stopProduce:      make(chan struct{}),
@@ -285,9 +312,8 @@ func NewMediatorPlugin( /*ctx *node.ServiceContext, */ cfg *Config, ptn PalletOn
 		requiredParticipation:     cfg.RequiredParticipation * core.PalletOne1Percent,
 		groupSigningEnabled:       cfg.EnableGroupSigning,
 
-		suite:               core.Suite,
-		activeDKGs:          make(map[common.Address]*dkg.DistKeyGenerator),
-		precedingDKGs:       make(map[common.Address]*dkg.DistKeyGenerator),
+		activeDKGs:          make(map[common.Address]groupsign.DKG),
+		precedingDKGs:       make(map[common.Address]groupsign.DKG),
 		lastMaintenanceTime: dag.LastMaintenanceTime(),
 		dkgLock:             new(sync.RWMutex),
 	}
@@ -302,6 +328,26 @@ func NewMediatorPlugin( /*ctx *node.ServiceContext, */ cfg *Config, ptn PalletOn
 	return &mp, nil
 }
 
+// SetClock 替换产块、vss协议和群签名使用的时钟，需要在Start之前调用
+func (mp *MediatorPlugin) SetClock(clock mclock.Clock) {
+	mp.clock = clock
+}
+
+// SetDKGRand 设置生成DKG使用的随机数源，使模拟测试中的群公钥每次运行都相同
+func (mp *MediatorPlugin) SetDKGRand(rand func(localMed common.Address) cipher.Stream) {
+	mp.dkgRand = rand
+}
+
+// stopped 判断插件是否已经停止，定时和异步调用执行之前检查
+func (mp *MediatorPlugin) stopped() bool {
+	select {
+	case <-mp.quit:
+		return true
+	default:
+		return false
+	}
+}
+
 func (mp *MediatorPlugin) initLocalConfigMediator(mcs []*MediatorConf /*, am *accounts.Manager*/) {
 	mas := make(map[common.Address]*MediatorAccount, len(mcs))
 	//ks := am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
@@ -333,9 +379,8 @@ func (mp *MediatorPlugin) initLocalConfigMediator(mcs []*MediatorConf /*, am *ac
 func (mp *MediatorPlugin) initGroupSignBuf() {
 	lamc := len(mp.mediators)
 
-	mp.dealBuf = make(map[common.Address]chan *dkg.Deal, lamc)
-	mp.respBuf = make(map[common.Address]map[common.Address]chan *dkg.Response, lamc)
-	mp.stopVSS = make(chan struct{})
+	mp.vssMediators = make(map[common.Address]bool, lamc)
+	mp.respBuf = make(map[common.Address][]*groupsign.Response, lamc)
 	mp.vssBufLock = new(sync.RWMutex)
 
 	mp.toTBLSSignBuf = make(map[common.Address]*sync.Map, lamc)
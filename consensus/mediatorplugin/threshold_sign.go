@@ -19,7 +19,9 @@
 package mediatorplugin
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -27,10 +29,8 @@ import (
 	"github.com/palletone/go-palletone/common/event"
 	"github.com/palletone/go-palletone/common/hexutil"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/core/groupsign"
 	"github.com/palletone/go-palletone/dag/modules"
-	"go.dedis.ch/kyber/v3/share"
-	"go.dedis.ch/kyber/v3/share/dkg/pedersen"
-	"go.dedis.ch/kyber/v3/sign/tbls"
 )
 
 func (mp *MediatorPlugin) signUnitsTBLS(localMed common.Address) {
@@ -40,18 +40,26 @@ func (mp *MediatorPlugin) signUnitsTBLS(localMed common.Address) {
 		return
 	}
 
+	unitHashes := make([]common.Hash, 0)
 	rangeFn := func(key, value interface{}) bool {
 		newUnitHash, ok := key.(common.Hash)
 		if !ok {
 			log.Debugf("key converted to Hash failed")
 			return true
 		}
-		go mp.signUnitTBLS(localMed, newUnitHash)
+		unitHashes = append(unitHashes, newUnitHash)
 
 		return true
 	}
 
 	medUnitsBuf.Range(rangeFn)
+
+	// 按哈希排序后依次签名，使调度顺序不依赖map的遍历顺序
+	sortHashes(unitHashes)
+	for _, unitHash := range unitHashes {
+		unitHash := unitHash
+		mp.clock.Go(func() { mp.signUnitTBLS(localMed, unitHash) })
+	}
 }
 
 func (mp *MediatorPlugin) recoverUnitsTBLS(localMed common.Address) {
@@ -64,9 +72,22 @@ func (mp *MediatorPlugin) recoverUnitsTBLS(localMed common.Address) {
 		return
 	}
 
+	unitHashes := make([]common.Hash, 0, len(sigSharesBuf))
 	for unitHash := range sigSharesBuf {
-		go mp.recoverUnitTBLS(localMed, unitHash)
+		unitHashes = append(unitHashes, unitHash)
 	}
+
+	sortHashes(unitHashes)
+	for _, unitHash := range unitHashes {
+		unitHash := unitHash
+		mp.clock.Go(func() { mp.recoverUnitTBLS(localMed, unitHash) })
+	}
+}
+
+func sortHashes(hashes []common.Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
 }
 
 func (mp *MediatorPlugin) AddToTBLSSignBufs(newHash common.Hash) {
@@ -92,7 +113,8 @@ func (mp *MediatorPlugin) AddToTBLSSignBufs(newHash common.Hash) {
 	for _, localMed := range ms {
 		log.Debugf("the mediator(%v) received a unit(%v) to be group-signed",
 			localMed.Str(), newHash.TerminalString())
-		go mp.addToTBLSSignBuf(localMed, newHash)
+		localMed := localMed
+		mp.clock.Go(func() { mp.addToTBLSSignBuf(localMed, newHash) })
 	}
 }
 
@@ -102,22 +124,20 @@ func (mp *MediatorPlugin) addToTBLSSignBuf(localMed common.Address, unitHash com
 	}
 
 	mp.toTBLSSignBuf[localMed].LoadOrStore(unitHash, true)
-	go mp.signUnitTBLS(localMed, unitHash)
+	mp.clock.Go(func() { mp.signUnitTBLS(localMed, unitHash) })
 
 	// 当 unit 过了确认时间后，及时删除待群签名的 unit，防止内存溢出
-	expiration := mp.dag.UnitIrreversibleTime()
-	deleteBuf := time.NewTimer(expiration)
+	mp.clock.AfterFunc(mp.dag.UnitIrreversibleTime(), func() {
+		if mp.stopped() {
+			return
+		}
 
-	select {
-	case <-mp.quit:
-		return
-	case <-deleteBuf.C:
 		if _, ok := mp.toTBLSSignBuf[localMed].Load(unitHash); ok {
 			log.Debugf("the unit(%v) has expired confirmation time, no longer need the mediator(%v)"+
 				" to sign-group", unitHash.TerminalString(), localMed.Str())
 			mp.toTBLSSignBuf[localMed].Delete(unitHash)
 		}
-	}
+	})
 }
 
 func (mp *MediatorPlugin) SubscribeSigShareEvent(ch chan<- SigShareEvent) event.Subscription {
@@ -135,7 +155,7 @@ func (mp *MediatorPlugin) signUnitTBLS(localMed common.Address, unitHash common.
 	mp.dkgLock.Lock()
 	defer mp.dkgLock.Unlock()
 	var (
-		dkgr      *dkg.DistKeyGenerator
+		dkgr      groupsign.DKG
 		newHeader *modules.Header
 		err       error
 	)
@@ -189,23 +209,32 @@ func (mp *MediatorPlugin) signUnitTBLS(localMed common.Address, unitHash common.
 	}
 
 	// 3. 群签名
-	dks, err := dkgr.DistKeyShare()
+	dks, err := dkgr.KeyShare()
 	if err != nil {
 		log.Debugf(err.Error())
 		return
 	}
 
-	sigShare, err := tbls.Sign(mp.suite, dks.PriShare(), unitHash[:])
+	scheme, err := mp.groupSignScheme()
 	if err != nil {
 		log.Debugf(err.Error())
 		return
 	}
 
+	start := time.Now()
+	sigShare, err := scheme.Sign(dks, unitHash[:])
+	if err != nil {
+		tblsFailMeter.Mark(1)
+		log.Debugf(err.Error())
+		return
+	}
+	tblsSignTimer.UpdateSince(start)
+
 	// 4. 群签名成功后的处理
 	log.Debugf("the mediator(%v) signed-group the unit(%v)", localMed.Str(),
 		unitHash.TerminalString())
 	mp.toTBLSSignBuf[localMed].Delete(unitHash)
-	go mp.sigShareFeed.Send(SigShareEvent{UnitHash: unitHash, SigShare: sigShare})
+	mp.clock.Go(func() { mp.sigShareFeed.Send(SigShareEvent{UnitHash: unitHash, SigShare: sigShare}) })
 }
 
 // 收集签名分片
@@ -247,7 +276,7 @@ func (mp *MediatorPlugin) AddToTBLSRecoverBuf(newUnitHash common.Hash, sigShare
 	sigShareSet.append(sigShare)
 
 	// recover群签名
-	go mp.recoverUnitTBLS(localMed, newUnitHash)
+	mp.clock.Go(func() { mp.recoverUnitTBLS(localMed, newUnitHash) })
 }
 
 func (mp *MediatorPlugin) SubscribeGroupSigEvent(ch chan<- GroupSigEvent) event.Subscription {
@@ -285,7 +314,8 @@ func (mp *MediatorPlugin) recoverUnitTBLS(localMed common.Address, unitHash comm
 	defer mp.dkgLock.Unlock()
 	var (
 		mSize, threshold int
-		dkgr             *dkg.DistKeyGenerator
+		dkgr             groupsign.DKG
+		unitTime         int64
 	)
 
 	{
@@ -298,6 +328,7 @@ func (mp *MediatorPlugin) recoverUnitTBLS(localMed common.Address, unitHash comm
 		}
 
 		// 判断是否是换届前的单元
+		unitTime = unit.Timestamp()
 		if unit.Timestamp() > mp.lastMaintenanceTime {
 			mSize = dag.ActiveMediatorsCount()
 			threshold = dag.ChainThreshold()
@@ -321,20 +352,28 @@ func (mp *MediatorPlugin) recoverUnitTBLS(localMed common.Address, unitHash comm
 		return
 	}
 
-	dks, err := dkgr.DistKeyShare()
+	dks, err := dkgr.KeyShare()
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+
+	scheme, err := mp.groupSignScheme()
 	if err != nil {
 		log.Debugf(err.Error())
 		return
 	}
 
 	// 4. recover群签名
-	suite := mp.suite
-	pubPoly := share.NewPubPoly(suite, suite.Point().Base(), dks.Commitments())
-	groupSig, err := tbls.Recover(suite, pubPoly, unitHash[:], sigShareSet.popSigShares(), threshold, mSize)
+	start := time.Now()
+	groupSig, err := scheme.Recover(dks, unitHash[:], sigShareSet.popSigShares(), threshold, mSize)
 	if err != nil {
+		tblsFailMeter.Mark(1)
 		log.Debugf(err.Error())
 		return
 	}
+	tblsRecoverTimer.UpdateSince(start)
+	tblsLatencyTimer.Update(mp.clock.Now().Sub(time.Unix(unitTime, 0)))
 
 	log.Debugf("Recovered the Unit(%v)'s the Group-sign: %v",
 		unitHash.TerminalString(), hexutil.Encode(groupSig))
@@ -342,6 +381,6 @@ func (mp *MediatorPlugin) recoverUnitTBLS(localMed common.Address, unitHash comm
 	// 5. recover后的相关处理
 	// recover后 删除buf
 	delete(mp.toTBLSRecoverBuf[localMed], unitHash)
-	go mp.dag.SetUnitGroupSign(unitHash, groupSig, mp.ptn.TxPool())
-	go mp.groupSigFeed.Send(GroupSigEvent{UnitHash: unitHash, GroupSig: groupSig})
+	mp.clock.Go(func() { mp.dag.SetUnitGroupSign(unitHash, groupSig, mp.ptn.TxPool()) })
+	mp.clock.Go(func() { mp.groupSigFeed.Send(GroupSigEvent{UnitHash: unitHash, GroupSig: groupSig}) })
 }
@@ -19,93 +19,96 @@
 package mediatorplugin
 
 import (
-	"fmt"
+	"crypto/cipher"
+	"sort"
 	"time"
 
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/event"
 	"github.com/palletone/go-palletone/common/log"
-	"go.dedis.ch/kyber/v3/share/dkg/pedersen"
-	"go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"github.com/palletone/go-palletone/core/groupsign"
 )
 
 func (mp *MediatorPlugin) newDKGAndInitVSSBuf() {
-	mp.dkgLock.RLock()
-	defer mp.dkgLock.RUnlock()
-	mp.vssBufLock.RLock()
-	defer mp.vssBufLock.RUnlock()
+	mp.dkgLock.Lock()
+	defer mp.dkgLock.Unlock()
+	mp.vssBufLock.Lock()
+	defer mp.vssBufLock.Unlock()
 
 	dag := mp.dag
 	lams := mp.GetLocalActiveMediators()
 	initPubs := dag.GetActiveMediatorInitPubs()
 	curThreshold := dag.ChainThreshold()
 
-	lamc := len(lams)
-	mp.activeDKGs = make(map[common.Address]*dkg.DistKeyGenerator, lamc)
+	scheme, err := mp.groupSignScheme()
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
 
-	ams := dag.GetActiveMediators()
-	aSize := len(ams)
+	lamc := len(lams)
+	mp.activeDKGs = make(map[common.Address]groupsign.DKG, lamc)
+	mp.vssMediators = make(map[common.Address]bool, lamc)
+	mp.respBuf = make(map[common.Address][]*groupsign.Response, lamc)
+	mp.respReady = false
 
 	for _, localMed := range lams {
 		initSec := mp.mediators[localMed].InitPrivKey
-		dkgr, err := dkg.NewDistKeyGenerator(mp.suite, initSec, initPubs, curThreshold)
+		var rand cipher.Stream
+		if mp.dkgRand != nil {
+			rand = mp.dkgRand(localMed)
+		}
+		dkgr, err := scheme.NewDKG(initSec, initPubs, curThreshold, rand)
 		if err != nil {
 			log.Debugf(err.Error())
 			continue
 		}
 		mp.activeDKGs[localMed] = dkgr
-
-		mp.dealBuf[localMed] = make(chan *dkg.Deal, aSize-1)
-		mp.respBuf[localMed] = make(map[common.Address]chan *dkg.Response, aSize)
-		for _, vrfrMed := range ams {
-			mp.respBuf[localMed][vrfrMed] = make(chan *dkg.Response, aSize-1)
-		}
+		mp.vssMediators[localMed] = true
 	}
 }
 
 func (mp *MediatorPlugin) startVSSProtocol() {
 	log.Debugf("start completing the VSS protocol")
 
-	// 开启处理其他 mediator 的 deals 的循环，准备完成vss协议
-	mp.launchVSSDealLoops()
+	// 重新开始vss协议后，上一轮还没执行的步骤不再执行
+	mp.vssBufLock.Lock()
+	mp.vssRound++
+	round := mp.vssRound
+	mp.vssBufLock.Unlock()
 
-	interval := mp.dag.GetGlobalProp().ChainParameters.MediatorInterval / 2
+	interval := time.Second * time.Duration(mp.dag.GetGlobalProp().ChainParameters.MediatorInterval/2)
+	step := func(n int, fn func()) {
+		mp.clock.AfterFunc(time.Duration(n)*interval, func() {
+			mp.vssBufLock.RLock()
+			current := mp.vssRound == round
+			mp.vssBufLock.RUnlock()
 
-	// 隔1个生产间隔，等待其他节点接收新unit，并处理好vss协议相关准备工作
-	select {
-	case <-mp.quit:
-		return
-	case <-time.After(time.Second * time.Duration(interval)):
-		mp.broadcastVSSDeals()
+			if mp.stopped() || !current {
+				return
+			}
+			fn()
+		})
 	}
 
-	// 再隔1个生产间隔，处理response
-	select {
-	case <-mp.quit:
-		return
-	case <-time.After(time.Second * time.Duration(interval)):
-		mp.launchVSSRespLoops()
-	}
+	// 隔半个生产间隔，等待其他节点接收新unit，并处理好vss协议相关准备工作
+	step(1, mp.broadcastVSSDeals)
 
-	// 再隔1个生产间隔，验证vss协议是否完成，并开始群签名
-	select {
-	case <-mp.quit:
-		return
-	case <-time.After(time.Second * time.Duration(interval)):
-		mp.completeVSSProtocol()
-	}
+	// 再隔半个生产间隔，处理response
+	step(2, mp.processVSSResps)
+
+	// 再隔半个生产间隔，验证vss协议是否完成，并开始群签名
+	step(3, mp.completeVSSProtocol)
 }
 
 func (mp *MediatorPlugin) completeVSSProtocol() {
-	// 停止所有vss相关的循环
-	mp.stopVSS <- struct{}{}
-
-	// 删除vss相关缓存
-	mp.vssBufLock.RLock()
+	// 删除vss相关缓存，不再接收deal和response
+	mp.vssBufLock.Lock()
 	lamc := len(mp.mediators)
-	mp.dealBuf = make(map[common.Address]chan *dkg.Deal, lamc)
-	mp.respBuf = make(map[common.Address]map[common.Address]chan *dkg.Response, lamc)
-	mp.vssBufLock.RUnlock()
+	mp.vssMediators = make(map[common.Address]bool, lamc)
+	mp.respBuf = make(map[common.Address][]*groupsign.Response, lamc)
+	mp.respReady = false
+	mp.vssBufLock.Unlock()
 
 	// 验证vss是否完成，并开启群签名
 	mp.launchGroupSignLoops()
@@ -126,62 +129,29 @@ func (mp *MediatorPlugin) launchGroupSignLoops() {
 		if dkgr.Certified() {
 			log.Debugf("the mediator(%v)'s DKG verification passed", localMed.Str())
 
-			go mp.signUnitsTBLS(localMed)
-			go mp.recoverUnitsTBLS(localMed)
-		}
-	}
-}
-
-func (mp *MediatorPlugin) launchVSSDealLoops() {
-	lams := mp.GetLocalActiveMediators()
-	//ams := mp.dag.GetActiveMediators()
-
-	for _, localMed := range lams {
-		go mp.processDealLoop(localMed)
-
-		//for _, vrfrMed := range ams {
-		//	go mp.processResponseLoop(localMed, vrfrMed)
-		//}
-	}
-}
-
-func (mp *MediatorPlugin) launchVSSRespLoops() {
-	lams := mp.GetLocalActiveMediators()
-	ams := mp.dag.GetActiveMediators()
-
-	for _, localMed := range lams {
-		for _, vrfrMed := range ams {
-			go mp.processResponseLoop(localMed, vrfrMed)
+			localMed := localMed
+			mp.clock.Go(func() { mp.signUnitsTBLS(localMed) })
+			mp.clock.Go(func() { mp.recoverUnitsTBLS(localMed) })
 		}
 	}
 }
 
-func (mp *MediatorPlugin) processDealLoop(localMed common.Address) {
-	log.Debugf("the local active mediator(%v) run the loop to deal", localMed.Str())
-
+// processVSSResps 进入处理response的阶段，先处理之前缓存的response
+func (mp *MediatorPlugin) processVSSResps() {
 	mp.vssBufLock.Lock()
-	dealCh, ok := mp.dealBuf[localMed]
+	mp.respReady = true
+	respBuf := mp.respBuf
+	mp.respBuf = make(map[common.Address][]*groupsign.Response, len(mp.mediators))
 	mp.vssBufLock.Unlock()
 
-	if !ok {
-		log.Debugf("the mediator(%v)'s dealBuf is not initialized", localMed.Str())
-		return
-	}
-
-	for {
-		select {
-		case <-mp.quit:
-			return
-		case <-mp.stopVSS:
-			return
-		case deal := <-dealCh:
-			// 处理deal，并广播response
-			mp.processVSSDeal(localMed, deal)
+	for localMed, resps := range respBuf {
+		for _, resp := range resps {
+			mp.processVSSResp(localMed, resp)
 		}
 	}
 }
 
-func (mp *MediatorPlugin) processVSSDeal(localMed common.Address, deal *dkg.Deal) {
+func (mp *MediatorPlugin) processVSSDeal(localMed common.Address, deal *groupsign.Deal) {
 	mp.dkgLock.RLock()
 	defer mp.dkgLock.RUnlock()
 
@@ -191,19 +161,13 @@ func (mp *MediatorPlugin) processVSSDeal(localMed common.Address, deal *dkg.Deal
 		return
 	}
 
-	vrfrMed := mp.dag.GetActiveMediatorAddr(int(deal.Index))
+	vrfrMed := mp.dag.GetActiveMediatorAddr(int(deal.Dealer))
 	log.Debugf("the mediator(%v) received the vss deal from the mediator(%v)",
 		localMed.Str(), vrfrMed.Str())
 
 	resp, err := dkgr.ProcessDeal(deal)
 	if err != nil {
-		log.Debugf("dkg: cannot process own deal: " + err.Error())
-		return
-	}
-
-	if resp.Response.Status != vss.StatusApproval {
-		err = fmt.Errorf("DKG: own deal gave a complaint: %v", localMed.String())
-		log.Debugf(err.Error())
+		log.Debugf("DKG: the mediator(%v) cannot process the deal: %v", localMed.String(), err.Error())
 		return
 	}
 
@@ -228,10 +192,17 @@ func (mp *MediatorPlugin) broadcastVSSDeals() {
 		}
 		log.Debugf("the mediator(%v) broadcast vss deals", localMed.Str())
 
-		for index, deal := range deals {
+		// 按mediator的序号依次发送
+		indexes := make([]int, 0, len(deals))
+		for index := range deals {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		for _, index := range indexes {
 			event := VSSDealEvent{
 				DstIndex: uint32(index),
-				Deal:     deal,
+				Deal:     deals[index],
 			}
 			mp.vssDealFeed.Send(event)
 		}
@@ -251,18 +222,21 @@ func (mp *MediatorPlugin) AddToDealBuf(dealEvent *VSSDealEvent) {
 	localMed := dag.GetActiveMediatorAddr(int(dealEvent.DstIndex))
 
 	deal := dealEvent.Deal
-	vrfrMed := dag.GetActiveMediatorAddr(int(deal.Index))
+	vrfrMed := dag.GetActiveMediatorAddr(int(deal.Dealer))
 	log.Debugf("the mediator(%v) received the vss deal from the mediator(%v)",
 		localMed.Str(), vrfrMed.Str())
 
 	mp.vssBufLock.RLock()
-	dealCh, ok := mp.dealBuf[localMed]
+	ok := mp.vssMediators[localMed]
+	mp.vssBufLock.RUnlock()
+
 	if !ok {
-		log.Debugf("the mediator(%v)'s dealBuf is not initialized", localMed.Str())
-	} else {
-		dealCh <- deal
+		log.Debugf("the mediator(%v) is not completing the VSS protocol", localMed.Str())
+		return
 	}
-	mp.vssBufLock.RUnlock()
+
+	// 处理deal，并广播response
+	mp.clock.Go(func() { mp.processVSSDeal(localMed, deal) })
 }
 
 func (mp *MediatorPlugin) SubscribeVSSResponseEvent(ch chan<- VSSResponseEvent) event.Subscription {
@@ -280,55 +254,30 @@ func (mp *MediatorPlugin) AddToResponseBuf(respEvent *VSSResponseEvent) {
 		dag := mp.dag
 
 		// ignore the message from myself
-		srcIndex := resp.Response.Index
-		srcMed := dag.GetActiveMediatorAddr(int(srcIndex))
+		srcMed := dag.GetActiveMediatorAddr(int(resp.Verifier))
 		if srcMed == localMed {
 			continue
 		}
 
-		vrfrMed := dag.GetActiveMediatorAddr(int(resp.Index))
+		vrfrMed := dag.GetActiveMediatorAddr(int(resp.Dealer))
 		log.Debugf("the mediator(%v) received the vss response from the mediator(%v) to the mediator(%v)",
 			localMed.Str(), srcMed.Str(), vrfrMed.Str())
 
-		mp.vssBufLock.RLock()
-		respCh, ok := mp.respBuf[localMed][vrfrMed]
-		if !ok {
-			log.Debugf("the mediator(%v)'s respBuf corresponding the mediator(%v) is not initialized",
-				localMed.Str(), vrfrMed.Str())
+		// 处理response的阶段之前收到的response先缓存
+		mp.vssBufLock.Lock()
+		if !mp.vssMediators[localMed] {
+			log.Debugf("the mediator(%v) is not completing the VSS protocol", localMed.Str())
+		} else if !mp.respReady {
+			mp.respBuf[localMed] = append(mp.respBuf[localMed], resp)
 		} else {
-			respCh <- resp
+			localMed := localMed
+			mp.clock.Go(func() { mp.processVSSResp(localMed, resp) })
 		}
-		mp.vssBufLock.RUnlock()
+		mp.vssBufLock.Unlock()
 	}
 }
 
-func (mp *MediatorPlugin) processResponseLoop(localMed, vrfrMed common.Address) {
-	log.Debugf("the mediator(%v) run the loop to process response regarding the mediator(%v)",
-		localMed.Str(), vrfrMed.Str())
-
-	mp.vssBufLock.Lock()
-	respCh, ok := mp.respBuf[localMed][vrfrMed]
-	mp.vssBufLock.Unlock()
-
-	if !ok {
-		log.Debugf("the mediator(%v)'s respBuf corresponding the mediator(%v) is not initialized",
-			localMed.Str(), vrfrMed.Str())
-		return
-	}
-
-	for {
-		select {
-		case <-mp.quit:
-			return
-		case <-mp.stopVSS:
-			return
-		case resp := <-respCh:
-			mp.processVSSResp(localMed, resp)
-		}
-	}
-}
-
-func (mp *MediatorPlugin) processVSSResp(localMed common.Address, resp *dkg.Response) {
+func (mp *MediatorPlugin) processVSSResp(localMed common.Address, resp *groupsign.Response) {
 	mp.dkgLock.RLock()
 	defer mp.dkgLock.RUnlock()
 
@@ -338,14 +287,9 @@ func (mp *MediatorPlugin) processVSSResp(localMed common.Address, resp *dkg.Resp
 		return
 	}
 
-	jstf, err := dkgr.ProcessResponse(resp)
+	err := dkgr.ProcessResponse(resp)
 	if err != nil {
-		log.Debugf(err.Error())
-		return
-	}
-
-	if jstf != nil {
-		log.Debugf("DKG: wrong Process Response: %v", localMed.String())
+		log.Debugf("DKG: wrong Process Response: %v, %v", localMed.String(), err.Error())
 		return
 	}
 }
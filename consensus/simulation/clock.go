@@ -0,0 +1,123 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package simulation
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/palletone/go-palletone/common/mclock"
+)
+
+//Clock 虚拟时钟，所有的出块、消息投递和超时都是时钟上的事件，
+//事件按时间顺序执行，同一时间的事件按加入的顺序执行，因此每次运行的结果都相同。
+//mediatorplugin和jury的定时和异步调用也作为事件在时钟上执行
+type Clock struct {
+	now    time.Time
+	seq    uint64
+	events eventQueue
+
+	// 每个事件执行之后调用，用于投递事件中产生的消息
+	afterEvent func()
+}
+
+var _ mclock.Clock = (*Clock)(nil)
+
+type clockEvent struct {
+	at  time.Time
+	seq uint64
+	fn  func()
+}
+
+type eventQueue []*clockEvent
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*clockEvent)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
+
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current virtual time.
+func (c *Clock) Now() time.Time {
+	return c.now
+}
+
+// Schedule runs fn at the given time, a time in the past runs it at the current time.
+func (c *Clock) Schedule(at time.Time, fn func()) {
+	if at.Before(c.now) {
+		at = c.now
+	}
+	c.seq++
+	heap.Push(&c.events, &clockEvent{at: at, seq: c.seq, fn: fn})
+}
+
+// AfterFunc runs fn after the duration of virtual time.
+func (c *Clock) AfterFunc(d time.Duration, fn func()) {
+	c.Schedule(c.now.Add(d), fn)
+}
+
+// Go runs fn at the current time, after the events already scheduled for it.
+func (c *Clock) Go(fn func()) {
+	c.Schedule(c.now, fn)
+}
+
+// Pending returns the number of events not run yet.
+func (c *Clock) Pending() int {
+	return c.events.Len()
+}
+
+// RunUntil runs all the events up to and including the time, then sets the clock to it.
+func (c *Clock) RunUntil(end time.Time) {
+	for c.events.Len() > 0 && !c.events[0].at.After(end) {
+		e := heap.Pop(&c.events).(*clockEvent)
+		c.now = e.at
+		e.fn()
+		if c.afterEvent != nil {
+			c.afterEvent()
+		}
+	}
+	if end.After(c.now) {
+		c.now = end
+	}
+}
+
+// Run advances the clock by the duration.
+func (c *Clock) Run(d time.Duration) {
+	c.RunUntil(c.now.Add(d))
+}
@@ -0,0 +1,93 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/consensus/jury"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/storage"
+)
+
+// 选举陪审员时部署的合约模板，只用于选举，模拟节点不执行合约
+var simTplId = crypto.Keccak256([]byte("palletone simulation template"))
+
+//Election 一次陪审员选举的结果。发起节点的jury收集到足够的签名后请求执行合约，
+//此时记录选出的陪审员和VRF证明
+type Election struct {
+	ReqId  common.Hash
+	Num    uint
+	Jurors []common.Address
+	Proofs [][]byte
+}
+
+// Done reports whether enough jurors have been elected.
+func (e *Election) Done() bool {
+	return e.Num > 0 && uint(len(e.Jurors)) >= e.Num
+}
+
+//saveElectionTpl 在创世之后写入合约模板，部署请求的选举需要模板存在
+func saveElectionTpl(db ptndb.Database) error {
+	return storage.NewStateDb(db).SaveContractTpl(&modules.ContractTemplate{
+		TplId:   simTplId,
+		TplName: "simulation",
+	})
+}
+
+// ElectJury lets the first node request a contract deployment, which starts a jury election
+// in the real jury processors of all the nodes. The result is filled in as the virtual clock runs.
+func (s *Simulation) ElectJury() (*Election, error) {
+	node := s.Nodes[0]
+	if node.down {
+		return nil, fmt.Errorf("node %v is down", node.index)
+	}
+
+	cp := node.dag.GetChainParameters()
+	num := cp.ContractElectionNum
+	if num < 1 {
+		num = core.DefaultContractElectionNum
+	}
+	reqId, _, err := node.contractProc.ContractDeployReq(node.addr, node.addr, 0, 10*cp.TransferPtnBaseFee,
+		simTplId, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Election{ReqId: reqId, Num: uint(num)}
+	s.elections[reqId] = e
+	return e, nil
+}
+
+//recordElection 按请求执行合约的事件中的选举结果记录陪审员
+func (s *Simulation) recordElection(event *jury.ContractEvent) {
+	e, ok := s.elections[event.Tx.RequestHash()]
+	if !ok || e.Done() {
+		return
+	}
+
+	for _, ele := range event.Ele.EleList {
+		e.Jurors = append(e.Jurors, crypto.PubkeyBytesToAddress(ele.PublicKey))
+		e.Proofs = append(e.Proofs, ele.Proof)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package simulation
+
+import (
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+)
+
+//节点之间传递的消息，都按RLP编码传递，保证每个节点持有自己的副本。
+//同步单元之外的消息与ptn协议中对应的消息内容相同
+type (
+	unitMsg struct {
+		data []byte
+	}
+	getUnitsMsg struct {
+		hash         common.Hash
+		stableHeight uint64
+	}
+	unitsMsg struct {
+		data [][]byte
+	}
+	vssDealMsg struct {
+		data []byte
+	}
+	vssResponseMsg struct {
+		data []byte
+	}
+	sigShareMsg struct {
+		data []byte
+	}
+	groupSigMsg struct {
+		data []byte
+	}
+	contractMsg struct {
+		data []byte
+	}
+	electionMsg struct {
+		data []byte
+	}
+)
+
+//Network 模拟节点之间的网络，可以设置链路延迟、网络分区，宕机的节点收不到也发不出消息。
+//消息在发送和到达时都要检查连通性，分区期间在途的消息会丢失
+type Network struct {
+	clock  *Clock
+	nodes  []*Node
+	delay  time.Duration
+	delays map[[2]int]time.Duration
+	groups []int
+
+	Sent      int // 发送的消息数
+	Delivered int // 到达的消息数
+}
+
+func newNetwork(clock *Clock, delay time.Duration) *Network {
+	return &Network{
+		clock:  clock,
+		delay:  delay,
+		delays: make(map[[2]int]time.Duration),
+	}
+}
+
+func (n *Network) addNode(node *Node) {
+	n.nodes = append(n.nodes, node)
+	n.groups = append(n.groups, 0)
+}
+
+// SetDelay sets the default delay of all links.
+func (n *Network) SetDelay(d time.Duration) {
+	n.delay = d
+}
+
+// SetLinkDelay sets the delay of the link between the two nodes in both directions.
+func (n *Network) SetLinkDelay(a, b int, d time.Duration) {
+	n.delays[[2]int{a, b}] = d
+	n.delays[[2]int{b, a}] = d
+}
+
+func (n *Network) linkDelay(from, to int) time.Duration {
+	if d, ok := n.delays[[2]int{from, to}]; ok {
+		return d
+	}
+	return n.delay
+}
+
+// Partition splits the network, nodes can only reach the nodes of the same group.
+// Nodes not listed in any group can only reach each other.
+func (n *Network) Partition(groups ...[]int) {
+	for i := range n.groups {
+		n.groups[i] = 0
+	}
+	for g, group := range groups {
+		for _, i := range group {
+			n.groups[i] = g + 1
+		}
+	}
+}
+
+// Heal removes all the partitions.
+func (n *Network) Heal() {
+	n.Partition()
+}
+
+// Connected reports whether a message from one node can reach the other.
+func (n *Network) Connected(from, to int) bool {
+	if n.nodes[from].down || n.nodes[to].down {
+		return false
+	}
+	return n.groups[from] == n.groups[to]
+}
+
+func (n *Network) send(from, to int, msg interface{}) {
+	if from == to || !n.Connected(from, to) {
+		return
+	}
+	n.Sent++
+	n.clock.AfterFunc(n.linkDelay(from, to), func() {
+		if !n.Connected(from, to) {
+			return
+		}
+		n.Delivered++
+		n.nodes[to].handleMsg(from, msg)
+	})
+}
+
+func (n *Network) broadcast(from int, msg interface{}) {
+	for to := range n.nodes {
+		n.send(from, to, msg)
+	}
+}
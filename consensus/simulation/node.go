@@ -0,0 +1,650 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package simulation
+
+import (
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/event"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/consensus/jury"
+	"github.com/palletone/go-palletone/consensus/mediatorplugin"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/core/accounts/keystore"
+	"github.com/palletone/go-palletone/dag"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/txspool"
+	"github.com/palletone/go-palletone/tokenengine"
+	"go.dedis.ch/kyber/v3/xof/blake2xb"
+)
+
+// 订阅插件事件的缓冲大小，事件在每个时钟事件执行之后才投递，缓冲满了插件会阻塞
+const eventChanSize = 1024
+
+//Node 模拟的gptn节点，每个节点有独立的Dag和一个mediator账户，运行真实的mediatorplugin和jury，
+//只是时间和异步调用取自虚拟时钟，消息通过模拟网络传递。
+//节点按ptn的方式在插件、jury和网络之间转发事件
+type Node struct {
+	index int
+	sim   *Simulation
+
+	addr     common.Address
+	ecdsaKey *ecdsa.PrivateKey
+	initSec  []byte
+	initPub  []byte
+	ksDir    string
+	ks       *keystore.KeyStore
+	dag      *dag.Dag
+	txpool   txspool.ITxPool
+
+	producer     *mediatorplugin.MediatorPlugin
+	contractProc *jury.Processor
+
+	// 订阅的插件事件
+	subs       []event.Subscription
+	unitCh     chan mediatorplugin.NewProducedUnitEvent
+	dealCh     chan mediatorplugin.VSSDealEvent
+	respCh     chan mediatorplugin.VSSResponseEvent
+	sigShareCh chan mediatorplugin.SigShareEvent
+	groupSigCh chan mediatorplugin.GroupSigEvent
+
+	down                bool
+	lastMaintenanceTime int64
+	dkgCount            uint64               // 已生成的DKG数量，用于确定DKG的随机数种子
+	received            map[common.Hash]bool // 收到过的合约和选举事件
+
+	Produced    int // 本节点生产的单元数
+	GroupSigned int // 本节点恢复出群签名的单元数
+}
+
+var (
+	_ mediatorplugin.PalletOne = (*Node)(nil)
+	_ jury.PalletOne           = (*Node)(nil)
+)
+
+// Index returns the index of the node in the simulation.
+func (n *Node) Index() int {
+	return n.index
+}
+
+// Address returns the account address of the node's mediator.
+func (n *Node) Address() common.Address {
+	return n.addr
+}
+
+// Dag returns the node's own dag.
+func (n *Node) Dag() *dag.Dag {
+	return n.dag
+}
+
+// Down reports whether the node is crashed.
+func (n *Node) Down() bool {
+	return n.down
+}
+
+// Head returns the last unit of the node's main chain.
+func (n *Node) Head() *modules.Unit {
+	return n.dag.Memdag.GetLastMainChainUnit()
+}
+
+// StableHeight returns the height of the node's last irreversible unit.
+func (n *Node) StableHeight() uint64 {
+	_, height := n.dag.Memdag.GetLastStableUnitInfo()
+	return height
+}
+
+// IsActive reports whether the node's mediator is active in its own view of the chain.
+func (n *Node) IsActive() bool {
+	return n.dag.IsActiveMediator(n.addr)
+}
+
+//start 与gptn启动时相同，按配置创建mediatorplugin和jury，并开始调度生产单元。
+//stale为true时不等待同步就开始生产，只在创世时使用
+func (n *Node) start(stale bool) error {
+	mcfg := &mediatorplugin.Config{
+		Mediators: []*mediatorplugin.MediatorConf{{
+			Address:     n.addr.String(),
+			InitPrivKey: core.InitKeyToStr(n.initSec),
+			InitPubKey:  core.InitKeyToStr(n.initPub),
+		}},
+		RequiredParticipation: n.sim.config.RequiredParticipation,
+		EnableProducing:       true,
+		EnableStaleProduction: stale,
+		EnableGroupSigning:    true,
+	}
+	producer, err := mediatorplugin.NewMediatorPlugin(mcfg, n, n.dag)
+	if err != nil {
+		return err
+	}
+	producer.SetClock(n.sim.Clock)
+	producer.SetDKGRand(n.dkgRand)
+
+	jcfg := &jury.Config{
+		Accounts:         []*jury.AccountConf{{Address: n.addr.String()}},
+		OutChainKeyStore: filepath.Join(n.ksDir, "outchain.keystore"),
+		OutChainPassword: "simulation",
+	}
+	contractProc, err := jury.NewContractProcessor(n, n.dag, nil, jcfg)
+	if err != nil {
+		return err
+	}
+	contractProc.SetClock(n.sim.Clock)
+
+	n.producer, n.contractProc = producer, contractProc
+	n.unitCh = make(chan mediatorplugin.NewProducedUnitEvent, eventChanSize)
+	n.dealCh = make(chan mediatorplugin.VSSDealEvent, eventChanSize)
+	n.respCh = make(chan mediatorplugin.VSSResponseEvent, eventChanSize)
+	n.sigShareCh = make(chan mediatorplugin.SigShareEvent, eventChanSize)
+	n.groupSigCh = make(chan mediatorplugin.GroupSigEvent, eventChanSize)
+	n.subs = []event.Subscription{
+		producer.SubscribeNewProducedUnitEvent(n.unitCh),
+		producer.SubscribeVSSDealEvent(n.dealCh),
+		producer.SubscribeVSSResponseEvent(n.respCh),
+		producer.SubscribeSigShareEvent(n.sigShareCh),
+		producer.SubscribeGroupSigEvent(n.groupSigCh),
+	}
+	n.received = make(map[common.Hash]bool)
+	n.lastMaintenanceTime = n.dag.LastMaintenanceTime()
+
+	return producer.Start(nil)
+}
+
+//stop 与gptn退出时相同，插件和jury在内存中的状态全部丢失
+func (n *Node) stop() {
+	for _, sub := range n.subs {
+		sub.Unsubscribe()
+	}
+	n.subs = nil
+	n.producer.Stop()
+	n.contractProc.Stop()
+}
+
+//dkgRand DKG的随机数取自节点的初始私钥和换届时间，使群公钥和单元每次运行都相同
+func (n *Node) dkgRand(localMed common.Address) cipher.Stream {
+	n.dkgCount++
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf, uint64(n.dag.LastMaintenanceTime()))
+	binary.BigEndian.PutUint64(buf[8:], n.dkgCount)
+	return blake2xb.New(crypto.Keccak256(n.initSec, localMed.Bytes(), buf))
+}
+
+func (n *Node) GetKeyStore() *keystore.KeyStore {
+	return n.ks
+}
+
+func (n *Node) TxPool() txspool.ITxPool {
+	return n.txpool
+}
+
+func (n *Node) ContractProcessor() *jury.Processor {
+	return n.contractProc
+}
+
+func (n *Node) LocalHaveActiveMediator() bool {
+	return n.producer.LocalHaveActiveMediator()
+}
+
+func (n *Node) GetLocalActiveMediators() []common.Address {
+	return n.producer.GetLocalActiveMediators()
+}
+
+//SignGenericTransaction 与ptn相同，用keystore中的私钥签名交易的所有付款输入
+func (n *Node) SignGenericTransaction(from common.Address, tx *modules.Transaction) (*modules.Transaction, error) {
+	inputpoints := make(map[modules.OutPoint][]byte)
+	for _, msg := range tx.TxMessages {
+		if msg.App != modules.APP_PAYMENT {
+			continue
+		}
+		payload, ok := msg.Payload.(*modules.PaymentPayload)
+		if !ok {
+			continue
+		}
+		for _, txin := range payload.Inputs {
+			utxo, err := n.dag.GetUtxoEntry(txin.PreviousOutPoint)
+			if err != nil {
+				return nil, err
+			}
+			inputpoints[*txin.PreviousOutPoint] = utxo.PkScript
+		}
+	}
+
+	_, err := tokenengine.Instance.SignTxAllPaymentInput(tx, tokenengine.SigHashAll, inputpoints, nil,
+		n.ks.GetPublicKey, n.ks.SignMessage)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (n *Node) MockContractLocalSend(event jury.ContractEvent) {
+}
+
+//ContractBroadcast 与ptn相同，把合约事件发给所有相连的节点，local为true时本节点也处理。
+//模拟节点不执行合约，选举完成后请求执行合约的事件只交给Simulation记录选出的陪审员
+func (n *Node) ContractBroadcast(event jury.ContractEvent, local bool) {
+	if n.down {
+		return
+	}
+	if event.CType == jury.CONTRACT_EVENT_EXEC && event.Ele != nil {
+		n.sim.recordElection(&event)
+		return
+	}
+
+	data, err := rlp.EncodeToBytes(&event)
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	n.received[event.Hash()] = true
+	n.sim.Network.broadcast(n.index, &contractMsg{data: data})
+
+	if local {
+		proc := n.contractProc
+		n.sim.Clock.Go(func() {
+			if err := proc.ProcessContractEvent(&event); err != nil {
+				log.Debugf("node %v: ProcessContractEvent err: %v", n.index, err.Error())
+			}
+		})
+	}
+}
+
+//ElectionBroadcast 与ptn相同，选举事件按ElectionEventBytes的格式发给所有相连的节点
+func (n *Node) ElectionBroadcast(event jury.ElectionEvent, local bool) {
+	if n.down {
+		return
+	}
+	evs, err := event.ToElectionEventBytes()
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	data, err := rlp.EncodeToBytes(evs)
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	n.received[evs.Hash()] = true
+	n.sim.Network.broadcast(n.index, &electionMsg{data: data})
+
+	if local {
+		proc := n.contractProc
+		n.sim.Clock.Go(func() { proc.ProcessElectionEvent(&event) })
+	}
+}
+
+//AdapterBroadcast 模拟节点不运行链外适配器
+func (n *Node) AdapterBroadcast(event jury.AdapterEvent) {
+}
+
+func (n *Node) handleMsg(from int, msg interface{}) {
+	switch msg := msg.(type) {
+	case *unitMsg:
+		n.handleUnit(from, msg.data)
+	case *getUnitsMsg:
+		n.handleGetUnits(from, msg)
+	case *unitsMsg:
+		n.handleUnits(msg)
+	case *vssDealMsg:
+		n.handleVSSDeal(msg.data)
+	case *vssResponseMsg:
+		n.handleVSSResponse(msg.data)
+	case *sigShareMsg:
+		n.handleSigShare(msg.data)
+	case *groupSigMsg:
+		n.handleGroupSig(msg.data)
+	case *contractMsg:
+		n.handleContract(msg.data)
+	case *electionMsg:
+		n.handleElection(msg.data)
+	}
+}
+
+func decodeUnit(data []byte) (*modules.Unit, error) {
+	unit := new(modules.Unit)
+	if err := rlp.DecodeBytes(data, unit); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+//isKnownUnit 判断单元是否已经在本地的MemDag或稳定数据库中
+func (n *Node) isKnownUnit(hash common.Hash) bool {
+	stableHash, _ := n.dag.Memdag.GetLastStableUnitInfo()
+	if hash == stableHash {
+		return true
+	}
+	if _, ok := n.dag.Memdag.GetChainUnits()[hash]; ok {
+		return true
+	}
+	return n.dag.IsIrreversibleUnit(hash)
+}
+
+func (n *Node) handleUnit(from int, data []byte) {
+	unit, err := decodeUnit(data)
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+
+	if n.isKnownUnit(unit.UnitHash) || unit.NumberU64() <= n.StableHeight() {
+		return
+	}
+
+	// 父单元未知，则向发送者请求从本地稳定单元之后的所有祖先单元
+	if !n.isKnownUnit(unit.ParentHash()[0]) {
+		n.sim.Network.send(n.index, from, &getUnitsMsg{hash: unit.UnitHash, stableHeight: n.StableHeight()})
+		return
+	}
+
+	n.insertUnits(modules.Units{unit})
+}
+
+func (n *Node) handleGetUnits(from int, msg *getUnitsMsg) {
+	chainUnits := n.dag.Memdag.GetChainUnits()
+	units := make([]*modules.Unit, 0)
+	hash := msg.hash
+	for {
+		unit, ok := chainUnits[hash]
+		if !ok {
+			var err error
+			unit, err = n.dag.GetUnitByHash(hash)
+			if err != nil {
+				break
+			}
+		}
+		if unit == nil || unit.NumberU64() <= msg.stableHeight {
+			break
+		}
+		units = append(units, unit)
+		hash = unit.ParentHash()[0]
+	}
+
+	data := make([][]byte, 0, len(units))
+	for i := len(units) - 1; i >= 0; i-- {
+		b, err := rlp.EncodeToBytes(units[i])
+		if err != nil {
+			log.Debugf(err.Error())
+			return
+		}
+		data = append(data, b)
+	}
+	if len(data) != 0 {
+		n.sim.Network.send(n.index, from, &unitsMsg{data: data})
+	}
+}
+
+func (n *Node) handleUnits(msg *unitsMsg) {
+	units := make(modules.Units, 0, len(msg.data))
+	for _, b := range msg.data {
+		unit, err := decodeUnit(b)
+		if err != nil {
+			log.Debugf(err.Error())
+			return
+		}
+		if n.isKnownUnit(unit.UnitHash) || unit.NumberU64() <= n.StableHeight() {
+			continue
+		}
+		units = append(units, unit)
+	}
+
+	if len(units) != 0 {
+		n.insertUnits(units)
+	}
+}
+
+func (n *Node) insertUnits(units modules.Units) {
+	if _, err := n.dag.InsertDag(units, n.txpool, false); err != nil {
+		log.Debugf("node %v: InsertDag err: %v", n.index, err.Error())
+		return
+	}
+	n.afterDagChanged()
+}
+
+//afterDagChanged 本地Dag有新单元或者新的群签名之后调用。
+//Dag在协程中发送换届和待群签名的事件，模拟节点不订阅，而是按ptn处理这些事件的方式直接检查
+func (n *Node) afterDagChanged() {
+	if lmt := n.dag.LastMaintenanceTime(); lmt != n.lastMaintenanceTime {
+		n.lastMaintenanceTime = lmt
+		// 同步完成的活跃mediator节点在换届后更新DKG，开始新一届的vss协议
+		if n.dag.IsSynced() && n.producer.LocalHaveActiveMediator() {
+			n.producer.UpdateMediatorsDKG(true)
+		}
+	}
+
+	n.toGroupSign()
+}
+
+//toGroupSign 与ptn相同，对最新稳定单元的下一个单元做群签名
+func (n *Node) toGroupSign() {
+	if !n.dag.IsSynced() {
+		return
+	}
+	if !n.producer.LocalHaveActiveMediator() && !n.producer.LocalHavePrecedingMediator() {
+		return
+	}
+
+	gasToken := dagconfig.DagConfig.GetGasToken()
+	iun := n.dag.GetIrreversibleUnitNum(gasToken)
+	hash, err := n.dag.GetUnitHash(&modules.ChainIndex{AssetID: gasToken, Index: iun + 1})
+	if err != nil {
+		return
+	}
+	n.producer.AddToTBLSSignBufs(hash)
+}
+
+//deliverEvents 把插件产生的单元、vss协议和群签名消息发送出去。
+//按事件类型依次取出，不使用select，保证投递的顺序每次运行都相同
+func (n *Node) deliverEvents() {
+	for len(n.unitCh) > 0 {
+		ev := <-n.unitCh
+		n.broadcastUnit(ev.Unit)
+	}
+	for len(n.dealCh) > 0 {
+		ev := <-n.dealCh
+		n.transmitVSSDeal(&ev)
+	}
+	for len(n.respCh) > 0 {
+		ev := <-n.respCh
+		n.broadcastVSSResp(&ev)
+	}
+	for len(n.sigShareCh) > 0 {
+		ev := <-n.sigShareCh
+		n.transmitSigShare(&ev)
+	}
+	for len(n.groupSigCh) > 0 {
+		ev := <-n.groupSigCh
+		n.broadcastGroupSig(&ev)
+	}
+}
+
+func encodeMsg(val interface{}) []byte {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		log.Debugf(err.Error())
+		return nil
+	}
+	return data
+}
+
+func (n *Node) broadcastUnit(unit *modules.Unit) {
+	n.Produced++
+	if data := encodeMsg(unit); data != nil {
+		n.sim.Network.broadcast(n.index, &unitMsg{data: data})
+	}
+	n.afterDagChanged()
+}
+
+//transmitVSSDeal 把deal发给对应序号的活跃mediator
+func (n *Node) transmitVSSDeal(deal *mediatorplugin.VSSDealEvent) {
+	// 没有同步完成时，发起的vss deal是无效的
+	if !n.dag.IsSynced() {
+		return
+	}
+
+	dst := n.sim.NodeByMediator(n.dag.GetActiveMediatorAddr(int(deal.DstIndex)))
+	if dst == nil {
+		return
+	}
+	if dst == n {
+		n.producer.AddToDealBuf(deal)
+		return
+	}
+	if data := encodeMsg(deal); data != nil {
+		n.sim.Network.send(n.index, dst.index, &vssDealMsg{data: data})
+	}
+}
+
+func (n *Node) broadcastVSSResp(resp *mediatorplugin.VSSResponseEvent) {
+	data := encodeMsg(resp)
+	if data == nil {
+		return
+	}
+
+	for _, med := range n.dag.GetActiveMediators() {
+		dst := n.sim.NodeByMediator(med)
+		if dst == nil {
+			continue
+		}
+		if dst == n {
+			n.producer.AddToResponseBuf(resp)
+			continue
+		}
+		n.sim.Network.send(n.index, dst.index, &vssResponseMsg{data: data})
+	}
+}
+
+//transmitSigShare 把签名分片发给单元的生产者，由生产者恢复群签名
+func (n *Node) transmitSigShare(sigShare *mediatorplugin.SigShareEvent) {
+	header, err := n.dag.GetHeaderByHash(sigShare.UnitHash)
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+
+	dst := n.sim.NodeByMediator(header.Author())
+	if dst == nil {
+		return
+	}
+	if dst == n {
+		n.producer.AddToTBLSRecoverBuf(sigShare.UnitHash, sigShare.SigShare)
+		return
+	}
+	if data := encodeMsg(sigShare); data != nil {
+		n.sim.Network.send(n.index, dst.index, &sigShareMsg{data: data})
+	}
+}
+
+//broadcastGroupSig 插件已经把群签名写入本地Dag
+func (n *Node) broadcastGroupSig(groupSig *mediatorplugin.GroupSigEvent) {
+	n.GroupSigned++
+	if data := encodeMsg(groupSig); data != nil {
+		n.sim.Network.broadcast(n.index, &groupSigMsg{data: data})
+	}
+	n.afterDagChanged()
+}
+
+func (n *Node) handleVSSDeal(data []byte) {
+	var deal mediatorplugin.VSSDealEvent
+	if err := rlp.DecodeBytes(data, &deal); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	// 没有同步完成时，收到的vss deal也是无效的
+	if !n.dag.IsSynced() {
+		return
+	}
+	n.producer.AddToDealBuf(&deal)
+}
+
+func (n *Node) handleVSSResponse(data []byte) {
+	var resp mediatorplugin.VSSResponseEvent
+	if err := rlp.DecodeBytes(data, &resp); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	n.producer.AddToResponseBuf(&resp)
+}
+
+func (n *Node) handleSigShare(data []byte) {
+	var sigShare mediatorplugin.SigShareEvent
+	if err := rlp.DecodeBytes(data, &sigShare); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	n.producer.AddToTBLSRecoverBuf(sigShare.UnitHash, sigShare.SigShare)
+}
+
+func (n *Node) handleGroupSig(data []byte) {
+	var groupSig mediatorplugin.GroupSigEvent
+	if err := rlp.DecodeBytes(data, &groupSig); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	if err := n.dag.SetUnitGroupSign(groupSig.UnitHash, groupSig.GroupSig, n.txpool); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	n.afterDagChanged()
+}
+
+func (n *Node) handleContract(data []byte) {
+	var event jury.ContractEvent
+	if err := rlp.DecodeBytes(data, &event); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	hash := event.Hash()
+	if n.received[hash] {
+		return
+	}
+	n.received[hash] = true
+
+	if err := n.contractProc.ProcessContractEvent(&event); err != nil {
+		log.Debugf("node %v: ProcessContractEvent err: %v", n.index, err.Error())
+	}
+}
+
+func (n *Node) handleElection(data []byte) {
+	var evs jury.ElectionEventBytes
+	if err := rlp.DecodeBytes(data, &evs); err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	hash := evs.Hash()
+	if n.received[hash] {
+		return
+	}
+	n.received[hash] = true
+
+	event, err := evs.ToElectionEvent()
+	if err != nil {
+		log.Debugf(err.Error())
+		return
+	}
+	if _, err := n.contractProc.ProcessElectionEvent(event); err != nil {
+		log.Debugf("node %v: ProcessElectionEvent err: %v", n.index, err.Error())
+	}
+}
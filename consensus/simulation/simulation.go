@@ -0,0 +1,346 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+// Package simulation runs several in-process mediator nodes on a virtual clock and a simulated network,
+// so that consensus scenarios (forks, partitions, crashed mediators, maintenance) can be reproduced in go test.
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/p2p/discover"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/consensus/mediatorplugin"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/core/accounts"
+	"github.com/palletone/go-palletone/core/accounts/keystore"
+	"github.com/palletone/go-palletone/core/gen"
+	"github.com/palletone/go-palletone/core/groupsign"
+	"github.com/palletone/go-palletone/dag"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+	"go.dedis.ch/kyber/v3/xof/blake2xb"
+)
+
+type Config struct {
+	Mediators             int           // mediator节点的数量，每个节点一个mediator
+	ActiveMediators       int           // 活跃mediator的数量，必须是奇数
+	MediatorInterval      uint8         // 生产单元的间隔，单位秒
+	MaintenanceInterval   uint32        // 换届的间隔，单位秒，必须是MediatorInterval的整数倍
+	Delay                 time.Duration // 默认的网络延迟
+	RequiredParticipation uint32        // 生产单元要求的最低mediator参与率，单位为百分之一
+	GenesisTime           time.Time     // 创世单元的时间，必须是MediatorInterval的整数倍
+}
+
+var DefaultConfig = Config{
+	Mediators:             5,
+	ActiveMediators:       5,
+	MediatorInterval:      core.DefaultMediatorInterval,
+	MaintenanceInterval:   core.DefaultMaintenanceInterval,
+	Delay:                 100 * time.Millisecond,
+	RequiredParticipation: mediatorplugin.DefaultRequiredParticipation,
+	GenesisTime:           time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC),
+}
+
+//Simulation 一组使用同一个创世单元的模拟节点，节点按mediator地址排序，
+//所以前ActiveMediators个节点是初始的活跃mediator
+type Simulation struct {
+	Clock   *Clock
+	Network *Network
+	Nodes   []*Node
+
+	config      Config
+	nodesByAddr map[common.Address]*Node
+	elections   map[common.Hash]*Election
+}
+
+// New creates the nodes and the genesis unit, starts the mediator plugin and jury of every node,
+// then starts the first VSS protocol at the genesis time.
+// Keys are derived from the node index, so every run produces the same units.
+func New(config Config) (*Simulation, error) {
+	if config.Mediators == 0 {
+		config.Mediators = DefaultConfig.Mediators
+	}
+	if config.ActiveMediators == 0 {
+		config.ActiveMediators = config.Mediators
+	}
+	if config.MediatorInterval == 0 {
+		config.MediatorInterval = DefaultConfig.MediatorInterval
+	}
+	if config.MaintenanceInterval == 0 {
+		config.MaintenanceInterval = DefaultConfig.MaintenanceInterval
+	}
+	if config.RequiredParticipation == 0 {
+		config.RequiredParticipation = DefaultConfig.RequiredParticipation
+	}
+	if config.GenesisTime.IsZero() {
+		config.GenesisTime = DefaultConfig.GenesisTime
+	}
+	if config.ActiveMediators > config.Mediators || config.ActiveMediators&1 == 0 {
+		return nil, fmt.Errorf("invalid active mediator count: %v", config.ActiveMediators)
+	}
+	if config.GenesisTime.Unix()%int64(config.MediatorInterval) != 0 ||
+		config.MaintenanceInterval%uint32(config.MediatorInterval) != 0 {
+		return nil, fmt.Errorf("genesis time and maintenance interval must be divisible by mediator interval")
+	}
+
+	clock := NewClock(config.GenesisTime)
+	sim := &Simulation{
+		Clock:       clock,
+		Network:     newNetwork(clock, config.Delay),
+		config:      config,
+		nodesByAddr: make(map[common.Address]*Node, config.Mediators),
+		elections:   make(map[common.Hash]*Election),
+	}
+	clock.afterEvent = sim.deliverEvents
+
+	nodes := make([]*Node, 0, config.Mediators)
+	for i := 0; i < config.Mediators; i++ {
+		node, err := newNode(sim, i)
+		if err != nil {
+			sim.closeNodes(nodes)
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return bytes.Compare(nodes[i].addr.Bytes(), nodes[j].addr.Bytes()) < 0
+	})
+
+	if err := sim.initDags(nodes); err != nil {
+		sim.closeNodes(nodes)
+		return nil, err
+	}
+
+	for i, node := range nodes {
+		node.index = i
+		sim.Nodes = append(sim.Nodes, node)
+		sim.nodesByAddr[node.addr] = node
+		sim.Network.addNode(node)
+	}
+
+	for _, node := range sim.Nodes {
+		if err := node.start(true); err != nil {
+			sim.Close()
+			return nil, err
+		}
+	}
+	// 创世单元没有换届事件，由节点直接开始第一次vss协议
+	for _, node := range sim.Nodes {
+		node.producer.UpdateMediatorsDKG(true)
+	}
+
+	return sim, nil
+}
+
+func newNode(sim *Simulation, i int) (*Node, error) {
+	seed := crypto.Keccak256([]byte(fmt.Sprintf("palletone simulation mediator %d", i)))
+	ecdsaKey, err := crypto.ToECDSA(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	// mediator的群签名初始公私钥也由种子确定
+	scheme, err := groupsign.Lookup(core.DefaultGroupSignScheme)
+	if err != nil {
+		return nil, err
+	}
+	initSec, initPub, err := scheme.GenerateKeyPair(blake2xb.New(seed))
+	if err != nil {
+		return nil, err
+	}
+
+	ksDir, err := ioutil.TempDir("", "simulation-keystore")
+	if err != nil {
+		return nil, err
+	}
+	ks := keystore.NewPlaintextKeyStore(ksDir)
+	account, err := ks.ImportECDSA(crypto.FromECDSA(ecdsaKey), "")
+	if err == nil {
+		err = ks.Unlock(account, "")
+	}
+	if err != nil {
+		os.RemoveAll(ksDir)
+		return nil, err
+	}
+
+	return &Node{
+		sim:      sim,
+		addr:     account.Address,
+		ecdsaKey: ecdsaKey,
+		initSec:  initSec,
+		initPub:  initPub,
+		ksDir:    ksDir,
+		ks:       ks,
+		txpool:   emptyTxPool{},
+	}, nil
+}
+
+func (s *Simulation) genesis(nodes []*Node) *core.Genesis {
+	genesis := gen.DefaultGenesisBlock()
+	genesis.GasToken = dagconfig.DefaultToken
+	genesis.TokenHolder = nodes[0].addr.String()
+	genesis.InitialTimestamp = s.config.GenesisTime.Unix()
+	genesis.InitialParameters.ActiveMediatorCount = uint8(s.config.ActiveMediators)
+	genesis.InitialParameters.MediatorInterval = s.config.MediatorInterval
+	genesis.InitialParameters.MaintenanceInterval = s.config.MaintenanceInterval
+
+	genesis.InitialMediatorCandidates = make([]*core.InitialMediator, 0, len(nodes))
+	for i, node := range nodes {
+		port := uint16(30303 + i)
+		imc := core.NewInitialMediator()
+		imc.AddStr = node.addr.String()
+		imc.RewardAdd = node.addr.String()
+		imc.InitPubKey = core.InitKeyToStr(node.initPub)
+		imc.Node = discover.NewNode(discover.PubkeyID(&node.ecdsaKey.PublicKey),
+			net.IP{127, 0, 0, 1}, port, port).String()
+		genesis.InitialMediatorCandidates = append(genesis.InitialMediatorCandidates, imc)
+	}
+
+	return genesis
+}
+
+//initDags 每个节点使用内存数据库，按gptn init的步骤写入同一个创世单元
+func (s *Simulation) initDags(nodes []*Node) error {
+	genesis := s.genesis(nodes)
+	unit, err := gen.SetupGenesisUnit(genesis, nodes[0].ks, accounts.Account{Address: nodes[0].addr})
+	if err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(unit)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		db, err := ptndb.NewMemDatabase()
+		if err != nil {
+			return err
+		}
+		initDag, err := dag.NewDag4GenesisInit(db)
+		if err != nil {
+			return err
+		}
+
+		unit := new(modules.Unit)
+		if err := rlp.DecodeBytes(data, unit); err != nil {
+			return err
+		}
+		if err := initDag.SaveUnit(unit, nil, true); err != nil {
+			return err
+		}
+		if err := initDag.InitStateDB(genesis, unit); err != nil {
+			return err
+		}
+		if err := initDag.InitPropertyDB(genesis, unit); err != nil {
+			return err
+		}
+		if err := saveElectionTpl(db); err != nil {
+			return err
+		}
+
+		node.dag, err = dag.NewDag(db, freecache.NewCache(1000*1024), false)
+		if err != nil {
+			return err
+		}
+		node.dag.RefreshSysParameters()
+		node.dag.SetClock(s.Clock)
+	}
+
+	return nil
+}
+
+func (s *Simulation) interval() time.Duration {
+	return time.Duration(s.config.MediatorInterval) * time.Second
+}
+
+//deliverEvents 每个时钟事件执行之后，按序号依次投递各节点插件产生的消息
+func (s *Simulation) deliverEvents() {
+	for _, node := range s.Nodes {
+		if !node.down {
+			node.deliverEvents()
+		}
+	}
+}
+
+// Run advances the virtual clock by the duration.
+func (s *Simulation) Run(d time.Duration) {
+	s.Clock.Run(d)
+}
+
+// RunSlots advances the virtual clock by the number of mediator intervals.
+func (s *Simulation) RunSlots(slots int) {
+	s.Clock.Run(time.Duration(slots) * s.interval())
+}
+
+// NodeByMediator returns the node controlling the mediator, or nil.
+func (s *Simulation) NodeByMediator(addr common.Address) *Node {
+	return s.nodesByAddr[addr]
+}
+
+// Crash stops the node's mediator plugin and jury, it neither produces units nor sends or receives messages.
+func (s *Simulation) Crash(i int) {
+	node := s.Nodes[i]
+	if node.down {
+		return
+	}
+	node.down = true
+	node.stop()
+}
+
+// Restart brings a crashed node back like a restarted gptn process: the dag is kept,
+// the mediator plugin and jury start anew, and production waits until the node has caught up.
+// As in gptn, the node takes part in group signing again after the next maintenance.
+func (s *Simulation) Restart(i int) error {
+	node := s.Nodes[i]
+	if !node.down {
+		return nil
+	}
+	node.down = false
+	return node.start(false)
+}
+
+// Close stops the running nodes and releases the dags and keystores of all the nodes.
+func (s *Simulation) Close() {
+	for _, node := range s.Nodes {
+		if !node.down && node.producer != nil {
+			node.down = true
+			node.stop()
+		}
+	}
+	s.closeNodes(s.Nodes)
+}
+
+func (s *Simulation) closeNodes(nodes []*Node) {
+	for _, node := range nodes {
+		if node.dag != nil {
+			node.dag.Close()
+		}
+		os.RemoveAll(node.ksDir)
+	}
+}
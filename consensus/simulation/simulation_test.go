@@ -0,0 +1,243 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimulation(t *testing.T, config Config) *Simulation {
+	sim, err := New(config)
+	require.Nil(t, err)
+	return sim
+}
+
+//settle 让最后一个生产槽广播的消息到达
+func settle(sim *Simulation) {
+	sim.Run(time.Second)
+}
+
+func assertSameHead(t *testing.T, sim *Simulation) {
+	head := sim.Nodes[0].Head()
+	for _, node := range sim.Nodes[1:] {
+		if node.Down() {
+			continue
+		}
+		assert.Equal(t, head.UnitHash, node.Head().UnitHash, "node %v", node.Index())
+	}
+}
+
+func TestClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewClock(start)
+
+	var order []int
+	c.AfterFunc(2*time.Second, func() { order = append(order, 2) })
+	c.AfterFunc(time.Second, func() {
+		order = append(order, 1)
+		c.AfterFunc(time.Second, func() { order = append(order, 3) })
+	})
+	c.Schedule(start.Add(-time.Second), func() { order = append(order, 0) })
+
+	c.Run(time.Second)
+	assert.Equal(t, []int{0, 1}, order)
+	assert.Equal(t, start.Add(time.Second), c.Now())
+
+	c.Run(5 * time.Second)
+	assert.Equal(t, []int{0, 1, 2, 3}, order)
+	assert.Equal(t, start.Add(6*time.Second), c.Now())
+	assert.Equal(t, 0, c.Pending())
+}
+
+func TestProduceAndGroupSign(t *testing.T) {
+	sim := newSimulation(t, DefaultConfig)
+	defer sim.Close()
+
+	sim.RunSlots(30)
+	settle(sim)
+
+	assertSameHead(t, sim)
+	for _, node := range sim.Nodes {
+		assert.Equal(t, uint64(30), node.Head().NumberU64())
+		assert.True(t, node.StableHeight() >= 29, "node %v", node.Index())
+		assert.True(t, node.Produced > 0)
+		assert.True(t, node.GroupSigned > 0)
+	}
+}
+
+//TestDeterministic 同样的场景运行两次，每个节点的主链和统计都相同
+func TestDeterministic(t *testing.T) {
+	type nodeState struct {
+		head                  common.Hash
+		stable                uint64
+		produced, groupSigned int
+	}
+	run := func() ([]nodeState, int, int) {
+		sim := newSimulation(t, DefaultConfig)
+		defer sim.Close()
+
+		sim.Network.SetLinkDelay(0, 1, 700*time.Millisecond)
+		sim.RunSlots(5)
+		sim.Network.Partition([]int{0, 1, 2}, []int{3, 4})
+		sim.RunSlots(10)
+		sim.Network.Heal()
+		sim.RunSlots(10)
+		settle(sim)
+
+		states := make([]nodeState, 0, len(sim.Nodes))
+		for _, node := range sim.Nodes {
+			states = append(states, nodeState{
+				head:        node.Head().UnitHash,
+				stable:      node.StableHeight(),
+				produced:    node.Produced,
+				groupSigned: node.GroupSigned,
+			})
+		}
+		return states, sim.Network.Sent, sim.Network.Delivered
+	}
+
+	states1, sent1, delivered1 := run()
+	states2, sent2, delivered2 := run()
+	assert.Equal(t, states1, states2)
+	assert.Equal(t, sent1, sent2)
+	assert.Equal(t, delivered1, delivered2)
+}
+
+//TestForkSwitch 网络分区后两边各自延伸分叉链，都达不到稳定的阈值，
+//恢复后少数一方的MemDag切换到更长的主链上，稳定高度继续增长
+func TestForkSwitch(t *testing.T) {
+	sim := newSimulation(t, DefaultConfig)
+	defer sim.Close()
+
+	sim.RunSlots(5)
+	settle(sim)
+	assertSameHead(t, sim)
+	stable := sim.Nodes[0].StableHeight()
+
+	sim.Network.Partition([]int{0, 1, 2}, []int{3, 4})
+	sim.RunSlots(15)
+	settle(sim)
+
+	majority, minority := sim.Nodes[0].Head(), sim.Nodes[3].Head()
+	assert.NotEqual(t, majority.UnitHash, minority.UnitHash)
+	assert.True(t, majority.NumberU64() > minority.NumberU64())
+	for _, node := range sim.Nodes {
+		assert.True(t, node.StableHeight() <= stable+1, "node %v", node.Index())
+	}
+
+	sim.Network.Heal()
+	sim.RunSlots(10)
+	settle(sim)
+
+	assertSameHead(t, sim)
+	for _, node := range sim.Nodes {
+		assert.True(t, node.StableHeight() > majority.NumberU64(), "node %v", node.Index())
+		assert.False(t, node.Dag().IsIrreversibleUnit(minority.UnitHash), "node %v", node.Index())
+	}
+}
+
+//TestMaintenanceRotation 一个活跃mediator宕机后连续错过太多生产槽，换届时由备选mediator替换，
+//新一届完成VSS协议后单元重新稳定，并继续产生群签名
+func TestMaintenanceRotation(t *testing.T) {
+	config := DefaultConfig
+	config.Mediators = 4
+	config.ActiveMediators = 3
+	config.MaintenanceInterval = 60
+	sim := newSimulation(t, config)
+	defer sim.Close()
+
+	standby := sim.Nodes[3]
+	assert.False(t, standby.IsActive())
+
+	sim.RunSlots(10)
+	crashed := sim.Nodes[1]
+	assert.True(t, crashed.IsActive())
+	sim.Crash(crashed.Index())
+
+	// 剩下两个活跃mediator达不到稳定的阈值
+	stable := sim.Nodes[0].StableHeight()
+	for i := 0; i < 15 && !standby.IsActive(); i++ {
+		sim.Run(time.Minute)
+		if !standby.IsActive() {
+			assert.Equal(t, stable, sim.Nodes[0].StableHeight())
+		}
+	}
+	require.True(t, standby.IsActive())
+
+	for _, node := range sim.Nodes {
+		if node.Down() {
+			continue
+		}
+		d := node.Dag()
+		assert.False(t, d.IsActiveMediator(crashed.Address()), "node %v", node.Index())
+		assert.True(t, d.IsActiveMediator(standby.Address()), "node %v", node.Index())
+		assert.True(t, node.StableHeight() > stable, "node %v", node.Index())
+	}
+
+	signed := standby.GroupSigned
+	sim.RunSlots(10)
+	settle(sim)
+	assertSameHead(t, sim)
+	assert.True(t, standby.GroupSigned > signed)
+}
+
+//TestRestart 宕机的节点重启后同步到最新的单元，并重新参与生产
+func TestRestart(t *testing.T) {
+	sim := newSimulation(t, DefaultConfig)
+	defer sim.Close()
+
+	sim.RunSlots(5)
+	sim.Crash(2)
+	sim.RunSlots(10)
+	produced := sim.Nodes[2].Produced
+
+	require.Nil(t, sim.Restart(2))
+	sim.RunSlots(15)
+	settle(sim)
+
+	assertSameHead(t, sim)
+	assert.True(t, sim.Nodes[2].Produced > produced)
+	assert.Equal(t, sim.Nodes[0].StableHeight(), sim.Nodes[2].StableHeight())
+}
+
+//TestElectJury 发起节点的jury通过真实的选举流程选出陪审员，分区另一边的节点不会当选
+func TestElectJury(t *testing.T) {
+	sim := newSimulation(t, DefaultConfig)
+	defer sim.Close()
+
+	sim.RunSlots(2)
+	sim.Network.Partition([]int{0, 1, 2, 3}, []int{4})
+	e, err := sim.ElectJury()
+	require.Nil(t, err)
+	sim.RunSlots(5)
+
+	require.True(t, e.Done())
+	assert.Equal(t, int(e.Num), len(e.Jurors))
+	assert.Equal(t, len(e.Jurors), len(e.Proofs))
+	for _, juror := range e.Jurors {
+		node := sim.NodeByMediator(juror)
+		require.NotNil(t, node)
+		assert.True(t, sim.Network.Connected(0, node.Index()) || node.Index() == 0)
+	}
+}
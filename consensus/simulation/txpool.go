@@ -0,0 +1,111 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package simulation
+
+import (
+	"errors"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/event"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/txspool"
+)
+
+var errNoTxPool = errors.New("the simulated node has no transaction pool")
+
+//emptyTxPool 模拟节点只生产空单元，MemDag会在协程中回调交易池，所以不能传nil
+type emptyTxPool struct{}
+
+var _ txspool.ITxPool = emptyTxPool{}
+
+func (emptyTxPool) Stop() {}
+
+func (emptyTxPool) AddLocal(tx *modules.Transaction) error { return errNoTxPool }
+
+func (emptyTxPool) AddLocals(txs []*modules.Transaction) []error { return nil }
+
+func (emptyTxPool) AddSequenTx(tx *modules.Transaction) error { return errNoTxPool }
+
+func (emptyTxPool) AddSequenTxs(txs []*modules.Transaction) error { return errNoTxPool }
+
+func (emptyTxPool) AllHashs() []*common.Hash { return nil }
+
+func (emptyTxPool) AllTxpoolTxs() map[common.Hash]*modules.TxPoolTransaction { return nil }
+
+func (emptyTxPool) AddRemote(tx *modules.Transaction) error { return errNoTxPool }
+
+func (emptyTxPool) AddRemotes([]*modules.Transaction) []error { return nil }
+
+func (emptyTxPool) ProcessTransaction(tx *modules.Transaction, allowOrphan bool, rateLimit bool,
+	tag txspool.Tag) ([]*txspool.TxDesc, error) {
+	return nil, errNoTxPool
+}
+
+func (emptyTxPool) Pending() (map[common.Hash][]*modules.TxPoolTransaction, error) { return nil, nil }
+
+func (emptyTxPool) Queued() ([]*modules.TxPoolTransaction, error) { return nil, nil }
+
+func (emptyTxPool) SetPendingTxs(unitHash common.Hash, num uint64, txs []*modules.Transaction) error {
+	return nil
+}
+
+func (emptyTxPool) ResetPendingTxs(txs []*modules.Transaction) error { return nil }
+
+func (emptyTxPool) SendStoredTxs(hashs []common.Hash) error { return nil }
+
+func (emptyTxPool) DiscardTxs(hashs []common.Hash) error { return nil }
+
+func (emptyTxPool) GetUtxoEntry(outpoint *modules.OutPoint) (*modules.Utxo, error) {
+	return nil, errNoTxPool
+}
+
+func (emptyTxPool) SubscribeTxPreEvent(chan<- modules.TxPreEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+func (emptyTxPool) GetSortedTxs(hash common.Hash, index uint64) ([]*modules.TxPoolTransaction,
+	common.StorageSize) {
+	return nil, 0
+}
+
+func (emptyTxPool) Get(hash common.Hash) (*modules.TxPoolTransaction, common.Hash) {
+	return nil, common.Hash{}
+}
+
+func (emptyTxPool) GetPoolTxsByAddr(addr string) ([]*modules.TxPoolTransaction, error) {
+	return nil, nil
+}
+
+func (emptyTxPool) Stats() (int, int, int) { return 0, 0, 0 }
+
+func (emptyTxPool) Content() (map[common.Hash]*modules.TxPoolTransaction,
+	map[common.Hash]*modules.TxPoolTransaction) {
+	return nil, nil
+}
+
+func (emptyTxPool) GetTxFee(tx *modules.Transaction) (*modules.AmountAsset, error) {
+	return nil, errNoTxPool
+}
+
+func (emptyTxPool) OutPointIsSpend(outPoint *modules.OutPoint) (bool, error) { return false, nil }
+
+func (emptyTxPool) ValidateOrphanTx(tx *modules.Transaction) (bool, error) { return false, nil }
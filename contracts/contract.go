@@ -46,7 +46,7 @@ type ContractInf interface {
 	Close() error
 	Install(chainID string, ccName string, ccPath string, ccVersion string, ccDescription, ccAbi, ccLanguage string) (payload *md.ContractTplPayload, err error)
 	Deploy(rwM rwset.TxManager, chainID string, templateId []byte, txId string, args [][]byte, timeout time.Duration) (deployId []byte, deployPayload *md.ContractDeployPayload, e error)
-	Invoke(rwM rwset.TxManager, chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration) (*md.ContractInvokeResult, error)
+	Invoke(rwM rwset.TxManager, chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration, costLimit uint64) (*md.ContractInvokeResult, error)
 	Stop(rwM rwset.TxManager, chainID string, deployId []byte, txid string, deleteImage bool) (*md.ContractStopPayload, error)
 }
 
@@ -140,7 +140,8 @@ func (c *Contract) Deploy(rwM rwset.TxManager, chainID string, templateId []byte
 // Invoke 合约invoke调用，根据指定合约调用参数执行已经部署的合约，函数返回合约调用单元。
 // The contract invoke call, execute the deployed contract according to the specified contract call parameters,
 // and the function returns the contract call unit.
-func (c *Contract) Invoke(rwM rwset.TxManager, chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration) (*md.ContractInvokeResult, error) {
+// costLimit为用户合约执行的cost上限，为0时使用默认值，系统合约不计价
+func (c *Contract) Invoke(rwM rwset.TxManager, chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration, costLimit uint64) (*md.ContractInvokeResult, error) {
 	log.Info("Enter Contract Invoke====", "chainID", chainID, "deployId", deployId, "txid", txid, "timeout", timeout)
 	defer log.Info("Exit Contract Invoke====", "chainID", chainID, "deployId", deployId, "txid", txid, "timeout", timeout)
 	atomic.LoadInt32(&initFlag)
@@ -152,7 +153,7 @@ func (c *Contract) Invoke(rwM rwset.TxManager, chainID string, deployId []byte,
 		log.Info("contract test invoke")
 		return test.Invoke(rwM, c.dag, chainID, deployId, txid, args)
 	}
-	return cc.Invoke(rwM, c.dag, chainID, deployId, txid, args, timeout)
+	return cc.Invoke(rwM, c.dag, chainID, deployId, txid, args, timeout, costLimit)
 }
 
 // Stop 停止指定合约。根据需求可以对镜像文件进行删除操作
@@ -3,6 +3,7 @@ package contractcfg
 import (
 	"fmt"
 	"github.com/palletone/go-palletone/configure"
+	"strings"
 	"time"
 )
 
@@ -15,6 +16,12 @@ const (
 	Nodejsimg = "palletone/nodejsimg"
 )
 
+//用户合约的运行方式
+const (
+	UccVmDocker  = "docker"
+	UccVmProcess = "process"
+)
+
 var DefaultConfig = Config{
 	//LogLevel:               logging.DEBUG,
 	//ContractFileSystemPath: "./chaincodes",
@@ -28,6 +35,12 @@ var DefaultConfig = Config{
 	//NodejsBuilder:          "palletone/nodejsimg",
 	VmEndpoint:  "unix:///var/run/docker.sock",
 	SysContract: map[string]string{"deposit_syscc": "true", "sample_syscc": "true", "createToken_sycc": "true"},
+
+	UccVm:              UccVmDocker,
+	UccProcessMemory:   1073741824, //1GB
+	UccProcessMaxFiles: 1024,
+	UccProcessUid:      65534, //nobody
+	UccProcessGid:      65534,
 }
 
 type Config struct {
@@ -41,6 +54,14 @@ type Config struct {
 	//NodejsBuilder          string        //Nodejs基础镜像
 	VmEndpoint  string //与docker服务连接协议
 	SysContract map[string]string
+
+	UccVm              string //用户合约运行方式，docker或process，process方式不依赖docker
+	UccProcessDir      string //process方式下合约进程的工作目录，为空时使用系统临时目录
+	UccProcessMemory   int64  //process方式下合约进程的虚拟内存上限(字节)，0表示不限制
+	UccProcessCpuTime  int64  //process方式下合约进程的CPU时间上限(秒)，0表示不限制
+	UccProcessMaxFiles int64  //process方式下合约进程可打开的文件数上限，0表示不限制
+	UccProcessUid      uint32 //process方式下合约进程的用户，节点以root运行时生效，否则在user namespace中运行
+	UccProcessGid      uint32 //process方式下合约进程的用户组
 	//LogLevel               logging.Level
 	//ContractFileSystemPath string
 	//vm.docker.attachStdout
@@ -62,3 +83,8 @@ func GetConfig() *Config {
 	//}
 	return &contractCfg
 }
+
+//IsProcessVm 用户合约是否以子进程的方式运行
+func (cfg *Config) IsProcessVm() bool {
+	return strings.EqualFold(cfg.UccVm, UccVmProcess)
+}
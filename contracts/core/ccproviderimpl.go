@@ -105,6 +105,15 @@ func (c *ccProviderImpl) Stop(ctxt context.Context, cccid interface{}, spec *pb.
 	return errors.New("Stop:ChaincodeSupport not initialized")
 }
 
+// Launch starts the chaincode given context and spec if it is not running
+func (c *ccProviderImpl) Launch(ctxt context.Context, cccid interface{}, spec *pb.ChaincodeDeploymentSpec) error {
+	if theChaincodeSupport != nil {
+		_, _, err := theChaincodeSupport.Launch(ctxt, cccid.(*ccProviderContextImpl).ctx, spec)
+		return err
+	}
+	return errors.New("Launch:ChaincodeSupport not initialized")
+}
+
 func (c *ccProviderImpl) Destroy(ctxt context.Context, cccid interface{}, spec *pb.ChaincodeDeploymentSpec) error {
 	if theChaincodeSupport != nil {
 		return theChaincodeSupport.Destroy(ctxt, cccid.(*ccProviderContextImpl).ctx, spec)
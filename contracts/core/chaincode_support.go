@@ -43,6 +43,7 @@ import (
 	"github.com/palletone/go-palletone/vm/api"
 	"github.com/palletone/go-palletone/vm/ccintf"
 	"github.com/palletone/go-palletone/vm/controller"
+	"github.com/palletone/go-palletone/vm/wasmcontroller"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
@@ -429,6 +430,9 @@ func (chaincodeSupport *ChaincodeSupport) getLaunchConfigs(cccid *ccprovider.CCC
 		args = []string{"java", "-jar", "chaincode.jar", "--peerAddress", chaincodeSupport.peerAddress}
 	case pb.ChaincodeSpec_NODE:
 		args = []string{"/bin/sh", "-c", fmt.Sprintf("cd /usr/local/src; npm start -- --peer.address %s", chaincodeSupport.peerAddress)}
+	case pb.ChaincodeSpec_WASM:
+		//WASM合约在节点进程中运行，不需要启动命令
+		args = []string{wasmcontroller.CodeFileName}
 
 	default:
 		return nil, nil, nil, errors.Errorf("unknown chaincodeType: %s", cLang)
@@ -828,6 +832,13 @@ func (chaincodeSupport *ChaincodeSupport) getVMType(cds *pb.ChaincodeDeploymentS
 	if cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM {
 		return controller.SYSTEM
 	}
+	if cds.ChaincodeSpec != nil && cds.ChaincodeSpec.Type == pb.ChaincodeSpec_WASM {
+		return controller.WASM
+	}
+	//节点配置为不使用docker时用户合约以子进程方式运行
+	if cfg.GetConfig().IsProcessVm() {
+		return controller.PROCESS
+	}
 	return controller.DOCKER
 }
 
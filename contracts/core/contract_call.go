@@ -0,0 +1,320 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/looplab/fsm"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/comm"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/core/vmContractPub/ccprovider"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+//CallTreeKey is used to attach the contract call tree of an invocation
+const CallTreeKey key = "contractcalltree"
+
+//MaxContractCallDepth 合约调用合约的最大深度，根合约的深度为0
+const MaxContractCallDepth = 8
+
+//CalledContract 被调用的合约，Name是合约在txsimulator中的namespace
+type CalledContract struct {
+	Name       string
+	ContractId []byte
+}
+
+//ContractCallTree 一次合约调用中由合约调用合约形成的调用树。
+//所有合约共用同一个txsimulator，被调用合约的读写集和付款合并到根合约的调用结果中，
+//任何一个被调用的合约失败，整个调用都失败。
+//整个调用树共用根合约的超时时间，被调用合约只能使用剩余的时间
+type ContractCallTree struct {
+	lock     sync.Mutex
+	root     []byte
+	deadline time.Time
+	//正在执行的合约，从根合约开始
+	stack  [][]byte
+	called []*CalledContract
+	err    error
+}
+
+func NewContractCallTree(root []byte, timeout time.Duration) *ContractCallTree {
+	return &ContractCallTree{root: root, deadline: time.Now().Add(timeout), stack: [][]byte{root}}
+}
+
+func getCallTree(ctxt context.Context) *ContractCallTree {
+	if tree, ok := ctxt.Value(CallTreeKey).(*ContractCallTree); ok {
+		return tree
+	}
+	return nil
+}
+
+//记录被调用的合约，同一个合约被多次调用时只记录一次
+func (t *ContractCallTree) addCall(name string, contractId []byte) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if bytes.Equal(contractId, t.root) {
+		return
+	}
+	for _, c := range t.called {
+		if bytes.Equal(c.ContractId, contractId) {
+			return
+		}
+	}
+	t.called = append(t.called, &CalledContract{Name: name, ContractId: contractId})
+}
+
+//进入被调用的合约，超过最大深度、重入正在执行的合约或者已经超时都返回错误，
+//成功时返回被调用合约剩余的执行时间
+func (t *ContractCallTree) enter(contractId []byte) (time.Duration, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.stack) > MaxContractCallDepth {
+		return 0, fmt.Errorf("contract call depth exceeds %d", MaxContractCallDepth)
+	}
+	for _, id := range t.stack {
+		if bytes.Equal(id, contractId) {
+			return 0, fmt.Errorf("reentrant call to contract %x is not allowed", contractId)
+		}
+	}
+	remaining := time.Until(t.deadline)
+	if remaining <= 0 {
+		return 0, errors.New("contract call deadline exceeded")
+	}
+	t.stack = append(t.stack, contractId)
+	return remaining, nil
+}
+
+//被调用的合约执行结束
+func (t *ContractCallTree) leave() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.stack) > 1 {
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+}
+
+//Called 按第一次调用的顺序返回被调用的合约，不包含根合约
+func (t *ContractCallTree) Called() []*CalledContract {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]*CalledContract{}, t.called...)
+}
+
+//记录第一个失败的被调用合约，即使调用者忽略了这个错误，整个调用也会失败
+func (t *ContractCallTree) fail(err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *ContractCallTree) Err() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.err
+}
+
+//被调用合约从自己的地址付款时，付款由根合约的陪审团签名，所以两个合约的陪审团必须相同
+func (t *ContractCallTree) checkPayout(contractId []byte,
+	getJury func(contractId []byte) (*modules.ElectionNode, error)) error {
+	if bytes.Equal(contractId, t.root) {
+		return nil
+	}
+	rootJury, err := getJury(t.root)
+	if err != nil {
+		return fmt.Errorf("get jury of contract %x error:%s", t.root, err)
+	}
+	jury, err := getJury(contractId)
+	if err != nil {
+		return fmt.Errorf("get jury of contract %x error:%s", contractId, err)
+	}
+	if !sameJury(rootJury, jury) {
+		return fmt.Errorf("called contract %x has a different jury, cannot pay out", contractId)
+	}
+	return nil
+}
+
+func sameJury(a, b *modules.ElectionNode) bool {
+	if a == nil || b == nil || len(a.EleList) != len(b.EleList) {
+		return false
+	}
+	keys := func(ele *modules.ElectionNode) [][]byte {
+		ks := make([][]byte, 0, len(ele.EleList))
+		for _, e := range ele.EleList {
+			ks = append(ks, e.PublicKey)
+		}
+		sort.Slice(ks, func(i, j int) bool { return bytes.Compare(ks[i], ks[j]) < 0 })
+		return ks
+	}
+	ka, kb := keys(a), keys(b)
+	for i := range ka {
+		if !bytes.Equal(ka[i], kb[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//在调用者的txsimulator中执行被调用合约，附带的Token先从调用合约付给被调用合约。
+//被调用合约通过GetInvokeParameters得到的调用地址是调用合约的地址。
+//进入被调用合约后的任何错误都使整个调用失败，已经写入txsimulator的部分付款不会被提交
+func (handler *Handler) invokeContract(txContext *transactionContext, msg *pb.ChaincodeMessage,
+	req *pb.InvokeContract) (res *pb.Response, err error) {
+	tree := txContext.callTree
+	if tree == nil || txContext.txsimulator == nil {
+		return nil, errors.New("contract call is not supported in this context")
+	}
+	caller := common.NewAddress(msg.ContractId, common.ContractHash)
+	callee := common.NewAddress(req.ContractId, common.ContractHash)
+	if caller.IsSystemContractAddress() || callee.IsSystemContractAddress() {
+		return nil, errors.New("system contract cannot be used in contract call")
+	}
+	timeout, err := tree.enter(req.ContractId)
+	if err != nil {
+		tree.fail(err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tree.fail(err)
+		}
+		tree.leave()
+	}()
+	idag, err := comm.GetCcDagHand()
+	if err != nil || idag == nil {
+		return nil, errors.New("dag is not available")
+	}
+	cc, err := idag.GetChaincode(callee)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("get contract %s error", callee.String()))
+	}
+
+	if len(req.Tokens) > 0 {
+		if err := tree.checkPayout(msg.ContractId, idag.GetContractJury); err != nil {
+			return nil, err
+		}
+	}
+	invokeTokens := make([]*modules.InvokeTokens, 0, len(req.Tokens))
+	for _, token := range req.Tokens {
+		asset := &modules.Asset{}
+		if err := asset.SetBytes(token.Asset); err != nil {
+			return nil, err
+		}
+		err := txContext.txsimulator.PayOutToken(handler.getCCRootName(), callee.String(), asset, token.Amount, 0)
+		if err != nil {
+			return nil, err
+		}
+		invokeTokens = append(invokeTokens,
+			&modules.InvokeTokens{Amount: token.Amount, Asset: asset, Address: callee.String()})
+	}
+	invokeInfo, err := json.Marshal(&modules.InvokeInfo{InvokeAddress: caller, InvokeTokens: invokeTokens})
+	if err != nil {
+		return nil, err
+	}
+	//与用户调用相同的参数格式：调用信息，证书ID，合约参数
+	args := append([][]byte{invokeInfo, nil}, req.Args...)
+
+	tree.addCall(cc.Name, req.ContractId)
+	ctxt := context.WithValue(context.Background(), TXSimulatorKey, txContext.txsimulator)
+	ctxt = context.WithValue(ctxt, CallTreeKey, tree)
+	cccid := ccprovider.NewCCContext(req.ContractId, msg.ChannelId, cc.Name, cc.Version, msg.Txid, false,
+		txContext.signedProp, txContext.proposal)
+	spec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value[cc.Language]),
+		ChaincodeId: &pb.ChaincodeID{Name: cc.Name, Version: cc.Version},
+		Input:       &pb.ChaincodeInput{Args: args},
+	}}
+	log.Debugf("[%s]contract %s call contract %s", shorttxid(msg.Txid), caller.String(), callee.String())
+	res, _, err = Execute(ctxt, cccid, spec, timeout)
+	if err == nil && res.Status >= shim.ERRORTHRESHOLD {
+		err = errors.New(res.Message)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("call contract %s failed", callee.String()))
+	}
+	return res, nil
+}
+
+func (handler *Handler) enterInvokeContract(e *fsm.Event) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(errors.New("received unexpected message type"))
+		return
+	}
+	log.Debugf("[%s]Received %s, invoking contract", shorttxid(msg.Txid), pb.ChaincodeMessage_INVOKE_CONTRACT)
+
+	handler.handleInvokeContract(msg)
+}
+
+func (handler *Handler) handleInvokeContract(msg *pb.ChaincodeMessage) {
+	go func() {
+		uniqueReq := handler.createTXIDEntry(msg.ChannelId, msg.Txid)
+		if !uniqueReq {
+			log.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+		var txContext *transactionContext
+		txContext, serialSendMsg = handler.isValidTxSim(msg.ChannelId, msg.Txid,
+			"[%s]No ledger context for InvokeContract. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.ChannelId, msg.Txid)
+			log.Debugf("[%s]handleInvokeContract serial send %s",
+				shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSendAsync(serialSendMsg, nil)
+		}()
+
+		if txContext == nil {
+			return
+		}
+		req := &pb.InvokeContract{}
+		err := proto.Unmarshal(msg.Payload, req)
+		var res []byte
+		if err == nil {
+			var resp *pb.Response
+			if resp, err = handler.invokeContract(txContext, msg, req); err == nil {
+				res, err = proto.Marshal(resp)
+			}
+		}
+		if err != nil {
+			log.Errorf("[%s]Failed to invoke contract(%s). Sending %s",
+				shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()),
+				Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res,
+			Txid: msg.Txid, ChannelId: msg.ChannelId}
+	}()
+}
@@ -0,0 +1,118 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/palletone/go-palletone/contracts/comm"
+	"github.com/palletone/go-palletone/contracts/list"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/core/vmContractPub/sysccprovider"
+	"github.com/palletone/go-palletone/dag"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/rwset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractCallTreeEnter(t *testing.T) {
+	tree := NewContractCallTree([]byte{0}, time.Minute)
+
+	//重入根合约
+	_, err := tree.enter([]byte{0})
+	assert.NotNil(t, err)
+
+	for i := 1; i <= MaxContractCallDepth; i++ {
+		remaining, err := tree.enter([]byte{byte(i)})
+		assert.Nil(t, err)
+		assert.True(t, remaining > 0 && remaining <= time.Minute)
+	}
+	//超过最大深度
+	_, err = tree.enter([]byte{0xff})
+	assert.NotNil(t, err)
+
+	//重入正在执行的合约
+	tree.leave()
+	_, err = tree.enter([]byte{1})
+	assert.NotNil(t, err)
+	//已经返回的合约可以再次调用
+	_, err = tree.enter([]byte{byte(MaxContractCallDepth)})
+	assert.Nil(t, err)
+}
+
+func TestContractCallTreeDeadline(t *testing.T) {
+	tree := NewContractCallTree([]byte{0}, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	_, err := tree.enter([]byte{1})
+	assert.NotNil(t, err)
+}
+
+//第二次付款失败，用于检查部分完成的付款
+type payOutFailSimulator struct {
+	rwset.TxSimulator
+	payouts int
+}
+
+func (s *payOutFailSimulator) PayOutToken(ns string, address string, token *modules.Asset, amount uint64,
+	lockTime uint32) error {
+	s.payouts++
+	if s.payouts > 1 {
+		return errors.New("insufficient balance")
+	}
+	return nil
+}
+
+func TestInvokeContractFailsTree(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mdag := dag.NewMockIDag(mockCtrl)
+	comm.SetCcDagHand(mdag)
+	defer comm.SetCcDagHand(nil)
+
+	caller, callee := bytes.Repeat([]byte{0xaa}, 20), bytes.Repeat([]byte{0xbb}, 20)
+	handler := &Handler{ccInstance: &sysccprovider.ChaincodeInstance{ChaincodeName: "caller"}}
+	msg := &pb.ChaincodeMessage{ContractId: caller}
+	newContext := func() *transactionContext {
+		return &transactionContext{txsimulator: &payOutFailSimulator{},
+			callTree: NewContractCallTree(caller, time.Minute)}
+	}
+
+	//找不到被调用的合约
+	mdag.EXPECT().GetChaincode(gomock.Any()).Return(nil, errors.New("not found"))
+	txContext := newContext()
+	_, err := handler.invokeContract(txContext, msg, &pb.InvokeContract{ContractId: callee})
+	assert.NotNil(t, err)
+	assert.Equal(t, err, txContext.callTree.Err())
+	assert.Equal(t, 1, len(txContext.callTree.stack))
+
+	//第一笔付款已经写入txsimulator后第二笔失败，调用者忽略错误时整个调用也要失败
+	mdag.EXPECT().GetChaincode(gomock.Any()).Return(&list.CCInfo{Name: "callee"}, nil)
+	txContext = newContext()
+	ptn := modules.NewPTNAsset().Bytes()
+	_, err = handler.invokeContract(txContext, msg, &pb.InvokeContract{ContractId: callee,
+		Tokens: []*pb.PayOutToken{{Asset: ptn, Amount: 1}, {Asset: ptn, Amount: 2}}})
+	assert.NotNil(t, err)
+	assert.Equal(t, err, txContext.callTree.Err())
+	assert.Equal(t, 2, txContext.txsimulator.(*payOutFailSimulator).payouts)
+	assert.Equal(t, 1, len(txContext.callTree.stack))
+}
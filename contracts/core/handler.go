@@ -34,6 +34,7 @@ import (
 	"github.com/looplab/fsm"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/comm"
 	cfg "github.com/palletone/go-palletone/contracts/contractcfg"
 	"github.com/palletone/go-palletone/contracts/outchain"
 	"github.com/palletone/go-palletone/core/vmContractPub/ccprovider"
@@ -41,6 +42,7 @@ import (
 	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
 	"github.com/palletone/go-palletone/core/vmContractPub/sysccprovider"
 	"github.com/palletone/go-palletone/core/vmContractPub/util"
+	"github.com/palletone/go-palletone/dag/constants"
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/rwset"
 	"github.com/palletone/go-palletone/vm/ccintf"
@@ -69,6 +71,8 @@ type transactionContext struct {
 	//pendingQueryResults map[string]*pendingQueryResult
 
 	txsimulator rwset.TxSimulator
+	//合约调用合约时的调用树
+	callTree *ContractCallTree
 }
 
 //type pendingQueryResult struct {
@@ -214,6 +218,8 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_TIMESTAMP.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_STATE_BY_PREFIX.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_STATE_BY_RANGE.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{readystate}, Dst: readystate},
 			//{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{readystate}, Dst: readystate},
 			//{Name: pb.ChaincodeMessage_QUERY_STATE_NEXT.String(), Src: []string{readystate}, Dst: readystate},
 			//{Name: pb.ChaincodeMessage_QUERY_STATE_CLOSE.String(), Src: []string{readystate}, Dst: readystate},
@@ -225,11 +231,13 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_GET_CONTRACT_ALL_STATE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_TOKEN_BALANCE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_PAY_OUT_TOKEN.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_INVOKE_CONTRACT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_SUPPLY_TOKEN.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_DEFINE_TOKEN.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_CERT_STATE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_SEND_JURY.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_RECV_JURY.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_CHARGE_INSTRUCTIONS.String(), Src: []string{readystate}, Dst: readystate},
 		},
 		fsm.Callbacks{
 			"before_" + pb.ChaincodeMessage_REGISTER.String():           func(e *fsm.Event) { v.beforeRegisterEvent(e, v.FSM.Current()) },
@@ -237,6 +245,8 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			"after_" + pb.ChaincodeMessage_GET_STATE.String():           func(e *fsm.Event) { v.afterGetState(e) },
 			"after_" + pb.ChaincodeMessage_GET_TIMESTAMP.String():       func(e *fsm.Event) { v.afterGetTimestamp(e) },
 			"after_" + pb.ChaincodeMessage_GET_STATE_BY_PREFIX.String(): func(e *fsm.Event) { v.afterGetStateByPrefix(e) },
+			"after_" + pb.ChaincodeMessage_GET_STATE_BY_RANGE.String():  func(e *fsm.Event) { v.afterGetStateByRange(e) },
+			"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(): func(e *fsm.Event) { v.afterGetHistoryForKey(e) },
 			//"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String():       func(e *fsm.Event) { v.afterGetHistoryForKey(e, v.FSM.Current()) },
 			//"after_" + pb.ChaincodeMessage_QUERY_STATE_NEXT.String():          func(e *fsm.Event) { v.afterQueryStateNext(e, v.FSM.Current()) },
 			//"after_" + pb.ChaincodeMessage_QUERY_STATE_CLOSE.String():         func(e *fsm.Event) { v.afterQueryStateClose(e, v.FSM.Current()) },
@@ -253,9 +263,11 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			"after_" + pb.ChaincodeMessage_GET_CONTRACT_ALL_STATE.String():    func(e *fsm.Event) { v.enterGetContractAllState(e) },
 			"after_" + pb.ChaincodeMessage_GET_TOKEN_BALANCE.String():         func(e *fsm.Event) { v.enterGetTokenBalance(e) },
 			"after_" + pb.ChaincodeMessage_PAY_OUT_TOKEN.String():             func(e *fsm.Event) { v.enterPayOutToken(e) },
+			"after_" + pb.ChaincodeMessage_INVOKE_CONTRACT.String():           func(e *fsm.Event) { v.enterInvokeContract(e) },
 			"after_" + pb.ChaincodeMessage_DEFINE_TOKEN.String():              func(e *fsm.Event) { v.enterDefineToken(e) },
 			"after_" + pb.ChaincodeMessage_SUPPLY_TOKEN.String():              func(e *fsm.Event) { v.enterSupplyToken(e) },
 			"after_" + pb.ChaincodeMessage_GET_CERT_STATE.String():            func(e *fsm.Event) { v.enterGetCertByID(e) },
+			"after_" + pb.ChaincodeMessage_CHARGE_INSTRUCTIONS.String():       func(e *fsm.Event) { v.enterChargeInstructions(e) },
 		},
 	)
 
@@ -468,6 +480,7 @@ func (handler *Handler) createTxContext(ctxt context.Context, chainID string, tx
 	log.Debugf("createTxContext, create txCtxID[%s]", txCtxID)
 	//glh
 	txctx.txsimulator = getTxSimulator(ctxt)
+	txctx.callTree = getCallTree(ctxt)
 	//txctx.historyQueryExecutor = getHistoryQueryExecutor(ctxt)
 
 	return txctx, nil
@@ -829,6 +842,149 @@ func (handler *Handler) handleGetStateByPrefix(msg *pb.ChaincodeMessage) {
 	}()
 }
 
+// afterGetStateByRange handles a GET_STATE_BY_RANGE request from the chaincode.
+func (handler *Handler) afterGetStateByRange(e *fsm.Event) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(errors.New("received unexpected message type"))
+		return
+	}
+	log.Debugf("[%s]Received %s, invoking get state from ledger", shorttxid(msg.Txid),
+		pb.ChaincodeMessage_GET_STATE_BY_RANGE)
+
+	// Query ledger for state
+	handler.handleGetStateByRange(msg)
+}
+
+//返回[startKey, endKey)范围内最多limit个状态，以及继续查询时使用的bookmark
+func (handler *Handler) handleGetStateByRange(msg *pb.ChaincodeMessage) {
+	go func() {
+		uniqueReq := handler.createTXIDEntry(msg.ChannelId, msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			log.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+		var txContext *transactionContext
+		txContext, serialSendMsg = handler.isValidTxSim(msg.ChannelId, msg.Txid,
+			"[%s]No ledger context for GetStateByRange. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.ChannelId, msg.Txid)
+			log.Debugf("[%s]handleGetStateByRange serial send %s",
+				shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSendAsync(serialSendMsg, nil)
+		}()
+
+		if txContext == nil {
+			return
+		}
+		getStateByRange := &pb.GetStateByRange{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, getStateByRange)
+		if unmarshalErr != nil {
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(unmarshalErr.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		contractId := getStateByRange.ContractId
+		if len(contractId) == 0 {
+			contractId = msg.ContractId
+		}
+		chaincodeID := handler.getCCRootName()
+		log.Debugf("[%s] getting state for chaincode %s, range [%s, %s), limit %d, channel %s",
+			shorttxid(msg.Txid), chaincodeID, getStateByRange.StartKey, getStateByRange.EndKey,
+			getStateByRange.Limit, txContext.chainID)
+
+		rows, bookmark, err := txContext.txsimulator.GetStatesByRange(contractId, chaincodeID,
+			getStateByRange.StartKey, getStateByRange.EndKey, int(getStateByRange.Limit))
+		if err != nil {
+			log.Errorf("[%s]Failed to get chaincode state(%s). Sending %s",
+				shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		res, err := json.Marshal(&modules.RangeQueryResult{KVs: rows, Bookmark: bookmark})
+		if err != nil {
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		log.Debugf("[%s]Got %d states. Sending %s", shorttxid(msg.Txid), len(rows), pb.ChaincodeMessage_RESPONSE)
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}
+	}()
+}
+
+// afterGetHistoryForKey handles a GET_HISTORY_FOR_KEY request from the chaincode.
+func (handler *Handler) afterGetHistoryForKey(e *fsm.Event) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(errors.New("received unexpected message type"))
+		return
+	}
+	log.Debugf("[%s]Received %s, invoking get state history from ledger", shorttxid(msg.Txid),
+		pb.ChaincodeMessage_GET_HISTORY_FOR_KEY)
+
+	// Query ledger for history
+	handler.handleGetHistoryForKey(msg)
+}
+
+//返回状态按时间顺序的全部修改
+func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
+	go func() {
+		uniqueReq := handler.createTXIDEntry(msg.ChannelId, msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			log.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+		var txContext *transactionContext
+		txContext, serialSendMsg = handler.isValidTxSim(msg.ChannelId, msg.Txid,
+			"[%s]No ledger context for GetHistoryForKey. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.ChannelId, msg.Txid)
+			log.Debugf("[%s]handleGetHistoryForKey serial send %s",
+				shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSendAsync(serialSendMsg, nil)
+		}()
+
+		if txContext == nil {
+			return
+		}
+		getHistory := &pb.GetHistoryForKey{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, getHistory)
+		if unmarshalErr != nil {
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(unmarshalErr.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		contractId := getHistory.ContractId
+		if len(contractId) == 0 {
+			contractId = msg.ContractId
+		}
+		chaincodeID := handler.getCCRootName()
+		log.Debugf("[%s] getting state history for chaincode %s, key %s, channel %s",
+			shorttxid(msg.Txid), chaincodeID, getHistory.Key, txContext.chainID)
+
+		histories, err := txContext.txsimulator.GetHistoryForKey(contractId, chaincodeID, getHistory.Key)
+		if err != nil {
+			log.Errorf("[%s]Failed to get chaincode state history(%s). Sending %s",
+				shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		res, err := json.Marshal(histories)
+		if err != nil {
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		log.Debugf("[%s]Got %d state histories. Sending %s", shorttxid(msg.Txid), len(histories),
+			pb.ChaincodeMessage_RESPONSE)
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: res, Txid: msg.Txid, ChannelId: msg.ChannelId}
+	}()
+}
+
 // afterGetState handles a GET_STATE request from the chaincode.
 func (handler *Handler) afterGetState(e *fsm.Event) {
 	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
@@ -923,13 +1079,15 @@ func (handler *Handler) handleGetTokenBalance(msg *pb.ChaincodeMessage) {
 			return
 		}
 
+		var asset *modules.Asset
 		if len(getBalance.Asset) > 0 {
-			asset := &modules.Asset{}
+			asset = &modules.Asset{}
 			asset.SetString(getBalance.Asset)
+		} // asset is nil, get all token type balance
+		if getBalance.Height > 0 { //balance at a stable unit
+			balance, err = txContext.txsimulator.GetTokenBalanceAt(chaincodeID, address, asset, getBalance.Height)
+		} else {
 			balance, err = txContext.txsimulator.GetTokenBalance(chaincodeID, address, asset)
-		} else { // get all token type balance
-			balance, err = txContext.txsimulator.GetTokenBalance(chaincodeID, address, nil)
-
 		}
 
 		if err != nil {
@@ -1014,7 +1172,18 @@ func (handler *Handler) handlePayOutToken(msg *pb.ChaincodeMessage) {
 		chaincodeID := handler.getCCRootName()
 		log.Debugf("[%s] getting balance for chaincode %s, key %#v, channel %s",
 			shorttxid(msg.Txid), chaincodeID, asset, txContext.chainID)
-		err := txContext.txsimulator.PayOutToken(chaincodeID, payout.Address, asset, payout.Amount, payout.Locktime)
+		var err error
+		if txContext.callTree != nil {
+			idag, _ := comm.GetCcDagHand()
+			if idag == nil {
+				err = errors.New("dag is not available")
+			} else {
+				err = txContext.callTree.checkPayout(msg.ContractId, idag.GetContractJury)
+			}
+		}
+		if err == nil {
+			err = txContext.txsimulator.PayOutToken(chaincodeID, payout.Address, asset, payout.Amount, payout.Locktime)
+		}
 		if err != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
@@ -1029,6 +1198,59 @@ func (handler *Handler) handlePayOutToken(msg *pb.ChaincodeMessage) {
 	}()
 }
 
+func (handler *Handler) enterChargeInstructions(e *fsm.Event) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(errors.New("received unexpected message type"))
+		return
+	}
+	log.Debugf("[%s]Received %s", shorttxid(msg.Txid), pb.ChaincodeMessage_CHARGE_INSTRUCTIONS)
+
+	handler.handleChargeInstructions(msg)
+}
+
+//wasm合约上报已执行的指令数，与账本访问一样累加到本次调用的cost
+func (handler *Handler) handleChargeInstructions(msg *pb.ChaincodeMessage) {
+	go func() {
+		uniqueReq := handler.createTXIDEntry(msg.ChannelId, msg.Txid)
+		if !uniqueReq {
+			log.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+		var txContext *transactionContext
+		txContext, serialSendMsg = handler.isValidTxSim(msg.ChannelId, msg.Txid,
+			"[%s]No ledger context for handleChargeInstructions. Sending %s", shorttxid(msg.Txid),
+			pb.ChaincodeMessage_ERROR)
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.ChannelId, msg.Txid)
+			log.Debugf("[%s]handleChargeInstructions serial send %s",
+				shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSendAsync(serialSendMsg, nil)
+		}()
+
+		if txContext == nil {
+			return
+		}
+		charge := &pb.ChargeInstructions{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, charge)
+		if unmarshalErr != nil {
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(unmarshalErr.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+			return
+		}
+		err := txContext.txsimulator.AddInstructionCost(charge.Count)
+		if err != nil {
+			log.Debugf("[%s]Failed to charge %d instructions(%s). Sending %s",
+				shorttxid(msg.Txid), charge.Count, err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid, ChannelId: msg.ChannelId}
+		} else {
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: nil, Txid: msg.Txid, ChannelId: msg.ChannelId}
+		}
+	}()
+}
+
 func (handler *Handler) enterDefineToken(e *fsm.Event) {
 	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
 	if !ok {
@@ -1318,7 +1540,9 @@ func (handler *Handler) handleGetTimestamp(msg *pb.ChaincodeMessage) {
 
 		var res []byte
 		var err error
-		if isCollectionSet(getTimestamp.Collection) {
+		if getTimestamp.Collection == constants.TIMESTAMP_COLLECTION_HEIGHT {
+			res, err = txContext.txsimulator.GetHeight(chaincodeID, getTimestamp.RangeNumber)
+		} else if isCollectionSet(getTimestamp.Collection) {
 			//glh
 			//res, err = txContext.txsimulator.GetPrivateData(chaincodeID, getTimestamp.Collection, getTimestamp.Key)
 		} else {
@@ -234,7 +234,8 @@ func GetChaincodes(dag dag.IDag) ([]*cclist.CCInfo, error) {
 //timeout:ms
 // ccName can be contract Id
 //func Invoke(chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration) (*peer.ContractInvokePayload, error) {
-func Invoke(rwM rwset.TxManager, idag dag.IDag, chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration) (*md.ContractInvokeResult, error) {
+//costLimit:用户合约执行的cost上限，为0时使用默认值
+func Invoke(rwM rwset.TxManager, idag dag.IDag, chainID string, deployId []byte, txid string, args [][]byte, timeout time.Duration, costLimit uint64) (*md.ContractInvokeResult, error) {
 	log.Debugf("Invoke enter")
 	log.Info("Invoke enter", "chainID", chainID, "deployId", deployId, "txid", txid, "timeout", timeout)
 	defer log.Info("Invoke exit", "chainID", chainID, "deployId", deployId, "txid", txid, "timeout", timeout)
@@ -258,6 +259,18 @@ func Invoke(rwM rwset.TxManager, idag dag.IDag, chainID string, deployId []byte,
 			return nil, err
 		}
 	}
+	//WASM合约和子进程方式运行的合约不会在节点重启后恢复，需要重新加载
+	if !cc.SysCC && (cc.Language == pb.ChaincodeSpec_WASM.String() || contractcfg.GetConfig().IsProcessVm()) {
+		if err := launchUserCC(chainID, deployId, txid, cc); err != nil {
+			return nil, err
+		}
+	}
+	//系统合约不计价
+	if cc.SysCC {
+		costLimit = 0
+	} else if costLimit == 0 {
+		costLimit = idag.GetChainParameters().ContractCostLimit
+	}
 	startTm := time.Now()
 	es := NewEndorserServer(mksupt)
 	log.Debugf("new endorser server")
@@ -277,14 +290,14 @@ func Invoke(rwM rwset.TxManager, idag dag.IDag, chainID string, deployId []byte,
 		log.Errorf("signedEndorserProposa error[%v]", err)
 		return nil, err
 	}
-	rsp, unit, err := es.ProcessProposal(rwM, idag, deployId, context.Background(), sprop, prop, chainID, cid, timeout)
+	rsp, unit, err := es.ProcessProposal(rwM, idag, deployId, context.Background(), sprop, prop, chainID, cid, timeout, costLimit)
 	log.Debugf("process proposal")
 	if err != nil {
 		log.Infof("ProcessProposal error[%v]", err)
 		return nil, err
 	}
 	//
-	if !cc.SysCC {
+	if !cc.SysCC && cc.Language != pb.ChaincodeSpec_WASM.String() && !contractcfg.GetConfig().IsProcessVm() {
 		sizeRW, disk, isOver := utils.RemoveConWhenOverDisk(cc, idag)
 		if isOver {
 			log.Debugf("utils.KillAndRmWhenOver name = %s,sizeRW = %d,disk = %d", cc.Name, sizeRW, disk)
@@ -300,6 +313,26 @@ func Invoke(rwM rwset.TxManager, idag dag.IDag, chainID string, deployId []byte,
 	return unit, nil
 }
 
+func launchUserCC(chainID string, deployId []byte, txid string, cc *cclist.CCInfo) error {
+	_, chaincodeData, err := ucc.RecoverChainCodeFromDb(chainID, cc.TempleId)
+	if err != nil {
+		return err
+	}
+	spec := &pb.ChaincodeSpec{
+		Type: pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value[cc.Language]),
+		ChaincodeId: &pb.ChaincodeID{
+			Name:    cc.Name,
+			Path:    cc.Path,
+			Version: cc.Version,
+		},
+	}
+	if err := ucc.LaunchUserCC(deployId, chaincodeData, spec, chainID, txid); err != nil {
+		log.Errorf("launch user contract[%s] err:%s", cc.Name, err)
+		return errors.WithMessage(err, "launch user contract fail")
+	}
+	return nil
+}
+
 func Stop(rwM rwset.TxManager, idag dag.IDag, contractid []byte, chainID string, deployId []byte, txid string, deleteImage bool, dontRmCon bool) (*md.ContractStopPayload, error) {
 	log.Info("Stop enter", "contractid", contractid, "chainID", chainID, "deployId", deployId, "txid", txid)
 	defer log.Info("Stop enter", "contractid", contractid, "chainID", chainID, "deployId", deployId, "txid", txid)
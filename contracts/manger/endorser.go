@@ -204,7 +204,7 @@ func (e *Endorser) validateProcess(signedProp *pb.SignedProposal) (*validateResu
 
 // ProcessProposal process the Proposal
 //func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ProposalResponse, error) {
-func (e *Endorser) ProcessProposal(rwM rwset.TxManager, idag dag.IDag, deployId []byte, ctx context.Context, signedProp *pb.SignedProposal, prop *pb.Proposal, chainID string, cid *pb.ChaincodeID, tmout time.Duration) (*pb.ProposalResponse, *modules.ContractInvokeResult, error) {
+func (e *Endorser) ProcessProposal(rwM rwset.TxManager, idag dag.IDag, deployId []byte, ctx context.Context, signedProp *pb.SignedProposal, prop *pb.Proposal, chainID string, cid *pb.ChaincodeID, tmout time.Duration, costLimit uint64) (*pb.ProposalResponse, *modules.ContractInvokeResult, error) {
 	log.Debugf("process proposal enter")
 	var txsim rwset.TxSimulator
 
@@ -224,6 +224,8 @@ func (e *Endorser) ProcessProposal(rwM rwset.TxManager, idag dag.IDag, deployId
 		if txsim, err = e.s.GetTxSimulator(rwM, idag, chainID, txid); err != nil {
 			return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, nil, err
 		}
+		//同一链上的调用共用txsim，每次调用重新计价
+		txsim.SetCostLimit(costLimit)
 		//defer txsim.Done()
 	}
 	if err != nil {
@@ -231,8 +233,14 @@ func (e *Endorser) ProcessProposal(rwM rwset.TxManager, idag dag.IDag, deployId
 	}
 
 	//1 -- simulate
+	//合约调用合约的调用树，被调用合约失败时即使调用者忽略了错误，整个调用也失败
+	callTree := core.NewContractCallTree(deployId, tmout)
+	ctx = context.WithValue(ctx, core.CallTreeKey, callTree)
 	res, _, _, err := e.simulateProposal(deployId, ctx, chainID, txid, signedProp, prop, cid, txsim, tmout)
 	log.Debugf("simulate proposal")
+	if err == nil {
+		err = callTree.Err()
+	}
 	if err != nil {
 		return &pb.ProposalResponse{Response: &pb.Response{Status: 500, Message: err.Error()}}, nil, err
 	}
@@ -263,9 +271,25 @@ func (e *Endorser) ProcessProposal(rwM rwset.TxManager, idag dag.IDag, deployId
 		log.Errorf("chainID[%s] converRwTxResult2DagUnit failed", chainID)
 		return nil, nil, errors.New("Conver RwSet to dag unit fail")
 	}
+	if err = mergeCalledContracts(txsim, unit, txid, callTree.Called()); err != nil {
+		log.Errorf("chainID[%s] merge called contracts failed:%s", chainID, err)
+		return nil, nil, err
+	}
 
 	pResp.Response.Payload = res.Payload
 	unit.Payload = res.Payload
+	if costLimit > 0 {
+		//调用参数和返回结果按字节计价，合约忽略了账本访问的错误时也在这里检查上限
+		size := len(res.Payload)
+		for _, arg := range cis.ChaincodeSpec.Input.Args {
+			size += len(arg)
+		}
+		if err := txsim.AddCost(uint64(size) * idag.GetChainParameters().ContractPayloadByteCost); err != nil {
+			log.Infof("[%s][%s] cost[%d] exceeded the limit[%d]", chainID, shorttxid(txid), txsim.GetCost(), costLimit)
+			return nil, nil, err
+		}
+		unit.Cost = txsim.GetCost()
+	}
 
 	return pResp, unit, nil
 }
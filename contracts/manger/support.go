@@ -20,11 +20,13 @@
 package manger
 
 import (
+	"errors"
 	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	chaincode "github.com/palletone/go-palletone/contracts/core"
 	"github.com/palletone/go-palletone/contracts/scc"
@@ -100,26 +102,55 @@ func GetBytesChaincodeEvent(event *pb.ChaincodeEvent) ([]byte, error) {
 
 func RwTxResult2DagInvokeUnit(tx rwset.TxSimulator, txid string, nm string, deployId []byte, args [][]byte, timeout time.Duration) (*md.ContractInvokeResult, error) {
 	log.Debug("enter")
+	invoke := &md.ContractInvokeResult{
+		ContractId: deployId,
+		Args:       args,
+		ReadSet:    make([]md.ContractReadSet, 0),
+		WriteSet:   make([]md.ContractWriteSet, 0),
+	}
+	if err := addNamespaceResult(tx, invoke, txid, nm, deployId); err != nil {
+		return nil, err
+	}
+	return invoke, nil
+}
+
+//合约调用合约时，把被调用合约的读写集和付款合并到根合约的调用结果中
+func mergeCalledContracts(tx rwset.TxSimulator, invoke *md.ContractInvokeResult, txid string,
+	called []*chaincode.CalledContract) error {
+	for _, c := range called {
+		if err := addNamespaceResult(tx, invoke, txid, c.Name, c.ContractId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//把txsimulator中一个合约的执行结果加入调用结果，付款的来源是该合约的地址
+func addNamespaceResult(tx rwset.TxSimulator, invoke *md.ContractInvokeResult, txid string, nm string,
+	contractId []byte) error {
 	rd, wt, err := tx.GetRwData(nm)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	tokenPay, err := tx.GetPayOutData(nm)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	tokenDefine, _ := tx.GetTokenDefineData(nm)
 	tokenSupply, _ := tx.GetTokenSupplyData(nm)
 	log.Infof("txid=%s, nm=%s, rd=%#v, wt=%v", txid, nm, rd, wt)
-	invoke := &md.ContractInvokeResult{
-		ContractId:  deployId,
-		Args:        args,
-		ReadSet:     make([]md.ContractReadSet, 0),
-		WriteSet:    make([]md.ContractWriteSet, 0),
-		TokenPayOut: tokenPay,
-		TokenDefine: tokenDefine,
-		TokenSupply: tokenSupply,
+	payFrom := common.NewAddress(contractId, common.ContractHash)
+	for _, pay := range tokenPay {
+		pay.PayFrom = payFrom
+		invoke.TokenPayOut = append(invoke.TokenPayOut, pay)
+	}
+	if tokenDefine != nil {
+		if invoke.TokenDefine != nil {
+			return errors.New("only one token can be defined in a contract invocation")
+		}
+		invoke.TokenDefine = tokenDefine
 	}
+	invoke.TokenSupply = append(invoke.TokenSupply, tokenSupply...)
 
 	for idx, val := range rd {
 		rs := md.ContractReadSet{
@@ -128,7 +159,7 @@ func RwTxResult2DagInvokeUnit(tx rwset.TxSimulator, txid string, nm string, depl
 			ContractId: val.ContractId,
 		}
 		invoke.ReadSet = append(invoke.ReadSet, rs)
-		log.Infof("ReadSet: idx[%v], fun[%s], key[%s], val[%v]", idx, args[2], val.GetKey(), val.GetVersion())
+		log.Infof("ReadSet: idx[%v], nm[%s], key[%s], val[%v]", idx, nm, val.GetKey(), val.GetVersion())
 	}
 	for idx, val := range wt {
 		ws := md.ContractWriteSet{
@@ -138,10 +169,21 @@ func RwTxResult2DagInvokeUnit(tx rwset.TxSimulator, txid string, nm string, depl
 			ContractId: val.ContractId,
 		}
 		invoke.WriteSet = append(invoke.WriteSet, ws)
-		log.Infof("WriteSet: idx[%d], fun[%s], key[%s], val[%v], delete[%t]", idx, args[2], val.GetKey(), val.GetValue(), val.GetIsDelete())
+		log.Infof("WriteSet: idx[%d], nm[%s], key[%s], val[%v], delete[%t]", idx, nm, val.GetKey(), val.GetValue(), val.GetIsDelete())
 	}
-
-	return invoke, nil
+	rangeReads, err := tx.GetRangeReadData(nm)
+	if err != nil {
+		return err
+	}
+	for _, val := range rangeReads {
+		invoke.RangeReadSet = append(invoke.RangeReadSet, md.ContractRangeReadSet{
+			ContractId: val.ContractId,
+			StartKey:   val.GetStartKey(),
+			EndKey:     val.GetEndKey(),
+			ResultHash: val.GetResultHash(),
+		})
+	}
+	return nil
 }
 
 //func RwTxResult2DagDeployUnit(tx rwset.TxSimulator, txid string, nm string, fun []byte) (*pb.ContractDeployPayload, error) {
@@ -16,7 +16,6 @@ import (
 )
 
 var (
-	allChain      = map[string]adaptor.ICryptoCurrency{"btc": GetBTCAdaptor(), "eth": GetETHAdaptor(), "erc20": GetERC20Adaptor()}
 	exceptMethond = map[string]CallbackExcpet{ //some method need private key, list in map, first key is methodName
 		"GetJuryPubkey": GetJuryPubkey, "GetJuryAddr": GetJuryAddr,
 		"SignTransaction": SignTransaction, "SignMessage": SignMessage,
@@ -56,7 +55,7 @@ func ProcessOutChainCall(chaincodeID string, outChainCall *pb.OutChainCall) (str
 	log.Debugf("Get Request method : %s", outChainCall.Method)
 
 	chainName := strings.ToLower(outChainCall.OutChainName)
-	if _, existChain := exceptMethond[chainName]; existChain {
+	if _, existChain := GetAdaptor(chainName); existChain {
 		ef, existMethod := exceptMethond[outChainCall.Method]
 		if existMethod {
 			return ef(chaincodeID, chainName, outChainCall.Params)
@@ -66,7 +65,7 @@ func ProcessOutChainCall(chaincodeID string, outChainCall *pb.OutChainCall) (str
 	return adaptorCall(chainName, outChainCall.Method, outChainCall.Params)
 }
 func GetJuryPubkey(chaincodeID string, chainName string, params []byte) (string, error) {
-	adaptorObj := allChain[chainName] //ProcessOutChainCall has checked
+	adaptorObj, _ := GetAdaptor(chainName) //ProcessOutChainCall has checked
 	priKey, err := GetJuryKeyInfo(chaincodeID, chainName, params, adaptorObj)
 	if err != nil {
 		return "", err
@@ -83,7 +82,7 @@ func GetJuryPubkey(chaincodeID string, chainName string, params []byte) (string,
 }
 
 func SignTransaction(chaincodeID string, chainName string, params []byte) (string, error) {
-	adaptorObj := allChain[chainName] //ProcessOutChainCall has checked
+	adaptorObj, _ := GetAdaptor(chainName) //ProcessOutChainCall has checked
 	//
 	priKey, err := GetJuryKeyInfo(chaincodeID, chainName, params, adaptorObj)
 	if err != nil {
@@ -106,7 +105,7 @@ func SignTransaction(chaincodeID string, chainName string, params []byte) (strin
 	return string(resultJson), nil
 }
 func GetJuryAddr(chaincodeID string, chainName string, params []byte) (string, error) {
-	adaptorObj := allChain[chainName] //ProcessOutChainCall has checked
+	adaptorObj, _ := GetAdaptor(chainName) //ProcessOutChainCall has checked
 	addr, err := GetJuryAddress(chaincodeID, chainName, params, adaptorObj)
 	if err != nil {
 		return "", err
@@ -115,7 +114,7 @@ func GetJuryAddr(chaincodeID string, chainName string, params []byte) (string, e
 }
 
 func SignMessage(chaincodeID string, chainName string, params []byte) (string, error) {
-	adaptorObj := allChain[chainName] //ProcessOutChainCall has checked
+	adaptorObj, _ := GetAdaptor(chainName) //ProcessOutChainCall has checked
 	//
 	priKey, err := GetJuryKeyInfo(chaincodeID, chainName, params, adaptorObj)
 	if err != nil {
@@ -139,7 +138,7 @@ func SignMessage(chaincodeID string, chainName string, params []byte) (string, e
 }
 
 func adaptorCall(chainName, methodName string, params []byte) (string, error) {
-	adaptorObj, has := allChain[chainName]
+	adaptorObj, has := GetAdaptor(chainName)
 	if !has {
 		log.Debugf("Not implement this Chain")
 		return "", errors.New("Not implement this Chain")
@@ -0,0 +1,338 @@
+package outchain
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/palletone/adaptor"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/core/accounts/keystore"
+)
+
+var (
+	ErrKeyStoreLocked = errors.New("outchain keystore is locked")
+	ErrKeyStoreNoPath = errors.New("outchain keystore path is not set")
+
+	//jury持有的链外私钥，加密保存在数据目录下的keystore文件中，不再明文保存在outchain.toml
+	ks = newKeyStore("", keystore.StandardScryptN, keystore.StandardScryptP)
+)
+
+type outchainKeyStore struct {
+	mu       sync.Mutex
+	path     string
+	scryptN  int
+	scryptP  int
+	auth     string
+	unlocked bool
+	keys     map[string]KeyInfo //chainName --- keyInfo
+}
+
+func newKeyStore(path string, scryptN, scryptP int) *outchainKeyStore {
+	return &outchainKeyStore{path: path, scryptN: scryptN, scryptP: scryptP}
+}
+
+func (s *outchainKeyStore) setPath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+}
+
+// 读取并解密keystore文件，文件不存在时返回空的私钥集合
+func (s *outchainKeyStore) load(auth string) (map[string]KeyInfo, error) {
+	if s.path == "" {
+		return nil, ErrKeyStoreNoPath
+	}
+	keys := map[string]KeyInfo{}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keys, nil
+		}
+		return nil, err
+	}
+	plain, err := keystore.DecryptData(data, auth)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(plain, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *outchainKeyStore) save() error {
+	plain, err := json.Marshal(s.keys)
+	if err != nil {
+		return err
+	}
+	data, err := keystore.EncryptData(plain, s.auth, s.scryptN, s.scryptP)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	//先写临时文件再改名，避免写入过程中断导致私钥丢失
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *outchainKeyStore) keyInfo(chainName string) KeyInfo {
+	info, ok := s.keys[chainName]
+	if !ok {
+		info = KeyInfo{ChaincodeKeys: map[string][]byte{}, AddressKeys: map[string][]byte{}}
+		s.keys[chainName] = info
+	}
+	if info.ChaincodeKeys == nil {
+		info.ChaincodeKeys = map[string][]byte{}
+		s.keys[chainName] = info
+	}
+	if info.AddressKeys == nil {
+		info.AddressKeys = map[string][]byte{}
+		s.keys[chainName] = info
+	}
+	return info
+}
+
+// 合并私钥，已存在的私钥不会被覆盖，返回新增的私钥数量
+func (s *outchainKeyStore) merge(keys map[string]KeyInfo) int {
+	count := 0
+	for chainName, info := range keys {
+		dst := s.keyInfo(chainName)
+		for chaincode, key := range info.ChaincodeKeys {
+			if _, ok := dst.ChaincodeKeys[chaincode]; !ok {
+				dst.ChaincodeKeys[chaincode] = key
+				count++
+			}
+		}
+		for addr, key := range info.AddressKeys {
+			if _, ok := dst.AddressKeys[addr]; !ok {
+				dst.AddressKeys[addr] = key
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// 迁移outchain.toml中遗留的明文私钥，迁移成功后从配置文件中删除
+func (s *outchainKeyStore) migrateConfigKeys() error {
+	if err := GetConfigTest(); err != nil {
+		return err
+	}
+	legacy := 0
+	for _, info := range cfg.Ada.ChainKeyKV {
+		legacy += len(info.ChaincodeKeys) + len(info.AddressKeys)
+	}
+	if legacy == 0 {
+		return nil
+	}
+	if s.merge(cfg.Ada.ChainKeyKV) > 0 {
+		if err := s.save(); err != nil {
+			return err
+		}
+	}
+	log.Info("Migrated outchain private keys from config file to keystore", "keystore", s.path)
+	cfg.Ada.ChainKeyKV = map[string]KeyInfo{}
+	return saveConfigTest()
+}
+
+func (s *outchainKeyStore) unlock(auth string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load(auth)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.auth = auth
+	//迁移失败时保持锁定状态，避免在缺少遗留私钥的情况下生成新私钥
+	if err := s.migrateConfigKeys(); err != nil {
+		s.keys = nil
+		s.auth = ""
+		return err
+	}
+	s.unlocked = true
+	return nil
+}
+
+func (s *outchainKeyStore) lock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = nil
+	s.auth = ""
+	s.unlocked = false
+}
+
+func (s *outchainKeyStore) isUnlocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unlocked
+}
+
+// 返回合约在该链上的私钥，不存在则生成一个新私钥并保存
+func (s *outchainKeyStore) chaincodeKey(chaincode, chainName string, params []byte,
+	iadaptor adaptor.IUtility) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.unlocked {
+		return nil, ErrKeyStoreLocked
+	}
+
+	info := s.keyInfo(chainName)
+	if key, exist := info.ChaincodeKeys[chaincode]; exist {
+		return key, nil
+	}
+	priKey, addr, err := getNewKey(params, iadaptor)
+	if err != nil {
+		return nil, err
+	}
+	info.ChaincodeKeys[chaincode] = priKey
+	info.AddressKeys[addr] = priKey
+	if err := s.save(); err != nil {
+		delete(info.ChaincodeKeys, chaincode)
+		delete(info.AddressKeys, addr)
+		return nil, err
+	}
+	return priKey, nil
+}
+
+// 返回jury在该链上的地址，该链还没有私钥时生成一个新私钥并保存
+func (s *outchainKeyStore) address(chaincode, chainName string, params []byte,
+	iadaptor adaptor.IUtility) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.unlocked {
+		return "", ErrKeyStoreLocked
+	}
+
+	info := s.keyInfo(chainName)
+	for addr := range info.AddressKeys {
+		return addr, nil
+	}
+	priKey, addr, err := getNewKey(params, iadaptor)
+	if err != nil {
+		return "", err
+	}
+	info.ChaincodeKeys[chaincode] = priKey
+	info.AddressKeys[addr] = priKey
+	if err := s.save(); err != nil {
+		delete(info.ChaincodeKeys, chaincode)
+		delete(info.AddressKeys, addr)
+		return "", err
+	}
+	return addr, nil
+}
+
+func (s *outchainKeyStore) export(auth, exportAuth string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load(auth)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.EncryptData(plain, exportAuth, s.scryptN, s.scryptP)
+}
+
+func (s *outchainKeyStore) importKeys(auth string, data []byte, importAuth string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plain, err := keystore.DecryptData(data, importAuth)
+	if err != nil {
+		return 0, err
+	}
+	imported := map[string]KeyInfo{}
+	if err := json.Unmarshal(plain, &imported); err != nil {
+		return 0, err
+	}
+
+	keys, err := s.load(auth)
+	if err != nil {
+		return 0, err
+	}
+	//导入到一个临时的keystore中，保存成功后再更新内存中的私钥
+	tmp := &outchainKeyStore{path: s.path, scryptN: s.scryptN, scryptP: s.scryptP, auth: auth, keys: keys}
+	count := tmp.merge(imported)
+	if count == 0 {
+		return 0, nil
+	}
+	if err := tmp.save(); err != nil {
+		return 0, err
+	}
+	if s.unlocked && s.auth == auth {
+		s.keys = tmp.keys
+	}
+	return count, nil
+}
+
+func (s *outchainKeyStore) rotate(oldAuth, newAuth string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load(oldAuth)
+	if err != nil {
+		return err
+	}
+	tmp := &outchainKeyStore{path: s.path, scryptN: s.scryptN, scryptP: s.scryptP, auth: newAuth, keys: keys}
+	if err := tmp.save(); err != nil {
+		return err
+	}
+	if s.unlocked {
+		s.keys = keys
+		s.auth = newAuth
+	}
+	return nil
+}
+
+// SetKeyStorePath sets the file of the outchain keystore, it should be resolved
+// in the data directory of the node.
+func SetKeyStorePath(path string) {
+	ks.setPath(path)
+}
+
+// UnlockKeyStore decrypts the outchain keystore with its dedicated password,
+// private keys left in the plaintext config file are migrated into the keystore.
+func UnlockKeyStore(password string) error {
+	return ks.unlock(password)
+}
+
+// LockKeyStore drops the decrypted private keys from memory.
+func LockKeyStore() {
+	ks.lock()
+}
+
+// IsKeyStoreUnlocked reports whether the outchain keystore has been unlocked.
+func IsKeyStoreUnlocked() bool {
+	return ks.isUnlocked()
+}
+
+// ExportKeyStore decrypts the keystore with password and returns all private keys
+// re-encrypted with exportPassword, so they can be moved to another jury node.
+func ExportKeyStore(password, exportPassword string) ([]byte, error) {
+	return ks.export(password, exportPassword)
+}
+
+// ImportKeyStore merges private keys exported by ExportKeyStore into the keystore,
+// existing keys are never overwritten. It returns the number of imported keys.
+func ImportKeyStore(password string, data []byte, importPassword string) (int, error) {
+	return ks.importKeys(password, data, importPassword)
+}
+
+// RotateKeyStore re-encrypts the keystore with a new password.
+func RotateKeyStore(oldPassword, newPassword string) error {
+	return ks.rotate(oldPassword, newPassword)
+}
@@ -0,0 +1,154 @@
+package outchain
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palletone/adaptor"
+	"github.com/palletone/go-palletone/core/accounts/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeyStore(t *testing.T) (*outchainKeyStore, func()) {
+	dir, err := ioutil.TempDir("", "outchain-keystore")
+	assert.Nil(t, err)
+	s := newKeyStore(filepath.Join(dir, "outchain.keystore"), keystore.LightScryptN, keystore.LightScryptP)
+	return s, func() { os.RemoveAll(dir) }
+}
+
+func newKeyParams(t *testing.T) []byte {
+	params, err := json.Marshal(&adaptor.NewPrivateKeyInput{})
+	assert.Nil(t, err)
+	return params
+}
+
+func TestKeyStore_Locked(t *testing.T) {
+	s, clean := newTestKeyStore(t)
+	defer clean()
+
+	_, err := s.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Equal(t, ErrKeyStoreLocked, err)
+	_, err = s.address("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Equal(t, ErrKeyStoreLocked, err)
+}
+
+func TestKeyStore_ChaincodeKey(t *testing.T) {
+	s, clean := newTestKeyStore(t)
+	defer clean()
+
+	assert.Nil(t, s.unlock("1"))
+	key, err := s.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.NotEmpty(t, key)
+	addr, err := s.address("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.NotEmpty(t, addr)
+
+	//keystore文件中不能出现明文私钥
+	data, err := ioutil.ReadFile(s.path)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(data), addr))
+
+	//重新解锁后私钥不变
+	s.lock()
+	assert.NotNil(t, s.unlock("2"))
+	assert.Nil(t, s.unlock("1"))
+	key2, err := s.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.Equal(t, key, key2)
+}
+
+func TestKeyStore_ExportImportRotate(t *testing.T) {
+	s, clean := newTestKeyStore(t)
+	defer clean()
+	assert.Nil(t, s.unlock("1"))
+	key, err := s.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+
+	data, err := s.export("1", "export")
+	assert.Nil(t, err)
+	_, err = s.export("2", "export")
+	assert.NotNil(t, err)
+
+	other, cleanOther := newTestKeyStore(t)
+	defer cleanOther()
+	_, err = other.importKeys("3", data, "bad")
+	assert.NotNil(t, err)
+	count, err := other.importKeys("3", data, "export")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	//重复导入不会覆盖
+	count, err = other.importKeys("3", data, "export")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+
+	assert.Nil(t, other.unlock("3"))
+	key2, err := other.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.Equal(t, key, key2)
+
+	assert.NotNil(t, other.rotate("1", "4"))
+	assert.Nil(t, other.rotate("3", "4"))
+	other.lock()
+	assert.NotNil(t, other.unlock("3"))
+	assert.Nil(t, other.unlock("4"))
+	key3, err := other.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.Equal(t, key, key3)
+}
+
+func TestKeyStore_MigrateConfigKeys(t *testing.T) {
+	s, clean := newTestKeyStore(t)
+	defer clean()
+	assert.Nil(t, GetConfigTest())
+	old := cfg.Ada.ChainKeyKV
+	defer func() {
+		cfg.Ada.ChainKeyKV = old
+		saveConfigTest()
+	}()
+
+	cfg.Ada.ChainKeyKV = map[string]KeyInfo{
+		"eth": {
+			ChaincodeKeys: map[string][]byte{"sample_syscc": []byte("legacy key")},
+			AddressKeys:   map[string][]byte{"0xlegacy": []byte("legacy key")},
+		},
+	}
+	assert.Nil(t, s.unlock("1"))
+	assert.Empty(t, cfg.Ada.ChainKeyKV)
+
+	key, err := s.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("legacy key"), key)
+	addr, err := s.address("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Nil(t, err)
+	assert.Equal(t, "0xlegacy", addr)
+}
+
+func TestKeyStore_UnlockFailed(t *testing.T) {
+	//未设置路径时不能解锁
+	s := newKeyStore("", keystore.LightScryptN, keystore.LightScryptP)
+	assert.Equal(t, ErrKeyStoreNoPath, s.unlock("1"))
+	assert.False(t, s.isUnlocked())
+
+	//迁移失败时保持锁定
+	s, clean := newTestKeyStore(t)
+	defer clean()
+	assert.Nil(t, GetConfigTest())
+	old := cfg.Ada.ChainKeyKV
+	defer func() {
+		cfg.Ada.ChainKeyKV = old
+		saveConfigTest()
+	}()
+	cfg.Ada.ChainKeyKV = map[string]KeyInfo{
+		"eth": {ChaincodeKeys: map[string][]byte{"sample_syscc": []byte("legacy key")}},
+	}
+	s.path = filepath.Join(s.path, "not", "writable", "\x00")
+	assert.NotNil(t, s.unlock("1"))
+	assert.False(t, s.isUnlocked())
+	_, err := s.chaincodeKey("sample_syscc", "eth", newKeyParams(t), GetETHAdaptor())
+	assert.Equal(t, ErrKeyStoreLocked, err)
+}
@@ -0,0 +1,569 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+// Package mockadaptor implements a deterministic in-memory chain which satisfies
+// adaptor.ICryptoCurrency, so that OutChainCall contracts can be tested offline.
+package mockadaptor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/palletone/adaptor"
+	"github.com/palletone/go-palletone/common/crypto"
+)
+
+const (
+	DefaultAsset       = "MOCK"
+	DefaultDecimal     = 8
+	DefaultStableDepth = 6
+	DefaultBlockTime   = 10
+
+	//固定的创世时间，保证每次运行的结果一致
+	genesisTimestamp = 1546300800
+	addressPrefix    = "mock"
+)
+
+var (
+	ErrTxNotFound          = errors.New("mock chain: transaction not found")
+	ErrBlockNotFound       = errors.New("mock chain: block not found")
+	ErrInsufficientBalance = errors.New("mock chain: insufficient balance")
+	ErrInvalidSignature    = errors.New("mock chain: invalid signature")
+	ErrInvalidTx           = errors.New("mock chain: invalid transaction")
+	ErrMappingNotFound     = errors.New("mock chain: mapping address not found")
+)
+
+type Config struct {
+	Seed        string //生成私钥的种子
+	Asset       string //默认资产
+	Decimal     uint
+	StableDepth uint //交易所在区块之后有多少个区块才算稳定
+}
+
+//未签名的转账交易，序列化后作为原始交易
+type rawTx struct {
+	From   string               `json:"from"`
+	To     string               `json:"to"`
+	Amount *adaptor.AmountAsset `json:"amount"`
+	Fee    *adaptor.AmountAsset `json:"fee"`
+	Nonce  uint64               `json:"nonce"`
+	Extra  []byte               `json:"extra"`
+}
+
+type signedTx struct {
+	Tx    []byte   `json:"tx"`
+	Signs [][]byte `json:"signs"`
+}
+
+type mockBlock struct {
+	id        []byte
+	height    uint
+	timestamp uint64
+	parent    []byte
+	txs       [][]byte
+}
+
+type mockTx struct {
+	id      []byte
+	raw     []byte
+	tx      *rawTx
+	height  uint
+	index   uint
+	inBlock bool
+}
+
+// AdaptorMock is an in-memory chain with balances, transactions and blocks.
+// Transactions are packed into a block by Mine, and become stable once
+// StableDepth blocks have been mined on top of them.
+type AdaptorMock struct {
+	mu       sync.RWMutex
+	cfg      Config
+	keyCount uint64
+	balances map[string]map[string]*big.Int //address --- asset --- balance
+	nonces   map[string]uint64
+	txs      map[string]*mockTx //txid hex --- tx
+	txOrder  []string
+	pending  [][]byte
+	blocks   []*mockBlock
+	mappings map[string]string //chain address --- palletone address
+}
+
+func NewAdaptorMock(cfg Config) *AdaptorMock {
+	if cfg.Asset == "" {
+		cfg.Asset = DefaultAsset
+	}
+	if cfg.Decimal == 0 {
+		cfg.Decimal = DefaultDecimal
+	}
+	if cfg.StableDepth == 0 {
+		cfg.StableDepth = DefaultStableDepth
+	}
+	m := &AdaptorMock{
+		cfg:      cfg,
+		balances: map[string]map[string]*big.Int{},
+		nonces:   map[string]uint64{},
+		txs:      map[string]*mockTx{},
+		mappings: map[string]string{},
+	}
+	m.blocks = []*mockBlock{m.newBlock(nil)}
+	return m
+}
+
+func (m *AdaptorMock) newBlock(txs [][]byte) *mockBlock {
+	b := &mockBlock{txs: txs}
+	if len(m.blocks) > 0 {
+		parent := m.blocks[len(m.blocks)-1]
+		b.height = parent.height + 1
+		b.parent = parent.id
+	}
+	b.timestamp = genesisTimestamp + uint64(b.height)*DefaultBlockTime
+	height := make([]byte, 8)
+	binary.BigEndian.PutUint64(height, uint64(b.height))
+	b.id = crypto.Keccak256(b.parent, height, crypto.Keccak256(txs...))
+	return b
+}
+
+// SetBalance sets the balance of an address, it works as a faucet in tests.
+func (m *AdaptorMock) SetBalance(address, asset string, amount *big.Int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.balanceOf(address, asset).Set(amount)
+}
+
+// SetMappingAddress binds a chain address to a PalletOne address.
+func (m *AdaptorMock) SetMappingAddress(chainAddress, ptnAddress string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mappings[chainAddress] = ptnAddress
+}
+
+// Mine packs all pending transactions into the next block and then mines
+// count-1 empty blocks, it returns the height of the last block.
+func (m *AdaptorMock) Mine(count int) uint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 0; i < count; i++ {
+		b := m.newBlock(m.pending)
+		for index, id := range m.pending {
+			tx := m.txs[hex.EncodeToString(id)]
+			tx.height = b.height
+			tx.index = uint(index)
+			tx.inBlock = true
+		}
+		m.pending = nil
+		m.blocks = append(m.blocks, b)
+	}
+	return m.latest().height
+}
+
+func (m *AdaptorMock) latest() *mockBlock {
+	return m.blocks[len(m.blocks)-1]
+}
+
+func (m *AdaptorMock) isStable(height uint) bool {
+	return m.latest().height >= height+m.cfg.StableDepth
+}
+
+func (m *AdaptorMock) balanceOf(address, asset string) *big.Int {
+	if asset == "" {
+		asset = m.cfg.Asset
+	}
+	assets, ok := m.balances[address]
+	if !ok {
+		assets = map[string]*big.Int{}
+		m.balances[address] = assets
+	}
+	b, ok := assets[asset]
+	if !ok {
+		b = new(big.Int)
+		assets[asset] = b
+	}
+	return b
+}
+
+func (m *AdaptorMock) NewPrivateKey(input *adaptor.NewPrivateKeyInput) (*adaptor.NewPrivateKeyOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	//按种子和序号生成私钥，保证结果是确定的
+	for {
+		m.keyCount++
+		count := make([]byte, 8)
+		binary.BigEndian.PutUint64(count, m.keyCount)
+		priKey := crypto.Keccak256([]byte(m.cfg.Seed), count)
+		if _, err := crypto.ToECDSA(priKey); err == nil {
+			return &adaptor.NewPrivateKeyOutput{PrivateKey: priKey}, nil
+		}
+	}
+}
+
+func (m *AdaptorMock) GetPublicKey(input *adaptor.GetPublicKeyInput) (*adaptor.GetPublicKeyOutput, error) {
+	prvKey, err := crypto.ToECDSA(input.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.GetPublicKeyOutput{PublicKey: crypto.CompressPubkey(&prvKey.PublicKey)}, nil
+}
+
+func (m *AdaptorMock) GetAddress(key *adaptor.GetAddressInput) (*adaptor.GetAddressOutput, error) {
+	if len(key.Key) == 0 {
+		return nil, errors.New("mock chain: empty public key")
+	}
+	return &adaptor.GetAddressOutput{Address: pubKeyToAddress(key.Key)}, nil
+}
+
+func pubKeyToAddress(pubKey []byte) string {
+	return addressPrefix + hex.EncodeToString(crypto.Keccak256(pubKey)[12:])
+}
+
+func (m *AdaptorMock) GetPalletOneMappingAddress(addr *adaptor.GetPalletOneMappingAddressInput) (
+	*adaptor.GetPalletOneMappingAddressOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if addr.ChainAddress != "" {
+		if ptnAddr, ok := m.mappings[addr.ChainAddress]; ok {
+			return &adaptor.GetPalletOneMappingAddressOutput{PalletOneAddress: ptnAddr,
+				ChainAddress: addr.ChainAddress}, nil
+		}
+		return nil, ErrMappingNotFound
+	}
+	for chainAddr, ptnAddr := range m.mappings {
+		if ptnAddr == addr.PalletOneAddress {
+			return &adaptor.GetPalletOneMappingAddressOutput{PalletOneAddress: ptnAddr,
+				ChainAddress: chainAddr}, nil
+		}
+	}
+	return nil, ErrMappingNotFound
+}
+
+//签名格式为 压缩公钥(33字节) + DER签名，便于验证签名者地址
+func sign(priKey, msg []byte) ([]byte, error) {
+	prvKey, err := crypto.ToECDSA(priKey)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(msg), prvKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(crypto.CompressPubkey(&prvKey.PublicKey), sig...), nil
+}
+
+func verify(pubKey, msg, signature []byte) bool {
+	if len(signature) <= 33 {
+		return false
+	}
+	if pubKey != nil && hex.EncodeToString(pubKey) != hex.EncodeToString(signature[:33]) {
+		return false
+	}
+	return crypto.VerifySignature(signature[:33], crypto.Keccak256(msg), signature[33:])
+}
+
+func (m *AdaptorMock) SignMessage(input *adaptor.SignMessageInput) (*adaptor.SignMessageOutput, error) {
+	sig, err := sign(input.PrivateKey, input.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.SignMessageOutput{Signature: sig}, nil
+}
+
+func (m *AdaptorMock) VerifySignature(input *adaptor.VerifySignatureInput) (*adaptor.VerifySignatureOutput, error) {
+	return &adaptor.VerifySignatureOutput{Pass: verify(input.PublicKey, input.Message, input.Signature)}, nil
+}
+
+func (m *AdaptorMock) SignTransaction(input *adaptor.SignTransactionInput) (*adaptor.SignTransactionOutput, error) {
+	sig, err := sign(input.PrivateKey, input.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.SignTransactionOutput{Signature: sig}, nil
+}
+
+func (m *AdaptorMock) BindTxAndSignature(input *adaptor.BindTxAndSignatureInput) (
+	*adaptor.BindTxAndSignatureOutput, error) {
+	signed, err := json.Marshal(&signedTx{Tx: input.Transaction, Signs: input.Signs})
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.BindTxAndSignatureOutput{SignedTx: signed}, nil
+}
+
+func (m *AdaptorMock) CalcTxHash(input *adaptor.CalcTxHashInput) (*adaptor.CalcTxHashOutput, error) {
+	return &adaptor.CalcTxHashOutput{Hash: txHash(input.Transaction)}, nil
+}
+
+//交易ID只与未签名交易有关
+func txHash(tx []byte) []byte {
+	signed := &signedTx{}
+	if err := json.Unmarshal(tx, signed); err == nil && len(signed.Tx) > 0 {
+		tx = signed.Tx
+	}
+	return crypto.Keccak256(tx)
+}
+
+func (m *AdaptorMock) CreateTransferTokenTx(input *adaptor.CreateTransferTokenTxInput) (
+	*adaptor.CreateTransferTokenTxOutput, error) {
+	if input.Amount == nil || input.Amount.Amount == nil || input.Amount.Amount.Sign() <= 0 {
+		return nil, ErrInvalidTx
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &rawTx{From: input.FromAddress, To: input.ToAddress, Amount: input.Amount, Fee: input.Fee,
+		Nonce: m.nonces[input.FromAddress], Extra: input.Extra}
+	if tx.Amount.Asset == "" {
+		tx.Amount = adaptor.NewAmountAsset(tx.Amount.Amount, m.cfg.Asset)
+	}
+	if tx.Fee == nil || tx.Fee.Amount == nil {
+		tx.Fee = adaptor.NewAmountAssetUint64(0, tx.Amount.Asset)
+	}
+	m.nonces[input.FromAddress]++
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.CreateTransferTokenTxOutput{Transaction: raw}, nil
+}
+
+// SendTransaction checks the signature and balance of the sender, applies the
+// transfer and puts the transaction into the pending list until Mine is called.
+func (m *AdaptorMock) SendTransaction(input *adaptor.SendTransactionInput) (*adaptor.SendTransactionOutput, error) {
+	signed := &signedTx{}
+	if err := json.Unmarshal(input.Transaction, signed); err != nil {
+		return nil, ErrInvalidTx
+	}
+	tx := &rawTx{}
+	if err := json.Unmarshal(signed.Tx, tx); err != nil || tx.Amount == nil || tx.Amount.Amount == nil {
+		return nil, ErrInvalidTx
+	}
+	signedByFrom := false
+	for _, sig := range signed.Signs {
+		if len(sig) > 33 && pubKeyToAddress(sig[:33]) == tx.From && verify(nil, signed.Tx, sig) {
+			signedByFrom = true
+			break
+		}
+	}
+	if !signedByFrom {
+		return nil, ErrInvalidSignature
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := crypto.Keccak256(signed.Tx)
+	key := hex.EncodeToString(id)
+	if _, ok := m.txs[key]; ok {
+		return &adaptor.SendTransactionOutput{TxID: id}, nil
+	}
+	fee := new(big.Int)
+	if tx.Fee != nil && tx.Fee.Amount != nil {
+		fee = tx.Fee.Amount
+	}
+	from := m.balanceOf(tx.From, tx.Amount.Asset)
+	total := new(big.Int).Add(tx.Amount.Amount, fee)
+	if tx.Fee != nil && tx.Fee.Asset != "" && tx.Fee.Asset != tx.Amount.Asset {
+		feeBalance := m.balanceOf(tx.From, tx.Fee.Asset)
+		if from.Cmp(tx.Amount.Amount) < 0 || feeBalance.Cmp(fee) < 0 {
+			return nil, ErrInsufficientBalance
+		}
+		feeBalance.Sub(feeBalance, fee)
+		total = tx.Amount.Amount
+	} else if from.Cmp(total) < 0 {
+		return nil, ErrInsufficientBalance
+	}
+	from.Sub(from, total)
+	to := m.balanceOf(tx.To, tx.Amount.Asset)
+	to.Add(to, tx.Amount.Amount)
+
+	m.txs[key] = &mockTx{id: id, raw: input.Transaction, tx: tx}
+	m.txOrder = append(m.txOrder, key)
+	m.pending = append(m.pending, id)
+	return &adaptor.SendTransactionOutput{TxID: id}, nil
+}
+
+func (m *AdaptorMock) basicInfo(tx *mockTx) adaptor.TxBasicInfo {
+	info := adaptor.TxBasicInfo{
+		TxID:           tx.id,
+		TxRawData:      tx.raw,
+		CreatorAddress: tx.tx.From,
+		TargetAddress:  tx.tx.To,
+		IsInBlock:      tx.inBlock,
+		IsSuccess:      tx.inBlock,
+	}
+	if tx.inBlock {
+		b := m.blocks[tx.height]
+		info.IsStable = m.isStable(tx.height)
+		info.BlockID = b.id
+		info.BlockHeight = b.height
+		info.TxIndex = tx.index
+		info.Timestamp = b.timestamp
+	}
+	return info
+}
+
+func (m *AdaptorMock) getTx(id []byte) (*mockTx, error) {
+	tx, ok := m.txs[hex.EncodeToString(id)]
+	if !ok {
+		return nil, ErrTxNotFound
+	}
+	return tx, nil
+}
+
+func (m *AdaptorMock) GetTxBasicInfo(input *adaptor.GetTxBasicInfoInput) (*adaptor.GetTxBasicInfoOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tx, err := m.getTx(input.TxID)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.GetTxBasicInfoOutput{Tx: m.basicInfo(tx)}, nil
+}
+
+func (m *AdaptorMock) transferTx(tx *mockTx) *adaptor.SimpleTransferTokenTx {
+	return &adaptor.SimpleTransferTokenTx{
+		TxBasicInfo: m.basicInfo(tx),
+		FromAddress: tx.tx.From,
+		ToAddress:   tx.tx.To,
+		Amount:      tx.tx.Amount,
+		Fee:         tx.tx.Fee,
+		AttachData:  tx.tx.Extra,
+	}
+}
+
+func (m *AdaptorMock) GetTransferTx(input *adaptor.GetTransferTxInput) (*adaptor.GetTransferTxOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tx, err := m.getTx(input.TxID)
+	if err != nil {
+		return nil, err
+	}
+	return &adaptor.GetTransferTxOutput{Tx: *m.transferTx(tx)}, nil
+}
+
+func (m *AdaptorMock) GetBlockInfo(input *adaptor.GetBlockInfoInput) (*adaptor.GetBlockInfoOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var b *mockBlock
+	switch {
+	case input.Latest:
+		b = m.latest()
+	case len(input.BlockID) > 0:
+		for _, block := range m.blocks {
+			if hex.EncodeToString(block.id) == hex.EncodeToString(input.BlockID) {
+				b = block
+				break
+			}
+		}
+	case input.Height < uint64(len(m.blocks)):
+		b = m.blocks[input.Height]
+	}
+	if b == nil {
+		return nil, ErrBlockNotFound
+	}
+	return &adaptor.GetBlockInfoOutput{Block: adaptor.BlockInfo{
+		BlockID:       b.id,
+		BlockHeight:   b.height,
+		Timestamp:     b.timestamp,
+		ParentBlockID: b.parent,
+		TxsRoot:       crypto.Keccak256(b.txs...),
+		IsStable:      m.isStable(b.height),
+	}}, nil
+}
+
+func (m *AdaptorMock) GetBalance(input *adaptor.GetBalanceInput) (*adaptor.GetBalanceOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	asset := input.Asset
+	if asset == "" {
+		asset = m.cfg.Asset
+	}
+	balance := new(big.Int).Set(m.balanceOf(input.Address, asset))
+	return &adaptor.GetBalanceOutput{Balance: *adaptor.NewAmountAsset(balance, asset)}, nil
+}
+
+func (m *AdaptorMock) GetAssetDecimal(asset *adaptor.GetAssetDecimalInput) (*adaptor.GetAssetDecimalOutput, error) {
+	return &adaptor.GetAssetDecimalOutput{Decimal: m.cfg.Decimal}, nil
+}
+
+func (m *AdaptorMock) GetAddrTxHistory(input *adaptor.GetAddrTxHistoryInput) (*adaptor.GetAddrTxHistoryOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	txs := []*adaptor.SimpleTransferTokenTx{}
+	for _, key := range m.txOrder {
+		tx := m.txs[key]
+		if input.Asset != "" && tx.tx.Amount.Asset != input.Asset {
+			continue
+		}
+		fromMatch := input.FromAddress == "" || tx.tx.From == input.FromAddress
+		toMatch := input.ToAddress == "" || tx.tx.To == input.ToAddress
+		if input.AddressLogicAndOr {
+			if !fromMatch || !toMatch {
+				continue
+			}
+		} else if input.FromAddress != "" && input.ToAddress != "" {
+			if tx.tx.From != input.FromAddress && tx.tx.To != input.ToAddress {
+				continue
+			}
+		} else if !fromMatch || !toMatch {
+			continue
+		}
+		txs = append(txs, m.transferTx(tx))
+	}
+	if !input.Asc {
+		//默认按时间从新到老
+		for i, j := 0, len(txs)-1; i < j; i, j = i+1, j-1 {
+			txs[i], txs[j] = txs[j], txs[i]
+		}
+	}
+	count := uint32(len(txs))
+	if input.PageSize > 0 {
+		start := input.PageSize * input.PageIndex
+		if start > count {
+			start = count
+		}
+		end := start + input.PageSize
+		if end > count {
+			end = count
+		}
+		txs = txs[start:end]
+	}
+	return &adaptor.GetAddrTxHistoryOutput{Txs: txs, Count: count}, nil
+}
+
+func (m *AdaptorMock) CreateMultiSigAddress(input *adaptor.CreateMultiSigAddressInput) (
+	*adaptor.CreateMultiSigAddressOutput, error) {
+	if input.SignCount <= 0 || input.SignCount > len(input.Keys) {
+		return nil, fmt.Errorf("mock chain: invalid sign count %d of %d keys", input.SignCount, len(input.Keys))
+	}
+	keys := make([]string, len(input.Keys))
+	for i, k := range input.Keys {
+		keys[i] = hex.EncodeToString(k)
+	}
+	sort.Strings(keys)
+	data := fmt.Sprintf("%d:%s", input.SignCount, strings.Join(keys, ","))
+	return &adaptor.CreateMultiSigAddressOutput{Address: addressPrefix + hex.EncodeToString(
+		crypto.Keccak256([]byte(data))[12:])}, nil
+}
+
+var _ adaptor.ICryptoCurrency = (*AdaptorMock)(nil)
@@ -0,0 +1,166 @@
+package mockadaptor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/palletone/adaptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAccount(t *testing.T, m *AdaptorMock) ([]byte, []byte, string) {
+	key, err := m.NewPrivateKey(&adaptor.NewPrivateKeyInput{})
+	assert.Nil(t, err)
+	pub, err := m.GetPublicKey(&adaptor.GetPublicKeyInput{PrivateKey: key.PrivateKey})
+	assert.Nil(t, err)
+	addr, err := m.GetAddress(&adaptor.GetAddressInput{Key: pub.PublicKey})
+	assert.Nil(t, err)
+	return key.PrivateKey, pub.PublicKey, addr.Address
+}
+
+func transfer(t *testing.T, m *AdaptorMock, priKey []byte, from, to string, amount uint64) ([]byte, error) {
+	tx, err := m.CreateTransferTokenTx(&adaptor.CreateTransferTokenTxInput{FromAddress: from, ToAddress: to,
+		Amount: adaptor.NewAmountAssetUint64(amount, ""), Fee: adaptor.NewAmountAssetUint64(1, "")})
+	assert.Nil(t, err)
+	sig, err := m.SignTransaction(&adaptor.SignTransactionInput{PrivateKey: priKey, Transaction: tx.Transaction})
+	assert.Nil(t, err)
+	signed, err := m.BindTxAndSignature(&adaptor.BindTxAndSignatureInput{Transaction: tx.Transaction,
+		Signs: [][]byte{sig.Signature}})
+	assert.Nil(t, err)
+	hash, err := m.CalcTxHash(&adaptor.CalcTxHashInput{Transaction: signed.SignedTx})
+	assert.Nil(t, err)
+	sent, err := m.SendTransaction(&adaptor.SendTransactionInput{Transaction: signed.SignedTx})
+	if err != nil {
+		return nil, err
+	}
+	assert.Equal(t, hash.Hash, sent.TxID)
+	return sent.TxID, nil
+}
+
+func balance(t *testing.T, m *AdaptorMock, addr string) int64 {
+	b, err := m.GetBalance(&adaptor.GetBalanceInput{Address: addr})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultAsset, b.Balance.Asset)
+	return b.Balance.Amount.Int64()
+}
+
+func TestAdaptorMock_Deterministic(t *testing.T) {
+	_, pub1, addr1 := newAccount(t, NewAdaptorMock(Config{Seed: "test"}))
+	_, pub2, addr2 := newAccount(t, NewAdaptorMock(Config{Seed: "test"}))
+	assert.Equal(t, pub1, pub2)
+	assert.Equal(t, addr1, addr2)
+
+	_, _, addr3 := newAccount(t, NewAdaptorMock(Config{Seed: "other"}))
+	assert.NotEqual(t, addr1, addr3)
+}
+
+func TestAdaptorMock_SignMessage(t *testing.T) {
+	m := NewAdaptorMock(Config{})
+	priKey, pubKey, _ := newAccount(t, m)
+	msg := []byte("hello palletone")
+	sig, err := m.SignMessage(&adaptor.SignMessageInput{PrivateKey: priKey, Message: msg})
+	assert.Nil(t, err)
+
+	out, err := m.VerifySignature(&adaptor.VerifySignatureInput{Message: msg, Signature: sig.Signature,
+		PublicKey: pubKey})
+	assert.Nil(t, err)
+	assert.True(t, out.Pass)
+	out, err = m.VerifySignature(&adaptor.VerifySignatureInput{Message: []byte("other"),
+		Signature: sig.Signature, PublicKey: pubKey})
+	assert.Nil(t, err)
+	assert.False(t, out.Pass)
+}
+
+func TestAdaptorMock_Transfer(t *testing.T) {
+	m := NewAdaptorMock(Config{StableDepth: 2})
+	priKey, _, alice := newAccount(t, m)
+	_, _, bob := newAccount(t, m)
+	m.SetBalance(alice, "", big.NewInt(100))
+
+	txID, err := transfer(t, m, priKey, alice, bob, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(39), balance(t, m, alice))
+	assert.Equal(t, int64(60), balance(t, m, bob))
+
+	//余额不足
+	_, err = transfer(t, m, priKey, alice, bob, 60)
+	assert.Equal(t, ErrInsufficientBalance, err)
+
+	//不是付款人签名
+	bobKey, _, _ := newAccount(t, m)
+	_, err = transfer(t, m, bobKey, alice, bob, 1)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	info, err := m.GetTxBasicInfo(&adaptor.GetTxBasicInfoInput{TxID: txID})
+	assert.Nil(t, err)
+	assert.False(t, info.Tx.IsInBlock)
+
+	m.Mine(1)
+	tx, err := m.GetTransferTx(&adaptor.GetTransferTxInput{TxID: txID})
+	assert.Nil(t, err)
+	assert.True(t, tx.Tx.IsInBlock)
+	assert.False(t, tx.Tx.IsStable)
+	assert.Equal(t, uint(1), tx.Tx.BlockHeight)
+	assert.Equal(t, alice, tx.Tx.FromAddress)
+	assert.Equal(t, int64(60), tx.Tx.Amount.Amount.Int64())
+
+	m.Mine(2)
+	tx, err = m.GetTransferTx(&adaptor.GetTransferTxInput{TxID: txID})
+	assert.Nil(t, err)
+	assert.True(t, tx.Tx.IsStable)
+
+	block, err := m.GetBlockInfo(&adaptor.GetBlockInfoInput{Latest: true})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(3), block.Block.BlockHeight)
+	block, err = m.GetBlockInfo(&adaptor.GetBlockInfoInput{BlockID: tx.Tx.BlockID})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), block.Block.BlockHeight)
+	assert.True(t, block.Block.IsStable)
+
+	_, err = m.GetTransferTx(&adaptor.GetTransferTxInput{TxID: []byte("unknown")})
+	assert.Equal(t, ErrTxNotFound, err)
+}
+
+func TestAdaptorMock_GetAddrTxHistory(t *testing.T) {
+	m := NewAdaptorMock(Config{})
+	priKey, _, alice := newAccount(t, m)
+	_, _, bob := newAccount(t, m)
+	m.SetBalance(alice, "", big.NewInt(100))
+	ids := [][]byte{}
+	for i := 0; i < 3; i++ {
+		id, err := transfer(t, m, priKey, alice, bob, 10)
+		assert.Nil(t, err)
+		ids = append(ids, id)
+	}
+	m.Mine(1)
+
+	out, err := m.GetAddrTxHistory(&adaptor.GetAddrTxHistoryInput{FromAddress: alice, Asc: true})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), out.Count)
+	assert.Equal(t, ids[0], out.Txs[0].TxID)
+
+	out, err = m.GetAddrTxHistory(&adaptor.GetAddrTxHistoryInput{ToAddress: bob, PageSize: 2, PageIndex: 0})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), out.Count)
+	assert.Equal(t, 2, len(out.Txs))
+	assert.Equal(t, ids[2], out.Txs[0].TxID)
+
+	out, err = m.GetAddrTxHistory(&adaptor.GetAddrTxHistoryInput{FromAddress: bob, ToAddress: alice,
+		AddressLogicAndOr: true})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), out.Count)
+}
+
+func TestAdaptorMock_CreateMultiSigAddress(t *testing.T) {
+	m := NewAdaptorMock(Config{})
+	_, pub1, _ := newAccount(t, m)
+	_, pub2, _ := newAccount(t, m)
+	a, err := m.CreateMultiSigAddress(&adaptor.CreateMultiSigAddressInput{Keys: [][]byte{pub1, pub2}, SignCount: 2})
+	assert.Nil(t, err)
+	b, err := m.CreateMultiSigAddress(&adaptor.CreateMultiSigAddressInput{Keys: [][]byte{pub2, pub1}, SignCount: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, a.Address, b.Address)
+
+	_, err = m.CreateMultiSigAddress(&adaptor.CreateMultiSigAddressInput{Keys: [][]byte{pub1}, SignCount: 2})
+	assert.NotNil(t, err)
+}
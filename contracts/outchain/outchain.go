@@ -23,7 +23,8 @@ type Config struct {
 type Ada struct {
 	Btc        BTC
 	Eth        ETH
-	ChainKeyKV map[string]KeyInfo //chainName --- keyInfo
+	ChainKeyKV map[string]KeyInfo     //chainName --- keyInfo, 已废弃，私钥保存在加密的keystore中
+	Chains     map[string]ChainConfig //chainName --- 适配器配置，可以注册内置之外的链
 }
 
 type BTC struct {
@@ -75,6 +76,7 @@ var DefaultConfig = Config{
 				AddressKeys:   map[string][]byte{},
 			},
 		},
+		Chains: map[string]ChainConfig{},
 	},
 }
 
@@ -189,7 +191,7 @@ func saveConfigTest() error {
 	return nil
 }
 
-func getNewKey(params []byte, iadaptor adaptor.ICryptoCurrency) ([]byte, string, error) {
+func getNewKey(params []byte, iadaptor adaptor.IUtility) ([]byte, string, error) {
 	var input adaptor.NewPrivateKeyInput
 	err := json.Unmarshal(params, &input)
 	if err != nil {
@@ -213,58 +215,20 @@ func getNewKey(params []byte, iadaptor adaptor.ICryptoCurrency) ([]byte, string,
 	return outputKey.PrivateKey, outputAddr.Address, nil
 }
 
-func GetJuryKeyInfo(chaincode, chainName string, params []byte, iadaptor adaptor.ICryptoCurrency) ([]byte, error) {
-	err := GetConfigTest()
+func GetJuryKeyInfo(chaincode, chainName string, params []byte, iadaptor adaptor.IUtility) ([]byte, error) {
+	priKey, err := ks.chaincodeKey(chaincode, chainName, params, iadaptor)
 	if err != nil {
-		log.Error("loadconfig() failed !!!!!!")
+		log.Errorf("GetJuryKeyInfo() failed  %s !!!!!!", err.Error())
 		return []byte{}, err
 	}
-
-	if _, ok := cfg.Ada.ChainKeyKV[chainName]; !ok {
-		cfg.Ada.ChainKeyKV[chainName] = KeyInfo{
-			ChaincodeKeys: map[string][]byte{},
-			AddressKeys:   map[string][]byte{},
-		}
-	}
-	if _, exist := cfg.Ada.ChainKeyKV[chainName].ChaincodeKeys[chaincode]; exist {
-		return cfg.Ada.ChainKeyKV[chainName].ChaincodeKeys[chaincode], nil
-	} else {
-		priKey, addr, err := getNewKey(params, iadaptor)
-		if err != nil {
-			log.Errorf("getNewKey() failed  %s !!!!!!", err.Error())
-			return []byte{}, err
-		}
-		cfg.Ada.ChainKeyKV[chainName].ChaincodeKeys[chaincode] = priKey
-		cfg.Ada.ChainKeyKV[chainName].AddressKeys[addr] = priKey
-
-		saveConfigTest() // todo save config
-
-		return priKey, nil
-	}
+	return priKey, nil
 }
 
-func GetJuryAddress(chaincode, chainName string, params []byte, iadaptor adaptor.ICryptoCurrency) (string, error) {
-	err := GetConfigTest()
+func GetJuryAddress(chaincode, chainName string, params []byte, iadaptor adaptor.IUtility) (string, error) {
+	addr, err := ks.address(chaincode, chainName, params, iadaptor)
 	if err != nil {
-		log.Error("loadconfig() failed !!!!!!")
+		log.Errorf("GetJuryAddress() failed  %s !!!!!!", err.Error())
 		return "", err
 	}
-
-	if _, ok := cfg.Ada.ChainKeyKV[chainName]; ok {
-		for addr := range cfg.Ada.ChainKeyKV[chainName].AddressKeys {
-			return addr, nil
-		}
-		return "", err
-	} else {
-		priKey, addr, err := getNewKey(params, iadaptor)
-		if err != nil {
-			log.Error("getNewKey() failed !!!!!!")
-			return "", err
-		}
-		cfg.Ada.ChainKeyKV[chainName].ChaincodeKeys[chaincode] = priKey
-		cfg.Ada.ChainKeyKV[chainName].AddressKeys[addr] = priKey
-		// todo save config
-		saveConfigTest()
-		return addr, nil
-	}
+	return addr, nil
 }
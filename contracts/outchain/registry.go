@@ -0,0 +1,191 @@
+package outchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/palletone/adaptor"
+	"github.com/palletone/eth-adaptor"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/outchain/mockadaptor"
+)
+
+// AdaptorFactory creates an adaptor of a chain from the params in config.
+// The adaptor must implement adaptor.IUtility, and usually also
+// adaptor.ICryptoCurrency or adaptor.ISmartContract.
+type AdaptorFactory func(params map[string]string) (adaptor.IUtility, error)
+
+type ChainConfig struct {
+	Adaptor string            //适配器类型，对应RegisterAdaptorFactory注册的名称
+	Params  map[string]string //适配器参数
+}
+
+var (
+	registryLock sync.RWMutex
+	factories    = map[string]AdaptorFactory{ //内置的适配器类型
+		"eth":   newETHAdaptor,
+		"erc20": newERC20Adaptor,
+		"mock":  newMockAdaptor,
+	}
+	allChain = map[string]adaptor.IUtility{} //chainName --- adaptor
+)
+
+// RegisterAdaptorFactory registers an adaptor type, chains in config refer to
+// it by name.
+func RegisterAdaptorFactory(name string, factory AdaptorFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	factories[strings.ToLower(name)] = factory
+}
+
+// RegisterAdaptor registers the adaptor of a chain, a registered chain with the
+// same name is replaced.
+func RegisterAdaptor(chainName string, ada adaptor.IUtility) error {
+	if ada == nil {
+		return fmt.Errorf("adaptor of chain %s is nil", chainName)
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	allChain[strings.ToLower(chainName)] = ada
+	return nil
+}
+
+func UnregisterAdaptor(chainName string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	delete(allChain, strings.ToLower(chainName))
+}
+
+func GetAdaptor(chainName string) (adaptor.IUtility, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	ada, ok := allChain[strings.ToLower(chainName)]
+	return ada, ok
+}
+
+// Adaptors returns the sorted names of all registered chains.
+func Adaptors() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	names := make([]string, 0, len(allChain))
+	for name := range allChain {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newAdaptor(chain ChainConfig) (adaptor.IUtility, error) {
+	registryLock.RLock()
+	factory, ok := factories[strings.ToLower(chain.Adaptor)]
+	registryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown adaptor type: %s", chain.Adaptor)
+	}
+	params := chain.Params
+	if params == nil {
+		params = map[string]string{}
+	}
+	return factory(params)
+}
+
+// RegisterAdaptors registers the built-in chains and the chains configured in
+// outchain.toml, it is called during node startup.
+func RegisterAdaptors() error {
+	if err := GetConfigTest(); err != nil {
+		return err
+	}
+	return registerAdaptors(cfg.Ada.Chains)
+}
+
+//注册内置的链和配置文件中的链，配置文件中的同名链会替换内置的链
+func registerAdaptors(chains map[string]ChainConfig) error {
+	if ada := GetETHAdaptor(); ada != nil {
+		RegisterAdaptor("eth", ada)
+	}
+	if ada := GetERC20Adaptor(); ada != nil {
+		RegisterAdaptor("erc20", ada)
+	}
+
+	var errs []string
+	for chainName, chain := range chains {
+		ada, err := newAdaptor(chain)
+		if err == nil {
+			err = RegisterAdaptor(chainName, ada)
+		}
+		if err != nil {
+			log.Warn("Register outchain adaptor failed", "chain", chainName, "err", err)
+			errs = append(errs, fmt.Sprintf("%s: %s", chainName, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func ethConfig(params map[string]string) (ETH, error) {
+	eth := cfg.Ada.Eth
+	if netID, ok := params["NetID"]; ok {
+		id, err := strconv.Atoi(netID)
+		if err != nil {
+			return eth, fmt.Errorf("invalid NetID: %s", netID)
+		}
+		eth.NetID = id
+	}
+	if url, ok := params["Rawurl"]; ok {
+		eth.Rawurl = url
+	}
+	if url, ok := params["TxQueryUrl"]; ok {
+		eth.TxQueryUrl = url
+	}
+	return eth, nil
+}
+
+func newETHAdaptor(params map[string]string) (adaptor.IUtility, error) {
+	eth, err := ethConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	var ethAdaptor ethadaptor.AdaptorETH
+	ethAdaptor.NetID = eth.NetID
+	ethAdaptor.Rawurl = eth.Rawurl
+	ethAdaptor.TxQueryUrl = eth.TxQueryUrl
+	return &ethAdaptor, nil
+}
+
+func newERC20Adaptor(params map[string]string) (adaptor.IUtility, error) {
+	eth, err := ethConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	var ethAdaptor ethadaptor.AdaptorErc20
+	ethAdaptor.NetID = eth.NetID
+	ethAdaptor.Rawurl = eth.Rawurl
+	ethAdaptor.TxQueryUrl = eth.TxQueryUrl
+	return &ethAdaptor, nil
+}
+
+func newMockAdaptor(params map[string]string) (adaptor.IUtility, error) {
+	mockCfg := mockadaptor.Config{Seed: params["Seed"], Asset: params["Asset"]}
+	if decimal, ok := params["Decimal"]; ok {
+		d, err := strconv.ParseUint(decimal, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Decimal: %s", decimal)
+		}
+		mockCfg.Decimal = uint(d)
+	}
+	if depth, ok := params["StableDepth"]; ok {
+		d, err := strconv.ParseUint(depth, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid StableDepth: %s", depth)
+		}
+		mockCfg.StableDepth = uint(d)
+	}
+	return mockadaptor.NewAdaptorMock(mockCfg), nil
+}
@@ -0,0 +1,84 @@
+package outchain
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palletone/adaptor"
+	"github.com/palletone/go-palletone/contracts/outchain/mockadaptor"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterAdaptors(t *testing.T) {
+	defer UnregisterAdaptor("mymock")
+	defer UnregisterAdaptor("badmock")
+
+	err := registerAdaptors(map[string]ChainConfig{
+		"MyMock":  {Adaptor: "mock", Params: map[string]string{"Seed": "test", "Decimal": "18"}},
+		"badmock": {Adaptor: "mock", Params: map[string]string{"StableDepth": "x"}},
+		"unknown": {Adaptor: "unknown"},
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, Adaptors(), "eth")
+	assert.Contains(t, Adaptors(), "erc20")
+	assert.Contains(t, Adaptors(), "mymock")
+	assert.NotContains(t, Adaptors(), "badmock")
+	assert.NotContains(t, Adaptors(), "unknown")
+
+	ada, ok := GetAdaptor("MYMOCK")
+	assert.True(t, ok)
+	decimal, err := ada.(adaptor.ICryptoCurrency).GetAssetDecimal(&adaptor.GetAssetDecimalInput{})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(18), decimal.Decimal)
+
+	assert.NotNil(t, RegisterAdaptor("nil", nil))
+}
+
+func TestChainConfigToml(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outchain-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outchain.toml")
+
+	c := makeDefaultConfig()
+	c.Ada.Chains = map[string]ChainConfig{"mock": {Adaptor: "mock", Params: map[string]string{"Seed": "1"}}}
+	assert.Nil(t, makeConfigFile(&c, path))
+
+	loaded := Config{}
+	assert.Nil(t, loadConfig(path, &loaded))
+	assert.Equal(t, c.Ada.Chains, loaded.Ada.Chains)
+}
+
+func TestProcessOutChainCall_Mock(t *testing.T) {
+	m := mockadaptor.NewAdaptorMock(mockadaptor.Config{Seed: "test"})
+	assert.Nil(t, RegisterAdaptor("mock", m))
+	defer UnregisterAdaptor("mock")
+	m.SetBalance("mockaddr", "", big.NewInt(100))
+
+	params, _ := json.Marshal(&adaptor.GetBalanceInput{Address: "mockaddr"})
+	result, err := ProcessOutChainCall("sample_syscc", &pb.OutChainCall{OutChainName: "Mock",
+		Method: "GetBalance", Params: params})
+	assert.Nil(t, err)
+	output := adaptor.GetBalanceOutput{}
+	assert.Nil(t, json.Unmarshal([]byte(result), &output))
+	assert.Equal(t, int64(100), output.Balance.Amount.Int64())
+
+	//需要jury私钥的方法在keystore锁定时返回错误
+	if !IsKeyStoreUnlocked() {
+		_, err = ProcessOutChainCall("sample_syscc", &pb.OutChainCall{OutChainName: "mock",
+			Method: "GetJuryAddr", Params: []byte("{}")})
+		assert.Equal(t, ErrKeyStoreLocked, err)
+	}
+
+	_, err = ProcessOutChainCall("sample_syscc", &pb.OutChainCall{OutChainName: "unknown",
+		Method: "GetBalance", Params: params})
+	assert.NotNil(t, err)
+	_, err = ProcessOutChainCall("sample_syscc", &pb.OutChainCall{OutChainName: "mock",
+		Method: "Mine", Params: []byte("1")})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,111 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package golang
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/contractcfg"
+	cutil "github.com/palletone/go-palletone/vm/common"
+)
+
+//localBuild 合约以process方式运行时节点上没有docker，使用本地的go工具链编译，
+//输出与DockerBuild相同的binpackage.tar内容
+func localBuild(pkgname string, codePackage []byte, output io.Writer) error {
+	dir, err := ioutil.TempDir("", "ccbuild")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input")
+	if err := untarCodePackage(codePackage, input); err != nil {
+		return err
+	}
+	bin := filepath.Join(dir, "output", "chaincode")
+
+	env := getEnv()
+	if gopath, ok := env["GOPATH"]; ok && gopath != "" {
+		env["GOPATH"] = gopath + string(os.PathListSeparator) + input
+	} else {
+		env["GOPATH"] = input
+	}
+	//代码包是GOPATH的目录结构
+	env["GO111MODULE"] = "off"
+
+	timeout := contractcfg.GetConfig().ContractDeploytimeout
+	if timeout == 0 {
+		timeout = contractcfg.DefaultConfig.ContractDeploytimeout
+	}
+	log.Infof("local build chaincode %s", pkgname)
+	_, err = runProgram(env, timeout, "go", "build", "-ldflags", "-s -w", "-o", bin, pkgname)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(output)
+	if err := cutil.WriteFileToPackage(bin, "chaincode", tw); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+//把代码包解压到dir，代码包已经由ValidateDeploymentSpec检查过，这里只防止路径越界
+func untarCodePackage(codePackage []byte, dir string) error {
+	gr, err := gzip.NewReader(bytes.NewReader(codePackage))
+	if err != nil {
+		return fmt.Errorf("failure opening codepackage gzip stream: %s", err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file detected in payload: \"%s\"", header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			return err
+		}
+	}
+}
@@ -690,8 +690,16 @@ func (goPlatform *Platform) GenerateDockerBuild(cds *pb.ChaincodeDeploymentSpec,
 	}
 	log.Infof("building chaincode with tags: %s", gotags)
 
-	codepackage := bytes.NewReader(cds.CodePackage)
 	binpackage := bytes.NewBuffer(nil)
+	if contractcfg.GetConfig().IsProcessVm() {
+		if err = localBuild(pkgname, cds.CodePackage, binpackage); err != nil {
+			log.Debugf("localBuild err:%s", err)
+			return err
+		}
+		return cutil.WriteBytesToPackage("binpackage.tar", binpackage.Bytes(), tw)
+	}
+
+	codepackage := bytes.NewReader(cds.CodePackage)
 	err = util.DockerBuild(util.DockerBuildOptions{
 		//Cmd: fmt.Sprintf("GOPATH=$GOPATH:/chaincode/input go build -tags \"%s\" %s -o /chaincode/output/chaincode %s", gotags, ldflagsOpt, pkgname),
 		Cmd: fmt.Sprintf("GOPATH=$GOPATH:/chaincode/input go build -ldflags \"-s -w\" -o /chaincode/output/chaincode %s", pkgname),
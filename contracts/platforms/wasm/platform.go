@@ -0,0 +1,94 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasm
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/palletone/go-palletone/common/log"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	cutil "github.com/palletone/go-palletone/vm/common"
+	"github.com/palletone/go-palletone/vm/wasmcontroller"
+)
+
+// Platform for chaincodes compiled to WebAssembly, the path of chaincode is the .wasm file
+type Platform struct {
+}
+
+func (wasmPlatform *Platform) ValidateSpec(spec *pb.ChaincodeSpec) error {
+	if spec.ChaincodeId == nil || spec.ChaincodeId.Path == "" {
+		return errors.New("ChaincodeSpec's path cannot be empty")
+	}
+	fi, err := os.Stat(spec.ChaincodeId.Path)
+	if err != nil {
+		return fmt.Errorf("error validating chaincode path: %s", err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("path of wasm chaincode must be a file: %s", spec.ChaincodeId.Path)
+	}
+	return nil
+}
+
+func (wasmPlatform *Platform) ValidateDeploymentSpec(cds *pb.ChaincodeDeploymentSpec) error {
+	if len(cds.CodePackage) == 0 {
+		// Nothing to validate if no CodePackage was included
+		return nil
+	}
+	return wasmcontroller.ValidateCode(cds.CodePackage)
+}
+
+//合约模板中保存的就是校验过的wasm文件
+func (wasmPlatform *Platform) GetChainCodePayload(spec *pb.ChaincodeSpec) ([]byte, error) {
+	if err := wasmPlatform.ValidateSpec(spec); err != nil {
+		return nil, err
+	}
+	code, err := ioutil.ReadFile(spec.ChaincodeId.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := wasmcontroller.ValidateCode(code); err != nil {
+		log.Errorf("invalid wasm chaincode %s: %s", spec.ChaincodeId.Path, err)
+		return nil, err
+	}
+	return code, nil
+}
+
+func (wasmPlatform *Platform) GetDeploymentPayload(spec *pb.ChaincodeSpec) ([]byte, error) {
+	return wasmPlatform.GetChainCodePayload(spec)
+}
+
+//WASM合约不运行在docker中，构建包里只有合约代码
+func (wasmPlatform *Platform) GenerateDockerfile(cds *pb.ChaincodeDeploymentSpec) (string, error) {
+	return "", nil
+}
+
+func (wasmPlatform *Platform) GenerateDockerBuild(cds *pb.ChaincodeDeploymentSpec, tw *tar.Writer) error {
+	if len(cds.CodePackage) == 0 {
+		return errors.New("wasm chaincode code package is empty")
+	}
+	return cutil.WriteBytesToPackage(wasmcontroller.CodeFileName, cds.CodePackage, tw)
+}
+
+func (wasmPlatform *Platform) GetPlatformEnvPath(spec *pb.ChaincodeSpec) (string, error) {
+	return "", errors.New("undo")
+}
@@ -21,9 +21,12 @@ import (
 	"github.com/palletone/go-palletone/contracts/syscontract/debugcc"
 	"github.com/palletone/go-palletone/contracts/syscontract/deposit"
 	"github.com/palletone/go-palletone/contracts/syscontract/digitalidcc"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
 	"github.com/palletone/go-palletone/contracts/syscontract/partitioncc"
+	"github.com/palletone/go-palletone/contracts/syscontract/policycc"
 	"github.com/palletone/go-palletone/contracts/syscontract/prc20"
 	"github.com/palletone/go-palletone/contracts/syscontract/prc721"
+	"github.com/palletone/go-palletone/contracts/syscontract/schedulecc"
 	"github.com/palletone/go-palletone/contracts/syscontract/sysconfigcc"
 	"github.com/palletone/go-palletone/contracts/syscontract/vote"
 	"github.com/palletone/go-palletone/contracts/example/go/samplesyscc"
@@ -161,6 +164,33 @@ var systemChaincodes = []*SystemChaincode{
 		InitArgs:  [][]byte{},
 		Chaincode: &blacklistcc.BlacklistMgr{},
 	},
+	{
+		Id:        syscontract.FoundationContractAddress.Bytes(),
+		Enabled:   true,
+		Name:      "foundation_sycc",
+		Path:      "./FoundationContractAddress",
+		Version:   "ptn001",
+		InitArgs:  [][]byte{},
+		Chaincode: &foundationcc.FoundationMgr{},
+	},
+	{
+		Id:        syscontract.ScheduledPaymentContractAddress.Bytes(),
+		Enabled:   true,
+		Name:      "scheduled_payment_sycc",
+		Path:      "./ScheduledPaymentContractAddress",
+		Version:   "ptn001",
+		InitArgs:  [][]byte{},
+		Chaincode: &schedulecc.ScheduledPaymentChainCode{},
+	},
+	{
+		Id:        syscontract.PolicyContractAddress.Bytes(),
+		Enabled:   true,
+		Name:      "policy_sycc",
+		Path:      "./PolicyContractAddress",
+		Version:   "ptn001",
+		InitArgs:  [][]byte{},
+		Chaincode: &policycc.PolicyChainCode{},
+	},
 	//TODO add other system chaincodes ...
 }
 
@@ -45,6 +45,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Logger for the shim package.
@@ -56,13 +57,14 @@ var cert string
 var GlobalStateContractId = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
 var ERROR_ONLY_SYS_CONTRACT = errors.New("Only system contract can call this function.")
 
-//const (
-//
-//	minUnicodeRuneValue   = 0            //U+0000
-//	maxUnicodeRuneValue   = utf8.MaxRune //U+10FFFF - maximum (and unallocated) code point
-//	compositeKeyNamespace = "\x00"
-//	emptyKeySubstitute    = "\x01"
-//)
+const (
+	minUnicodeRuneValue   = 0            //U+0000
+	maxUnicodeRuneValue   = utf8.MaxRune //U+10FFFF - maximum (and unallocated) code point
+	compositeKeyNamespace = "\x00"
+	emptyKeySubstitute    = "\x01"
+	//不分页的范围查询每次从peer获取的状态数量
+	rangeQueryBatchSize = 100
+)
 
 // ChaincodeStub is an object passed to chaincode for shim side handling of
 // APIs.
@@ -570,6 +572,16 @@ func (stub *ChaincodeStub) GetTxTimestamp(rangeNumber uint32) (*timestamp.Timest
 	return timeStamp, nil
 }
 
+// GetTxHeight documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetTxHeight(rangeNumber uint32) (uint64, error) {
+	height, err := stub.handler.handleGetTimestamp(dagConstants.TIMESTAMP_COLLECTION_HEIGHT, rangeNumber,
+		stub.ContractId, stub.ChannelId, stub.TxID)
+	if err != nil {
+		return 0, errors.New("handleGetTimestamp failed")
+	}
+	return strconv.ParseUint(string(height), 10, 64)
+}
+
 // ------------- ChaincodeEvent API ----------------------
 
 // SetEvent documentation can be found in interfaces.go
@@ -608,7 +620,16 @@ func (stub *ChaincodeStub) GetContractID() ([]byte, string) {
 
 //获得该合约的Token余额
 func (stub *ChaincodeStub) GetTokenBalance(address string, token *modules.Asset) ([]*modules.InvokeTokens, error) {
-	return stub.handler.handleGetTokenBalance(address, token, stub.ContractId, stub.ChannelId, stub.TxID)
+	return stub.handler.handleGetTokenBalance(address, token, 0, stub.ContractId, stub.ChannelId, stub.TxID)
+}
+
+//获得某地址在指定高度的稳定单元时的Token余额
+func (stub *ChaincodeStub) GetTokenBalanceAt(address string, token *modules.Asset, height uint64) (
+	[]*modules.InvokeTokens, error) {
+	if height == 0 {
+		return nil, errors.New("height must be greater than 0")
+	}
+	return stub.handler.handleGetTokenBalance(address, token, height, stub.ContractId, stub.ChannelId, stub.TxID)
 }
 
 func (stub *ChaincodeStub) DefineToken(tokenType byte, define []byte, creator string) error {
@@ -634,6 +655,22 @@ func (stub *ChaincodeStub) PayOutToken(addr string, invokeTokens *modules.Amount
 		stub.TxID)
 }
 
+//ChargeInstructions documentation can be found in interfaces_stable.go
+func (stub *ChaincodeStub) ChargeInstructions(count uint64) error {
+	return stub.handler.handleChargeInstructions(count, stub.ContractId, stub.ChannelId, stub.TxID)
+}
+
+//InvokeContract documentation can be found in interfaces_stable.go
+func (stub *ChaincodeStub) InvokeContract(contractAddr string, args [][]byte,
+	invokeTokens []*modules.AmountAsset) pb.Response {
+	addr, err := common.StringToAddress(contractAddr)
+	if err != nil {
+		return Error(err.Error())
+	}
+	return stub.handler.handleInvokeContract(addr.Bytes(), args, invokeTokens, stub.ContractId, stub.ChannelId,
+		stub.TxID)
+}
+
 // 根据证书ID获得证书字节数据，不包含BEGIN和EN两行字符
 func (stub *ChaincodeStub) GetRequesterCert() (certBytes []byte, err error) {
 	if len(stub.args) <= 1 {
@@ -870,15 +907,11 @@ func (c *ChaincodeLogger) Criticalf(format string, args ...interface{}) {
 // currentLoc int
 //}
 
-// StateQueryIterator documentation can be found in interfaces.go
-//type StateQueryIterator struct {
-// *CommonIterator
-//}
-
 // HistoryQueryIterator documentation can be found in interfaces.go
-//type HistoryQueryIterator struct {
-// *CommonIterator
-//}
+type HistoryQueryIterator struct {
+	results []*modules.ContractStateHistory
+	current int
+}
 
 //type resultType uint8
 
@@ -887,89 +920,142 @@ func (c *ChaincodeLogger) Criticalf(format string, args ...interface{}) {
 // HISTORY_QUERY_RESULT
 //)
 
-//func (stub *ChaincodeStub) handleGetStateByRange(collection, startKey, endKey string) (StateQueryIteratorInterface, error) {
-// response, err := stub.handler.handleGetStateByRange(collection, startKey, endKey, stub.ChannelId, stub.TxID)
-// if err != nil {
-//    return nil, err
-// }
-// return &StateQueryIterator{CommonIterator: &CommonIterator{stub.handler, stub.ChannelId, stub.TxID, response, 0}},
-// nil
-//}
+// StateQueryIterator documentation can be found in interfaces.go
+// 缓存的状态读完后，不分页的查询根据bookmark从peer获取下一批
+type StateQueryIterator struct {
+	stub     *ChaincodeStub
+	endKey   string
+	results  []*modules.KeyValue
+	bookmark string
+	fetchAll bool
+	current  int
+}
+
+func (stub *ChaincodeStub) handleGetStateByRange(startKey, endKey string,
+	limit int32) (*modules.RangeQueryResult, error) {
+	return stub.handler.handleGetStateByRange(startKey, endKey, limit, stub.ContractId, stub.ChannelId, stub.TxID)
+}
+
+func (stub *ChaincodeStub) newStateQueryIterator(startKey, endKey string) (*StateQueryIterator, error) {
+	iter := &StateQueryIterator{stub: stub, endKey: endKey, bookmark: startKey, fetchAll: true}
+	if err := iter.fetchNextResults(); err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+//查询一页的状态，返回的迭代器只包含这一页
+func (stub *ChaincodeStub) getStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+	bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, errors.Errorf("invalid page size %d", pageSize)
+	}
+	if bookmark != "" {
+		if bookmark < startKey || (endKey != "" && bookmark >= endKey) {
+			return nil, nil, errors.Errorf("bookmark [%s] is out of the range", bookmark)
+		}
+		startKey = bookmark
+	}
+	result, err := stub.handleGetStateByRange(startKey, endKey, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	iter := &StateQueryIterator{stub: stub, endKey: endKey, results: result.KVs}
+	metadata := &QueryResponseMetadata{FetchedRecordsCount: int32(len(result.KVs)), Bookmark: result.Bookmark}
+	return iter, metadata, nil
+}
 
 // GetStateByRange documentation can be found in interfaces.go
-//func (stub *ChaincodeStub) GetStateByRange(startKey, endKey string) (StateQueryIteratorInterface, error) {
-// if startKey == "" {
-//    startKey = emptyKeySubstitute
-// }
-// if err := validateSimpleKeys(startKey, endKey); err != nil {
-//    return nil, err
-// }
-// collection := ""
-// return stub.handleGetStateByRange(collection, startKey, endKey)
-//}
+func (stub *ChaincodeStub) GetStateByRange(startKey, endKey string) (StateQueryIteratorInterface, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return stub.newStateQueryIterator(startKey, endKey)
+}
+
+// GetStateByRangeWithPagination documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+	bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+	return stub.getStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+}
 
 //CreateCompositeKey documentation can be found in interfaces.go
-//func (stub *ChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
-// return createCompositeKey(objectType, attributes)
-//}
+func (stub *ChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return createCompositeKey(objectType, attributes)
+}
 
 //SplitCompositeKey documentation can be found in interfaces.go
-//func (stub *ChaincodeStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
-// return splitCompositeKey(compositeKey)
-//}
+func (stub *ChaincodeStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return splitCompositeKey(compositeKey)
+}
 
-//func createCompositeKey(objectType string, attributes []string) (string, error) {
-// if err := validateCompositeKeyAttribute(objectType); err != nil {
-//    return "", err
-// }
-// ck := compositeKeyNamespace + objectType + string(minUnicodeRuneValue)
-// for _, att := range attributes {
-//    if err := validateCompositeKeyAttribute(att); err != nil {
-//       return "", err
-//    }
-//    ck += att + string(minUnicodeRuneValue)
-// }
-// return ck, nil
-//}
+func createCompositeKey(objectType string, attributes []string) (string, error) {
+	if err := validateCompositeKeyAttribute(objectType); err != nil {
+		return "", err
+	}
+	ck := compositeKeyNamespace + objectType + string(rune(minUnicodeRuneValue))
+	for _, att := range attributes {
+		if err := validateCompositeKeyAttribute(att); err != nil {
+			return "", err
+		}
+		ck += att + string(rune(minUnicodeRuneValue))
+	}
+	return ck, nil
+}
 
-//func splitCompositeKey(compositeKey string) (string, []string, error) {
-// componentIndex := 1
-// components := []string{}
-// for i := 1; i < len(compositeKey); i++ {
-//    if compositeKey[i] == minUnicodeRuneValue {
-//       components = append(components, compositeKey[componentIndex:i])
-//       componentIndex = i + 1
-//    }
-// }
-// return components[0], components[1:], nil
-//}
+func splitCompositeKey(compositeKey string) (string, []string, error) {
+	if len(compositeKey) == 0 || compositeKey[0] != compositeKeyNamespace[0] {
+		return "", nil, errors.Errorf("[%s] is not a composite key", compositeKey)
+	}
+	componentIndex := 1
+	components := []string{}
+	for i := 1; i < len(compositeKey); i++ {
+		if compositeKey[i] == minUnicodeRuneValue {
+			components = append(components, compositeKey[componentIndex:i])
+			componentIndex = i + 1
+		}
+	}
+	if len(components) == 0 {
+		return "", nil, errors.Errorf("[%s] is not a composite key", compositeKey)
+	}
+	return components[0], components[1:], nil
+}
 
-//func validateCompositeKeyAttribute(str string) error {
-// if !utf8.ValidString(str) {
-//    return errors.Errorf("not a valid utf8 string: [%x]", str)
-// }
-// for index, runeValue := range str {
-//    if runeValue == minUnicodeRuneValue || runeValue == maxUnicodeRuneValue {
-//       return errors.Errorf(`input contain unicode %#U starting at position [%d]. %#U and %#U are not allowed
-// in the input attribute of a composite key`,
-//          runeValue, index, minUnicodeRuneValue, maxUnicodeRuneValue)
-//    }
-// }
-// return nil
-//}
+func validateCompositeKeyAttribute(str string) error {
+	if !utf8.ValidString(str) {
+		return errors.Errorf("not a valid utf8 string: [%x]", str)
+	}
+	for index, runeValue := range str {
+		if runeValue == minUnicodeRuneValue || runeValue == maxUnicodeRuneValue {
+			return errors.Errorf(`input contain unicode %#U starting at position [%d]. %#U and %#U are not allowed
+ in the input attribute of a composite key`,
+				runeValue, index, minUnicodeRuneValue, maxUnicodeRuneValue)
+		}
+	}
+	return nil
+}
 
 //To ensure that simple keys do not go into composite key namespace,
 //we validate simplekey to check whether the key starts with 0x00 (which
 //is the namespace for compositeKey). This helps in avoding simple/composite
 //key collisions.
-//func validateSimpleKeys(simpleKeys ...string) error {
-// for _, key := range simpleKeys {
-//    if len(key) > 0 && key[0] == compositeKeyNamespace[0] {
-//       return errors.Errorf(`first character of the key [%s] contains a null character which is not allowed`, key)
-//    }
-// }
-// return nil
-//}
+func validateSimpleKeys(simpleKeys ...string) error {
+	for _, key := range simpleKeys {
+		if len(key) > 0 && key[0] == compositeKeyNamespace[0] {
+			return errors.Errorf(`first character of the key [%s] contains a null character which is not allowed`, key)
+		}
+	}
+	return nil
+}
 
 //GetStateByPartialCompositeKey function can be invoked by a chaincode to query the
 //state based on a given partial composite key. This function returns an
@@ -977,23 +1063,101 @@ func (c *ChaincodeLogger) Criticalf(format string, args ...interface{}) {
 //matches the given partial composite key. This function should be used only for
 //a partial composite key. For a full composite key, an iter with empty response
 //would be returned.
-//func (stub *ChaincodeStub) GetStateByPartialCompositeKey(objectType string,
-// attributes []string) (StateQueryIteratorInterface, error) {
-// collection := ""
-// if partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes); err == nil {
-//    return stub.handleGetStateByRange(collection, partialCompositeKey, partialCompositeKey+string(maxUnicodeRuneValue))
-// } else {
-//    return nil, err
-// }
-//}
+func (stub *ChaincodeStub) GetStateByPartialCompositeKey(objectType string,
+	attributes []string) (StateQueryIteratorInterface, error) {
+	partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return stub.newStateQueryIterator(partialCompositeKey, partialCompositeKey+string(maxUnicodeRuneValue))
+}
+
+// GetStateByPartialCompositeKeyWithPagination documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string,
+	pageSize int32, bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error) {
+	partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stub.getStateByRangeWithPagination(partialCompositeKey,
+		partialCompositeKey+string(maxUnicodeRuneValue), pageSize, bookmark)
+}
+
+//从上一批的bookmark开始获取下一批状态
+func (iter *StateQueryIterator) fetchNextResults() error {
+	iter.results = nil
+	iter.current = 0
+	for len(iter.results) == 0 && iter.bookmark != "" {
+		result, err := iter.stub.handleGetStateByRange(iter.bookmark, iter.endKey, rangeQueryBatchSize)
+		if err != nil {
+			return err
+		}
+		iter.results = result.KVs
+		iter.bookmark = result.Bookmark
+	}
+	return nil
+}
 
 // HasNext documentation can be found in interfaces.go
-//func (iter *CommonIterator) HasNext() bool {
-// if iter.currentLoc < len(iter.response.Results) || iter.response.HasMore {
-//    return true
-// }
-// return false
-//}
+func (iter *StateQueryIterator) HasNext() bool {
+	return iter.current < len(iter.results)
+}
+
+// Next documentation can be found in interfaces.go
+func (iter *StateQueryIterator) Next() (*modules.KeyValue, error) {
+	if !iter.HasNext() {
+		return nil, errors.New("no such key")
+	}
+	kv := iter.results[iter.current]
+	iter.current++
+	//最后一个状态被读取时预先获取下一批，以便HasNext的结果准确
+	if iter.current == len(iter.results) && iter.fetchAll {
+		if err := iter.fetchNextResults(); err != nil {
+			log.Errorf("Failed to fetch next results: %+v", err)
+			return nil, err
+		}
+	}
+	return kv, nil
+}
+
+// Close documentation can be found in interfaces.go
+func (iter *StateQueryIterator) Close() error {
+	iter.results = nil
+	iter.bookmark = ""
+	iter.current = 0
+	return nil
+}
+
+// GetHistoryForKey documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error) {
+	histories, err := stub.handler.handleGetHistoryForKey(key, stub.ContractId, stub.ChannelId, stub.TxID)
+	if err != nil {
+		return nil, err
+	}
+	return &HistoryQueryIterator{results: histories}, nil
+}
+
+// HasNext documentation can be found in interfaces.go
+func (iter *HistoryQueryIterator) HasNext() bool {
+	return iter.current < len(iter.results)
+}
+
+// Next documentation can be found in interfaces.go
+func (iter *HistoryQueryIterator) Next() (*modules.ContractStateHistory, error) {
+	if !iter.HasNext() {
+		return nil, errors.New("no such history")
+	}
+	history := iter.results[iter.current]
+	iter.current++
+	return history, nil
+}
+
+// Close documentation can be found in interfaces.go
+func (iter *HistoryQueryIterator) Close() error {
+	iter.results = nil
+	iter.current = 0
+	return nil
+}
 
 // getResultsFromBytes deserializes QueryResult and return either a KV struct
 // or KeyModification depending on the result type (i.e., state (range/execute)
@@ -0,0 +1,61 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package shim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeKey(t *testing.T) {
+	stub := &ChaincodeStub{}
+	key, err := stub.CreateCompositeKey("order", []string{"BTC", "0001"})
+	assert.Nil(t, err)
+	assert.Equal(t, "\x00order\x00BTC\x000001\x00", key)
+
+	objectType, attributes, err := stub.SplitCompositeKey(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "order", objectType)
+	assert.Equal(t, []string{"BTC", "0001"}, attributes)
+
+	//部分key是完整key的前缀，范围查询可以覆盖
+	partial, err := stub.CreateCompositeKey("order", []string{"BTC"})
+	assert.Nil(t, err)
+	assert.True(t, key > partial && key < partial+string(maxUnicodeRuneValue))
+
+	_, err = stub.CreateCompositeKey("order", []string{"a\x00b"})
+	assert.NotNil(t, err)
+	_, _, err = stub.SplitCompositeKey("order")
+	assert.NotNil(t, err)
+}
+
+func TestValidateSimpleKeys(t *testing.T) {
+	assert.Nil(t, validateSimpleKeys("a", "", emptyKeySubstitute))
+	assert.NotNil(t, validateSimpleKeys("a", "\x00order"))
+
+	stub := &ChaincodeStub{}
+	_, err := stub.GetStateByRange("\x00order", "")
+	assert.NotNil(t, err)
+	_, _, err = stub.GetStateByRangeWithPagination("a", "z", 0, "")
+	assert.NotNil(t, err)
+	_, _, err = stub.GetStateByRangeWithPagination("b", "z", 10, "a")
+	assert.NotNil(t, err)
+}
@@ -470,6 +470,76 @@ func (handler *Handler) handelGetStateByPrefix(prefix string, contractId []byte,
 	return nil, errors.Errorf("[%s]incorrect chaincode message %s received. Expecting %s or %s",
 		shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
 }
+
+// handleGetStateByRange 查询[startKey, endKey)范围内最多limit个状态，返回结果中的Bookmark为空表示没有更多的状态
+func (handler *Handler) handleGetStateByRange(startKey, endKey string, limit int32, contractId []byte,
+	channelId string, txid string) (*modules.RangeQueryResult, error) {
+	payloadBytes, _ := proto.Marshal(&pb.GetStateByRange{StartKey: startKey, EndKey: endKey,
+		ContractId: contractId, Limit: limit})
+
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_BY_RANGE, Payload: payloadBytes, Txid: txid,
+		ChannelId: channelId, ContractId: contractId}
+	log.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_BY_RANGE)
+
+	responseMsg, err := handler.callPeerWithChaincodeMsg(msg, channelId, txid)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("[%s]error sending GET_STATE_BY_RANGE",
+			shorttxid(txid)))
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		log.Debugf("[%s]GetStateByRange received payload %s", shorttxid(responseMsg.Txid),
+			pb.ChaincodeMessage_RESPONSE)
+		result := &modules.RangeQueryResult{}
+		err = json.Unmarshal(responseMsg.Payload, result)
+		return result, err
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		log.Errorf("[%s]GetStateByRange received error %s", shorttxid(responseMsg.Txid),
+			pb.ChaincodeMessage_ERROR)
+		return nil, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	return nil, errors.Errorf("[%s]incorrect chaincode message %s received. Expecting %s or %s",
+		shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+}
+// handleGetHistoryForKey 查询状态按时间顺序的全部修改
+func (handler *Handler) handleGetHistoryForKey(key string, contractId []byte, channelId string,
+	txid string) ([]*modules.ContractStateHistory, error) {
+	payloadBytes, _ := proto.Marshal(&pb.GetHistoryForKey{Key: key, ContractId: contractId})
+
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY, Payload: payloadBytes, Txid: txid,
+		ChannelId: channelId, ContractId: contractId}
+	log.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_HISTORY_FOR_KEY)
+
+	responseMsg, err := handler.callPeerWithChaincodeMsg(msg, channelId, txid)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("[%s]error sending GET_HISTORY_FOR_KEY",
+			shorttxid(txid)))
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		log.Debugf("[%s]GetHistoryForKey received payload %s", shorttxid(responseMsg.Txid),
+			pb.ChaincodeMessage_RESPONSE)
+		histories := []*modules.ContractStateHistory{}
+		err = json.Unmarshal(responseMsg.Payload, &histories)
+		return histories, err
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		log.Errorf("[%s]GetHistoryForKey received error %s", shorttxid(responseMsg.Txid),
+			pb.ChaincodeMessage_ERROR)
+		return nil, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	return nil, errors.Errorf("[%s]incorrect chaincode message %s received. Expecting %s or %s",
+		shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+}
 func (handler *Handler) handleGetTimestamp(collection string, rangeNumber uint32, contractid []byte,
 	channelId string, txid string) ([]byte, error) {
 	// Construct payload for GET_STATE
@@ -502,9 +572,9 @@ func (handler *Handler) handleGetTimestamp(collection string, rangeNumber uint32
 		shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
 }
 
-func (handler *Handler) handleGetTokenBalance(address string, token *modules.Asset, contractid []byte,
-	channelId string, txid string) ([]*modules.InvokeTokens, error) {
-	par := &pb.GetTokenBalance{Address: address}
+func (handler *Handler) handleGetTokenBalance(address string, token *modules.Asset, height uint64,
+	contractid []byte, channelId string, txid string) ([]*modules.InvokeTokens, error) {
+	par := &pb.GetTokenBalance{Address: address, Height: height}
 	if token != nil {
 		par.Asset = token.String()
 	}
@@ -576,6 +646,30 @@ func (handler *Handler) handlePayOutToken(collection string, addr string, invoke
 	return errors.Errorf("[%s]incorrect chaincode message %s received. Expecting %s or %s",
 		shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
 }
+func (handler *Handler) handleChargeInstructions(count uint64, contractid []byte, channelId string,
+	txid string) error {
+	payloadBytes, _ := proto.Marshal(&pb.ChargeInstructions{Count: count})
+
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_CHARGE_INSTRUCTIONS, Payload: payloadBytes, Txid: txid,
+		ChannelId: channelId, ContractId: contractid}
+	log.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_CHARGE_INSTRUCTIONS)
+
+	responseMsg, err := handler.callPeerWithChaincodeMsg(msg, channelId, txid)
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("[%s]error sending CHARGE_INSTRUCTIONS", shorttxid(txid)))
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		return nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		return errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	return errors.Errorf("[%s]incorrect chaincode message %s received. Expecting %s or %s",
+		shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+}
 func (handler *Handler) handleDefineToken(tokenType byte, define []byte, creator string, contractid []byte,
 	channelId string, txid string) error {
 	par := &pb.DefineToken{TokenType: int32(tokenType), Define: define, Creator: creator}
@@ -952,6 +1046,44 @@ func (handler *Handler) handleInvokeChaincode(chaincodeName string, args [][]byt
 		pb.ChaincodeMessage_ERROR)))
 }
 
+//调用另一个用户合约，节点在同一个txsimulator中执行被调用合约
+func (handler *Handler) handleInvokeContract(calleeId []byte, args [][]byte, invokeTokens []*modules.AmountAsset,
+	contractid []byte, channelId string, txid string) pb.Response {
+	req := &pb.InvokeContract{ContractId: calleeId, Args: args}
+	for _, token := range invokeTokens {
+		req.Tokens = append(req.Tokens, &pb.PayOutToken{Asset: token.Asset.Bytes(), Amount: token.Amount})
+	}
+	payloadBytes, _ := proto.Marshal(req)
+
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_INVOKE_CONTRACT, Payload: payloadBytes, Txid: txid,
+		ChannelId: channelId, ContractId: contractid}
+	log.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_INVOKE_CONTRACT)
+
+	responseMsg, err := handler.callPeerWithChaincodeMsg(msg, channelId, txid)
+	if err != nil {
+		return handler.createResponse(ERROR, []byte(fmt.Sprintf("[%s]error sending %s",
+			shorttxid(txid), pb.ChaincodeMessage_INVOKE_CONTRACT)))
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		log.Debugf("[%s]Received %s. Successfully invoked contract", shorttxid(responseMsg.Txid),
+			pb.ChaincodeMessage_RESPONSE)
+		res := &pb.Response{}
+		if err = proto.Unmarshal(responseMsg.Payload, res); err != nil {
+			return handler.createResponse(ERROR, []byte(err.Error()))
+		}
+		return *res
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		log.Errorf("[%s]Received %s.", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return pb.Response{Status: ERROR, Message: string(responseMsg.Payload)}
+	}
+
+	return handler.createResponse(ERROR, []byte(fmt.Sprintf("[%s]Incorrect chaincode message %s received."+
+		" Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE,
+		pb.ChaincodeMessage_ERROR)))
+}
+
 // handleMessage message handles loop for shim side of chaincode/peer stream.
 func (handler *Handler) handleMessage(msg *pb.ChaincodeMessage) error {
 	if msg.Type == pb.ChaincodeMessage_KEEPALIVE {
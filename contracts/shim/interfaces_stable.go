@@ -135,6 +135,10 @@ type ChaincodeStubInterface interface {
 	// client's timestamp and will have the same value across all endorsers.
 	GetTxTimestamp(rangeNumber uint32) (*timestamp.Timestamp, error)
 
+	// GetTxHeight returns the height of the unit whose time is returned by
+	// GetTxTimestamp with the same rangeNumber.
+	GetTxHeight(rangeNumber uint32) (uint64, error)
+
 	// SetEvent allows the chaincode to set an event on the response to the
 	// proposal to be included as part of a transaction. The event will be
 	// available within the transaction in the committed block regardless of the
@@ -158,8 +162,15 @@ type ChaincodeStubInterface interface {
 	//如果地址为空则表示当前合约
 	//如果token为空则表示查询所有Token余额
 	GetTokenBalance(address string, token *modules.Asset) ([]*modules.InvokeTokens, error)
+	//获得某地址在高度为height的单元时的Token余额，该单元必须已经稳定
+	GetTokenBalanceAt(address string, token *modules.Asset, height uint64) ([]*modules.InvokeTokens, error)
 	//将合约上锁定的某种Token支付出去
 	PayOutToken(addr string, invokeTokens *modules.AmountAsset, lockTime uint32) error
+	//调用另一个用户合约，并从当前合约把invokeTokens付给被调用合约
+	//被调用合约的读写集和付款合并到当前调用的结果中，任何一个合约失败整个调用都失败
+	InvokeContract(contractAddr string, args [][]byte, invokeTokens []*modules.AmountAsset) pb.Response
+	//wasm合约上报已执行的指令数，按系统参数计入本次调用的cost，超过上限时返回错误
+	ChargeInstructions(count uint64) error
 	//获取invoke参数，包括invokeAddr,tokens,fee,funcName,params
 	GetInvokeParameters() (invokeAddr common.Address, invokeTokens []*modules.InvokeTokens, invokeFees *modules.AmountAsset, funcName string, params []string, err error)
 	//定义并发行一种全新的Token
@@ -182,8 +193,15 @@ type ChaincodeStubInterface interface {
 	// Call Close() on the returned StateQueryIteratorInterface object when done.
 	// The query is re-executed during validation phase to ensure result set
 	// has not changed since transaction endorsement (phantom reads detected).
-	//TODO
-	//GetStateByRange(startKey, endKey string) (StateQueryIteratorInterface, error)
+	GetStateByRange(startKey, endKey string) (StateQueryIteratorInterface, error)
+
+	// GetStateByRangeWithPagination returns a page of at most `pageSize` keys
+	// between the startKey (inclusive) and endKey (exclusive), starting from
+	// `bookmark` (inclusive) if it is not empty. The bookmark of the next page
+	// is returned in the QueryResponseMetadata, an empty bookmark means there
+	// are no more keys in the range.
+	GetStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+		bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error)
 
 	// GetStateByPartialCompositeKey queries the state in the ledger based on
 	// a given partial composite key. This function returns an iterator
@@ -195,23 +213,26 @@ type ChaincodeStubInterface interface {
 	// Call Close() on the returned StateQueryIteratorInterface object when done.
 	// The query is re-executed during validation phase to ensure result set
 	// has not changed since transaction endorsement (phantom reads detected).
-	//TODO
-	//GetStateByPartialCompositeKey(objectType string, keys []string) (StateQueryIteratorInterface, error)
+	GetStateByPartialCompositeKey(objectType string, keys []string) (StateQueryIteratorInterface, error)
+
+	// GetStateByPartialCompositeKeyWithPagination is the paginated version of
+	// GetStateByPartialCompositeKey, see GetStateByRangeWithPagination for the
+	// usage of `pageSize` and `bookmark`.
+	GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32,
+		bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error)
 
 	// CreateCompositeKey combines the given `attributes` to form a composite
 	// key. The objectType and attributes are expected to have only valid utf8
 	// strings and should not contain U+0000 (nil byte) and U+10FFFF
 	// (biggest and unallocated code point).
 	// The resulting composite key can be used as the key in PutState().
-	//TODO
-	//CreateCompositeKey(objectType string, attributes []string) (string, error)
+	CreateCompositeKey(objectType string, attributes []string) (string, error)
 
 	// SplitCompositeKey splits the specified key into attributes on which the
 	// composite key was formed. Composite keys found during range queries
 	// or partial composite key queries can therefore be split into their
 	// composite parts.
-	//TODO
-	//SplitCompositeKey(compositeKey string) (string, []string, error)
+	SplitCompositeKey(compositeKey string) (string, []string, error)
 
 	// GetQueryResult performs a "rich" query against a state database. It is
 	// only supported for state databases that support rich query,
@@ -229,17 +250,17 @@ type ChaincodeStubInterface interface {
 
 	// GetHistoryForKey returns a history of key values across time.
 	// For each historic key update, the historic value and associated
-	// transaction id and timestamp are returned. The timestamp is the
-	// timestamp provided by the client in the proposal header.
-	// GetHistoryForKey requires peer configuration
-	// core.ledger.history.enableHistoryDatabase to be true.
+	// transaction id, unit and timestamp are returned. The timestamp is the
+	// timestamp of the unit which contains the transaction.
+	// GetHistoryForKey requires the node configuration
+	// Dag.ContractStateHistory to be true, otherwise an error is returned.
 	// The query is NOT re-executed during validation phase, phantom reads are
 	// not detected. That is, other committed transactions may have updated
 	// the key concurrently, impacting the result set, and this would not be
 	// detected at validation/commit time. Applications susceptible to this
 	// should therefore not use GetHistoryForKey as part of transactions that
 	// update ledger, and should limit use to read-only chaincode operations.
-	//GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error)
+	GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error)
 
 	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
 	// of the `SignedProposal`. This is the identity of the agent (or user)
@@ -277,35 +298,41 @@ type ChaincodeStubInterface interface {
 
 // CommonIteratorInterface allows a chaincode to check whether any more result
 // to be fetched from an iterator and close it when done.
-//type CommonIteratorInterface interface {
-// HasNext returns true if the range query iterator contains additional keys
-// and values.
-//HasNext() bool
-
-// Close closes the iterator. This should be called when done
-// reading from the iterator to free up resources.
-//Close() error
-//}
+type CommonIteratorInterface interface {
+	// HasNext returns true if the range query iterator contains additional keys
+	// and values.
+	HasNext() bool
+
+	// Close closes the iterator. This should be called when done
+	// reading from the iterator to free up resources.
+	Close() error
+}
 
 // StateQueryIteratorInterface allows a chaincode to iterate over a set of
 // key/value pairs returned by range and execute query.
-//type StateQueryIteratorInterface interface {
-// Inherit HasNext() and Close()
-//CommonIteratorInterface
+type StateQueryIteratorInterface interface {
+	// Inherit HasNext() and Close()
+	CommonIteratorInterface
 
-// Next returns the next key and value in the range and execute query iterator.
-//Next() (*queryresult.KV, error)
-//}
+	// Next returns the next key and value in the range and execute query iterator.
+	Next() (*modules.KeyValue, error)
+}
+
+// QueryResponseMetadata 分页查询的结果信息，Bookmark为空表示没有下一页
+type QueryResponseMetadata struct {
+	FetchedRecordsCount int32
+	Bookmark            string
+}
 
 // HistoryQueryIteratorInterface allows a chaincode to iterate over a set of
 // key/value pairs returned by a history query.
-//type HistoryQueryIteratorInterface interface {
-//	// Inherit HasNext() and Close()
-//	CommonIteratorInterface
-//
-//	// Next returns the next key and value in the history query iterator.
-//	Next() (*queryresult.KeyModification, error)
-//}
+type HistoryQueryIteratorInterface interface {
+	// Inherit HasNext() and Close()
+	CommonIteratorInterface
+
+	// Next returns the next key and value in the history query iterator.
+	Next() (*modules.ContractStateHistory, error)
+}
 
 // MockQueryIteratorInterface allows a chaincode to iterate over a set of
 // key/value pairs returned by range query.
@@ -247,6 +247,114 @@ func (mr *MockChaincodeStubInterfaceMockRecorder) GetStateByPrefix(prefix interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateByPrefix", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetStateByPrefix), prefix)
 }
 
+// GetStateByRange mocks base method
+func (m *MockChaincodeStubInterface) GetStateByRange(startKey, endKey string) (StateQueryIteratorInterface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStateByRange", startKey, endKey)
+	ret0, _ := ret[0].(StateQueryIteratorInterface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStateByRange indicates an expected call of GetStateByRange
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetStateByRange(startKey, endKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateByRange", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetStateByRange), startKey, endKey)
+}
+
+// GetStateByRangeWithPagination mocks base method
+func (m *MockChaincodeStubInterface) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStateByRangeWithPagination", startKey, endKey, pageSize, bookmark)
+	ret0, _ := ret[0].(StateQueryIteratorInterface)
+	ret1, _ := ret[1].(*QueryResponseMetadata)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetStateByRangeWithPagination indicates an expected call of GetStateByRangeWithPagination
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateByRangeWithPagination", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetStateByRangeWithPagination), startKey, endKey, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKey mocks base method
+func (m *MockChaincodeStubInterface) GetStateByPartialCompositeKey(objectType string, keys []string) (StateQueryIteratorInterface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStateByPartialCompositeKey", objectType, keys)
+	ret0, _ := ret[0].(StateQueryIteratorInterface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStateByPartialCompositeKey indicates an expected call of GetStateByPartialCompositeKey
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetStateByPartialCompositeKey(objectType, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateByPartialCompositeKey", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetStateByPartialCompositeKey), objectType, keys)
+}
+
+// GetStateByPartialCompositeKeyWithPagination mocks base method
+func (m *MockChaincodeStubInterface) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (StateQueryIteratorInterface, *QueryResponseMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStateByPartialCompositeKeyWithPagination", objectType, keys, pageSize, bookmark)
+	ret0, _ := ret[0].(StateQueryIteratorInterface)
+	ret1, _ := ret[1].(*QueryResponseMetadata)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetStateByPartialCompositeKeyWithPagination indicates an expected call of GetStateByPartialCompositeKeyWithPagination
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetStateByPartialCompositeKeyWithPagination(objectType, keys, pageSize, bookmark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateByPartialCompositeKeyWithPagination", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetStateByPartialCompositeKeyWithPagination), objectType, keys, pageSize, bookmark)
+}
+
+// CreateCompositeKey mocks base method
+func (m *MockChaincodeStubInterface) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCompositeKey", objectType, attributes)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCompositeKey indicates an expected call of CreateCompositeKey
+func (mr *MockChaincodeStubInterfaceMockRecorder) CreateCompositeKey(objectType, attributes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompositeKey", reflect.TypeOf((*MockChaincodeStubInterface)(nil).CreateCompositeKey), objectType, attributes)
+}
+
+// SplitCompositeKey mocks base method
+func (m *MockChaincodeStubInterface) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SplitCompositeKey", compositeKey)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SplitCompositeKey indicates an expected call of SplitCompositeKey
+func (mr *MockChaincodeStubInterfaceMockRecorder) SplitCompositeKey(compositeKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SplitCompositeKey", reflect.TypeOf((*MockChaincodeStubInterface)(nil).SplitCompositeKey), compositeKey)
+}
+
+// GetHistoryForKey mocks base method
+func (m *MockChaincodeStubInterface) GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryForKey", key)
+	ret0, _ := ret[0].(HistoryQueryIteratorInterface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoryForKey indicates an expected call of GetHistoryForKey
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetHistoryForKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryForKey", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetHistoryForKey), key)
+}
+
 // PutState mocks base method
 func (m *MockChaincodeStubInterface) PutState(key string, value []byte) error {
 	m.ctrl.T.Helper()
@@ -363,6 +471,21 @@ func (mr *MockChaincodeStubInterfaceMockRecorder) GetTxTimestamp(rangeNumber int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxTimestamp", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetTxTimestamp), rangeNumber)
 }
 
+// GetTxHeight mocks base method
+func (m *MockChaincodeStubInterface) GetTxHeight(rangeNumber uint32) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTxHeight", rangeNumber)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTxHeight indicates an expected call of GetTxHeight
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetTxHeight(rangeNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxHeight", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetTxHeight), rangeNumber)
+}
+
 // SetEvent mocks base method
 func (m *MockChaincodeStubInterface) SetEvent(name string, payload []byte) error {
 	m.ctrl.T.Helper()
@@ -482,6 +605,21 @@ func (mr *MockChaincodeStubInterfaceMockRecorder) GetTokenBalance(address, token
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenBalance", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetTokenBalance), address, token)
 }
 
+// GetTokenBalanceAt mocks base method
+func (m *MockChaincodeStubInterface) GetTokenBalanceAt(address string, token *modules.Asset, height uint64) ([]*modules.InvokeTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenBalanceAt", address, token, height)
+	ret0, _ := ret[0].([]*modules.InvokeTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenBalanceAt indicates an expected call of GetTokenBalanceAt
+func (mr *MockChaincodeStubInterfaceMockRecorder) GetTokenBalanceAt(address, token, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenBalanceAt", reflect.TypeOf((*MockChaincodeStubInterface)(nil).GetTokenBalanceAt), address, token, height)
+}
+
 // PayOutToken mocks base method
 func (m *MockChaincodeStubInterface) PayOutToken(addr string, invokeTokens *modules.AmountAsset, lockTime uint32) error {
 	m.ctrl.T.Helper()
@@ -496,6 +634,34 @@ func (mr *MockChaincodeStubInterfaceMockRecorder) PayOutToken(addr, invokeTokens
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayOutToken", reflect.TypeOf((*MockChaincodeStubInterface)(nil).PayOutToken), addr, invokeTokens, lockTime)
 }
 
+// InvokeContract mocks base method
+func (m *MockChaincodeStubInterface) InvokeContract(contractAddr string, args [][]byte, invokeTokens []*modules.AmountAsset) peer.Response {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvokeContract", contractAddr, args, invokeTokens)
+	ret0, _ := ret[0].(peer.Response)
+	return ret0
+}
+
+// InvokeContract indicates an expected call of InvokeContract
+func (mr *MockChaincodeStubInterfaceMockRecorder) InvokeContract(contractAddr, args, invokeTokens interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvokeContract", reflect.TypeOf((*MockChaincodeStubInterface)(nil).InvokeContract), contractAddr, args, invokeTokens)
+}
+
+// ChargeInstructions mocks base method
+func (m *MockChaincodeStubInterface) ChargeInstructions(count uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChargeInstructions", count)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChargeInstructions indicates an expected call of ChargeInstructions
+func (mr *MockChaincodeStubInterfaceMockRecorder) ChargeInstructions(count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChargeInstructions", reflect.TypeOf((*MockChaincodeStubInterface)(nil).ChargeInstructions), count)
+}
+
 // GetInvokeParameters mocks base method
 func (m *MockChaincodeStubInterface) GetInvokeParameters() (common.Address, []*modules.InvokeTokens, *modules.AmountAsset, string, []string, error) {
 	m.ctrl.T.Helper()
@@ -572,3 +738,186 @@ func (mr *MockChaincodeStubInterfaceMockRecorder) IsRequesterCertValid() *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRequesterCertValid", reflect.TypeOf((*MockChaincodeStubInterface)(nil).IsRequesterCertValid))
 }
+
+// MockCommonIteratorInterface is a mock of CommonIteratorInterface interface
+type MockCommonIteratorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommonIteratorInterfaceMockRecorder
+}
+
+// MockCommonIteratorInterfaceMockRecorder is the mock recorder for MockCommonIteratorInterface
+type MockCommonIteratorInterfaceMockRecorder struct {
+	mock *MockCommonIteratorInterface
+}
+
+// NewMockCommonIteratorInterface creates a new mock instance
+func NewMockCommonIteratorInterface(ctrl *gomock.Controller) *MockCommonIteratorInterface {
+	mock := &MockCommonIteratorInterface{ctrl: ctrl}
+	mock.recorder = &MockCommonIteratorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCommonIteratorInterface) EXPECT() *MockCommonIteratorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// HasNext mocks base method
+func (m *MockCommonIteratorInterface) HasNext() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasNext")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasNext indicates an expected call of HasNext
+func (mr *MockCommonIteratorInterfaceMockRecorder) HasNext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasNext", reflect.TypeOf((*MockCommonIteratorInterface)(nil).HasNext))
+}
+
+// Close mocks base method
+func (m *MockCommonIteratorInterface) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockCommonIteratorInterfaceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockCommonIteratorInterface)(nil).Close))
+}
+
+// MockStateQueryIteratorInterface is a mock of StateQueryIteratorInterface interface
+type MockStateQueryIteratorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockStateQueryIteratorInterfaceMockRecorder
+}
+
+// MockStateQueryIteratorInterfaceMockRecorder is the mock recorder for MockStateQueryIteratorInterface
+type MockStateQueryIteratorInterfaceMockRecorder struct {
+	mock *MockStateQueryIteratorInterface
+}
+
+// NewMockStateQueryIteratorInterface creates a new mock instance
+func NewMockStateQueryIteratorInterface(ctrl *gomock.Controller) *MockStateQueryIteratorInterface {
+	mock := &MockStateQueryIteratorInterface{ctrl: ctrl}
+	mock.recorder = &MockStateQueryIteratorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStateQueryIteratorInterface) EXPECT() *MockStateQueryIteratorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// HasNext mocks base method
+func (m *MockStateQueryIteratorInterface) HasNext() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasNext")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasNext indicates an expected call of HasNext
+func (mr *MockStateQueryIteratorInterfaceMockRecorder) HasNext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasNext", reflect.TypeOf((*MockStateQueryIteratorInterface)(nil).HasNext))
+}
+
+// Close mocks base method
+func (m *MockStateQueryIteratorInterface) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockStateQueryIteratorInterfaceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStateQueryIteratorInterface)(nil).Close))
+}
+
+// Next mocks base method
+func (m *MockStateQueryIteratorInterface) Next() (*modules.KeyValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next")
+	ret0, _ := ret[0].(*modules.KeyValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Next indicates an expected call of Next
+func (mr *MockStateQueryIteratorInterfaceMockRecorder) Next() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockStateQueryIteratorInterface)(nil).Next))
+}
+
+// MockHistoryQueryIteratorInterface is a mock of HistoryQueryIteratorInterface interface
+type MockHistoryQueryIteratorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryQueryIteratorInterfaceMockRecorder
+}
+
+// MockHistoryQueryIteratorInterfaceMockRecorder is the mock recorder for MockHistoryQueryIteratorInterface
+type MockHistoryQueryIteratorInterfaceMockRecorder struct {
+	mock *MockHistoryQueryIteratorInterface
+}
+
+// NewMockHistoryQueryIteratorInterface creates a new mock instance
+func NewMockHistoryQueryIteratorInterface(ctrl *gomock.Controller) *MockHistoryQueryIteratorInterface {
+	mock := &MockHistoryQueryIteratorInterface{ctrl: ctrl}
+	mock.recorder = &MockHistoryQueryIteratorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHistoryQueryIteratorInterface) EXPECT() *MockHistoryQueryIteratorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// HasNext mocks base method
+func (m *MockHistoryQueryIteratorInterface) HasNext() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasNext")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasNext indicates an expected call of HasNext
+func (mr *MockHistoryQueryIteratorInterfaceMockRecorder) HasNext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasNext", reflect.TypeOf((*MockHistoryQueryIteratorInterface)(nil).HasNext))
+}
+
+// Close mocks base method
+func (m *MockHistoryQueryIteratorInterface) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockHistoryQueryIteratorInterfaceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockHistoryQueryIteratorInterface)(nil).Close))
+}
+
+// Next mocks base method
+func (m *MockHistoryQueryIteratorInterface) Next() (*modules.ContractStateHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next")
+	ret0, _ := ret[0].(*modules.ContractStateHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Next indicates an expected call of Next
+func (mr *MockHistoryQueryIteratorInterfaceMockRecorder) Next() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockHistoryQueryIteratorInterface)(nil).Next))
+}
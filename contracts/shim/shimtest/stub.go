@@ -0,0 +1,156 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+//Package shimtest 为系统合约的单元测试提供一个基于内存state db的ChaincodeStub
+package shimtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/contracts/shim"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+//Stub 测试用的合约调用环境，修改导出字段即可模拟不同的调用者、时间和转入的Token。
+//环境中所有合约共用一个state db
+type Stub struct {
+	*shim.MockChaincodeStubInterface
+	Contract common.Address
+	Invoker  common.Address
+	Now      time.Time
+	Height   uint64
+	TxID     string
+	Function string
+	Args     []string
+	Tokens   []*modules.InvokeTokens
+	Gp       *modules.GlobalProperty
+	DB       map[string][]byte
+	Balance  map[string]uint64            //address --- GetTokenBalance返回的数量
+	Holdings map[uint64]map[string]uint64 //height --- address --- GetTokenBalanceAt返回的数量
+	Paid     map[string]uint64            //address --- PayOutToken支付的数量
+}
+
+func NewStub(mockCtrl *gomock.Controller, contract common.Address) *Stub {
+	s := &Stub{
+		MockChaincodeStubInterface: shim.NewMockChaincodeStubInterface(mockCtrl),
+		Contract:                   contract,
+		Now:                        time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Gp:                         modules.NewGlobalProp(),
+		DB:                         make(map[string][]byte),
+		Balance:                    make(map[string]uint64),
+		Holdings:                   make(map[uint64]map[string]uint64),
+		Paid:                       make(map[string]uint64),
+	}
+	e := s.EXPECT()
+	e.PutState(gomock.Any(), gomock.Any()).DoAndReturn(func(key string, value []byte) error {
+		s.DB[key] = value
+		return nil
+	}).AnyTimes()
+	e.DelState(gomock.Any()).DoAndReturn(func(key string) error {
+		delete(s.DB, key)
+		return nil
+	}).AnyTimes()
+	e.GetState(gomock.Any()).DoAndReturn(func(key string) ([]byte, error) {
+		return s.DB[key], nil
+	}).AnyTimes()
+	e.GetContractState(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(addr common.Address, key string) ([]byte, error) {
+			return s.DB[key], nil
+		}).AnyTimes()
+	e.GetStateByPrefix(gomock.Any()).DoAndReturn(func(prefix string) ([]*modules.KeyValue, error) {
+		kvs := []*modules.KeyValue{}
+		for k, v := range s.DB {
+			if strings.HasPrefix(k, prefix) {
+				kvs = append(kvs, &modules.KeyValue{Key: k, Value: v})
+			}
+		}
+		sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+		return kvs, nil
+	}).AnyTimes()
+	e.GetInvokeAddress().DoAndReturn(func() (common.Address, error) {
+		return s.Invoker, nil
+	}).AnyTimes()
+	e.GetTxID().DoAndReturn(func() string { return s.TxID }).AnyTimes()
+	e.GetTxTimestamp(gomock.Any()).DoAndReturn(func(uint32) (*timestamp.Timestamp, error) {
+		return &timestamp.Timestamp{Seconds: s.Now.Unix()}, nil
+	}).AnyTimes()
+	e.GetTxHeight(gomock.Any()).DoAndReturn(func(uint32) (uint64, error) {
+		return s.Height, nil
+	}).AnyTimes()
+	e.GetFunctionAndParameters().DoAndReturn(func() (string, []string) {
+		return s.Function, s.Args
+	}).AnyTimes()
+	e.GetContractID().DoAndReturn(func() ([]byte, string) {
+		return s.Contract.Bytes(), s.Contract.String()
+	}).AnyTimes()
+	e.GetInvokeTokens().DoAndReturn(func() ([]*modules.InvokeTokens, error) {
+		return s.Tokens, nil
+	}).AnyTimes()
+	e.GetSystemConfig().DoAndReturn(func() (*modules.GlobalProperty, error) {
+		return s.Gp, nil
+	}).AnyTimes()
+	e.GetTokenBalance(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(addr string, token *modules.Asset) ([]*modules.InvokeTokens, error) {
+			return []*modules.InvokeTokens{{Amount: s.Balance[addr], Asset: token, Address: addr}}, nil
+		}).AnyTimes()
+	e.GetTokenBalanceAt(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(addr string, token *modules.Asset, height uint64) ([]*modules.InvokeTokens, error) {
+			if height > s.Height {
+				return nil, fmt.Errorf("unit at height %d is not stable", height)
+			}
+			return []*modules.InvokeTokens{{Amount: s.Holdings[height][addr], Asset: token, Address: addr}}, nil
+		}).AnyTimes()
+	e.PayOutToken(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(addr string, amount *modules.AmountAsset, lockTime uint32) error {
+			s.Paid[addr] += amount.Amount
+			return nil
+		}).AnyTimes()
+	return s
+}
+
+//Call 设置调用者、方法和参数，用于直接测试合约内部函数
+func (s *Stub) Call(invoker common.Address, function string, args ...string) {
+	s.Invoker = invoker
+	s.Function = function
+	s.Args = args
+}
+
+//Invoke 以invoker的身份调用合约的function
+func (s *Stub) Invoke(cc shim.Chaincode, invoker common.Address, function string, args ...string) pb.Response {
+	s.Call(invoker, function, args...)
+	return cc.Invoke(s)
+}
+
+//Escrow 设置本次调用转入合约的Token
+func (s *Stub) Escrow(amount uint64, asset *modules.Asset) {
+	s.Tokens = []*modules.InvokeTokens{{Amount: amount, Asset: asset, Address: s.Contract.String()}}
+}
+
+//NewAddress 生成一个测试地址，b不同则地址不同
+func NewAddress(b byte) common.Address {
+	addr := common.Address{}
+	addr[0] = b
+	return addr
+}
@@ -58,6 +58,18 @@ var (
 	//PCGTta3M4t3yXu8uRgkKvaWd2d8DRxVdGDZ
 	PartitionContractAddress = common.HexToAddress("0x00000000000000000000000000000000000000091C")
 
+	//10基金会多签委员会合约
+	//PCGTta3M4t3yXu8uRgkKvaWd2d8DS36t3ba
+	FoundationContractAddress = common.HexToAddress("0x000000000000000000000000000000000000000A1C")
+
+	//11定时支付合约
+	//PCGTta3M4t3yXu8uRgkKvaWd2d8DSDC6K99
+	ScheduledPaymentContractAddress = common.HexToAddress("0x000000000000000000000000000000000000000B1C")
+
+	//12账户支出策略合约
+	//PCGTta3M4t3yXu8uRgkKvaWd2d8DSHHyWEW
+	PolicyContractAddress = common.HexToAddress("0x000000000000000000000000000000000000000C1C")
+
 	//15测试调试用
 	//PCGTta3M4t3yXu8uRgkKvaWd2d8DSfQdUHf
 	TestContractAddress = common.HexToAddress("0x000000000000000000000000000000000000000F1C")
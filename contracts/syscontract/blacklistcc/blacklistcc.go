@@ -27,6 +27,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
 	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
 	"github.com/palletone/go-palletone/dag/constants"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -49,6 +50,9 @@ func (p *BlacklistMgr) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		if len(args) != 2 {
 			return shim.Error("must input 2 args: blackAddress, reason")
 		}
+		if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+			return rsp
+		}
 		addr, err := common.StringToAddress(args[0])
 		if err != nil {
 			return shim.Error("Invalid address string:" + args[0])
@@ -76,6 +80,9 @@ func (p *BlacklistMgr) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		if len(args) != 3 {
 			return shim.Error("must input 3 args: Address,Amount,Asset")
 		}
+		if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+			return rsp
+		}
 		addr, err := common.StringToAddress(args[0])
 		if err != nil {
 			return shim.Error("Invalid address string:" + args[0])
@@ -116,9 +123,6 @@ func (p *BlacklistMgr) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	}
 }
 func (p *BlacklistMgr) AddBlacklist(stub shim.ChaincodeStubInterface, blackAddr common.Address, reason string) error {
-	if !isFoundationInvoke(stub) {
-		return errors.New("only foundation address can call this function")
-	}
 	exist, _ := p.QueryIsInBlacklist(stub, blackAddr)
 	if exist { //不可重复添加同一个地址到黑名单
 		return errors.New(blackAddr.String() + " already exist in blacklist")
@@ -163,9 +167,6 @@ func (p *BlacklistMgr) GetBlacklistAddress(stub shim.ChaincodeStubInterface) ([]
 	return getBlacklistAddress(stub)
 }
 func (p *BlacklistMgr) Payout(stub shim.ChaincodeStubInterface, addr common.Address, amount decimal.Decimal, asset *modules.Asset) error {
-	if !isFoundationInvoke(stub) {
-		return errors.New("only foundation address can call this function")
-	}
 	uint64Amt := ptnjson.JsonAmt2AssetAmt(asset, amount)
 	return stub.PayOutToken(addr.String(), &modules.AmountAsset{
 		Amount: uint64Amt,
@@ -214,28 +215,6 @@ func getAllRecords(stub shim.ChaincodeStubInterface) ([]*BlacklistRecord, error)
 	return result, nil
 }
 
-//  判断是否基金会发起的
-func isFoundationInvoke(stub shim.ChaincodeStubInterface) bool {
-	//  判断是否基金会发起的
-	invokeAddr, err := stub.GetInvokeAddress()
-	if err != nil {
-		log.Error("get invoke address err: ", "error", err)
-		return false
-	}
-	//  获取
-	gp, err := stub.GetSystemConfig()
-	if err != nil {
-		//log.Error("strconv.ParseUint err:", "error", err)
-		return false
-	}
-	foundationAddress := gp.ChainParameters.FoundationAddress
-	// 判断当前请求的是否为基金会
-	if invokeAddr.String() != foundationAddress {
-		log.Error("please use foundation address")
-		return false
-	}
-	return true
-}
 func updateBlacklistAddressList(stub shim.ChaincodeStubInterface, address common.Address) error {
 	list, _ := getBlacklistAddress(stub)
 	list = append(list, address)
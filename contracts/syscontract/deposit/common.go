@@ -345,28 +345,6 @@ func DelJuryBalance(stub shim.ChaincodeStubInterface, addr string) error {
 	return nil
 }
 
-//  判断是否基金会发起的
-func isFoundationInvoke(stub shim.ChaincodeStubInterface) bool {
-	//  判断是否基金会发起的
-	invokeAddr, err := stub.GetInvokeAddress()
-	if err != nil {
-		log.Error("get invoke address err: ", "error", err)
-		return false
-	}
-	//  获取
-	gp, err := stub.GetSystemConfig()
-	if err != nil {
-		//log.Error("strconv.ParseUint err:", "error", err)
-		return false
-	}
-	foundationAddress := gp.ChainParameters.FoundationAddress
-	// 判断当前请求的是否为基金会
-	if invokeAddr.String() != foundationAddress {
-		log.Error("please use foundation address")
-		return false
-	}
-	return true
-}
 func getTime(stub shim.ChaincodeStubInterface) string {
 	t, _ := stub.GetTxTimestamp(10)
 	ti := time.Unix(t.Seconds, 0)
@@ -0,0 +1,202 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package deposit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/math"
+	"github.com/palletone/go-palletone/contracts/shim"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+//委托质押：普通用户将PTN委托给某个mediator，出块时mediator扣除佣金后的奖励按委托数量分给委托人，
+//奖励的记账和发放由Coinbase完成
+
+func getMediatorDelegation(stub shim.ChaincodeStubInterface, mediator, delegator string) (
+	*modules.MediatorDelegation, error) {
+	d := &modules.MediatorDelegation{Mediator: mediator, Delegator: delegator}
+	b, err := stub.GetState(d.Key())
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return d, nil
+	}
+	err = json.Unmarshal(b, d)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func saveMediatorDelegation(stub shim.ChaincodeStubInterface, d *modules.MediatorDelegation) error {
+	if d.Amount == 0 {
+		return stub.DelState(d.Key())
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(d.Key(), b)
+}
+
+func getDelegationsByPrefix(stub shim.ChaincodeStubInterface, prefix string) ([]*modules.MediatorDelegation, error) {
+	kvs, err := stub.GetStateByPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	result := []*modules.MediatorDelegation{}
+	for _, kv := range kvs {
+		d := &modules.MediatorDelegation{}
+		err = json.Unmarshal(kv.Value, d)
+		if err != nil {
+			return nil, err
+		}
+		if d.Amount > 0 {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+//  委托PTN给某个mediator
+func delegateToMediator(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("need 1 arg, mediator address")
+	}
+	mediator, err := common.StringToAddress(args[0])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid mediator address %s: %s", args[0], err.Error()))
+	}
+	//  只能委托给候选列表中的mediator
+	isIn, err := isInCandidate(stub, mediator.String(), modules.MediatorList)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if !isIn {
+		return shim.Error(mediator.String() + " is not in mediator candidate list")
+	}
+	invokeTokens, err := isContainDepositContractAddr(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if invokeAddr == mediator {
+		return shim.Error("mediator can not delegate to itself")
+	}
+	d, err := getMediatorDelegation(stub, mediator.String(), invokeAddr.String())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	d.Amount += invokeTokens.Amount
+	err = saveMediatorDelegation(stub, d)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	log.Debugf("%s delegate %d to mediator %s, total %d", d.Delegator, invokeTokens.Amount, d.Mediator, d.Amount)
+	return shim.Success(nil)
+}
+
+//  从某个mediator撤回委托的PTN，数量为all表示全部撤回
+func undelegateFromMediator(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("need 2 args, mediator address and withdraw Dao amount")
+	}
+	mediator, err := common.StringToAddress(args[0])
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid mediator address %s: %s", args[0], err.Error()))
+	}
+	amount := uint64(0)
+	if strings.ToLower(args[1]) == "all" {
+		amount = math.MaxUint64
+	} else {
+		amount, err = strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	d, err := getMediatorDelegation(stub, mediator.String(), invokeAddr.String())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if d.Amount == 0 {
+		return shim.Error(invokeAddr.String() + " has no delegation to " + mediator.String())
+	}
+	if amount == math.MaxUint64 {
+		amount = d.Amount
+	}
+	if amount == 0 || amount > d.Amount {
+		return shim.Error(fmt.Sprintf("invalid withdraw amount %d, delegated %d", amount, d.Amount))
+	}
+	d.Amount -= amount
+	err = saveMediatorDelegation(stub, d)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+	err = stub.PayOutToken(invokeAddr.String(), modules.NewAmountAsset(amount, gasToken), 0)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+//  查询某个mediator的所有委托
+func queryMediatorDelegations(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("need 1 arg, mediator address")
+	}
+	list, err := getDelegationsByPrefix(stub, modules.MediatorDelegationKeyPrefix(args[0]))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	data, _ := json.Marshal(list)
+	return shim.Success(data)
+}
+
+//  查询某个地址委托给所有mediator的质押
+func queryDelegationsByAddr(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("need 1 arg, delegator address")
+	}
+	all, err := getDelegationsByPrefix(stub, string(constants.MEDIATOR_DELEGATION_PREFIX))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	list := []*modules.MediatorDelegation{}
+	for _, d := range all {
+		if d.Delegator == args[0] {
+			list = append(list, d)
+		}
+	}
+	data, _ := json.Marshal(list)
+	return shim.Success(data)
+}
@@ -371,6 +371,25 @@ func (d *DepositChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 	case modules.QueryPledgeListByDate:
 		log.Info("Enter DepositChaincode Contract " + modules.QueryPledgeListByDate + " Query")
 		return queryPledgeListByDate(stub, args)
+
+	//  委托质押给mediator，分享出块奖励
+	case modules.DelegateToMediator:
+		log.Info("Enter DepositChaincode Contract " + modules.DelegateToMediator + " Invoke")
+		return d.delegateToMediator(stub, args)
+	case modules.UndelegateFromMediator:
+		log.Info("Enter DepositChaincode Contract " + modules.UndelegateFromMediator + " Invoke")
+		return d.undelegateFromMediator(stub, args)
+	case modules.QueryMediatorDelegations:
+		log.Info("Enter DepositChaincode Contract " + modules.QueryMediatorDelegations + " Query")
+		return queryMediatorDelegations(stub, args)
+	case modules.QueryDelegationsByAddr:
+		log.Info("Enter DepositChaincode Contract " + modules.QueryDelegationsByAddr + " Query")
+		return queryDelegationsByAddr(stub, args)
+
+	//  任何人提交mediator重复出块的证据，直接没收其保证金
+	case modules.SlashMediatorByEvidence:
+		log.Info("Enter DepositChaincode Contract " + modules.SlashMediatorByEvidence + " Invoke")
+		return d.slashMediatorByEvidence(stub, args)
 		//TODO Devin一个用户，怎么查看自己的流水账？
 		//case AllPledgeVotes:
 		//	b, err := getVotes(stub)
@@ -560,7 +579,19 @@ func (d DepositChaincode) handlePledgeReward(stub shim.ChaincodeStubInterface, a
 	return handlePledgeReward(stub, args)
 }
 
-//  质押
+//  委托质押
+
+func (d DepositChaincode) delegateToMediator(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	return delegateToMediator(stub, args)
+}
+
+func (d DepositChaincode) undelegateFromMediator(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	return undelegateFromMediator(stub, args)
+}
+
+func (d DepositChaincode) slashMediatorByEvidence(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	return slashMediatorByEvidence(stub, args)
+}
 
 //  移除超级节点候选列表
 func (d DepositChaincode) handleMediatorInCandidateList(stub shim.ChaincodeStubInterface, args []string) pb.Response {
@@ -19,6 +19,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
 	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
 	"github.com/palletone/go-palletone/dag/dagconfig"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -33,9 +34,8 @@ func handleForApplyBecomeMediator(stub shim.ChaincodeStubInterface, args []strin
 		return shim.Error("args need two parameters")
 	}
 	//  判断是否基金会发起的
-	if !isFoundationInvoke(stub) {
-		log.Error("please use foundation address")
-		return shim.Error("please use foundation address")
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	//  判断处理地址是否申请过
 	isOk := strings.ToLower(args[1])
@@ -125,9 +125,8 @@ func handleForApplyQuitNode(stub shim.ChaincodeStubInterface, args []string, rol
 		return shim.Error("Arg need two parameter.")
 	}
 	//  判断是否基金会发起的
-	if !isFoundationInvoke(stub) {
-		log.Error("please use foundation address")
-		return shim.Error("please use foundation address")
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	addr, err := common.StringToAddress(args[0])
 	if err != nil {
@@ -172,9 +171,8 @@ func handleForApplyQuitMediator(stub shim.ChaincodeStubInterface, args []string)
 		return shim.Error("Arg need two parameter.")
 	}
 	//  判断是否基金会发起的
-	if !isFoundationInvoke(stub) {
-		log.Error("please use foundation address")
-		return shim.Error("please use foundation address")
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	addr, err := common.StringToAddress(args[0])
 	if err != nil {
@@ -213,9 +211,8 @@ func handleForForfeitureApplication(stub shim.ChaincodeStubInterface, args []str
 		return shim.Error("args need two parameters.")
 	}
 	//  判断是否基金会发起的
-	if !isFoundationInvoke(stub) {
-		log.Error("please use foundation address")
-		return shim.Error("please use foundation address")
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	//  处理没收地址
 	addr := args[0]
@@ -224,12 +221,6 @@ func handleForForfeitureApplication(stub shim.ChaincodeStubInterface, args []str
 	if err != nil {
 		return shim.Error(err.Error())
 	}
-	//  获取基金会地址
-	invokeAddr, err := stub.GetInvokeAddress()
-	if err != nil {
-		log.Error("get invoke address err: ", "error", err)
-		return shim.Error(err.Error())
-	}
 	//  需要判断是否在列表
 	listForForfeiture, err := GetListForForfeiture(stub)
 	if err != nil {
@@ -250,7 +241,8 @@ func handleForForfeitureApplication(stub shim.ChaincodeStubInterface, args []str
 	isOk := strings.ToLower(args[1])
 	//check 如果为ok，则同意此申请，如果为no，则不同意此申请
 	if isOk == modules.Ok {
-		err = agreeForApplyForfeiture(stub, invokeAddr.String(), f.String(), forfeitureNode.ForfeitureRole)
+		//  没收的保证金转入基金会合约，由基金会委员会多签支配
+		err = agreeForApplyForfeiture(stub, foundationcc.TreasuryAddress(), f.String(), forfeitureNode.ForfeitureRole)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
@@ -404,8 +396,8 @@ func hanldeNodeRemoveFromAgreeList(stub shim.ChaincodeStubInterface, args []stri
 	if err != nil {
 		return shim.Error(err.Error())
 	}
-	if !isFoundationInvoke(stub) {
-		return shim.Error("please use foundation address")
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	agreeList, err := getList(stub, modules.ListForAgreeBecomeMediator)
 	if err != nil {
@@ -450,9 +442,8 @@ func hanldeNodeRemoveFromAgreeList(stub shim.ChaincodeStubInterface, args []stri
 
 func handleNodeInList(stub shim.ChaincodeStubInterface, args []string, role string) pb.Response {
 	if len(args) > 0 {
-		if !isFoundationInvoke(stub) {
-			log.Debugf("please use foundation address")
-			return shim.Error("please use foundation address")
+		if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+			return rsp
 		}
 		//
 		list := ""
@@ -0,0 +1,78 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package deposit
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+//  根据mediator在同一个slot签名两个不同unit的证据，没收其保证金转入基金会合约，并移出候选列表，
+//  证据可以自行验证，所以不需要基金会审批
+func slashMediatorByEvidence(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("need 1 arg, the hex of rlp encoded evidence")
+	}
+	data, err := hex.DecodeString(args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	evidence := &modules.MediatorEvidencePayload{}
+	err = rlp.DecodeBytes(data, evidence)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid evidence: %s", err.Error()))
+	}
+	mediator, err := evidence.Verify()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid evidence: %s", err.Error()))
+	}
+
+	//  同一个mediator只处罚一次
+	key := modules.MediatorEvidencePrefix + mediator.String()
+	b, err := stub.GetState(key)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if b != nil {
+		return shim.Error(mediator.String() + " has been slashed")
+	}
+
+	md, err := GetMediatorDeposit(stub, mediator.Str())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if md == nil || md.Balance == 0 {
+		return shim.Error(mediator.String() + " has no deposit")
+	}
+
+	err = handleMediatorForfeitureDeposit(stub, foundationcc.TreasuryAddress(), mediator.Str())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	err = stub.PutState(key, data)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	log.Infof("mediator(%v) is slashed by evidence, forfeit deposit: %v", mediator.String(), md.Balance)
+	return shim.Success(nil)
+}
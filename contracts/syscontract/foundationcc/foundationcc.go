@@ -0,0 +1,148 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package foundationcc
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/contracts/shim"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+//基金会多签委员会合约，管理委员会成员和门限
+type FoundationMgr struct {
+}
+
+func (p *FoundationMgr) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (p *FoundationMgr) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	f, args := stub.GetFunctionAndParameters()
+
+	switch f {
+	case "setCommittee": //修改委员会成员，需要当前委员会多签通过
+		if len(args) != 3 {
+			return shim.Error("must input 3 args: membersJson, threshold, window")
+		}
+		c := &Committee{}
+		if err := json.Unmarshal([]byte(args[0]), &c.Members); err != nil {
+			return shim.Error("Invalid members json:" + args[0])
+		}
+		threshold, err := strconv.Atoi(args[1])
+		if err != nil {
+			return shim.Error("Invalid threshold:" + args[1])
+		}
+		c.Threshold = threshold
+		window, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return shim.Error("Invalid window:" + args[2])
+		}
+		c.Window = window
+		if err := c.Validate(); err != nil {
+			return shim.Error(err.Error())
+		}
+		if rsp, ok := CheckFoundation(stub); !ok {
+			return rsp
+		}
+		if err := p.SetCommittee(stub, c); err != nil {
+			return shim.Error("SetCommittee error:" + err.Error())
+		}
+		return shim.Success(nil)
+	case "payout": //从基金会合约中支付没收的保证金，需要委员会多签通过
+		if len(args) != 2 {
+			return shim.Error("must input 2 args: address, amount")
+		}
+		if _, err := common.StringToAddress(args[0]); err != nil {
+			return shim.Error("Invalid address:" + args[0])
+		}
+		amount, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil || amount == 0 {
+			return shim.Error("Invalid amount:" + args[1])
+		}
+		if rsp, ok := CheckFoundation(stub); !ok {
+			return rsp
+		}
+		gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+		if err := stub.PayOutToken(args[0], modules.NewAmountAsset(amount, gasToken), 0); err != nil {
+			return shim.Error("PayOutToken error:" + err.Error())
+		}
+		return shim.Success(nil)
+	case "getCommittee": //查询当前委员会
+		c, err := GetCommittee(stub)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		data, _ := json.Marshal(c)
+		return shim.Success(data)
+	case "getProposal": //查询本合约中的提案
+		if len(args) != 1 {
+			return shim.Error("must input 1 args: proposalId")
+		}
+		proposal, err := GetProposal(stub, args[0])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if proposal == nil {
+			return shim.Error("proposal not found:" + args[0])
+		}
+		data, _ := json.Marshal(proposal)
+		return shim.Success(data)
+	case "getProposals": //列出本合约中的所有提案
+		result, err := p.GetProposals(stub)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		data, _ := json.Marshal(result)
+		return shim.Success(data)
+	default:
+		jsonResp := "{\"Error\":\"Unknown function " + f + "\"}"
+		return shim.Error(jsonResp)
+	}
+}
+
+func (p *FoundationMgr) SetCommittee(stub shim.ChaincodeStubInterface, c *Committee) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(CommitteeKey, data)
+}
+
+func (p *FoundationMgr) GetProposals(stub shim.ChaincodeStubInterface) ([]*Proposal, error) {
+	kvs, err := stub.GetStateByPrefix(ProposalPrefix)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Proposal, 0, len(kvs))
+	for _, kv := range kvs {
+		proposal := &Proposal{}
+		if err := json.Unmarshal(kv.Value, proposal); err != nil {
+			return nil, err
+		}
+		result = append(result, proposal)
+	}
+	return result, nil
+}
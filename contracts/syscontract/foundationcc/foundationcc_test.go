@@ -0,0 +1,202 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package foundationcc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/shim/shimtest"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStub(mockCtrl *gomock.Controller, foundation common.Address) *shimtest.Stub {
+	stub := shimtest.NewStub(mockCtrl, syscontract.FoundationContractAddress)
+	stub.Gp.ChainParameters.FoundationAddress = foundation.String()
+	return stub
+}
+
+func invoke(stub *shimtest.Stub, invoker common.Address, function string, args ...string) (*Proposal, bool, error) {
+	stub.Call(invoker, function, args...)
+	return Approve(stub)
+}
+
+func setTestCommittee(t *testing.T, stub *shimtest.Stub, threshold int, members ...common.Address) {
+	c := &Committee{Threshold: threshold, Window: 3600}
+	for _, m := range members {
+		c.Members = append(c.Members, m.String())
+	}
+	assert.Nil(t, c.Validate())
+	assert.Nil(t, (&FoundationMgr{}).SetCommittee(stub, c))
+}
+
+func TestApprove_DefaultFoundation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	foundation := shimtest.NewAddress(1)
+	stub := newTestStub(mockCtrl, foundation)
+
+	c, err := GetCommittee(stub)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{foundation.String()}, c.Members)
+	assert.Equal(t, 1, c.Threshold)
+
+	//未设置委员会时，基金会地址单签直接通过
+	_, approved, err := invoke(stub, foundation, "addBlacklist", "P1xxx", "test")
+	assert.Nil(t, err)
+	assert.True(t, approved)
+	assert.Equal(t, 0, len(stub.DB))
+
+	_, approved, err = invoke(stub, shimtest.NewAddress(2), "addBlacklist", "P1xxx", "test")
+	assert.Equal(t, ErrNotCommitteeMember, err)
+	assert.False(t, approved)
+}
+
+func TestApprove_MultiSig(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	alice, bob, carol, dave := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3), shimtest.NewAddress(4)
+	stub := newTestStub(mockCtrl, alice)
+	setTestCommittee(t, stub, 2, alice, bob, carol)
+
+	p, approved, err := invoke(stub, alice, "payout", "P1xxx", "10", "PTN")
+	assert.Nil(t, err)
+	assert.False(t, approved)
+	assert.Equal(t, []string{alice.String()}, p.Signers)
+
+	//同一成员不能重复签名
+	_, _, err = invoke(stub, alice, "payout", "P1xxx", "10", "PTN")
+	assert.NotNil(t, err)
+	//非委员会成员不能签名
+	_, _, err = invoke(stub, dave, "payout", "P1xxx", "10", "PTN")
+	assert.Equal(t, ErrNotCommitteeMember, err)
+	//参数不同是另一个提案
+	p2, approved, err := invoke(stub, bob, "payout", "P1xxx", "11", "PTN")
+	assert.Nil(t, err)
+	assert.False(t, approved)
+	assert.NotEqual(t, p.Id, p2.Id)
+
+	p, approved, err = invoke(stub, carol, "payout", "P1xxx", "10", "PTN")
+	assert.Nil(t, err)
+	assert.True(t, approved)
+	assert.True(t, p.Executed)
+
+	//已执行的提案再次发起需要重新签名
+	_, approved, err = invoke(stub, bob, "payout", "P1xxx", "10", "PTN")
+	assert.Nil(t, err)
+	assert.False(t, approved)
+}
+
+func TestApprove_Expired(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	alice, bob := shimtest.NewAddress(1), shimtest.NewAddress(2)
+	stub := newTestStub(mockCtrl, alice)
+	setTestCommittee(t, stub, 2, alice, bob)
+
+	_, approved, err := invoke(stub, alice, "registerPartition", "a")
+	assert.Nil(t, err)
+	assert.False(t, approved)
+
+	//超过有效期，bob的签名开始一个新提案
+	stub.Now = stub.Now.Add(2 * time.Hour)
+	p, approved, err := invoke(stub, bob, "registerPartition", "a")
+	assert.Nil(t, err)
+	assert.False(t, approved)
+	assert.Equal(t, []string{bob.String()}, p.Signers)
+	assert.Equal(t, stub.Now.Unix(), p.CreateTime)
+
+	_, approved, err = invoke(stub, alice, "registerPartition", "a")
+	assert.Nil(t, err)
+	assert.True(t, approved)
+}
+
+func TestFoundationMgr_SetCommittee(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	alice, bob, carol := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3)
+	stub := newTestStub(mockCtrl, alice)
+	mgr := &FoundationMgr{}
+
+	//默认基金会单签设置2-of-2委员会
+	members, _ := json.Marshal([]string{alice.String(), bob.String()})
+	stub.Call(alice, "setCommittee", string(members), "2", "3600")
+	rsp := mgr.Invoke(stub)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	c, err := GetCommittee(stub)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, c.Threshold)
+
+	//修改委员会也需要2个成员签名
+	members, _ = json.Marshal([]string{alice.String(), bob.String(), carol.String()})
+	stub.Call(bob, "setCommittee", string(members), "2", "3600")
+	rsp = mgr.Invoke(stub)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	c, _ = GetCommittee(stub)
+	assert.Equal(t, 2, len(c.Members))
+
+	stub.Invoker = alice
+	rsp = mgr.Invoke(stub)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	c, _ = GetCommittee(stub)
+	assert.Equal(t, 3, len(c.Members))
+
+	stub.Function, stub.Args = "getProposals", nil
+	rsp = mgr.Invoke(stub)
+	proposals := []*Proposal{}
+	assert.Nil(t, json.Unmarshal(rsp.Payload, &proposals))
+	assert.Equal(t, 1, len(proposals))
+	assert.True(t, proposals[0].Executed)
+
+	//非法的委员会
+	stub.Function, stub.Args = "setCommittee", []string{string(members), "4", "3600"}
+	rsp = mgr.Invoke(stub)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	dup, _ := json.Marshal([]string{alice.String(), alice.String()})
+	stub.Args = []string{string(dup), "1", "3600"}
+	rsp = mgr.Invoke(stub)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+}
+
+func TestFoundationMgr_Payout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	alice, bob, carol := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3)
+	stub := newTestStub(mockCtrl, alice)
+	setTestCommittee(t, stub, 2, alice, bob)
+	mgr := &FoundationMgr{}
+
+	rsp := stub.Invoke(mgr, carol, "payout", carol.String(), "100")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = stub.Invoke(mgr, alice, "payout", carol.String(), "0")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	//没收的保证金需要委员会多签才能支付
+	rsp = stub.Invoke(mgr, alice, "payout", carol.String(), "100")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(0), stub.Paid[carol.String()])
+	rsp = stub.Invoke(mgr, bob, "payout", carol.String(), "100")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(100), stub.Paid[carol.String()])
+}
@@ -0,0 +1,247 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package foundationcc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+)
+
+const (
+	CommitteeKey   = "FoundationCommittee"
+	ProposalPrefix = "FoundationProposal_"
+
+	//默认提案有效期7天，单位秒
+	DefaultProposalWindow = 7 * 24 * 3600
+)
+
+var ErrNotCommitteeMember = errors.New("only foundation committee member can call this function")
+
+//基金会委员会，m-of-n 多签
+type Committee struct {
+	Members   []string `json:"members"`
+	Threshold int      `json:"threshold"`
+	Window    int64    `json:"window"` //提案有效期，单位秒
+}
+
+func (c *Committee) Validate() error {
+	if len(c.Members) == 0 {
+		return errors.New("committee members is empty")
+	}
+	exist := make(map[string]bool, len(c.Members))
+	for _, m := range c.Members {
+		if _, err := common.StringToAddress(m); err != nil {
+			return fmt.Errorf("invalid committee member address: %s", m)
+		}
+		if exist[m] {
+			return fmt.Errorf("duplicate committee member: %s", m)
+		}
+		exist[m] = true
+	}
+	if c.Threshold < 1 || c.Threshold > len(c.Members) {
+		return fmt.Errorf("threshold must be between 1 and %d", len(c.Members))
+	}
+	if c.Window <= 0 {
+		return errors.New("proposal window must be positive")
+	}
+	return nil
+}
+
+func (c *Committee) IsMember(addr string) bool {
+	for _, m := range c.Members {
+		if m == addr {
+			return true
+		}
+	}
+	return false
+}
+
+//需要基金会委员会批准的提案，由函数名和参数唯一确定
+type Proposal struct {
+	Id         string   `json:"id"`
+	Function   string   `json:"function"`
+	Args       []string `json:"args"`
+	Signers    []string `json:"signers"`
+	Threshold  int      `json:"threshold"`
+	CreateTime int64    `json:"create_time"`
+	ExpireTime int64    `json:"expire_time"`
+	Executed   bool     `json:"executed"`
+}
+
+func proposalId(function string, args []string) string {
+	data, _ := json.Marshal(append([]string{function}, args...))
+	return crypto.Keccak256Hash(data).Hex()
+}
+
+func (p *Proposal) hasSigned(addr string) bool {
+	for _, s := range p.Signers {
+		if s == addr {
+			return true
+		}
+	}
+	return false
+}
+
+//只统计仍然是委员会成员的签名
+func (p *Proposal) validSigns(c *Committee) int {
+	count := 0
+	for _, s := range p.Signers {
+		if c.IsMember(s) {
+			count++
+		}
+	}
+	return count
+}
+
+// TreasuryAddress returns the address that receives forfeited deposits, the
+// tokens can only be paid out by the foundation committee through the payout
+// function of the foundation contract.
+func TreasuryAddress() string {
+	return syscontract.FoundationContractAddress.String()
+}
+
+// GetCommittee returns the foundation committee stored in the foundation
+// contract, if it has never been set, the FoundationAddress in chain parameters
+// is a 1-of-1 committee.
+func GetCommittee(stub shim.ChaincodeStubInterface) (*Committee, error) {
+	data, err := stub.GetContractState(syscontract.FoundationContractAddress, CommitteeKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		c := &Committee{}
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	gp, err := stub.GetSystemConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &Committee{
+		Members:   []string{gp.ChainParameters.FoundationAddress},
+		Threshold: 1,
+		Window:    DefaultProposalWindow,
+	}, nil
+}
+
+func GetProposal(stub shim.ChaincodeStubInterface, id string) (*Proposal, error) {
+	data, err := stub.GetState(ProposalPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	p := &Proposal{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func saveProposal(stub shim.ChaincodeStubInterface, p *Proposal) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(ProposalPrefix+p.Id, data)
+}
+
+// Approve records the signature of the invoker on the proposal identified by
+// the function name and args of this invoke. The first member creates the
+// proposal, the others co-sign it by invoking the same function with the same
+// args before it expires. It returns true once the threshold is reached, and
+// the caller should execute the function in this invoke.
+func Approve(stub shim.ChaincodeStubInterface) (*Proposal, bool, error) {
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return nil, false, err
+	}
+	signer := invokeAddr.String()
+	c, err := GetCommittee(stub)
+	if err != nil {
+		return nil, false, err
+	}
+	if !c.IsMember(signer) {
+		return nil, false, ErrNotCommitteeMember
+	}
+
+	function, args := stub.GetFunctionAndParameters()
+	id := proposalId(function, args)
+	//单签委员会直接执行，不保存提案
+	if c.Threshold == 1 {
+		return &Proposal{Id: id, Function: function, Args: args, Signers: []string{signer}, Threshold: 1,
+			Executed: true}, true, nil
+	}
+
+	ts, err := stub.GetTxTimestamp(10)
+	if err != nil {
+		return nil, false, err
+	}
+	now := ts.Seconds
+	p, err := GetProposal(stub, id)
+	if err != nil {
+		return nil, false, err
+	}
+	//已执行或已过期的提案重新开始
+	if p == nil || p.Executed || now > p.ExpireTime {
+		p = &Proposal{Id: id, Function: function, Args: args, CreateTime: now, ExpireTime: now + c.Window}
+	}
+	if p.hasSigned(signer) {
+		return nil, false, fmt.Errorf("%s has already signed proposal %s", signer, id)
+	}
+	p.Signers = append(p.Signers, signer)
+	p.Threshold = c.Threshold
+	if p.validSigns(c) >= c.Threshold {
+		p.Executed = true
+	}
+	if err := saveProposal(stub, p); err != nil {
+		return nil, false, err
+	}
+	log.Debugf("foundation proposal %s signed by %s, %d/%d", id, signer, p.validSigns(c), c.Threshold)
+	return p, p.Executed, nil
+}
+
+// CheckFoundation is the shim.Response version of Approve, if the proposal is
+// not approved yet, the returned response should be returned to the invoker
+// directly.
+func CheckFoundation(stub shim.ChaincodeStubInterface) (pb.Response, bool) {
+	p, approved, err := Approve(stub)
+	if err != nil {
+		log.Debugf("foundation approve failed: %s", err.Error())
+		return shim.Error(err.Error()), false
+	}
+	if !approved {
+		data, _ := json.Marshal(p)
+		return shim.Success(data), false
+	}
+	return pb.Response{}, true
+}
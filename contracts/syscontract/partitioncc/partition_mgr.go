@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
 	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
 	"github.com/palletone/go-palletone/dag/errors"
 	dm "github.com/palletone/go-palletone/dag/modules"
@@ -135,20 +136,9 @@ func buildPartitionChain(args []string) (*dm.PartitionChain, error) {
 	partitionChain.CrossChainTokens = tokens
 	return partitionChain, nil
 }
-func hasPermission(stub shim.ChaincodeStubInterface) bool {
-	requester, _, _, _, _, _ := stub.GetInvokeParameters()
-	//foundationAddress, _ := stub.GetSystemConfig(dm.FoundationAddress)
-	gp, err := stub.GetSystemConfig()
-	if err != nil {
-		//log.Error("strconv.ParseUint err:", "error", err)
-		return false
-	}
-	foundationAddress := gp.ChainParameters.FoundationAddress
-	return foundationAddress == requester.String()
-}
 func registerPartition(args []string, stub shim.ChaincodeStubInterface) pb.Response {
-	if !hasPermission(stub) {
-		return shim.Error(ErrorForbiddenAccess)
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	//params check
 	partitionChain, err := buildPartitionChain(args)
@@ -175,8 +165,8 @@ func listPartition(stub shim.ChaincodeStubInterface) pb.Response {
 }
 
 func updatePartition(args []string, stub shim.ChaincodeStubInterface) pb.Response {
-	if !hasPermission(stub) {
-		return shim.Error(ErrorForbiddenAccess)
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	partitionChain, err := buildPartitionChain(args)
 	if err != nil {
@@ -232,8 +222,8 @@ func buildMainChain(args []string) (*dm.MainChain, error) {
 	return mainChain, nil
 }
 func setMainChain(args []string, stub shim.ChaincodeStubInterface) pb.Response {
-	if !hasPermission(stub) {
-		return shim.Error(ErrorForbiddenAccess)
+	if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+		return rsp
 	}
 	mainChain, err := buildMainChain(args)
 	if err != nil {
@@ -0,0 +1,280 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package policycc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/tokenengine"
+)
+
+//账户支出策略合约，策略账户的每笔支出都要调用本合约的spend方法，由合约检查限额、白名单和第二因子签名
+type PolicyChainCode struct {
+}
+
+func (p *PolicyChainCode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (p *PolicyChainCode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	f, args := stub.GetFunctionAndParameters()
+
+	switch f {
+	case modules.SetPolicy: //所有者设置或修改策略
+		if len(args) < 2 {
+			return shim.Error("must input 2 args: ownerPubKey, policyJson, [secondFactorSign]")
+		}
+		ownerPubKey, err := hex.DecodeString(args[0])
+		if err != nil {
+			return shim.Error("Invalid owner public key:" + args[0])
+		}
+		sign, err := decodeSign(args, 2)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		account, err := p.SetPolicy(stub, ownerPubKey, []byte(args[1]), sign)
+		if err != nil {
+			return shim.Error("SetPolicy error:" + err.Error())
+		}
+		data, _ := json.Marshal(account)
+		return shim.Success(data)
+	case modules.PolicySpend: //策略账户支出，由锁定脚本要求调用
+		if len(args) < 1 {
+			return shim.Error("must input 1 args: account, [secondFactorSign]")
+		}
+		sign, err := decodeSign(args, 1)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		err = p.Spend(stub, args[0], sign)
+		if err != nil {
+			return shim.Error("Spend error:" + err.Error())
+		}
+		return shim.Success(nil)
+	case modules.GetPolicy:
+		if len(args) != 1 {
+			return shim.Error("must input 1 args: account")
+		}
+		account, err := getPolicyAccount(stub, args[0])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if account == nil {
+			return shim.Error("policy not found:" + args[0])
+		}
+		if now, err := getNow(stub); err == nil {
+			account.ApplyPending(now)
+		}
+		data, _ := json.Marshal(account)
+		return shim.Success(data)
+	default:
+		jsonResp := "{\"Error\":\"Unknown function " + f + "\"}"
+		return shim.Error(jsonResp)
+	}
+}
+
+//设置策略，首次设置立即生效；之后修改需要第二因子签名才能立即生效，否则等待PolicyChangeDelay后生效
+func (p *PolicyChainCode) SetPolicy(stub shim.ChaincodeStubInterface, ownerPubKey, policyJson []byte,
+	sign []byte) (*modules.PolicyAccount, error) {
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyBytesToAddress(ownerPubKey) != invokeAddr {
+		return nil, errors.New("only the owner can set policy")
+	}
+	policy := &modules.SpendingPolicy{}
+	if err := json.Unmarshal(policyJson, policy); err != nil {
+		return nil, fmt.Errorf("invalid policy json: %s", err.Error())
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	now, err := getNow(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	redeem := tokenengine.Instance.GeneratePolicyRedeemScript(ownerPubKey, syscontract.PolicyContractAddress)
+	accountAddr := crypto.ScriptToAddress(redeem).String()
+	account, err := getPolicyAccount(stub, accountAddr)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		account = &modules.PolicyAccount{Account: accountAddr, Owner: hex.EncodeToString(ownerPubKey),
+			Policy: policy}
+		log.Debugf("create policy account %s", accountAddr)
+		return account, savePolicyAccount(stub, account)
+	}
+
+	account.ApplyPending(now)
+	hash := modules.PolicyAuthHash(accountAddr, account.Nonce, policyJson)
+	if account.Policy.VerifySecondFactor(hash, sign) {
+		account.Policy = policy
+		account.Pending = nil
+		account.PendingTime = 0
+		account.Nonce++
+	} else {
+		account.Pending = policy
+		account.PendingTime = now + modules.PolicyChangeDelay
+	}
+	return account, savePolicyAccount(stub, account)
+}
+
+//检查本交易从策略账户支出的Token是否符合策略，并累计当天的支出数量
+func (p *PolicyChainCode) Spend(stub shim.ChaincodeStubInterface, accountAddr string, sign []byte) error {
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return err
+	}
+	if invokeAddr.String() != accountAddr {
+		return errors.New("the first input of tx must be spent from the policy account")
+	}
+	account, err := getPolicyAccount(stub, accountAddr)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return errors.New("policy not set for account " + accountAddr)
+	}
+	now, err := getNow(stub)
+	if err != nil {
+		return err
+	}
+	account.ApplyPending(now)
+	policy := account.Policy
+
+	tokens, err := stub.GetInvokeTokens()
+	if err != nil {
+		return err
+	}
+	//找零回到策略账户的不算支出
+	spending := make(map[string]uint64)
+	recipients := []string{}
+	for _, token := range tokens {
+		if token.Address == accountAddr || token.Amount == 0 {
+			continue
+		}
+		spending[token.Asset.String()] += token.Amount
+		recipients = append(recipients, token.Address)
+	}
+
+	authorized := policy.VerifySecondFactor(modules.PolicySpendHash(accountAddr, account.Nonce, tokens), sign)
+	if authorized {
+		account.Nonce++
+	} else {
+		for _, addr := range recipients {
+			if !policy.InWhitelist(addr) {
+				return fmt.Errorf("recipient %s is not in whitelist", addr)
+			}
+		}
+		for asset, amount := range spending {
+			if limit, ok := policy.SecondFactorLimit[asset]; ok && amount > limit {
+				return fmt.Errorf("spend %d %s requires second factor signature", amount, asset)
+			}
+		}
+	}
+
+	day := time.Unix(now, 0).UTC().Format("20060102")
+	for asset, amount := range spending {
+		key := spentKey(accountAddr, day, asset)
+		spent, err := getUint64(stub, key)
+		if err != nil {
+			return err
+		}
+		spent += amount
+		if limit, ok := policy.DailyLimit[asset]; ok && !authorized && spent > limit {
+			return fmt.Errorf("daily limit of %s exceeded, limit %d, spent %d", asset, limit, spent)
+		}
+		err = stub.PutState(key, []byte(strconv.FormatUint(spent, 10)))
+		if err != nil {
+			return err
+		}
+	}
+	return savePolicyAccount(stub, account)
+}
+
+func decodeSign(args []string, idx int) ([]byte, error) {
+	if len(args) <= idx || args[idx] == "" {
+		return nil, nil
+	}
+	sign, err := hex.DecodeString(args[idx])
+	if err != nil {
+		return nil, errors.New("Invalid second factor signature:" + args[idx])
+	}
+	return sign, nil
+}
+
+func spentKey(account, day, asset string) string {
+	return modules.PolicySpentPrefix + account + "_" + day + "_" + asset
+}
+
+func getUint64(stub shim.ChaincodeStubInterface, key string) (uint64, error) {
+	data, err := stub.GetState(key)
+	if err != nil || len(data) == 0 {
+		return 0, err
+	}
+	return strconv.ParseUint(string(data), 10, 64)
+}
+
+func getNow(stub shim.ChaincodeStubInterface) (int64, error) {
+	ts, err := stub.GetTxTimestamp(10)
+	if err != nil {
+		return 0, err
+	}
+	return ts.Seconds, nil
+}
+
+func getPolicyAccount(stub shim.ChaincodeStubInterface, account string) (*modules.PolicyAccount, error) {
+	data, err := stub.GetState(modules.PolicyPrefix + account)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	result := &modules.PolicyAccount{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func savePolicyAccount(stub shim.ChaincodeStubInterface, account *modules.PolicyAccount) error {
+	data, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(modules.PolicyPrefix+account.Account, data)
+}
@@ -0,0 +1,172 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package policycc
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/shim/shimtest"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStub(mockCtrl *gomock.Controller) *shimtest.Stub {
+	stub := shimtest.NewStub(mockCtrl, syscontract.PolicyContractAddress)
+	stub.Now = time.Date(2019, 1, 1, 8, 0, 0, 0, time.UTC)
+	return stub
+}
+
+func spend(stub *shimtest.Stub, account common.Address, to string, amount uint64, sign string) pb.Response {
+	stub.Tokens = []*modules.InvokeTokens{{Amount: amount, Asset: modules.NewPTNAsset(), Address: to}}
+	return stub.Invoke(&PolicyChainCode{}, account, modules.PolicySpend, account.String(), sign)
+}
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	prvKey, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+	return prvKey, crypto.CompressPubkey(&prvKey.PublicKey)
+}
+
+func signHash(t *testing.T, prvKey *ecdsa.PrivateKey, hash []byte) string {
+	sign, err := crypto.Sign(hash, prvKey)
+	assert.Nil(t, err)
+	return hex.EncodeToString(sign)
+}
+
+func TestPolicy_Spend(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	stub := newTestStub(mockCtrl)
+	cc := &PolicyChainCode{}
+	_, ownerPubKey := newTestKey(t)
+	factorKey, factorPubKey := newTestKey(t)
+	owner := crypto.PubkeyBytesToAddress(ownerPubKey)
+	bob := crypto.PubkeyBytesToAddress([]byte("bob")).String()
+	carol := crypto.PubkeyBytesToAddress([]byte("carol")).String()
+	ptn := modules.NewPTNAsset().String()
+
+	policy := &modules.SpendingPolicy{
+		DailyLimit:        map[string]uint64{ptn: 100},
+		SecondFactorLimit: map[string]uint64{ptn: 60},
+		Whitelist:         []string{bob},
+		SecondFactorKey:   hex.EncodeToString(factorPubKey),
+	}
+	policyJson, _ := json.Marshal(policy)
+
+	//只有所有者可以设置策略
+	rsp := stub.Invoke(cc, crypto.PubkeyBytesToAddress([]byte("other")), modules.SetPolicy,
+		hex.EncodeToString(ownerPubKey), string(policyJson))
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = stub.Invoke(cc, owner, modules.SetPolicy, hex.EncodeToString(ownerPubKey), string(policyJson))
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	account := &modules.PolicyAccount{}
+	assert.Nil(t, json.Unmarshal(rsp.Payload, account))
+	accountAddr, err := common.StringToAddress(account.Account)
+	assert.Nil(t, err)
+	assert.Equal(t, common.ScriptHash, accountAddr.GetType())
+
+	//第一个input必须来自策略账户
+	stub.Tokens = []*modules.InvokeTokens{{Amount: 10, Asset: modules.NewPTNAsset(), Address: bob}}
+	rsp = stub.Invoke(cc, owner, modules.PolicySpend, account.Account)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	rsp = spend(stub, accountAddr, bob, 50, "")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	//不在白名单
+	rsp = spend(stub, accountAddr, carol, 10, "")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	//超过单笔限额需要第二因子
+	rsp = spend(stub, accountAddr, bob, 70, "")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	//超过每日限额
+	rsp = spend(stub, accountAddr, bob, 60, "")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = spend(stub, accountAddr, bob, 50, "")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+
+	//第二因子签名可以突破限制，签名绑定收款地址和数量，同一签名不能重放
+	payment := []*modules.InvokeTokens{{Amount: 500, Asset: modules.NewPTNAsset(), Address: carol}}
+	sign := signHash(t, factorKey, modules.PolicySpendHash(account.Account, 0, payment))
+	rsp = spend(stub, accountAddr, carol, 600, sign)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = spend(stub, accountAddr, bob, 500, sign)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = spend(stub, accountAddr, carol, 500, sign)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	rsp = spend(stub, accountAddr, carol, 500, sign)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	//第二天限额重新计算
+	stub.Now = stub.Now.Add(24 * time.Hour)
+	rsp = spend(stub, accountAddr, bob, 60, "")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+}
+
+func TestPolicy_Change(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	stub := newTestStub(mockCtrl)
+	cc := &PolicyChainCode{}
+	_, ownerPubKey := newTestKey(t)
+	factorKey, factorPubKey := newTestKey(t)
+	owner := crypto.PubkeyBytesToAddress(ownerPubKey)
+	ptn := modules.NewPTNAsset().String()
+
+	policyJson, _ := json.Marshal(&modules.SpendingPolicy{DailyLimit: map[string]uint64{ptn: 100},
+		SecondFactorKey: hex.EncodeToString(factorPubKey)})
+	rsp := stub.Invoke(cc, owner, modules.SetPolicy, hex.EncodeToString(ownerPubKey), string(policyJson))
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	account := &modules.PolicyAccount{}
+	assert.Nil(t, json.Unmarshal(rsp.Payload, account))
+
+	//没有第二因子签名时，放宽限额需要等待
+	looseJson, _ := json.Marshal(&modules.SpendingPolicy{DailyLimit: map[string]uint64{ptn: 1000},
+		SecondFactorKey: hex.EncodeToString(factorPubKey)})
+	rsp = stub.Invoke(cc, owner, modules.SetPolicy, hex.EncodeToString(ownerPubKey), string(looseJson))
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Nil(t, json.Unmarshal(rsp.Payload, account))
+	assert.Equal(t, uint64(100), account.Policy.DailyLimit[ptn])
+	assert.Equal(t, stub.Now.Unix()+modules.PolicyChangeDelay, account.PendingTime)
+
+	stub.Now = stub.Now.Add(modules.PolicyChangeDelay * time.Second)
+	rsp = stub.Invoke(cc, owner, modules.GetPolicy, account.Account)
+	assert.Nil(t, json.Unmarshal(rsp.Payload, account))
+	assert.Equal(t, uint64(1000), account.Policy.DailyLimit[ptn])
+	assert.Nil(t, account.Pending)
+
+	//有第二因子签名时立即生效
+	strictJson, _ := json.Marshal(&modules.SpendingPolicy{DailyLimit: map[string]uint64{ptn: 10},
+		SecondFactorKey: hex.EncodeToString(factorPubKey)})
+	sign := signHash(t, factorKey, modules.PolicyAuthHash(account.Account, account.Nonce, strictJson))
+	rsp = stub.Invoke(cc, owner, modules.SetPolicy, hex.EncodeToString(ownerPubKey), string(strictJson), sign)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Nil(t, json.Unmarshal(rsp.Payload, account))
+	assert.Equal(t, uint64(10), account.Policy.DailyLimit[ptn])
+	assert.Equal(t, uint64(1), account.Nonce)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/math"
 	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
 	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
 	dm "github.com/palletone/go-palletone/dag/modules"
 
@@ -444,14 +445,9 @@ func frozenToken(args []string, stub shim.ChaincodeStubInterface) pb.Response {
 		ownerAddr = gTkInfo.CreateAddr
 	}
 	if invokeAddrStr != ownerAddr {
-		gp, err := stub.GetSystemConfig()
-		if err != nil {
-			jsonResp := "{\"Error\":\"GetSystemConfig() failed\"}"
-			return shim.Error(jsonResp)
-		}
-		if invokeAddrStr != gp.ChainParameters.FoundationAddress {
-			jsonResp := "{\"Error\":\"Only the FoundationAddress or Owner can frozen token\"}"
-			return shim.Error(jsonResp)
+		//不是owner时需要基金会委员会多签通过
+		if rsp, ok := foundationcc.CheckFoundation(stub); !ok {
+			return rsp
 		}
 	}
 
@@ -0,0 +1,477 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package schedulecc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/shim"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+//一次调用最多执行的支付期数，避免到期计划太多时交易过大
+const MaxPaymentsPerInvoke = 100
+
+//定时支付合约，付款人将资金托管到合约，由mediator在到期时触发支付
+type ScheduledPaymentChainCode struct {
+}
+
+func (s *ScheduledPaymentChainCode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (s *ScheduledPaymentChainCode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	f, args := stub.GetFunctionAndParameters()
+
+	switch f {
+	case modules.CreateSchedule: //托管资金并创建支付计划
+		if len(args) != 4 && len(args) != 5 {
+			return shim.Error("must input 4 or 5 args: recipient, startTime, interval, count, [startHeight]")
+		}
+		recipient, err := common.StringToAddress(args[0])
+		if err != nil {
+			return shim.Error("Invalid address string:" + args[0])
+		}
+		startTime, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return shim.Error("Invalid start time:" + args[1])
+		}
+		interval, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return shim.Error("Invalid interval:" + args[2])
+		}
+		count, err := strconv.ParseUint(args[3], 10, 32)
+		if err != nil {
+			return shim.Error("Invalid count:" + args[3])
+		}
+		startHeight := uint64(0)
+		if len(args) == 5 {
+			startHeight, err = strconv.ParseUint(args[4], 10, 64)
+			if err != nil {
+				return shim.Error("Invalid start height:" + args[4])
+			}
+		}
+		schedule, err := s.CreateSchedule(stub, recipient, startTime, interval, uint32(count), startHeight)
+		if err != nil {
+			return shim.Error("CreateSchedule error:" + err.Error())
+		}
+		data, _ := json.Marshal(schedule)
+		return shim.Success(data)
+	case modules.CancelSchedule: //取消支付计划，剩余资金退回付款人
+		if len(args) != 1 {
+			return shim.Error("must input 1 args: scheduleId")
+		}
+		err := s.CancelSchedule(stub, args[0])
+		if err != nil {
+			return shim.Error("CancelSchedule error:" + err.Error())
+		}
+		return shim.Success(nil)
+	case modules.ExecuteDueSchedules: //执行所有到期的支付
+		paid, err := s.ExecuteDueSchedules(stub)
+		if err != nil {
+			return shim.Error("ExecuteDueSchedules error:" + err.Error())
+		}
+		data, _ := json.Marshal(paid)
+		return shim.Success(data)
+	case modules.GetSchedule:
+		if len(args) != 1 {
+			return shim.Error("must input 1 args: scheduleId")
+		}
+		schedule, err := getSchedule(stub, args[0])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if schedule == nil {
+			return shim.Error("schedule not found:" + args[0])
+		}
+		data, _ := json.Marshal(schedule)
+		return shim.Success(data)
+	case modules.ListSchedules: //列出所有计划，或者某地址作为付款人或收款人的计划
+		addr := ""
+		if len(args) > 0 {
+			addr = args[0]
+		}
+		result, err := s.ListSchedules(stub, addr)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		data, _ := json.Marshal(result)
+		return shim.Success(data)
+	default:
+		jsonResp := "{\"Error\":\"Unknown function " + f + "\"}"
+		return shim.Error(jsonResp)
+	}
+}
+
+func (s *ScheduledPaymentChainCode) CreateSchedule(stub shim.ChaincodeStubInterface, recipient common.Address,
+	startTime, interval int64, count uint32, startHeight uint64) (*modules.Schedule, error) {
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return nil, err
+	}
+	gp, err := stub.GetSystemConfig()
+	if err != nil {
+		return nil, err
+	}
+	//每期支付都要付手续费给触发执行的mediator，由付款人预先全部托管
+	fee := gp.ChainParameters.TransferPtnBaseFee
+	escrow, err := getEscrowToken(stub, fee*uint64(count))
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 || escrow.Amount%uint64(count) != 0 {
+		return nil, fmt.Errorf("escrow amount %d can not be divided into %d payments", escrow.Amount, count)
+	}
+	now, err := getNow(stub)
+	if err != nil {
+		return nil, err
+	}
+	//开始时间已过则立即开始支付
+	if startTime < now {
+		startTime = now
+	}
+	schedule := &modules.Schedule{
+		Id:        stub.GetTxID(),
+		Owner:     invokeAddr.String(),
+		Recipient: recipient.String(),
+		Asset:     escrow.Asset.String(),
+		Amount:    escrow.Amount / uint64(count),
+		StartTime: startTime,
+		Interval:  interval,
+		Count:     count,
+		NextTime:  startTime,
+		Escrow:    escrow.Amount,
+
+		Fee:         fee,
+		FeeEscrow:   fee * uint64(count),
+		StartHeight: startHeight,
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	schedules, err := getAllSchedules(stub)
+	if err != nil {
+		return nil, err
+	}
+	for _, exist := range schedules {
+		if exist.Id == schedule.Id {
+			return nil, errors.New("schedule already exist:" + schedule.Id)
+		}
+	}
+	if err := saveSchedule(stub, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, updateNextDue(stub, append(schedules, schedule))
+}
+
+func (s *ScheduledPaymentChainCode) CancelSchedule(stub shim.ChaincodeStubInterface, id string) error {
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return err
+	}
+	schedules, err := getAllSchedules(stub)
+	if err != nil {
+		return err
+	}
+	var schedule *modules.Schedule
+	for _, sc := range schedules {
+		if sc.Id == id {
+			schedule = sc
+			break
+		}
+	}
+	if schedule == nil {
+		return errors.New("schedule not found:" + id)
+	}
+	if schedule.Owner != invokeAddr.String() {
+		return errors.New("only the owner can cancel the schedule")
+	}
+	if !schedule.IsActive() {
+		return errors.New("schedule is already finished or canceled")
+	}
+	schedule.Canceled = true
+	if err := closeSchedule(stub, schedule); err != nil {
+		return err
+	}
+	return updateNextDue(stub, schedules)
+}
+
+// ExecuteDueSchedules pays all due payments, the payments missed are paid
+// together. It returns the ids of the schedules paid.
+func (s *ScheduledPaymentChainCode) ExecuteDueSchedules(stub shim.ChaincodeStubInterface) ([]string, error) {
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return nil, err
+	}
+	//手续费支付给触发执行的地址，只允许活跃mediator触发
+	gp, err := stub.GetSystemConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !gp.IsActiveMediator(invokeAddr) {
+		return nil, errors.New("only active mediator can execute due schedules")
+	}
+	now, err := getNow(stub)
+	if err != nil {
+		return nil, err
+	}
+	height, err := stub.GetTxHeight(10)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := getAllSchedules(stub)
+	if err != nil {
+		return nil, err
+	}
+	paid := []string{}
+	payments := uint32(0)
+	for _, schedule := range schedules {
+		due := schedule.DueCount(now, height)
+		if due == 0 {
+			continue
+		}
+		if payments+due > MaxPaymentsPerInvoke {
+			due = MaxPaymentsPerInvoke - payments
+		}
+		if due == 0 {
+			break
+		}
+		asset, err := modules.StringToAsset(schedule.Asset)
+		if err != nil {
+			return nil, err
+		}
+		amount := schedule.Amount * uint64(due)
+		err = stub.PayOutToken(schedule.Recipient, &modules.AmountAsset{Amount: amount, Asset: asset}, 0)
+		if err != nil {
+			return nil, err
+		}
+		//手续费按执行次数收取，一次补付多期也只收一次，多余的在计划结束时退回
+		if schedule.Fee > 0 {
+			gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+			err = stub.PayOutToken(invokeAddr.String(), modules.NewAmountAsset(schedule.Fee, gasToken), 0)
+			if err != nil {
+				return nil, err
+			}
+			schedule.FeeEscrow -= schedule.Fee
+		}
+		schedule.Paid += due
+		schedule.Escrow -= amount
+		schedule.NextTime += int64(due) * schedule.Interval
+		if schedule.IsActive() {
+			err = saveSchedule(stub, schedule)
+		} else {
+			err = closeSchedule(stub, schedule)
+		}
+		if err != nil {
+			return nil, err
+		}
+		payments += due
+		paid = append(paid, schedule.Id)
+		log.Debugf("schedule %s paid %d to %s, %d/%d", schedule.Id, amount, schedule.Recipient,
+			schedule.Paid, schedule.Count)
+	}
+	if len(paid) == 0 {
+		return nil, errors.New("no schedule is due")
+	}
+	return paid, updateNextDue(stub, schedules)
+}
+
+func (s *ScheduledPaymentChainCode) ListSchedules(stub shim.ChaincodeStubInterface,
+	addr string) ([]*modules.Schedule, error) {
+	schedules, err := getAllSchedules(stub)
+	if err != nil {
+		return nil, err
+	}
+	if addr == "" {
+		return schedules, nil
+	}
+	result := []*modules.Schedule{}
+	for _, schedule := range schedules {
+		if schedule.Owner == addr || schedule.Recipient == addr {
+			result = append(result, schedule)
+		}
+	}
+	return result, nil
+}
+
+//付款人转给本合约的Token即为托管资金，除了gas token的手续费外只能是一种Token。
+//托管的就是gas token时，手续费从托管资金中扣除，否则必须另外转入正好feeReserve的gas token
+func getEscrowToken(stub shim.ChaincodeStubInterface, feeReserve uint64) (*modules.InvokeTokens, error) {
+	invokeTokens, err := stub.GetInvokeTokens()
+	if err != nil {
+		return nil, err
+	}
+	_, contractAddr := stub.GetContractID()
+	gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+	var escrow, fee *modules.InvokeTokens
+	for _, token := range invokeTokens {
+		if token.Address != contractAddr || token.Amount == 0 {
+			continue
+		}
+		if token.Asset.Equal(gasToken) {
+			if fee == nil {
+				fee = &modules.InvokeTokens{Asset: token.Asset, Address: token.Address}
+			}
+			fee.Amount += token.Amount
+			continue
+		}
+		if escrow != nil && !escrow.Asset.Equal(token.Asset) {
+			return nil, errors.New("only one asset can be escrowed in a schedule")
+		}
+		if escrow == nil {
+			escrow = &modules.InvokeTokens{Asset: token.Asset, Address: token.Address}
+		}
+		escrow.Amount += token.Amount
+	}
+	if escrow == nil && fee == nil {
+		return nil, errors.New("no token is escrowed to the contract")
+	}
+	if escrow == nil {
+		if fee.Amount <= feeReserve {
+			return nil, fmt.Errorf("escrow amount %d is not enough to pay the fee %d", fee.Amount, feeReserve)
+		}
+		fee.Amount -= feeReserve
+		return fee, nil
+	}
+	feeAmount := uint64(0)
+	if fee != nil {
+		feeAmount = fee.Amount
+	}
+	if feeAmount != feeReserve {
+		return nil, fmt.Errorf("must escrow %d %s for the fee, got %d", feeReserve, gasToken.String(), feeAmount)
+	}
+	return escrow, nil
+}
+
+func getNow(stub shim.ChaincodeStubInterface) (int64, error) {
+	ts, err := stub.GetTxTimestamp(10)
+	if err != nil {
+		return 0, err
+	}
+	return ts.Seconds, nil
+}
+
+func getSchedule(stub shim.ChaincodeStubInterface, id string) (*modules.Schedule, error) {
+	data, err := stub.GetState(modules.SchedulePrefix + id)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	schedule := &modules.Schedule{}
+	if err := json.Unmarshal(data, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+//计划完成或取消后退回剩余的托管资金和手续费，并删除计划
+func closeSchedule(stub shim.ChaincodeStubInterface, schedule *modules.Schedule) error {
+	if schedule.Escrow > 0 {
+		asset, err := modules.StringToAsset(schedule.Asset)
+		if err != nil {
+			return err
+		}
+		err = stub.PayOutToken(schedule.Owner, &modules.AmountAsset{Amount: schedule.Escrow, Asset: asset}, 0)
+		if err != nil {
+			return err
+		}
+		schedule.Escrow = 0
+	}
+	if schedule.FeeEscrow > 0 {
+		gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+		err := stub.PayOutToken(schedule.Owner, modules.NewAmountAsset(schedule.FeeEscrow, gasToken), 0)
+		if err != nil {
+			return err
+		}
+		schedule.FeeEscrow = 0
+	}
+	return stub.DelState(modules.SchedulePrefix + schedule.Id)
+}
+
+func saveSchedule(stub shim.ChaincodeStubInterface, schedule *modules.Schedule) error {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(modules.SchedulePrefix+schedule.Id, data)
+}
+
+//按下一期支付时间排序，保证执行顺序确定
+func getAllSchedules(stub shim.ChaincodeStubInterface) ([]*modules.Schedule, error) {
+	kvs, err := stub.GetStateByPrefix(modules.SchedulePrefix)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*modules.Schedule, 0, len(kvs))
+	for _, kv := range kvs {
+		schedule := &modules.Schedule{}
+		if err := json.Unmarshal(kv.Value, schedule); err != nil {
+			return nil, err
+		}
+		result = append(result, schedule)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NextTime != result[j].NextTime {
+			return result[i].NextTime < result[j].NextTime
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result, nil
+}
+
+//记录所有进行中计划的最早到期时间和最小开始高度，没有进行中的计划时删除
+//GetStateByPrefix读不到本交易的写入，所以由调用者传入更新后的计划列表
+func updateNextDue(stub shim.ChaincodeStubInterface, schedules []*modules.Schedule) error {
+	nextDue := int64(-1)
+	nextHeight := uint64(0)
+	for _, schedule := range schedules {
+		if !schedule.IsActive() {
+			continue
+		}
+		if nextDue < 0 || schedule.StartHeight < nextHeight {
+			nextHeight = schedule.StartHeight
+		}
+		if nextDue < 0 || schedule.NextTime < nextDue {
+			nextDue = schedule.NextTime
+		}
+	}
+	if nextDue < 0 {
+		if err := stub.DelState(modules.ScheduleNextDueHeightKey); err != nil {
+			return err
+		}
+		return stub.DelState(modules.ScheduleNextDueKey)
+	}
+	err := stub.PutState(modules.ScheduleNextDueHeightKey, []byte(strconv.FormatUint(nextHeight, 10)))
+	if err != nil {
+		return err
+	}
+	return stub.PutState(modules.ScheduleNextDueKey, []byte(strconv.FormatInt(nextDue, 10)))
+}
@@ -0,0 +1,199 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package schedulecc
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/shim/shimtest"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStub(mockCtrl *gomock.Controller) *shimtest.Stub {
+	stub := shimtest.NewStub(mockCtrl, syscontract.ScheduledPaymentContractAddress)
+	stub.Gp.ChainParameters.TransferPtnBaseFee = 1
+	return stub
+}
+
+func nextDue(stub *shimtest.Stub) string {
+	return string(stub.DB[modules.ScheduleNextDueKey])
+}
+
+func nextDueHeight(stub *shimtest.Stub) string {
+	return string(stub.DB[modules.ScheduleNextDueHeightKey])
+}
+
+func scheduleCount(stub *shimtest.Stub) int {
+	count := 0
+	for key := range stub.DB {
+		if strings.HasPrefix(key, modules.SchedulePrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestScheduledPayment_Recurring(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	stub := newTestStub(mockCtrl)
+	cc := &ScheduledPaymentChainCode{}
+	alice, bob, carol := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3)
+	stub.Gp.ActiveMediators[carol] = true
+	start := stub.Now.Add(time.Hour).Unix()
+
+	//托管的是PTN时，扣除3期手续费后的金额不能被期数整除
+	stub.TxID = "tx1"
+	stub.Escrow(100, modules.NewPTNAsset())
+	rsp := stub.Invoke(cc, alice, modules.CreateSchedule, bob.String(), strconv.FormatInt(start, 10), "3600", "3")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	stub.Escrow(93, modules.NewPTNAsset())
+	rsp = stub.Invoke(cc, alice, modules.CreateSchedule, bob.String(), strconv.FormatInt(start, 10), "3600", "3")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	schedule := &modules.Schedule{}
+	assert.Nil(t, json.Unmarshal(rsp.Payload, schedule))
+	assert.Equal(t, uint64(30), schedule.Amount)
+	assert.Equal(t, uint64(1), schedule.Fee)
+	assert.Equal(t, uint64(3), schedule.FeeEscrow)
+	assert.Equal(t, strconv.FormatInt(start, 10), nextDue(stub))
+
+	//还未到期
+	rsp = stub.Invoke(cc, carol, modules.ExecuteDueSchedules)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	//只有活跃mediator可以触发执行，手续费支付给触发执行的mediator
+	stub.Now = stub.Now.Add(time.Hour)
+	rsp = stub.Invoke(cc, bob, modules.ExecuteDueSchedules)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	assert.Equal(t, uint64(0), stub.Paid[bob.String()])
+	rsp = stub.Invoke(cc, carol, modules.ExecuteDueSchedules)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(30), stub.Paid[bob.String()])
+	assert.Equal(t, uint64(1), stub.Paid[carol.String()])
+	assert.Equal(t, strconv.FormatInt(start+3600, 10), nextDue(stub))
+
+	//错过的两期一起支付，只收一次手续费，计划完成后退回剩余手续费并删除计划
+	stub.Now = stub.Now.Add(5 * time.Hour)
+	rsp = stub.Invoke(cc, carol, modules.ExecuteDueSchedules)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(90), stub.Paid[bob.String()])
+	assert.Equal(t, uint64(2), stub.Paid[carol.String()])
+	assert.Equal(t, uint64(1), stub.Paid[alice.String()])
+	assert.Equal(t, "", nextDue(stub))
+	assert.Equal(t, 0, scheduleCount(stub))
+
+	rsp = stub.Invoke(cc, alice, modules.GetSchedule, "tx1")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+}
+
+func TestScheduledPayment_Cancel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	stub := newTestStub(mockCtrl)
+	cc := &ScheduledPaymentChainCode{}
+	alice, bob, carol, dave := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3),
+		shimtest.NewAddress(4)
+	stub.Gp.ActiveMediators[dave] = true
+	btc, _ := modules.StringToAsset("BTC")
+	contractAddr := syscontract.ScheduledPaymentContractAddress.String()
+
+	//托管的不是PTN时，必须另外转入正好4期的手续费
+	stub.TxID = "tx1"
+	stub.Escrow(40, btc)
+	rsp := stub.Invoke(cc, alice, modules.CreateSchedule, bob.String(), "0", "60", "4")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	stub.Tokens = append(stub.Tokens, &modules.InvokeTokens{Amount: 4, Asset: modules.NewPTNAsset(),
+		Address: contractAddr})
+	rsp = stub.Invoke(cc, alice, modules.CreateSchedule, bob.String(), "0", "60", "4")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+
+	//高度达到100后才开始支付
+	stub.TxID = "tx2"
+	stub.Escrow(6, modules.NewPTNAsset())
+	rsp = stub.Invoke(cc, carol, modules.CreateSchedule, alice.String(), "0", "0", "1", "100")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, "0", nextDueHeight(stub))
+
+	//开始时间已过，立即支付第一期
+	rsp = stub.Invoke(cc, dave, modules.ExecuteDueSchedules)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(10), stub.Paid[bob.String()])
+	assert.Equal(t, uint64(0), stub.Paid[alice.String()])
+	assert.Equal(t, uint64(1), stub.Paid[dave.String()])
+
+
+	stub.Height = 100
+	rsp = stub.Invoke(cc, dave, modules.ExecuteDueSchedules)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(5), stub.Paid[alice.String()])
+	assert.Equal(t, uint64(2), stub.Paid[dave.String()])
+	assert.Equal(t, 1, scheduleCount(stub))
+
+	//只有付款人可以取消，取消时退回剩余的托管资金和手续费
+	rsp = stub.Invoke(cc, bob, modules.CancelSchedule, "tx1")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = stub.Invoke(cc, alice, modules.CancelSchedule, "tx1")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(5+30+3), stub.Paid[alice.String()])
+	assert.Equal(t, "", nextDue(stub))
+	rsp = stub.Invoke(cc, alice, modules.CancelSchedule, "tx1")
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	rsp = stub.Invoke(cc, bob, modules.ListSchedules)
+	result := []*modules.Schedule{}
+	assert.Nil(t, json.Unmarshal(rsp.Payload, &result))
+	assert.Equal(t, 0, len(result))
+}
+
+func TestScheduledPayment_StartHeight(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	stub := newTestStub(mockCtrl)
+	cc := &ScheduledPaymentChainCode{}
+	alice, bob, carol := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3)
+	stub.Gp.ActiveMediators[carol] = true
+
+	//开始时间已过，但高度达到100后才开始支付，mediator据此跳过检查
+	stub.TxID = "tx1"
+	stub.Escrow(11, modules.NewPTNAsset())
+	rsp := stub.Invoke(cc, alice, modules.CreateSchedule, bob.String(), "0", "0", "1", "100")
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, strconv.FormatInt(stub.Now.Unix(), 10), nextDue(stub))
+	assert.Equal(t, "100", nextDueHeight(stub))
+
+	stub.Height = 90
+	rsp = stub.Invoke(cc, carol, modules.ExecuteDueSchedules)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	stub.Height = 100
+	rsp = stub.Invoke(cc, carol, modules.ExecuteDueSchedules)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	assert.Equal(t, uint64(10), stub.Paid[bob.String()])
+	assert.Equal(t, "", nextDue(stub))
+	assert.Equal(t, "", nextDueHeight(stub))
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/syscontract/foundationcc"
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -185,14 +186,18 @@ func (s *SysConfigChainCode) createVotesTokens(stub shim.ChaincodeStubInterface,
 		return nil, fmt.Errorf(jsonResp)
 	}
 
+	//需要基金会委员会多签通过
+	proposal, approved, err := foundationcc.Approve(stub)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return json.Marshal(proposal)
+	}
 	gp, err := stub.GetSystemConfig()
 	if err != nil {
 		return nil, fmt.Errorf("fail to get system config err")
 	}
-	if createAddr.Str() != gp.ChainParameters.FoundationAddress {
-		jsonResp := "{\"Error\":\"Only foundation can call this function\"}"
-		return nil, fmt.Errorf(jsonResp)
-	}
 
 	//==== convert params to token information
 	var vt modules.VoteToken
@@ -490,15 +495,13 @@ func (s *SysConfigChainCode) updateSysParamWithoutVote(stub shim.ChaincodeStubIn
 		return nil, err
 	}
 
-	createAddr, err := stub.GetInvokeAddress()
+	//需要基金会委员会多签通过
+	proposal, approved, err := foundationcc.Approve(stub)
 	if err != nil {
-		jsonResp := "{\"Error\":\"Failed to get invoke address\"}"
-		return nil, fmt.Errorf(jsonResp)
+		return nil, err
 	}
-
-	if createAddr.Str() != gp.ChainParameters.FoundationAddress {
-		jsonResp := "{\"Error\":\"Only foundation can call this function\"}"
-		return nil, fmt.Errorf(jsonResp)
+	if !approved {
+		return json.Marshal(proposal)
 	}
 
 	resultBytes, err := stub.GetState(modules.DesiredSysParamsWithoutVote)
@@ -0,0 +1,508 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package vote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/palletone/go-palletone/contracts/shim"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	dm "github.com/palletone/go-palletone/dag/modules"
+)
+
+// 加权投票主题（DAO 风格）：
+// 1. createTopic 创建投票，可指定权重资产和快照单元的高度，不指定权重资产则一地址一票；
+// 2. 票权为投票人在快照单元时持有的权重资产数量，快照单元稳定之后才能查询；
+// 3. 快照之后至投票结束，投票人可以 vote 直接投票，或 delegate 将票权委托给其他地址；
+// 4. 每个投票人的回执可通过 getVoteReceipt 查询。
+const topicPrefix = "topic_"
+const receiptPrefix = "receipt_"
+const timeLayout = "2006-01-02 15:04:05"
+
+//one weighted topic
+type WeightedTopic struct {
+	TopicTitle    string
+	SelectOptions []string
+	SelectMax     uint64
+	//排序投票，SelectIndexs 的顺序即为投票人的排名，第 i 名得分为 weight*(SelectMax-i)
+	Ranked bool
+	Scores []uint64 `json:",omitempty"`
+}
+
+//weighted vote information
+type TopicInfo struct {
+	TopicID        string
+	Name           string
+	CreateAddr     string
+	WeightAsset    string //为空则一地址一票
+	SnapshotHeight uint64 //按该高度的单元统计持有的权重资产
+	VoteEndTime    time.Time
+	Topics         []WeightedTopic
+}
+
+//one voter's receipt of a weighted vote
+type VoteReceipt struct {
+	Address         string
+	Weight          uint64 //快照单元时持有的权重资产数量
+	DelegatedWeight uint64 //其他地址委托过来的票权
+	Delegate        string //票权委托的地址
+	Voted           bool
+	Supports        []SupportRequest
+	VoteTime        int64
+}
+
+var errWeightOverflow = errors.New("vote weight overflow")
+
+//票权来自持币量，可能很大，累加和乘法都要检查溢出
+func addWeight(a, b uint64) (uint64, error) {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return 0, errWeightOverflow
+	}
+	return sum, nil
+}
+
+func mulWeight(a, b uint64) (uint64, error) {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return 0, errWeightOverflow
+	}
+	return lo, nil
+}
+
+func (r *VoteReceipt) totalWeight() (uint64, error) {
+	return addWeight(r.Weight, r.DelegatedWeight)
+}
+
+func (t *TopicInfo) isWeighted() bool {
+	return t.WeightAsset != ""
+}
+
+func getTopic(stub shim.ChaincodeStubInterface, topicID string) (*TopicInfo, error) {
+	val, err := stub.GetState(topicPrefix + topicID)
+	if err != nil || len(val) == 0 {
+		return nil, fmt.Errorf("topic %s not exist", topicID)
+	}
+	topic := &TopicInfo{}
+	err = json.Unmarshal(val, topic)
+	if err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+func saveTopic(stub shim.ChaincodeStubInterface, topic *TopicInfo) error {
+	val, err := json.Marshal(topic)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(topicPrefix+topic.TopicID, val)
+}
+
+//查询地址在快照单元时持有的权重资产，快照之后持有量不再变化，所以任何时候查询结果都相同
+func getSnapshotWeight(stub shim.ChaincodeStubInterface, topic *TopicInfo, addr string) (uint64, error) {
+	asset, err := dm.StringToAsset(topic.WeightAsset)
+	if err != nil {
+		return 0, err
+	}
+	tokens, err := stub.GetTokenBalanceAt(addr, asset, topic.SnapshotHeight)
+	if err != nil {
+		return 0, fmt.Errorf("get balance at snapshot height %d failed:%s", topic.SnapshotHeight, err.Error())
+	}
+	weight := uint64(0)
+	for _, token := range tokens {
+		if token.Asset != nil && token.Asset.String() == topic.WeightAsset {
+			if weight, err = addWeight(weight, token.Amount); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return weight, nil
+}
+
+//还没有投票或委托过的地址返回一个新回执，票权为快照时的持有量
+func getReceipt(stub shim.ChaincodeStubInterface, topic *TopicInfo, addr string) (*VoteReceipt, error) {
+	val, _ := stub.GetState(receiptPrefix + topic.TopicID + "_" + addr)
+	if len(val) == 0 {
+		receipt := &VoteReceipt{Address: addr, Weight: 1}
+		if topic.isWeighted() {
+			weight, err := getSnapshotWeight(stub, topic, addr)
+			if err != nil {
+				return nil, err
+			}
+			receipt.Weight = weight
+		}
+		return receipt, nil
+	}
+	receipt := &VoteReceipt{}
+	err := json.Unmarshal(val, receipt)
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func saveReceipt(stub shim.ChaincodeStubInterface, topic *TopicInfo, receipt *VoteReceipt) error {
+	val, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(receiptPrefix+topic.TopicID+"_"+receipt.Address, val)
+}
+
+func getNowSeconds(stub shim.ChaincodeStubInterface) (int64, error) {
+	headerTime, err := stub.GetTxTimestamp(10)
+	if err != nil {
+		return 0, err
+	}
+	return headerTime.Seconds, nil
+}
+
+//检查当前是否处于投票阶段：快照之后，结束之前
+func checkVotePeriod(stub shim.ChaincodeStubInterface, topic *TopicInfo) (int64, error) {
+	now, err := getNowSeconds(stub)
+	if err != nil {
+		return 0, err
+	}
+	if now > topic.VoteEndTime.Unix() {
+		return 0, fmt.Errorf("vote is over")
+	}
+	if topic.isWeighted() {
+		height, err := stub.GetTxHeight(10)
+		if err != nil {
+			return 0, err
+		}
+		if height <= topic.SnapshotHeight {
+			return 0, fmt.Errorf("vote not started, snapshot height is %d", topic.SnapshotHeight)
+		}
+	}
+	return now, nil
+}
+
+func createTopic(args []string, stub shim.ChaincodeStubInterface) pb.Response {
+	if len(args) < 5 {
+		return shim.Error("need 5 args (Name,WeightAsset,SnapshotHeight,VoteEndTime,TopicsJson)")
+	}
+	if len(args[0]) > 1024 {
+		return shim.Error("Name length should not be greater than 1024")
+	}
+	topic := &TopicInfo{TopicID: stub.GetTxID(), Name: args[0]}
+	if args[1] != "" {
+		asset, err := dm.StringToAsset(args[1])
+		if err != nil {
+			return shim.Error("WeightAsset invalid:" + err.Error())
+		}
+		topic.WeightAsset = asset.String()
+	}
+	voteEndTime, err := time.Parse(timeLayout, args[3])
+	if err != nil {
+		return shim.Error("VoteEndTime invalid:" + err.Error())
+	}
+	topic.VoteEndTime = voteEndTime
+	if topic.isWeighted() {
+		snapshotHeight, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil || snapshotHeight == 0 {
+			return shim.Error("SnapshotHeight invalid:" + args[2])
+		}
+		topic.SnapshotHeight = snapshotHeight
+	}
+
+	var topics []WeightedTopic
+	err = json.Unmarshal([]byte(args[4]), &topics)
+	if err != nil || len(topics) == 0 {
+		return shim.Error("TopicsJson format invalid")
+	}
+	for i := range topics {
+		if topics[i].SelectMax == 0 || topics[i].SelectMax > uint64(len(topics[i].SelectOptions)) {
+			return shim.Error(fmt.Sprintf("topic %d 's SelectMax invalid", i+1))
+		}
+		topics[i].Scores = make([]uint64, len(topics[i].SelectOptions))
+	}
+	topic.Topics = topics
+
+	if old, _ := getTopic(stub, topic.TopicID); old != nil {
+		return shim.Error("Repeat topic")
+	}
+	createAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return shim.Error("Failed to get invoke address")
+	}
+	topic.CreateAddr = createAddr.String()
+	err = saveTopic(stub, topic)
+	if err != nil {
+		return shim.Error("Failed to save topic:" + err.Error())
+	}
+	return shim.Success([]byte(topic.TopicID))
+}
+
+//校验投票请求，并按 weight 计入各选项得分
+func applySupports(topic *TopicInfo, supports []SupportRequest, weight uint64) error {
+	indexHistory := make(map[uint64]bool)
+	for _, s := range supports {
+		if s.TopicIndex == 0 || s.TopicIndex > uint64(len(topic.Topics)) {
+			return fmt.Errorf("topic index %d out of range", s.TopicIndex)
+		}
+		if indexHistory[s.TopicIndex] {
+			return fmt.Errorf("repeat topic index %d", s.TopicIndex)
+		}
+		indexHistory[s.TopicIndex] = true
+		t := &topic.Topics[s.TopicIndex-1]
+		if len(s.SelectIndexs) == 0 || uint64(len(s.SelectIndexs)) > t.SelectMax {
+			return fmt.Errorf("topic %d select count must between 1 and %d", s.TopicIndex, t.SelectMax)
+		}
+		selHistory := make(map[uint64]bool)
+		for _, sel := range s.SelectIndexs {
+			if sel == 0 || sel > uint64(len(t.SelectOptions)) {
+				return fmt.Errorf("topic %d select index %d out of range", s.TopicIndex, sel)
+			}
+			if selHistory[sel] {
+				return fmt.Errorf("topic %d repeat select index %d", s.TopicIndex, sel)
+			}
+			selHistory[sel] = true
+		}
+	}
+	for _, s := range supports {
+		t := &topic.Topics[s.TopicIndex-1]
+		for rank, sel := range s.SelectIndexs {
+			score := weight
+			if t.Ranked {
+				var err error
+				if score, err = mulWeight(weight, t.SelectMax-uint64(rank)); err != nil {
+					return err
+				}
+			}
+			total, err := addWeight(t.Scores[sel-1], score)
+			if err != nil {
+				return err
+			}
+			t.Scores[sel-1] = total
+		}
+	}
+	return nil
+}
+
+func voteTopic(args []string, stub shim.ChaincodeStubInterface) pb.Response {
+	if len(args) < 2 {
+		return shim.Error("need 2 args (TopicID,SupportRequestJson)")
+	}
+	topic, err := getTopic(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	now, err := checkVotePeriod(stub, topic)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	var supports []SupportRequest
+	err = json.Unmarshal([]byte(args[1]), &supports)
+	if err != nil || len(supports) == 0 {
+		return shim.Error("SupportRequestJson format invalid")
+	}
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return shim.Error("Failed to get invoke address")
+	}
+	receipt, err := getReceipt(stub, topic, invokeAddr.String())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if receipt.Voted {
+		return shim.Error("already voted")
+	}
+	if receipt.Delegate != "" {
+		return shim.Error("already delegated to " + receipt.Delegate)
+	}
+	weight, err := receipt.totalWeight()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if weight == 0 {
+		return shim.Error("no vote weight, hold " + topic.WeightAsset + " at the snapshot unit to vote")
+	}
+	err = applySupports(topic, supports, weight)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	receipt.Voted = true
+	receipt.Supports = supports
+	receipt.VoteTime = now
+	err = saveReceipt(stub, topic, receipt)
+	if err != nil {
+		return shim.Error("Failed to save receipt:" + err.Error())
+	}
+	err = saveTopic(stub, topic)
+	if err != nil {
+		return shim.Error("Failed to save topic:" + err.Error())
+	}
+	return shim.Success(nil)
+}
+
+//将票权委托给另一个地址，如果被委托人已经投票，则票权直接计入其选择
+func delegateVote(args []string, stub shim.ChaincodeStubInterface) pb.Response {
+	if len(args) < 2 {
+		return shim.Error("need 2 args (TopicID,DelegateAddress)")
+	}
+	topic, err := getTopic(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	now, err := checkVotePeriod(stub, topic)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	invokeAddr, err := stub.GetInvokeAddress()
+	if err != nil {
+		return shim.Error("Failed to get invoke address")
+	}
+	from, err := getReceipt(stub, topic, invokeAddr.String())
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if from.Voted || from.Delegate != "" {
+		return shim.Error("already voted or delegated")
+	}
+	weight, err := from.totalWeight()
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if weight == 0 {
+		return shim.Error("no vote weight, hold " + topic.WeightAsset + " at the snapshot unit to delegate")
+	}
+
+	//沿委托链找到最终的被委托人
+	to, err := getReceipt(stub, topic, args[1])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	for to.Delegate != "" {
+		if to.Delegate == from.Address {
+			return shim.Error("found loop in delegation")
+		}
+		to, err = getReceipt(stub, topic, to.Delegate)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	if to.Address == from.Address {
+		return shim.Error("self-delegation is disallowed")
+	}
+
+	delegated, err := addWeight(to.DelegatedWeight, weight)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if to.Voted {
+		err = applySupports(topic, to.Supports, weight)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		err = saveTopic(stub, topic)
+		if err != nil {
+			return shim.Error("Failed to save topic:" + err.Error())
+		}
+	}
+	to.DelegatedWeight = delegated
+	from.Delegate = to.Address
+	from.VoteTime = now
+	if err = saveReceipt(stub, topic, to); err != nil {
+		return shim.Error("Failed to save receipt:" + err.Error())
+	}
+	if err = saveReceipt(stub, topic, from); err != nil {
+		return shim.Error("Failed to save receipt:" + err.Error())
+	}
+	return shim.Success([]byte(to.Address))
+}
+
+type WeightedResult struct {
+	SelectOption string
+	Score        uint64
+}
+type WeightedTopicResult struct {
+	TopicIndex  uint64
+	TopicTitle  string
+	Ranked      bool
+	VoteResults []WeightedResult
+}
+type TopicResult struct {
+	TopicID        string
+	Name           string
+	IsVoteEnd      bool
+	CreateAddr     string
+	WeightAsset    string
+	SnapshotHeight uint64
+	VoteEndTime    string
+	Results        []WeightedTopicResult
+}
+
+func getTopicResult(args []string, stub shim.ChaincodeStubInterface) pb.Response {
+	if len(args) < 1 {
+		return shim.Error("need 1 args (TopicID)")
+	}
+	topic, err := getTopic(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	now, err := getNowSeconds(stub)
+	if err != nil {
+		return shim.Error("GetTxTimestamp invalid:" + err.Error())
+	}
+	result := TopicResult{TopicID: topic.TopicID, Name: topic.Name, IsVoteEnd: now > topic.VoteEndTime.Unix(),
+		CreateAddr: topic.CreateAddr, WeightAsset: topic.WeightAsset, SnapshotHeight: topic.SnapshotHeight,
+		VoteEndTime: topic.VoteEndTime.String()}
+	for i, t := range topic.Topics {
+		one := WeightedTopicResult{TopicIndex: uint64(i) + 1, TopicTitle: t.TopicTitle, Ranked: t.Ranked}
+		for j, option := range t.SelectOptions {
+			one.VoteResults = append(one.VoteResults, WeightedResult{SelectOption: option, Score: t.Scores[j]})
+		}
+		sort.SliceStable(one.VoteResults, func(a, b int) bool {
+			return one.VoteResults[a].Score > one.VoteResults[b].Score
+		})
+		result.Results = append(result.Results, one)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(data)
+}
+
+func getVoteReceipt(args []string, stub shim.ChaincodeStubInterface) pb.Response {
+	if len(args) < 2 {
+		return shim.Error("need 2 args (TopicID,Address)")
+	}
+	topic, err := getTopic(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	receipt, err := getReceipt(stub, topic, args[1])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(data)
+}
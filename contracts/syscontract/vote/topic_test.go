@@ -0,0 +1,155 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package vote
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/contracts/shim/shimtest"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedVoteAndDelegate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	env := shimtest.NewStub(mockCtrl, syscontract.VoteTokenContractAddress)
+	alice, bob, carol, dave := shimtest.NewAddress(1), shimtest.NewAddress(2), shimtest.NewAddress(3),
+		shimtest.NewAddress(4)
+	env.Holdings[20] = map[string]uint64{alice.String(): 100, bob.String(): 30, carol.String(): 5}
+	//holdings after the snapshot unit are not counted
+	env.Holdings[25] = map[string]uint64{dave.String(): 50}
+
+	topics := []WeightedTopic{
+		{TopicTitle: "color", SelectOptions: []string{"red", "blue"}, SelectMax: 1},
+		{TopicTitle: "rank", SelectOptions: []string{"a", "b", "c"}, SelectMax: 3, Ranked: true},
+	}
+	topicsJson, _ := json.Marshal(topics)
+	env.Now = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	env.Height = 10
+	env.Invoker = alice
+	env.TxID = "0x1234"
+	rsp := createTopic([]string{"dao", "PTN", "0", "2019-01-03 00:00:00", string(topicsJson)}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = createTopic([]string{"dao", "PTN", "20", "2019-01-03 00:00:00", string(topicsJson)}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	topicID := string(rsp.Payload)
+
+	//vote before the snapshot unit is not allowed
+	env.Height = 20
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[1]}]`}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	env.Height = 30
+	env.Invoker = carol
+	rsp = delegateVote([]string{topicID, bob.String()}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+
+	env.Invoker = alice
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[1]},{"TopicIndex":2,"SelectIndexs":[3,1]}]`},
+		env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[1]}]`}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	env.Invoker = bob
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[2]}]`}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	//no holdings at the snapshot unit, no weight to delegate or vote
+	env.Invoker = dave
+	rsp = delegateVote([]string{topicID, bob.String()}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[2]}]`}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	topic, err := getTopic(env, topicID)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{100, 35}, topic.Topics[0].Scores)
+	assert.Equal(t, []uint64{200, 0, 300}, topic.Topics[1].Scores)
+
+	rsp = getVoteReceipt([]string{topicID, carol.String()}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	receipt := &VoteReceipt{}
+	assert.Nil(t, json.Unmarshal(rsp.Payload, receipt))
+	assert.Equal(t, bob.String(), receipt.Delegate)
+	assert.Equal(t, uint64(5), receipt.Weight)
+
+	rsp = getTopicResult([]string{topicID}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	t.Log(string(rsp.Payload))
+}
+
+func TestWeightOverflow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	env := shimtest.NewStub(mockCtrl, syscontract.VoteTokenContractAddress)
+	alice, bob := shimtest.NewAddress(1), shimtest.NewAddress(2)
+	env.Holdings[5] = map[string]uint64{alice.String(): math.MaxUint64 / 2, bob.String(): math.MaxUint64/2 + 2}
+	env.Height = 10
+	env.TxID = "0x1234"
+	rsp := createTopic([]string{"dao", "PTN", "5", "2019-01-03 00:00:00",
+		`[{"TopicTitle":"t","SelectOptions":["x","y"],"SelectMax":2,"Ranked":true}]`}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	topicID := string(rsp.Payload)
+
+	//the first ranked choice scores weight*2
+	env.Invoker = alice
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[1,2]}]`}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	env.Invoker = bob
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[2]}]`}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = delegateVote([]string{topicID, alice.String()}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+
+	topic, err := getTopic(env, topicID)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{math.MaxUint64 - 1, math.MaxUint64 / 2}, topic.Topics[0].Scores)
+}
+
+func TestDelegateLoop(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	env := shimtest.NewStub(mockCtrl, syscontract.VoteTokenContractAddress)
+	alice, bob := shimtest.NewAddress(1), shimtest.NewAddress(2)
+	env.Now = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	env.TxID = "0x1234"
+	rsp := createTopic([]string{"poll", "", "", "2019-01-03 00:00:00",
+		`[{"TopicTitle":"t","SelectOptions":["x","y"],"SelectMax":1}]`}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	topicID := string(rsp.Payload)
+
+	env.Invoker = alice
+	rsp = delegateVote([]string{topicID, bob.String()}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+	env.Invoker = bob
+	rsp = delegateVote([]string{topicID, alice.String()}, env)
+	assert.NotEqual(t, int32(shim.OK), rsp.Status)
+	rsp = voteTopic([]string{topicID, `[{"TopicIndex":1,"SelectIndexs":[2]}]`}, env)
+	assert.Equal(t, int32(shim.OK), rsp.Status, rsp.Message)
+
+	topic, err := getTopic(env, topicID)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{0, 2}, topic.Topics[0].Scores)
+}
@@ -96,6 +96,16 @@ func (v *Vote) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return getVoteResult(args, stub)
 	case "getVoteInfo":
 		return getVoteInfo(args, stub)
+	case "createTopic":
+		return createTopic(args, stub)
+	case "vote":
+		return voteTopic(args, stub)
+	case "delegate":
+		return delegateVote(args, stub)
+	case "getTopicResult":
+		return getTopicResult(args, stub)
+	case "getVoteReceipt":
+		return getVoteReceipt(args, stub)
 	default:
 		jsonResp := "{\"Error\":\"Unknown function " + f + "\"}"
 		return shim.Error(jsonResp)
@@ -212,7 +212,7 @@ func Invoke(rwM rwset.TxManager, idag dag.IDag, chainID string, deployId []byte,
 		log.Errorf("signedEndorserProposa error[%v]", err)
 		return nil, err
 	}
-	rsp, unit, err := es.ProcessProposal(rwM, idag, deployId, context.Background(), sprop, prop, chainID, cid, setTimeOut)
+	rsp, unit, err := es.ProcessProposal(rwM, idag, deployId, context.Background(), sprop, prop, chainID, cid, setTimeOut, 0)
 	if err != nil {
 		log.Infof("ProcessProposal error[%v]", err)
 		return nil, err
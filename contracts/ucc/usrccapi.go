@@ -82,6 +82,14 @@ func DeployUserCC(contractId []byte, chaincodeData []byte, spec *pb.ChaincodeSpe
 	return nil
 }
 
+//LaunchUserCC 启动已经部署的用户合约，合约已经运行时什么也不做
+func LaunchUserCC(contractId []byte, chaincodeData []byte, spec *pb.ChaincodeSpec, chainID string, txid string) error {
+	cdDeploymentSpec := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: chaincodeData}
+	ccprov := ccprovider.GetChaincodeProvider()
+	cccid := ccprov.GetCCContext(contractId, chainID, spec.ChaincodeId.Name, spec.ChaincodeId.Version, txid, false, nil, nil)
+	return ccprov.Launch(context.Background(), cccid, cdDeploymentSpec)
+}
+
 func StopUserCC(contractid []byte, chainID string, usrcc *UserChaincode, txid string, deleteImage bool, dontRmCon bool) error {
 	ccprov := ccprovider.GetChaincodeProvider()
 	chaincodeID := &pb.ChaincodeID{Path: usrcc.Path, Name: usrcc.Name, Version: usrcc.Version}
@@ -18,6 +18,7 @@ package keystore
 
 import (
 	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/palletone/go-palletone/common"
@@ -59,8 +60,31 @@ func TestKeyEncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestDataEncryptDecrypt(t *testing.T) {
+	data := []byte("outchain private keys")
+	cryptoJson, err := EncryptData(data, "1", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptData(cryptoJson, "bad"); err != ErrDecrypt {
+		t.Errorf("data decrypted with bad password, err: %v", err)
+	}
+	plain, err := DecryptData(cryptoJson, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != string(data) {
+		t.Errorf("data mismatch: have %s, want %s", plain, data)
+	}
+}
+
 func TestStoreKey(t *testing.T) {
-	dir := "./"
+	dir, err := ioutil.TempDir("", "keystore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
 	auth := "123456"
 	add, err := StoreKey(dir, auth, 2, 2)
 	t.Log("Address: " + add.Str())
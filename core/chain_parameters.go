@@ -22,10 +22,14 @@ package core
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 )
 
+// 激活高度类的参数取该值时，表示对应功能还未激活
+const NeverActivated = math.MaxUint64
+
 type ImmutableChainParameters struct {
 	MinMaintSkipSlots    uint8    `json:"min_maint_skip_slots"`  // 最小区块链维护间隔
 	MinimumMediatorCount uint8    `json:"min_mediator_count"`    // 最小活跃mediator数量
@@ -70,6 +74,21 @@ func NewChainParametersBase() ChainParametersBase {
 		TransferPtnPricePerKByte:  DefaultTransferPtnPricePerKByte,
 		// ContractInvokeFee:         DefaultContractInvokeFee,
 		UnitMaxSize: DefaultUnitMaxSize,
+
+		MaxConsecutiveMissedSlots: DefaultMaxConsecutiveMissedSlots,
+		GroupSignScheme:           DefaultGroupSignScheme,
+		PolicyScriptHeight:        DefaultPolicyScriptHeight,
+		UtxoCommitmentHeight:      DefaultUtxoCommitmentHeight,
+		StateCommitmentHeight:     DefaultStateCommitmentHeight,
+
+		ContractCallCost:        DefaultContractCallCost,
+		ContractReadByteCost:    DefaultContractReadByteCost,
+		ContractWriteByteCost:   DefaultContractWriteByteCost,
+		ContractPayoutCost:      DefaultContractPayoutCost,
+		ContractPayloadByteCost: DefaultContractPayloadByteCost,
+		ContractCostLimit:       DefaultContractCostLimit,
+		ContractCostUnitFee:     DefaultContractCostUnitFee,
+		ContractInstructionCost: DefaultContractInstructionCost,
 	}
 }
 
@@ -108,6 +127,41 @@ type ChainParametersBase struct {
 	TransferPtnBaseFee       uint64 `json:"transfer_ptn_base_fee"`
 	TransferPtnPricePerKByte uint64 `json:"transfer_ptn_price_per_KByte"`
 	// ContractInvokeFee        uint64 `json:"contract_invoke_fee"`
+
+	// mediator连续错过生产slot的数量达到该值，则在下次维护时被移出活跃mediator集合，为0时不停用
+	MaxConsecutiveMissedSlots uint32 `json:"max_consecutive_missed_slots"`
+
+	// mediator群签名使用的方案，mediator的初始公钥依赖于该方案，创世后不能修改
+	GroupSignScheme string `json:"group_sign_scheme"`
+
+	// 从该高度的unit开始启用OP_CHECK_POLICY，之前该操作码无效
+	PolicyScriptHeight uint64 `json:"policy_script_height"`
+
+	// 从该高度的unit开始，单元头中包含UTXO集合的承诺
+	UtxoCommitmentHeight uint64 `json:"utxo_commitment_height"`
+
+	// 从该高度的unit开始，单元头中包含合约状态的承诺
+	StateCommitmentHeight uint64 `json:"state_commitment_height"`
+
+	// 用户合约执行的计价，与硬件无关，各个陪审员执行同一请求得到的cost相同
+	ContractCallCost        uint64 `json:"contract_call_cost"`         //每次通过shim访问账本
+	ContractReadByteCost    uint64 `json:"contract_read_byte_cost"`    //读取的状态，按key+value的字节数
+	ContractWriteByteCost   uint64 `json:"contract_write_byte_cost"`   //写入的状态，按key+value的字节数
+	ContractPayoutCost      uint64 `json:"contract_payout_cost"`       //每次支付token
+	ContractPayloadByteCost uint64 `json:"contract_payload_byte_cost"` //调用参数和返回结果的字节数
+	ContractCostLimit       uint64 `json:"contract_cost_limit"`        //请求未指定时的cost上限
+	ContractCostUnitFee     uint64 `json:"contract_cost_unit_fee"`     //每个cost需要的手续费(dao)
+	ContractInstructionCost uint64 `json:"contract_instruction_cost"`  //wasm合约每执行一条指令
+}
+
+// 该高度的单元头是否需要包含UTXO集合的承诺
+func (cp *ChainParametersBase) IsUtxoCommitmentEnabled(height uint64) bool {
+	return height >= cp.UtxoCommitmentHeight
+}
+
+// 该高度的单元头是否需要包含合约状态的承诺
+func (cp *ChainParametersBase) IsStateCommitmentEnabled(height uint64) bool {
+	return height >= cp.StateCommitmentHeight
 }
 
 func NewChainParams() ChainParameters {
@@ -244,9 +298,36 @@ func CheckChainParameterValue(field, value string, icp *ImmutableChainParameters
 				newMaintenanceInterval, cp.MediatorInterval)
 		}
 
+	case "GroupSignScheme":
+		if value != cp.GroupSignScheme {
+			err = fmt.Errorf("GroupSignScheme(%v) cannot be changed after genesis", cp.GroupSignScheme)
+		}
+	case "PolicyScriptHeight":
+		err = checkActivationHeight(field, value, cp.PolicyScriptHeight)
+	case "UtxoCommitmentHeight":
+		err = checkActivationHeight(field, value, cp.UtxoCommitmentHeight)
+	case "StateCommitmentHeight":
+		err = checkActivationHeight(field, value, cp.StateCommitmentHeight)
+	case "ContractCostLimit":
+		// 上限为0时用户合约不再计价
+		if newLimit, _ := strconv.ParseUint(value, 10, 64); newLimit == 0 {
+			err = fmt.Errorf("new ContractCostLimit(%v) must be larger than 0", newLimit)
+		}
+
 	default:
 		err = nil
 	}
 
 	return err
 }
+
+// 功能的激活高度只能从未激活(NeverActivated)修改为某个高度，设置后不能再修改，避免已验证的unit失效
+func checkActivationHeight(field, value string, current uint64) error {
+	if current == NeverActivated {
+		return nil
+	}
+	if newHeight, _ := strconv.ParseUint(value, 10, 64); newHeight != current {
+		return fmt.Errorf("%v(%v) cannot be changed after it is set", field, current)
+	}
+	return nil
+}
@@ -39,3 +39,40 @@ func Test_ChainParameters_Rlp(t *testing.T) {
 	assert.Equal(t, cp.ContractElectionNum, cp2.ContractElectionNum)
 	assert.Equal(t, cp.UccCpuShares, cp2.UccCpuShares)
 }
+
+func TestCheckChainParameterValue_ActivationHeight(t *testing.T) {
+	icp := NewImmutChainParams()
+	cp := NewChainParams()
+	//已经设置的激活高度不能修改
+	assert.NotNil(t, CheckChainParameterValue("PolicyScriptHeight", "100", &icp, &cp, nil))
+	assert.Nil(t, CheckChainParameterValue("PolicyScriptHeight", "0", &icp, &cp, nil))
+
+	cp.PolicyScriptHeight = NeverActivated
+	assert.Nil(t, CheckChainParameterValue("PolicyScriptHeight", "100", &icp, &cp, nil))
+
+	//UTXO集合的承诺默认未激活，可以设置一次
+	assert.False(t, cp.IsUtxoCommitmentEnabled(100))
+	assert.Nil(t, CheckChainParameterValue("UtxoCommitmentHeight", "100", &icp, &cp, nil))
+	cp.UtxoCommitmentHeight = 100
+	assert.True(t, cp.IsUtxoCommitmentEnabled(100))
+	assert.NotNil(t, CheckChainParameterValue("UtxoCommitmentHeight", "200", &icp, &cp, nil))
+	assert.False(t, cp.IsStateCommitmentEnabled(100))
+	assert.Nil(t, CheckChainParameterValue("StateCommitmentHeight", "100", &icp, &cp, nil))
+}
+
+func TestCheckChainParameterValue_ContractCost(t *testing.T) {
+	icp := NewImmutChainParams()
+	cp := NewChainParams()
+	assert.Nil(t, CheckSysConfigArgType("ContractCallCost", "200"))
+	assert.Nil(t, CheckChainParameterValue("ContractCostLimit", "2000000", &icp, &cp, nil))
+	//上限为0时用户合约不再计价
+	assert.NotNil(t, CheckChainParameterValue("ContractCostLimit", "0", &icp, &cp, nil))
+
+	data, err := rlp.EncodeToBytes(&cp)
+	assert.Nil(t, err)
+	cp2 := &ChainParameters{}
+	assert.Nil(t, rlp.DecodeBytes(data, cp2))
+	assert.Equal(t, cp.ContractCostLimit, cp2.ContractCostLimit)
+	assert.Equal(t, cp.ContractCostUnitFee, cp2.ContractCostUnitFee)
+	assert.Equal(t, cp.ContractInstructionCost, cp2.ContractInstructionCost)
+}
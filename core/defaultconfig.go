@@ -20,6 +20,8 @@
 
 package core
 
+import "github.com/palletone/go-palletone/core/groupsign"
+
 const (
 	DefaultTokenAmount = "100000000000000000"
 	//DefaultTokenDecimal              = 8
@@ -66,12 +68,28 @@ const (
 	DefaultMinMediatorInterval = 1
 	DefaultMinMaintSkipSlots   = 0
 
+	// mediator连续错过生产slot的数量达到该值，则在下次维护时被移出活跃mediator集合
+	DefaultMaxConsecutiveMissedSlots = 100
+
+	// mediator群签名的默认方案
+	DefaultGroupSignScheme = groupsign.DefaultSchemeName
+
+	// 新创建的链从创世开始启用OP_CHECK_POLICY
+	DefaultPolicyScriptHeight = 0
+
+	// UTXO集合和合约状态的承诺默认不启用，通过修改系统参数激活
+	DefaultUtxoCommitmentHeight  = NeverActivated
+	DefaultStateCommitmentHeight = NeverActivated
+
 	//contract
 	DefaultContractSystemVersion = "" //contractId1:v1;contractId2:v2;contractId3:v3
 
 	DefaultContractSignatureNum = 3
 	DefaultContractElectionNum  = 4
 
+	// 陪审员信誉低于该值时，不会被选入陪审团
+	DefaultMinJurorReputation = 30
+
 	DefaultContractTxTimeoutUnitFee  = 10 //s
 	DefaultContractTxSizeUnitFee     = 1  //byte
 	DefaultContractTxInstallFeeLevel = 2.5
@@ -79,6 +97,16 @@ const (
 	DefaultContractTxInvokeFeeLevel  = 1.0
 	DefaultContractTxStopFeeLevel    = 0.5
 
+	// 用户合约执行计价的默认系统参数
+	DefaultContractCallCost        = 100 //每次通过shim访问账本
+	DefaultContractReadByteCost    = 1   //读取的状态，按key+value的字节数
+	DefaultContractWriteByteCost   = 10  //写入的状态，按key+value的字节数
+	DefaultContractPayoutCost      = 1000
+	DefaultContractPayloadByteCost = 1       //调用参数和返回结果的字节数
+	DefaultContractCostLimit       = 1000000 //请求未指定时的cost上限
+	DefaultContractCostUnitFee     = 1       //每个cost需要的手续费(dao)
+	DefaultContractInstructionCost = 1       //wasm合约每执行一条指令
+
 	DefaultText = "姓名 丨 坐标 丨 简介   \r\n" +
 		"孟岩丨北京丨通证派倡导者、CSDN副总裁、柏链道捷CEO.\r\n" +
 		"刘百祥丨上海丨 GoC-lab发起人兼技术社群负责人,复旦大学计算机博士.\r\n" +
@@ -25,7 +25,6 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
-	"go.dedis.ch/kyber/v3"
 )
 
 // Genesis specifies the header fields, state of a genesis block. It also defines hard
@@ -143,7 +142,7 @@ func (mib *MediatorInfoBase) Validate() (common.Address, error) {
 		}
 	}
 
-	_, err = StrToPoint(mib.InitPubKey)
+	_, err = StrToInitKey(mib.InitPubKey)
 	if err != nil {
 		return addr, err
 	}
@@ -175,31 +174,19 @@ func NewInitialMediator() *InitialMediator {
 }
 
 // author Albert·Gou
-func ScalarToStr(sec kyber.Scalar) string {
-	secB, err := sec.MarshalBinary()
-	if err != nil {
-		log.Error(err.Error())
-	}
-
-	return base58.Encode(secB)
+func InitKeyToStr(key []byte) string {
+	return base58.Encode(key)
 }
 
 // author Albert·Gou
-func PointToStr(pub kyber.Point) string {
-	pubB, err := pub.MarshalBinary()
+func CreateInitDKS(scheme string) (secStr, pubStr string, err error) {
+	sec, pub, err := GenInitPair(scheme)
 	if err != nil {
-		log.Error(err.Error())
+		return "", "", err
 	}
 
-	return base58.Encode(pubB)
-}
-
-// author Albert·Gou
-func CreateInitDKS() (secStr, pubStr string) {
-	sec, pub := GenInitPair()
-
-	secStr = ScalarToStr(sec)
-	pubStr = PointToStr(pub)
+	secStr = InitKeyToStr(sec)
+	pubStr = InitKeyToStr(pub)
 
 	return
 }
@@ -0,0 +1,276 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package groupsign
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/share"
+	"go.dedis.ch/kyber/v3/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v3/share/vss/pedersen"
+	"go.dedis.ch/kyber/v3/sign/bls"
+	"go.dedis.ch/kyber/v3/sign/tbls"
+)
+
+// 基于bn256曲线的pedersen DKG和BLS门限签名，公钥在G2上
+type bn256Scheme struct {
+	suite *bn256.Suite
+}
+
+func NewBn256Scheme() Scheme {
+	return &bn256Scheme{suite: bn256.NewSuiteG2()}
+}
+
+func (s *bn256Scheme) Name() string {
+	return DefaultSchemeName
+}
+
+// 随机数可以由调用者指定的suite
+type streamSuite struct {
+	*bn256.Suite
+	stream cipher.Stream
+}
+
+func (s *streamSuite) RandomStream() cipher.Stream {
+	if s.stream == nil {
+		return s.Suite.RandomStream()
+	}
+	return s.stream
+}
+
+func (s *bn256Scheme) GenerateKeyPair(rand cipher.Stream) (sec, pub []byte, err error) {
+	suite := &streamSuite{Suite: s.suite, stream: rand}
+	sc := suite.Scalar().Pick(suite.RandomStream())
+	if sec, err = sc.MarshalBinary(); err != nil {
+		return nil, nil, err
+	}
+	if pub, err = suite.Point().Mul(sc, nil).MarshalBinary(); err != nil {
+		return nil, nil, err
+	}
+
+	return sec, pub, nil
+}
+
+func (s *bn256Scheme) scalar(data []byte) (kyber.Scalar, error) {
+	sc := s.suite.Scalar()
+	if err := sc.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+func (s *bn256Scheme) point(data []byte) (kyber.Point, error) {
+	p := s.suite.Point()
+	if err := p.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *bn256Scheme) CheckPrivKey(sec []byte) error {
+	_, err := s.scalar(sec)
+	return err
+}
+
+func (s *bn256Scheme) CheckPubKey(pub []byte) error {
+	_, err := s.point(pub)
+	return err
+}
+
+func (s *bn256Scheme) NewDKG(initSec []byte, initPubs [][]byte, threshold int, rand cipher.Stream) (DKG, error) {
+	sec, err := s.scalar(initSec)
+	if err != nil {
+		return nil, err
+	}
+	pubs := make([]kyber.Point, 0, len(initPubs))
+	for _, pubB := range initPubs {
+		pub, err := s.point(pubB)
+		if err != nil {
+			return nil, err
+		}
+		pubs = append(pubs, pub)
+	}
+
+	suite := &streamSuite{Suite: s.suite, stream: rand}
+	dkgr, err := dkg.NewDistKeyGenerator(suite, sec, pubs, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bn256DKG{dkgr: dkgr}, nil
+}
+
+// 密钥分片的序列化格式
+type bn256KeyShare struct {
+	Index   uint32
+	Share   []byte
+	Commits [][]byte
+}
+
+func (s *bn256Scheme) decodeKeyShare(ks KeyShare) (*share.PriShare, []kyber.Point, error) {
+	data := &bn256KeyShare{}
+	if err := rlp.DecodeBytes(ks, data); err != nil {
+		return nil, nil, err
+	}
+	v, err := s.scalar(data.Share)
+	if err != nil {
+		return nil, nil, err
+	}
+	commits := make([]kyber.Point, 0, len(data.Commits))
+	for _, c := range data.Commits {
+		p, err := s.point(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		commits = append(commits, p)
+	}
+	if len(commits) == 0 {
+		return nil, nil, errors.New("key share has no commitment")
+	}
+
+	return &share.PriShare{I: int(data.Index), V: v}, commits, nil
+}
+
+func (s *bn256Scheme) GroupPubKey(ks KeyShare) ([]byte, error) {
+	_, commits, err := s.decodeKeyShare(ks)
+	if err != nil {
+		return nil, err
+	}
+	return commits[0].MarshalBinary()
+}
+
+func (s *bn256Scheme) Sign(ks KeyShare, msg []byte) ([]byte, error) {
+	priShare, _, err := s.decodeKeyShare(ks)
+	if err != nil {
+		return nil, err
+	}
+	return tbls.Sign(s.suite, priShare, msg)
+}
+
+func (s *bn256Scheme) Recover(ks KeyShare, msg []byte, sigShares [][]byte, threshold, n int) ([]byte, error) {
+	_, commits, err := s.decodeKeyShare(ks)
+	if err != nil {
+		return nil, err
+	}
+	pubPoly := share.NewPubPoly(s.suite, s.suite.Point().Base(), commits)
+	return tbls.Recover(s.suite, pubPoly, msg, sigShares, threshold, n)
+}
+
+func (s *bn256Scheme) Verify(groupPubKey []byte, msg, sig []byte) error {
+	pubKey, err := s.point(groupPubKey)
+	if err != nil {
+		return err
+	}
+
+	return bls.Verify(s.suite, pubKey, msg, sig)
+}
+
+// pedersen DKG，deal和response使用rlp编码
+type bn256DKG struct {
+	dkgr *dkg.DistKeyGenerator
+}
+
+func (d *bn256DKG) Deals() (map[int]*Deal, error) {
+	deals, err := d.dkgr.Deals()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int]*Deal, len(deals))
+	for i, deal := range deals {
+		data, err := rlp.EncodeToBytes(deal)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = &Deal{Dealer: deal.Index, Data: data}
+	}
+
+	return result, nil
+}
+
+func (d *bn256DKG) ProcessDeal(deal *Deal) (*Response, error) {
+	dd := &dkg.Deal{}
+	if err := rlp.DecodeBytes(deal.Data, dd); err != nil {
+		return nil, err
+	}
+	if dd.Index != deal.Dealer {
+		return nil, fmt.Errorf("deal index(%v) does not match the dealer(%v)", dd.Index, deal.Dealer)
+	}
+	resp, err := d.dkgr.ProcessDeal(dd)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Response.Status != vss.StatusApproval {
+		return nil, fmt.Errorf("the deal from dealer(%v) gave a complaint", deal.Dealer)
+	}
+	data, err := rlp.EncodeToBytes(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{Dealer: resp.Index, Verifier: resp.Response.Index, Data: data}, nil
+}
+
+func (d *bn256DKG) ProcessResponse(resp *Response) error {
+	r := &dkg.Response{}
+	if err := rlp.DecodeBytes(resp.Data, r); err != nil {
+		return err
+	}
+	if r.Response == nil || r.Index != resp.Dealer || r.Response.Index != resp.Verifier {
+		return errors.New("response index does not match")
+	}
+	jstf, err := d.dkgr.ProcessResponse(r)
+	if err != nil {
+		return err
+	}
+	if jstf != nil {
+		return fmt.Errorf("the response of verifier(%v) to dealer(%v) needs justification",
+			resp.Verifier, resp.Dealer)
+	}
+
+	return nil
+}
+
+func (d *bn256DKG) Certified() bool {
+	return d.dkgr.Certified()
+}
+
+func (d *bn256DKG) KeyShare() (KeyShare, error) {
+	dks, err := d.dkgr.DistKeyShare()
+	if err != nil {
+		return nil, err
+	}
+	data := &bn256KeyShare{Index: uint32(dks.Share.I)}
+	if data.Share, err = dks.Share.V.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	for _, c := range dks.Commits {
+		cb, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data.Commits = append(data.Commits, cb)
+	}
+
+	return rlp.EncodeToBytes(data)
+}
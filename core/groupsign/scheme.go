@@ -0,0 +1,139 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+// Package groupsign 定义mediator对unit进行群签名所用的分布式密钥生成(DKG)和门限签名方案，
+// 具体方案由链参数GroupSignScheme选定
+package groupsign
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 发给某个参与者的deal，Data是方案序列化后的deal
+type Deal struct {
+	Dealer uint32 // 发出deal的参与者序号
+	Data   []byte
+}
+
+// 参与者对deal的response，需要广播给所有参与者
+type Response struct {
+	Dealer   uint32 // deal的发出者序号
+	Verifier uint32 // 发出response的参与者序号
+	Data     []byte
+}
+
+// DKG完成后本地参与者的密钥分片，格式由方案决定
+type KeyShare []byte
+
+// 分布式密钥生成协议，Deal和Response会在mediator之间广播
+type DKG interface {
+	// 发给其他参与者的deal，key为接收者的序号
+	Deals() (map[int]*Deal, error)
+
+	// 验证发给本地的deal，通过时返回需要广播的response
+	ProcessDeal(deal *Deal) (*Response, error)
+
+	// 处理其他参与者的response，response中有投诉时返回错误
+	ProcessResponse(resp *Response) error
+
+	Certified() bool
+
+	KeyShare() (KeyShare, error)
+}
+
+// 群签名方案，对外只使用序列化后的密钥、deal、密钥分片和签名，不暴露具体的密码学库类型
+type Scheme interface {
+	// 方案名称，记录在链参数中
+	Name() string
+
+	// 生成mediator的初始公私钥，rand为nil时使用系统的随机数
+	GenerateKeyPair(rand cipher.Stream) (sec, pub []byte, err error)
+
+	// 检查初始私钥和公钥的格式
+	CheckPrivKey(sec []byte) error
+	CheckPubKey(pub []byte) error
+
+	// 创建一个DKG实例，initSec是本地mediator的初始私钥，initPubs是所有参与者的初始公钥，
+	// rand为nil时使用系统的随机数
+	NewDKG(initSec []byte, initPubs [][]byte, threshold int, rand cipher.Stream) (DKG, error)
+
+	// 密钥分片对应的群公钥
+	GroupPubKey(ks KeyShare) ([]byte, error)
+
+	// 用本地的密钥分片对消息签名，返回签名分片
+	Sign(ks KeyShare, msg []byte) ([]byte, error)
+
+	// 从不少于门限数量的签名分片中恢复出群签名
+	Recover(ks KeyShare, msg []byte, sigShares [][]byte, threshold, n int) ([]byte, error)
+
+	// 用群公钥验证群签名
+	Verify(groupPubKey []byte, msg, sig []byte) error
+}
+
+const DefaultSchemeName = "bn256-tbls"
+
+var (
+	schemesLock sync.RWMutex
+	schemes     = make(map[string]Scheme)
+)
+
+func init() {
+	Register(NewBn256Scheme())
+}
+
+// 注册一个群签名方案，同名的方案会被覆盖
+func Register(s Scheme) {
+	schemesLock.Lock()
+	defer schemesLock.Unlock()
+
+	schemes[s.Name()] = s
+}
+
+// 根据名称查找群签名方案，名称为空时返回默认方案
+func Lookup(name string) (Scheme, error) {
+	if name == "" {
+		name = DefaultSchemeName
+	}
+
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+
+	s, ok := schemes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown group sign scheme: %v", name)
+	}
+
+	return s, nil
+}
+
+// 已注册的方案名称
+func Names() []string {
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
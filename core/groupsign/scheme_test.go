@@ -0,0 +1,122 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package groupsign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	s, err := Lookup("")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultSchemeName, s.Name())
+	assert.Contains(t, Names(), DefaultSchemeName)
+
+	_, err = Lookup("unknown")
+	assert.NotNil(t, err)
+}
+
+// 模拟n个mediator完成DKG，返回每个mediator的密钥分片
+func runDKG(t *testing.T, s Scheme, n, threshold int) []KeyShare {
+	secs := make([][]byte, n)
+	pubs := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		sec, pub, err := s.GenerateKeyPair(nil)
+		assert.Nil(t, err)
+		assert.Nil(t, s.CheckPrivKey(sec))
+		assert.Nil(t, s.CheckPubKey(pub))
+		secs[i], pubs[i] = sec, pub
+	}
+
+	dkgs := make([]DKG, n)
+	for i := 0; i < n; i++ {
+		d, err := s.NewDKG(secs[i], pubs, threshold, nil)
+		assert.Nil(t, err)
+		dkgs[i] = d
+	}
+
+	resps := make([]*Response, 0)
+	for _, d := range dkgs {
+		deals, err := d.Deals()
+		assert.Nil(t, err)
+		for i, deal := range deals {
+			resp, err := dkgs[i].ProcessDeal(deal)
+			assert.Nil(t, err)
+			assert.Equal(t, uint32(i), resp.Verifier)
+			resps = append(resps, resp)
+		}
+	}
+
+	for _, resp := range resps {
+		for i, d := range dkgs {
+			if uint32(i) == resp.Verifier {
+				continue
+			}
+			assert.Nil(t, d.ProcessResponse(resp))
+		}
+	}
+
+	kss := make([]KeyShare, n)
+	for i, d := range dkgs {
+		assert.True(t, d.Certified())
+		ks, err := d.KeyShare()
+		assert.Nil(t, err)
+		kss[i] = ks
+	}
+
+	return kss
+}
+
+func TestBn256Scheme(t *testing.T) {
+	s := NewBn256Scheme()
+	n, threshold := 3, 2
+	kss := runDKG(t, s, n, threshold)
+
+	msg := []byte("unit hash")
+	sigShares := make([][]byte, 0, threshold)
+	for i := 0; i < threshold; i++ {
+		sigShare, err := s.Sign(kss[i], msg)
+		assert.Nil(t, err)
+		sigShares = append(sigShares, sigShare)
+	}
+
+	groupSig, err := s.Recover(kss[n-1], msg, sigShares, threshold, n)
+	assert.Nil(t, err)
+
+	groupPubKey, err := s.GroupPubKey(kss[0])
+	assert.Nil(t, err)
+	for _, ks := range kss[1:] {
+		pubKey, err := s.GroupPubKey(ks)
+		assert.Nil(t, err)
+		assert.Equal(t, groupPubKey, pubKey)
+	}
+	assert.Nil(t, s.Verify(groupPubKey, msg, groupSig))
+	assert.NotNil(t, s.Verify(groupPubKey, []byte("other unit hash"), groupSig))
+	assert.NotNil(t, s.Verify([]byte("invalid pub key"), msg, groupSig))
+
+	// 签名分片不足门限
+	_, err = s.Recover(kss[0], msg, sigShares[:1], threshold, n)
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, s.CheckPubKey([]byte("invalid pub key")))
+	_, err = s.Sign(KeyShare("invalid key share"), msg)
+	assert.NotNil(t, err)
+}
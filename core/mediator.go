@@ -25,23 +25,24 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/p2p/discover"
-	"go.dedis.ch/kyber/v3"
-	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"github.com/palletone/go-palletone/core/groupsign"
 )
 
-var Suite = bn256.NewSuiteG2()
-
-func GenInitPair() (kyber.Scalar, kyber.Point) {
-	sc := Suite.Scalar().Pick(Suite.RandomStream())
+// 用链参数选定的群签名方案生成mediator的初始公私钥
+func GenInitPair(scheme string) (sec, pub []byte, err error) {
+	s, err := groupsign.Lookup(scheme)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return sc, Suite.Point().Mul(sc, nil)
+	return s.GenerateKeyPair(nil)
 }
 
 // mediator 结构体 和具体的账户模型有关
 type Mediator struct {
 	Address    common.Address `json:"address"`    // mediator账户地址，主要用于产块签名
 	RewardAdd  common.Address `json:"rewardAdd"`  // mediator奖励地址，主要用于接收产块奖励
-	InitPubKey kyber.Point    `json:"initPubKey"` // mediator的群签名初始公钥
+	InitPubKey []byte         `json:"initPubKey"` // mediator的群签名初始公钥
 	Node       *discover.Node `json:"node"`       // mediator节点网络信息，包括ip和端口等
 	*MediatorApplyInfo
 	*MediatorInfoExpand
@@ -88,6 +89,9 @@ type MediatorApplyInfo struct {
 	Location    string `json:"loc"`       // 节点所在地区
 	Url         string `json:"url"`       // 节点宣传网站
 	Description string `json:"applyInfo"` // 节点详细信息描述
+	// 出块奖励中mediator抽取的佣金比例，单位为万分之一，剩余部分按委托数量分给委托人，
+	// 没有委托人时全部奖励归mediator
+	CommissionRate uint16 `json:"commissionRate"`
 }
 
 func NewMediatorApplyInfo() *MediatorApplyInfo {
@@ -100,6 +104,17 @@ func NewMediatorApplyInfo() *MediatorApplyInfo {
 	}
 }
 
+// 佣金比例上限，即100%
+const MaxCommissionRate = 10000
+
+func (mai *MediatorApplyInfo) ValidateCommissionRate() error {
+	if mai.CommissionRate > MaxCommissionRate {
+		return fmt.Errorf("invalid commission rate %v, must not exceed %v", mai.CommissionRate, MaxCommissionRate)
+	}
+
+	return nil
+}
+
 func StrToMedNode(medNode string) (*discover.Node, error) {
 	node, err := discover.ParseNode(medNode)
 	if err != nil {
@@ -127,28 +142,46 @@ func StrToMedAdd(addStr string) (common.Address, error) {
 	return addr, nil
 }
 
-func StrToScalar(secStr string) (kyber.Scalar, error) {
-	secB := base58.Decode(secStr)
-	sec := Suite.Scalar()
+// 初始公私钥以base58编码，具体格式由群签名方案检查
+func StrToInitKey(keyStr string) ([]byte, error) {
+	key := base58.Decode(keyStr)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("invalid init mediator key \"%v\"", keyStr)
+	}
+
+	return key, nil
+}
 
-	err := sec.UnmarshalBinary(secB)
+// 按照群签名方案检查初始私钥
+func StrToInitPrivKey(scheme, secStr string) ([]byte, error) {
+	s, err := groupsign.Lookup(scheme)
 	if err != nil {
-		err = fmt.Errorf("invalid init mediator private key \"%v\" : %v", secStr, err)
 		return nil, err
 	}
+	sec, err := StrToInitKey(secStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckPrivKey(sec); err != nil {
+		return nil, fmt.Errorf("invalid init mediator private key \"%v\" : %v", secStr, err)
+	}
 
 	return sec, nil
 }
 
-func StrToPoint(pubStr string) (kyber.Point, error) {
-	pubB := base58.Decode(pubStr)
-	pub := Suite.Point()
-
-	err := pub.UnmarshalBinary(pubB)
+// 按照群签名方案检查初始公钥
+func StrToInitPubKey(scheme, pubStr string) ([]byte, error) {
+	s, err := groupsign.Lookup(scheme)
 	if err != nil {
-		err = fmt.Errorf("invalid init mediator public key \"%v\" : %v", pubStr, err)
 		return nil, err
 	}
+	pub, err := StrToInitKey(pubStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckPubKey(pub); err != nil {
+		return nil, fmt.Errorf("invalid init mediator public key \"%v\" : %v", pubStr, err)
+	}
 
 	return pub, nil
 }
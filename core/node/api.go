@@ -106,6 +106,43 @@ func (api *PrivateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// BanPeer disconnects the remote node and refuses its connections for the given
+// seconds, zero seconds bans it until it is unbanned. Bans survive restarts.
+func (api *PrivateAdminAPI) BanPeer(url string, seconds uint64, reason string) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := discover.ParseNode(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid pnode: %v", err)
+	}
+	server.BanPeer(node.ID, time.Duration(seconds)*time.Second, reason)
+	return true, nil
+}
+
+// UnbanPeer lifts the ban of a remote node.
+func (api *PrivateAdminAPI) UnbanPeer(url string) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := discover.ParseNode(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid pnode: %v", err)
+	}
+	return server.UnbanPeer(node.ID), nil
+}
+
+// BannedPeers lists the banned remote nodes.
+func (api *PrivateAdminAPI) BannedPeers() ([]*discover.BanEntry, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.BannedPeers(), nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
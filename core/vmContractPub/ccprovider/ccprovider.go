@@ -495,6 +495,8 @@ type ChaincodeProvider interface {
 	ExecuteWithErrorFilter(ctxt context.Context, cccid interface{}, spec interface{}, timeout time.Duration) ([]byte, *pb.ChaincodeEvent, error)
 	// Stop stops the chaincode given context and deployment spec
 	Stop(ctxt context.Context, cccid interface{}, spec *pb.ChaincodeDeploymentSpec, dontRmCon bool) error
+	// Launch starts the chaincode given context and deployment spec if it is not running
+	Launch(ctxt context.Context, cccid interface{}, spec *pb.ChaincodeDeploymentSpec) error
 
 	Destroy(ctxt context.Context, cccid interface{}, spec *pb.ChaincodeDeploymentSpec) error
 }
@@ -54,6 +54,7 @@ const (
 	ChaincodeSpec_NODE      ChaincodeSpec_Type = 2
 	ChaincodeSpec_CAR       ChaincodeSpec_Type = 3
 	ChaincodeSpec_JAVA      ChaincodeSpec_Type = 4
+	ChaincodeSpec_WASM      ChaincodeSpec_Type = 5
 )
 
 var ChaincodeSpec_Type_name = map[int32]string{
@@ -62,6 +63,7 @@ var ChaincodeSpec_Type_name = map[int32]string{
 	2: "NODE",
 	3: "CAR",
 	4: "JAVA",
+	5: "WASM",
 }
 
 var ChaincodeSpec_Type_value = map[string]int32{
@@ -70,6 +72,7 @@ var ChaincodeSpec_Type_value = map[string]int32{
 	"NODE":      2,
 	"CAR":       3,
 	"JAVA":      4,
+	"WASM":      5,
 }
 
 func (x ChaincodeSpec_Type) String() string {
@@ -439,46 +442,45 @@ func init() {
 func init() { proto.RegisterFile("chaincode.proto", fileDescriptor_97136ef4b384cc22) }
 
 var fileDescriptor_97136ef4b384cc22 = []byte{
-	// 641 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xad, 0xf3, 0xd9, 0x8e, 0xd3, 0xd4, 0x2c, 0xa5, 0x58, 0x3d, 0x85, 0x5c, 0x88, 0x2a, 0x70,
-	0xa4, 0x50, 0x21, 0x84, 0x50, 0x45, 0x6a, 0xbb, 0x95, 0x21, 0x4d, 0x8a, 0xd3, 0x22, 0xc1, 0x25,
-	0xda, 0x3a, 0x43, 0x62, 0xd5, 0xd9, 0xb5, 0x9c, 0xb5, 0xd5, 0x9c, 0xf9, 0x41, 0xfc, 0x14, 0x7e,
-	0x10, 0x17, 0xb4, 0xeb, 0x34, 0x69, 0x69, 0x8f, 0x9c, 0x32, 0xf3, 0xe6, 0xed, 0xdb, 0x79, 0x2f,
-	0x9b, 0xc0, 0x4e, 0x30, 0xa5, 0x21, 0x0b, 0xf8, 0x18, 0xad, 0x38, 0xe1, 0x82, 0x93, 0x8a, 0xfa,
-	0x98, 0x37, 0x07, 0xa0, 0xdb, 0xb7, 0x23, 0xcf, 0x21, 0x04, 0x4a, 0x31, 0x15, 0x53, 0x53, 0x6b,
-	0x68, 0xad, 0x2d, 0x5f, 0xd5, 0x12, 0x63, 0x74, 0x86, 0x66, 0x21, 0xc7, 0x64, 0x4d, 0x4c, 0xa8,
-	0x66, 0x98, 0xcc, 0x43, 0xce, 0xcc, 0xa2, 0x82, 0x6f, 0xdb, 0xe6, 0x2f, 0x0d, 0xea, 0x6b, 0x45,
-	0x16, 0xa7, 0x42, 0x0a, 0xd0, 0x64, 0x32, 0x37, 0xb5, 0x46, 0xb1, 0x55, 0xf3, 0x55, 0x4d, 0x3c,
-	0xd0, 0xc7, 0x18, 0xf0, 0x84, 0x8a, 0x90, 0xb3, 0xb9, 0x59, 0x68, 0x14, 0x5b, 0x7a, 0xe7, 0x65,
-	0xbe, 0xdc, 0xdc, 0xba, 0x2f, 0x60, 0x39, 0x6b, 0xa6, 0xcb, 0x44, 0xb2, 0xf0, 0xef, 0x9e, 0xdd,
-	0x3f, 0x02, 0xe3, 0x5f, 0x02, 0x31, 0xa0, 0x78, 0x8d, 0x8b, 0xa5, 0x0d, 0x59, 0x92, 0x5d, 0x28,
-	0x67, 0x34, 0x4a, 0x73, 0x1b, 0x35, 0x3f, 0x6f, 0xde, 0x17, 0xde, 0x69, 0xcd, 0xdf, 0x05, 0xd8,
-	0x5e, 0x5d, 0x38, 0x8c, 0x31, 0x20, 0x16, 0x94, 0xc4, 0x22, 0x46, 0x75, 0xbc, 0xde, 0xd9, 0x7f,
-	0xb0, 0x95, 0x24, 0x59, 0x17, 0x8b, 0x18, 0x7d, 0xc5, 0x23, 0x6f, 0xa1, 0xb6, 0xca, 0x77, 0x14,
-	0x8e, 0xd5, 0x15, 0x7a, 0xe7, 0xe9, 0x43, 0x37, 0x8e, 0xaf, 0xaf, 0x88, 0xde, 0x98, 0xbc, 0x82,
-	0x72, 0x28, 0x0d, 0xaa, 0x0c, 0xf5, 0xce, 0xde, 0xe3, 0xf6, 0xfd, 0x9c, 0x24, 0x33, 0x17, 0xe1,
-	0x0c, 0x79, 0x2a, 0xcc, 0x52, 0x43, 0x6b, 0x95, 0xfd, 0xdb, 0x96, 0xec, 0x41, 0xe5, 0x0c, 0x67,
-	0x3c, 0x59, 0x98, 0xe5, 0x86, 0xd6, 0x2a, 0xfa, 0xcb, 0x8e, 0xec, 0xc3, 0xa6, 0x1d, 0xa7, 0x5f,
-	0x52, 0x2e, 0xa8, 0x59, 0x51, 0x93, 0x55, 0xbf, 0x9c, 0x0d, 0xa7, 0x34, 0x41, 0xb3, 0xba, 0x9a,
-	0xa9, 0xbe, 0x79, 0x04, 0x25, 0xe9, 0x8e, 0x6c, 0xc3, 0xd6, 0x65, 0xdf, 0x71, 0x4f, 0xbc, 0xbe,
-	0xeb, 0x18, 0x1b, 0x04, 0xa0, 0x72, 0x3a, 0xe8, 0x75, 0xfb, 0xa7, 0x86, 0x46, 0x36, 0xa1, 0xd4,
-	0x1f, 0x38, 0xae, 0x51, 0x20, 0x55, 0x28, 0xda, 0x5d, 0xdf, 0x28, 0x4a, 0xe8, 0x53, 0xf7, 0x6b,
-	0xd7, 0x28, 0x35, 0xff, 0x68, 0xf0, 0x7c, 0xe5, 0xc1, 0xc1, 0x38, 0xe2, 0x8b, 0x19, 0x32, 0xa1,
-	0xb2, 0xfd, 0x00, 0xf5, 0x75, 0x56, 0xf3, 0x18, 0x03, 0x95, 0xb2, 0xde, 0x79, 0xf6, 0x68, 0xca,
-	0xfe, 0x76, 0x70, 0xef, 0x9b, 0x79, 0x01, 0x35, 0x75, 0x30, 0xa6, 0xc1, 0x35, 0x9d, 0xa0, 0x0a,
-	0xae, 0xe6, 0xeb, 0x12, 0x3b, 0xcf, 0x21, 0x32, 0x80, 0x4d, 0xbc, 0xc1, 0x60, 0x84, 0x2c, 0x53,
-	0x39, 0xd5, 0x3b, 0x87, 0x0f, 0xa4, 0xef, 0xef, 0x64, 0xb9, 0x37, 0x18, 0xa4, 0xf2, 0xf5, 0xb8,
-	0x2c, 0x0b, 0x13, 0xce, 0xe4, 0xc0, 0xaf, 0x4a, 0x15, 0x97, 0x65, 0x4d, 0x0b, 0x76, 0x1f, 0x23,
-	0xc8, 0x38, 0x9c, 0x81, 0xfd, 0xd9, 0xf5, 0xf3, 0x68, 0x86, 0xdf, 0x86, 0x17, 0xee, 0x99, 0xa1,
-	0x35, 0x7f, 0xde, 0x75, 0xef, 0xb1, 0x8c, 0x07, 0xea, 0x65, 0xfe, 0x07, 0xf7, 0x07, 0xf0, 0x24,
-	0x1c, 0x8f, 0x26, 0xc8, 0x30, 0x7f, 0xec, 0x23, 0x1a, 0x4d, 0x96, 0x3f, 0xcb, 0x9d, 0x70, 0x7c,
-	0xba, 0xc2, 0xbb, 0xd1, 0xe4, 0xe0, 0x10, 0x76, 0x6d, 0xce, 0x7e, 0x84, 0x63, 0x64, 0x22, 0xa4,
-	0x51, 0x28, 0x16, 0x3d, 0xcc, 0x30, 0x92, 0x9b, 0x9e, 0x5f, 0x1e, 0xf7, 0x3c, 0xdb, 0xd8, 0x20,
-	0x06, 0xd4, 0xec, 0x41, 0xff, 0xc4, 0x73, 0xdc, 0xfe, 0x85, 0xd7, 0xed, 0x19, 0xda, 0xf1, 0x00,
-	0xf4, 0xe5, 0x22, 0x31, 0x62, 0xf2, 0xfd, 0xe3, 0x24, 0x14, 0xd3, 0xf4, 0xca, 0x0a, 0xf8, 0xac,
-	0x1d, 0xd3, 0x28, 0x42, 0xc1, 0x19, 0xb6, 0x27, 0xfc, 0xf5, 0xba, 0x09, 0x78, 0x82, 0xed, 0x6c,
-	0x66, 0x73, 0x26, 0x12, 0x1a, 0x88, 0xf3, 0xf4, 0xaa, 0x9d, 0x2b, 0xb4, 0xa5, 0xc2, 0x55, 0xfe,
-	0x3f, 0xf3, 0xe6, 0x6f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc2, 0x3a, 0xc3, 0x07, 0x81, 0x04, 0x00,
-	0x00,
+	// 640 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0xad, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xc5, 0x71, 0x3e, 0xda, 0x71, 0x9a, 0x9a, 0xa5, 0x14, 0xab, 0x27, 0xf0, 0x85, 0xaa, 0x02,
+	0x47, 0x0a, 0x15, 0x42, 0x08, 0x21, 0x5c, 0xdb, 0x2d, 0x86, 0x34, 0x29, 0x4e, 0x0a, 0x82, 0x4b,
+	0xb4, 0xb5, 0x97, 0xc4, 0xaa, 0xe3, 0xb5, 0x1c, 0xdb, 0x22, 0x67, 0x7e, 0x10, 0xbf, 0x87, 0xdf,
+	0xc2, 0x85, 0xdd, 0x75, 0xea, 0xb4, 0xb4, 0x47, 0x4e, 0x9e, 0x79, 0xf3, 0x76, 0x76, 0xde, 0xf3,
+	0xee, 0xc2, 0xb6, 0x3f, 0xc3, 0x61, 0xec, 0xd3, 0x80, 0x18, 0x49, 0x4a, 0x33, 0x8a, 0x9a, 0xe2,
+	0xb3, 0xd0, 0x87, 0xa0, 0x58, 0x57, 0x25, 0xd7, 0x46, 0x08, 0xea, 0x09, 0xce, 0x66, 0x9a, 0xf4,
+	0x58, 0xda, 0xdf, 0xf4, 0x44, 0xcc, 0xb1, 0x18, 0xcf, 0x89, 0x56, 0x2b, 0x31, 0x1e, 0x23, 0x0d,
+	0x5a, 0x05, 0x49, 0x17, 0x21, 0x8d, 0x35, 0x59, 0xc0, 0x57, 0xa9, 0xfe, 0x4b, 0x82, 0xce, 0xba,
+	0x63, 0x9c, 0xe4, 0x19, 0x6f, 0x80, 0xd3, 0xe9, 0x82, 0x35, 0x95, 0xf7, 0xdb, 0x9e, 0x88, 0x91,
+	0x0b, 0x4a, 0x40, 0x7c, 0x9a, 0xe2, 0x8c, 0x2d, 0x5a, 0xb0, 0xde, 0xf2, 0xbe, 0xd2, 0x7b, 0x5a,
+	0x0e, 0xb7, 0x30, 0x6e, 0x36, 0x30, 0xec, 0x35, 0xd3, 0x89, 0xb3, 0x74, 0xe9, 0x5d, 0x5f, 0xbb,
+	0xf7, 0x16, 0xd4, 0x7f, 0x09, 0x48, 0x05, 0xf9, 0x92, 0x2c, 0x57, 0x32, 0x78, 0x88, 0x76, 0xa0,
+	0x51, 0xe0, 0x28, 0x2f, 0x65, 0xb4, 0xbd, 0x32, 0x79, 0x5d, 0x7b, 0x25, 0xe9, 0xbf, 0x6b, 0xb0,
+	0x55, 0x6d, 0x38, 0x4a, 0x88, 0x8f, 0x0c, 0xa8, 0x67, 0xcb, 0x84, 0x88, 0xe5, 0x9d, 0xde, 0xde,
+	0xad, 0xa9, 0x38, 0xc9, 0x18, 0x33, 0x86, 0x27, 0x78, 0xe8, 0x25, 0xb4, 0x2b, 0x7f, 0x27, 0x61,
+	0x20, 0xb6, 0x50, 0x7a, 0x0f, 0x6e, 0xab, 0xb1, 0x3d, 0xa5, 0x22, 0xba, 0x01, 0x7a, 0x06, 0x8d,
+	0x90, 0x0b, 0x14, 0x1e, 0x2a, 0xbd, 0xdd, 0xbb, 0xe5, 0x7b, 0x25, 0x89, 0x7b, 0x9e, 0x85, 0x73,
+	0x42, 0x19, 0xbf, 0xce, 0xf8, 0x0d, 0xef, 0x2a, 0x45, 0xbb, 0xd0, 0x3c, 0x25, 0x73, 0x9a, 0x2e,
+	0xb5, 0x06, 0x2b, 0xc8, 0xde, 0x2a, 0x43, 0x7b, 0xb0, 0x61, 0x25, 0xf9, 0xa7, 0x9c, 0x66, 0x58,
+	0x6b, 0x8a, 0x4a, 0x95, 0xaf, 0x6a, 0xa3, 0x19, 0x4e, 0x89, 0xd6, 0xaa, 0x6a, 0x22, 0xd7, 0xdf,
+	0x43, 0x9d, 0xab, 0x43, 0x5b, 0xb0, 0x79, 0x3e, 0xb0, 0x9d, 0x63, 0x77, 0xe0, 0xd8, 0xea, 0x3d,
+	0x04, 0xd0, 0x3c, 0x19, 0xf6, 0xcd, 0xc1, 0x89, 0x2a, 0xa1, 0x0d, 0xa8, 0x0f, 0x86, 0xb6, 0xa3,
+	0xd6, 0x50, 0x0b, 0x64, 0xcb, 0xf4, 0x54, 0x99, 0x43, 0x1f, 0xcc, 0xcf, 0xa6, 0x5a, 0xe7, 0xd1,
+	0x17, 0x73, 0x74, 0xaa, 0x36, 0xf4, 0x3f, 0x12, 0x3c, 0xaa, 0xd4, 0xd8, 0x24, 0x89, 0xe8, 0x72,
+	0x4e, 0xe2, 0x4c, 0xb8, 0xfc, 0x06, 0x3a, 0x6b, 0xd7, 0x16, 0x0c, 0x11, 0x7e, 0x2b, 0xbd, 0x87,
+	0x77, 0xfa, 0xed, 0x6d, 0xf9, 0x37, 0xfe, 0xd1, 0x13, 0xe6, 0x39, 0x5f, 0x98, 0x60, 0xff, 0x12,
+	0x4f, 0x89, 0xb0, 0xb0, 0xcd, 0xec, 0x65, 0xd8, 0x59, 0x09, 0xa1, 0x21, 0x6c, 0x90, 0x1f, 0xc4,
+	0x9f, 0x90, 0xb8, 0x10, 0x8e, 0x75, 0x7a, 0x87, 0xb7, 0x5a, 0xdf, 0x9c, 0xc9, 0x70, 0x18, 0x3f,
+	0xe7, 0xe7, 0xc8, 0x89, 0x8b, 0x30, 0xa5, 0x31, 0x2f, 0x78, 0x2d, 0xde, 0x85, 0x01, 0xba, 0x01,
+	0x3b, 0x77, 0x11, 0xb8, 0x31, 0xf6, 0xd0, 0xfa, 0xe8, 0x78, 0xa5, 0x49, 0xa3, 0xaf, 0xa3, 0xb1,
+	0x73, 0xaa, 0x4a, 0xfa, 0xcf, 0xeb, 0xea, 0xdd, 0xb8, 0xa0, 0xbe, 0x38, 0xa3, 0xff, 0x41, 0xfd,
+	0x01, 0xdc, 0x0f, 0x83, 0xc9, 0x94, 0xc4, 0xa4, 0x3c, 0xf6, 0x13, 0x1c, 0x4d, 0x57, 0x17, 0x74,
+	0x3b, 0x0c, 0x4e, 0x2a, 0xdc, 0x8c, 0xa6, 0x07, 0x87, 0xb0, 0x63, 0xd1, 0xf8, 0x7b, 0x18, 0xb0,
+	0x51, 0x43, 0x1c, 0x85, 0xd9, 0xb2, 0x4f, 0x0a, 0x12, 0xf1, 0x49, 0xcf, 0xce, 0x8f, 0xfa, 0xae,
+	0xc5, 0xa6, 0x56, 0xa1, 0x6d, 0x0d, 0x07, 0xc7, 0xae, 0xed, 0x0c, 0xc6, 0xae, 0xd9, 0x57, 0xa5,
+	0x23, 0xf6, 0x30, 0xac, 0x06, 0x49, 0x08, 0x49, 0xbf, 0xbd, 0x9b, 0x86, 0xd9, 0x2c, 0xbf, 0x30,
+	0x7c, 0x3a, 0xef, 0x26, 0x38, 0x8a, 0x48, 0x46, 0x63, 0xd2, 0x9d, 0xd2, 0xe7, 0xeb, 0x84, 0x5d,
+	0x41, 0xd2, 0x2d, 0xe6, 0x6c, 0xaf, 0x2c, 0xc5, 0x7e, 0x76, 0x96, 0x5f, 0x74, 0xcb, 0x0e, 0x5d,
+	0xde, 0xe1, 0xa2, 0x7c, 0x71, 0x5e, 0xfc, 0x05, 0xa8, 0x8f, 0x31, 0x65, 0x8b, 0x04, 0x00, 0x00,
 }
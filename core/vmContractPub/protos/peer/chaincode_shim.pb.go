@@ -38,13 +38,14 @@ const (
 	ChaincodeMessage_PUT_STATE           ChaincodeMessage_Type = 9
 	ChaincodeMessage_DEL_STATE           ChaincodeMessage_Type = 10
 	ChaincodeMessage_INVOKE_CHAINCODE    ChaincodeMessage_Type = 11
+	ChaincodeMessage_GET_STATE_BY_RANGE  ChaincodeMessage_Type = 12
 	ChaincodeMessage_RESPONSE            ChaincodeMessage_Type = 13
 	ChaincodeMessage_GET_STATE_BY_PREFIX ChaincodeMessage_Type = 14
 	//        GET_QUERY_RESULT = 15;
 	//        QUERY_STATE_NEXT = 16;
 	//        QUERY_STATE_CLOSE = 17;
-	ChaincodeMessage_KEEPALIVE ChaincodeMessage_Type = 18
-	//        GET_HISTORY_FOR_KEY = 19;
+	ChaincodeMessage_KEEPALIVE                 ChaincodeMessage_Type = 18
+	ChaincodeMessage_GET_HISTORY_FOR_KEY       ChaincodeMessage_Type = 19
 	ChaincodeMessage_GET_SYSTEM_CONFIG_REQUEST ChaincodeMessage_Type = 23
 	ChaincodeMessage_GET_CONTRACT_ALL_STATE    ChaincodeMessage_Type = 24
 	ChaincodeMessage_GET_TOKEN_BALANCE         ChaincodeMessage_Type = 25
@@ -56,6 +57,8 @@ const (
 	ChaincodeMessage_RECV_JURY                 ChaincodeMessage_Type = 31
 	ChaincodeMessage_GET_CERT_STATE            ChaincodeMessage_Type = 32
 	ChaincodeMessage_OUTCHAIN_CALL             ChaincodeMessage_Type = 33
+	ChaincodeMessage_INVOKE_CONTRACT           ChaincodeMessage_Type = 34
+	ChaincodeMessage_CHARGE_INSTRUCTIONS       ChaincodeMessage_Type = 35
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -71,9 +74,11 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	9:  "PUT_STATE",
 	10: "DEL_STATE",
 	11: "INVOKE_CHAINCODE",
+	12: "GET_STATE_BY_RANGE",
 	13: "RESPONSE",
 	14: "GET_STATE_BY_PREFIX",
 	18: "KEEPALIVE",
+	19: "GET_HISTORY_FOR_KEY",
 	23: "GET_SYSTEM_CONFIG_REQUEST",
 	24: "GET_CONTRACT_ALL_STATE",
 	25: "GET_TOKEN_BALANCE",
@@ -85,6 +90,8 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	31: "RECV_JURY",
 	32: "GET_CERT_STATE",
 	33: "OUTCHAIN_CALL",
+	34: "INVOKE_CONTRACT",
+	35: "CHARGE_INSTRUCTIONS",
 }
 
 var ChaincodeMessage_Type_value = map[string]int32{
@@ -100,9 +107,11 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"PUT_STATE":                 9,
 	"DEL_STATE":                 10,
 	"INVOKE_CHAINCODE":          11,
+	"GET_STATE_BY_RANGE":        12,
 	"RESPONSE":                  13,
 	"GET_STATE_BY_PREFIX":       14,
 	"KEEPALIVE":                 18,
+	"GET_HISTORY_FOR_KEY":       19,
 	"GET_SYSTEM_CONFIG_REQUEST": 23,
 	"GET_CONTRACT_ALL_STATE":    24,
 	"GET_TOKEN_BALANCE":         25,
@@ -114,6 +123,8 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"RECV_JURY":                 31,
 	"GET_CERT_STATE":            32,
 	"OUTCHAIN_CALL":             33,
+	"INVOKE_CONTRACT":           34,
+	"CHARGE_INSTRUCTIONS":       35,
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -495,6 +506,8 @@ type GetStateByRange struct {
 	StartKey             string   `protobuf:"bytes,1,opt,name=startKey,proto3" json:"startKey,omitempty"`
 	EndKey               string   `protobuf:"bytes,2,opt,name=endKey,proto3" json:"endKey,omitempty"`
 	Collection           string   `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	ContractId           []byte   `protobuf:"bytes,4,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Limit                int32    `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -546,6 +559,20 @@ func (m *GetStateByRange) GetCollection() string {
 	return ""
 }
 
+func (m *GetStateByRange) GetContractId() []byte {
+	if m != nil {
+		return m.ContractId
+	}
+	return nil
+}
+
+func (m *GetStateByRange) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
 type GetQueryResult struct {
 	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
 	Collection           string   `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
@@ -595,6 +622,7 @@ func (m *GetQueryResult) GetCollection() string {
 
 type GetHistoryForKey struct {
 	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ContractId           []byte   `protobuf:"bytes,2,opt,name=contractId,proto3" json:"contractId,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -632,6 +660,13 @@ func (m *GetHistoryForKey) GetKey() string {
 	return ""
 }
 
+func (m *GetHistoryForKey) GetContractId() []byte {
+	if m != nil {
+		return m.ContractId
+	}
+	return nil
+}
+
 type QueryStateNext struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -1043,6 +1078,7 @@ func (m *RecvJury) GetCollection() string {
 type GetTokenBalance struct {
 	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	Asset                string   `protobuf:"bytes,2,opt,name=asset,proto3" json:"asset,omitempty"`
+	Height               uint64   `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -1087,6 +1123,13 @@ func (m *GetTokenBalance) GetAsset() string {
 	return ""
 }
 
+func (m *GetTokenBalance) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
 type PayOutToken struct {
 	Asset                []byte   `protobuf:"bytes,1,opt,name=asset,proto3" json:"asset,omitempty"`
 	Amount               uint64   `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
@@ -1354,6 +1397,100 @@ func (m *GetStateByPrefixKv) GetResultBytes() []byte {
 	return nil
 }
 
+type InvokeContract struct {
+	ContractId           []byte         `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Args                 [][]byte       `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	Tokens               []*PayOutToken `protobuf:"bytes,3,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *InvokeContract) Reset()         { *m = InvokeContract{} }
+func (m *InvokeContract) String() string { return proto.CompactTextString(m) }
+func (*InvokeContract) ProtoMessage()    {}
+func (*InvokeContract) Descriptor() ([]byte, []int) {
+	return fileDescriptor_adb8e00c9c92d6c8, []int{23}
+}
+
+func (m *InvokeContract) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvokeContract.Unmarshal(m, b)
+}
+func (m *InvokeContract) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvokeContract.Marshal(b, m, deterministic)
+}
+func (m *InvokeContract) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvokeContract.Merge(m, src)
+}
+func (m *InvokeContract) XXX_Size() int {
+	return xxx_messageInfo_InvokeContract.Size(m)
+}
+func (m *InvokeContract) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvokeContract.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvokeContract proto.InternalMessageInfo
+
+func (m *InvokeContract) GetContractId() []byte {
+	if m != nil {
+		return m.ContractId
+	}
+	return nil
+}
+
+func (m *InvokeContract) GetArgs() [][]byte {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *InvokeContract) GetTokens() []*PayOutToken {
+	if m != nil {
+		return m.Tokens
+	}
+	return nil
+}
+
+type ChargeInstructions struct {
+	Count                uint64   `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChargeInstructions) Reset()         { *m = ChargeInstructions{} }
+func (m *ChargeInstructions) String() string { return proto.CompactTextString(m) }
+func (*ChargeInstructions) ProtoMessage()    {}
+func (*ChargeInstructions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_adb8e00c9c92d6c8, []int{24}
+}
+
+func (m *ChargeInstructions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChargeInstructions.Unmarshal(m, b)
+}
+func (m *ChargeInstructions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChargeInstructions.Marshal(b, m, deterministic)
+}
+func (m *ChargeInstructions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChargeInstructions.Merge(m, src)
+}
+func (m *ChargeInstructions) XXX_Size() int {
+	return xxx_messageInfo_ChargeInstructions.Size(m)
+}
+func (m *ChargeInstructions) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChargeInstructions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChargeInstructions proto.InternalMessageInfo
+
+func (m *ChargeInstructions) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("protos.ChaincodeMessage_Type", ChaincodeMessage_Type_name, ChaincodeMessage_Type_value)
 	proto.RegisterType((*ChaincodeMessage)(nil), "protos.ChaincodeMessage")
@@ -1379,95 +1516,105 @@ func init() {
 	proto.RegisterType((*SupplyToken)(nil), "protos.SupplyToken")
 	proto.RegisterType((*KeyForSystemConfig)(nil), "protos.KeyForSystemConfig")
 	proto.RegisterType((*GetStateByPrefixKv)(nil), "protos.GetStateByPrefixKv")
+	proto.RegisterType((*InvokeContract)(nil), "protos.InvokeContract")
+	proto.RegisterType((*ChargeInstructions)(nil), "protos.ChargeInstructions")
 }
 
 func init() { proto.RegisterFile("chaincode_shim.proto", fileDescriptor_adb8e00c9c92d6c8) }
 
 var fileDescriptor_adb8e00c9c92d6c8 = []byte{
-	// 1316 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0xdf, 0x52, 0xdb, 0xc6,
-	0x17, 0x8e, 0xb1, 0x01, 0xfb, 0x18, 0x8c, 0xb2, 0xbf, 0xfc, 0x12, 0x87, 0x86, 0x84, 0x68, 0x3a,
-	0x19, 0x6e, 0x0a, 0x2d, 0xed, 0x45, 0xef, 0x1a, 0x21, 0x2f, 0x44, 0xc1, 0x48, 0xca, 0x4a, 0x66,
-	0x42, 0x27, 0x1d, 0x8d, 0x90, 0x16, 0x5b, 0x13, 0x59, 0xeb, 0x48, 0x2b, 0x1a, 0xdf, 0xf6, 0xa2,
-	0xd3, 0xe7, 0xe9, 0x7b, 0xf4, 0x9d, 0x3a, 0x2b, 0xad, 0x6c, 0x63, 0x92, 0x61, 0xa6, 0x69, 0xaf,
-	0xf0, 0x77, 0xce, 0xd1, 0x77, 0xbe, 0x73, 0xf6, 0xec, 0x1f, 0xe0, 0x41, 0x30, 0xf2, 0xa3, 0x24,
-	0x60, 0x21, 0xf5, 0xb2, 0x51, 0x34, 0xde, 0x9f, 0xa4, 0x8c, 0x33, 0xb4, 0x56, 0xfc, 0xc9, 0xb6,
-	0xcf, 0x87, 0x11, 0x1f, 0xe5, 0x97, 0xfb, 0x01, 0x1b, 0x1f, 0x4c, 0xfc, 0x38, 0xa6, 0x9c, 0x25,
-	0xf4, 0x60, 0xc8, 0xbe, 0x99, 0x83, 0x80, 0xa5, 0xf4, 0xe0, 0x7a, 0xac, 0xb3, 0x84, 0xa7, 0x7e,
-	0xc0, 0xed, 0xfc, 0xf2, 0xa0, 0xfc, 0xf8, 0x60, 0x42, 0x69, 0x7a, 0x30, 0xa7, 0xa7, 0xd7, 0x34,
-	0xe1, 0x25, 0xff, 0xb6, 0xf5, 0xc5, 0xbc, 0x93, 0x94, 0x4d, 0x58, 0xe6, 0xc7, 0x92, 0xf0, 0xd9,
-	0x90, 0xb1, 0x61, 0x4c, 0xcb, 0x90, 0xcb, 0xfc, 0xea, 0x80, 0x47, 0x63, 0x9a, 0x71, 0x7f, 0x3c,
-	0x29, 0x03, 0xd4, 0x3f, 0xd7, 0x40, 0xd1, 0x2b, 0x2d, 0x67, 0x34, 0xcb, 0xfc, 0x21, 0x45, 0xdf,
-	0x41, 0x83, 0x4f, 0x27, 0xb4, 0x5b, 0xdb, 0xad, 0xed, 0x75, 0x0e, 0x77, 0xca, 0xd0, 0x6c, 0x7f,
-	0x39, 0x6e, 0xdf, 0x9d, 0x4e, 0x28, 0x29, 0x42, 0xd1, 0x8f, 0xd0, 0x9a, 0x51, 0x77, 0x57, 0x76,
-	0x6b, 0x7b, 0xed, 0xc3, 0xed, 0xfd, 0x32, 0xf9, 0x7e, 0x95, 0x7c, 0xdf, 0xad, 0x22, 0xc8, 0x3c,
-	0x18, 0x75, 0x61, 0x7d, 0xe2, 0x4f, 0x63, 0xe6, 0x87, 0xdd, 0xfa, 0x6e, 0x6d, 0x6f, 0x83, 0x54,
-	0x10, 0x21, 0x68, 0xf0, 0x8f, 0x51, 0xd8, 0x6d, 0xec, 0xd6, 0xf6, 0x5a, 0xa4, 0xf8, 0x8d, 0x0e,
-	0xa1, 0x59, 0x95, 0xd8, 0x5d, 0x2d, 0xd2, 0x3c, 0xac, 0xe4, 0x39, 0xd1, 0x30, 0xa1, 0xa1, 0x2d,
-	0xbd, 0x64, 0x16, 0x87, 0x7e, 0x82, 0xad, 0xa5, 0x76, 0x77, 0xd7, 0x6e, 0x7e, 0x3a, 0xab, 0x0c,
-	0x0b, 0x2f, 0xe9, 0x04, 0x37, 0x30, 0xda, 0x01, 0x08, 0x46, 0x7e, 0x92, 0xd0, 0xd8, 0x8b, 0xc2,
-	0xee, 0x7a, 0x21, 0xa7, 0x25, 0x2d, 0x46, 0x88, 0x9e, 0x41, 0x3b, 0x90, 0xcb, 0x21, 0xfc, 0xcd,
-	0xa2, 0x0a, 0xa8, 0x4c, 0x46, 0xa8, 0xfe, 0x55, 0x87, 0x86, 0xe8, 0x15, 0xda, 0x84, 0xd6, 0xc0,
-	0xec, 0xe1, 0x63, 0xc3, 0xc4, 0x3d, 0xe5, 0x1e, 0xda, 0x80, 0x26, 0xc1, 0x27, 0x86, 0xe3, 0x62,
-	0xa2, 0xd4, 0x50, 0x07, 0xa0, 0x42, 0xb8, 0xa7, 0xac, 0xa0, 0x26, 0x34, 0x0c, 0xd3, 0x70, 0x95,
-	0x3a, 0x6a, 0xc1, 0x2a, 0xc1, 0x5a, 0xef, 0x42, 0x69, 0xa0, 0x2d, 0x68, 0xbb, 0x44, 0x33, 0x1d,
-	0x4d, 0x77, 0x0d, 0xcb, 0x54, 0x56, 0x05, 0xa5, 0x6e, 0x9d, 0xd9, 0x7d, 0xec, 0xe2, 0x9e, 0xb2,
-	0x26, 0x42, 0x31, 0x21, 0x16, 0x51, 0xd6, 0x85, 0xe7, 0x04, 0xbb, 0x9e, 0xe3, 0x6a, 0x2e, 0x56,
-	0x9a, 0x02, 0xda, 0x83, 0x0a, 0xb6, 0x04, 0xec, 0xe1, 0xbe, 0x84, 0x80, 0x1e, 0x80, 0x62, 0x98,
-	0xe7, 0xd6, 0x29, 0xf6, 0xf4, 0x57, 0x9a, 0x61, 0xea, 0x56, 0x0f, 0x2b, 0xed, 0x52, 0xa0, 0x63,
-	0x5b, 0xa6, 0x83, 0x95, 0x4d, 0xf4, 0x08, 0xfe, 0x37, 0x23, 0xf4, 0x8e, 0x2e, 0x3c, 0x9b, 0xe0,
-	0x63, 0xe3, 0xad, 0xd2, 0x11, 0x5c, 0xa7, 0x18, 0xdb, 0x5a, 0xdf, 0x38, 0xc7, 0x0a, 0x42, 0x3b,
-	0xf0, 0xb8, 0x88, 0xbb, 0x70, 0x5c, 0x7c, 0xe6, 0xe9, 0x96, 0x79, 0x6c, 0x9c, 0x78, 0x04, 0xbf,
-	0x19, 0x60, 0xc7, 0x55, 0x1e, 0xa1, 0x6d, 0x78, 0x28, 0xdc, 0xba, 0x65, 0xba, 0x44, 0xd3, 0x5d,
-	0x4f, 0xeb, 0x57, 0x32, 0xba, 0xe8, 0xff, 0x70, 0x5f, 0xf8, 0x5c, 0xeb, 0x14, 0x9b, 0xde, 0x91,
-	0xd6, 0xd7, 0x4c, 0x1d, 0x2b, 0x8f, 0xd1, 0x7d, 0xd8, 0xb4, 0xb5, 0x0b, 0xcf, 0x1a, 0x48, 0x97,
-	0xb2, 0x8d, 0x14, 0xd8, 0x28, 0x1b, 0x29, 0x2d, 0x5f, 0x09, 0x8b, 0x33, 0xb0, 0xed, 0xfe, 0x85,
-	0xb4, 0x3c, 0x11, 0x9f, 0x15, 0x6c, 0xc6, 0x19, 0x76, 0x5c, 0xed, 0xcc, 0x56, 0x76, 0x84, 0x54,
-	0x07, 0x9b, 0x3d, 0xef, 0xf5, 0x80, 0x5c, 0x28, 0x4f, 0x05, 0x24, 0x58, 0x3f, 0x2f, 0xe1, 0x33,
-	0x84, 0xa0, 0x53, 0x48, 0xc3, 0xa4, 0x6a, 0xd4, 0xae, 0x20, 0xb1, 0x06, 0x6e, 0xd1, 0x15, 0x4f,
-	0xd7, 0xfa, 0x7d, 0xe5, 0xb9, 0xfa, 0x0e, 0x9a, 0x27, 0x94, 0x3b, 0xdc, 0xe7, 0x14, 0x29, 0x50,
-	0x7f, 0x4f, 0xa7, 0xc5, 0x56, 0x69, 0x11, 0xf1, 0x13, 0x3d, 0x05, 0x08, 0x58, 0x1c, 0xd3, 0x80,
-	0x47, 0x2c, 0x29, 0xf6, 0x42, 0x8b, 0x2c, 0x58, 0x4a, 0x7f, 0x35, 0x1b, 0x72, 0xe6, 0x17, 0xa7,
-	0xe5, 0x35, 0x28, 0x15, 0xfb, 0xd1, 0xd4, 0x4e, 0xe9, 0x55, 0xf4, 0x11, 0x3d, 0x84, 0xb5, 0x49,
-	0xf1, 0x4b, 0x26, 0x92, 0x68, 0x89, 0x6b, 0xe5, 0x16, 0x97, 0x0d, 0x1b, 0x27, 0x94, 0xcf, 0xf6,
-	0x1d, 0xda, 0x85, 0x76, 0xea, 0x27, 0x43, 0x6a, 0xe6, 0xe3, 0x4b, 0x9a, 0x16, 0x64, 0x9b, 0x64,
-	0xd1, 0x74, 0x97, 0x7a, 0x35, 0x85, 0xa6, 0x9d, 0x7f, 0xb6, 0xf6, 0x07, 0xb0, 0x7a, 0xed, 0xc7,
-	0x39, 0x95, 0x52, 0x4a, 0xb0, 0xc4, 0x59, 0xbf, 0xa3, 0x23, 0x8d, 0x5b, 0x55, 0xbc, 0x83, 0x66,
-	0x8f, 0xc6, 0xff, 0x55, 0xbf, 0x29, 0x6c, 0xcd, 0xfb, 0x4d, 0x44, 0x2b, 0xd0, 0x36, 0x34, 0x33,
-	0xee, 0xa7, 0xfc, 0x74, 0x96, 0x69, 0x86, 0xc5, 0x52, 0xd0, 0x24, 0x14, 0x9e, 0x32, 0x95, 0x44,
-	0x77, 0x15, 0xa9, 0x1e, 0x43, 0xe7, 0x84, 0xf2, 0x37, 0x39, 0x4d, 0xa7, 0x84, 0x66, 0x79, 0xcc,
-	0x45, 0xb3, 0x3e, 0x08, 0x28, 0x53, 0x94, 0xe0, 0xce, 0x05, 0xf8, 0xba, 0x18, 0x8f, 0x57, 0x51,
-	0xc6, 0x59, 0x3a, 0x3d, 0x66, 0xa9, 0xc8, 0x7d, 0xab, 0x29, 0xea, 0x2e, 0x74, 0x8a, 0x54, 0x45,
-	0x59, 0x26, 0xfd, 0xc8, 0x51, 0x07, 0x56, 0xa2, 0x50, 0x86, 0xac, 0x44, 0xa1, 0xfa, 0x1c, 0xb6,
-	0xe6, 0x11, 0x7a, 0xcc, 0x32, 0x7a, 0x2b, 0xe4, 0x07, 0x50, 0x16, 0xf4, 0x1e, 0x4d, 0x39, 0xcd,
-	0x8a, 0x09, 0x9a, 0xc3, 0x22, 0x78, 0x83, 0x2c, 0x9a, 0xd4, 0x04, 0x36, 0xab, 0xaf, 0x26, 0x2c,
-	0xc9, 0x28, 0x3a, 0x84, 0xf5, 0xd2, 0x2f, 0xc2, 0xeb, 0x7b, 0xed, 0xc3, 0x6e, 0x75, 0xee, 0x2e,
-	0xb3, 0x93, 0x2a, 0x10, 0x3d, 0x86, 0xe6, 0xc8, 0xcf, 0xbc, 0x31, 0x4b, 0xcb, 0x59, 0x6a, 0x92,
-	0xf5, 0x91, 0x9f, 0x9d, 0xb1, 0xb4, 0x52, 0x59, 0x9f, 0xa9, 0xfc, 0xad, 0x06, 0x1b, 0x56, 0xce,
-	0x8b, 0x33, 0x5c, 0xf7, 0xe3, 0x18, 0xa9, 0x73, 0x6c, 0xfa, 0x63, 0x2a, 0x0b, 0xba, 0x61, 0x13,
-	0xab, 0x38, 0xa6, 0x7c, 0xc4, 0xc2, 0x6a, 0x15, 0x4b, 0x54, 0x6c, 0x34, 0x3f, 0xf5, 0xc7, 0x99,
-	0x1c, 0x14, 0x89, 0x96, 0x56, 0xa5, 0x71, 0x6b, 0x55, 0x5e, 0x16, 0x1b, 0x4d, 0xa7, 0xe9, 0x3f,
-	0x3d, 0x16, 0xd4, 0x3f, 0x6a, 0xd0, 0x74, 0x68, 0x12, 0xbe, 0xce, 0xd3, 0xa9, 0xb8, 0x14, 0xc7,
-	0xd9, 0xd0, 0xad, 0x2e, 0xe1, 0x4d, 0x52, 0x41, 0xf4, 0x02, 0x3a, 0x01, 0x4b, 0x44, 0x93, 0xc4,
-	0x13, 0x40, 0xdc, 0x65, 0xe5, 0x56, 0x5b, 0xb2, 0x8a, 0x11, 0x1e, 0x4f, 0xb5, 0x24, 0xfb, 0x95,
-	0xa6, 0xb2, 0x94, 0x19, 0xbe, 0xb3, 0x98, 0xdf, 0x6b, 0xd0, 0x24, 0x34, 0xb8, 0xfe, 0x97, 0xa4,
-	0x74, 0x61, 0x5d, 0x5c, 0xf7, 0x2c, 0xe7, 0x85, 0x92, 0x4d, 0x52, 0xc1, 0x3b, 0x85, 0x68, 0xc5,
-	0xd6, 0x74, 0xd9, 0x7b, 0x9a, 0x1c, 0xf9, 0xb1, 0x9f, 0x04, 0x54, 0x90, 0xf9, 0x61, 0x98, 0xd2,
-	0x2c, 0x93, 0xcd, 0xad, 0xa0, 0xd8, 0x4e, 0x7e, 0x96, 0x51, 0x2e, 0x7b, 0x5b, 0x02, 0xf5, 0x03,
-	0xb4, 0x6d, 0x7f, 0x6a, 0xe5, 0x25, 0xcb, 0x3c, 0xa8, 0x1c, 0xdc, 0x12, 0x88, 0x55, 0xf7, 0xc7,
-	0x2c, 0x97, 0x15, 0x34, 0x88, 0x44, 0xa2, 0x89, 0x31, 0x0b, 0xde, 0x0b, 0xb9, 0x52, 0xfa, 0x0c,
-	0x2f, 0x0a, 0x69, 0xdc, 0x10, 0xa2, 0xfe, 0x02, 0xed, 0x1e, 0xbd, 0x8a, 0x12, 0x5a, 0xa6, 0x7c,
-	0x02, 0x2d, 0x2e, 0x7e, 0xcc, 0x5a, 0xb8, 0x4a, 0xe6, 0x06, 0x91, 0x3a, 0x2c, 0x82, 0x65, 0xf3,
-	0x24, 0x12, 0xf4, 0x41, 0x4a, 0x7d, 0xce, 0x52, 0x39, 0xea, 0x15, 0x54, 0x73, 0x68, 0x3b, 0xf9,
-	0x64, 0x12, 0x4f, 0x4b, 0x7a, 0xa1, 0x43, 0x14, 0x61, 0x84, 0xb2, 0xa6, 0x0a, 0x0a, 0xf5, 0x79,
-	0x12, 0x7d, 0xc8, 0xe9, 0xec, 0x6a, 0x98, 0xe1, 0x85, 0x8a, 0xeb, 0x37, 0x2a, 0x5e, 0x48, 0xdb,
-	0xb8, 0x99, 0xf6, 0x05, 0xa0, 0x53, 0x2a, 0x0e, 0x1c, 0x67, 0x9a, 0x71, 0x2a, 0x1e, 0xa0, 0x57,
-	0xd1, 0xf0, 0x13, 0x27, 0xcf, 0x2b, 0x40, 0xcb, 0xd7, 0xd7, 0xe9, 0xf5, 0x27, 0xf6, 0xc3, 0xd2,
-	0x41, 0xb2, 0x72, 0xeb, 0x20, 0x39, 0x7c, 0xbb, 0xf0, 0x34, 0x15, 0x15, 0xb3, 0x94, 0xa3, 0x9e,
-	0x98, 0xcc, 0x61, 0x94, 0x71, 0x9a, 0xa2, 0xee, 0xe7, 0x1e, 0xa6, 0xdb, 0x9f, 0xf5, 0xa8, 0xf7,
-	0xf6, 0x6a, 0xdf, 0xd6, 0x8e, 0x2c, 0x68, 0xcb, 0x00, 0xf1, 0x68, 0xfe, 0xf9, 0xe5, 0x97, 0x3e,
-	0xbb, 0x2f, 0xcb, 0x7f, 0x0c, 0xbe, 0xff, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x17, 0x4a, 0x9c, 0x32,
-	0x37, 0x0c, 0x00, 0x00,
+	// 1452 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0xb5, 0x57, 0x4b, 0x6f, 0xdb, 0x46,
+	0x10, 0x8e, 0x2c, 0xd9, 0x96, 0x56, 0xb2, 0xcc, 0xac, 0xd3, 0x44, 0x71, 0xf3, 0x70, 0x58, 0x20,
+	0x08, 0x5a, 0x54, 0x6a, 0xdd, 0x1e, 0x7a, 0x6b, 0x64, 0x8a, 0xb6, 0x19, 0xdb, 0x14, 0xb3, 0xa4,
+	0x8c, 0xa8, 0x68, 0x41, 0xd0, 0xd2, 0x46, 0x22, 0xc2, 0x87, 0xc2, 0x87, 0x1b, 0x5d, 0x7b, 0x28,
+	0x7a, 0xed, 0xb9, 0x7f, 0xa1, 0x3f, 0xb2, 0xb3, 0xcb, 0xa5, 0x9e, 0x75, 0x0d, 0x34, 0xed, 0x49,
+	0xfc, 0x66, 0x67, 0x67, 0xbe, 0x99, 0x9d, 0x99, 0x5d, 0xa1, 0x7b, 0x83, 0xb1, 0xe3, 0x06, 0x83,
+	0x70, 0x48, 0xed, 0x78, 0xec, 0xfa, 0xcd, 0x49, 0x14, 0x26, 0x21, 0xde, 0xe2, 0x3f, 0xf1, 0xfe,
+	0xe5, 0xc8, 0x4d, 0xc6, 0xe9, 0x55, 0x73, 0x10, 0xfa, 0xad, 0x89, 0xe3, 0x79, 0x34, 0x09, 0x03,
+	0xda, 0x1a, 0x85, 0x5f, 0xce, 0xc1, 0x20, 0x8c, 0x68, 0xeb, 0xda, 0x57, 0xc2, 0x20, 0x89, 0x9c,
+	0x41, 0x62, 0xa4, 0x57, 0xad, 0x6c, 0x73, 0x6b, 0x42, 0x69, 0xd4, 0x9a, 0x9b, 0xa7, 0xd7, 0x34,
+	0x48, 0x32, 0xfb, 0xfb, 0xdd, 0x8f, 0xb6, 0x0b, 0xdf, 0x93, 0x30, 0x76, 0x3c, 0x61, 0xf0, 0xe9,
+	0x28, 0x0c, 0x47, 0x1e, 0xcd, 0x54, 0xae, 0xd2, 0xb7, 0xad, 0xc4, 0xf5, 0x69, 0x9c, 0x38, 0xfe,
+	0x24, 0x53, 0x90, 0x7f, 0xdf, 0x46, 0x92, 0x92, 0x73, 0xb9, 0xa0, 0x71, 0xec, 0x8c, 0x28, 0xfe,
+	0x1a, 0x95, 0x92, 0xe9, 0x84, 0x36, 0x0a, 0x07, 0x85, 0x17, 0xf5, 0xc3, 0xc7, 0x99, 0x6a, 0xdc,
+	0x5c, 0xd5, 0x6b, 0x5a, 0xa0, 0x44, 0xb8, 0x2a, 0xfe, 0x0e, 0x55, 0x66, 0xa6, 0x1b, 0x1b, 0xb0,
+	0xaf, 0x7a, 0xb8, 0xdf, 0xcc, 0x9c, 0x37, 0x73, 0xe7, 0x4d, 0x2b, 0xd7, 0x20, 0x73, 0x65, 0xdc,
+	0x40, 0xdb, 0x13, 0x67, 0xea, 0x85, 0xce, 0xb0, 0x51, 0x84, 0x7d, 0x35, 0x92, 0x43, 0x8c, 0x81,
+	0xc6, 0x07, 0x77, 0xd8, 0x28, 0x81, 0xb8, 0x42, 0xf8, 0x37, 0x3e, 0x44, 0xe5, 0x3c, 0xc4, 0xc6,
+	0x26, 0x77, 0x73, 0x3f, 0xa7, 0x67, 0xba, 0xa3, 0x80, 0x0e, 0x0d, 0xb1, 0x4a, 0x66, 0x7a, 0xf8,
+	0x7b, 0xb4, 0xbb, 0x92, 0xee, 0xc6, 0xd6, 0xf2, 0xd6, 0x59, 0x64, 0x2a, 0x5b, 0x25, 0xf5, 0xc1,
+	0x12, 0xc6, 0x8f, 0x11, 0x02, 0x49, 0x10, 0x50, 0xcf, 0x06, 0x3a, 0xdb, 0x9c, 0x4e, 0x45, 0x48,
+	0xb4, 0x21, 0x7e, 0x8a, 0xaa, 0x03, 0x71, 0x1c, 0x6c, 0xbd, 0xcc, 0xa3, 0x40, 0xb9, 0x48, 0x1b,
+	0xca, 0x7f, 0x96, 0x50, 0x89, 0xe5, 0x0a, 0xef, 0xa0, 0x4a, 0x4f, 0xef, 0xa8, 0xc7, 0x9a, 0xae,
+	0x76, 0xa4, 0x3b, 0xb8, 0x86, 0xca, 0x44, 0x3d, 0xd1, 0x4c, 0x4b, 0x25, 0x52, 0x01, 0xd7, 0x11,
+	0xca, 0x11, 0xac, 0x6e, 0xe0, 0x32, 0x2a, 0x69, 0xba, 0x66, 0x49, 0x45, 0x5c, 0x41, 0x9b, 0x44,
+	0x6d, 0x77, 0xfa, 0x52, 0x09, 0xef, 0xa2, 0xaa, 0x45, 0xda, 0xba, 0xd9, 0x56, 0x2c, 0xad, 0xab,
+	0x4b, 0x9b, 0xcc, 0xa4, 0xd2, 0xbd, 0x30, 0xce, 0x55, 0x0b, 0x36, 0x6d, 0x31, 0x55, 0x95, 0x90,
+	0x2e, 0x91, 0xb6, 0xd9, 0xca, 0x89, 0x6a, 0xd9, 0xa6, 0xd5, 0xb6, 0x54, 0xa9, 0xcc, 0xa0, 0xd1,
+	0xcb, 0x61, 0x85, 0xc1, 0x8e, 0x7a, 0x2e, 0x20, 0xc2, 0xf7, 0x90, 0xa4, 0xe9, 0x97, 0xdd, 0x33,
+	0xd5, 0x56, 0x4e, 0xdb, 0x9a, 0xae, 0x74, 0x3b, 0xaa, 0x54, 0xc5, 0xf7, 0x11, 0x9e, 0x99, 0xb0,
+	0x8f, 0xfa, 0x36, 0x78, 0x3e, 0x51, 0xa5, 0x5a, 0x46, 0xdc, 0x34, 0xba, 0xba, 0xa9, 0x4a, 0x3b,
+	0xf8, 0x01, 0xda, 0x5b, 0xd2, 0x32, 0x08, 0x44, 0xf8, 0x46, 0xaa, 0x33, 0x1f, 0x67, 0xaa, 0x6a,
+	0xb4, 0xcf, 0xb5, 0x4b, 0x55, 0xc2, 0x90, 0xc6, 0x87, 0x5c, 0xaf, 0x0f, 0x21, 0x5e, 0xd8, 0x4a,
+	0x57, 0x3f, 0xd6, 0x4e, 0x6c, 0xa2, 0xbe, 0xee, 0xa9, 0xa6, 0x25, 0x3d, 0xc0, 0xfb, 0xe8, 0x3e,
+	0x5b, 0x06, 0x39, 0x44, 0xa8, 0x58, 0x76, 0xfb, 0x3c, 0xa7, 0xd7, 0xc0, 0x9f, 0xa0, 0xbb, 0x6c,
+	0xcd, 0x02, 0x82, 0xba, 0x7d, 0xd4, 0x3e, 0x6f, 0xeb, 0x8a, 0x2a, 0x3d, 0xc4, 0x77, 0xd1, 0x8e,
+	0xd1, 0xee, 0xdb, 0xdd, 0x9e, 0x58, 0x92, 0xf6, 0xb1, 0x84, 0x6a, 0x59, 0x82, 0x85, 0xe4, 0x53,
+	0x26, 0x31, 0x7b, 0x86, 0x71, 0xde, 0x17, 0x92, 0x47, 0x6c, 0x1b, 0xb7, 0xa6, 0x5d, 0x80, 0xe7,
+	0xf6, 0x85, 0x21, 0x3d, 0x66, 0x54, 0x4d, 0x55, 0xef, 0xd8, 0xaf, 0x7a, 0xa4, 0x2f, 0x3d, 0x61,
+	0x90, 0xa8, 0xca, 0x65, 0x06, 0x9f, 0x42, 0x25, 0xd6, 0x39, 0x35, 0x95, 0xe4, 0x09, 0x3c, 0x60,
+	0x46, 0xc0, 0x2f, 0xcf, 0x96, 0xad, 0x00, 0x57, 0xe9, 0x19, 0xde, 0x43, 0xbb, 0x79, 0x12, 0x45,
+	0x10, 0x92, 0xcc, 0xb2, 0x03, 0x4a, 0xe4, 0x44, 0xb5, 0x35, 0xdd, 0xb4, 0x48, 0x8f, 0x1f, 0x9c,
+	0x29, 0x7d, 0x96, 0xa7, 0xed, 0x14, 0x4e, 0xbc, 0x4b, 0xfa, 0xf6, 0x71, 0x97, 0xd8, 0x67, 0x6a,
+	0x5f, 0xda, 0x93, 0x7f, 0x44, 0xe5, 0x13, 0x9a, 0x98, 0x89, 0x93, 0x50, 0x20, 0x5f, 0x7c, 0x47,
+	0xa7, 0xbc, 0x13, 0x2b, 0x84, 0x7d, 0xe2, 0x27, 0x50, 0x8c, 0x21, 0x4c, 0x83, 0x41, 0xe2, 0x86,
+	0x01, 0x6f, 0xb5, 0x0a, 0x59, 0x90, 0x64, 0xeb, 0x79, 0xe9, 0x89, 0x96, 0x5a, 0x2c, 0xc6, 0x57,
+	0x48, 0xca, 0xad, 0x1f, 0x4d, 0x8d, 0x88, 0xbe, 0x75, 0x3f, 0xc0, 0x39, 0xc3, 0x64, 0x63, 0x5f,
+	0xc2, 0x91, 0x40, 0x2b, 0xb6, 0x36, 0xd6, 0x6c, 0x19, 0xa8, 0x06, 0xb6, 0x66, 0x6d, 0x8d, 0x0f,
+	0x50, 0x35, 0x72, 0x82, 0x11, 0xd5, 0x53, 0xff, 0x8a, 0x46, 0xdc, 0xd8, 0x0e, 0x59, 0x14, 0xdd,
+	0xc6, 0x5e, 0x8e, 0x50, 0xd9, 0x48, 0x6f, 0x8c, 0xfd, 0x1e, 0xda, 0xbc, 0x76, 0xbc, 0x94, 0x0a,
+	0x2a, 0x19, 0x58, 0xb1, 0x59, 0xbc, 0x25, 0x23, 0xa5, 0xb5, 0x28, 0x20, 0xdf, 0x1d, 0xea, 0xfd,
+	0x5f, 0xf9, 0xfe, 0xa3, 0x80, 0x76, 0xe7, 0x09, 0x27, 0x2c, 0x17, 0x50, 0xea, 0x65, 0x48, 0x58,
+	0x94, 0x9c, 0xcd, 0x5c, 0xcd, 0x30, 0x3b, 0x0b, 0x1a, 0x0c, 0xd9, 0x4a, 0xe6, 0x4b, 0xa0, 0x8f,
+	0x8d, 0x92, 0xe5, 0xce, 0x73, 0x7d, 0x37, 0xe1, 0x63, 0x73, 0x93, 0x64, 0x40, 0x3e, 0x86, 0xca,
+	0xa6, 0xc9, 0xeb, 0x94, 0x46, 0x53, 0x42, 0xe3, 0xd4, 0x4b, 0x98, 0xde, 0x7b, 0x06, 0x05, 0xb1,
+	0x0c, 0xdc, 0x7a, 0x6e, 0x1d, 0x5e, 0x55, 0xa7, 0x6e, 0x9c, 0x84, 0xd1, 0xf4, 0x38, 0x8c, 0x18,
+	0xe3, 0x1b, 0x72, 0xf9, 0x0f, 0xf5, 0x74, 0x80, 0xea, 0x9c, 0x0a, 0x4f, 0x96, 0x4e, 0x3f, 0x24,
+	0x30, 0x14, 0x37, 0x60, 0xa4, 0x66, 0x26, 0xe0, 0x4b, 0x7e, 0x86, 0x76, 0xe7, 0x1a, 0x8a, 0x17,
+	0xc6, 0x74, 0x4d, 0xe5, 0x5b, 0x24, 0x2d, 0xc4, 0x73, 0x34, 0x4d, 0x68, 0xcc, 0x0b, 0x73, 0x0e,
+	0xb9, 0x72, 0x8d, 0x2c, 0x8a, 0xe4, 0x00, 0xed, 0xe4, 0xbb, 0x26, 0x61, 0x00, 0x66, 0x0f, 0xd1,
+	0x76, 0xb6, 0xce, 0xd4, 0x8b, 0x70, 0x5b, 0x34, 0xf2, 0xdb, 0x62, 0xd5, 0x3a, 0xc9, 0x15, 0xf1,
+	0x43, 0x54, 0x1e, 0x3b, 0xb1, 0xed, 0xc3, 0x1d, 0xcd, 0xa3, 0x2b, 0x93, 0x6d, 0xc0, 0x17, 0x00,
+	0x05, 0xcb, 0xe2, 0x8c, 0xe5, 0x2f, 0x05, 0x54, 0xeb, 0xa6, 0x09, 0xbf, 0x79, 0x14, 0xb8, 0xdd,
+	0xb1, 0x3c, 0xc7, 0xba, 0xe3, 0x53, 0x11, 0xd0, 0x92, 0x8c, 0xd5, 0x86, 0x4f, 0x93, 0x71, 0x38,
+	0xcc, 0x6b, 0x23, 0x43, 0xbc, 0x7f, 0x9d, 0xc8, 0xf1, 0x63, 0x51, 0x7f, 0x02, 0xad, 0x9c, 0x5a,
+	0x69, 0xed, 0xd4, 0x5e, 0xf2, 0xfe, 0x55, 0x68, 0xf4, 0x6f, 0xa7, 0x8d, 0xfc, 0x5b, 0x01, 0x95,
+	0x4d, 0xa8, 0xd0, 0x57, 0x29, 0x14, 0x09, 0x5c, 0xe5, 0x7e, 0x3c, 0xb2, 0xf2, 0xa7, 0xc3, 0x0e,
+	0xc9, 0x21, 0x7e, 0x8e, 0xea, 0x70, 0xcc, 0x2c, 0x49, 0xec, 0xe1, 0xc2, 0x6e, 0xe0, 0xec, 0xf0,
+	0x57, 0xa4, 0xac, 0x31, 0xfc, 0x69, 0x3b, 0x88, 0x7f, 0x86, 0xe9, 0x91, 0x85, 0x32, 0xc3, 0xb7,
+	0x06, 0xf3, 0x2b, 0x50, 0x21, 0x74, 0x70, 0xfd, 0x1f, 0x51, 0x01, 0x0b, 0xec, 0x91, 0x12, 0xa6,
+	0x09, 0x67, 0x02, 0x16, 0x04, 0xbc, 0x95, 0x48, 0x9f, 0x37, 0xbc, 0x15, 0xbe, 0xa3, 0xc1, 0x91,
+	0xe3, 0x39, 0xc1, 0x80, 0x32, 0x63, 0xce, 0x70, 0x08, 0x65, 0x12, 0x8b, 0xe4, 0xe6, 0x90, 0xb5,
+	0x9b, 0x13, 0xc7, 0x34, 0x11, 0xb9, 0xcd, 0x00, 0x3b, 0xd0, 0x31, 0x75, 0x47, 0xe3, 0xcc, 0x77,
+	0x89, 0x08, 0x24, 0xbf, 0x47, 0x55, 0xc3, 0x99, 0x42, 0x4d, 0x70, 0xeb, 0xf3, 0xcd, 0x59, 0x41,
+	0xcf, 0x37, 0x3b, 0x7e, 0x98, 0x8a, 0xc8, 0x60, 0x73, 0x86, 0x58, 0x72, 0xbd, 0x70, 0xf0, 0x8e,
+	0x85, 0x21, 0x42, 0x9a, 0xe1, 0x45, 0x82, 0xa5, 0x25, 0x82, 0xf2, 0x4f, 0xa8, 0xda, 0x81, 0xcb,
+	0x20, 0xa0, 0x99, 0xcb, 0x47, 0xf0, 0xd0, 0x63, 0x1f, 0xb3, 0xd4, 0x6e, 0x92, 0xb9, 0x80, 0xb9,
+	0x1e, 0x72, 0x65, 0x91, 0x54, 0x81, 0x98, 0xf9, 0x41, 0x44, 0x1d, 0x18, 0x0e, 0xa2, 0x05, 0x72,
+	0x28, 0xa7, 0xa8, 0x6a, 0xa6, 0x93, 0x89, 0x37, 0xcd, 0xcc, 0x33, 0x1e, 0x2c, 0x08, 0x6d, 0x28,
+	0x62, 0xca, 0x21, 0x63, 0x9f, 0x06, 0x2e, 0x4c, 0xa3, 0xd9, 0xe4, 0x98, 0xe1, 0x85, 0x88, 0x8b,
+	0x4b, 0x11, 0x2f, 0xb8, 0x2d, 0x2d, 0xbb, 0x7d, 0x8e, 0x30, 0x8c, 0x28, 0x18, 0x54, 0xe6, 0x34,
+	0x4e, 0x28, 0x7b, 0x4e, 0xbf, 0x75, 0x47, 0xeb, 0xf5, 0x2f, 0x9f, 0xc2, 0x0b, 0x68, 0xe5, 0xb6,
+	0x3c, 0xbb, 0xfe, 0x9b, 0x3e, 0x59, 0x19, 0x30, 0x1b, 0xeb, 0x03, 0xe6, 0x3d, 0xaa, 0x6b, 0xc1,
+	0x35, 0x04, 0x99, 0x3f, 0xdd, 0x57, 0xa6, 0x61, 0x61, 0x6d, 0x62, 0xc3, 0xfb, 0xd7, 0x89, 0x46,
+	0xcc, 0x58, 0x11, 0x56, 0xf8, 0x37, 0xfe, 0x02, 0x6d, 0xf1, 0x6c, 0xb3, 0x4e, 0x67, 0x43, 0x69,
+	0x2f, 0x1f, 0x4a, 0x0b, 0x65, 0x41, 0x84, 0x8a, 0xfc, 0x39, 0xc2, 0x30, 0x3b, 0xa2, 0x11, 0xd5,
+	0x82, 0x38, 0x89, 0x52, 0x5e, 0x9d, 0xbc, 0xe2, 0x06, 0x3c, 0x57, 0x05, 0x9e, 0xab, 0x0c, 0x1c,
+	0xbe, 0x59, 0xf8, 0x1f, 0xc0, 0x0e, 0x24, 0x8c, 0x12, 0xdc, 0x61, 0x0d, 0x35, 0x82, 0x99, 0x0e,
+	0xdd, 0xd7, 0xb8, 0xe9, 0x5f, 0xc0, 0xfe, 0x8d, 0x2b, 0xf2, 0x9d, 0x17, 0x85, 0xaf, 0x0a, 0x47,
+	0x5d, 0x54, 0x15, 0x0a, 0xec, 0x1f, 0xca, 0x0f, 0x2f, 0x3f, 0xf6, 0x3f, 0xce, 0x55, 0xf6, 0x2f,
+	0xec, 0x9b, 0xbf, 0x00, 0xc1, 0xe6, 0xd3, 0xd9, 0xa4, 0x0d, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -21,7 +21,6 @@
 package common
 
 import (
-	"encoding/binary"
 	"time"
 
 	"github.com/palletone/go-palletone/common"
@@ -178,7 +177,7 @@ func (pRep *PropRepository) UpdateMediatorSchedule() bool {
 	//}
 
 	// 4. 打乱证人的调度顺序
-	shuffleMediators(ms.CurrentShuffledMediators, binary.BigEndian.Uint64(hash[8:]))
+	modules.ShuffleMediators(ms.CurrentShuffledMediators, modules.ShuffleSeed(hash))
 
 	err = pRep.StoreMediatorSchl(ms)
 	if err != nil {
@@ -189,26 +188,6 @@ func (pRep *PropRepository) UpdateMediatorSchedule() bool {
 	return true
 }
 
-func shuffleMediators(mediators []common.Address, seed uint64) {
-	nowHi := seed << 32
-	aSize := len(mediators)
-	for i := 0; i < aSize; i++ {
-		// 高性能随机生成器(High performance random generator)
-		// 原理请参考 http://xorshift.di.unimi.it/
-		k := nowHi + uint64(i)*2685821657736338717
-		k ^= k >> 12
-		k ^= k << 25
-		k ^= k >> 27
-		k *= 2685821657736338717
-
-		jmax := uint64(aSize - i)
-		j := uint64(i) + k%jmax
-
-		// 进行N次随机交换
-		mediators[i], mediators[j] = mediators[j], mediators[i]
-	}
-}
-
 /**
 @brief 获取给定的未来第slotNum个slot开始的时间。
 Get the time at which the given slot occurs.
@@ -235,36 +214,13 @@ func (pRep *PropRepository) GetSlotTime(slotNum uint32) time.Time {
 		return time.Unix(0, 0)
 	}
 
-	interval := gp.ChainParameters.MediatorInterval
 	gasToken := dagconfig.DagConfig.GetGasToken()
 	_, idx, ts, _ := pRep.db.GetNewestUnit(gasToken)
-	// 本条件是用来生产第一个unit
-	if idx.Index == 0 {
-		/**
-		注：第一个unit的生产时间是在genesisTime加上一个unit生产间隔
-		n.b. first Unit is at genesisTime plus one UnitInterval
-		*/
-		genesisTime := ts
-		return time.Unix(genesisTime+int64(slotNum)*int64(interval), 0)
-	}
-
-	// 最近的unit的绝对slot
-	var unitAbsSlot = ts / int64(interval)
-	// 最近的时间槽起始时间
-	headSlotTime := time.Unix(unitAbsSlot*int64(interval), 0)
-
-	// 添加区块链网络参数修改维护时所需跳过的slot
-	if dgp.MaintenanceFlag {
-		slotNum += uint32(gp.ChainParameters.MaintenanceSkipSlots)
-	}
-
-	// 如果是维护周期的话，加上维护间隔时间
-	// 如果不是，就直接加上unit的slot时间
-	// "slot 0" is headSlotTime
-	// "slot 1" is headSlotTime,
-	// plus maintenance interval if head uint is a maintenance Unit
-	// plus Unit interval if head uint is not a maintenance Unit
-	return headSlotTime.Add(time.Second * time.Duration(slotNum) * time.Duration(interval))
+	// 第一个unit的生产时间是在创世时间加上一个unit生产间隔，
+	// 之后的slot从最近的unit所在的slot开始计算，维护之后需要跳过一些slot
+	cp := gp.ChainParameters
+	return time.Unix(modules.SlotTime(ts, idx.Index, cp.MediatorInterval, cp.MaintenanceSkipSlots,
+		dgp.MaintenanceFlag, slotNum), 0)
 }
 
 /**
@@ -276,11 +232,11 @@ func (pRep *PropRepository) GetSlotAtTime(when time.Time) uint32 {
 		log.Debugf("Retrieve Global Prop error: %v", err.Error())
 		return 0
 	}
-	//dgp, err := pRep.RetrieveDynGlobalProp()
-	//if err != nil {
-	//	log.Debugf("Retrieve Dyn Global Prop error: %v", err.Error())
-	//	return 0
-	//}
+	dgp, err := pRep.RetrieveDynGlobalProp()
+	if err != nil {
+		log.Debugf("Retrieve Dyn Global Prop error: %v", err.Error())
+		return 0
+	}
 
 	/**
 	返回值是所有满足 GetSlotTime（N）<= when 中最大的N
@@ -288,18 +244,11 @@ func (pRep *PropRepository) GetSlotAtTime(when time.Time) uint32 {
 	如果都不满足，则返回 0
 	If no such N exists, return 0.
 	*/
-	firstSlotTime := pRep.GetSlotTime(1)
-
-	if when.Before(firstSlotTime) {
-		return 0
-	}
-
-	diffSecs := when.Unix() - firstSlotTime.Unix()
-	interval := int64(gp.ChainParameters.MediatorInterval)
-	if interval == 0 {
-		return 0
-	}
-	return uint32(diffSecs/interval) + 1
+	gasToken := dagconfig.DagConfig.GetGasToken()
+	_, idx, ts, _ := pRep.db.GetNewestUnit(gasToken)
+	cp := gp.ChainParameters
+	return modules.SlotAtTime(ts, idx.Index, cp.MediatorInterval, cp.MaintenanceSkipSlots, dgp.MaintenanceFlag,
+		when.Unix())
 }
 
 /**
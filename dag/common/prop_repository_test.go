@@ -79,7 +79,7 @@ func TestShuffle(t *testing.T) {
 
 	for i := 0; i < 10; i++ {
 		addrs := []common.Address{addr1, addr2, addr3, addr4, addr5}
-		shuffleMediators(addrs, uint64(i))
+		modules.ShuffleMediators(addrs, uint64(i))
 		addrJs, _ := json.Marshal(addrs)
 		t.Logf("i:%d,addr:%s", i, addrJs)
 	}
@@ -38,6 +38,9 @@ type IStateRepository interface {
 	SaveContractState(id []byte, w *modules.ContractWriteSet, version *modules.StateVersion) error
 	GetContractStatesById(id []byte) (map[string]*modules.ContractStateValue, error)
 	GetContractStatesByPrefix(id []byte, prefix string) (map[string]*modules.ContractStateValue, error)
+	GetContractStatesByRange(id []byte, startKey, endKey string, limit int) ([]*modules.ContractStateKV, error)
+	GetStateRoot() (common.Hash, error)
+	GetContractStateProof(root common.Hash, contractId []byte, field string) (common.Hash, [][]byte, [][]byte, error)
 
 	GetContract(id []byte) (*modules.Contract, error)
 	GetAllContracts() ([]*modules.Contract, error)
@@ -79,6 +82,10 @@ type IStateRepository interface {
 	GetMainChain() (*modules.MainChain, error)
 	//获得一个合约的陪审团列表
 	GetContractJury(contractId []byte) (*modules.ElectionNode, error)
+	//获得陪审员执行合约的统计
+	GetJurorStats(address common.Address) (*modules.JurorStats, error)
+	GetAllJurorStats() ([]*modules.JurorStats, error)
+	SaveJurorStats(stats *modules.JurorStats) error
 	GetAllContractTpl() ([]*modules.ContractTemplate, error)
 	GetDataVersion() (*modules.DataVersion, error)
 	StoreDataVersion(dv *modules.DataVersion) error
@@ -107,6 +114,15 @@ func (rep *StateRepository) GetContractState(id []byte, field string) ([]byte, *
 	return rep.statedb.GetContractState(id, field)
 }
 
+func (rep *StateRepository) GetStateRoot() (common.Hash, error) {
+	return rep.statedb.GetStateRoot()
+}
+
+func (rep *StateRepository) GetContractStateProof(root common.Hash, contractId []byte, field string) (
+	common.Hash, [][]byte, [][]byte, error) {
+	return rep.statedb.GetContractStateProof(root, contractId, field)
+}
+
 func (rep *StateRepository) SaveSysConfigContract(key string, val []byte, ver *modules.StateVersion) error {
 	return rep.statedb.SaveSysConfigContract(key, val, ver)
 }
@@ -130,6 +146,11 @@ func (rep *StateRepository) GetContractStatesByPrefix(id []byte,
 	return rep.statedb.GetContractStatesByPrefix(id, prefix)
 }
 
+func (rep *StateRepository) GetContractStatesByRange(id []byte, startKey, endKey string,
+	limit int) ([]*modules.ContractStateKV, error) {
+	return rep.statedb.GetContractStatesByRange(id, startKey, endKey, limit)
+}
+
 func (rep *StateRepository) GetContract(id []byte) (*modules.Contract, error) {
 	return rep.statedb.GetContract(id)
 }
@@ -415,6 +436,19 @@ func (rep *StateRepository) GetAccountState(address common.Address, statekey str
 func (rep *StateRepository) GetContractJury(contractId []byte) (*modules.ElectionNode, error) {
 	return rep.statedb.GetContractJury(contractId)
 }
+
+//获得陪审员执行合约的统计
+func (rep *StateRepository) GetJurorStats(address common.Address) (*modules.JurorStats, error) {
+	return rep.statedb.GetJurorStats(address)
+}
+
+func (rep *StateRepository) GetAllJurorStats() ([]*modules.JurorStats, error) {
+	return rep.statedb.GetAllJurorStats()
+}
+
+func (rep *StateRepository) SaveJurorStats(stats *modules.JurorStats) error {
+	return rep.statedb.SaveJurorStats(stats)
+}
 func (rep *StateRepository) GetAllContractTpl() ([]*modules.ContractTemplate, error) {
 	return rep.statedb.GetAllContractTpl()
 }
@@ -104,6 +104,8 @@ func (rep *UnitProduceRepository) SubscribeChainMaintenanceEvent(ob AfterChainMa
 type voteTally struct {
 	candidate  common.Address
 	votedCount uint64
+	// 连续错过太多slot而被停用，不能成为活跃mediator
+	deactivated bool
 }
 
 func newVoteTally(candidate common.Address) *voteTally {
@@ -120,6 +122,10 @@ func (vts voteTallys) Len() int {
 }
 
 func (vts voteTallys) Less(i, j int) bool {
+	if vts[i].deactivated != vts[j].deactivated {
+		return vts[j].deactivated
+	}
+
 	mVoteI := vts[i].votedCount
 	mVoteJ := vts[j].votedCount
 
@@ -216,6 +222,7 @@ func (rep *UnitProduceRepository) updateMediatorMissedUnits(unit *modules.Unit)
 
 	aSize := rep.GetGlobalProp().ActiveMediatorsCount()
 	if missedUnits < uint32(aSize) {
+		dgp := rep.GetDynGlobalProp()
 		var i uint32
 		for i = 0; i < missedUnits; i++ {
 			mediatorMissed := rep.propRep.GetScheduledMediator(i + 1)
@@ -223,7 +230,12 @@ func (rep *UnitProduceRepository) updateMediatorMissedUnits(unit *modules.Unit)
 			med := rep.GetMediator(mediatorMissed)
 			med.TotalMissed++
 			rep.stateRep.StoreMediator(med)
+
+			// 记录连续错过的slot数量，用于停用长期不出块的mediator
+			count := dgp.AddMissedSlot(mediatorMissed)
+			log.Debugf("mediator(%v) has missed %v consecutive slots", mediatorMissed.Str(), count)
 		}
+		rep.propRep.StoreDynGlobalProp(dgp)
 	}
 
 	return uint64(missedUnits)
@@ -241,6 +253,7 @@ func (rep *UnitProduceRepository) updateDynGlobalProp(unit *modules.Unit, missed
 
 	dgp.LastMediator = unit.Author()
 	dgp.IsShuffledSchedule = false
+	dgp.ResetMissedSlots(unit.Author())
 	dgp.RecentSlotsFilled = (dgp.RecentSlotsFilled << (missedUnits + 1)) + 1
 	dgp.CurrentASlot += missedUnits + 1
 
@@ -309,6 +322,9 @@ func (dag *UnitProduceRepository) performChainMaintenance(nextUnit *modules.Unit
 	// 统计投票并更新活跃 mediator 列表
 	isChanged := dag.updateActiveMediators()
 
+	// 根据陪审员的信誉更新不参与选举的陪审员
+	dag.updateExcludedJurors()
+
 	// 更新要修改的区块链参数
 	dag.updateChainParameters(nextUnit)
 
@@ -493,15 +509,51 @@ func (dag *UnitProduceRepository) performAccountMaintenance() {
 	// 遍历所有账户
 	mediatorVoteCount, _ := dag.stateRep.GetMediatorVotedResults()
 
+	// 连续错过太多slot的mediator，本次维护中被移出活跃mediator集合，计数清零，下次维护可以重新参选
+	dgp := dag.GetDynGlobalProp()
+	maxMissed := dag.GetGlobalProp().ChainParameters.MaxConsecutiveMissedSlots
+	deactivated := make(map[common.Address]bool)
+	for mediator := range mediators {
+		if maxMissed > 0 && dgp.GetMissedSlots(mediator) >= maxMissed {
+			log.Infof("mediator(%v) missed too many consecutive slots, deactivate it", mediator.Str())
+			deactivated[mediator] = true
+			dgp.ResetMissedSlots(mediator)
+		}
+	}
+	if len(deactivated) > 0 {
+		dag.propRep.StoreDynGlobalProp(dgp)
+	}
+
 	// 初始化 mediator 的投票数据
 	for mediator := range mediators {
 
 		voteTally := newVoteTally(mediator)
 		voteTally.votedCount = mediatorVoteCount[mediator.Str()]
+		voteTally.deactivated = deactivated[mediator]
 		dag.mediatorVoteTally = append(dag.mediatorVoteTally, voteTally)
 	}
 }
 
+// 陪审员是否被排除只在维护时更新，保证各节点在两次维护之间的选举结果一致
+func (dag *UnitProduceRepository) updateExcludedJurors() {
+	allStats, err := dag.stateRep.GetAllJurorStats()
+	if err != nil {
+		log.Warnf("get all juror stats error: %v", err.Error())
+		return
+	}
+	for _, stats := range allStats {
+		excluded := stats.Reputation() < core.DefaultMinJurorReputation
+		if excluded == stats.Excluded {
+			continue
+		}
+		log.Infof("juror(%v) reputation is %v, excluded: %v", stats.Address.Str(), stats.Reputation(), excluded)
+		stats.Excluded = excluded
+		if err := dag.stateRep.SaveJurorStats(stats); err != nil {
+			log.Warnf("save juror(%v) stats error: %v", stats.Address.Str(), err.Error())
+		}
+	}
+}
+
 func (dag *UnitProduceRepository) updateActiveMediators() bool {
 	// 1. 统计出活跃mediator数量n
 	//maxFn := func(x, y int) int {
@@ -526,6 +578,9 @@ func (dag *UnitProduceRepository) updateActiveMediators() bool {
 	log.Debugf("the desired mediator count is %v, the actual mediator count is %v,"+
 		" the minimum mediator count is %v", mediatorCount, mediatorLen, minMediatorCount)
 
+	// 被停用的mediator不参与排名，候选人不足时相应减少活跃mediator的数量
+	mediatorCount = adjustActiveMediatorCount(dag.mediatorVoteTally, mediatorCount, int(minMediatorCount))
+
 	// 2. 根据每个mediator的得票数，排序出前n个 active mediator
 	log.Debugf("In this round, The active mediator's count is %v", mediatorCount)
 	if dag.mediatorVoteTally.Len() > 0 {
@@ -559,6 +614,35 @@ func (dag *UnitProduceRepository) updateActiveMediators() bool {
 	return true
 }
 
+// 可参选的mediator不足desired个时，活跃mediator的数量减少为不超过候选人数量的最大奇数，
+// 但不能少于minCount，此时由排在最后的被停用mediator补足
+func adjustActiveMediatorCount(vts voteTallys, desired, minCount int) int {
+	candidates := 0
+	for _, vt := range vts {
+		if !vt.deactivated {
+			candidates++
+		}
+	}
+	if candidates >= desired {
+		return desired
+	}
+
+	count := (candidates-1)/2*2 + 1
+	if count < minCount {
+		count = minCount
+	}
+	if count > desired {
+		count = desired
+	}
+	if count > len(vts) {
+		count = len(vts)
+	}
+
+	log.Warnf("only %v mediators can be active, the desired count is %v, the active count is adjusted to %v",
+		candidates, desired, count)
+	return count
+}
+
 func (d *UnitProduceRepository) getDesiredActiveMediatorCount() int {
 	// 获取之前的设置
 	//activeMediatorStr, _, _ := d.stateRep.GetConfig(modules.DesiredActiveMediatorCount)
@@ -593,32 +677,9 @@ func (dag *UnitProduceRepository) updateNextMaintenanceTime(nextUnit *modules.Un
 	dgp := dag.GetDynGlobalProp()
 	gp := dag.GetGlobalProp()
 
-	nextMaintenanceTime := dgp.NextMaintenanceTime
 	maintenanceInterval := int64(gp.ChainParameters.MaintenanceInterval)
-
-	if nextUnit.NumberU64() == 1 {
-		// 对第一个unit之后的特殊换届，进行调整，让其回到普通换届时间来
-		nextMaintenanceTime = uint32((nextUnit.Timestamp()/maintenanceInterval + 1) * maintenanceInterval)
-	} else {
-		// We want to find the smallest k such that nextMaintenanceTime + k * maintenanceInterval > HeadUnitTime()
-		//  This implies k > ( HeadUnitTime() - nextMaintenanceTime ) / maintenanceInterval
-		//
-		// Let y be the right-hand side of this inequality, i.e.
-		// y = ( HeadUnitTime() - nextMaintenanceTime ) / maintenanceInterval
-		//
-		// and let the fractional part f be y-floor(y).  Clearly 0 <= f < 1.
-		// We can rewrite f = y-floor(y) as floor(y) = y-f.
-		//
-		// Clearly k = floor(y)+1 has k > y as desired.  Now we must
-		// show that this is the least such k, i.e. k-1 <= y.
-		//
-		// But k-1 = floor(y)+1-1 = floor(y) = y-f <= y.
-		// So this k suffices.
-		//
-
-		y := (dag.HeadUnitTime() - int64(nextMaintenanceTime)) / maintenanceInterval
-		nextMaintenanceTime += uint32((y + 1) * maintenanceInterval)
-	}
+	nextMaintenanceTime := modules.NextMaintenanceTime(nextUnit.NumberU64(), dag.HeadUnitTime(),
+		dgp.NextMaintenanceTime, maintenanceInterval)
 
 	dgp.LastMaintenanceTime = dgp.NextMaintenanceTime
 	dgp.NextMaintenanceTime = nextMaintenanceTime
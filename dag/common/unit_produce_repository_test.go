@@ -123,3 +123,26 @@ func Test_UnitProduceRepository_UpdateSysParams(t *testing.T) {
 		t.Log("update sysParams success")
 	}
 }
+
+func Test_adjustActiveMediatorCount(t *testing.T) {
+	newTallys := func(total, deactivated int) voteTallys {
+		vts := make(voteTallys, 0, total)
+		for i := 0; i < total; i++ {
+			vts = append(vts, &voteTally{deactivated: i < deactivated})
+		}
+		return vts
+	}
+
+	// 候选人足够时保持期望的数量
+	if count := adjustActiveMediatorCount(newTallys(7, 2), 5, 3); count != 5 {
+		t.Errorf("expect 5 active mediators, got %v", count)
+	}
+	// 被停用的mediator不再计入，活跃数量减为奇数
+	if count := adjustActiveMediatorCount(newTallys(5, 1), 5, 3); count != 3 {
+		t.Errorf("expect 3 active mediators, got %v", count)
+	}
+	// 不能少于最小数量
+	if count := adjustActiveMediatorCount(newTallys(3, 1), 3, 3); count != 3 {
+		t.Errorf("expect 3 active mediators, got %v", count)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/common/ptndb"
 	"github.com/palletone/go-palletone/core"
@@ -53,7 +54,7 @@ type IUnitRepository interface {
 	GetGenesisUnit() (*modules.Unit, error)
 	//GenesisHeight() modules.ChainIndex
 	SaveUnit(unit *modules.Unit, isGenesis bool) error
-	CreateUnit(mAddr common.Address, txpool txspool.ITxPool, propdb IPropRepository, t time.Time) (*modules.Unit, error)
+	CreateUnit(med *core.Mediator, txpool txspool.ITxPool, propdb IPropRepository, t time.Time) (*modules.Unit, error)
 	IsGenesis(hash common.Hash) bool
 	GetAddrTransactions(addr common.Address) ([]*modules.TransactionWithUnitInfo, error)
 	GetHeaderByHash(hash common.Hash) (*modules.Header, error)
@@ -104,6 +105,7 @@ type IUnitRepository interface {
 	SubscribeSysContractStateChangeEvent(ob AfterSysContractStateChangeEventFunc)
 	SaveCommon(key, val []byte) error
 	RebuildAddrTxIndex() error
+	GetContractStateHistory(contractId []byte, key string, from, to uint64) ([]*modules.ContractStateHistory, error)
 }
 type UnitRepository struct {
 	dagdb          storage.IDagDb
@@ -422,15 +424,16 @@ func GetUnitWithSig(unit *modules.Unit, ks *keystore.KeyStore, signer common.Add
 /**
 创建单元
 create common unit
-@param mAddr is minner addr
+@param med is minner
 return: correct if error is nil, and otherwise is incorrect
 */
-func (rep *UnitRepository) CreateUnit(mAddr common.Address, txpool txspool.ITxPool,
+func (rep *UnitRepository) CreateUnit(med *core.Mediator, txpool txspool.ITxPool,
 	propdb IPropRepository, t time.Time) (*modules.Unit, error) {
 	log.Debug("create unit lock unitRepository.")
 	rep.lock.RLock()
 	defer rep.lock.RUnlock()
 	begin := time.Now()
+	mAddr := med.GetRewardAdd()
 
 	// step1. get mediator responsible for asset (for now is ptn)
 	assetId := dagconfig.DagConfig.GetGasToken()
@@ -481,10 +484,16 @@ func (rep *UnitRepository) CreateUnit(mAddr common.Address, txpool txspool.ITxPo
 		return nil, err
 	}
 
-	//出块奖励
-	rewardAd := rep.ComputeGenerateUnitReward(mAddr, assetId.ToAsset())
-	if rewardAd != nil && rewardAd.Amount > 0 {
-		ads = append(ads, rewardAd)
+	//出块奖励，按佣金比例与委托人分享
+	rewardAds, err := rep.ComputeGenerateUnitReward(med, assetId.ToAsset())
+	if err != nil {
+		log.Errorf("CreateUnit, ComputeGenerateUnitReward is failed, error:%s", err.Error())
+		return nil, err
+	}
+	for _, rewardAd := range rewardAds {
+		if rewardAd.Amount > 0 {
+			ads = append(ads, rewardAd)
+		}
 	}
 
 	outAds := arrangeAdditionFeeList(ads)
@@ -529,6 +538,22 @@ func (rep *UnitRepository) CreateUnit(mAddr common.Address, txpool txspool.ITxPo
 	// step9. generate genesis unit header
 	header.TxsIllegal = illegalTxs
 	header.TxRoot = root
+	// 父单元之后的UTXO集合的承诺
+	if cp := propdb.GetChainParameters(); cp != nil && cp.IsUtxoCommitmentEnabled(chainIndex.Index) {
+		header.UtxoRoot, err = rep.utxoRepository.GetUtxoRoot()
+		if err != nil {
+			log.Errorf("CreateUnit, GetUtxoRoot failed, error:%s", err.Error())
+			return nil, err
+		}
+	}
+	// 父单元之后的合约状态的承诺
+	if cp := propdb.GetChainParameters(); cp != nil && cp.IsStateCommitmentEnabled(chainIndex.Index) {
+		header.StateRoot, err = rep.statedb.GetStateRoot()
+		if err != nil {
+			log.Errorf("CreateUnit, GetStateRoot failed, error:%s", err.Error())
+			return nil, err
+		}
+	}
 	unit := &modules.Unit{}
 	unit.UnitHeader = &header
 	unit.UnitHash = header.Hash()
@@ -557,6 +582,28 @@ func checkReadSetValid(dag storage.IStateDb, contractId []byte, readSet []module
 	return true
 }
 
+//重新执行范围查询，区间内的key有新增、删除或者版本变化时读集失效
+func checkRangeReadSetValid(dag storage.IStateDb, contractId []byte,
+	rangeReadSet []modules.ContractRangeReadSet) bool {
+	for _, rr := range rangeReadSet {
+		cid := rr.ContractId
+		if len(cid) == 0 {
+			cid = contractId
+		}
+		states, err := dag.GetContractStatesByRange(cid, rr.StartKey, rr.EndKey, 0)
+		if err != nil {
+			log.Debugf("checkRangeReadSetValid, GetContractStatesByRange fail, contractId[%x], err:%s",
+				cid, err.Error())
+			return false
+		}
+		if modules.HashContractStates(states) != rr.ResultHash {
+			log.Debugf("checkRangeReadSetValid, range [%s,%s) of contract[%x] changed", rr.StartKey, rr.EndKey, cid)
+			return false
+		}
+	}
+	return true
+}
+
 func markTxIllegal(dag storage.IStateDb, tx *modules.Transaction) {
 	if tx == nil {
 		return
@@ -568,6 +615,7 @@ func markTxIllegal(dag storage.IStateDb, tx *modules.Transaction) {
 		return
 	}
 	var readSet []modules.ContractReadSet
+	var rangeReadSet []modules.ContractRangeReadSet
 	var contractId []byte
 
 	for _, msg := range tx.TxMessages {
@@ -579,6 +627,7 @@ func markTxIllegal(dag storage.IStateDb, tx *modules.Transaction) {
 		case modules.APP_CONTRACT_INVOKE:
 			payload := msg.Payload.(*modules.ContractInvokePayload)
 			readSet = payload.ReadSet
+			rangeReadSet = payload.RangeReadSet
 			contractId = payload.ContractId
 		case modules.APP_CONTRACT_STOP:
 			payload := msg.Payload.(*modules.ContractStopPayload)
@@ -586,7 +635,8 @@ func markTxIllegal(dag storage.IStateDb, tx *modules.Transaction) {
 			contractId = payload.ContractId
 		}
 	}
-	valid := checkReadSetValid(dag, contractId, readSet)
+	valid := checkReadSetValid(dag, contractId, readSet) &&
+		checkRangeReadSetValid(dag, contractId, rangeReadSet)
 	tx.Illegal = !valid
 }
 
@@ -621,14 +671,24 @@ func (rep *UnitRepository) ComputeTxFeesAllocate(m common.Address, txs []*module
 	return ads, nil
 }
 
-//,Mediator奖励
-func (rep *UnitRepository) ComputeGenerateUnitReward(m common.Address, asset *modules.Asset) *modules.Addition {
-	a := &modules.Addition{
-		Addr:   m,
-		Amount: ComputeGenerateUnitReward(),
-		Asset:  asset,
+//,Mediator奖励，扣除佣金后按委托数量分给委托人
+func (rep *UnitRepository) ComputeGenerateUnitReward(med *core.Mediator, asset *modules.Asset) (
+	[]*modules.Addition, error) {
+	states, err := rep.statedb.GetContractStatesByPrefix(syscontract.DepositContractAddress.Bytes(),
+		modules.MediatorDelegationKeyPrefix(med.Address.String()))
+	if err != nil {
+		if !errors.IsNotFoundError(err) {
+			return nil, err
+		}
+		//没有任何委托
+		states = nil
 	}
-	return a
+	delegations, err := modules.ParseMediatorDelegations(states)
+	if err != nil {
+		return nil, err
+	}
+	return modules.SplitMediatorReward(med.GetRewardAdd(), med.CommissionRate, ComputeGenerateUnitReward(),
+		asset, delegations), nil
 }
 
 //获取保证金利息
@@ -862,6 +922,15 @@ func (rep *UnitRepository) SaveUnit(unit *modules.Unit, isGenesis bool) error {
 		//log.Debugf("save transaction, hash[%s] tx_index[%d]", tx.Hash().String(), txIndex)
 		txHashSet = append(txHashSet, tx.Hash())
 	}
+	// 单元的全部交易保存之后提交一次UTXO集合和合约状态的承诺
+	if err := rep.utxoRepository.CommitUtxoRoot(); err != nil {
+		log.Errorf("CommitUtxoRoot error:%s", err.Error())
+		return err
+	}
+	if err := rep.statedb.CommitStateRoot(); err != nil {
+		log.Errorf("CommitStateRoot error:%s", err.Error())
+		return err
+	}
 	// step3. save unit body, the value only save txs' hash set, and the key is merkle root
 	if err := rep.dagdb.SaveBody(uHash, txHashSet); err != nil {
 		log.Info("SaveBody", "error", err.Error())
@@ -960,10 +1029,20 @@ func (rep *UnitRepository) saveTx4Unit(unit *modules.Unit, txIndex int, tx *modu
 				msg.Payload.(*modules.DataPayload)); !ok {
 				return fmt.Errorf("save data payload faild.")
 			}
+		case modules.APP_MEDIATOR_EVIDENCE:
+			// 证据随交易一起保存，没收保证金由同一交易中的保证金合约调用完成
+		case modules.APP_JUROR_EVIDENCE:
+			if ok := rep.saveJurorEvidence(msg); !ok {
+				return fmt.Errorf("save juror evidence failed.")
+			}
 		default:
 			return fmt.Errorf("Message type is not supported now: %v", msg.App)
 		}
 	}
+	// 用户合约的执行结果，更新陪审员的统计
+	if reqIndex != -1 && !tx.Illegal && !tx.IsSystemContract() {
+		rep.updateJurorStats(tx)
+	}
 	// step6. save transaction
 	if err := rep.dagdb.SaveTransaction(tx); err != nil {
 		log.Info("Save transaction:", "error", err.Error())
@@ -973,8 +1052,58 @@ func (rep *UnitRepository) saveTx4Unit(unit *modules.Unit, txIndex int, tx *modu
 	if dagconfig.DagConfig.AddrTxsIndex {
 		rep.saveAddrTxIndex(txHash, tx)
 	}
+	if dagconfig.DagConfig.ContractStateHistory {
+		rep.saveContractStateHistory(unit, uint32(txIndex), tx)
+	}
 	return nil
 }
+
+//记录交易中合约状态的修改，非法交易没有修改状态
+func (rep *UnitRepository) saveContractStateHistory(unit *modules.Unit, txIndex uint32, tx *modules.Transaction) {
+	if tx.Illegal {
+		return
+	}
+	for _, msg := range tx.TxMessages {
+		var contractId []byte
+		var writeSet []modules.ContractWriteSet
+		switch payload := msg.Payload.(type) {
+		case *modules.ContractDeployPayload:
+			contractId, writeSet = payload.ContractId, payload.WriteSet
+		case *modules.ContractInvokePayload:
+			contractId, writeSet = payload.ContractId, payload.WriteSet
+		default:
+			continue
+		}
+		for _, write := range writeSet {
+			cid := contractId
+			if len(write.ContractId) != 0 {
+				cid = write.ContractId
+			}
+			history := &modules.ContractStateHistory{
+				TxId:      tx.Hash(),
+				UnitHash:  unit.Hash(),
+				Timestamp: uint64(unit.Timestamp()),
+				Version:   &modules.StateVersion{Height: unit.UnitHeader.Number, TxIndex: txIndex},
+				IsDelete:  write.IsDelete,
+			}
+			if !write.IsDelete {
+				history.Value = write.Value
+			}
+			if err := rep.idxdb.SaveContractStateHistory(cid, write.Key, history); err != nil {
+				log.Errorf("Save contract[%x] state history of key[%s] error:%s", cid, write.Key, err.Error())
+			}
+		}
+	}
+}
+
+//查询合约状态在高度[from, to]之间的修改历史，to为0表示不限制
+func (rep *UnitRepository) GetContractStateHistory(contractId []byte, key string,
+	from, to uint64) ([]*modules.ContractStateHistory, error) {
+	if !dagconfig.DagConfig.ContractStateHistory {
+		return nil, errors.New("Please enable ContractStateHistory in toml DagConfig")
+	}
+	return rep.idxdb.GetContractStateHistory(contractId, key, from, to)
+}
 func (rep *UnitRepository) saveAddrTxIndex(txHash common.Hash, tx *modules.Transaction) {
 
 	//Index TxId for to address
@@ -1365,6 +1494,111 @@ func (rep *UnitRepository) saveSignature(reqid []byte, msg *modules.Message) boo
 	return true
 }
 
+//根据结果交易中的签名，更新执行该请求的陪审团成员的参与和超时次数。
+//签名已合并到Payout的解锁脚本中，没有Payout的结果交易无法确定签名者，不做统计
+func (rep *UnitRepository) updateJurorStats(tx *modules.Transaction) {
+	reqId := tx.RequestHash()
+	signers, err := tx.GetContractTxSigners(rep.tokenEngine.GetScriptSigners)
+	if err != nil {
+		log.Warnf("[%s]get contract tx signers error:%s", reqId.String(), err.Error())
+		return
+	}
+	if len(signers) == 0 {
+		return
+	}
+	jury := rep.getRequestJury(tx)
+	if jury == nil || len(jury.EleList) == 0 {
+		log.Debugf("[%s]the jury of the request not found", reqId.String())
+		return
+	}
+	signed := make(map[common.Address]bool, len(signers))
+	for _, addr := range signers {
+		if jury.IsJuror(addr) {
+			signed[addr] = true
+		}
+	}
+	// 签名数达到门限后陪审团即完成执行，其余成员的签名不是必需的，不算超时；
+	// 只有签名数不足门限时，缺少签名的成员才视为超时
+	timeouts := make([]common.Address, 0)
+	if len(signed) < modules.JuryThreshold(len(jury.EleList)) {
+		for _, ele := range jury.EleList {
+			addr := crypto.PubkeyBytesToAddress(ele.PublicKey)
+			if !signed[addr] {
+				timeouts = append(timeouts, addr)
+			}
+		}
+	}
+
+	update := func(addr common.Address, f func(stats *modules.JurorStats)) {
+		stats, err := rep.statedb.GetJurorStats(addr)
+		if err != nil {
+			log.Warnf("get juror[%s] stats error:%s", addr.String(), err.Error())
+			return
+		}
+		f(stats)
+		if err := rep.statedb.SaveJurorStats(stats); err != nil {
+			log.Warnf("save juror[%s] stats error:%s", addr.String(), err.Error())
+		}
+	}
+	for _, ele := range jury.EleList {
+		addr := crypto.PubkeyBytesToAddress(ele.PublicKey)
+		if signed[addr] {
+			update(addr, func(stats *modules.JurorStats) { stats.Participated++ })
+		}
+	}
+	for _, addr := range timeouts {
+		update(addr, func(stats *modules.JurorStats) { stats.Timeouts++ })
+	}
+}
+
+//获得执行请求的陪审团，部署请求使用交易中选出的陪审团，其他请求使用合约部署时保存的陪审团
+func (rep *UnitRepository) getRequestJury(tx *modules.Transaction) *modules.ElectionNode {
+	for _, msg := range tx.TxMessages {
+		if msg.App == modules.APP_CONTRACT_DEPLOY {
+			if payload, ok := msg.Payload.(*modules.ContractDeployPayload); ok {
+				return &payload.EleNode
+			}
+		}
+	}
+	jury, err := rep.statedb.GetContractJury(tx.ContractIdBytes())
+	if err != nil {
+		return nil
+	}
+	return jury
+}
+
+//保存陪审员作恶证据，并增加其不一致结果的次数，同一证据只统计一次
+func (rep *UnitRepository) saveJurorEvidence(msg *modules.Message) bool {
+	evidence, ok := msg.Payload.(*modules.JurorEvidencePayload)
+	if !ok {
+		log.Error("save juror evidence", "error", "payload is not the juror evidence type.")
+		return false
+	}
+	if rep.statedb.IsJurorEvidenceExist(evidence.Hash()) {
+		return true
+	}
+	juror, err := evidence.Verify()
+	if err != nil {
+		log.Infof("save juror evidence failed, error:%s", err.Error())
+		return false
+	}
+	stats, err := rep.statedb.GetJurorStats(juror)
+	if err != nil {
+		log.Infof("get juror[%s] stats failed, error:%s", juror.String(), err.Error())
+		return false
+	}
+	stats.Divergent++
+	if err := rep.statedb.SaveJurorStats(stats); err != nil {
+		log.Infof("save juror[%s] stats failed, error:%s", juror.String(), err.Error())
+		return false
+	}
+	if err := rep.statedb.SaveJurorEvidence(evidence); err != nil {
+		log.Infof("save juror evidence failed, error:%s", err.Error())
+		return false
+	}
+	return true
+}
+
 /**
 从levedb中根据ChainIndex获得Unit信息
 To get unit information by its ChainIndex
@@ -720,6 +720,38 @@ func TestContractTxsIllegal(t *testing.T) {
 	//mark
 	markTxsIllegal(statedb, txs)
 }
+func TestMarkTxIllegal_RangeReadSet(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	statedb := storage.NewStateDb(db)
+	contractId := []byte("contract0000")
+	ver := &modules.StateVersion{Height: &modules.ChainIndex{Index: 123}, TxIndex: 1}
+	ws := []modules.ContractWriteSet{*modules.NewWriteSet("order1", []byte("1")),
+		*modules.NewWriteSet("order2", []byte("2")), *modules.NewWriteSet("user1", []byte("3"))}
+	assert.Nil(t, statedb.SaveContractStates(contractId, ws, ver))
+
+	states, _ := statedb.GetContractStatesByRange(contractId, "order", "order~", 0)
+	assert.Equal(t, 2, len(states))
+	payload := &modules.ContractInvokePayload{ContractId: contractId,
+		RangeReadSet: []modules.ContractRangeReadSet{{StartKey: "order", EndKey: "order~",
+			ResultHash: modules.HashContractStates(states)}}}
+	tx := &modules.Transaction{TxMessages: []*modules.Message{
+		modules.NewMessage(modules.APP_CONTRACT_INVOKE_REQUEST,
+			&modules.ContractInvokeRequestPayload{ContractId: contractId}),
+		modules.NewMessage(modules.APP_CONTRACT_INVOKE, payload)}}
+	markTxIllegal(statedb, tx)
+	assert.False(t, tx.Illegal)
+
+	//区间外的写入不影响
+	assert.Nil(t, statedb.SaveContractState(contractId, modules.NewWriteSet("user2", []byte("4")), ver))
+	markTxIllegal(statedb, tx)
+	assert.False(t, tx.Illegal)
+
+	//区间内新增的key是幻读
+	assert.Nil(t, statedb.SaveContractState(contractId, modules.NewWriteSet("order3", []byte("5")), ver))
+	markTxIllegal(statedb, tx)
+	assert.True(t, tx.Illegal)
+}
+
 func markTxsIllegal(dag storage.IStateDb, txs []*modules.Transaction) {
 	for _, tx := range txs {
 		if !tx.IsContractTx() {
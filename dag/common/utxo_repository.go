@@ -72,6 +72,7 @@ type IUtxoRepository interface {
 	GetAllUtxos() (map[modules.OutPoint]*modules.Utxo, error)
 	GetAddrOutpoints(addr common.Address) ([]modules.OutPoint, error)
 	GetAddrUtxos(addr common.Address, asset *modules.Asset) (map[modules.OutPoint]*modules.Utxo, error)
+	GetAddrStxos(addr common.Address, asset *modules.Asset) (map[modules.OutPoint]*modules.Stxo, error)
 	GetUxto(txin modules.Input) *modules.Utxo
 	UpdateUtxo(unitTime int64, txHash common.Hash, payment *modules.PaymentPayload, msgIndex uint32) error
 	IsUtxoSpent(outpoint *modules.OutPoint) (bool, error)
@@ -84,11 +85,23 @@ type IUtxoRepository interface {
 	ClearUtxo() error
 	SaveUtxoView(view map[modules.OutPoint]*modules.Utxo) error
 	SaveUtxoEntity(outpoint *modules.OutPoint, utxo *modules.Utxo) error
+	GetUtxoRoot() (common.Hash, error)
+	GetUtxoProof(root common.Hash, outpoint *modules.OutPoint) ([][]byte, error)
+	CommitUtxoRoot() error
 }
 
 func (repository *UtxoRepository) GetUtxoEntry(outpoint *modules.OutPoint) (*modules.Utxo, error) {
 	return repository.utxodb.GetUtxoEntry(outpoint)
 }
+func (repository *UtxoRepository) GetUtxoRoot() (common.Hash, error) {
+	return repository.utxodb.GetUtxoRoot()
+}
+func (repository *UtxoRepository) GetUtxoProof(root common.Hash, outpoint *modules.OutPoint) ([][]byte, error) {
+	return repository.utxodb.GetUtxoProof(root, outpoint)
+}
+func (repository *UtxoRepository) CommitUtxoRoot() error {
+	return repository.utxodb.CommitUtxoRoot()
+}
 func (repository *UtxoRepository) GetStxoEntry(outpoint *modules.OutPoint) (*modules.Stxo, error) {
 	return repository.utxodb.GetStxoEntry(outpoint)
 }
@@ -105,6 +118,10 @@ func (repository *UtxoRepository) GetAddrUtxos(addr common.Address, asset *modul
 	map[modules.OutPoint]*modules.Utxo, error) {
 	return repository.utxodb.GetAddrUtxos(addr, asset)
 }
+func (repository *UtxoRepository) GetAddrStxos(addr common.Address, asset *modules.Asset) (
+	map[modules.OutPoint]*modules.Stxo, error) {
+	return repository.utxodb.GetAddrStxos(addr, asset)
+}
 func (repository *UtxoRepository) SaveUtxoView(view map[modules.OutPoint]*modules.Utxo) error {
 	return repository.utxodb.SaveUtxoView(view)
 }
@@ -54,6 +54,9 @@ var (
 	BlacklistAddress="BlacklistAddress"
 )
 
+//GetTimestamp请求的Collection为此值时，返回对应unit的高度而不是时间
+const TIMESTAMP_COLLECTION_HEIGHT = "height"
+
 func init() {
 	VERSION = "1.0"
 	ALT = "1"
@@ -29,6 +29,7 @@ var (
 	TRANSACTION_PREFIX          = []byte("tx")
 	ADDR_TXID_PREFIX            = []byte("at") // to addr  transactions hash prefix
 	ADDR_OUTPOINT_PREFIX        = []byte("ap") // addr outpoint
+	ADDR_SPENT_OUTPOINT_PREFIX  = []byte("as") // addr spent outpoint
 	OUTPOINT_ADDR_PREFIX        = []byte("pa") // outpoint addr
 	CONTRACT_STATE_PREFIX       = []byte("cs")
 	CONTRACT_TPL                = []byte("ct")
@@ -43,6 +44,8 @@ var (
 	CONTRACT_PREFIX             = []byte("co")
 	CONTRACT_TPL_INSTANCE_MAP   = []byte("cm")
 	CONTRACT_JURY_PREFIX        = []byte("cj")
+	CONTRACT_STATE_TRIE_PREFIX  = []byte("cx") // 合约状态承诺的trie节点
+	CONTRACT_STATE_HISTORY      = []byte("ch") // IndexDB中存储合约状态的修改历史
 	REQID_TXID_PREFIX           = []byte("rq")
 	MEDIATOR_INFO_PREFIX        = []byte("mi")
 	DEPOSIT_BALANCE_PREFIX      = []byte("db")
@@ -50,6 +53,12 @@ var (
 	//DEPOSIT_MEDIATOR_VOTE_PREFIX = []byte("dn")
 	PLEDGE_DEPOSIT_PREFIX  = []byte("pd")
 	PLEDGE_WITHDRAW_PREFIX = []byte("pw")
+	//保证金合约中记录委托给mediator的质押，key为前缀+mediator地址+委托人地址
+	MEDIATOR_DELEGATION_PREFIX = []byte("dg")
+	//陪审员执行合约的统计，key为前缀+陪审员地址
+	JUROR_STATS_PREFIX = []byte("js")
+	//已经处理过的陪审员作恶证据，key为前缀+证据hash
+	JUROR_EVIDENCE_PREFIX = []byte("je")
 
 	GLOBAL_PROPERTY_HISTORY_PREFIX = []byte("gh")
 
@@ -62,6 +71,7 @@ var (
 	UTXO_PREFIX                = []byte("uo")
 	SPENT_UTXO_PREFIX          = []byte("us")
 	UTXO_INDEX_PREFIX          = []byte("ui")
+	UTXO_TRIE_PREFIX           = []byte("ut") // UTXO集合承诺的trie节点
 	TrieSyncKey                = []byte("TrieSync")
 	LastUnitInfo               = []byte("stbu")
 	GenesisUnitHash            = []byte("GenesisUnitHash")
@@ -69,6 +79,8 @@ var (
 	DYNAMIC_GLOBALPROPERTY_KEY = []byte("dpDynamicGlobalProperty")
 	MEDIATOR_SCHEDULE_KEY      = []byte("msMediatorSchedule")
 	DATA_VERSION_KEY           = []byte("gptnversion")
+	UTXO_ROOT_KEY              = []byte("utUtxoRoot")
+	CONTRACT_STATE_ROOT_KEY    = []byte("cxStateRoot")
 
 	//filehash
 	IDX_MAIN_DATA_TXID              = []byte("md") //Old value: mda
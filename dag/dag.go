@@ -31,6 +31,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/event"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/mclock"
 	"github.com/palletone/go-palletone/common/ptndb"
 	"github.com/palletone/go-palletone/configure"
 	"github.com/palletone/go-palletone/contracts/list"
@@ -80,11 +81,19 @@ type Dag struct {
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
 	scope         event.SubscriptionScope
+
+	// 判断是否同步时使用的时钟，为nil时使用系统时间
+	clock mclock.Clock
 }
 
 func cache() palletcache.ICache {
 	return freecache.NewCache(1000 * 1024)
 }
+// SetClock 替换判断是否同步时使用的时钟，模拟测试中使用虚拟时钟
+func (d *Dag) SetClock(clock mclock.Clock) {
+	d.clock = clock
+}
+
 func (d *Dag) IsEmpty() bool {
 	it := d.Db.NewIterator()
 	return !it.Next()
@@ -532,6 +541,14 @@ func NewDag(db ptndb.Database, cache palletcache.ICache, light bool) (*Dag, erro
 	if err != nil {
 		return nil, err
 	}
+	err = utxoDb.InitUtxoRoot()
+	if err != nil {
+		return nil, err
+	}
+	err = stateDb.InitStateRoot()
+	if err != nil {
+		return nil, err
+	}
 
 	utxoRep := dagcommon.NewUtxoRepository(utxoDb, idxDb, stateDb, propDb, tokenEngine)
 	unitRep := dagcommon.NewUnitRepository(dagDb, idxDb, utxoDb, stateDb, propDb, tokenEngine)
@@ -751,6 +768,69 @@ func (d *Dag) GetUtxoEntry(outpoint *modules.OutPoint) (*modules.Utxo, error) {
 	return d.unstableUtxoRep.GetUtxoEntry(outpoint)
 }
 
+// 返回承诺了稳定状态的单元头，稳定单元之后的下一个单元承诺了当前稳定的状态，
+// 没有时使用最新的稳定单元，它承诺的状态的trie节点也已经在稳定的数据库中
+func (d *Dag) getCommitmentHeader(stableRoot common.Hash, root func(h *modules.Header) common.Hash) (
+	*modules.Header, error) {
+	stableIndex := d.GetStableChainIndex(dagconfig.DagConfig.GetGasToken())
+	if stableIndex == nil {
+		return nil, errors.New("stable unit not found")
+	}
+	next := modules.NewChainIndex(stableIndex.AssetID, stableIndex.Index+1)
+	header, err := d.GetHeaderByNumber(next)
+	if err == nil && root(header) == stableRoot {
+		return header, nil
+	}
+	return d.stableUnitRep.GetHeaderByNumber(stableIndex)
+}
+
+// 生成outpoint在稳定的UTXO集合中存在或者不存在的证明，并返回包含该UTXO集合承诺的单元头
+func (d *Dag) GetUtxoProof(outpoint *modules.OutPoint) (*modules.Header, [][]byte, error) {
+	d.Mutex.RLock()
+	defer d.Mutex.RUnlock()
+	stableRoot, err := d.stableUtxoRep.GetUtxoRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := d.getCommitmentHeader(stableRoot, func(h *modules.Header) common.Hash { return h.UtxoRoot })
+	if err != nil {
+		return nil, nil, err
+	}
+	if cp := d.GetChainParameters(); cp == nil || !cp.IsUtxoCommitmentEnabled(header.NumberU64()) {
+		return nil, nil, errors.New("utxo commitment is not enabled")
+	}
+	proof, err := d.stableUtxoRep.GetUtxoProof(header.UtxoRoot, outpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, proof, nil
+}
+
+// 生成合约状态在稳定的合约状态中存在或者不存在的证明，并返回包含合约状态承诺的单元头、
+// 合约的状态root、合约root的证明和状态的证明
+func (d *Dag) GetContractStateProof(contractId []byte, field string) (*modules.Header, common.Hash,
+	[][]byte, [][]byte, error) {
+	d.Mutex.RLock()
+	defer d.Mutex.RUnlock()
+	stableRoot, err := d.stableStateRep.GetStateRoot()
+	if err != nil {
+		return nil, common.Hash{}, nil, nil, err
+	}
+	header, err := d.getCommitmentHeader(stableRoot, func(h *modules.Header) common.Hash { return h.StateRoot })
+	if err != nil {
+		return nil, common.Hash{}, nil, nil, err
+	}
+	if cp := d.GetChainParameters(); cp == nil || !cp.IsStateCommitmentEnabled(header.NumberU64()) {
+		return nil, common.Hash{}, nil, nil, errors.New("contract state commitment is not enabled")
+	}
+	contractRoot, contractProof, stateProof, err := d.stableStateRep.GetContractStateProof(header.StateRoot,
+		contractId, field)
+	if err != nil {
+		return nil, common.Hash{}, nil, nil, err
+	}
+	return header, contractRoot, contractProof, stateProof, nil
+}
+
 // get the stxoEntry by outpoint
 func (d *Dag) GetStxoEntry(outpoint *modules.OutPoint) (*modules.Stxo, error) {
 	d.Mutex.RLock()
@@ -886,6 +966,42 @@ func (d *Dag) GetAddrStableUtxos(addr common.Address) (map[modules.OutPoint]*mod
 	return all, err
 }
 
+// 返回地址在指定高度的主链单元时持有的Token，该单元必须已经稳定，所有节点得到的结果才一致。
+// 统计该单元之前产生且当时尚未花费的UTXO，即现在的UTXO加上在该单元之后才花费的STXO
+func (d *Dag) GetAddrTokenBalanceAt(addr common.Address, asset *modules.Asset, height uint64) (
+	map[modules.Asset]uint64, error) {
+	gasToken := dagconfig.DagConfig.GetGasToken()
+	stableIndex := d.GetStableChainIndex(gasToken)
+	if stableIndex == nil || height > stableIndex.Index {
+		return nil, fmt.Errorf("unit at height %d is not stable", height)
+	}
+	header, err := d.stableUnitRep.GetHeaderByNumber(modules.NewChainIndex(gasToken, height))
+	if err != nil {
+		return nil, err
+	}
+	unitTime := uint64(header.Timestamp())
+	utxos, err := d.stableUtxoRep.GetAddrUtxos(addr, asset)
+	if err != nil {
+		return nil, err
+	}
+	stxos, err := d.stableUtxoRep.GetAddrStxos(addr, asset)
+	if err != nil {
+		return nil, err
+	}
+	result := map[modules.Asset]uint64{}
+	for _, utxo := range utxos {
+		if utxo.Timestamp <= unitTime {
+			result[*utxo.Asset] += utxo.Amount
+		}
+	}
+	for _, stxo := range stxos {
+		if stxo.Timestamp <= unitTime && stxo.SpentTime > unitTime {
+			result[*stxo.Asset] += stxo.Amount
+		}
+	}
+	return result, nil
+}
+
 // refresh system parameters
 func (d *Dag) RefreshSysParameters() {
 	d.unstableUnitProduceRep.RefreshSysParameters()
@@ -911,11 +1027,22 @@ func (d *Dag) GetContractStatesByPrefix(id []byte, prefix string) (map[string]*m
 	return d.unstableStateRep.GetContractStatesByPrefix(id, prefix)
 }
 
+// return contract states in key range [startKey,endKey) by key order
+func (d *Dag) GetContractStatesByRange(id []byte, startKey, endKey string,
+	limit int) ([]*modules.ContractStateKV, error) {
+	return d.unstableStateRep.GetContractStatesByRange(id, startKey, endKey, limit)
+}
+
 // return electionInfo by contractId
 func (d *Dag) GetContractJury(contractId []byte) (*modules.ElectionNode, error) {
 	return d.unstableStateRep.GetContractJury(contractId)
 }
 
+// return the statistics of juror executing contracts
+func (d *Dag) GetJurorStats(address common.Address) (*modules.JurorStats, error) {
+	return d.unstableStateRep.GetJurorStats(address)
+}
+
 // createUnit, create a unit when mediator being produced
 func (d *Dag) CreateUnit(mAddr common.Address, txpool txspool.ITxPool, t time.Time) (*modules.Unit, error) {
 	_, _, state, rep, _ := d.Memdag.GetUnstableRepositories()
@@ -924,7 +1051,7 @@ func (d *Dag) CreateUnit(mAddr common.Address, txpool txspool.ITxPool, t time.Ti
 		return nil, err
 	}
 
-	return d.unstableUnitRep.CreateUnit(med.GetRewardAdd(), txpool, rep, t)
+	return d.unstableUnitRep.CreateUnit(med, txpool, rep, t)
 }
 
 // save header
@@ -1300,3 +1427,9 @@ func (d *Dag) GetBlacklistAddress() ([]common.Address, *modules.StateVersion, er
 func (d *Dag) RebuildAddrTxIndex() error {
 	return d.stableUnitRep.RebuildAddrTxIndex()
 }
+
+//包括未稳定单元中的修改
+func (d *Dag) GetContractStateHistory(contractId []byte, key string,
+	from, to uint64) ([]*modules.ContractStateHistory, error) {
+	return d.unstableUnitRep.GetContractStateHistory(contractId, key, from, to)
+}
@@ -548,6 +548,21 @@ func (mr *MockIDagMockRecorder) GetContractStatesByPrefix(id, prefix interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractStatesByPrefix", reflect.TypeOf((*MockIDag)(nil).GetContractStatesByPrefix), id, prefix)
 }
 
+// GetContractStatesByRange mocks base method
+func (m *MockIDag) GetContractStatesByRange(id []byte, startKey, endKey string, limit int) ([]*modules.ContractStateKV, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractStatesByRange", id, startKey, endKey, limit)
+	ret0, _ := ret[0].([]*modules.ContractStateKV)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractStatesByRange indicates an expected call of GetContractStatesByRange
+func (mr *MockIDagMockRecorder) GetContractStatesByRange(id, startKey, endKey, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractStatesByRange", reflect.TypeOf((*MockIDag)(nil).GetContractStatesByRange), id, startKey, endKey, limit)
+}
+
 // GetContractJury mocks base method
 func (m *MockIDag) GetContractJury(contractId []byte) (*modules.ElectionNode, error) {
 	m.ctrl.T.Helper()
@@ -563,6 +578,21 @@ func (mr *MockIDagMockRecorder) GetContractJury(contractId interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractJury", reflect.TypeOf((*MockIDag)(nil).GetContractJury), contractId)
 }
 
+// GetJurorStats mocks base method
+func (m *MockIDag) GetJurorStats(address common.Address) (*modules.JurorStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJurorStats", address)
+	ret0, _ := ret[0].(*modules.JurorStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJurorStats indicates an expected call of GetJurorStats
+func (mr *MockIDagMockRecorder) GetJurorStats(address interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJurorStats", reflect.TypeOf((*MockIDag)(nil).GetJurorStats), address)
+}
+
 // GetUnitNumber mocks base method
 func (m *MockIDag) GetUnitNumber(hash common.Hash) (*modules.ChainIndex, error) {
 	m.ctrl.T.Helper()
@@ -693,6 +723,40 @@ func (mr *MockIDagMockRecorder) GetStxoEntry(outpoint interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStxoEntry", reflect.TypeOf((*MockIDag)(nil).GetStxoEntry), outpoint)
 }
 
+// GetContractStateProof mocks base method
+func (m *MockIDag) GetContractStateProof(contractId []byte, field string) (*modules.Header, common.Hash, [][]byte, [][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractStateProof", contractId, field)
+	ret0, _ := ret[0].(*modules.Header)
+	ret1, _ := ret[1].(common.Hash)
+	ret2, _ := ret[2].([][]byte)
+	ret3, _ := ret[3].([][]byte)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// GetContractStateProof indicates an expected call of GetContractStateProof
+func (mr *MockIDagMockRecorder) GetContractStateProof(contractId, field interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractStateProof", reflect.TypeOf((*MockIDag)(nil).GetContractStateProof), contractId, field)
+}
+
+// GetUtxoProof mocks base method
+func (m *MockIDag) GetUtxoProof(outpoint *modules.OutPoint) (*modules.Header, [][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUtxoProof", outpoint)
+	ret0, _ := ret[0].(*modules.Header)
+	ret1, _ := ret[1].([][]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUtxoProof indicates an expected call of GetUtxoProof
+func (mr *MockIDagMockRecorder) GetUtxoProof(outpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUtxoProof", reflect.TypeOf((*MockIDag)(nil).GetUtxoProof), outpoint)
+}
+
 // GetTxOutput mocks base method
 func (m *MockIDag) GetTxOutput(outpoint *modules.OutPoint) (*modules.Utxo, error) {
 	m.ctrl.T.Helper()
@@ -768,6 +832,21 @@ func (mr *MockIDagMockRecorder) GetAddr1TokenUtxos(addr, asset interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddr1TokenUtxos", reflect.TypeOf((*MockIDag)(nil).GetAddr1TokenUtxos), addr, asset)
 }
 
+// GetAddrTokenBalanceAt mocks base method
+func (m *MockIDag) GetAddrTokenBalanceAt(addr common.Address, asset *modules.Asset, height uint64) (map[modules.Asset]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAddrTokenBalanceAt", addr, asset, height)
+	ret0, _ := ret[0].(map[modules.Asset]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAddrTokenBalanceAt indicates an expected call of GetAddrTokenBalanceAt
+func (mr *MockIDagMockRecorder) GetAddrTokenBalanceAt(addr, asset, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddrTokenBalanceAt", reflect.TypeOf((*MockIDag)(nil).GetAddrTokenBalanceAt), addr, asset, height)
+}
+
 // GetAllUtxos mocks base method
 func (m *MockIDag) GetAllUtxos() (map[modules.OutPoint]*modules.Utxo, error) {
 	m.ctrl.T.Helper()
@@ -1061,6 +1140,20 @@ func (mr *MockIDagMockRecorder) IsSynced() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSynced", reflect.TypeOf((*MockIDag)(nil).IsSynced))
 }
 
+// SubscribeMediatorEvidenceEvent mocks base method
+func (m *MockIDag) SubscribeMediatorEvidenceEvent(ch chan<- modules.MediatorEvidenceEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeMediatorEvidenceEvent", ch)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeMediatorEvidenceEvent indicates an expected call of SubscribeMediatorEvidenceEvent
+func (mr *MockIDagMockRecorder) SubscribeMediatorEvidenceEvent(ch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeMediatorEvidenceEvent", reflect.TypeOf((*MockIDag)(nil).SubscribeMediatorEvidenceEvent), ch)
+}
+
 // SubscribeActiveMediatorsUpdatedEvent mocks base method
 func (m *MockIDag) SubscribeActiveMediatorsUpdatedEvent(ch chan<- modules.ActiveMediatorsUpdatedEvent) event.Subscription {
 	m.ctrl.T.Helper()
@@ -1499,6 +1592,20 @@ func (mr *MockIDagMockRecorder) GetGlobalProp() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGlobalProp", reflect.TypeOf((*MockIDag)(nil).GetGlobalProp))
 }
 
+// GetMediatorSchl mocks base method
+func (m *MockIDag) GetMediatorSchl() *modules.MediatorSchedule {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediatorSchl")
+	ret0, _ := ret[0].(*modules.MediatorSchedule)
+	return ret0
+}
+
+// GetMediatorSchl indicates an expected call of GetMediatorSchl
+func (mr *MockIDagMockRecorder) GetMediatorSchl() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediatorSchl", reflect.TypeOf((*MockIDag)(nil).GetMediatorSchl))
+}
+
 // GetMediatorCount mocks base method
 func (m *MockIDag) GetMediatorCount() int {
 	m.ctrl.T.Helper()
@@ -1670,6 +1777,22 @@ func (mr *MockIDagMockRecorder) GetNewestUnitTimestamp(token interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewestUnitTimestamp", reflect.TypeOf((*MockIDag)(nil).GetNewestUnitTimestamp), token)
 }
 
+// GetNewestUnit mocks base method
+func (m *MockIDag) GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewestUnit", token)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(*modules.ChainIndex)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetNewestUnit indicates an expected call of GetNewestUnit
+func (mr *MockIDagMockRecorder) GetNewestUnit(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewestUnit", reflect.TypeOf((*MockIDag)(nil).GetNewestUnit), token)
+}
+
 // GetScheduledMediator mocks base method
 func (m *MockIDag) GetScheduledMediator(slotNum uint32) common.Address {
 	m.ctrl.T.Helper()
@@ -1945,3 +2068,18 @@ func (mr *MockIDagMockRecorder) RebuildAddrTxIndex() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildAddrTxIndex", reflect.TypeOf((*MockIDag)(nil).RebuildAddrTxIndex))
 }
+
+// GetContractStateHistory mocks base method
+func (m *MockIDag) GetContractStateHistory(contractId []byte, key string, from, to uint64) ([]*modules.ContractStateHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractStateHistory", contractId, key, from, to)
+	ret0, _ := ret[0].([]*modules.ContractStateHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractStateHistory indicates an expected call of GetContractStateHistory
+func (mr *MockIDagMockRecorder) GetContractStateHistory(contractId, key, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractStateHistory", reflect.TypeOf((*MockIDag)(nil).GetContractStateHistory), contractId, key, from, to)
+}
@@ -48,6 +48,7 @@ var DefaultConfig = Config{
 	AddrTxsIndex:            false,
 	Token721TxIndex:         true,
 	TextFileHashIndex:       true,
+	ContractStateHistory:    false,
 	GasToken:                DefaultToken,
 }
 
@@ -85,6 +86,8 @@ type Config struct {
 	Token721TxIndex bool
 
 	TextFileHashIndex bool
+	// 记录合约状态的每次修改，用于查询状态的历史，未开启时合约中GetHistoryForKey返回错误
+	ContractStateHistory bool
 
 	//当前节点选择的平台币，燃料币,必须为Asset全名
 	GasToken string
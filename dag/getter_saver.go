@@ -29,7 +29,6 @@ import (
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/dag/dagconfig"
 	"github.com/palletone/go-palletone/dag/modules"
-	"go.dedis.ch/kyber/v3"
 )
 
 func (d *Dag) GetGlobalProp() *modules.GlobalProperty {
@@ -72,9 +71,9 @@ func (d *Dag) GetActiveMediatorNodes() map[string]*discover.Node {
 }
 
 // author Albert·Gou
-func (d *Dag) GetActiveMediatorInitPubs() []kyber.Point {
+func (d *Dag) GetActiveMediatorInitPubs() [][]byte {
 	aSize := d.ActiveMediatorsCount()
-	pubs := make([]kyber.Point, aSize)
+	pubs := make([][]byte, aSize)
 
 	meds := d.GetActiveMediators()
 	for i, add := range meds {
@@ -166,6 +165,11 @@ func (dag *Dag) HeadUnitTime() int64 {
 	return t
 }
 
+func (dag *Dag) GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error) {
+	_, _, _, rep, _ := dag.Memdag.GetUnstableRepositories()
+	return rep.GetNewestUnit(token)
+}
+
 func (dag *Dag) HeadUnitNum() uint64 {
 	gasToken := dagconfig.DagConfig.GetGasToken()
 	_, _, _, rep, _ := dag.Memdag.GetUnstableRepositories()
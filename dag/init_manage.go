@@ -32,13 +32,17 @@ import (
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/storage"
-	"go.dedis.ch/kyber/v3/sign/bls"
+	"github.com/palletone/go-palletone/validator"
 )
 
 func (d *Dag) SubscribeToGroupSignEvent(ch chan<- modules.ToGroupSignEvent) event.Subscription {
 	return d.Memdag.SubscribeToGroupSignEvent(ch)
 }
 
+func (d *Dag) SubscribeMediatorEvidenceEvent(ch chan<- modules.MediatorEvidenceEvent) event.Subscription {
+	return d.Memdag.SubscribeMediatorEvidenceEvent(ch)
+}
+
 func (d *Dag) IsActiveMediator(add common.Address) bool {
 	return d.GetGlobalProp().IsActiveMediator(add)
 }
@@ -95,6 +99,11 @@ func (dag *Dag) InitStateDB(genesis *core.Genesis, unit *modules.Unit) error {
 			log.Debugf(err.Error())
 			return err
 		}
+		_, err = core.StrToInitPubKey(genesis.InitialParameters.GroupSignScheme, imc.InitPubKey)
+		if err != nil {
+			log.Debugf(err.Error())
+			return err
+		}
 
 		mi := modules.NewMediatorInfo()
 		mi.MediatorInfoBase = imc.MediatorInfoBase
@@ -162,6 +171,9 @@ func (dag *Dag) IsSynced() bool {
 	//nowFine := time.Now()
 	//now := time.Unix(nowFine.Add(500*time.Millisecond).Unix(), 0)
 	now := time.Now()
+	if dag.clock != nil {
+		now = dag.clock.Now()
+	}
 	// 防止误判，获取之后的第2个生产槽时间
 	//nextSlotTime := dag.unstablePropRep.GetSlotTime(gp, dgp, 1)
 	//nextSlotTime := dag.unstablePropRep.GetSlotTime(2)
@@ -222,13 +234,9 @@ func (d *Dag) VerifyUnitGroupSign(unitHash common.Hash, groupSign []byte) error
 		return err
 	}
 
-	pubKey, err := header.GetGroupPubKey()
-	if err != nil {
-		log.Debug(err.Error())
-		return err
-	}
-
-	err = bls.Verify(core.Suite, pubKey, unitHash[:], groupSign)
+	signed := *header
+	signed.GroupSign = groupSign
+	err = validator.NewValidate(nil, nil, nil, d.stablePropRep, nil).ValidateUnitGroupSign(&signed)
 	if err != nil {
 		log.Debug("the group signature: " + hexutil.Encode(groupSign) + " of the Unit that hash: " +
 			unitHash.Hex() + " is verified that an error has occurred: " + err.Error())
@@ -77,7 +77,10 @@ type IDag interface {
 	GetContractState(contractid []byte, field string) ([]byte, *modules.StateVersion, error)
 	GetContractStatesById(id []byte) (map[string]*modules.ContractStateValue, error)
 	GetContractStatesByPrefix(id []byte, prefix string) (map[string]*modules.ContractStateValue, error)
+	GetContractStatesByRange(id []byte, startKey, endKey string, limit int) ([]*modules.ContractStateKV, error)
+	GetContractStateProof(contractId []byte, field string) (*modules.Header, common.Hash, [][]byte, [][]byte, error)
 	GetContractJury(contractId []byte) (*modules.ElectionNode, error)
+	GetJurorStats(address common.Address) (*modules.JurorStats, error)
 	GetUnitNumber(hash common.Hash) (*modules.ChainIndex, error)
 
 	GetUtxoView(tx *modules.Transaction) (*txspool.UtxoViewpoint, error)
@@ -89,12 +92,14 @@ type IDag interface {
 	GetTrieSyncProgress() (uint64, error)
 	GetUtxoEntry(outpoint *modules.OutPoint) (*modules.Utxo, error)
 	GetStxoEntry(outpoint *modules.OutPoint) (*modules.Stxo, error)
+	GetUtxoProof(outpoint *modules.OutPoint) (*modules.Header, [][]byte, error)
 	//Include Utxo and Stxo
 	GetTxOutput(outpoint *modules.OutPoint) (*modules.Utxo, error)
 	GetAddrOutpoints(addr common.Address) ([]modules.OutPoint, error)
 	GetAddrUtxos(addr common.Address) (map[modules.OutPoint]*modules.Utxo, error)
 	GetAddrStableUtxos(addr common.Address) (map[modules.OutPoint]*modules.Utxo, error)
 	GetAddr1TokenUtxos(addr common.Address, asset *modules.Asset) (map[modules.OutPoint]*modules.Utxo, error)
+	GetAddrTokenBalanceAt(addr common.Address, asset *modules.Asset, height uint64) (map[modules.Asset]uint64, error)
 	GetAllUtxos() (map[modules.OutPoint]*modules.Utxo, error)
 	GetAddrTransactions(addr common.Address) ([]*modules.TransactionWithUnitInfo, error)
 	GetAssetTxHistory(asset *modules.Asset) ([]*modules.TransactionWithUnitInfo, error)
@@ -119,6 +124,7 @@ type IDag interface {
 	GetTxFee(pay *modules.Transaction) (*modules.AmountAsset, error)
 	SetUnitGroupSign(unitHash common.Hash, groupSign []byte, txpool txspool.ITxPool) error
 	SubscribeToGroupSignEvent(ch chan<- modules.ToGroupSignEvent) event.Subscription
+	SubscribeMediatorEvidenceEvent(ch chan<- modules.MediatorEvidenceEvent) event.Subscription
 
 	IsSynced() bool
 	SubscribeActiveMediatorsUpdatedEvent(ch chan<- modules.ActiveMediatorsUpdatedEvent) event.Subscription
@@ -163,6 +169,7 @@ type IDag interface {
 		txPool txspool.ITxPool) (*modules.Transaction, uint64, error)
 	GetDynGlobalProp() *modules.DynamicGlobalProperty
 	GetGlobalProp() *modules.GlobalProperty
+	GetMediatorSchl() *modules.MediatorSchedule
 	GetMediatorCount() int
 
 	IsMediator(address common.Address) bool
@@ -178,6 +185,7 @@ type IDag interface {
 	GetMediator(add common.Address) *core.Mediator
 
 	GetNewestUnitTimestamp(token modules.AssetId) (int64, error)
+	GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error)
 	GetScheduledMediator(slotNum uint32) common.Address
 	GetSlotAtTime(when time.Time) uint32
 	GetChainParameters() *core.ChainParameters
@@ -201,4 +209,5 @@ type IDag interface {
 	CheckHeaderCorrect(number int) error
 	GetBlacklistAddress() ([]common.Address, *modules.StateVersion, error)
 	RebuildAddrTxIndex() error
+	GetContractStateHistory(contractId []byte, key string, from, to uint64) ([]*modules.ContractStateHistory, error)
 }
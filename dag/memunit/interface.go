@@ -58,5 +58,6 @@ type IMemDag interface {
 	GetHeaderByNumber(number *modules.ChainIndex) (*modules.Header, error)
 
 	SubscribeToGroupSignEvent(ch chan<- modules.ToGroupSignEvent) event.Subscription
+	SubscribeMediatorEvidenceEvent(ch chan<- modules.MediatorEvidenceEvent) event.Subscription
 	Close()
 }
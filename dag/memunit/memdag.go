@@ -31,14 +31,13 @@ import (
 	"github.com/palletone/go-palletone/common/hexutil"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/common/ptndb"
-	"github.com/palletone/go-palletone/core"
 	common2 "github.com/palletone/go-palletone/dag/common"
+	"github.com/palletone/go-palletone/dag/dagconfig"
 	"github.com/palletone/go-palletone/dag/errors"
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/palletcache"
 	"github.com/palletone/go-palletone/dag/txspool"
 	"github.com/palletone/go-palletone/validator"
-	"go.dedis.ch/kyber/v3/sign/bls"
 )
 
 type MemDag struct {
@@ -62,6 +61,9 @@ type MemDag struct {
 	// append by albert·gou 用于通知群签名
 	toGroupSignFeed  event.Feed
 	toGroupSignScope event.SubscriptionScope
+	// 发现mediator重复出块时通知
+	evidenceFeed  event.Feed
+	evidenceScope event.SubscriptionScope
 	db               ptndb.Database
 	tokenEngine      tokenengine.ITokenEngine
 	quit             chan struct{} // used for exit
@@ -69,12 +71,17 @@ type MemDag struct {
 
 func (pmg *MemDag) Close() {
 	pmg.toGroupSignScope.Close()
+	pmg.evidenceScope.Close()
 }
 
 func (pmg *MemDag) SubscribeToGroupSignEvent(ch chan<- modules.ToGroupSignEvent) event.Subscription {
 	return pmg.toGroupSignScope.Track(pmg.toGroupSignFeed.Subscribe(ch))
 }
 
+func (pmg *MemDag) SubscribeMediatorEvidenceEvent(ch chan<- modules.MediatorEvidenceEvent) event.Subscription {
+	return pmg.evidenceScope.Track(pmg.evidenceFeed.Subscribe(ch))
+}
+
 func (pmg *MemDag) SetStableThreshold(count int) {
 	pmg.lock.Lock()
 	defer pmg.lock.Unlock()
@@ -287,6 +294,7 @@ func (chain *MemDag) setNextStableUnit(unit *modules.Unit, txpool txspool.ITxPoo
 	//Set stable unit
 	chain.stableUnitHash = hash
 	chain.stableUnitHeight = height
+	chain.updateHeightMetrics()
 }
 
 func (chain *MemDag) checkUnitIrreversibleWithGroupSign(unit *modules.Unit) bool {
@@ -294,13 +302,7 @@ func (chain *MemDag) checkUnitIrreversibleWithGroupSign(unit *modules.Unit) bool
 		return false
 	}
 
-	pubKey, err := unit.GetGroupPubKey()
-	if err != nil {
-		log.Debug(err.Error())
-		return false
-	}
-
-	err = bls.Verify(core.Suite, pubKey, unit.UnitHash[:], unit.GetGroupSign())
+	err := validator.NewValidate(nil, nil, nil, chain.ldbPropRep, nil).ValidateUnitGroupSign(unit.Header())
 	if err != nil {
 		log.Debug(err.Error())
 		return false
@@ -317,6 +319,7 @@ func (chain *MemDag) checkStableCondition(unit *modules.Unit, txpool txspool.ITx
 		log.Debugf("the unit(%s) have group sign(%s), make it to irreversible.",
 			unit.UnitHash.TerminalString(), hexutil.Encode(unit.GetGroupSign()))
 		chain.setStableUnit(unit.UnitHash, unit.NumberU64(), txpool)
+		groupSignStableMeter.Mark(1)
 		return true
 	}
 
@@ -467,6 +470,7 @@ func (chain *MemDag) AddStableUnit(unit *modules.Unit) {
 	//Set stable unit
 	chain.stableUnitHash = hash
 	chain.stableUnitHeight = unit.NumberU64()
+	chain.updateHeightMetrics()
 }
 
 func (chain *MemDag) AddUnit(unit *modules.Unit, txpool txspool.ITxPool, isGenerate bool) (common2.IUnitRepository,
@@ -655,10 +659,36 @@ func (chain *MemDag) addUnitHeight(unit *modules.Unit) {
 		all := inter.([]common.Hash)
 		hs = append(hs, all...)
 	}
+	chain.checkDoubleProduction(unit, hs)
 	hs = append(hs, unit.Hash())
 	chain.height_hashs.Store(height, hs)
 }
 
+// 同一高度已有同一个mediator在同一slot生产的其他单元，则发出重复出块的证据
+func (chain *MemDag) checkDoubleProduction(unit *modules.Unit, hashs []common.Hash) {
+	uHash := unit.Hash()
+	for _, hash := range hashs {
+		if hash == uHash {
+			continue
+		}
+		var other *modules.Unit
+		if inter, ok := chain.chainUnits.Load(hash); ok {
+			other = inter.(*ChainTempDb).Unit
+		} else if inter, ok := chain.orphanUnits.Load(hash); ok {
+			other = inter.(*modules.Unit)
+		}
+		if other == nil || other.Author() != unit.Author() || other.Timestamp() != unit.Timestamp() {
+			continue
+		}
+
+		log.Warnf("mediator(%v) produced two units[%s, %s] in the same slot", unit.Author().Str(),
+			hash.String(), uHash.String())
+		evidence := modules.NewMediatorEvidence(other.UnitHeader, unit.UnitHeader)
+		go chain.evidenceFeed.Send(modules.MediatorEvidenceEvent{Evidence: evidence})
+		return
+	}
+}
+
 // 单元稳定后，清空该高度的所有缓存
 func (chain *MemDag) delHeightUnitsAndTemp(height uint64) {
 	to_del_h := make([]uint64, 0)
@@ -796,6 +826,26 @@ func (chain *MemDag) GetLastMainChainUnit() *modules.Unit {
 //设置最新的主链单元，并更新PropDB
 func (chain *MemDag) setLastMainchainUnit(unit *modules.Unit) {
 	chain.lastMainChainUnit = unit
+	chain.updateHeightMetrics()
+}
+
+//更新稳定单元滞后的统计，只统计主链Token的memdag
+func (chain *MemDag) updateHeightMetrics() {
+	if chain.token != dagconfig.DagConfig.GetGasToken() {
+		return
+	}
+	if delta := int64(chain.stableUnitHeight) - stableHeightCounter.Count(); delta > 0 {
+		stableHeightCounter.Inc(delta)
+	}
+	if chain.lastMainChainUnit != nil {
+		height := chain.lastMainChainUnit.NumberU64()
+		unstableHeightGauge.Update(int64(height))
+		if height > chain.stableUnitHeight {
+			stableLagGauge.Update(int64(height - chain.stableUnitHeight))
+		} else {
+			stableLagGauge.Update(0)
+		}
+	}
 }
 
 //查询所有不稳定单元（不包括孤儿单元）
@@ -0,0 +1,29 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package memunit
+
+import "github.com/palletone/go-palletone/statistics/metrics"
+
+var (
+	stableHeightCounter  = metrics.NewRegisteredCounter("dag/memdag/stable/height", nil)  // 最新稳定单元高度，只增不减
+	unstableHeightGauge  = metrics.NewRegisteredGauge("dag/memdag/unstable/height", nil)  // 主链最新单元高度，切换分叉时可能降低
+	stableLagGauge       = metrics.NewRegisteredGauge("dag/memdag/stable/lag", nil)       // 主链最新单元与稳定单元的高度差
+	groupSignStableMeter = metrics.NewRegisteredMeter("dag/memdag/stable/groupsign", nil) // 通过群签名变为稳定的单元
+)
@@ -21,10 +21,11 @@
 package memunit
 
 import (
+	"bytes"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/ptndb"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
-	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -87,17 +88,12 @@ func (i *TempdbIterator) First() bool {
 func (i *TempdbIterator) Last() bool {
 	return i.idx != -1
 }
+//与leveldb相同，移动到第一个不小于key的位置
 func (i *TempdbIterator) Seek(key []byte) bool {
-	if i.idx == -1 {
-		return false
-	}
-	for j := 0; j <= i.idx; j++ {
-		val := i.result[j]
-		if reflect.DeepEqual(key, val.Key) {
-			return true
-		}
-	}
-	return false
+	i.idx = sort.Search(len(i.result), func(j int) bool {
+		return bytes.Compare(i.result[j].Key, key) >= 0
+	})
+	return i.idx < len(i.result)
 }
 func (i *TempdbIterator) Prev() bool {
 	return i.idx != -1
@@ -141,6 +137,10 @@ func (db *Tempdb) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
 	for k, v := range result {
 		kv = append(kv, KeyValue{[]byte(k), v})
 	}
+	//按key排序，与leveldb的遍历顺序一致
+	sort.Slice(kv, func(a, b int) bool {
+		return bytes.Compare(kv[a].Key, kv[b].Key) < 0
+	})
 	return &TempdbIterator{result: kv, idx: -1}
 }
 
@@ -56,3 +56,18 @@ func TestTempdb_Get(t *testing.T) {
 		t.Logf("Key:%s,Value:%s", it.Key(), it.Value())
 	}
 }
+
+func TestTempdb_NewIteratorWithPrefix(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	tmpdb, _ := NewTempdb(db)
+	db.Put([]byte("AC"), []byte("1"))
+	db.Put([]byte("AA"), []byte("1"))
+	tmpdb.Put([]byte("AB"), []byte("2"))
+	tmpdb.Delete([]byte("AA"))
+	it := tmpdb.NewIteratorWithPrefix([]byte("A"))
+	keys := []string{}
+	for ok := it.Seek([]byte("A")); ok; ok = it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	assert.Equal(t, []string{"AB", "AC"}, keys)
+}
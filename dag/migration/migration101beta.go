@@ -146,9 +146,9 @@ func (m *Migration100_101) upgradeMediatorInfo() error {
 		}
 
 		newMediator := &MediatorInfo101{
-			MediatorInfoBase101: oldMediator.MediatorInfoBase101,
-			MediatorApplyInfo:   &core.MediatorApplyInfo{Description: oldMediator.ApplyInfo},
-			MediatorInfoExpand:  oldMediator.MediatorInfoExpand,
+			MediatorInfoBase101:       oldMediator.MediatorInfoBase101,
+			MediatorApplyInfo103alpha: &MediatorApplyInfo103alpha{Description: oldMediator.ApplyInfo},
+			MediatorInfoExpand:        oldMediator.MediatorInfoExpand,
 		}
 
 		err = storage.StoreToRlpBytes(m.statedb, oldMediatorsIterator.Key(), newMediator)
@@ -25,7 +25,6 @@ import (
 	"github.com/palletone/go-palletone/common/ptndb"
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/dag/constants"
-	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/storage"
 )
 
@@ -76,10 +75,10 @@ func (m *Migration102beta_102gamma) upgradeMediatorInfo() error {
 			Node:       oldMediator.Node,
 		}
 
-		newMediator := &modules.MediatorInfo{
-			MediatorInfoBase:   mib,
-			MediatorApplyInfo:  oldMediator.MediatorApplyInfo,
-			MediatorInfoExpand: oldMediator.MediatorInfoExpand,
+		newMediator := &MediatorInfo103alpha{
+			MediatorInfoBase:          mib,
+			MediatorApplyInfo103alpha: oldMediator.MediatorApplyInfo103alpha,
+			MediatorInfoExpand:        oldMediator.MediatorInfoExpand,
 		}
 
 		err = storage.StoreToRlpBytes(m.statedb, oldMediatorsIterator.Key(), newMediator)
@@ -153,6 +152,6 @@ type MediatorInfoBase101 struct {
 
 type MediatorInfo101 struct {
 	*MediatorInfoBase101
-	*core.MediatorApplyInfo
+	*MediatorApplyInfo103alpha
 	*core.MediatorInfoExpand
 }
@@ -25,7 +25,6 @@ import (
 	"github.com/palletone/go-palletone/common/ptndb"
 	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/dag/constants"
-	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/storage"
 	"strconv"
 )
@@ -63,7 +62,7 @@ func (m *Migration102delta_103alpha) upgradeGP() error {
 		return err
 	}
 
-	newData := &modules.GlobalPropertyTemp{}
+	newData := &GlobalProperty103alpha{}
 	newData.ActiveJuries = oldGp.ActiveJuries
 	newData.ActiveMediators = oldGp.ActiveMediators
 	newData.PrecedingMediators = oldGp.PrecedingMediators
@@ -92,98 +91,27 @@ func (m *Migration102delta_103alpha) upgradeGP() error {
 	newData.ChainParameters.ContractSystemVersion = core.DefaultContractSystemVersion
 
 	// =======================chainParameters=============================
-	UccMemory, err := strconv.ParseInt(oldGp.ChainParameters.UccMemory, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.UccMemory = UccMemory
-
-	UccCpuShares, err := strconv.ParseInt(oldGp.ChainParameters.UccCpuShares, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.UccCpuShares = UccCpuShares
-
-	UccCpuQuota, err := strconv.ParseInt(oldGp.ChainParameters.UccCpuQuota, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.UccCpuQuota = UccCpuQuota
-
-	UccDisk, err := strconv.ParseInt(oldGp.ChainParameters.UccDisk, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.UccDisk = UccDisk
-
-	TempUccMemory, err := strconv.ParseInt(oldGp.ChainParameters.TempUccMemory, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.TempUccMemory = TempUccMemory
-
-	TempUccCpuShares, err := strconv.ParseInt(oldGp.ChainParameters.TempUccCpuShares, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.TempUccCpuShares = TempUccCpuShares
-
-	TempUccCpuQuota, err := strconv.ParseInt(oldGp.ChainParameters.TempUccCpuQuota, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.TempUccCpuQuota = TempUccCpuQuota
-
-	ContractSignatureNum, err := strconv.ParseInt(oldGp.ChainParameters.ContractSignatureNum, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractSignatureNum = int(ContractSignatureNum)
-
-	ContractElectionNum, err := strconv.ParseInt(oldGp.ChainParameters.ContractElectionNum, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractElectionNum = int(ContractElectionNum)
-
-	ContractTxTimeoutUnitFee, err := strconv.ParseUint(oldGp.ChainParameters.ContractTxTimeoutUnitFee, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractTxTimeoutUnitFee = ContractTxTimeoutUnitFee
-
-	ContractTxSizeUnitFee, err := strconv.ParseUint(oldGp.ChainParameters.ContractTxSizeUnitFee, 10, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractTxSizeUnitFee = ContractTxSizeUnitFee
+	newData.ChainParameters.UccMemory = oldGp.ChainParameters.UccMemory
+	newData.ChainParameters.UccCpuShares = oldGp.ChainParameters.UccCpuShares
+	newData.ChainParameters.UccCpuQuota = oldGp.ChainParameters.UccCpuQuota
+	newData.ChainParameters.UccDisk = oldGp.ChainParameters.UccDisk
 
-	ContractTxInstallFeeLevel, err := strconv.ParseFloat(oldGp.ChainParameters.ContractTxInstallFeeLevel, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractTxInstallFeeLevel = ContractTxInstallFeeLevel
-
-	ContractTxDeployFeeLevel, err := strconv.ParseFloat(oldGp.ChainParameters.ContractTxDeployFeeLevel, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractTxDeployFeeLevel = ContractTxDeployFeeLevel
+	newData.ChainParameters.TempUccMemory = oldGp.ChainParameters.TempUccMemory
+	newData.ChainParameters.TempUccCpuShares = oldGp.ChainParameters.TempUccCpuShares
+	newData.ChainParameters.TempUccCpuQuota = oldGp.ChainParameters.TempUccCpuQuota
 
-	ContractTxInvokeFeeLevel, err := strconv.ParseFloat(oldGp.ChainParameters.ContractTxInvokeFeeLevel, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractTxInvokeFeeLevel = ContractTxInvokeFeeLevel
+	newData.ChainParameters.ContractSignatureNum = oldGp.ChainParameters.ContractSignatureNum
+	newData.ChainParameters.ContractElectionNum = oldGp.ChainParameters.ContractElectionNum
 
-	ContractTxStopFeeLevel, err := strconv.ParseFloat(oldGp.ChainParameters.ContractTxStopFeeLevel, 64)
-	if err != nil {
-		return err
-	}
-	newData.ChainParameters.ContractTxStopFeeLevel = ContractTxStopFeeLevel
+	newData.ChainParameters.ContractTxTimeoutUnitFee = oldGp.ChainParameters.ContractTxTimeoutUnitFee
+	newData.ChainParameters.ContractTxSizeUnitFee = oldGp.ChainParameters.ContractTxSizeUnitFee
+	newData.ChainParameters.ContractTxInstallFeeLevel = oldGp.ChainParameters.ContractTxInstallFeeLevel
+	newData.ChainParameters.ContractTxDeployFeeLevel = oldGp.ChainParameters.ContractTxDeployFeeLevel
+	newData.ChainParameters.ContractTxInvokeFeeLevel = oldGp.ChainParameters.ContractTxInvokeFeeLevel
+	newData.ChainParameters.ContractTxStopFeeLevel = oldGp.ChainParameters.ContractTxStopFeeLevel
 
 	//新加的
-	newData.ChainParameters.UccDuringTime = core.DefaultContainerDuringTime
+	newData.ChainParameters.UccDuringTime = strconv.FormatInt(core.DefaultContainerDuringTime, 10)
 
 	err = storage.StoreToRlpBytes(m.propdb, constants.GLOBALPROPERTY_KEY, newData)
 	if err != nil {
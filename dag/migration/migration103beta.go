@@ -0,0 +1,297 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developers <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+package migration
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/storage"
+	"github.com/palletone/go-palletone/tokenengine"
+)
+
+type Migration103alpha_103beta struct {
+	dagdb   ptndb.Database
+	idxdb   ptndb.Database
+	utxodb  ptndb.Database
+	statedb ptndb.Database
+	propdb  ptndb.Database
+}
+
+func (m *Migration103alpha_103beta) FromVersion() string {
+	return "1.0.3-alpha"
+}
+
+func (m *Migration103alpha_103beta) ToVersion() string {
+	return "1.0.3-beta"
+}
+
+func (m *Migration103alpha_103beta) ExecuteUpgrade() error {
+	//添加新的系统参数，转换GLOBALPROPERTY结构体
+	if err := m.upgradeGP(); err != nil {
+		return err
+	}
+	//为已花费的UTXO建立地址索引
+	if err := m.indexSpentOutpoints(); err != nil {
+		return err
+	}
+	//MediatorApplyInfo新增了佣金比例
+	if err := m.upgradeMediatorInfo(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Migration103alpha_103beta) indexSpentOutpoints() error {
+	dbop := storage.NewUtxoDb(m.utxodb, tokenengine.Instance)
+	iter := m.utxodb.NewIteratorWithPrefix(constants.SPENT_UTXO_PREFIX)
+	for iter.Next() {
+		stxo := &modules.Stxo{}
+		if err := rlp.DecodeBytes(iter.Value(), stxo); err != nil {
+			log.Errorf("Migrate utxo db,decode stxo error:%s", err.Error())
+			return err
+		}
+		outpoint := modules.KeyToOutpoint(iter.Key()[len(constants.SPENT_UTXO_PREFIX):])
+		address, err := tokenengine.Instance.GetAddressFromScript(stxo.PkScript)
+		if err != nil {
+			continue
+		}
+		if err := dbop.SaveAddrSpentOutpoint(address, outpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migration103alpha_103beta) upgradeMediatorInfo() error {
+	oldMediatorsIterator := m.statedb.NewIteratorWithPrefix(constants.MEDIATOR_INFO_PREFIX)
+	for oldMediatorsIterator.Next() {
+		oldMediator := &MediatorInfo103alpha{}
+		err := rlp.DecodeBytes(oldMediatorsIterator.Value(), oldMediator)
+		if err != nil {
+			log.Debugf(err.Error())
+			return err
+		}
+
+		newMediator := &modules.MediatorInfo{
+			MediatorInfoBase: oldMediator.MediatorInfoBase,
+			MediatorApplyInfo: &core.MediatorApplyInfo{
+				Logo:        oldMediator.Logo,
+				Name:        oldMediator.Name,
+				Location:    oldMediator.Location,
+				Url:         oldMediator.Url,
+				Description: oldMediator.Description,
+				// 已有的mediator保留全部出块奖励，直到自己修改佣金比例
+				CommissionRate: core.MaxCommissionRate,
+			},
+			MediatorInfoExpand: oldMediator.MediatorInfoExpand,
+		}
+
+		err = storage.StoreToRlpBytes(m.statedb, oldMediatorsIterator.Key(), newMediator)
+		if err != nil {
+			log.Debugf(err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migration103alpha_103beta) upgradeGP() error {
+	oldGp := &GlobalProperty103alpha{}
+	err := storage.RetrieveFromRlpBytes(m.propdb, constants.GLOBALPROPERTY_KEY, oldGp)
+	if err != nil {
+		log.Errorf(err.Error())
+		return err
+	}
+
+	cpt := &core.ChainParametersTemp{}
+	cpt.GenerateUnitReward = oldGp.ChainParameters.GenerateUnitReward
+	cpt.PledgeDailyReward = oldGp.ChainParameters.PledgeDailyReward
+	cpt.RewardHeight = oldGp.ChainParameters.RewardHeight
+	cpt.UnitMaxSize = oldGp.ChainParameters.UnitMaxSize
+	cpt.FoundationAddress = oldGp.ChainParameters.FoundationAddress
+	cpt.DepositAmountForMediator = oldGp.ChainParameters.DepositAmountForMediator
+	cpt.DepositAmountForJury = oldGp.ChainParameters.DepositAmountForJury
+	cpt.DepositAmountForDeveloper = oldGp.ChainParameters.DepositAmountForDeveloper
+	cpt.RmExpConFromSysParam = oldGp.ChainParameters.RmExpConFromSysParam
+	cpt.ActiveMediatorCount = oldGp.ChainParameters.ActiveMediatorCount
+	cpt.MaximumMediatorCount = oldGp.ChainParameters.MaximumMediatorCount
+	cpt.MediatorInterval = oldGp.ChainParameters.MediatorInterval
+	cpt.MaintenanceInterval = oldGp.ChainParameters.MaintenanceInterval
+	cpt.MaintenanceSkipSlots = oldGp.ChainParameters.MaintenanceSkipSlots
+	cpt.MediatorCreateFee = oldGp.ChainParameters.MediatorCreateFee
+	cpt.AccountUpdateFee = oldGp.ChainParameters.AccountUpdateFee
+	cpt.TransferPtnBaseFee = oldGp.ChainParameters.TransferPtnBaseFee
+	cpt.TransferPtnPricePerKByte = oldGp.ChainParameters.TransferPtnPricePerKByte
+
+	cpt.UccMemory = oldGp.ChainParameters.UccMemory
+	cpt.UccCpuShares = oldGp.ChainParameters.UccCpuShares
+	cpt.UccCpuQuota = oldGp.ChainParameters.UccCpuQuota
+	cpt.UccDisk = oldGp.ChainParameters.UccDisk
+	cpt.UccDuringTime = oldGp.ChainParameters.UccDuringTime
+	cpt.TempUccMemory = oldGp.ChainParameters.TempUccMemory
+	cpt.TempUccCpuShares = oldGp.ChainParameters.TempUccCpuShares
+	cpt.TempUccCpuQuota = oldGp.ChainParameters.TempUccCpuQuota
+	cpt.ContractSystemVersion = oldGp.ChainParameters.ContractSystemVersion
+	cpt.ContractSignatureNum = oldGp.ChainParameters.ContractSignatureNum
+	cpt.ContractElectionNum = oldGp.ChainParameters.ContractElectionNum
+	cpt.ContractTxTimeoutUnitFee = oldGp.ChainParameters.ContractTxTimeoutUnitFee
+	cpt.ContractTxSizeUnitFee = oldGp.ChainParameters.ContractTxSizeUnitFee
+	cpt.ContractTxInstallFeeLevel = oldGp.ChainParameters.ContractTxInstallFeeLevel
+	cpt.ContractTxDeployFeeLevel = oldGp.ChainParameters.ContractTxDeployFeeLevel
+	cpt.ContractTxInvokeFeeLevel = oldGp.ChainParameters.ContractTxInvokeFeeLevel
+	cpt.ContractTxStopFeeLevel = oldGp.ChainParameters.ContractTxStopFeeLevel
+
+	//新加的
+	setNewChainParameters103beta(&cpt.ChainParametersBase)
+
+	//经过ChainParameters的rlp解码，完成字符串形式参数的转换和校验
+	data, err := rlp.EncodeToBytes(cpt)
+	if err != nil {
+		return err
+	}
+	newData := &modules.GlobalPropertyTemp{}
+	if err = rlp.DecodeBytes(data, &newData.ChainParameters); err != nil {
+		log.Errorf(err.Error())
+		return err
+	}
+	newData.ImmutableParameters = oldGp.ImmutableParameters
+	newData.ActiveJuries = oldGp.ActiveJuries
+	newData.ActiveMediators = oldGp.ActiveMediators
+	newData.PrecedingMediators = oldGp.PrecedingMediators
+
+	err = storage.StoreToRlpBytes(m.propdb, constants.GLOBALPROPERTY_KEY, newData)
+	if err != nil {
+		log.Errorf(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// 1.0.3-beta 新增的系统参数使用默认值
+func setNewChainParameters103beta(cp *core.ChainParametersBase) {
+	cp.MaxConsecutiveMissedSlots = core.DefaultMaxConsecutiveMissedSlots
+	cp.GroupSignScheme = core.DefaultGroupSignScheme
+	// 已有的链通过修改系统参数的投票激活新功能
+	cp.PolicyScriptHeight = core.NeverActivated
+	cp.UtxoCommitmentHeight = core.NeverActivated
+	cp.StateCommitmentHeight = core.NeverActivated
+
+	cp.ContractCallCost = core.DefaultContractCallCost
+	cp.ContractReadByteCost = core.DefaultContractReadByteCost
+	cp.ContractWriteByteCost = core.DefaultContractWriteByteCost
+	cp.ContractPayoutCost = core.DefaultContractPayoutCost
+	cp.ContractPayloadByteCost = core.DefaultContractPayloadByteCost
+	cp.ContractCostLimit = core.DefaultContractCostLimit
+	cp.ContractCostUnitFee = core.DefaultContractCostUnitFee
+	cp.ContractInstructionCost = core.DefaultContractInstructionCost
+}
+
+type MediatorInfo103alpha struct {
+	*core.MediatorInfoBase
+	*MediatorApplyInfo103alpha
+	*core.MediatorInfoExpand
+}
+
+type MediatorApplyInfo103alpha struct {
+	Logo        string `json:"logo"`      // 节点图标url
+	Name        string `json:"name"`      // 节点名称
+	Location    string `json:"loc"`       // 节点所在地区
+	Url         string `json:"url"`       // 节点宣传网站
+	Description string `json:"applyInfo"` // 节点详细信息描述
+}
+
+type GlobalProperty103alpha struct {
+	GlobalPropBase103alpha
+	ActiveJuries       []common.Address
+	ActiveMediators    []common.Address
+	PrecedingMediators []common.Address
+}
+
+type GlobalPropBase103alpha struct {
+	ImmutableParameters core.ImmutableChainParameters // 不可改变的区块链网络参数
+	ChainParameters     ChainParameters103alpha       // 区块链网络参数
+}
+
+type ChainParameters103alpha struct {
+	ChainParametersBase103alpha
+
+	UccMemory     string
+	UccCpuShares  string
+	UccCpuQuota   string
+	UccDisk       string
+	UccDuringTime string
+
+	TempUccMemory    string
+	TempUccCpuShares string
+	TempUccCpuQuota  string
+
+	ContractSystemVersion string
+	ContractSignatureNum  string
+	ContractElectionNum   string
+
+	ContractTxTimeoutUnitFee  string
+	ContractTxSizeUnitFee     string
+	ContractTxInstallFeeLevel string
+	ContractTxDeployFeeLevel  string
+	ContractTxInvokeFeeLevel  string
+	ContractTxStopFeeLevel    string
+}
+
+type ChainParametersBase103alpha struct {
+	GenerateUnitReward uint64 `json:"generate_unit_reward"` //每生产一个单元，奖励多少Dao的PTN
+	PledgeDailyReward  uint64 `json:"pledge_daily_reward"`  //质押金的日奖励额
+	RewardHeight       uint64 `json:"reward_height"`        //每多少高度进行一次奖励的派发
+	UnitMaxSize        uint64 `json:"unit_max_size"`        //一个单元最大允许多大
+	FoundationAddress  string `json:"foundation_address"`   //基金会地址，该地址具有一些特殊权限，比如发起参数修改的投票，发起罚没保证金等
+
+	DepositAmountForMediator  uint64 `json:"deposit_amount_for_mediator"` //保证金的数量
+	DepositAmountForJury      uint64 `json:"deposit_amount_for_jury"`
+	DepositAmountForDeveloper uint64 `json:"deposit_amount_for_developer"`
+	RmExpConFromSysParam      bool   `json:"remove_expired_container_from_system_parameter"`
+
+	// 活跃mediator的数量。 number of active mediators
+	ActiveMediatorCount uint8 `json:"active_mediator_count"`
+
+	// 用户可投票mediator的最大数量。the maximum number of mediator users can vote for
+	MaximumMediatorCount uint8 `json:"max_mediator_count"`
+
+	// unit生产之间的间隔时间，以秒为单元。 interval in seconds between Units
+	MediatorInterval uint8 `json:"mediator_interval"`
+
+	// 区块链维护事件之间的间隔，以秒为单元。 interval in sections between unit maintenance events
+	MaintenanceInterval uint32 `json:"maintenance_interval"`
+
+	// 在维护时跳过的MediatorInterval数量。 number of MediatorInterval to skip at maintenance time
+	MaintenanceSkipSlots uint8 `json:"maintenance_skip_slots"`
+
+	// 目前的操作交易费，current schedule of fees
+	MediatorCreateFee        uint64 `json:"mediator_create_fee"`
+	AccountUpdateFee         uint64 `json:"account_update_fee"`
+	TransferPtnBaseFee       uint64 `json:"transfer_ptn_base_fee"`
+	TransferPtnPricePerKByte uint64 `json:"transfer_ptn_price_per_KByte"`
+}
@@ -0,0 +1,85 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+package migration
+
+import (
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigration103alpha_103beta(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	mediator := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	oldGp := &GlobalProperty103alpha{}
+	oldGp.ImmutableParameters = core.NewImmutChainParams()
+	oldGp.ActiveMediators = []common.Address{mediator}
+	oldGp.ChainParameters.FoundationAddress = "P1Kp2hcLhGEP45Xgx7vmSrE37QXunJUd8gJ"
+	oldGp.ChainParameters.ActiveMediatorCount = 3
+	oldGp.ChainParameters.UccMemory = "1024"
+	oldGp.ChainParameters.UccCpuShares = "1"
+	oldGp.ChainParameters.UccCpuQuota = "2"
+	oldGp.ChainParameters.UccDisk = "3"
+	oldGp.ChainParameters.UccDuringTime = "4"
+	oldGp.ChainParameters.TempUccMemory = "5"
+	oldGp.ChainParameters.TempUccCpuShares = "6"
+	oldGp.ChainParameters.TempUccCpuQuota = "7"
+	oldGp.ChainParameters.ContractSignatureNum = "3"
+	oldGp.ChainParameters.ContractElectionNum = "4"
+	oldGp.ChainParameters.ContractTxTimeoutUnitFee = "10"
+	oldGp.ChainParameters.ContractTxSizeUnitFee = "1"
+	oldGp.ChainParameters.ContractTxInstallFeeLevel = "2.5"
+	oldGp.ChainParameters.ContractTxDeployFeeLevel = "2"
+	oldGp.ChainParameters.ContractTxInvokeFeeLevel = "1"
+	oldGp.ChainParameters.ContractTxStopFeeLevel = "0.5"
+	assert.Nil(t, storage.StoreToRlpBytes(db, constants.GLOBALPROPERTY_KEY, oldGp))
+	oldMediator := &MediatorInfo103alpha{
+		MediatorInfoBase:          &core.MediatorInfoBase{AddStr: mediator.String()},
+		MediatorApplyInfo103alpha: &MediatorApplyInfo103alpha{Name: "m1", Description: "desc"},
+		MediatorInfoExpand:        &core.MediatorInfoExpand{TotalMissed: 2},
+	}
+	assert.Nil(t, storage.StoreToRlpBytes(db, append(constants.MEDIATOR_INFO_PREFIX, mediator.Bytes()...),
+		oldMediator))
+
+	m := NewMigration103alpha_103beta(db)
+	assert.Nil(t, m.ExecuteUpgrade())
+
+	gp, err := storage.NewPropertyDb(db).RetrieveGlobalProp()
+	assert.Nil(t, err)
+	assert.True(t, gp.ActiveMediators[mediator])
+	assert.Equal(t, "P1Kp2hcLhGEP45Xgx7vmSrE37QXunJUd8gJ", gp.ChainParameters.FoundationAddress)
+	assert.Equal(t, uint8(3), gp.ChainParameters.ActiveMediatorCount)
+	assert.Equal(t, int64(1024), gp.ChainParameters.UccMemory)
+	assert.Equal(t, 2.5, gp.ChainParameters.ContractTxInstallFeeLevel)
+	assert.Equal(t, uint32(core.DefaultMaxConsecutiveMissedSlots), gp.ChainParameters.MaxConsecutiveMissedSlots)
+
+	mi, err := storage.NewStateDb(db).RetrieveMediatorInfo(mediator)
+	assert.Nil(t, err)
+	assert.Equal(t, "m1", mi.Name)
+	assert.Equal(t, "desc", mi.Description)
+	assert.Equal(t, uint64(2), mi.TotalMissed)
+	assert.Equal(t, uint16(core.MaxCommissionRate), mi.CommissionRate)
+}
@@ -51,6 +51,9 @@ func NewMigrations(db ptndb.Database) map[string]IMigration {
 	m_103_alpha := NewMigration102delta_103alpha(db)
 	migrations[m_103_alpha.FromVersion()] = m_103_alpha
 
+	m_103_beta := NewMigration103alpha_103beta(db)
+	migrations[m_103_beta.FromVersion()] = m_103_beta
+
 	/* version: 1.0.0-beta end */
 	/* version: 1.0.0-beta */
 	//m_101_beta := NewNothingMigration("1.0.1-beta", "1.0.2-beta")
@@ -82,3 +85,7 @@ func NewMigration102gamma_102delta(db ptndb.Database) *Migration102gamma_102delt
 func NewMigration102delta_103alpha(db ptndb.Database) *Migration102delta_103alpha {
 	return &Migration102delta_103alpha{dagdb: db, idxdb: db, utxodb: db, statedb: db, propdb: db}
 }
+
+func NewMigration103alpha_103beta(db ptndb.Database) *Migration103alpha_103beta {
+	return &Migration103alpha_103beta{dagdb: db, idxdb: db, utxodb: db, statedb: db, propdb: db}
+}
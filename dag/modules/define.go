@@ -31,3 +31,10 @@ type KeyValue struct {
 	Key   string
 	Value []byte
 }
+
+// 合约状态范围查询的一批结果
+type RangeQueryResult struct {
+	KVs []*KeyValue `json:"kvs"`
+	//下一批结果的起始key，为空表示没有更多结果
+	Bookmark string `json:"bookmark"`
+}
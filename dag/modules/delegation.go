@@ -0,0 +1,124 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/shopspring/decimal"
+)
+
+//  委托质押相关
+const (
+	DelegateToMediator       = "DelegateToMediator"
+	UndelegateFromMediator   = "UndelegateFromMediator"
+	QueryMediatorDelegations = "QueryMediatorDelegations"
+	QueryDelegationsByAddr   = "QueryDelegationsByAddr"
+)
+
+//委托人委托给某个mediator的质押
+type MediatorDelegation struct {
+	Mediator  string `json:"mediator"`
+	Delegator string `json:"delegator"`
+	Amount    uint64 `json:"amount"`
+}
+
+type MediatorDelegationJson struct {
+	Mediator  string          `json:"mediator"`
+	Delegator string          `json:"delegator"`
+	Amount    decimal.Decimal `json:"amount"`
+}
+
+func (d *MediatorDelegation) ToJson(gasToken *Asset) *MediatorDelegationJson {
+	return &MediatorDelegationJson{
+		Mediator:  d.Mediator,
+		Delegator: d.Delegator,
+		Amount:    gasToken.DisplayAmount(d.Amount),
+	}
+}
+
+//保证金合约中某个mediator的所有委托的key前缀
+func MediatorDelegationKeyPrefix(mediator string) string {
+	return string(constants.MEDIATOR_DELEGATION_PREFIX) + mediator + "_"
+}
+
+func (d *MediatorDelegation) Key() string {
+	return MediatorDelegationKeyPrefix(d.Mediator) + d.Delegator
+}
+
+//从保证金合约的状态数据中解析出委托列表，按委托人地址排序以保证各节点计算结果一致
+func ParseMediatorDelegations(states map[string]*ContractStateValue) ([]*MediatorDelegation, error) {
+	result := make([]*MediatorDelegation, 0, len(states))
+	for _, v := range states {
+		d := &MediatorDelegation{}
+		err := json.Unmarshal(v.Value, d)
+		if err != nil {
+			return nil, err
+		}
+		if d.Amount > 0 {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Delegator < result[j].Delegator
+	})
+	return result, nil
+}
+
+//将mediator的出块奖励按佣金比例拆分，mediator获得佣金和取整余数，其余按委托数量比例分给各委托人
+func SplitMediatorReward(rewardAdd common.Address, commissionRate uint16, reward uint64, asset *Asset,
+	delegations []*MediatorDelegation) []*Addition {
+	total := uint64(0)
+	for _, d := range delegations {
+		total += d.Amount
+	}
+	if total == 0 || reward == 0 || commissionRate >= core.MaxCommissionRate {
+		return []*Addition{{Addr: rewardAdd, Amount: reward, Asset: asset}}
+	}
+
+	shared := new(big.Int).SetUint64(reward)
+	shared.Mul(shared, big.NewInt(int64(core.MaxCommissionRate-commissionRate)))
+	shared.Div(shared, big.NewInt(core.MaxCommissionRate))
+
+	ads := make([]*Addition, 0, len(delegations)+1)
+	allocated := uint64(0)
+	for _, d := range delegations {
+		addr, err := common.StringToAddress(d.Delegator)
+		if err != nil {
+			continue
+		}
+		amount := new(big.Int).SetUint64(d.Amount)
+		amount.Mul(amount, shared)
+		amount.Div(amount, new(big.Int).SetUint64(total))
+		if amount.Sign() == 0 {
+			continue
+		}
+		ads = append(ads, &Addition{Addr: addr, Amount: amount.Uint64(), Asset: asset})
+		allocated += amount.Uint64()
+	}
+
+	return append(ads, &Addition{Addr: rewardAdd, Amount: reward - allocated, Asset: asset})
+}
@@ -0,0 +1,56 @@
+package modules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMediatorReward(t *testing.T) {
+	med, _ := common.StringToAddress("P1NzevLMVCFJKWr4KAcHxyyh9xXaVU8yv3N")
+	d1, _ := common.StringToAddress("P1HXNZReTByQHgWQNGMXotMyTkMG9XeEQfX")
+	d2, _ := common.StringToAddress("P1EZE1HAqMZATrdTkpgmizoRV21rj4pm3db")
+	asset := NewPTNAsset()
+	delegations := []*MediatorDelegation{
+		{Mediator: med.String(), Delegator: d1.String(), Amount: 100},
+		{Mediator: med.String(), Delegator: d2.String(), Amount: 200},
+	}
+
+	//mediator抽取20%佣金，剩余80按1:2分配，取整余数归mediator
+	ads := SplitMediatorReward(med, 2000, 100, asset, delegations)
+	result := make(map[common.Address]uint64)
+	total := uint64(0)
+	for _, a := range ads {
+		result[a.Addr] += a.Amount
+		total += a.Amount
+	}
+	assert.Equal(t, uint64(100), total)
+	assert.Equal(t, uint64(26), result[d1])
+	assert.Equal(t, uint64(53), result[d2])
+	assert.Equal(t, uint64(21), result[med])
+
+	//没有委托时全部奖励归mediator
+	ads = SplitMediatorReward(med, 0, 100, asset, nil)
+	assert.Equal(t, 1, len(ads))
+	assert.Equal(t, uint64(100), ads[0].Amount)
+	assert.Equal(t, med, ads[0].Addr)
+}
+
+func TestParseMediatorDelegations(t *testing.T) {
+	states := make(map[string]*ContractStateValue)
+	for _, d := range []*MediatorDelegation{
+		{Mediator: "P1NzevLMVCFJKWr4KAcHxyyh9xXaVU8yv3N", Delegator: "P1b", Amount: 1},
+		{Mediator: "P1NzevLMVCFJKWr4KAcHxyyh9xXaVU8yv3N", Delegator: "P1a", Amount: 2},
+		{Mediator: "P1NzevLMVCFJKWr4KAcHxyyh9xXaVU8yv3N", Delegator: "P1c", Amount: 0},
+	} {
+		data, _ := json.Marshal(d)
+		states[d.Key()] = &ContractStateValue{Value: data}
+	}
+	list, err := ParseMediatorDelegations(states)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "P1a", list[0].Delegator)
+	assert.Equal(t, "P1b", list[1].Delegator)
+}
@@ -45,3 +45,8 @@ type ChainMaintenanceEvent struct {
 
 type ToGroupSignEvent struct {
 }
+
+// 发现mediator在同一slot生产了两个单元
+type MediatorEvidenceEvent struct {
+	Evidence *MediatorEvidencePayload
+}
@@ -0,0 +1,105 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/util"
+)
+
+//  根据作恶证据没收mediator保证金
+const SlashMediatorByEvidence = "SlashMediatorByEvidence"
+
+//  保证金合约中记录已处理证据的key前缀
+const MediatorEvidencePrefix = "MediatorEvidence_"
+
+// mediator在同一个生产slot签名了两个不同unit的证据
+type MediatorEvidencePayload struct {
+	Header1 *Header `json:"header1"`
+	Header2 *Header `json:"header2"`
+}
+
+// 构建证据，去掉群签名以减小体积，并按不含签名的hash排序使相同的两个header得到相同的证据
+func NewMediatorEvidence(h1, h2 *Header) *MediatorEvidencePayload {
+	c1, c2 := CopyHeader(h1), CopyHeader(h2)
+	for _, h := range []*Header{c1, c2} {
+		if h != nil {
+			h.GroupSign = nil
+			h.GroupPubKey = nil
+		}
+	}
+	if c1 != nil && c2 != nil {
+		hash1, hash2 := c1.HashWithoutAuthor(), c2.HashWithoutAuthor()
+		if bytes.Compare(hash1[:], hash2[:]) > 0 {
+			c1, c2 = c2, c1
+		}
+	}
+
+	return &MediatorEvidencePayload{Header1: c1, Header2: c2}
+}
+
+func (e *MediatorEvidencePayload) Hash() common.Hash {
+	return util.RlpHash(e)
+}
+
+// 验证证据是否有效，有效则返回作恶的mediator地址
+func (e *MediatorEvidencePayload) Verify() (common.Address, error) {
+	h1, h2 := e.Header1, e.Header2
+	if h1 == nil || h2 == nil || h1.Number == nil || h2.Number == nil {
+		return common.Address{}, errors.New("evidence header is incomplete")
+	}
+	if h1.Authors.Empty() || h2.Authors.Empty() {
+		return common.Address{}, errors.New("evidence header has no author")
+	}
+
+	author := h1.Authors.Address()
+	if author != h2.Authors.Address() {
+		return common.Address{}, errors.New("the authors of the two headers are different")
+	}
+
+	// 同一个生产slot：单元时间相同且是同一条链
+	if h1.Time != h2.Time || h1.Number.AssetID != h2.Number.AssetID {
+		return common.Address{}, fmt.Errorf("the two headers are not in the same slot: %v, %v", h1.Time, h2.Time)
+	}
+
+	// 只比较签名的内容，否则重新编码一个诚实header的签名就能伪造证据
+	if h1.HashWithoutAuthor() == h2.HashWithoutAuthor() {
+		return common.Address{}, errors.New("the two headers are the same")
+	}
+
+	for _, h := range []*Header{h1, h2} {
+		if !crypto.IsCanonicalSignature(h.Authors.PubKey, h.Authors.Signature) {
+			return common.Address{}, fmt.Errorf("non-canonical author signature of header[%v]",
+				h.Hash().TerminalString())
+		}
+		hash := h.HashWithoutAuthor()
+		if pass, _ := crypto.MyCryptoLib.Verify(h.Authors.PubKey, h.Authors.Signature, hash.Bytes()); !pass {
+			return common.Address{}, fmt.Errorf("invalid author signature of header[%v]", h.Hash().TerminalString())
+		}
+	}
+
+	return author, nil
+}
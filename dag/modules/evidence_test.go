@@ -0,0 +1,108 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignedHeader(key *ecdsa.PrivateKey, parent common.Hash, time int64) *Header {
+	h := &Header{
+		ParentsHash: []common.Hash{parent},
+		Number:      &ChainIndex{AssetID: PTNCOIN, Index: 10},
+		Time:        time,
+		GroupSign:   []byte("group sign"),
+	}
+	hash := h.HashWithoutAuthor()
+	sign, _ := crypto.MyCryptoLib.Sign(crypto.FromECDSA(key), hash[:])
+	h.Authors = Authentifier{PubKey: crypto.CompressPubkey(&key.PublicKey), Signature: sign}
+	return h
+}
+
+func TestMediatorEvidence_Verify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	h1 := newSignedHeader(key, common.HexToHash("0x01"), 1565000000)
+	h2 := newSignedHeader(key, common.HexToHash("0x02"), 1565000000)
+
+	evidence := NewMediatorEvidence(h2, h1)
+	addr, err := evidence.Verify()
+	assert.Nil(t, err)
+	assert.Equal(t, h1.Author(), addr)
+	assert.Equal(t, 0, len(evidence.Header1.GroupSign))
+	//证据与header的顺序无关
+	assert.Equal(t, evidence.Hash(), NewMediatorEvidence(h1, h2).Hash())
+
+	data, err := rlp.EncodeToBytes(evidence)
+	assert.Nil(t, err)
+	evidence2 := &MediatorEvidencePayload{}
+	assert.Nil(t, rlp.DecodeBytes(data, evidence2))
+	_, err = evidence2.Verify()
+	assert.Nil(t, err)
+
+	//同一个header不是证据
+	_, err = NewMediatorEvidence(h1, h1).Verify()
+	assert.NotNil(t, err)
+
+	//不同slot不是证据
+	h3 := newSignedHeader(key, common.HexToHash("0x03"), 1565000003)
+	_, err = NewMediatorEvidence(h1, h3).Verify()
+	assert.NotNil(t, err)
+
+	//不同mediator不是证据
+	key2, _ := crypto.GenerateKey()
+	h4 := newSignedHeader(key2, common.HexToHash("0x04"), 1565000000)
+	_, err = NewMediatorEvidence(h1, h4).Verify()
+	assert.NotNil(t, err)
+
+	//同一个header的签名换一种编码不是证据
+	sig, _ := btcec.ParseDERSignature(h1.Authors.Signature, btcec.S256())
+	rs := append(common.LeftPadBytes(sig.R.Bytes(), 32), common.LeftPadBytes(sig.S.Bytes(), 32)...)
+	h1raw := CopyHeader(h1)
+	h1raw.Authors.Signature = rs
+	pass, _ := crypto.MyCryptoLib.Verify(h1raw.Authors.PubKey, rs, h1raw.HashWithoutAuthor().Bytes())
+	assert.True(t, pass)
+	_, err = NewMediatorEvidence(h1, h1raw).Verify()
+	assert.NotNil(t, err)
+	h1raw.Authors.Signature = h1.Authors.Signature
+	h1raw.Authors.PubKey = crypto.FromECDSAPub(&key.PublicKey)
+	_, err = NewMediatorEvidence(h1, h1raw).Verify()
+	assert.NotNil(t, err)
+
+	//签名编码不规范
+	h6 := newSignedHeader(key, common.HexToHash("0x06"), 1565000000)
+	sig6, _ := btcec.ParseDERSignature(h6.Authors.Signature, btcec.S256())
+	h6.Authors.Signature = append(common.LeftPadBytes(sig6.R.Bytes(), 32), common.LeftPadBytes(sig6.S.Bytes(), 32)...)
+	_, err = NewMediatorEvidence(h1, h6).Verify()
+	assert.NotNil(t, err)
+
+	//签名被篡改
+	h5 := newSignedHeader(key, common.HexToHash("0x05"), 1565000000)
+	h5.TxRoot = common.HexToHash("0x06")
+	_, err = NewMediatorEvidence(h1, h5).Verify()
+	assert.NotNil(t, err)
+}
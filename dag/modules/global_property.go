@@ -97,7 +97,49 @@ type DynamicGlobalProperty struct {
 	//
 	// This flag answers the question, "Was maintenance performed in the last call to ApplyUnit()?"
 	MaintenanceFlag bool
+
+	// 记录每个mediator连续错过的生产slot数量，mediator正常生产unit后清零。
+	// 使用 tail 标签，以兼容没有该字段的旧数据
+	ConsecutiveMissedSlots []*MediatorMissedSlots `rlp:"tail"`
+}
+
+type MediatorMissedSlots struct {
+	Mediator common.Address
+	Count    uint32
+}
+
+// 增加mediator连续错过的slot数量，并返回增加后的数量
+func (dgp *DynamicGlobalProperty) AddMissedSlot(med common.Address) uint32 {
+	for _, ms := range dgp.ConsecutiveMissedSlots {
+		if ms.Mediator == med {
+			ms.Count++
+			return ms.Count
+		}
+	}
+
+	dgp.ConsecutiveMissedSlots = append(dgp.ConsecutiveMissedSlots, &MediatorMissedSlots{Mediator: med, Count: 1})
+	return 1
 }
+
+func (dgp *DynamicGlobalProperty) GetMissedSlots(med common.Address) uint32 {
+	for _, ms := range dgp.ConsecutiveMissedSlots {
+		if ms.Mediator == med {
+			return ms.Count
+		}
+	}
+
+	return 0
+}
+
+func (dgp *DynamicGlobalProperty) ResetMissedSlots(med common.Address) {
+	for i, ms := range dgp.ConsecutiveMissedSlots {
+		if ms.Mediator == med {
+			dgp.ConsecutiveMissedSlots = append(dgp.ConsecutiveMissedSlots[:i], dgp.ConsecutiveMissedSlots[i+1:]...)
+			return
+		}
+	}
+}
+
 type UnitProperty struct {
 	Hash      common.Hash // 最近的单元hash
 	Index     *ChainIndex // 最近的单元编号(数量)
@@ -116,6 +158,8 @@ func NewDynGlobalProp() *DynamicGlobalProperty {
 		RecentSlotsFilled: ^uint64(0),
 
 		MaintenanceFlag: false,
+
+		ConsecutiveMissedSlots: make([]*MediatorMissedSlots, 0),
 	}
 }
 
@@ -56,3 +56,30 @@ func TestGlobalProperty_Rlp(t *testing.T) {
 	assert.Equal(t, uint8(21), gp2.ChainParameters.MaximumMediatorCount)
 	assert.Equal(t, 2, len(gp2.ActiveMediators))
 }
+
+func TestDynamicGlobalProperty_MissedSlots(t *testing.T) {
+	addr1, _ := common.StringToAddress("P1Kp2hcLhGEP45Xgx7vmSrE37QXunJUd8gj")
+	addr2, _ := common.StringToAddress("P124gB1bXHDTXmox58g4hd4u13HV3e5vKie")
+	dgp := NewDynGlobalProp()
+	assert.Equal(t, uint32(1), dgp.AddMissedSlot(addr1))
+	assert.Equal(t, uint32(2), dgp.AddMissedSlot(addr1))
+	dgp.AddMissedSlot(addr2)
+
+	data, err := rlp.EncodeToBytes(dgp)
+	assert.Nil(t, err)
+	dgp2 := &DynamicGlobalProperty{}
+	assert.Nil(t, rlp.DecodeBytes(data, dgp2))
+	assert.Equal(t, uint32(2), dgp2.GetMissedSlots(addr1))
+	assert.Equal(t, uint32(1), dgp2.GetMissedSlots(addr2))
+
+	dgp2.ResetMissedSlots(addr1)
+	assert.Equal(t, uint32(0), dgp2.GetMissedSlots(addr1))
+	assert.Equal(t, uint32(1), dgp2.GetMissedSlots(addr2))
+
+	//兼容没有连续错过slot记录的旧数据
+	dgp.ConsecutiveMissedSlots = nil
+	data, _ = rlp.EncodeToBytes(dgp)
+	dgp3 := &DynamicGlobalProperty{}
+	assert.Nil(t, rlp.DecodeBytes(data, dgp3))
+	assert.Equal(t, 0, len(dgp3.ConsecutiveMissedSlots))
+}
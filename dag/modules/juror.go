@@ -0,0 +1,165 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/util"
+)
+
+const (
+	// 信誉满分
+	JurorFullReputation = 100
+	// 一次被证实的不一致结果相当于多少次超时
+	JurorDivergentWeight = 5
+	// 样本数少于该值时不计算信誉，视为满分，避免新陪审员被误判
+	JurorReputationMinSamples = 10
+)
+
+// 陪审员执行合约的链上统计
+type JurorStats struct {
+	Address      common.Address `json:"address"`
+	Participated uint64         `json:"participated"` // 在链上结果交易中签名的次数
+	Divergent    uint64         `json:"divergent"`    // 被证据证实返回不一致结果的次数
+	Timeouts     uint64         `json:"timeouts"`     // 结果交易签名数不足门限时，陪审团中没有签名的次数
+	Excluded     bool           `json:"excluded"`     // 上次维护时信誉低于门限，下次维护前不参与陪审团选举
+}
+
+func NewJurorStats(addr common.Address) *JurorStats {
+	return &JurorStats{Address: addr}
+}
+
+// 陪审团签名的门限，与陪审团赎回脚本中需要的签名数一致
+func JuryThreshold(count int) int {
+	return int(math.Ceil((float64(count)*2 + 1) / 3))
+}
+
+// 信誉值，范围0~100，不一致结果的惩罚权重大于超时
+func (s *JurorStats) Reputation() uint32 {
+	if s == nil {
+		return JurorFullReputation
+	}
+	total := s.Participated + s.Timeouts + s.Divergent*JurorDivergentWeight
+	if total < JurorReputationMinSamples {
+		return JurorFullReputation
+	}
+	good := s.Participated
+	if good > s.Divergent {
+		good -= s.Divergent
+	} else {
+		good = 0
+	}
+
+	return uint32(good * JurorFullReputation / total)
+}
+
+// 陪审员签名了一个与链上被接受结果不一致的合约执行结果的证据
+type JurorEvidencePayload struct {
+	AcceptedTx common.Hash  `json:"accepted_tx"` // 链上被接受的结果交易
+	Result     []byte       `json:"result"`      // 陪审员签名的结果交易，即去掉签名后的rlp编码
+	Signature  SignatureSet `json:"signature"`   // 陪审员对Result的签名
+}
+
+// 根据陪审员广播的签名交易构建证据
+func NewJurorEvidence(accepted common.Hash, sigTx *Transaction, sig SignatureSet) (*JurorEvidencePayload, error) {
+	if sigTx == nil {
+		return nil, errors.New("sig tx is nil")
+	}
+	result, err := rlp.EncodeToBytes(sigTx.GetResultRawTx())
+	if err != nil {
+		return nil, err
+	}
+
+	return &JurorEvidencePayload{AcceptedTx: accepted, Result: result, Signature: sig}, nil
+}
+
+func (e *JurorEvidencePayload) Hash() common.Hash {
+	return util.RlpHash(e)
+}
+
+func (e *JurorEvidencePayload) ResultTx() (*Transaction, error) {
+	tx := &Transaction{}
+	err := rlp.DecodeBytes(e.Result, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// 验证陪审员的签名，有效则返回陪审员地址
+func (e *JurorEvidencePayload) Verify() (common.Address, error) {
+	if len(e.Result) == 0 || len(e.Signature.PubKey) == 0 || len(e.Signature.Signature) == 0 {
+		return common.Address{}, errors.New("evidence is incomplete")
+	}
+	if _, err := e.ResultTx(); err != nil {
+		return common.Address{}, fmt.Errorf("invalid result tx: %s", err.Error())
+	}
+	if pass, _ := crypto.MyCryptoLib.Verify(e.Signature.PubKey, e.Signature.Signature, e.Result); !pass {
+		return common.Address{}, errors.New("invalid juror signature")
+	}
+
+	return crypto.PubkeyBytesToAddress(e.Signature.PubKey), nil
+}
+
+// 检查证据中的结果是否与链上被接受的结果属于同一请求且内容不同
+func (e *JurorEvidencePayload) CheckDivergent(accepted *Transaction) error {
+	if accepted == nil || accepted.Hash() != e.AcceptedTx {
+		return errors.New("accepted tx does not match the evidence")
+	}
+	result, err := e.ResultTx()
+	if err != nil {
+		return err
+	}
+	if result.RequestHash() != accepted.RequestHash() {
+		return fmt.Errorf("the request of the evidence[%s] is not the accepted one[%s]",
+			result.RequestHash().String(), accepted.RequestHash().String())
+	}
+	acceptedResult, err := rlp.EncodeToBytes(accepted.GetResultRawTx())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(acceptedResult, e.Result) {
+		return errors.New("the result of the evidence is the same as the accepted one")
+	}
+
+	return nil
+}
+
+// 地址是否是陪审团成员
+func (e *ElectionNode) IsJuror(addr common.Address) bool {
+	if e == nil {
+		return false
+	}
+	for _, ele := range e.EleList {
+		if crypto.PubkeyBytesToAddress(ele.PublicKey) == addr {
+			return true
+		}
+	}
+	return false
+}
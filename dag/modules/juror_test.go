@@ -0,0 +1,119 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJurorStats_Reputation(t *testing.T) {
+	stats := &JurorStats{}
+	assert.Equal(t, uint32(JurorFullReputation), stats.Reputation())
+
+	//样本太少时视为满分
+	stats.Timeouts = JurorReputationMinSamples - 1
+	assert.Equal(t, uint32(JurorFullReputation), stats.Reputation())
+
+	stats.Participated = 10
+	stats.Timeouts = 10
+	assert.Equal(t, uint32(50), stats.Reputation())
+
+	//不一致结果的惩罚重于超时
+	stats2 := &JurorStats{Participated: 10, Divergent: 2}
+	assert.True(t, stats2.Reputation() < stats.Reputation())
+}
+
+func newJurorResultTx(payload string) *Transaction {
+	tx := &Transaction{}
+	tx.AddMessage(NewMessage(APP_CONTRACT_INVOKE_REQUEST,
+		&ContractInvokeRequestPayload{ContractId: []byte("contract"), Args: [][]byte{[]byte("put")}}))
+	tx.AddMessage(NewMessage(APP_CONTRACT_INVOKE,
+		&ContractInvokePayload{ContractId: []byte("contract"), Payload: []byte(payload)}))
+	return tx
+}
+
+func signJurorResultTx(key *ecdsa.PrivateKey, tx *Transaction) SignatureSet {
+	data, _ := rlp.EncodeToBytes(tx.GetResultRawTx())
+	sign, _ := crypto.MyCryptoLib.Sign(crypto.FromECDSA(key), data)
+	return SignatureSet{PubKey: crypto.CompressPubkey(&key.PublicKey), Signature: sign}
+}
+
+func TestJurorEvidence_Verify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	accepted := newJurorResultTx("a")
+	divergent := newJurorResultTx("b")
+
+	evidence, err := NewJurorEvidence(accepted.Hash(), divergent, signJurorResultTx(key, divergent))
+	assert.Nil(t, err)
+	addr, err := evidence.Verify()
+	assert.Nil(t, err)
+	assert.Equal(t, crypto.PubkeyBytesToAddress(crypto.CompressPubkey(&key.PublicKey)), addr)
+	assert.Nil(t, evidence.CheckDivergent(accepted))
+
+	data, err := rlp.EncodeToBytes(evidence)
+	assert.Nil(t, err)
+	evidence2 := &JurorEvidencePayload{}
+	assert.Nil(t, rlp.DecodeBytes(data, evidence2))
+	assert.Equal(t, evidence.Hash(), evidence2.Hash())
+
+	//与被接受的结果相同
+	same, _ := NewJurorEvidence(accepted.Hash(), accepted, signJurorResultTx(key, accepted))
+	_, err = same.Verify()
+	assert.Nil(t, err)
+	assert.NotNil(t, same.CheckDivergent(accepted))
+
+	//签名不是对该结果的
+	forged, _ := NewJurorEvidence(accepted.Hash(), divergent, signJurorResultTx(key, accepted))
+	_, err = forged.Verify()
+	assert.NotNil(t, err)
+
+	//不是同一个请求
+	other := newJurorResultTx("a")
+	other.TxMessages[0].Payload.(*ContractInvokeRequestPayload).Args = [][]byte{[]byte("get")}
+	assert.NotNil(t, evidence.CheckDivergent(other))
+
+	//证据消息的编解码
+	tx := &Transaction{}
+	tx.AddMessage(NewMessage(APP_JUROR_EVIDENCE, evidence))
+	data, err = rlp.EncodeToBytes(tx)
+	assert.Nil(t, err)
+	tx2 := &Transaction{}
+	assert.Nil(t, rlp.DecodeBytes(data, tx2))
+	assert.Equal(t, tx.Hash(), tx2.Hash())
+	js, err := json.Marshal(tx)
+	assert.Nil(t, err)
+	tx3 := &Transaction{}
+	assert.Nil(t, json.Unmarshal(js, tx3))
+	assert.Equal(t, tx.Hash(), tx3.Hash())
+}
+
+func TestJuryThreshold(t *testing.T) {
+	assert.Equal(t, 1, JuryThreshold(1))
+	assert.Equal(t, 3, JuryThreshold(3))
+	assert.Equal(t, 3, JuryThreshold(4))
+	assert.Equal(t, 4, JuryThreshold(5))
+}
@@ -21,6 +21,8 @@
 package modules
 
 import (
+	"fmt"
+
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/core"
 )
@@ -54,7 +56,7 @@ func MediatorToInfo(md *core.Mediator) *MediatorInfo {
 	mi := NewMediatorInfo()
 	mi.AddStr = md.Address.Str()
 	mi.RewardAdd = md.RewardAdd.Str()
-	mi.InitPubKey = core.PointToStr(md.InitPubKey)
+	mi.InitPubKey = core.InitKeyToStr(md.InitPubKey)
 	mi.Node = md.Node.String()
 	*mi.MediatorApplyInfo = *md.MediatorApplyInfo
 	*mi.MediatorInfoExpand = *md.MediatorInfoExpand
@@ -66,7 +68,7 @@ func (mi *MediatorInfo) InfoToMediator() *core.Mediator {
 	md := core.NewMediator()
 	md.Address, _ = core.StrToMedAdd(mi.AddStr)
 	md.RewardAdd, _ = core.StrToMedAdd(mi.RewardAdd)
-	md.InitPubKey, _ = core.StrToPoint(mi.InitPubKey)
+	md.InitPubKey, _ = core.StrToInitKey(mi.InitPubKey)
 	md.Node, _ = core.StrToMedNode(mi.Node)
 	*md.MediatorApplyInfo = *mi.MediatorApplyInfo
 	*md.MediatorInfoExpand = *mi.MediatorInfoExpand
@@ -87,6 +89,19 @@ func NewMediatorCreateArgs() *MediatorCreateArgs {
 	}
 }
 
+func (mca *MediatorCreateArgs) Validate() (common.Address, error) {
+	addr, err := mca.MediatorInfoBase.Validate()
+	if err != nil {
+		return addr, err
+	}
+
+	if mca.MediatorApplyInfo != nil {
+		err = mca.MediatorApplyInfo.ValidateCommissionRate()
+	}
+
+	return addr, err
+}
+
 // 更新 mediator 信息所需参数
 type MediatorUpdateArgs struct {
 	AddStr      string  `json:"account"`              // 要更新的mediator地址
@@ -98,6 +113,8 @@ type MediatorUpdateArgs struct {
 	Location    *string `json:"loc" rlp:"nil"`        // 节点所在地区
 	Url         *string `json:"url" rlp:"nil"`        // 节点宣传网站
 	Description *string `json:"applyInfo" rlp:"nil"`  // 节点详细信息描述
+	// 出块奖励的佣金比例，单位为万分之一
+	CommissionRate *uint16 `json:"commissionRate" rlp:"nil"`
 }
 
 func (mua *MediatorUpdateArgs) Validate() (common.Address, error) {
@@ -114,12 +131,17 @@ func (mua *MediatorUpdateArgs) Validate() (common.Address, error) {
 	}
 
 	if mua.InitPubKey != nil {
-		_, err = core.StrToPoint(*mua.InitPubKey)
+		_, err = core.StrToInitKey(*mua.InitPubKey)
 		if err != nil {
 			return addr, err
 		}
 	}
 
+	if mua.CommissionRate != nil && *mua.CommissionRate > core.MaxCommissionRate {
+		return addr, fmt.Errorf("invalid commission rate %v, must not exceed %v",
+			*mua.CommissionRate, core.MaxCommissionRate)
+	}
+
 	if mua.Node != nil {
 		node, err := core.StrToMedNode(*mua.Node)
 		if err != nil {
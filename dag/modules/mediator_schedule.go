@@ -20,6 +20,7 @@
 package modules
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
@@ -55,3 +56,85 @@ func NewMediatorSchl() *MediatorSchedule {
 		CurrentShuffledMediators: []common.Address{},
 	}
 }
+
+// ShuffleMediators 用种子打乱mediator的调度顺序，全节点和轻客户端使用相同的洗牌算法
+func ShuffleMediators(mediators []common.Address, seed uint64) {
+	nowHi := seed << 32
+	aSize := len(mediators)
+	for i := 0; i < aSize; i++ {
+		// 高性能随机生成器(High performance random generator)
+		// 原理请参考 http://xorshift.di.unimi.it/
+		k := nowHi + uint64(i)*2685821657736338717
+		k ^= k >> 12
+		k ^= k << 25
+		k ^= k >> 27
+		k *= 2685821657736338717
+
+		jmax := uint64(aSize - i)
+		j := uint64(i) + k%jmax
+
+		// 进行N次随机交换
+		mediators[i], mediators[j] = mediators[j], mediators[i]
+	}
+}
+
+// ShuffleSeed 以洗牌时最新单元的hash作为洗牌的种子
+func ShuffleSeed(unitHash common.Hash) uint64 {
+	return binary.BigEndian.Uint64(unitHash[8:])
+}
+
+// SlotTime 返回最新单元之后第slotNum个slot的开始时间，slotNum为0时返回0
+func SlotTime(headTime int64, headIndex uint64, interval, maintenanceSkipSlots uint8, maintenanceFlag bool,
+	slotNum uint32) int64 {
+	if slotNum == 0 {
+		return 0
+	}
+	// 第一个unit的生产时间是在创世时间加上一个unit生产间隔
+	if headIndex == 0 {
+		return headTime + int64(slotNum)*int64(interval)
+	}
+	// 维护之后需要跳过一些slot
+	if maintenanceFlag {
+		slotNum += uint32(maintenanceSkipSlots)
+	}
+	headSlotTime := headTime / int64(interval) * int64(interval)
+	return headSlotTime + int64(slotNum)*int64(interval)
+}
+
+// SlotAtTime 返回满足 SlotTime(N) <= when 的最大的N，都不满足时返回0
+func SlotAtTime(headTime int64, headIndex uint64, interval, maintenanceSkipSlots uint8, maintenanceFlag bool,
+	when int64) uint32 {
+	if interval == 0 {
+		return 0
+	}
+	firstSlotTime := SlotTime(headTime, headIndex, interval, maintenanceSkipSlots, maintenanceFlag, 1)
+	if when < firstSlotTime {
+		return 0
+	}
+	return uint32((when-firstSlotTime)/int64(interval)) + 1
+}
+
+// NextMaintenanceTime 返回维护单元之后的下一次维护时间
+func NextMaintenanceTime(unitIndex uint64, unitTime int64, nextMaintenanceTime uint32,
+	maintenanceInterval int64) uint32 {
+	if unitIndex == 1 {
+		// 对第一个unit之后的特殊换届，进行调整，让其回到普通换届时间来
+		return uint32((unitTime/maintenanceInterval + 1) * maintenanceInterval)
+	}
+	// We want to find the smallest k such that nextMaintenanceTime + k * maintenanceInterval > unitTime
+	//  This implies k > ( unitTime - nextMaintenanceTime ) / maintenanceInterval
+	//
+	// Let y be the right-hand side of this inequality, i.e.
+	// y = ( unitTime - nextMaintenanceTime ) / maintenanceInterval
+	//
+	// and let the fractional part f be y-floor(y).  Clearly 0 <= f < 1.
+	// We can rewrite f = y-floor(y) as floor(y) = y-f.
+	//
+	// Clearly k = floor(y)+1 has k > y as desired.  Now we must
+	// show that this is the least such k, i.e. k-1 <= y.
+	//
+	// But k-1 = floor(y)+1-1 = floor(y) = y-f <= y.
+	// So this k suffices.
+	y := (unitTime - int64(nextMaintenanceTime)) / maintenanceInterval
+	return nextMaintenanceTime + uint32((y+1)*maintenanceInterval)
+}
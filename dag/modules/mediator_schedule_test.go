@@ -0,0 +1,49 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotTime(t *testing.T) {
+	//创世单元之后的第一个slot在创世时间加上一个间隔
+	assert.Equal(t, int64(1003), SlotTime(1000, 0, 3, 2, false, 1))
+	assert.Equal(t, int64(0), SlotTime(1000, 0, 3, 2, false, 0))
+	//其他单元之后的slot对齐到间隔，维护之后跳过一些slot
+	assert.Equal(t, int64(1008), SlotTime(1004, 5, 3, 2, false, 2))
+	assert.Equal(t, int64(1014), SlotTime(1004, 5, 3, 2, true, 2))
+
+	assert.Equal(t, uint32(0), SlotAtTime(1004, 5, 3, 2, false, 1004))
+	assert.Equal(t, uint32(1), SlotAtTime(1004, 5, 3, 2, false, 1007))
+	assert.Equal(t, uint32(2), SlotAtTime(1004, 5, 3, 2, false, 1008))
+	assert.Equal(t, uint32(0), SlotAtTime(1004, 5, 3, 2, true, 1010))
+	assert.Equal(t, uint32(1), SlotAtTime(1004, 5, 3, 2, true, 1013))
+}
+
+func TestNextMaintenanceTime(t *testing.T) {
+	assert.Equal(t, uint32(1200), NextMaintenanceTime(1, 1003, 0, 600))
+	assert.Equal(t, uint32(1800), NextMaintenanceTime(10, 1200, 1200, 600))
+	//错过了多个维护周期
+	assert.Equal(t, uint32(3000), NextMaintenanceTime(10, 2500, 1200, 600))
+}
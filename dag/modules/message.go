@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/util"
 )
 
 type MessageType byte
@@ -42,6 +43,8 @@ const (
 
 	APP_DATA
 	APP_ACCOUNT_UPDATE
+	APP_MEDIATOR_EVIDENCE
+	APP_JUROR_EVIDENCE
 
 	APP_UNKNOW = 99
 
@@ -117,6 +120,7 @@ func (msg *Message) CopyMessages(cpyMsg *Message) *Message {
 			ContractId: payload.ContractId,
 			Args:       payload.Args,
 			Timeout:    payload.Timeout,
+			CostLimit:  payload.CostLimit,
 		}
 		msg.Payload = newPayload
 	case APP_CONTRACT_INVOKE:
@@ -135,6 +139,8 @@ func (msg *Message) CopyMessages(cpyMsg *Message) *Message {
 		}
 		newPayload.ReadSet = readSet
 		newPayload.WriteSet = writeSet
+		newPayload.RangeReadSet = append(newPayload.RangeReadSet, payload.RangeReadSet...)
+		newPayload.Cost = payload.Cost
 		msg.Payload = newPayload
 	case APP_SIGNATURE:
 		payload, _ := cpyMsg.Payload.(*SignaturePayload)
@@ -220,6 +226,23 @@ type ContractStateValue struct {
 	Version *StateVersion `json:"version"`
 }
 
+// 带key的合约状态，用于按key顺序返回的范围查询
+type ContractStateKV struct {
+	Key     string        `json:"key"`
+	Value   []byte        `json:"value"`
+	Version *StateVersion `json:"version"`
+}
+
+// 合约状态的一次修改，IsDelete为true表示该状态被删除
+type ContractStateHistory struct {
+	TxId      common.Hash   `json:"tx_id"`
+	UnitHash  common.Hash   `json:"unit_hash"`
+	Timestamp uint64        `json:"timestamp"`
+	Version   *StateVersion `json:"version"`
+	Value     []byte        `json:"value"`
+	IsDelete  bool          `json:"is_delete"`
+}
+
 func (version *StateVersion) String() string {
 	if version == nil {
 		return `null`
@@ -309,6 +332,24 @@ type ContractReadSet struct {
 	ContractId []byte        `json:"contract_id"`
 }
 
+// 范围查询读取的key区间[StartKey,EndKey)，EndKey为空表示没有上界，
+// ResultHash是读到的key和版本的hash，打包时重新查询该区间以检查是否有新增或删除的key
+type ContractRangeReadSet struct {
+	ContractId []byte      `json:"contract_id"`
+	StartKey   string      `json:"start_key"`
+	EndKey     string      `json:"end_key"`
+	ResultHash common.Hash `json:"result_hash"`
+}
+
+// 计算范围查询结果的hash，只包含key和版本
+func HashContractStates(states []*ContractStateKV) common.Hash {
+	reads := make([]ContractReadSet, 0, len(states))
+	for _, state := range states {
+		reads = append(reads, ContractReadSet{Key: state.Key, Version: state.Version})
+	}
+	return util.RlpHash(reads)
+}
+
 //请求合约信息
 type InvokeInfo struct {
 	InvokeAddress common.Address  `json:"invoke_address"` //请求地址
@@ -333,6 +374,8 @@ type TokenPayOut struct {
 	Amount   uint64
 	PayTo    common.Address
 	LockTime uint32
+	//付款的合约地址，合约调用合约时可能是被调用的合约，为空时是调用结果中的合约
+	PayFrom common.Address
 }
 
 //用户钱包发起的合约调用申请
@@ -382,6 +425,8 @@ type ContractInvokeRequestPayload struct {
 	ContractId []byte   `json:"contract_id"` // contract id
 	Args       [][]byte `json:"args"`        // contract arguments list
 	Timeout    uint32   `json:"timeout"`
+	//执行的cost上限，为0时使用默认值，不影响旧请求的编码
+	CostLimit uint64 `json:"cost_limit,omitempty"`
 }
 
 //如果是用户想修改自己的State信息，那么ContractId可以为空或�?0字节
@@ -392,6 +437,10 @@ type ContractInvokePayload struct {
 	WriteSet   []ContractWriteSet `json:"write_set"`      // the set data of write, and value could be any type
 	Payload    []byte             `json:"payload"`        // the contract execution result
 	ErrMsg     ContractError      `json:"contract_error"` // contract error message
+	//范围查询的读集，没有范围查询时为空，不影响旧交易的编码
+	RangeReadSet []ContractRangeReadSet `json:"range_read_set,omitempty"`
+	//用户合约执行的cost，按cost收取手续费，系统合约为0
+	Cost uint64 `json:"cost,omitempty"`
 }
 
 // App: contract_stop
@@ -410,16 +459,18 @@ type ContractStopPayload struct {
 
 //contract invoke result
 type ContractInvokeResult struct {
-	ContractId  []byte             `json:"contract_id"` // contract id
-	RequestId   common.Hash        `json:"request_id"`
-	Args        [][]byte           `json:"args"`           // contract arguments list
-	ReadSet     []ContractReadSet  `json:"read_set"`       // the set data of read, and value could be any type
-	WriteSet    []ContractWriteSet `json:"write_set"`      // the set data of write, and value could be any type
-	Payload     []byte             `json:"payload"`        // the contract execution result
-	TokenPayOut []*TokenPayOut     `json:"token_payout"`   //从合约地址付出Token
-	TokenSupply []*TokenSupply     `json:"token_supply"`   //增发Token请求产生的结果
-	TokenDefine *TokenDefine       `json:"token_define"`   //定义新Token
-	ErrMsg      ContractError      `json:"contract_error"` // contract error message
+	ContractId   []byte                 `json:"contract_id"` // contract id
+	RequestId    common.Hash            `json:"request_id"`
+	Args         [][]byte               `json:"args"`           // contract arguments list
+	ReadSet      []ContractReadSet      `json:"read_set"`       // the set data of read, and value could be any type
+	WriteSet     []ContractWriteSet     `json:"write_set"`      // the set data of write, and value could be any type
+	RangeReadSet []ContractRangeReadSet `json:"range_read_set"` //范围查询的读集
+	Payload      []byte                 `json:"payload"`        // the contract execution result
+	TokenPayOut  []*TokenPayOut         `json:"token_payout"`   //从合约地址付出Token
+	TokenSupply  []*TokenSupply         `json:"token_supply"`   //增发Token请求产生的结果
+	TokenDefine  *TokenDefine           `json:"token_define"`   //定义新Token
+	ErrMsg       ContractError          `json:"contract_error"` // contract error message
+	Cost         uint64                 `json:"cost"`           //执行的cost
 }
 
 type SignaturePayload struct {
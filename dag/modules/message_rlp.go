@@ -21,8 +21,10 @@
 package modules
 
 import (
-	"github.com/ethereum/go-ethereum/rlp"
+	"errors"
 	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type ContractDeployPayloadV1 struct {
@@ -51,3 +53,128 @@ func (input *ContractDeployPayload) EncodeRLP(w io.Writer) error {
 	}
 	return rlp.Encode(w, input)
 }
+
+//旧版本的请求没有CostLimit，CostLimit为0时编码不变
+type contractInvokeRequestPayloadTemp struct {
+	ContractId []byte
+	Args       [][]byte
+	Timeout    uint32
+	Extension  []rlp.RawValue `rlp:"tail"`
+}
+
+func (input *ContractInvokeRequestPayload) EncodeRLP(w io.Writer) error {
+	temp := &contractInvokeRequestPayloadTemp{
+		ContractId: input.ContractId,
+		Args:       input.Args,
+		Timeout:    input.Timeout,
+	}
+	if input.CostLimit > 0 {
+		limit, err := rlp.EncodeToBytes(input.CostLimit)
+		if err != nil {
+			return err
+		}
+		temp.Extension = append(temp.Extension, limit)
+	}
+	return rlp.Encode(w, temp)
+}
+
+func (input *ContractInvokeRequestPayload) DecodeRLP(s *rlp.Stream) error {
+	temp := &contractInvokeRequestPayloadTemp{}
+	if err := s.Decode(temp); err != nil {
+		return err
+	}
+	input.ContractId = temp.ContractId
+	input.Args = temp.Args
+	input.Timeout = temp.Timeout
+	input.CostLimit = 0
+	if len(temp.Extension) > 1 {
+		return errors.New("invalid contract invoke request extension")
+	}
+	if len(temp.Extension) == 1 {
+		if err := rlp.DecodeBytes(temp.Extension[0], &input.CostLimit); err != nil {
+			return err
+		}
+		//为0时不编码，保证编码唯一
+		if input.CostLimit == 0 {
+			return errors.New("invalid contract invoke request cost limit")
+		}
+	}
+	return nil
+}
+
+//Extension中依次是范围查询的读集(list)和Cost(string)，都为空时与旧版本的编码相同
+type contractInvokePayloadTemp struct {
+	ContractId []byte
+	Args       [][]byte
+	ReadSet    []ContractReadSet
+	WriteSet   []ContractWriteSet
+	Payload    []byte
+	ErrMsg     ContractError
+	Extension  []rlp.RawValue `rlp:"tail"`
+}
+
+func (input *ContractInvokePayload) EncodeRLP(w io.Writer) error {
+	temp := &contractInvokePayloadTemp{
+		ContractId: input.ContractId,
+		Args:       input.Args,
+		ReadSet:    input.ReadSet,
+		WriteSet:   input.WriteSet,
+		Payload:    input.Payload,
+		ErrMsg:     input.ErrMsg,
+	}
+	for _, rangeRead := range input.RangeReadSet {
+		data, err := rlp.EncodeToBytes(rangeRead)
+		if err != nil {
+			return err
+		}
+		temp.Extension = append(temp.Extension, data)
+	}
+	if input.Cost > 0 {
+		cost, err := rlp.EncodeToBytes(input.Cost)
+		if err != nil {
+			return err
+		}
+		temp.Extension = append(temp.Extension, cost)
+	}
+	return rlp.Encode(w, temp)
+}
+
+func (input *ContractInvokePayload) DecodeRLP(s *rlp.Stream) error {
+	temp := &contractInvokePayloadTemp{}
+	if err := s.Decode(temp); err != nil {
+		return err
+	}
+	input.ContractId = temp.ContractId
+	input.Args = temp.Args
+	input.ReadSet = temp.ReadSet
+	input.WriteSet = temp.WriteSet
+	input.Payload = temp.Payload
+	input.ErrMsg = temp.ErrMsg
+	input.RangeReadSet = nil
+	input.Cost = 0
+	for i, ext := range temp.Extension {
+		kind, _, _, err := rlp.Split(ext)
+		if err != nil {
+			return err
+		}
+		if kind == rlp.List {
+			rangeRead := ContractRangeReadSet{}
+			if err := rlp.DecodeBytes(ext, &rangeRead); err != nil {
+				return err
+			}
+			input.RangeReadSet = append(input.RangeReadSet, rangeRead)
+			continue
+		}
+		//Cost只能在最后
+		if i != len(temp.Extension)-1 {
+			return errors.New("invalid contract invoke payload extension")
+		}
+		if err := rlp.DecodeBytes(ext, &input.Cost); err != nil {
+			return err
+		}
+		if input.Cost == 0 {
+			return errors.New("invalid contract invoke payload cost")
+		}
+	}
+	return nil
+}
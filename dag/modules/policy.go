@@ -0,0 +1,164 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+)
+
+//  账户策略合约的方法名
+const (
+	SetPolicy   = "setPolicy"
+	PolicySpend = "spend"
+	GetPolicy   = "getPolicy"
+)
+
+//  账户策略合约中记录策略和每日已支出数量的key前缀
+const (
+	PolicyPrefix      = "Policy_"
+	PolicySpentPrefix = "PolicySpent_"
+)
+
+//  没有第二因子签名时，修改策略需要等待的时间，单位秒
+const PolicyChangeDelay = 24 * 3600
+
+//  用户为策略账户设置的支出规则
+type SpendingPolicy struct {
+	DailyLimit        map[string]uint64 `json:"daily_limit"`         // 每种资产每天最多支出的数量，未设置则不限
+	SecondFactorLimit map[string]uint64 `json:"second_factor_limit"` // 单笔支出超过该数量时需要第二因子签名
+	Whitelist         []string          `json:"whitelist"`           // 允许的收款地址，为空则不限
+	SecondFactorKey   string            `json:"second_factor_key"`   // 第二因子公钥，hex编码
+}
+
+func (p *SpendingPolicy) Validate() error {
+	for asset := range p.DailyLimit {
+		if _, err := StringToAsset(asset); err != nil {
+			return fmt.Errorf("invalid asset %s in daily limit", asset)
+		}
+	}
+	for asset := range p.SecondFactorLimit {
+		if _, err := StringToAsset(asset); err != nil {
+			return fmt.Errorf("invalid asset %s in second factor limit", asset)
+		}
+	}
+	for _, addr := range p.Whitelist {
+		if _, err := common.StringToAddress(addr); err != nil {
+			return fmt.Errorf("invalid whitelist address %s", addr)
+		}
+	}
+	if p.SecondFactorKey == "" {
+		if len(p.SecondFactorLimit) > 0 {
+			return errors.New("second factor limit requires a second factor key")
+		}
+		return nil
+	}
+	if _, err := p.SecondFactorPubKey(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *SpendingPolicy) SecondFactorPubKey() ([]byte, error) {
+	pubKey, err := hex.DecodeString(p.SecondFactorKey)
+	if err != nil || (len(pubKey) != 33 && len(pubKey) != 65) {
+		return nil, errors.New("invalid second factor key")
+	}
+	return pubKey, nil
+}
+
+func (p *SpendingPolicy) InWhitelist(addr string) bool {
+	if len(p.Whitelist) == 0 {
+		return true
+	}
+	for _, a := range p.Whitelist {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+//  验证第二因子对hash的签名，未设置第二因子公钥时总是返回false
+func (p *SpendingPolicy) VerifySecondFactor(hash, signature []byte) bool {
+	if p.SecondFactorKey == "" || len(signature) == 0 {
+		return false
+	}
+	pubKey, err := p.SecondFactorPubKey()
+	if err != nil {
+		return false
+	}
+	return crypto.VerifySignature(pubKey, hash, signature)
+}
+
+//  策略账户在合约中保存的状态
+type PolicyAccount struct {
+	Account     string          `json:"account"`      // 策略账户地址(P2SH)
+	Owner       string          `json:"owner"`        // 所有者公钥，hex编码
+	Policy      *SpendingPolicy `json:"policy"`       // 当前生效的策略
+	Pending     *SpendingPolicy `json:"pending"`      // 等待生效的新策略
+	PendingTime int64           `json:"pending_time"` // 新策略的生效时间，unix秒
+	Nonce       uint64          `json:"nonce"`        // 每次使用第二因子签名后加1，防止重放
+}
+
+//  到达生效时间后用等待中的策略替换当前策略，返回是否发生了替换
+func (a *PolicyAccount) ApplyPending(now int64) bool {
+	if a.Pending == nil || now < a.PendingTime {
+		return false
+	}
+	a.Policy = a.Pending
+	a.Pending = nil
+	a.PendingTime = 0
+	return true
+}
+
+//  修改策略时第二因子需要签名的hash，data为修改后的策略json
+func PolicyAuthHash(account string, nonce uint64, data []byte) []byte {
+	return crypto.Keccak256([]byte(account), []byte(strconv.FormatUint(nonce, 10)), data)
+}
+
+//  支出时第二因子需要签名的hash，绑定本次支出的每个收款地址、资产和数量，找零回策略账户的不计入，
+//  因此签名不能被用于其他支出
+func PolicySpendHash(account string, nonce uint64, payments []*InvokeTokens) []byte {
+	amounts := make(map[string]uint64)
+	for _, p := range payments {
+		if p.Address == account || p.Amount == 0 {
+			continue
+		}
+		amounts[p.Address+":"+p.Asset.String()] += p.Amount
+	}
+	keys := make([]string, 0, len(amounts))
+	for k := range amounts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	data := []byte(PolicySpend)
+	for _, k := range keys {
+		data = append(data, []byte(";"+k+":"+strconv.FormatUint(amounts[k], 10))...)
+	}
+	return PolicyAuthHash(account, nonce, data)
+}
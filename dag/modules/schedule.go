@@ -0,0 +1,99 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"errors"
+)
+
+//  定时支付合约的方法名
+const (
+	CreateSchedule      = "createSchedule"
+	CancelSchedule      = "cancelSchedule"
+	ExecuteDueSchedules = "executeDueSchedules"
+	GetSchedule         = "getSchedule"
+	ListSchedules       = "listSchedules"
+)
+
+//  定时支付合约中记录支付计划的key前缀
+const SchedulePrefix = "Schedule_"
+
+//  定时支付合约中记录最近一次到期时间的key，mediator据此判断是否需要触发支付
+const ScheduleNextDueKey = "ScheduleNextDue"
+
+//  定时支付合约中记录进行中计划最小开始高度的key，链高度未达到时mediator无需检查到期的计划
+const ScheduleNextDueHeightKey = "ScheduleNextDueHeight"
+
+// 定时或周期性支付计划，创建时由付款人将全部金额托管到合约
+type Schedule struct {
+	Id        string `json:"id"`
+	Owner     string `json:"owner"`
+	Recipient string `json:"recipient"`
+	Asset     string `json:"asset"`
+	Amount    uint64 `json:"amount"`     // 每期支付的数量
+	StartTime int64  `json:"start_time"` // 第一期支付时间，unix秒
+	Interval  int64  `json:"interval"`   // 两期之间的间隔，单位秒，只支付一次时为0
+	Count     uint32 `json:"count"`      // 总期数
+	Paid      uint32 `json:"paid"`       // 已支付期数
+	NextTime  int64  `json:"next_time"`  // 下一期支付时间
+	Escrow    uint64 `json:"escrow"`     // 合约中剩余的托管数量
+	Canceled  bool   `json:"canceled"`
+	// 每次执行支付时由付款人承担的手续费，创建时按TransferPtnBaseFee确定，以gas token支付给触发执行的mediator
+	Fee         uint64 `json:"fee"`
+	FeeEscrow   uint64 `json:"fee_escrow"`   // 剩余的手续费托管数量
+	StartHeight uint64 `json:"start_height"` // 链高度达到此值后才开始支付，0表示不限制
+}
+
+func (s *Schedule) Validate() error {
+	if s.Count == 0 {
+		return errors.New("count must be positive")
+	}
+	if s.Amount == 0 {
+		return errors.New("amount of each payment must be positive")
+	}
+	if s.Count > 1 && s.Interval <= 0 {
+		return errors.New("interval must be positive for recurring payment")
+	}
+	if s.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	return nil
+}
+
+func (s *Schedule) IsActive() bool {
+	return !s.Canceled && s.Paid < s.Count
+}
+
+// 截至now和height到期但未支付的期数
+func (s *Schedule) DueCount(now int64, height uint64) uint32 {
+	if !s.IsActive() || now < s.NextTime || height < s.StartHeight {
+		return 0
+	}
+	remain := s.Count - s.Paid
+	if s.Interval == 0 {
+		return remain
+	}
+	due := uint64((now-s.NextTime)/s.Interval) + 1
+	if due > uint64(remain) {
+		return remain
+	}
+	return uint32(due)
+}
@@ -0,0 +1,54 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule_DueCount(t *testing.T) {
+	s := &Schedule{Amount: 10, StartTime: 100, NextTime: 100, Interval: 50, Count: 3}
+	assert.Nil(t, s.Validate())
+	assert.Equal(t, uint32(0), s.DueCount(99, 0))
+	assert.Equal(t, uint32(1), s.DueCount(100, 0))
+	assert.Equal(t, uint32(1), s.DueCount(149, 0))
+	assert.Equal(t, uint32(2), s.DueCount(150, 0))
+	assert.Equal(t, uint32(3), s.DueCount(10000, 0))
+
+	s.StartHeight = 20
+	assert.Equal(t, uint32(0), s.DueCount(10000, 19))
+	assert.Equal(t, uint32(3), s.DueCount(10000, 20))
+
+	s.Paid = 2
+	s.NextTime = 200
+	assert.Equal(t, uint32(1), s.DueCount(10000, 20))
+	s.Canceled = true
+	assert.Equal(t, uint32(0), s.DueCount(10000, 20))
+
+	once := &Schedule{Amount: 10, NextTime: 100, Count: 1}
+	assert.Nil(t, once.Validate())
+	assert.Equal(t, uint32(1), once.DueCount(100, 0))
+
+	assert.NotNil(t, (&Schedule{Amount: 10, Count: 2}).Validate())
+	assert.NotNil(t, (&Schedule{Amount: 0, Count: 1}).Validate())
+}
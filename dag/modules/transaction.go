@@ -479,6 +479,35 @@ func (tx *Transaction) GetContractTxSignatureAddress() []common.Address {
 	return addrs
 }
 
+//获得对合约执行结果签名的陪审员，有Contract Payout时从解锁脚本中解析
+func (tx *Transaction) GetContractTxSigners(getSignerFunc GetScriptSignersFunc) ([]common.Address, error) {
+	isResultMsg := false
+	jury := []common.Address{}
+	for msgIdx, msg := range tx.TxMessages {
+		if msg.App.IsRequest() {
+			isResultMsg = true
+			continue
+		}
+		if isResultMsg && msg.App == APP_SIGNATURE {
+			payload := msg.Payload.(*SignaturePayload)
+			for _, sig := range payload.Signatures {
+				jury = append(jury, crypto.PubkeyBytesToAddress(sig.PubKey))
+			}
+		}
+		if isResultMsg && msg.App == APP_PAYMENT {
+			payment := msg.Payload.(*PaymentPayload)
+			if !payment.IsCoinbase() {
+				var err error
+				jury, err = getSignerFunc(tx, msgIdx, 0)
+				if err != nil {
+					return nil, errors.New("Parse unlock script to get signers error:" + err.Error())
+				}
+			}
+		}
+	}
+	return jury, nil
+}
+
 //如果是合约调用交易，Copy其中的Msg0到ContractRequest的部分，如果不是请求，那么返回完整Tx
 func (tx *Transaction) GetRequestTx() *Transaction {
 	request := &Transaction{}
@@ -543,6 +572,14 @@ func (tx *Transaction) GetRequestTx() *Transaction {
 				payload := new(AccountStateUpdatePayload)
 				obj.DeepCopy(payload, msg.Payload)
 				request.AddMessage(NewMessage(msg.App, payload))
+			} else if msg.App == APP_MEDIATOR_EVIDENCE {
+				payload := new(MediatorEvidencePayload)
+				obj.DeepCopy(payload, msg.Payload)
+				request.AddMessage(NewMessage(msg.App, payload))
+			} else if msg.App == APP_JUROR_EVIDENCE {
+				payload := new(JurorEvidencePayload)
+				obj.DeepCopy(payload, msg.Payload)
+				request.AddMessage(NewMessage(msg.App, payload))
 			} else {
 				log.Error("Invalid tx message")
 				return nil
@@ -604,6 +641,16 @@ func (tx *Transaction) GetRequestMsgIndex() int {
 
 //这个交易是否包含了从合约付款出去的结果,有则返回该Payment
 func (tx *Transaction) HasContractPayoutMsg() (bool, *PaymentPayload) {
+	payouts := tx.ContractPayoutMsgs()
+	if len(payouts) == 0 {
+		return false, nil
+	}
+	return true, payouts[0]
+}
+
+//ContractPayoutMsgs 合约执行结果中从合约地址付款的Payment，合约调用合约时可能有多个
+func (tx *Transaction) ContractPayoutMsgs() []*PaymentPayload {
+	payouts := []*PaymentPayload{}
 	isInvokeResult := false
 	for _, msg := range tx.TxMessages {
 		if msg.App.IsRequest() {
@@ -613,11 +660,11 @@ func (tx *Transaction) HasContractPayoutMsg() (bool, *PaymentPayload) {
 		if isInvokeResult && msg.App == APP_PAYMENT {
 			pay := msg.Payload.(*PaymentPayload)
 			if !pay.IsCoinbase() {
-				return true, pay
+				payouts = append(payouts, pay)
 			}
 		}
 	}
-	return false, nil
+	return payouts
 }
 
 func (tx *Transaction) InvokeContractId() []byte {
@@ -812,28 +859,10 @@ func (tx *Transaction) GetTxFeeAllocate(queryUtxoFunc QueryUtxoFunc, getSignerFu
 	if fee.Amount == 0 {
 		return result, nil
 	}
-	isResultMsg := false
-	jury := []common.Address{}
-	for msgIdx, msg := range tx.TxMessages {
-		if msg.App.IsRequest() {
-			isResultMsg = true
-			continue
-		}
-		if isResultMsg && msg.App == APP_SIGNATURE {
-			payload := msg.Payload.(*SignaturePayload)
-			for _, sig := range payload.Signatures {
-				jury = append(jury, crypto.PubkeyBytesToAddress(sig.PubKey))
-			}
-		}
-		if isResultMsg && msg.App == APP_PAYMENT {
-			payment := msg.Payload.(*PaymentPayload)
-			if !payment.IsCoinbase() {
-				jury, err = getSignerFunc(tx, msgIdx, 0)
-				if err != nil {
-					return nil, errors.New("Parse unlock script to get signers error:" + err.Error())
-				}
-			}
-		}
+	isResultMsg := tx.GetRequestMsgIndex() != -1
+	jury, err := tx.GetContractTxSigners(getSignerFunc)
+	if err != nil {
+		return nil, err
 	}
 	if isResultMsg { //合约执行，Fee需要分配给Jury
 		juryAmount := float64(fee.Amount) * parameter.CurrentSysParameters.ContractFeeJuryPercent
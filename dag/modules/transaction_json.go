@@ -59,6 +59,16 @@ type idxAccountUpdateOperation struct {
 	*AccountStateUpdatePayload
 }
 
+type idxMediatorEvidence struct {
+	Index int
+	*MediatorEvidencePayload
+}
+
+type idxJurorEvidence struct {
+	Index int
+	*JurorEvidencePayload
+}
+
 //install
 type idxContractInstallRequestPayload struct {
 	Index int
@@ -112,6 +122,8 @@ type txJsonTemp struct {
 	Text []*idxTextPayload
 	//MediatorCreateOperation []*idxMediatorCreateOperation
 	AccountUpdateOperation []*idxAccountUpdateOperation
+	MediatorEvidence       []*idxMediatorEvidence
+	JurorEvidence          []*idxJurorEvidence
 	Signature              []*idxSignaturePayload
 
 	ContractInstallRequest []*idxContractInstallRequestPayload
@@ -177,6 +189,12 @@ func tx2JsonTemp(tx *Transaction) (*txJsonTemp, error) {
 		} else if msg.App == APP_ACCOUNT_UPDATE {
 			temp.AccountUpdateOperation = append(temp.AccountUpdateOperation,
 				&idxAccountUpdateOperation{Index: idx, AccountStateUpdatePayload: msg.Payload.(*AccountStateUpdatePayload)})
+		} else if msg.App == APP_MEDIATOR_EVIDENCE {
+			temp.MediatorEvidence = append(temp.MediatorEvidence,
+				&idxMediatorEvidence{Index: idx, MediatorEvidencePayload: msg.Payload.(*MediatorEvidencePayload)})
+		} else if msg.App == APP_JUROR_EVIDENCE {
+			temp.JurorEvidence = append(temp.JurorEvidence,
+				&idxJurorEvidence{Index: idx, JurorEvidencePayload: msg.Payload.(*JurorEvidencePayload)})
 		} else {
 			return nil, errors.New("Unsupport APP" + strconv.Itoa(int(msg.App)) + " please edit transaction_json.go")
 		}
@@ -247,6 +265,14 @@ func jsonTemp2tx(tx *Transaction, temp *txJsonTemp) error {
 		tx.TxMessages[p.Index] = NewMessage(APP_ACCOUNT_UPDATE, p.AccountStateUpdatePayload)
 		processed++
 	}
+	for _, p := range temp.MediatorEvidence {
+		tx.TxMessages[p.Index] = NewMessage(APP_MEDIATOR_EVIDENCE, p.MediatorEvidencePayload)
+		processed++
+	}
+	for _, p := range temp.JurorEvidence {
+		tx.TxMessages[p.Index] = NewMessage(APP_JUROR_EVIDENCE, p.JurorEvidencePayload)
+		processed++
+	}
 	if processed < temp.MsgCount {
 		return errors.New("Some message don't process in transaction_json.go")
 	}
@@ -184,6 +184,20 @@ func temp2Tx(temp *transactionTemp, tx *Transaction) error {
 				return err
 			}
 			m1.Payload = &accountUpdateOp
+		} else if m.App == APP_MEDIATOR_EVIDENCE {
+			var evidence MediatorEvidencePayload
+			err := rlp.DecodeBytes(m.Data, &evidence)
+			if err != nil {
+				return err
+			}
+			m1.Payload = &evidence
+		} else if m.App == APP_JUROR_EVIDENCE {
+			var evidence JurorEvidencePayload
+			err := rlp.DecodeBytes(m.Data, &evidence)
+			if err != nil {
+				return err
+			}
+			m1.Payload = &evidence
 		} else {
 			fmt.Println("Unknown message app type:", m.App)
 		}
@@ -225,6 +225,32 @@ func TestContractInvokeReqPayload_Rlp(t *testing.T) {
 	assertEqualRlp(t, pay, pay2)
 }
 
+func TestContractInvokeReqPayload_CostLimitRlp(t *testing.T) {
+	old := newTestContractInvokeReq()
+	oldBytes, err := rlp.EncodeToBytes(old)
+	assert.Nil(t, err)
+	//没有CostLimit的请求编码不变
+	pay := &ContractInvokeRequestPayload{ContractId: old.ContractId, Args: old.Args, Timeout: old.Timeout}
+	data, err := rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+	assert.Equal(t, oldBytes, data)
+
+	pay.CostLimit = 5000
+	data, err = rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+	pay2 := &ContractInvokeRequestPayload{}
+	assert.Nil(t, rlp.DecodeBytes(data, pay2))
+	assert.Equal(t, uint64(5000), pay2.CostLimit)
+	assert.True(t, pay.Equal(pay2))
+	assert.NotNil(t, rlp.DecodeBytes(data, old))
+
+	//CostLimit为0时不能编码
+	data, err = rlp.EncodeToBytes(&contractInvokeRequestPayloadTemp{ContractId: old.ContractId,
+		Extension: []rlp.RawValue{{0x80}}})
+	assert.Nil(t, err)
+	assert.NotNil(t, rlp.DecodeBytes(data, pay2))
+}
+
 func newTestContractInvokeReq() *TestContractInvokeRequestPayload {
 	a := []byte("AAAA")
 	b := []byte("BBBBBBBBBBB")
@@ -247,6 +273,59 @@ func TestContractInvokeResultPayload_Rlp(t *testing.T) {
 	assertEqualRlp(t, pay, pay2)
 }
 
+func TestContractInvokePayload_RangeReadSetRlp(t *testing.T) {
+	pay := newTestContractInvokeResult()
+	oldBytes, err := rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+	//没有范围查询的payload编码不变
+	pay.RangeReadSet = []ContractRangeReadSet{}
+	data, err := rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+	assert.Equal(t, oldBytes, data)
+
+	pay.RangeReadSet = []ContractRangeReadSet{{ContractId: []byte("ContractId"), StartKey: "A", EndKey: "B",
+		ResultHash: HashContractStates([]*ContractStateKV{{Key: "A1", Version: pay.ReadSet[0].Version}})}}
+	data, err = rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+	pay2 := &ContractInvokePayload{}
+	assert.Nil(t, rlp.DecodeBytes(data, pay2))
+	assert.Equal(t, pay.RangeReadSet, pay2.RangeReadSet)
+	assert.False(t, pay.Equal(newTestContractInvokeResult()))
+
+	pay3 := &ContractInvokePayload{}
+	assert.Nil(t, rlp.DecodeBytes(oldBytes, pay3))
+	assert.Equal(t, 0, len(pay3.RangeReadSet))
+}
+
+func TestContractInvokePayload_CostRlp(t *testing.T) {
+	pay := newTestContractInvokeResult()
+	pay.RangeReadSet = []ContractRangeReadSet{{ContractId: []byte("ContractId"), StartKey: "A", EndKey: "B"}}
+	oldBytes, err := rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+
+	pay.Cost = 12345
+	data, err := rlp.EncodeToBytes(pay)
+	assert.Nil(t, err)
+	assert.NotEqual(t, oldBytes, data)
+	pay2 := &ContractInvokePayload{}
+	assert.Nil(t, rlp.DecodeBytes(data, pay2))
+	assert.Equal(t, pay.RangeReadSet, pay2.RangeReadSet)
+	assert.Equal(t, uint64(12345), pay2.Cost)
+	assert.True(t, pay.Equal(pay2))
+
+	pay3 := &ContractInvokePayload{}
+	assert.Nil(t, rlp.DecodeBytes(oldBytes, pay3))
+	assert.Equal(t, uint64(0), pay3.Cost)
+
+	//Cost只能在最后
+	rangeRead, _ := rlp.EncodeToBytes(pay.RangeReadSet[0])
+	cost, _ := rlp.EncodeToBytes(pay.Cost)
+	data, err = rlp.EncodeToBytes(&contractInvokePayloadTemp{ContractId: pay.ContractId,
+		Extension: []rlp.RawValue{cost, rangeRead}})
+	assert.Nil(t, err)
+	assert.NotNil(t, rlp.DecodeBytes(data, pay3))
+}
+
 func newTestContractInvokeResult() *ContractInvokePayload {
 	version := &StateVersion{&ChainIndex{PTNCOIN, 100}, 2}
 	read1 := ContractReadSet{"A", version, []byte("This is value")}
@@ -21,7 +21,6 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -32,8 +31,6 @@ import (
 	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/common/util"
-	"github.com/palletone/go-palletone/core"
-	"go.dedis.ch/kyber/v3"
 )
 
 // unit state
@@ -54,6 +51,8 @@ type Header struct {
 	Extra       []byte        `json:"extra"`
 	Time        int64         `json:"creation_time"` // unit create time
 	CryptoLib   []byte        `json:"crypto_lib"`    //该区块使用的加解密算法和哈希算法，0位表示非对称加密算法，1位表示Hash算法
+	UtxoRoot    common.Hash   `json:"utxo_root"`     //父单元之后的UTXO集合的承诺，未启用时为空
+	StateRoot   common.Hash   `json:"state_root"`    //父单元之后的合约状态的承诺，未启用时为空
 }
 
 func (h *Header) NumberU64() uint64 {
@@ -68,18 +67,6 @@ func (h *Header) GetGroupPubKeyByte() []byte {
 	return h.GroupPubKey
 }
 
-func (h *Header) GetGroupPubKey() (kyber.Point, error) {
-	pubKeyB := h.GroupPubKey
-	if len(pubKeyB) == 0 {
-		return nil, errors.New("group public key is null")
-	}
-
-	pubKey := core.Suite.Point()
-	err := pubKey.UnmarshalBinary(pubKeyB)
-
-	return pubKey, err
-}
-
 func (cpy *Header) CopyHeader(h *Header) {
 	index := new(ChainIndex)
    index.Index = h.Number.Index
@@ -201,6 +188,8 @@ func CopyHeader(h *Header) *Header {
 	if len(h.TxRoot) > 0 {
 		cpy.TxRoot.Set(h.TxRoot)
 	}
+	cpy.UtxoRoot = h.UtxoRoot
+	cpy.StateRoot = h.StateRoot
 
 	if len(h.TxsIllegal) > 0 {
 		cpy.TxsIllegal = make([]uint16, 0)
@@ -283,10 +272,6 @@ func (unit *Unit) Author() common.Address {
 	return unit.UnitHeader.Author()
 }
 
-func (unit *Unit) GetGroupPubKey() (kyber.Point, error) {
-	return unit.UnitHeader.GetGroupPubKey()
-}
-
 func (unit *Unit) GetGroupPubKeyByte() []byte {
 	return unit.UnitHeader.GetGroupPubKeyByte()
 }
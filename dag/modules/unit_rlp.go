@@ -34,6 +34,8 @@ type headerTemp struct {
 	Extra       []byte        `json:"extra"`
 	Time        uint32        `json:"creation_time"` // unit create time
 	CryptoLib   []byte        `json:"crypto_lib"`    //该区块使用的加解密算法和哈希算法，0位表示非对称加密算法，1位表示Hash算法
+	// 扩展字段，为空时与旧版本的编码相同，依次是UtxoRoot、StateRoot
+	Extension [][]byte `rlp:"tail"`
 }
 
 func (input *Header) DecodeRLP(s *rlp.Stream) error {
@@ -57,6 +59,12 @@ func (input *Header) DecodeRLP(s *rlp.Stream) error {
 	input.Extra = temp.Extra
 	input.Time = int64(temp.Time)
 	input.CryptoLib = temp.CryptoLib
+	if len(temp.Extension) > 0 {
+		input.UtxoRoot = common.BytesToHash(temp.Extension[0])
+	}
+	if len(temp.Extension) > 1 {
+		input.StateRoot = common.BytesToHash(temp.Extension[1])
+	}
 	return nil
 }
 func (input *Header) EncodeRLP(w io.Writer) error {
@@ -71,6 +79,11 @@ func (input *Header) EncodeRLP(w io.Writer) error {
 	temp.Extra = input.Extra
 	temp.Time = uint32(input.Time)
 	temp.CryptoLib = input.CryptoLib
+	if input.StateRoot != (common.Hash{}) {
+		temp.Extension = [][]byte{input.UtxoRoot.Bytes(), input.StateRoot.Bytes()}
+	} else if input.UtxoRoot != (common.Hash{}) {
+		temp.Extension = [][]byte{input.UtxoRoot.Bytes()}
+	}
 	return rlp.Encode(w, temp)
 }
 
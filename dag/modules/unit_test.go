@@ -212,6 +212,48 @@ func TestHeaderRLP(t *testing.T) {
 	assertEqualRlp(t, h, h2)
 }
 
+func TestHeaderRLP_UtxoRoot(t *testing.T) {
+	h := mockHeader()
+	hash := h.Hash()
+	data, err := rlp.EncodeToBytes(h)
+	assert.Nil(t, err)
+	h2 := &Header{}
+	assert.Nil(t, rlp.DecodeBytes(data, h2))
+	assert.Equal(t, common.Hash{}, h2.UtxoRoot)
+
+	//设置UtxoRoot后Hash改变，并且可以正确解码
+	h.UtxoRoot = common.HexToHash("0x1234")
+	assert.NotEqual(t, hash, h.Hash())
+	data, err = rlp.EncodeToBytes(h)
+	assert.Nil(t, err)
+	h2 = &Header{}
+	assert.Nil(t, rlp.DecodeBytes(data, h2))
+	assert.Equal(t, h.UtxoRoot, h2.UtxoRoot)
+	assert.Equal(t, h.Hash(), h2.Hash())
+	assert.Equal(t, h.UtxoRoot, CopyHeader(h).UtxoRoot)
+}
+
+func TestHeaderRLP_StateRoot(t *testing.T) {
+	h := mockHeader()
+	h.StateRoot = common.HexToHash("0x5678")
+	data, err := rlp.EncodeToBytes(h)
+	assert.Nil(t, err)
+	h2 := &Header{}
+	assert.Nil(t, rlp.DecodeBytes(data, h2))
+	assert.Equal(t, common.Hash{}, h2.UtxoRoot)
+	assert.Equal(t, h.StateRoot, h2.StateRoot)
+	assert.Equal(t, h.Hash(), h2.Hash())
+
+	h.UtxoRoot = common.HexToHash("0x1234")
+	data, err = rlp.EncodeToBytes(h)
+	assert.Nil(t, err)
+	h2 = &Header{}
+	assert.Nil(t, rlp.DecodeBytes(data, h2))
+	assert.Equal(t, h.UtxoRoot, h2.UtxoRoot)
+	assert.Equal(t, h.StateRoot, h2.StateRoot)
+	assert.Equal(t, h.StateRoot, CopyHeader(h).StateRoot)
+}
+
 func assertEqualRlp(t *testing.T, a, b interface{}) {
 	aa, err := rlp.EncodeToBytes(a)
 	if err != nil {
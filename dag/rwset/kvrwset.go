@@ -21,6 +21,7 @@ package rwset
 
 import (
 	"github.com/golang/protobuf/proto"
+	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/dag/modules"
 )
 
@@ -135,6 +136,41 @@ type Version struct {
 	//txNum   uint64 `protobuf:"varint,2,opt,name=tx_num,json=txNum"`
 }
 
+// KVRangeRead captures a range query performed during transaction simulation,
+// the range [startKey,endKey) is re-executed when packing to detect phantom reads
+type KVRangeRead struct {
+	startKey   string
+	endKey     string
+	resultHash common.Hash
+	ContractId []byte
+}
+
+func (m *KVRangeRead) GetStartKey() string {
+	if m != nil {
+		return m.startKey
+	}
+	return ""
+}
+
+func (m *KVRangeRead) GetEndKey() string {
+	if m != nil {
+		return m.endKey
+	}
+	return ""
+}
+
+func (m *KVRangeRead) GetResultHash() common.Hash {
+	if m != nil {
+		return m.resultHash
+	}
+	return common.Hash{}
+}
+
+func newKVRangeRead(contractId []byte, startKey, endKey string, states []*modules.ContractStateKV) *KVRangeRead {
+	return &KVRangeRead{startKey: startKey, endKey: endKey, resultHash: modules.HashContractStates(states),
+		ContractId: contractId}
+}
+
 // NewKVRead helps constructing proto message kvrwset.KVRead
 func NewKVRead(contractId []byte, key string, version *modules.StateVersion) *KVRead {
 	return &KVRead{key: key, version: version, ContractId: contractId}
@@ -22,11 +22,13 @@ package rwset
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/dag"
 	"github.com/palletone/go-palletone/dag/dagconfig"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -41,8 +43,15 @@ type RwSetTxSimulator struct {
 	//writePerformed          bool   // 没用到，注释掉
 	pvtdataQueriesPerformed bool
 	doneInvoked             bool
+	//本次合约调用的cost，costLimit为0时不计价
+	costLimit uint64
+	cost      uint64
+	//计价使用的系统参数
+	costs core.ChainParametersBase
 }
 
+var ErrCostExceeded = errors.New("contract execution cost exceeded the limit")
+
 type VersionedValue struct {
 	Value   []byte
 	Version *Version
@@ -69,7 +78,55 @@ func NewBasedTxSimulator(idag dag.IDag, hash common.Hash) *RwSetTxSimulator {
 //	return data, nil
 //}
 
+//开始一次合约调用的计价，limit为0时不计价
+func (s *RwSetTxSimulator) SetCostLimit(limit uint64) {
+	s.costLimit = limit
+	s.cost = 0
+	if limit > 0 {
+		s.costs = s.dag.GetChainParameters().ChainParametersBase
+	}
+}
+
+func (s *RwSetTxSimulator) GetCost() uint64 {
+	return s.cost
+}
+
+//累加cost，超过上限后本次调用的所有账本访问都返回错误
+func (s *RwSetTxSimulator) AddCost(cost uint64) error {
+	if s.costLimit == 0 {
+		return nil
+	}
+	if s.cost+cost < s.cost {
+		s.cost = math.MaxUint64
+	} else {
+		s.cost += cost
+	}
+	if s.cost > s.costLimit {
+		return ErrCostExceeded
+	}
+	return nil
+}
+
+//wasm合约执行的指令数，按系统参数计价
+func (s *RwSetTxSimulator) AddInstructionCost(count uint64) error {
+	if s.costLimit == 0 {
+		return nil
+	}
+	cost := count * s.costs.ContractInstructionCost
+	if s.costs.ContractInstructionCost != 0 && cost/s.costs.ContractInstructionCost != count {
+		cost = math.MaxUint64
+	}
+	return s.AddCost(cost)
+}
+
+func (s *RwSetTxSimulator) addReadCost(key string, value []byte) error {
+	return s.AddCost(uint64(len(key)+len(value)) * s.costs.ContractReadByteCost)
+}
+
 func (s *RwSetTxSimulator) GetGlobalProp() ([]byte, error) {
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
 	gp := s.dag.GetGlobalProp()
 
 	data, err := rlp.EncodeToBytes(gp)
@@ -86,11 +143,14 @@ func (s *RwSetTxSimulator) GetState(contractid []byte, ns string, key string) ([
 	if err := s.CheckDone(); err != nil {
 		return nil, err
 	}
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
 	if value, has := s.write_cache[key]; has {
 		if s.rwsetBuilder != nil {
 			s.rwsetBuilder.AddToReadSet(contractid, ns, key, nil)
 		}
-		return value, nil
+		return value, s.addReadCost(key, value)
 	}
 	val, ver, err := s.dag.GetContractState(contractid, key)
 	//TODO 这里证明数据库里面没有该账户信息，需要返回nil,nil
@@ -107,12 +167,15 @@ func (s *RwSetTxSimulator) GetState(contractid []byte, ns string, key string) ([
 
 	//TODO change.
 	//return testValue, nil
-	return val, nil
+	return val, s.addReadCost(key, val)
 }
 func (s *RwSetTxSimulator) GetStatesByPrefix(contractid []byte, ns string, prefix string) ([]*modules.KeyValue, error) {
 	if err := s.CheckDone(); err != nil {
 		return nil, err
 	}
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
 
 	data, err := s.dag.GetContractStatesByPrefix(contractid, prefix)
 
@@ -129,6 +192,9 @@ func (s *RwSetTxSimulator) GetStatesByPrefix(contractid []byte, ns string, prefi
 		if s.rwsetBuilder != nil {
 			s.rwsetBuilder.AddToReadSet(contractid, ns, key, row.Version)
 		}
+		if err := s.addReadCost(key, row.Value); err != nil {
+			return nil, err
+		}
 	}
 
 	log.Debugf("RW:GetStatesByPrefix,ns[%s]--contractid[%x]---prefix[%s]", ns, contractid, prefix)
@@ -136,12 +202,80 @@ func (s *RwSetTxSimulator) GetStatesByPrefix(contractid []byte, ns string, prefi
 	return result, nil
 }
 
+func (s *RwSetTxSimulator) GetStatesByRange(contractid []byte, ns string, startKey, endKey string,
+	limit int) ([]*modules.KeyValue, string, error) {
+	if err := s.CheckDone(); err != nil {
+		return nil, "", err
+	}
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, "", err
+	}
+	queryLimit := 0
+	if limit > 0 {
+		//多查一个用于确定下一批的起始key
+		queryLimit = limit + 1
+	}
+	states, err := s.dag.GetContractStatesByRange(contractid, startKey, endKey, queryLimit)
+	if err != nil {
+		return nil, "", err
+	}
+	bookmark := ""
+	rangeEnd := endKey
+	if limit > 0 && len(states) > limit {
+		bookmark = states[limit].Key
+		rangeEnd = bookmark
+		states = states[:limit]
+	}
+	result := make([]*modules.KeyValue, 0, len(states))
+	for _, state := range states {
+		if err := s.addReadCost(state.Key, state.Value); err != nil {
+			return nil, "", err
+		}
+		result = append(result, &modules.KeyValue{Key: state.Key, Value: state.Value})
+		if s.rwsetBuilder != nil {
+			s.rwsetBuilder.AddToReadSet(contractid, ns, state.Key, state.Version)
+		}
+	}
+	//记录实际读取的区间，打包时检查区间内是否有新增或删除的key
+	if s.rwsetBuilder != nil {
+		s.rwsetBuilder.AddToRangeReadSet(contractid, ns, startKey, rangeEnd, states)
+	}
+	log.Debugf("RW:GetStatesByRange,ns[%s]--contractid[%x]---range[%s,%s)---count[%d]", ns, contractid,
+		startKey, rangeEnd, len(result))
+	return result, bookmark, nil
+}
+
+func (s *RwSetTxSimulator) GetHistoryForKey(contractid []byte, ns string,
+	key string) ([]*modules.ContractStateHistory, error) {
+	if err := s.CheckDone(); err != nil {
+		return nil, err
+	}
+	if err := s.AddCost(core.DefaultContractCallCost); err != nil {
+		return nil, err
+	}
+	histories, err := s.dag.GetContractStateHistory(contractid, key, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, history := range histories {
+		if err := s.addReadCost(key, history.Value); err != nil {
+			return nil, err
+		}
+	}
+	log.Debugf("RW:GetHistoryForKey,ns[%s]--contractid[%x]---key[%s]---count[%d]", ns, contractid, key,
+		len(histories))
+	return histories, nil
+}
+
 // GetState implements method in interface `ledger.TxSimulator`
 func (s *RwSetTxSimulator) GetTimestamp(ns string, rangeNumber uint32) ([]byte, error) {
 	//testValue := []byte("abc")
 	if err := s.CheckDone(); err != nil {
 		return nil, err
 	}
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
 	gasToken := dagconfig.DagConfig.GetGasToken()
 	header := s.dag.CurrentHeader(gasToken)
 	timeIndex := header.Number.Index / uint64(rangeNumber) * uint64(rangeNumber)
@@ -152,6 +286,20 @@ func (s *RwSetTxSimulator) GetTimestamp(ns string, rangeNumber uint32) ([]byte,
 
 	return []byte(fmt.Sprintf("%d", timeHeader.Time)), nil
 }
+func (s *RwSetTxSimulator) GetHeight(ns string, rangeNumber uint32) ([]byte, error) {
+	if err := s.CheckDone(); err != nil {
+		return nil, err
+	}
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
+	gasToken := dagconfig.DagConfig.GetGasToken()
+	height := s.dag.CurrentHeader(gasToken).Number.Index
+	if rangeNumber > 0 {
+		height = height / uint64(rangeNumber) * uint64(rangeNumber)
+	}
+	return []byte(fmt.Sprintf("%d", height)), nil
+}
 func (s *RwSetTxSimulator) SetState(contractId []byte, ns string, key string, value []byte) error {
 	if err := s.CheckDone(); err != nil {
 		return err
@@ -159,6 +307,10 @@ func (s *RwSetTxSimulator) SetState(contractId []byte, ns string, key string, va
 	if s.pvtdataQueriesPerformed {
 		return errors.New("pvtdata Queries Performed")
 	}
+	if err := s.AddCost(s.costs.ContractCallCost +
+		uint64(len(key)+len(value))*s.costs.ContractWriteByteCost); err != nil {
+		return err
+	}
 	//todo ValidateKeyValue
 	s.rwsetBuilder.AddToWriteSet(contractId, ns, key, value)
 	s.write_cache[key] = value
@@ -191,6 +343,12 @@ func (s *RwSetTxSimulator) GetRwData(ns string) ([]*KVRead, []*KVWrite, error) {
 	//sort keys and convert map to slice
 	return convertReadMap2Slice(rd), convertWriteMap2Slice(wt), nil
 }
+func (s *RwSetTxSimulator) GetRangeReadData(ns string) ([]*KVRangeRead, error) {
+	if s.rwsetBuilder == nil {
+		return nil, nil
+	}
+	return s.rwsetBuilder.GetRangeReadSet(ns), nil
+}
 func convertReadMap2Slice(rd map[string]*KVRead) []*KVRead {
 	keys := make([]string, 0)
 	for k := range rd {
@@ -218,7 +376,19 @@ func convertWriteMap2Slice(rd map[string]*KVWrite) []*KVWrite {
 
 //get all dag
 func (s *RwSetTxSimulator) GetContractStatesById(contractid []byte) (map[string]*modules.ContractStateValue, error) {
-	return s.dag.GetContractStatesById(contractid)
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
+	states, err := s.dag.GetContractStatesById(contractid)
+	if err != nil {
+		return nil, err
+	}
+	for key, state := range states {
+		if err := s.addReadCost(key, state.Value); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
 }
 
 func (s *RwSetTxSimulator) CheckDone() error {
@@ -241,6 +411,8 @@ func (s *RwSetTxSimulator) Close() {
 	s.rwsetBuilder = item.rwsetBuilder
 	s.write_cache = item.write_cache
 	s.dag = item.dag
+	s.costLimit = item.costLimit
+	s.cost = item.cost
 }
 
 func (h *RwSetTxSimulator) GetTxSimulationResults() ([]byte, error) {
@@ -250,6 +422,9 @@ func (h *RwSetTxSimulator) GetTxSimulationResults() ([]byte, error) {
 
 func (s *RwSetTxSimulator) GetTokenBalance(ns string, addr common.Address, asset *modules.Asset) (
 	map[modules.Asset]uint64, error) {
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
 	var utxos map[modules.OutPoint]*modules.Utxo
 	if asset == nil {
 		utxos, _ = s.dag.GetAddrUtxos(addr)
@@ -259,6 +434,14 @@ func (s *RwSetTxSimulator) GetTokenBalance(ns string, addr common.Address, asset
 	return convertUtxo2Balance(utxos), nil
 }
 
+func (s *RwSetTxSimulator) GetTokenBalanceAt(ns string, addr common.Address, asset *modules.Asset,
+	height uint64) (map[modules.Asset]uint64, error) {
+	if err := s.AddCost(s.costs.ContractCallCost); err != nil {
+		return nil, err
+	}
+	return s.dag.GetAddrTokenBalanceAt(addr, asset, height)
+}
+
 func convertUtxo2Balance(utxos map[modules.OutPoint]*modules.Utxo) map[modules.Asset]uint64 {
 	result := map[modules.Asset]uint64{}
 	for _, v := range utxos {
@@ -272,6 +455,9 @@ func convertUtxo2Balance(utxos map[modules.OutPoint]*modules.Utxo) map[modules.A
 }
 func (s *RwSetTxSimulator) PayOutToken(ns string, address string, token *modules.Asset, amount uint64,
 	lockTime uint32) error {
+	if err := s.AddCost(s.costs.ContractCallCost + s.costs.ContractPayoutCost); err != nil {
+		return err
+	}
 	s.rwsetBuilder.AddTokenPayOut(ns, address, token, amount, lockTime)
 	return nil
 }
@@ -285,11 +471,18 @@ func (s *RwSetTxSimulator) GetTokenSupplyData(ns string) ([]*modules.TokenSupply
 	return s.rwsetBuilder.GetTokenSupply(ns), nil
 }
 func (s *RwSetTxSimulator) DefineToken(ns string, tokenType int32, define []byte, creator string) error {
+	if err := s.AddCost(s.costs.ContractCallCost +
+		uint64(len(define))*s.costs.ContractWriteByteCost); err != nil {
+		return err
+	}
 	createAddr, _ := common.StringToAddress(creator)
 	s.rwsetBuilder.DefineToken(ns, tokenType, define, createAddr)
 	return nil
 }
 func (s *RwSetTxSimulator) SupplyToken(ns string, assetId, uniqueId []byte, amt uint64, creator string) error {
+	if err := s.AddCost(s.costs.ContractCallCost + s.costs.ContractPayoutCost); err != nil {
+		return err
+	}
 	createAddr, _ := common.StringToAddress(creator)
 	return s.rwsetBuilder.AddSupplyToken(ns, assetId, uniqueId, amt, createAddr)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
 	"testing"
 	"time"
@@ -43,6 +44,84 @@ func TestRwSetTxSimulator_GetTokenBalance(t *testing.T) {
 	assert.True(t, len(balance1) == 1, "for PTN asset, only need return 1 row")
 	assert.Equal(t, balance1[*ptnAsset], uint64(300), "sum PTN must 300")
 }
+func TestRwSetTxSimulator_GetStatesByRange(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	idag := dag.NewMockIDag(mockCtrl)
+	simulator := &RwSetTxSimulator{rwsetBuilder: NewRWSetBuilder(), dag: idag}
+	contractId := []byte("contract")
+	version := &modules.StateVersion{Height: &modules.ChainIndex{AssetID: modules.PTNCOIN, Index: 10}}
+	states := []*modules.ContractStateKV{}
+	for _, key := range []string{"a", "b", "c"} {
+		states = append(states, &modules.ContractStateKV{Key: key, Value: []byte(key), Version: version})
+	}
+	idag.EXPECT().GetContractStatesByRange(contractId, "a", "z", 3).Return(states, nil)
+	idag.EXPECT().GetContractStatesByRange(contractId, "c", "z", 3).Return(states[2:], nil)
+
+	kvs, bookmark, err := simulator.GetStatesByRange(contractId, "ns", "a", "z", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(kvs))
+	assert.Equal(t, "c", bookmark)
+	kvs, bookmark, err = simulator.GetStatesByRange(contractId, "ns", bookmark, "z", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(kvs))
+	assert.Equal(t, "", bookmark)
+
+	reads, _, _ := simulator.GetRwData("ns")
+	assert.Equal(t, 3, len(reads))
+	rangeReads, err := simulator.GetRangeReadData("ns")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(rangeReads))
+	//第一批只读到了bookmark之前
+	assert.Equal(t, "a", rangeReads[0].GetStartKey())
+	assert.Equal(t, "c", rangeReads[0].GetEndKey())
+	assert.Equal(t, modules.HashContractStates(states[:2]), rangeReads[0].GetResultHash())
+	assert.Equal(t, "z", rangeReads[1].GetEndKey())
+	assert.Equal(t, modules.HashContractStates(states[2:]), rangeReads[1].GetResultHash())
+}
+func TestRwSetTxSimulator_Cost(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	idag := dag.NewMockIDag(mockCtrl)
+	simulator := &RwSetTxSimulator{rwsetBuilder: NewRWSetBuilder(), write_cache: make(map[string][]byte), dag: idag}
+	contractId := []byte("contract")
+	version := &modules.StateVersion{Height: &modules.ChainIndex{AssetID: modules.PTNCOIN, Index: 10}}
+	idag.EXPECT().GetContractState(contractId, "key").Return([]byte("value"), version, nil).AnyTimes()
+	cp := core.NewChainParams()
+	cp.ContractCallCost = 200
+	idag.EXPECT().GetChainParameters().Return(&cp).AnyTimes()
+
+	//不计价
+	_, err := simulator.GetState(contractId, "ns", "key")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), simulator.GetCost())
+
+	//按系统参数计价
+	simulator.SetCostLimit(1700)
+	_, err = simulator.GetState(contractId, "ns", "key")
+	assert.Nil(t, err)
+	readCost := 200 + 8*cp.ContractReadByteCost
+	assert.Equal(t, readCost, simulator.GetCost())
+	assert.Nil(t, simulator.SetState(contractId, "ns", "k", []byte("v")))
+	writeCost := 200 + 2*cp.ContractWriteByteCost
+	assert.Equal(t, readCost+writeCost, simulator.GetCost())
+	assert.Nil(t, simulator.PayOutToken("ns", "P1", nil, 1, 0))
+	assert.Equal(t, ErrCostExceeded, simulator.PayOutToken("ns", "P1", nil, 1, 0))
+	//超过上限后不能继续访问账本
+	_, err = simulator.GetState(contractId, "ns", "key")
+	assert.Equal(t, ErrCostExceeded, err)
+
+	//重新开始计价
+	simulator.SetCostLimit(1000)
+	assert.Equal(t, uint64(0), simulator.GetCost())
+	//wasm合约执行的指令
+	assert.Nil(t, simulator.AddInstructionCost(1000/cp.ContractInstructionCost))
+	assert.Equal(t, ErrCostExceeded, simulator.AddInstructionCost(1))
+	simulator.SetCostLimit(1000)
+	assert.Equal(t, ErrCostExceeded, simulator.AddInstructionCost(math.MaxUint64))
+}
 func mockUtxos() map[modules.OutPoint]*modules.Utxo {
 	result := map[modules.OutPoint]*modules.Utxo{}
 	p1 := modules.NewOutPoint(common.Hash{}, 0, 0)
@@ -15,21 +15,38 @@ type TxManager interface {
 type TxSimulator interface {
 	GetState(contractid []byte, ns string, key string) ([]byte, error)
 	GetStatesByPrefix(contractid []byte, ns string, prefix string) ([]*modules.KeyValue, error)
+	//按key的顺序查询[startKey,endKey)区间的状态，最多返回limit个，返回的bookmark为下一批的起始key，
+	//与GetStatesByPrefix相同，读不到本交易的写入
+	GetStatesByRange(contractid []byte, ns string, startKey, endKey string, limit int) (
+		[]*modules.KeyValue, string, error)
+	//查询状态的修改历史，历史不会被记录到读集中
+	GetHistoryForKey(contractid []byte, ns string, key string) ([]*modules.ContractStateHistory, error)
 	GetTimestamp(ns string, rangeNumber uint32) ([]byte, error)
+	//返回GetTimestamp所用unit的高度
+	GetHeight(ns string, rangeNumber uint32) ([]byte, error)
 	SetState(contractid []byte, ns string, key string, value []byte) error
 	GetTokenBalance(ns string, addr common.Address, asset *modules.Asset) (map[modules.Asset]uint64, error)
+	//返回地址在指定高度的稳定单元时持有的Token
+	GetTokenBalanceAt(ns string, addr common.Address, asset *modules.Asset, height uint64) (
+		map[modules.Asset]uint64, error)
 	PayOutToken(ns string, address string, token *modules.Asset, amount uint64, lockTime uint32) error
 	DefineToken(ns string, tokenType int32, define []byte, creator string) error
 	SupplyToken(ns string, assetId, uniqueId []byte, amt uint64, creator string) error
 	DeleteState(contractid []byte, ns string, key string) error
 	GetContractStatesById(contractid []byte) (map[string]*modules.ContractStateValue, error)
 	GetRwData(ns string) ([]*KVRead, []*KVWrite, error)
+	GetRangeReadData(ns string) ([]*KVRangeRead, error)
 	GetPayOutData(ns string) ([]*modules.TokenPayOut, error)
 	GetTokenDefineData(ns string) (*modules.TokenDefine, error)
 	GetTokenSupplyData(ns string) ([]*modules.TokenSupply, error)
 	GetTxSimulationResults() ([]byte, error)
 	CheckDone() error
 	Done()
+	//用户合约执行的计价，limit为0时不计价；超过上限后访问账本返回ErrCostExceeded
+	SetCostLimit(limit uint64)
+	GetCost() uint64
+	AddCost(cost uint64) error
+	AddInstructionCost(count uint64) error
 	String() string
 
 	//GetChainParameters() ([]byte, error)
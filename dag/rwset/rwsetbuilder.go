@@ -32,6 +32,7 @@ type nsPubRwBuilder struct {
 	namespace   string
 	readMap     map[string]*KVRead
 	writeMap    map[string]*KVWrite
+	rangeReads  []*KVRangeRead
 	tokenPayOut []*modules.TokenPayOut
 	tokenSupply []*modules.TokenSupply
 	tokenDefine *modules.TokenDefine
@@ -64,6 +65,16 @@ func (b *RWSetBuilder) AddToReadSet(contractId []byte, ns string, key string, ve
 	// ReadSet
 	nsPubRwBuilder.readMap[key] = NewKVRead(contractId, key, version)
 }
+func (b *RWSetBuilder) AddToRangeReadSet(contractId []byte, ns string, startKey, endKey string,
+	states []*modules.ContractStateKV) {
+	nsPubRwBuilder := b.getOrCreateNsPubRwBuilder(ns)
+	nsPubRwBuilder.rangeReads = append(nsPubRwBuilder.rangeReads,
+		newKVRangeRead(contractId, startKey, endKey, states))
+}
+func (b *RWSetBuilder) GetRangeReadSet(ns string) []*KVRangeRead {
+	nsPubRwBuilder := b.getOrCreateNsPubRwBuilder(ns)
+	return nsPubRwBuilder.rangeReads
+}
 func (b *RWSetBuilder) AddTokenPayOut(ns string, addr string, asset *modules.Asset, amount uint64, lockTime uint32) {
 	nsPubRwBuilder := b.getOrCreateNsPubRwBuilder(ns)
 	if nsPubRwBuilder.tokenPayOut == nil {
@@ -131,6 +142,7 @@ func newNsPubRwBuilder(namespace string) *nsPubRwBuilder {
 		namespace,
 		make(map[string]*KVRead),
 		make(map[string]*KVWrite),
+		nil,
 		[]*modules.TokenPayOut{},
 		[]*modules.TokenSupply{},
 		nil,
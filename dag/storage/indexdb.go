@@ -21,8 +21,11 @@
 package storage
 
 import (
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/common/ptndb"
 	"github.com/palletone/go-palletone/dag/constants"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -48,6 +51,9 @@ type IIndexDb interface {
 	GetMainDataTxIds(maindata []byte) ([]common.Hash, error)
 	SaveProofOfExistence(poe *modules.ProofOfExistence) error
 	QueryProofOfExistenceByReference(ref []byte) ([]*modules.ProofOfExistence, error)
+
+	SaveContractStateHistory(contractId []byte, key string, history *modules.ContractStateHistory) error
+	GetContractStateHistory(contractId []byte, key string, from, to uint64) ([]*modules.ContractStateHistory, error)
 }
 
 func (db *IndexDb) SaveAddressTxId(address common.Address, txid common.Hash) error {
@@ -141,3 +147,50 @@ func (db *IndexDb) QueryProofOfExistenceByReference(ref []byte) ([]*modules.Proo
 	}
 	return result, nil
 }
+
+//合约状态的key长度不固定，用hash保证不同key的前缀不会重叠
+func getContractStateHistoryPrefix(contractId []byte, key string) []byte {
+	prefix := append(constants.CONTRACT_STATE_HISTORY, contractId...)
+	return append(prefix, crypto.Keccak256([]byte(key))...)
+}
+
+//高度和交易序号按大端序排列，同一个状态的修改按时间顺序保存
+func getContractStateHistoryKey(prefix []byte, height uint64, txIndex uint32) []byte {
+	key := make([]byte, len(prefix)+12)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], height)
+	binary.BigEndian.PutUint32(key[len(prefix)+8:], txIndex)
+	return key
+}
+
+func (db *IndexDb) SaveContractStateHistory(contractId []byte, key string,
+	history *modules.ContractStateHistory) error {
+	prefix := getContractStateHistoryPrefix(contractId, key)
+	hkey := getContractStateHistoryKey(prefix, history.Version.Height.Index, history.Version.TxIndex)
+	return StoreToRlpBytes(db.db, hkey, history)
+}
+
+//查询高度在[from, to]之间的修改历史，to为0表示不限制
+func (db *IndexDb) GetContractStateHistory(contractId []byte, key string,
+	from, to uint64) ([]*modules.ContractStateHistory, error) {
+	prefix := getContractStateHistoryPrefix(contractId, key)
+	iter := db.db.NewIteratorWithPrefix(prefix)
+	defer iter.Release()
+
+	result := []*modules.ContractStateHistory{}
+	for ok := iter.Seek(getContractStateHistoryKey(prefix, from, 0)); ok; ok = iter.Next() {
+		hkey := iter.Key()
+		if len(hkey) != len(prefix)+12 {
+			continue
+		}
+		if to > 0 && binary.BigEndian.Uint64(hkey[len(prefix):]) > to {
+			break
+		}
+		history := &modules.ContractStateHistory{}
+		if err := rlp.DecodeBytes(iter.Value(), history); err != nil {
+			return nil, err
+		}
+		result = append(result, history)
+	}
+	return result, iter.Error()
+}
@@ -50,3 +50,48 @@ func TestIndexDb_QueryProofOfExistenceByReference(t *testing.T) {
 }
 
 
+
+func TestIndexDb_GetContractStateHistory(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	idxdb := NewIndexDb(db)
+	contractId := []byte("contract")
+	saveHistory := func(key string, height uint64, txIndex uint32, value string) {
+		history := &modules.ContractStateHistory{
+			TxId:      common.BytesToHash([]byte(value)),
+			Timestamp: height * 3,
+			Version:   &modules.StateVersion{Height: &modules.ChainIndex{Index: height}, TxIndex: txIndex},
+			Value:     []byte(value),
+			IsDelete:  value == "",
+		}
+		assert.Nil(t, idxdb.SaveContractStateHistory(contractId, key, history))
+	}
+	saveHistory("owner", 300, 1, "c")
+	saveHistory("owner", 5, 2, "a")
+	saveHistory("owner", 256, 0, "")
+	saveHistory("owner", 5, 3, "b")
+	//key是另一个key的前缀
+	saveHistory("own", 10, 0, "x")
+
+	result, err := idxdb.GetContractStateHistory(contractId, "owner", 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(result))
+	values := []string{}
+	for _, history := range result {
+		values = append(values, string(history.Value))
+	}
+	assert.Equal(t, []string{"a", "b", "", "c"}, values)
+	assert.True(t, result[2].IsDelete)
+	assert.Equal(t, uint32(3), result[1].Version.TxIndex)
+
+	result, err = idxdb.GetContractStateHistory(contractId, "owner", 6, 256)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, uint64(256), result[0].Version.Height.Index)
+
+	result, err = idxdb.GetContractStateHistory(contractId, "own", 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result))
+	result, err = idxdb.GetContractStateHistory([]byte("other"), "owner", 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(result))
+}
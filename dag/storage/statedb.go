@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
@@ -36,7 +37,10 @@ import (
 
 //保存了对合约写集、Config、Asset信息
 type StateDb struct {
-	db ptndb.Database
+	db       ptndb.Database
+	rootLock sync.Mutex
+	// 单元中还没有提交到trie的合约状态修改
+	pending stateTrieWrites
 }
 
 func NewStateDb(db ptndb.Database) *StateDb {
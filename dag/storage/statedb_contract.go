@@ -31,6 +31,7 @@ import (
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/contracts/syscontract"
 	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/errors"
 	"github.com/palletone/go-palletone/dag/modules"
 )
 
@@ -96,14 +97,22 @@ func (statedb *StateDb) SaveContractState(contractId []byte, ws *modules.Contrac
 		cid = ws.ContractId
 	}
 	key := getContractStateKey(cid, ws.Key)
+	writes := newStateTrieWrites()
 	if ws.IsDelete {
 		log.Debugf("Delete contract state by key:[%s]", ws.Key)
-		return statedb.db.Delete(key)
-	}
-	if err := storeBytesWithVersion(statedb.db, key, version, ws.Value); err != nil {
-		return err
+		if err := statedb.db.Delete(key); err != nil {
+			return err
+		}
+		writes.add(cid, ws.Key, nil)
+	} else {
+		if err := storeBytesWithVersion(statedb.db, key, version, ws.Value); err != nil {
+			return err
+		}
+		writes.add(cid, ws.Key, append(version.Bytes(), ws.Value...))
 	}
-	return nil
+	//创世和维护时单独保存的状态立即提交
+	statedb.updateStateTrie(writes)
+	return statedb.CommitStateRoot()
 }
 
 func getContractStateKey(id []byte, field string) []byte {
@@ -154,6 +163,7 @@ To save contract
 func (statedb *StateDb) SaveContractStates(id []byte, wset []modules.ContractWriteSet,
 	version *modules.StateVersion) error {
 	batch := statedb.db.NewBatch()
+	writes := newStateTrieWrites()
 	for _, write := range wset {
 		cid := id
 		if len(write.ContractId) != 0 {
@@ -164,10 +174,12 @@ func (statedb *StateDb) SaveContractStates(id []byte, wset []modules.ContractWri
 		if write.IsDelete {
 			batch.Delete(key)
 			log.Debugf("Delete contract state by key:[%s]", write.Key)
+			writes.add(cid, write.Key, nil)
 		} else {
 			if err := storeBytesWithVersion(batch, key, version, write.Value); err != nil {
 				return err
 			}
+			writes.add(cid, write.Key, append(version.Bytes(), write.Value...))
 		}
 	}
 	err := batch.Write()
@@ -177,6 +189,8 @@ func (statedb *StateDb) SaveContractStates(id []byte, wset []modules.ContractWri
 		return err
 	}
 
+	//单元中的合约状态在单元保存之后统一提交
+	statedb.updateStateTrie(writes)
 	return nil
 }
 
@@ -215,7 +229,8 @@ func (statedb *StateDb) GetContractStatesByPrefix(id []byte,
 	key := append(constants.CONTRACT_STATE_PREFIX, id...)
 	data := getprefix(statedb.db, append(key, []byte(prefix)...))
 	if len(data) == 0 {
-		return nil, fmt.Errorf("the contract %x state is null.", id)
+		//没有该前缀的状态，调用者用errors.IsNotFoundError区分
+		return nil, errors.ErrNotFound
 	}
 	var err error
 	result := make(map[string]*modules.ContractStateValue)
@@ -233,6 +248,37 @@ func (statedb *StateDb) GetContractStatesByPrefix(id []byte,
 	return result, err
 }
 
+/**
+按key的顺序获取合约在[startKey,endKey)区间的状态，endKey为空表示没有上界，limit大于0时最多返回limit个
+To get contract states whose key is in range [startKey,endKey) in key order
+*/
+func (statedb *StateDb) GetContractStatesByRange(id []byte, startKey, endKey string,
+	limit int) ([]*modules.ContractStateKV, error) {
+	prefix := append(constants.CONTRACT_STATE_PREFIX, id...)
+	iter := statedb.db.NewIteratorWithPrefix(prefix)
+	defer iter.Release()
+
+	result := []*modules.ContractStateKV{}
+	for ok := iter.Seek(append(prefix, []byte(startKey)...)); ok; ok = iter.Next() {
+		key := string(iter.Key()[len(prefix):])
+		if endKey != "" && key >= endKey {
+			break
+		}
+		if key == "" {
+			continue
+		}
+		state, version, err := splitValueAndVersion(common.CopyBytes(iter.Value()))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &modules.ContractStateKV{Key: key, Value: state, Version: version})
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, iter.Error()
+}
+
 /**
 获取合约某一个属性
 To get contract or contract template one field
@@ -362,6 +408,9 @@ func (statedb *StateDb) UpdateStateByContractInvoke(invoke *modules.ContractInvo
 						if mua.InitPubKey != nil {
 							mi.InitPubKey = *mua.InitPubKey
 						}
+						if mua.CommissionRate != nil {
+							mi.CommissionRate = *mua.CommissionRate
+						}
 						statedb.StoreMediatorInfo(addr, mi)
 					} else {
 						log.Warnf("RetrieveMediatorInfo error: %v", err.Error())
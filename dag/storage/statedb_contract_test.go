@@ -32,6 +32,7 @@ import (
 	"github.com/palletone/go-palletone/common/util"
 	"github.com/palletone/go-palletone/contracts/syscontract"
 	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/errors"
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
@@ -185,6 +186,48 @@ func TestStateDb_DeleteContractState(t *testing.T) {
 	result, err = statedb.GetContractStatesByPrefix(contractId, "A")
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(result))
+	_, err = statedb.GetContractStatesByPrefix(contractId, "B")
+	assert.True(t, errors.IsNotFoundError(err))
+}
+func TestStateDb_GetContractStatesByRange(t *testing.T) {
+	ldb, remove := newTestLDB()
+	defer remove()
+	mdb, _ := ptndb.NewMemDatabase()
+	for _, db := range []ptndb.Database{ldb, mdb} {
+		statedb := NewStateDb(db)
+		contractId := common.HexToAddress("0x00000000000000000000000000000000000000011C").Bytes()
+		otherId := common.HexToAddress("0x00000000000000000000000000000000000000021C").Bytes()
+		version := &modules.StateVersion{Height: &modules.ChainIndex{Index: 123}, TxIndex: 1}
+		ws := []modules.ContractWriteSet{}
+		for _, key := range []string{"b", "a", "c2", "c1", "d"} {
+			ws = append(ws, *modules.NewWriteSet(key, []byte("value_"+key)))
+		}
+		assert.Nil(t, statedb.SaveContractStates(contractId, ws, version))
+		assert.Nil(t, statedb.SaveContractStates(otherId, ws[:1], version))
+
+		keys := func(states []*modules.ContractStateKV) []string {
+			result := []string{}
+			for _, state := range states {
+				result = append(result, state.Key)
+			}
+			return result
+		}
+		states, err := statedb.GetContractStatesByRange(contractId, "", "", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "c1", "c2", "d"}, keys(states))
+		assert.Equal(t, []byte("value_c1"), states[2].Value)
+		assert.Equal(t, version.String(), states[2].Version.String())
+
+		states, err = statedb.GetContractStatesByRange(contractId, "b", "c2", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"b", "c1"}, keys(states))
+		states, err = statedb.GetContractStatesByRange(contractId, "bb", "", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"c1", "c2"}, keys(states))
+		states, err = statedb.GetContractStatesByRange(contractId, "e", "", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(states))
+	}
 }
 func newTestLDB() (*ptndb.LDBDatabase, func()) {
 	dirname, err := ioutil.TempDir(os.TempDir(), "ptndb_test_")
@@ -0,0 +1,231 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package storage
+
+import (
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/common/trie"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/errors"
+)
+
+// 合约状态的承诺分为两层：每个合约的状态是一棵trie，key为状态的key，value与数据库中保存的相同(version+value)；
+// 所有合约的root组成一棵trie，key为合约ID，它的root就是单元头中的StateRoot
+
+// 合约ID是合约地址中的hash160
+const contractIdLength = common.AddressLength - 1
+
+// 一次需要更新到trie的合约状态，value为nil表示删除
+type stateTrieWrites map[string]map[string][]byte
+
+func newStateTrieWrites() stateTrieWrites {
+	return make(stateTrieWrites)
+}
+
+func (w stateTrieWrites) add(contractId []byte, key string, value []byte) {
+	states, ok := w[string(contractId)]
+	if !ok {
+		states = make(map[string][]byte)
+		w[string(contractId)] = states
+	}
+	states[key] = value
+}
+
+func (statedb *StateDb) newStateTrieDatabase() *trie.Database {
+	return trie.NewDatabase(ptndb.NewTable(statedb.db, string(constants.CONTRACT_STATE_TRIE_PREFIX)))
+}
+
+// 当前所有合约状态的承诺
+func (statedb *StateDb) GetStateRoot() (common.Hash, error) {
+	data, err := statedb.db.Get(constants.CONTRACT_STATE_ROOT_KEY)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return new(trie.Trie).Hash(), nil
+		}
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(data), nil
+}
+
+// 在root对应的合约状态中查询某个合约的状态root
+func (statedb *StateDb) getContractStateRoot(tdb *trie.Database, root common.Hash,
+	contractId []byte) (common.Hash, error) {
+	tr, err := trie.New(root, tdb)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	data, err := tr.TryGet(contractId)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(data) == 0 {
+		return new(trie.Trie).Hash(), nil
+	}
+	return common.BytesToHash(data), nil
+}
+
+// 某个合约当前状态的承诺
+func (statedb *StateDb) GetContractStateRoot(contractId []byte) (common.Hash, error) {
+	root, err := statedb.GetStateRoot()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return statedb.getContractStateRoot(statedb.newStateTrieDatabase(), root, contractId)
+}
+
+// 生成root对应的合约状态中，合约的某个状态存在或者不存在的证明，
+// 返回合约的状态root、合约root在root中的证明以及状态在合约root中的证明
+func (statedb *StateDb) GetContractStateProof(root common.Hash, contractId []byte, field string) (
+	common.Hash, [][]byte, [][]byte, error) {
+	tdb := statedb.newStateTrieDatabase()
+	tr, err := trie.New(root, tdb)
+	if err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+	contractProof := &proofList{}
+	if err := tr.Prove(contractId, 0, contractProof); err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+	contractRoot, err := statedb.getContractStateRoot(tdb, root, contractId)
+	if err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+	ctr, err := trie.New(contractRoot, tdb)
+	if err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+	stateProof := &proofList{}
+	if err := ctr.Prove([]byte(field), 0, stateProof); err != nil {
+		return common.Hash{}, nil, nil, err
+	}
+	return contractRoot, *contractProof, *stateProof, nil
+}
+
+// 旧版本的数据库没有合约状态的承诺，根据全部的合约状态重建
+func (statedb *StateDb) InitStateRoot() error {
+	has, err := statedb.db.Has(constants.CONTRACT_STATE_ROOT_KEY)
+	if err != nil || has {
+		return err
+	}
+	writes := newStateTrieWrites()
+	preLen := len(constants.CONTRACT_STATE_PREFIX)
+	for key, value := range getprefix(statedb.db, constants.CONTRACT_STATE_PREFIX) {
+		if len(key) <= preLen+contractIdLength {
+			continue
+		}
+		contractId := []byte(key[preLen : preLen+contractIdLength])
+		writes.add(contractId, key[preLen+contractIdLength:], value)
+	}
+	log.Infof("Rebuild contract state commitment, contract count:%d", len(writes))
+	statedb.updateStateTrie(writes)
+	return statedb.CommitStateRoot()
+}
+
+// 保存和删除合约状态时记录对trie的修改
+func (statedb *StateDb) updateStateTrie(writes stateTrieWrites) {
+	statedb.rootLock.Lock()
+	defer statedb.rootLock.Unlock()
+
+	if statedb.pending == nil {
+		statedb.pending = newStateTrieWrites()
+	}
+	for contractId, states := range writes {
+		for key, value := range states {
+			statedb.pending.add([]byte(contractId), key, value)
+		}
+	}
+}
+
+// CommitStateRoot 每个单元保存之后提交一次trie，trie节点和新的root在同一个batch中写入
+func (statedb *StateDb) CommitStateRoot() error {
+	statedb.rootLock.Lock()
+	defer statedb.rootLock.Unlock()
+
+	if len(statedb.pending) == 0 {
+		return nil
+	}
+	root, err := statedb.GetStateRoot()
+	if err != nil {
+		return err
+	}
+	tdb := statedb.newStateTrieDatabase()
+	tr, err := trie.New(root, tdb)
+	if err != nil {
+		return err
+	}
+	emptyRoot := new(trie.Trie).Hash()
+	contractRoots := make([]common.Hash, 0, len(statedb.pending))
+	for contractId, states := range statedb.pending {
+		contractRoot, err := statedb.getContractStateRoot(tdb, root, []byte(contractId))
+		if err != nil {
+			return err
+		}
+		ctr, err := trie.New(contractRoot, tdb)
+		if err != nil {
+			return err
+		}
+		for key, value := range states {
+			if value == nil {
+				err = ctr.TryDelete([]byte(key))
+			} else {
+				err = ctr.TryUpdate([]byte(key), value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		contractRoot, err = ctr.Commit(nil)
+		if err != nil {
+			return err
+		}
+		if contractRoot == emptyRoot {
+			err = tr.TryDelete([]byte(contractId))
+		} else {
+			contractRoots = append(contractRoots, contractRoot)
+			err = tr.TryUpdate([]byte(contractId), contractRoot.Bytes())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	root, err = tr.Commit(nil)
+	if err != nil {
+		return err
+	}
+	//合约的root只是叶子的值，需要单独写入
+	batch := statedb.db.NewBatch()
+	trieBatch := ptndb.WrapTableBatch(batch, string(constants.CONTRACT_STATE_TRIE_PREFIX))
+	for _, contractRoot := range append(contractRoots, root) {
+		if err := tdb.CommitTo(contractRoot, trieBatch); err != nil {
+			return err
+		}
+	}
+	if err := batch.Put(constants.CONTRACT_STATE_ROOT_KEY, root.Bytes()); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	statedb.pending = nil
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateRoot(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	statedb := NewStateDb(db)
+	emptyRoot, err := statedb.GetStateRoot()
+	assert.Nil(t, err)
+
+	id1 := common.HexToAddress("P1NzevLMVCFJKWr4KAcHxyyh9xXaVU8yv3N").Bytes()
+	id2 := make([]byte, contractIdLength)
+	id2[0] = 2
+	version := &modules.StateVersion{Height: &modules.ChainIndex{Index: 10}, TxIndex: 1}
+	ws := []modules.ContractWriteSet{
+		*modules.NewWriteSet("a", []byte("1")),
+		*modules.NewWriteSet("b", []byte("2")),
+		{ContractId: id2, Key: "c", Value: []byte("3")},
+	}
+	assert.Nil(t, statedb.SaveContractStates(id1, ws, version))
+	//单元中的状态修改在提交之后才进入承诺
+	root, _ := statedb.GetStateRoot()
+	assert.Equal(t, emptyRoot, root)
+	assert.Nil(t, statedb.CommitStateRoot())
+	root, _ = statedb.GetStateRoot()
+	assert.NotEqual(t, emptyRoot, root)
+	root1, _ := statedb.GetContractStateRoot(id1)
+	root2, _ := statedb.GetContractStateRoot(id2)
+	assert.NotEqual(t, emptyRoot, root1)
+	assert.NotEqual(t, root1, root2)
+
+	//逐个保存得到相同的承诺
+	db2, _ := ptndb.NewMemDatabase()
+	statedb2 := NewStateDb(db2)
+	for i := range ws {
+		assert.Nil(t, statedb2.SaveContractState(id1, &ws[i], version))
+	}
+	root2, _ = statedb2.GetStateRoot()
+	assert.Equal(t, root, root2)
+
+	//根据数据库中的状态重建
+	db3, _ := ptndb.NewMemDatabase()
+	for key, value := range getprefix(db, constants.CONTRACT_STATE_PREFIX) {
+		db3.Put([]byte(key), value)
+	}
+	statedb3 := NewStateDb(db3)
+	assert.Nil(t, statedb3.InitStateRoot())
+	root3, _ := statedb3.GetStateRoot()
+	assert.Equal(t, root, root3)
+
+	//删除合约的全部状态后，合约从承诺中移除
+	del := modules.ContractWriteSet{ContractId: id2, Key: "c", IsDelete: true}
+	assert.Nil(t, statedb.SaveContractState(id1, &del, version))
+	root2, _ = statedb.GetContractStateRoot(id2)
+	assert.Equal(t, emptyRoot, root2)
+
+	//历史的承诺仍然可以生成证明
+	contractRoot, contractProof, stateProof, err := statedb.GetContractStateProof(root, id2, "c")
+	assert.Nil(t, err)
+	assert.NotEqual(t, emptyRoot, contractRoot)
+	assert.NotEmpty(t, contractProof)
+	assert.NotEmpty(t, stateProof)
+}
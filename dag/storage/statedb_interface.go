@@ -35,7 +35,13 @@ type IStateDb interface {
 	SaveContractStates(id []byte, wset []modules.ContractWriteSet, version *modules.StateVersion) error
 	GetContractState(id []byte, field string) ([]byte, *modules.StateVersion, error)
 	GetContractStatesByPrefix(id []byte, prefix string) (map[string]*modules.ContractStateValue, error)
+	GetContractStatesByRange(id []byte, startKey, endKey string, limit int) ([]*modules.ContractStateKV, error)
 	GetContractStatesById(id []byte) (map[string]*modules.ContractStateValue, error)
+	GetStateRoot() (common.Hash, error)
+	GetContractStateRoot(contractId []byte) (common.Hash, error)
+	GetContractStateProof(root common.Hash, contractId []byte, field string) (common.Hash, [][]byte, [][]byte, error)
+	InitStateRoot() error
+	CommitStateRoot() error
 
 	UpdateStateByContractInvoke(invoke *modules.ContractInvokeRequestPayload) error
 
@@ -73,6 +79,11 @@ type IStateDb interface {
 	//获得一个合约的陪审团列表
 	GetContractJury(contractId []byte) (*modules.ElectionNode, error)
 	SaveContractJury(contractId []byte, jury modules.ElectionNode, version *modules.StateVersion) error
+	GetJurorStats(address common.Address) (*modules.JurorStats, error)
+	SaveJurorStats(stats *modules.JurorStats) error
+	GetAllJurorStats() ([]*modules.JurorStats, error)
+	IsJurorEvidenceExist(hash common.Hash) bool
+	SaveJurorEvidence(evidence *modules.JurorEvidencePayload) error
 	// world state chainIndex
 
 	StoreMediator(med *core.Mediator) error
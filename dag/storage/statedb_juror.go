@@ -0,0 +1,79 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package storage
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/dag/constants"
+	"github.com/palletone/go-palletone/dag/errors"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+func jurorStatsKey(address common.Address) []byte {
+	return append(constants.JUROR_STATS_PREFIX, address.Bytes()...)
+}
+
+func jurorEvidenceKey(hash common.Hash) []byte {
+	return append(constants.JUROR_EVIDENCE_PREFIX, hash.Bytes()...)
+}
+
+//获得陪审员的统计，没有记录时返回空的统计
+func (statedb *StateDb) GetJurorStats(address common.Address) (*modules.JurorStats, error) {
+	stats := modules.NewJurorStats(address)
+	err := RetrieveFromRlpBytes(statedb.db, jurorStatsKey(address), stats)
+	if errors.IsNotFoundError(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (statedb *StateDb) SaveJurorStats(stats *modules.JurorStats) error {
+	return StoreToRlpBytes(statedb.db, jurorStatsKey(stats.Address), stats)
+}
+
+//获得所有陪审员的统计，按地址排序
+func (statedb *StateDb) GetAllJurorStats() ([]*modules.JurorStats, error) {
+	result := make([]*modules.JurorStats, 0)
+	iter := statedb.db.NewIteratorWithPrefix(constants.JUROR_STATS_PREFIX)
+	for iter.Next() {
+		stats := &modules.JurorStats{}
+		if err := rlp.DecodeBytes(iter.Value(), stats); err != nil {
+			return nil, err
+		}
+		result = append(result, stats)
+	}
+
+	return result, nil
+}
+
+func (statedb *StateDb) IsJurorEvidenceExist(hash common.Hash) bool {
+	has, _ := statedb.db.Has(jurorEvidenceKey(hash))
+	return has
+}
+
+func (statedb *StateDb) SaveJurorEvidence(evidence *modules.JurorEvidencePayload) error {
+	return StoreToRlpBytes(statedb.db, jurorEvidenceKey(evidence.Hash()), evidence)
+}
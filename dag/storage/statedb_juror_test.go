@@ -0,0 +1,57 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDb_JurorStats(t *testing.T) {
+	db := MockStateMemDb()
+	addr, _ := common.StringToAddress("P1JJkSss3dEPCBJD6V759MgkB4vtj2EiUFX")
+
+	//没有记录时返回空的统计
+	stats, err := db.GetJurorStats(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, modules.NewJurorStats(addr), stats)
+
+	stats.Participated = 3
+	stats.Timeouts = 1
+	assert.Nil(t, db.SaveJurorStats(stats))
+	dbStats, err := db.GetJurorStats(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, stats, dbStats)
+
+	stats.Excluded = true
+	assert.Nil(t, db.SaveJurorStats(stats))
+	all, err := db.GetAllJurorStats()
+	assert.Nil(t, err)
+	assert.Equal(t, []*modules.JurorStats{stats}, all)
+
+	evidence := &modules.JurorEvidencePayload{Result: []byte("result")}
+	assert.False(t, db.IsJurorEvidenceExist(evidence.Hash()))
+	assert.Nil(t, db.SaveJurorEvidence(evidence))
+	assert.True(t, db.IsJurorEvidenceExist(evidence.Hash()))
+}
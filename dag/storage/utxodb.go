@@ -21,10 +21,13 @@
 package storage
 
 import (
+	"sync"
+
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/common/trie"
 	"github.com/palletone/go-palletone/dag/constants"
 	"github.com/palletone/go-palletone/dag/errors"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -34,6 +37,9 @@ import (
 type UtxoDb struct {
 	db ptndb.Database
 	tokenEngine tokenengine.ITokenEngine
+	rootLock    sync.Mutex
+	// 还没有提交到trie的UTXO修改，值为nil表示删除
+	pending map[modules.OutPoint]*modules.Utxo
 }
 
 func NewUtxoDb(db ptndb.Database,tokenEngine tokenengine.ITokenEngine) *UtxoDb {
@@ -51,7 +57,15 @@ type IUtxoDb interface {
 	DeleteUtxo(outpoint *modules.OutPoint, spentTxId common.Hash, spentTime uint64) error
 	IsUtxoSpent(outpoint *modules.OutPoint) (bool, error)
 	GetStxoEntry(outpoint *modules.OutPoint) (*modules.Stxo, error)
+	GetAddrSpentOutpoints(addr common.Address) ([]modules.OutPoint, error)
+	GetAddrStxos(addr common.Address, asset *modules.Asset) (map[modules.OutPoint]*modules.Stxo, error)
+	SaveAddrSpentOutpoint(address common.Address, outpoint *modules.OutPoint) error
 	ClearUtxo() error
+
+	GetUtxoRoot() (common.Hash, error)
+	GetUtxoProof(root common.Hash, outpoint *modules.OutPoint) ([][]byte, error)
+	InitUtxoRoot() error
+	CommitUtxoRoot() error
 }
 
 // ###################### UTXO index for Address ######################
@@ -88,6 +102,27 @@ func (db *UtxoDb) GetAddrOutpoints(address common.Address) ([]modules.OutPoint,
 	return outpoints, nil
 }
 
+
+// ###################### STXO index for Address ######################
+// key: spent_outpoint_prefix + addr + outpoint's Bytes
+//按地址索引已花费的UTXO，用于查询某个地址在历史单元时的余额
+func (utxodb *UtxoDb) SaveAddrSpentOutpoint(address common.Address, outpoint *modules.OutPoint) error {
+	key := append(constants.ADDR_SPENT_OUTPOINT_PREFIX, address.Bytes()...)
+	key = append(key, outpoint.Bytes()...)
+	return StoreToRlpBytes(utxodb.db, key, outpoint)
+}
+func (db *UtxoDb) GetAddrSpentOutpoints(address common.Address) ([]modules.OutPoint, error) {
+	data := getprefix(db.db, append(constants.ADDR_SPENT_OUTPOINT_PREFIX, address.Bytes()...))
+	outpoints := make([]modules.OutPoint, 0)
+	for _, b := range data {
+		out := new(modules.OutPoint)
+		if err := rlp.DecodeBytes(b, out); err == nil {
+			outpoints = append(outpoints, *out)
+		}
+	}
+	return outpoints, nil
+}
+
 // ###################### UTXO Entity ######################
 func (utxodb *UtxoDb) SaveUtxoEntity(outpoint *modules.OutPoint, utxo *modules.Utxo) error {
 	key := outpoint.ToKey()
@@ -96,6 +131,7 @@ func (utxodb *UtxoDb) SaveUtxoEntity(outpoint *modules.OutPoint, utxo *modules.U
 	if err != nil {
 		return err
 	}
+	utxodb.updateUtxoTrie(map[modules.OutPoint]*modules.Utxo{*outpoint: utxo}, nil)
 
 	return utxodb.saveUtxoOutpoint(address, outpoint)
 }
@@ -127,7 +163,11 @@ func (utxodb *UtxoDb) SaveUtxoView(view map[modules.OutPoint]*modules.Utxo) erro
 		}
 	}
 
-	return batch.Write()
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	utxodb.updateUtxoTrie(view, nil)
+	return utxodb.CommitUtxoRoot()
 }
 
 // Remove the utxo
@@ -145,10 +185,11 @@ func (utxodb *UtxoDb) DeleteUtxo(outpoint *modules.OutPoint, spentTxId common.Ha
 	}
 	//log.Debugf("Try delete utxo by key:%s, move to spent table", outpoint.String())
 	utxodb.SaveUtxoSpent(outpoint, utxo, spentTxId, spentTime)
+	utxodb.updateUtxoTrie(nil, []*modules.OutPoint{outpoint})
 
 	address, _ := utxodb.tokenEngine.GetAddressFromScript(utxo.PkScript[:])
 	utxodb.deleteUtxoOutpoint(address, outpoint)
-	return nil
+	return utxodb.SaveAddrSpentOutpoint(address, outpoint)
 }
 
 // ###################### SAVE IMPL END ######################
@@ -219,6 +260,27 @@ func (db *UtxoDb) GetAddrUtxos(addr common.Address, asset *modules.Asset) (
 	}
 	return allutxos, nil
 }
+
+//GetAddrStxos if asset is nil, query all Asset spent from address
+func (db *UtxoDb) GetAddrStxos(addr common.Address, asset *modules.Asset) (
+	map[modules.OutPoint]*modules.Stxo, error) {
+	allstxos := make(map[modules.OutPoint]*modules.Stxo)
+	outpoints, err := db.GetAddrSpentOutpoints(addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, out := range outpoints {
+		item := out
+		stxo, err := db.GetStxoEntry(&item)
+		if err != nil {
+			return nil, err
+		}
+		if asset == nil || asset.IsSimilar(stxo.Asset) {
+			allstxos[out] = stxo
+		}
+	}
+	return allstxos, nil
+}
 func (db *UtxoDb) GetAllUtxos() (map[modules.OutPoint]*modules.Utxo, error) {
 	view := make(map[modules.OutPoint]*modules.Utxo)
 
@@ -237,6 +299,9 @@ func (db *UtxoDb) GetAllUtxos() (map[modules.OutPoint]*modules.Utxo, error) {
 	return view, err
 }
 func (db *UtxoDb) ClearUtxo() error {
+	db.rootLock.Lock()
+	db.pending = nil
+	db.rootLock.Unlock()
 	err := clearByPrefix(db.db, constants.UTXO_PREFIX)
 	if err != nil {
 		return err
@@ -249,7 +314,7 @@ func (db *UtxoDb) ClearUtxo() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return clearByPrefix(db.db, constants.UTXO_TRIE_PREFIX)
 }
 func clearByPrefix(db ptndb.Database, prefix []byte) error {
 	iter := db.NewIteratorWithPrefix(prefix)
@@ -264,3 +329,125 @@ func clearByPrefix(db ptndb.Database, prefix []byte) error {
 }
 
 // ###################### GET IMPL END ######################
+
+// ###################### UTXO Commitment ######################
+// UTXO集合的承诺是一棵Merkle Patricia Trie，key为outpoint，value为utxo的rlp编码，
+// trie节点按hash存储且不删除，所以历史的root仍然可以生成证明
+
+// 当前UTXO集合的承诺
+func (utxodb *UtxoDb) GetUtxoRoot() (common.Hash, error) {
+	data, err := utxodb.db.Get(constants.UTXO_ROOT_KEY)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return new(trie.Trie).Hash(), nil
+		}
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(data), nil
+}
+
+// 生成outpoint在root对应的UTXO集合中存在或者不存在的证明
+func (utxodb *UtxoDb) GetUtxoProof(root common.Hash, outpoint *modules.OutPoint) ([][]byte, error) {
+	tr, err := trie.New(root, utxodb.newTrieDatabase())
+	if err != nil {
+		return nil, err
+	}
+	proof := &proofList{}
+	if err := tr.Prove(outpoint.Bytes(), 0, proof); err != nil {
+		return nil, err
+	}
+	return *proof, nil
+}
+
+// 旧版本的数据库没有UTXO集合的承诺，根据全部的UTXO重建
+func (utxodb *UtxoDb) InitUtxoRoot() error {
+	has, err := utxodb.db.Has(constants.UTXO_ROOT_KEY)
+	if err != nil || has {
+		return err
+	}
+	utxos, err := utxodb.GetAllUtxos()
+	if err != nil {
+		return err
+	}
+	log.Infof("Rebuild utxo commitment, utxo count:%d", len(utxos))
+	utxodb.updateUtxoTrie(utxos, nil)
+	return utxodb.CommitUtxoRoot()
+}
+
+func (utxodb *UtxoDb) newTrieDatabase() *trie.Database {
+	return trie.NewDatabase(ptndb.NewTable(utxodb.db, string(constants.UTXO_TRIE_PREFIX)))
+}
+
+// 保存和删除UTXO时记录对trie的修改，view中值为nil的项没有改变
+func (utxodb *UtxoDb) updateUtxoTrie(view map[modules.OutPoint]*modules.Utxo, deleted []*modules.OutPoint) {
+	utxodb.rootLock.Lock()
+	defer utxodb.rootLock.Unlock()
+
+	if utxodb.pending == nil {
+		utxodb.pending = make(map[modules.OutPoint]*modules.Utxo)
+	}
+	for outpoint, utxo := range view {
+		if utxo != nil {
+			utxodb.pending[outpoint] = utxo
+		}
+	}
+	for _, outpoint := range deleted {
+		utxodb.pending[*outpoint] = nil
+	}
+}
+
+// CommitUtxoRoot 每个单元保存之后提交一次trie，trie节点和新的root在同一个batch中写入
+func (utxodb *UtxoDb) CommitUtxoRoot() error {
+	utxodb.rootLock.Lock()
+	defer utxodb.rootLock.Unlock()
+
+	if len(utxodb.pending) == 0 {
+		return nil
+	}
+	root, err := utxodb.GetUtxoRoot()
+	if err != nil {
+		return err
+	}
+	tdb := utxodb.newTrieDatabase()
+	tr, err := trie.New(root, tdb)
+	if err != nil {
+		return err
+	}
+	for outpoint, utxo := range utxodb.pending {
+		if utxo == nil {
+			err = tr.TryDelete(outpoint.Bytes())
+		} else {
+			var data []byte
+			if data, err = rlp.EncodeToBytes(utxo); err == nil {
+				err = tr.TryUpdate(outpoint.Bytes(), data)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	root, err = tr.Commit(nil)
+	if err != nil {
+		return err
+	}
+	batch := utxodb.db.NewBatch()
+	if err := tdb.CommitTo(root, ptndb.WrapTableBatch(batch, string(constants.UTXO_TRIE_PREFIX))); err != nil {
+		return err
+	}
+	if err := batch.Put(constants.UTXO_ROOT_KEY, root.Bytes()); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	utxodb.pending = nil
+	return nil
+}
+
+// 按顺序收集证明中的trie节点
+type proofList [][]byte
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
@@ -63,3 +63,110 @@ func TestGetUtxos(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, utxo.Bytes(), queryUtxo.Bytes())
 }
+
+func newTestUtxo(amount uint64) *modules.Utxo {
+	return &modules.Utxo{Amount: amount, Asset: &modules.Asset{AssetId: modules.PTNCOIN}}
+}
+
+func TestUtxoRoot(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	utxodb := NewUtxoDb(db, tokenengine.Instance)
+	emptyRoot, err := utxodb.GetUtxoRoot()
+	assert.Nil(t, err)
+
+	view := make(map[modules.OutPoint]*modules.Utxo)
+	for i := 0; i < 10; i++ {
+		view[*modules.NewOutPoint(common.BytesToHash([]byte{byte(i)}), 0, uint32(i))] = newTestUtxo(uint64(i + 1))
+	}
+	assert.Nil(t, utxodb.SaveUtxoView(view))
+	root, err := utxodb.GetUtxoRoot()
+	assert.Nil(t, err)
+	assert.NotEqual(t, emptyRoot, root)
+
+	//承诺与保存的顺序无关
+	db2, _ := ptndb.NewMemDatabase()
+	utxodb2 := NewUtxoDb(db2, tokenengine.Instance)
+	for outpoint, utxo := range view {
+		op := outpoint
+		assert.Nil(t, utxodb2.SaveUtxoEntity(&op, utxo))
+	}
+	//提交之前承诺不变
+	root2, _ := utxodb2.GetUtxoRoot()
+	assert.Equal(t, emptyRoot, root2)
+	assert.Nil(t, utxodb2.CommitUtxoRoot())
+	root2, _ = utxodb2.GetUtxoRoot()
+	assert.Equal(t, root, root2)
+
+	//删除后的承诺
+	spent := modules.NewOutPoint(common.BytesToHash([]byte{3}), 0, 3)
+	assert.Nil(t, utxodb.DeleteUtxo(spent, common.Hash{}, 0))
+	assert.Nil(t, utxodb.CommitUtxoRoot())
+	rootAfterSpent, _ := utxodb.GetUtxoRoot()
+	assert.NotEqual(t, root, rootAfterSpent)
+
+	//同一个单元中创建又花费的UTXO不影响承诺
+	created := modules.NewOutPoint(common.BytesToHash([]byte{20}), 0, 0)
+	assert.Nil(t, utxodb.SaveUtxoEntity(created, newTestUtxo(20)))
+	assert.Nil(t, utxodb.DeleteUtxo(created, common.Hash{}, 0))
+	assert.Nil(t, utxodb.CommitUtxoRoot())
+	root, _ = utxodb.GetUtxoRoot()
+	assert.Equal(t, rootAfterSpent, root)
+
+	//旧的数据库根据全部的UTXO重建承诺
+	db3, _ := ptndb.NewMemDatabase()
+	utxodb3 := NewUtxoDb(db3, tokenengine.Instance)
+	all, _ := utxodb.GetAllUtxos()
+	for outpoint, utxo := range all {
+		assert.Nil(t, StoreToRlpBytes(db3, outpoint.ToKey(), utxo))
+	}
+	assert.Nil(t, utxodb3.InitUtxoRoot())
+	root3, _ := utxodb3.GetUtxoRoot()
+	assert.Equal(t, rootAfterSpent, root3)
+
+	assert.Nil(t, utxodb.ClearUtxo())
+	root, _ = utxodb.GetUtxoRoot()
+	assert.Equal(t, emptyRoot, root)
+}
+
+func TestGetUtxoProof(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	utxodb := NewUtxoDb(db, tokenengine.Instance)
+	outpoint := modules.NewOutPoint(common.BytesToHash([]byte{1}), 0, 0)
+	assert.Nil(t, utxodb.SaveUtxoEntity(outpoint, newTestUtxo(100)))
+	assert.Nil(t, utxodb.CommitUtxoRoot())
+	oldRoot, _ := utxodb.GetUtxoRoot()
+	assert.Nil(t, utxodb.SaveUtxoEntity(modules.NewOutPoint(common.BytesToHash([]byte{2}), 0, 0),
+		newTestUtxo(200)))
+	assert.Nil(t, utxodb.CommitUtxoRoot())
+
+	proof, err := utxodb.GetUtxoProof(oldRoot, outpoint)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, proof)
+	//历史的承诺仍然可以生成证明
+	absent := modules.NewOutPoint(common.BytesToHash([]byte{2}), 0, 0)
+	proof, err = utxodb.GetUtxoProof(oldRoot, absent)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, proof)
+}
+
+func TestGetAddrStxos(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	utxodb := NewUtxoDb(db, tokenengine.Instance)
+	addr, _ := common.StringToAddress("P1NzevLMVCFJKWr4KAcHxyyh9xXaVU8yv3N")
+	outpoint := modules.NewOutPoint(common.BytesToHash([]byte("tx1")), 0, 0)
+	utxo := newTestUtxo(100)
+	utxo.PkScript = tokenengine.Instance.GenerateLockScript(addr)
+	utxo.Timestamp = 10
+	assert.Nil(t, utxodb.SaveUtxoEntity(outpoint, utxo))
+
+	assert.Nil(t, utxodb.DeleteUtxo(outpoint, common.BytesToHash([]byte("tx2")), 20))
+	utxos, err := utxodb.GetAddrUtxos(addr, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(utxos))
+	stxos, err := utxodb.GetAddrStxos(addr, modules.NewPTNAsset())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stxos))
+	assert.Equal(t, uint64(100), stxos[*outpoint].Amount)
+	assert.Equal(t, uint64(10), stxos[*outpoint].Timestamp)
+	assert.Equal(t, uint64(20), stxos[*outpoint].SpentTime)
+}
@@ -0,0 +1,29 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package txspool
+
+import "github.com/palletone/go-palletone/statistics/metrics"
+
+var (
+	pendingGauge = metrics.NewRegisteredGauge("txpool/pending", nil) // 可打包的交易数
+	queuedGauge  = metrics.NewRegisteredGauge("txpool/queued", nil)  // 暂不可打包的交易数
+	orphanGauge  = metrics.NewRegisteredGauge("txpool/orphans", nil) // 孤儿交易数
+	allGauge     = metrics.NewRegisteredGauge("txpool/all", nil)     // 交易池中的交易总数
+)
@@ -100,6 +100,7 @@ type dags interface {
 	GetMediators() map[common.Address]bool
 	GetChainParameters() *core.ChainParameters
 	GetNewestUnitTimestamp(token modules.AssetId) (int64, error)
+	GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error)
 	GetScheduledMediator(slotNum uint32) common.Address
 	GetSlotAtTime(when time.Time) uint32
 	GetMediator(add common.Address) *core.Mediator
@@ -296,7 +297,11 @@ func (pool *TxPool) loop() {
 		select {
 		// Handle stats reporting ticks
 		case <-report.C:
-			pending, queued, _ := pool.stats()
+			pending, queued, orphans := pool.stats()
+			pendingGauge.Update(int64(pending))
+			queuedGauge.Update(int64(queued))
+			orphanGauge.Update(int64(orphans))
+			allGauge.Update(int64(pool.Count()))
 
 			if pending != prevPending || queued != prevQueued {
 				log.Debug("Transaction pool status report", "executable", pending, "queued", queued)
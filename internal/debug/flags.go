@@ -27,6 +27,7 @@ import (
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/statistics/metrics"
 	"github.com/palletone/go-palletone/statistics/metrics/exp"
+	"github.com/palletone/go-palletone/statistics/metrics/prometheus"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -66,6 +67,15 @@ var (
 		Usage: "pprof HTTP server listening interface",
 		Value: "127.0.0.1",
 	}
+	metricsAddrFlag = cli.StringFlag{
+		Name:  "metricsaddr",
+		Usage: "Enable the stand-alone Prometheus metrics HTTP server listening interface (requires --metrics)",
+	}
+	metricsPortFlag = cli.IntFlag{
+		Name:  "metricsport",
+		Usage: "Prometheus metrics HTTP server listening port",
+		Value: 6061,
+	}
 	memprofilerateFlag = cli.IntFlag{
 		Name:  "memprofilerate",
 		Usage: "Turn on memory profiling with the given rate",
@@ -88,7 +98,7 @@ var (
 // Flags holds all command-line flags required for debugging.
 var Flags = []cli.Flag{
 	verbosityFlag, vmoduleFlag, backtraceAtFlag, debugFlag,
-	pprofFlag, pprofAddrFlag, pprofPortFlag,
+	pprofFlag, pprofAddrFlag, pprofPortFlag, metricsAddrFlag, metricsPortFlag,
 	memprofilerateFlag, blockprofilerateFlag, cpuprofileFlag, traceFlag,
 }
 
@@ -136,6 +146,12 @@ func Setup(ctx *cli.Context) error {
 		address := fmt.Sprintf("%s:%d", ctx.GlobalString(pprofAddrFlag.Name), ctx.GlobalInt(pprofPortFlag.Name))
 		StartPProf(address)
 	}
+
+	// stand-alone metrics server
+	if metrics.Enabled && ctx.GlobalString(metricsAddrFlag.Name) != "" {
+		address := fmt.Sprintf("%s:%d", ctx.GlobalString(metricsAddrFlag.Name), ctx.GlobalInt(metricsPortFlag.Name))
+		prometheus.StartServer(address, metrics.DefaultRegistry)
+	}
 	return nil
 }
 
@@ -143,6 +159,7 @@ func StartPProf(address string) {
 	// Hook go-metrics into expvar on any /debug/metrics request, load all vars
 	// from the registry into expvar, and execute regular expvar handler.
 	exp.Exp(metrics.DefaultRegistry)
+	http.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
 	http.Handle("/memsize/", http.StripPrefix("/memsize", &Memsize))
 	log.Info("Starting pprof server", "addr", fmt.Sprintf("http://%s/debug/pprof", address))
 	go func() {
@@ -32,6 +32,7 @@ import (
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/state"
 	"github.com/palletone/go-palletone/dag/txspool"
+	"github.com/palletone/go-palletone/light/spv"
 	"github.com/palletone/go-palletone/ptn/downloader"
 	"github.com/palletone/go-palletone/ptnjson"
 	"github.com/palletone/go-palletone/ptnjson/statistics"
@@ -199,6 +200,7 @@ type Backend interface {
 	ProofTransactionByHash(txhash string) (string, error)
 	ProofTransactionByRlptx(rlptx [][]byte) (string, error)
 	SyncUTXOByAddr(addr string) string
+	GetUtxoProof(outpoint *modules.OutPoint) (*spv.UtxoProof, error)
 	StartCorsSync() (string, error)
 }
 
@@ -71,8 +71,15 @@ func NewPrivateContractAPI(b Backend) *PrivateContractAPI {
 //install
 func (s *PrivateContractAPI) Ccinstall(ctx context.Context,
 	ccname, ccpath, ccversion, ccdescription, ccabi, cclanguage string) (hexutil.Bytes, error) {
-	log.Info("CcInstall:", "ccname", ccname, "ccpath", ccpath, "ccversion", ccversion)
-	templateId, err := s.b.ContractInstall(ccname, ccpath, ccversion, ccdescription, ccabi, cclanguage)
+	log.Info("CcInstall:", "ccname", ccname, "ccpath", ccpath, "ccversion", ccversion, "cclanguage", cclanguage)
+	if strings.ToLower(cclanguage) == "go" {
+		cclanguage = "golang"
+	}
+	language := strings.ToUpper(cclanguage)
+	if _, ok := peer.ChaincodeSpec_Type_value[language]; !ok {
+		return nil, errors.New(cclanguage + " language is not supported")
+	}
+	templateId, err := s.b.ContractInstall(ccname, ccpath, ccversion, ccdescription, ccabi, language)
 	return hexutil.Bytes(templateId), err
 }
 
@@ -0,0 +1,60 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ptnapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/light/spv"
+	"github.com/palletone/go-palletone/ptnjson"
+)
+
+// 获得合约的某个状态存在或者不存在于单元头承诺的合约状态中的证明
+func (s *PublicContractAPI) GetStateProof(ctx context.Context, contractAddr string, key string) (
+	*spv.ContractStateProof, error) {
+	addr, err := common.StringToAddress(contractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %v", contractAddr)
+	}
+	contractId := addr.Bytes()
+	header, contractRoot, contractProof, stateProof, err := s.b.Dag().GetContractStateProof(contractId, key)
+	if err != nil {
+		return nil, err
+	}
+	return spv.NewContractStateProof(header, contractId, key, contractRoot, contractProof, stateProof), nil
+}
+
+// 查询合约的某个状态在单元高度[from, to]之间的修改历史，to为0表示到最新的单元，需要节点开启ContractStateHistory
+func (s *PublicContractAPI) GetStateHistory(ctx context.Context, contractAddr string, key string,
+	from uint64, to uint64) ([]*ptnjson.ContractStateHistoryJson, error) {
+	addr, err := common.StringToAddress(contractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %v", contractAddr)
+	}
+	if to > 0 && from > to {
+		return nil, fmt.Errorf("invalid unit index range: [%d, %d]", from, to)
+	}
+	histories, err := s.b.Dag().GetContractStateHistory(addr.Bytes(), key, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ptnjson.ContractStateHistoryJson, 0, len(histories))
+	for _, history := range histories {
+		result = append(result, ptnjson.ConvertContractStateHistory2Json(history))
+	}
+	return result, nil
+}
@@ -23,13 +23,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/contracts/syscontract"
 	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/constants"
 	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/errors"
 	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/light/spv"
 	"github.com/palletone/go-palletone/ptnjson"
 	"github.com/shopspring/decimal"
 )
@@ -185,6 +191,78 @@ func (a *PublicMediatorAPI) GetInfo(addStr string) (*modules.MediatorInfo, error
 	return a.Dag().GetMediatorInfo(mediator), nil
 }
 
+func (a *PublicMediatorAPI) GetDelegations(medAddStr string) ([]*modules.MediatorDelegationJson, error) {
+	mediator, err := common.StringToAddress(medAddStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", medAddStr)
+	}
+
+	return getDelegations(a.Backend, modules.MediatorDelegationKeyPrefix(mediator.String()), "")
+}
+
+func (a *PublicMediatorAPI) GetDelegationsByAddr(addStr string) ([]*modules.MediatorDelegationJson, error) {
+	addr, err := common.StringToAddress(addStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", addStr)
+	}
+
+	return getDelegations(a.Backend, string(constants.MEDIATOR_DELEGATION_PREFIX), addr.String())
+}
+
+func getDelegations(b Backend, prefix, delegator string) ([]*modules.MediatorDelegationJson, error) {
+	result := make([]*modules.MediatorDelegationJson, 0)
+	states, err := b.Dag().GetContractStatesByPrefix(syscontract.DepositContractAddress.Bytes(), prefix)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			// 没有委托记录
+			return result, nil
+		}
+		return nil, err
+	}
+
+	delegations, err := modules.ParseMediatorDelegations(states)
+	if err != nil {
+		return nil, err
+	}
+
+	gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+	for _, d := range delegations {
+		if delegator == "" || d.Delegator == delegator {
+			result = append(result, d.ToJson(gasToken))
+		}
+	}
+
+	return result, nil
+}
+
+// 查询某个地址已累计但尚未通过Coinbase发放的奖励，包括出块奖励、手续费以及委托分享的奖励
+func (a *PublicMediatorAPI) GetAccruedReward(addStr string) (map[string]decimal.Decimal, error) {
+	addr, err := common.StringToAddress(addStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", addStr)
+	}
+
+	result := make(map[string]decimal.Decimal)
+	data, _, err := a.Dag().GetContractState(syscontract.CoinbaseContractAddress.Bytes(),
+		constants.RewardAddressPrefix+addr.String())
+	if err != nil {
+		// 没有奖励记录
+		return result, nil
+	}
+
+	income := []modules.AmountAsset{}
+	err = rlp.DecodeBytes(data, &income)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, aa := range income {
+		result[aa.Asset.String()] = aa.Asset.DisplayAmount(aa.Amount)
+	}
+
+	return result, nil
+}
+
 const DefaultResult = "Transaction executed locally, but may not be confirmed by the network yet!"
 
 type PrivateMediatorAPI struct {
@@ -413,6 +491,99 @@ func (a *PrivateMediatorAPI) Vote(voterStr string, mediatorStrs []string) (*TxEx
 	return res, nil
 }
 
+func (a *PrivateMediatorAPI) Delegate(from, medAddStr string, amount decimal.Decimal) (*TxExecuteResult, error) {
+	// 参数检查
+	fromAdd, err := common.StringToAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", from)
+	}
+
+	medAdd, err := common.StringToAddress(medAddStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", medAddStr)
+	}
+
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("the amount of the delegation must be greater than 0")
+	}
+
+	// 判断本节点是否同步完成，数据是否最新
+	if !a.Dag().IsSynced() {
+		return nil, fmt.Errorf("this node is not synced, and can't delegate now")
+	}
+
+	// 判断是否是mediator
+	if !a.Dag().IsMediator(medAdd) {
+		return nil, fmt.Errorf("%v is not mediator", medAddStr)
+	}
+
+	// 调用系统合约
+	cArgs := [][]byte{[]byte(modules.DelegateToMediator), []byte(medAdd.String())}
+	fee := a.Dag().GetChainParameters().TransferPtnBaseFee
+	reqId, err := a.ContractInvokeReqTx(fromAdd, syscontract.DepositContractAddress, ptnjson.Ptn2Dao(amount),
+		fee, nil, syscontract.DepositContractAddress, cArgs, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回执行结果
+	res := &TxExecuteResult{}
+	res.TxContent = fmt.Sprintf("Account(%v) delegate %vPTN to mediator(%v)", from, amount, medAddStr)
+	res.TxFee = fmt.Sprintf("%vdao", fee)
+	res.Warning = DefaultResult
+	res.Tip = "Your ReqId is: " + hex.EncodeToString(reqId[:]) +
+		" , You can get the transaction hash with dag.getTxByReqId()"
+
+	return res, nil
+}
+
+// amount 为 all 时撤回全部委托
+func (a *PrivateMediatorAPI) Undelegate(from, medAddStr, amount string) (*TxExecuteResult, error) {
+	// 参数检查
+	fromAdd, err := common.StringToAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", from)
+	}
+
+	medAdd, err := common.StringToAddress(medAddStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", medAddStr)
+	}
+
+	amountStr := "all"
+	if strings.ToLower(amount) != "all" {
+		amt, err := decimal.NewFromString(amount)
+		if err != nil || !amt.IsPositive() {
+			return nil, fmt.Errorf("invalid amount: %v", amount)
+		}
+		amountStr = strconv.FormatUint(ptnjson.Ptn2Dao(amt), 10)
+	}
+
+	// 判断本节点是否同步完成，数据是否最新
+	if !a.Dag().IsSynced() {
+		return nil, fmt.Errorf("this node is not synced, and can't undelegate now")
+	}
+
+	// 调用系统合约
+	cArgs := [][]byte{[]byte(modules.UndelegateFromMediator), []byte(medAdd.String()), []byte(amountStr)}
+	fee := a.Dag().GetChainParameters().TransferPtnBaseFee
+	reqId, err := a.ContractInvokeReqTx(fromAdd, fromAdd, 0, fee, nil,
+		syscontract.DepositContractAddress, cArgs, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回执行结果
+	res := &TxExecuteResult{}
+	res.TxContent = fmt.Sprintf("Account(%v) undelegate %v from mediator(%v)", from, amount, medAddStr)
+	res.TxFee = fmt.Sprintf("%vdao", fee)
+	res.Warning = DefaultResult
+	res.Tip = "Your ReqId is: " + hex.EncodeToString(reqId[:]) +
+		" , You can get the transaction hash with dag.getTxByReqId()"
+
+	return res, nil
+}
+
 func (a *PrivateMediatorAPI) Update(args modules.MediatorUpdateArgs) (*TxExecuteResult, error) {
 	// 参数验证
 	addr, err := args.Validate()
@@ -478,11 +649,15 @@ func (a *PrivateMediatorAPI) Update(args modules.MediatorUpdateArgs) (*TxExecute
 	if args.RewardAdd != nil {
 		rewardAddStr = *args.RewardAdd
 	}
+	commissionRateStr := ""
+	if args.CommissionRate != nil {
+		commissionRateStr = fmt.Sprintf("%v", *args.CommissionRate)
+	}
 
 	res := &TxExecuteResult{}
 	res.TxContent = fmt.Sprintf("mediator(%v) update info with name: %v, url: %v logo: %v, location: %v, "+
-		"applyInfo: %v, node: %v, initPubKey: %v, rewardAdd: %v", args.AddStr, nameStr, urlStr, logoStr, locStr,
-		descStr, nodeStr, initPubKeyStr, rewardAddStr)
+		"applyInfo: %v, node: %v, initPubKey: %v, rewardAdd: %v, commissionRate: %v", args.AddStr, nameStr, urlStr,
+		logoStr, locStr, descStr, nodeStr, initPubKeyStr, rewardAddStr, commissionRateStr)
 	res.TxFee = fmt.Sprintf("%vdao", fee)
 	res.Warning = DefaultResult
 	res.Tip = "Your ReqId is: " + hex.EncodeToString(reqId[:]) +
@@ -490,3 +665,25 @@ func (a *PrivateMediatorAPI) Update(args modules.MediatorUpdateArgs) (*TxExecute
 
 	return res, nil
 }
+
+// 上一届mediator对轻客户端使用的换届信息签名
+func (a *PrivateMediatorAPI) SignSpvTransition(medAddStr string, transition *spv.MediatorSetTransition) (
+	*spv.MediatorSetTransition, error) {
+	medAdd, err := common.StringToAddress(medAddStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address: %v", medAddStr)
+	}
+	if !a.Dag().GetGlobalProp().IsPrecedingMediator(medAdd) {
+		return nil, fmt.Errorf("%v is not a preceding mediator", medAddStr)
+	}
+	ks := a.GetKeyStore()
+	pubKey, err := ks.GetPublicKey(medAdd)
+	if err != nil {
+		return nil, err
+	}
+	err = transition.Sign(pubKey, func(msg []byte) ([]byte, error) { return ks.SignMessage(medAdd, msg) })
+	if err != nil {
+		return nil, err
+	}
+	return transition, nil
+}
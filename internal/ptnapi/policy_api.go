@@ -0,0 +1,164 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ptnapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/ptnjson"
+	"github.com/palletone/go-palletone/tokenengine"
+	"github.com/shopspring/decimal"
+)
+
+type PolicyAccountJson struct {
+	Address      string `json:"address"`
+	Owner        string `json:"owner"`
+	RedeemScript string `json:"redeem_script"`
+}
+
+// 查询策略账户当前的策略
+func (s *PublicContractAPI) GetAccountPolicy(ctx context.Context, account string) (string, error) {
+	return s.Ccquery(ctx, syscontract.PolicyContractAddress.String(), []string{modules.GetPolicy, account}, 0)
+}
+
+// 获得第二因子需要签名的hash。policyJson不为空时是修改策略用的hash；
+// 否则是从策略账户向to转账amount数量asset用的hash，与TransferFromPolicyAccount的参数相同
+func (s *PublicContractAPI) GetPolicyAuthHash(ctx context.Context, account string, policyJson string,
+	to string, asset string, amount decimal.Decimal) (string, error) {
+	result, err := s.GetAccountPolicy(ctx, account)
+	if err != nil {
+		return "", err
+	}
+	policyAccount := &modules.PolicyAccount{}
+	if err := json.Unmarshal([]byte(result), policyAccount); err != nil {
+		return "", fmt.Errorf("invalid policy of account %s: %v", account, err)
+	}
+	if policyJson != "" {
+		hash := modules.PolicyAuthHash(account, policyAccount.Nonce, []byte(policyJson))
+		return hex.EncodeToString(hash), nil
+	}
+
+	if _, err := common.StringToAddress(to); err != nil {
+		return "", fmt.Errorf("invalid recipient address: %v", to)
+	}
+	token := dagconfig.DagConfig.GetGasToken().ToAsset()
+	if asset != "" {
+		token, err = modules.StringToAsset(asset)
+		if err != nil {
+			return "", fmt.Errorf("invalid asset: %v", asset)
+		}
+	}
+	payment := &modules.InvokeTokens{Amount: ptnjson.JsonAmt2AssetAmt(token, amount), Asset: token, Address: to}
+	hash := modules.PolicySpendHash(account, policyAccount.Nonce, []*modules.InvokeTokens{payment})
+	return hex.EncodeToString(hash), nil
+}
+
+// 根据钱包中所有者地址的公钥，生成策略账户地址和赎回脚本
+func (s *PrivateWalletAPI) CreatePolicyAccount(ctx context.Context, owner string) (*PolicyAccountJson, error) {
+	ownerAddr, err := common.StringToAddress(owner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner address: %v", owner)
+	}
+	pubKey, err := s.b.GetKeyStore().GetPublicKey(ownerAddr)
+	if err != nil {
+		return nil, err
+	}
+	redeem := tokenengine.Instance.GeneratePolicyRedeemScript(pubKey, syscontract.PolicyContractAddress)
+	return &PolicyAccountJson{
+		Address:      crypto.ScriptToAddress(redeem).String(),
+		Owner:        owner,
+		RedeemScript: hex.EncodeToString(redeem),
+	}, nil
+}
+
+// 所有者设置策略账户的支出策略，secondFactorSign为第二因子对GetPolicyAuthHash结果的签名，可为空
+func (s *PrivateWalletAPI) SetAccountPolicy(ctx context.Context, owner string, policyJson string,
+	secondFactorSign string, fee decimal.Decimal, password string, duration *uint64) (string, error) {
+	ownerAddr, err := common.StringToAddress(owner)
+	if err != nil {
+		return "", fmt.Errorf("invalid owner address: %v", owner)
+	}
+	policy := &modules.SpendingPolicy{}
+	if err := json.Unmarshal([]byte(policyJson), policy); err != nil {
+		return "", fmt.Errorf("invalid policy json: %v", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return "", err
+	}
+	if err := s.unlockKS(ownerAddr, password, duration); err != nil {
+		return "", err
+	}
+	pubKey, err := s.b.GetKeyStore().GetPublicKey(ownerAddr)
+	if err != nil {
+		return "", err
+	}
+	args := [][]byte{[]byte(modules.SetPolicy), []byte(hex.EncodeToString(pubKey)), []byte(policyJson),
+		[]byte(secondFactorSign)}
+	contractAddr := syscontract.PolicyContractAddress
+	reqId, err := s.b.ContractInvokeReqTx(ownerAddr, ownerAddr, 0, ptnjson.Ptn2Dao(fee), nil, contractAddr, args, 0)
+	if err != nil {
+		return "", err
+	}
+	return reqId.String(), nil
+}
+
+// 从策略账户转账，交易中同时调用策略合约的spend方法，由合约检查是否符合策略
+func (s *PrivateWalletAPI) TransferFromPolicyAccount(ctx context.Context, asset string, owner string, to string,
+	amount decimal.Decimal, fee decimal.Decimal, secondFactorSign string, password string,
+	duration *uint64) (common.Hash, error) {
+	ownerAddr, err := common.StringToAddress(owner)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid owner address: %v", owner)
+	}
+	ks := s.b.GetKeyStore()
+	if err := s.unlockKS(ownerAddr, password, duration); err != nil {
+		return common.Hash{}, err
+	}
+	pubKey, err := ks.GetPublicKey(ownerAddr)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	redeem := tokenengine.Instance.GeneratePolicyRedeemScript(pubKey, syscontract.PolicyContractAddress)
+	account := crypto.ScriptToAddress(redeem).String()
+
+	rawTx, usedUtxo, err := s.buildRawTransferTx(asset, account, to, amount, fee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	rawTx.AddMessage(modules.NewMessage(modules.APP_CONTRACT_INVOKE_REQUEST, &modules.ContractInvokeRequestPayload{
+		ContractId: syscontract.PolicyContractAddress.Bytes(),
+		Args:       [][]byte{[]byte(modules.PolicySpend), []byte(account), []byte(secondFactorSign)},
+	}))
+
+	utxoLockScripts := make(map[modules.OutPoint][]byte)
+	for _, utxo := range usedUtxo {
+		utxoLockScripts[utxo.OutPoint] = utxo.PkScript
+	}
+	_, err = tokenengine.Instance.SignTxAllPaymentInput(rawTx, 1, utxoLockScripts, redeem,
+		func(addr common.Address) ([]byte, error) { return ks.GetPublicKey(addr) },
+		func(addr common.Address, msg []byte) ([]byte, error) { return ks.SignMessage(addr, msg) })
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, rawTx)
+}
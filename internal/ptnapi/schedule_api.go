@@ -0,0 +1,118 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ptnapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/ptnjson"
+	"github.com/shopspring/decimal"
+)
+
+const scheduleTimeLayout = "2006-01-02 15:04:05"
+
+// 列出某地址作为付款人或收款人的定时支付计划，地址为空时列出全部
+func (s *PublicContractAPI) ListSchedules(ctx context.Context, addr string) (string, error) {
+	return s.Ccquery(ctx, syscontract.ScheduledPaymentContractAddress.String(),
+		[]string{modules.ListSchedules, addr}, 0)
+}
+
+func (s *PublicContractAPI) GetSchedule(ctx context.Context, id string) (string, error) {
+	return s.Ccquery(ctx, syscontract.ScheduledPaymentContractAddress.String(),
+		[]string{modules.GetSchedule, id}, 0)
+}
+
+// 创建定时支付计划，amount为每期支付的数量，共count期，全部金额在创建时托管到合约
+// startTime格式为"2006-01-02 15:04:05"(UTC)，为空时立即开始，interval为两期之间的秒数，
+// startHeight为开始支付的链高度，0表示不限制。每期执行的手续费(TransferPtnBaseFee)也同时托管
+func (s *PrivateContractAPI) CreateSchedule(ctx context.Context, from, recipient string, amount decimal.Decimal,
+	asset string, startTime string, interval uint64, count uint32, startHeight uint64,
+	fee decimal.Decimal) (string, error) {
+	fromAddr, err := common.StringToAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid account address: %v", from)
+	}
+	if _, err := common.StringToAddress(recipient); err != nil {
+		return "", fmt.Errorf("invalid recipient address: %v", recipient)
+	}
+	if !amount.IsPositive() || count == 0 {
+		return "", fmt.Errorf("amount and count must be positive")
+	}
+	if count > 1 && interval == 0 {
+		return "", fmt.Errorf("interval must be positive for recurring payment")
+	}
+
+	start := int64(0)
+	if startTime != "" {
+		t, err := time.ParseInLocation(scheduleTimeLayout, startTime, time.UTC)
+		if err != nil {
+			return "", fmt.Errorf("invalid start time: %v", startTime)
+		}
+		start = t.Unix()
+	}
+
+	gasToken := dagconfig.DagConfig.GetGasToken().ToAsset()
+	token := gasToken
+	if asset != "" {
+		token, err = modules.StringToAsset(asset)
+		if err != nil {
+			return "", fmt.Errorf("invalid asset: %v", asset)
+		}
+	}
+	escrow := ptnjson.JsonAmt2AssetAmt(token, amount) * uint64(count)
+	feeReserve := s.b.Dag().GetChainParameters().TransferPtnBaseFee * uint64(count)
+
+	contractAddr := syscontract.ScheduledPaymentContractAddress
+	args := [][]byte{[]byte(modules.CreateSchedule), []byte(recipient),
+		[]byte(strconv.FormatInt(start, 10)), []byte(strconv.FormatUint(interval, 10)),
+		[]byte(strconv.FormatUint(uint64(count), 10)), []byte(strconv.FormatUint(startHeight, 10))}
+	daoFee := ptnjson.Ptn2Dao(fee)
+
+	var reqId common.Hash
+	if token.Equal(gasToken) {
+		reqId, err = s.b.ContractInvokeReqTx(fromAddr, contractAddr, escrow+feeReserve, daoFee, nil, contractAddr,
+			args, 0)
+	} else {
+		reqId, err = s.b.ContractInvokeReqTokenTx(fromAddr, contractAddr, contractAddr, feeReserve, daoFee, escrow,
+			token.String(), contractAddr, args, 0)
+	}
+	if err != nil {
+		return "", err
+	}
+	return reqId.String(), nil
+}
+
+// 取消定时支付计划，未支付的托管资金退回付款人
+func (s *PrivateContractAPI) CancelSchedule(ctx context.Context, from, id string,
+	fee decimal.Decimal) (string, error) {
+	fromAddr, err := common.StringToAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid account address: %v", from)
+	}
+	contractAddr := syscontract.ScheduledPaymentContractAddress
+	args := [][]byte{[]byte(modules.CancelSchedule), []byte(id)}
+	reqId, err := s.b.ContractInvokeReqTx(fromAddr, fromAddr, 0, ptnjson.Ptn2Dao(fee), nil, contractAddr, args, 0)
+	if err != nil {
+		return "", err
+	}
+	return reqId.String(), nil
+}
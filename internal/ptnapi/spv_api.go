@@ -0,0 +1,115 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package ptnapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/light/spv"
+)
+
+// 一次最多返回的单元头数量
+const maxSpvHeaders = 1000
+
+// 以当前最新单元生成离线验证用的检查点
+func (s *PublicBlockChainAPI) GetSpvCheckpoint(ctx context.Context) (*spv.Checkpoint, error) {
+	dag := s.b.Dag()
+	header := dag.CurrentHeader(dagconfig.DagConfig.GetGasToken())
+	if header == nil {
+		return nil, fmt.Errorf("current header not found")
+	}
+	return spv.NewCheckpoint(header, dag.GetGlobalProp(), dag.GetDynGlobalProp(), dag.GetMediatorSchl()), nil
+}
+
+// 以最近一次维护之后的全局属性生成换届信息，由上一届mediator签名后供轻客户端跨过维护单元
+func (s *PublicBlockChainAPI) GetSpvMediatorSetTransition(ctx context.Context) (*spv.MediatorSetTransition, error) {
+	dag := s.b.Dag()
+	gasToken := dagconfig.DagConfig.GetGasToken()
+	header := dag.CurrentHeader(gasToken)
+	if header == nil {
+		return nil, fmt.Errorf("current header not found")
+	}
+	// 维护单元是第一个到达上一次维护时间的单元
+	lastMaintenanceTime := int64(dag.GetDynGlobalProp().LastMaintenanceTime)
+	for header.NumberU64() > 1 {
+		parent, err := dag.GetHeaderByNumber(modules.NewChainIndex(gasToken, header.NumberU64()-1))
+		if err != nil {
+			return nil, err
+		}
+		if parent.Time < lastMaintenanceTime {
+			break
+		}
+		header = parent
+	}
+
+	gp := dag.GetGlobalProp()
+	return &spv.MediatorSetTransition{
+		UnitHash:             header.Hash(),
+		ActiveMediators:      gp.GetActiveMediators(),
+		Threshold:            gp.ChainThreshold(),
+		MediatorInterval:     gp.ChainParameters.MediatorInterval,
+		MaintenanceSkipSlots: gp.ChainParameters.MaintenanceSkipSlots,
+		MaintenanceInterval:  gp.ChainParameters.MaintenanceInterval,
+		GroupSignScheme:      gp.ChainParameters.GroupSignScheme,
+	}, nil
+}
+
+// 获得从from开始的count个单元头
+func (s *PublicBlockChainAPI) GetSpvHeaders(ctx context.Context, from uint64, count uint64) (
+	[]*modules.Header, error) {
+	if count > maxSpvHeaders {
+		return nil, fmt.Errorf("count must not be greater than %d", maxSpvHeaders)
+	}
+	gasToken := dagconfig.DagConfig.GetGasToken()
+	headers := make([]*modules.Header, 0, count)
+	for i := from; i < from+count; i++ {
+		header, err := s.b.Dag().GetHeaderByNumber(modules.NewChainIndex(gasToken, i))
+		if err != nil {
+			break
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// 获得UTXO存在或者不存在于单元头承诺的UTXO集合中的证明
+func (s *PublicBlockChainAPI) GetUtxoProof(ctx context.Context, txid string, msgIdx int, outIdx int) (
+	*spv.UtxoProof, error) {
+	outpoint := modules.NewOutPoint(common.HexToHash(txid), uint32(msgIdx), uint32(outIdx))
+	return s.b.GetUtxoProof(outpoint)
+}
+
+// 获得交易包含在单元中的Merkle证明
+func (s *PublicBlockChainAPI) GetTxInclusionProof(ctx context.Context, txHash string) (*spv.TxProof, error) {
+	hash := common.HexToHash(txHash)
+	tx, err := s.b.Dag().GetTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	unit, err := s.b.Dag().GetUnitByHash(tx.UnitHash)
+	if err != nil {
+		return nil, err
+	}
+	for i, utx := range unit.Txs {
+		if utx.Hash() == hash {
+			return spv.NewTxProof(unit, i)
+		}
+	}
+	return nil, fmt.Errorf("tx %s not found in unit %s", txHash, tx.UnitHash.String())
+}
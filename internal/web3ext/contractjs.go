@@ -144,6 +144,54 @@ web3._extend({
         	call: 'contract_sysConfigContractQuery',
         	params: 1, // param[]string
 		}),
+		new web3._extend.Method({
+			name: 'createSchedule',
+			call: 'contract_createSchedule',
+			params: 9, // from, recipient, amount, asset, startTime, interval, count, startHeight, fee
+			inputFormatter: [null, null, null, null, null, null, null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'cancelSchedule',
+			call: 'contract_cancelSchedule',
+			params: 3, // from, scheduleId, fee
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'listSchedules',
+			call: 'contract_listSchedules',
+			params: 1, // address
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getSchedule',
+			call: 'contract_getSchedule',
+			params: 1, // scheduleId
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getStateProof',
+			call: 'contract_getStateProof',
+			params: 2, // contractAddr, key
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getStateHistory',
+			call: 'contract_getStateHistory',
+			params: 4, // contractAddr, key, from, to
+			inputFormatter: [null, null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getAccountPolicy',
+			call: 'contract_getAccountPolicy',
+			params: 1, // account
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getPolicyAuthHash',
+			call: 'contract_getPolicyAuthHash',
+			params: 5, // account, policyJson, to, asset, amount
+			inputFormatter: [null, null, null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getAllContractsUsedTemplateId',
         	call: 'contract_getAllContractsUsedTemplateId',
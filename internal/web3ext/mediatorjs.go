@@ -81,6 +81,11 @@ web3._extend({
 			call: 'mediator_update',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'signSpvTransition',
+			call: 'mediator_signSpvTransition',
+			params: 2,
+		}),
 		new web3._extend.Method({
 			name: 'getNextUpdateTime',
 			call: 'mediator_getNextUpdateTime',
@@ -121,6 +126,31 @@ web3._extend({
 			call: 'mediator_isActive',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'delegate',
+			call: 'mediator_delegate',
+			params: 3,
+		}),
+		new web3._extend.Method({
+			name: 'undelegate',
+			call: 'mediator_undelegate',
+			params: 3,
+		}),
+		new web3._extend.Method({
+			name: 'getDelegations',
+			call: 'mediator_getDelegations',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'getDelegationsByAddr',
+			call: 'mediator_getDelegationsByAddr',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'getAccruedReward',
+			call: 'mediator_getAccruedReward',
+			params: 1,
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -169,6 +169,31 @@ web3._extend({
 			call: 'ptn_proofTransactionByRlptx',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getSpvCheckpoint',
+			call: 'ptn_getSpvCheckpoint',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSpvMediatorSetTransition',
+			call: 'ptn_getSpvMediatorSetTransition',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSpvHeaders',
+			call: 'ptn_getSpvHeaders',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getTxInclusionProof',
+			call: 'ptn_getTxInclusionProof',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getUtxoProof',
+			call: 'ptn_getUtxoProof',
+			params: 3
+		}),
 		new web3._extend.Method({
 			name: 'syncUTXOByAddr',
 			call: 'ptn_syncUTXOByAddr',
@@ -98,6 +98,24 @@ const Wallet_JS = `
 			params: 7,
 			inputFormatter: [null,null,null,null,null,null,null]
 		}),
+		new web3._extend.Method({
+			name: 'createPolicyAccount',
+			call: 'wallet_createPolicyAccount',
+			params: 1, // owner
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'setAccountPolicy',
+			call: 'wallet_setAccountPolicy',
+			params: 6, // owner, policyJson, secondFactorSign, fee, password, duration
+			inputFormatter: [null,null,null,null,null,null]
+		}),
+		new web3._extend.Method({
+			name: 'transferFromPolicyAccount',
+			call: 'wallet_transferFromPolicyAccount',
+			params: 8, // asset, owner, to, amount, fee, secondFactorSign, password, duration
+			inputFormatter: [null,null,null,null,null,null,null,null]
+		}),
 		new web3._extend.Method({
 			name: 'createProofTransaction',
 			call: 'wallet_createProofTransaction',
@@ -39,6 +39,7 @@ import (
 	"github.com/palletone/go-palletone/dag/state"
 	"github.com/palletone/go-palletone/dag/txspool"
 	"github.com/palletone/go-palletone/internal/ptnapi"
+	"github.com/palletone/go-palletone/light/spv"
 	"github.com/palletone/go-palletone/ptn/downloader"
 	"github.com/palletone/go-palletone/ptnjson"
 	"github.com/palletone/go-palletone/ptnjson/statistics"
@@ -513,6 +514,10 @@ func (b *LesApiBackend) SyncUTXOByAddr(addr string) string {
 	return b.ptn.ProtocolManager().SyncUTXOByAddr(addr)
 }
 
+func (b *LesApiBackend) GetUtxoProof(outpoint *modules.OutPoint) (*spv.UtxoProof, error) {
+	return b.ptn.ProtocolManager().GetUtxoProof(outpoint)
+}
+
 func (b *LesApiBackend) StartCorsSync() (string, error) {
 	return "light node have not cors server", errors.New("light node have not cors server")
 }
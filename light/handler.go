@@ -122,6 +122,7 @@ type ProtocolManager struct {
 	//SPV
 	validation   *Validation
 	utxosync     *UtxosSync
+	utxoproofs   *UtxoProofSync
 	protocolname string
 
 	//cors
@@ -152,6 +153,7 @@ func NewProtocolManager(lightSync bool, peers *peerSet, networkId uint64, gasTok
 		noMorePeers:   make(chan struct{}),
 		validation:    NewValidation(dag),
 		utxosync:      NewUtxosSync(dag),
+		utxoproofs:    NewUtxoProofSync(),
 		receivedCache: freecache.NewCache(5 * 1024 * 1024),
 	}
 
@@ -203,7 +205,7 @@ func NewProtocolManager(lightSync bool, peers *peerSet, networkId uint64, gasTok
 		return nil, errIncompatibleConfig
 	}
 
-	removePeer := manager.removePeer
+	removePeer := manager.misbehavingPeerDropper(p2p.MisbehaviorTimeout)
 	if disableClientRemovePeer {
 		removePeer = func(id string) {}
 	}
@@ -237,7 +239,17 @@ func (pm *ProtocolManager) newLightFetcher() *LightFetcher {
 		return pm.dag.InsertLightHeader(headers)
 	}
 	return NewLightFetcher(pm.dag.GetHeaderByHash, pm.dag.GetLightChainHeight, headerVerifierFn,
-		headerBroadcaster, inserter, pm.removePeer)
+		headerBroadcaster, inserter, pm.misbehavingPeerDropper(p2p.MisbehaviorInvalidHeader))
+}
+
+//misbehavingPeerDropper 断开节点时先记录不当行为，分数过高时节点被禁止连接
+func (pm *ProtocolManager) misbehavingPeerDropper(m p2p.Misbehavior) func(id string) {
+	return func(id string) {
+		if p := pm.peers.Peer(id); p != nil {
+			p.Penalize(m)
+		}
+		pm.removePeer(id)
+	}
 }
 
 func (pm *ProtocolManager) BroadcastLightHeader(header *modules.Header) {
@@ -427,12 +439,17 @@ func (pm *ProtocolManager) handle(p *peer) error {
 
 // handleMsg is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
-func (pm *ProtocolManager) handleMsg(p *peer) error {
+func (pm *ProtocolManager) handleMsg(p *peer) (err error) {
 	// Read the next message from the remote peer, and ensure it's fully consumed
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err != nil {
+			p.Penalize(p2p.MisbehaviorProtocolError)
+		}
+	}()
 	log.Trace("Light Palletone message arrived", "code", msg.Code, "bytes", msg.Size)
 
 	if msg.Size > ProtocolMaxMsgSize {
@@ -480,6 +497,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	case LeafNodesMsg:
 		return pm.LeafNodesMsg(msg, p)
 
+	case GetUtxoProofsMsg:
+		return pm.GetUtxoProofsMsg(msg, p)
+
+	case UtxoProofsMsg:
+		return pm.UtxoProofsMsg(msg, p)
+
 	default:
 		log.Trace("Received unknown message", "code", msg.Code)
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
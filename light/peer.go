@@ -221,6 +221,15 @@ func (p *peer) RequestUTXOs(reqID, cost uint64, addr string) error {
 	return p2p.Send(p.rw, GetUTXOsMsg, addr)
 }
 
+func (p *peer) RequestUtxoProofs(outpoints []modules.OutPoint) error {
+	log.Debug("Fetching batch of utxo proofs", "count", len(outpoints))
+	return p2p.Send(p.rw, GetUtxoProofsMsg, outpoints)
+}
+
+func (p *peer) SendUtxoProofs(data *utxoProofsData) error {
+	return p2p.Send(p.rw, UtxoProofsMsg, data)
+}
+
 // RequestProofs fetches a batch of merkle proofs from a remote node.
 func (p *peer) RequestProofs(reqID, cost uint64, reqs []ProofReq) error {
 	log.Debug("Fetching batch of proofs", "count", len(reqs))
@@ -60,6 +60,8 @@ const (
 	SendTxMsg          = 0x08
 	GetLeafNodesMsg    = 0x09
 	LeafNodesMsg       = 0x0a
+	GetUtxoProofsMsg   = 0x0b
+	UtxoProofsMsg      = 0x0c
 	// Protocol messages belonging to LPV2
 	//GetProofsV2Msg         = 0x0f
 	//ProofsV2Msg            = 0x10
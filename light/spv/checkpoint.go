@@ -0,0 +1,147 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+// Package spv 不依赖节点和数据库，离线验证单元头链和交易的包含证明
+package spv
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/hexutil"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+// Checkpoint 是轻客户端信任的起点，包含验证后续单元调度顺序所需的状态。
+// 检查点需要从可信的渠道获得，验证单元头链之后会得到新的检查点，
+// 维护周期会改变活跃mediator集合，跨过维护单元时需要有上一届mediator签名的换届信息
+type Checkpoint struct {
+	Header *modules.Header `json:"header"`
+
+	// 当前的绝对时间槽数量
+	CurrentASlot         uint64 `json:"current_aslot"`
+	MediatorInterval     uint8  `json:"mediator_interval"`
+	MaintenanceSkipSlots uint8  `json:"maintenance_skip_slots"`
+	MaintenanceFlag      bool   `json:"maintenance_flag"`
+	NextMaintenanceTime  uint32 `json:"next_maintenance_time"`
+	MaintenanceInterval  uint32 `json:"maintenance_interval"`
+
+	// 单元不可逆所需要的确认mediator数量
+	Threshold         int              `json:"threshold"`
+	ActiveMediators   []common.Address `json:"active_mediators"`
+	ShuffledMediators []common.Address `json:"shuffled_mediators"`
+
+	// 验证群签名所用的群签名方案，为空时使用默认方案
+	GroupSignScheme string `json:"group_sign_scheme,omitempty"`
+	// 可信的mediator群公钥，key为mediator地址，没有可信群公钥的单元不采信其群签名
+	GroupPubKeys map[string]hexutil.Bytes `json:"group_pub_keys,omitempty"`
+}
+
+// 根据全节点当前的全局属性生成检查点
+func NewCheckpoint(header *modules.Header, gp *modules.GlobalProperty, dgp *modules.DynamicGlobalProperty,
+	ms *modules.MediatorSchedule) *Checkpoint {
+	cp := &Checkpoint{
+		Header:               header,
+		CurrentASlot:         dgp.CurrentASlot,
+		MediatorInterval:     gp.ChainParameters.MediatorInterval,
+		MaintenanceSkipSlots: gp.ChainParameters.MaintenanceSkipSlots,
+		MaintenanceFlag:      dgp.MaintenanceFlag,
+		NextMaintenanceTime:  dgp.NextMaintenanceTime,
+		MaintenanceInterval:  gp.ChainParameters.MaintenanceInterval,
+		Threshold:            gp.ChainThreshold(),
+		ActiveMediators:      gp.GetActiveMediators(),
+		ShuffledMediators:    make([]common.Address, len(ms.CurrentShuffledMediators)),
+		GroupSignScheme:      gp.ChainParameters.GroupSignScheme,
+	}
+	copy(cp.ShuffledMediators, ms.CurrentShuffledMediators)
+	return cp
+}
+
+func (cp *Checkpoint) Validate() error {
+	if cp.Header == nil || cp.Header.Number == nil {
+		return errors.New("checkpoint header is nil")
+	}
+	if cp.MediatorInterval == 0 || cp.MaintenanceInterval == 0 {
+		return errors.New("mediator interval or maintenance interval of checkpoint is 0")
+	}
+	if len(cp.ActiveMediators) == 0 || len(cp.ShuffledMediators) != len(cp.ActiveMediators) {
+		return errors.New("invalid mediators of checkpoint")
+	}
+	if cp.Threshold <= 0 || cp.Threshold > len(cp.ActiveMediators) {
+		return errors.New("invalid threshold of checkpoint")
+	}
+	return nil
+}
+
+func (cp *Checkpoint) copy() *Checkpoint {
+	cpy := *cp
+	cpy.ShuffledMediators = make([]common.Address, len(cp.ShuffledMediators))
+	copy(cpy.ShuffledMediators, cp.ShuffledMediators)
+	return &cpy
+}
+
+// 检查点之后的单元到达维护时间时是维护单元，会改变活跃mediator集合
+func (cp *Checkpoint) isMaintenance(h *modules.Header) bool {
+	return !(uint32(h.Time) < cp.NextMaintenanceTime)
+}
+
+// 与 PropRepository.GetSlotAtTime 相同，返回检查点单元之后when所在的slot
+func (cp *Checkpoint) slotAtTime(when int64) uint64 {
+	return uint64(modules.SlotAtTime(cp.Header.Time, cp.Header.NumberU64(), cp.MediatorInterval,
+		cp.MaintenanceSkipSlots, cp.MaintenanceFlag, when))
+}
+
+// 与 PropRepository.GetScheduledMediator 相同
+func (cp *Checkpoint) scheduledMediator(slotNum uint64) common.Address {
+	index := (cp.CurrentASlot + slotNum - 1) % uint64(len(cp.ShuffledMediators))
+	return cp.ShuffledMediators[index]
+}
+
+// 按照 UnitProduceRepository.ApplyUnit 的流程，更新检查点到下一个单元，
+// 维护单元使用已验证的换届信息更新活跃mediator和相关参数
+func (cp *Checkpoint) apply(h *modules.Header, slotNum uint64, tr *MediatorSetTransition) {
+	maintenance := cp.isMaintenance(h)
+	cp.Header = h
+	cp.CurrentASlot += slotNum
+
+	if maintenance {
+		cp.ActiveMediators = make([]common.Address, len(tr.ActiveMediators))
+		copy(cp.ActiveMediators, tr.ActiveMediators)
+		cp.Threshold = tr.Threshold
+		cp.MediatorInterval = tr.MediatorInterval
+		cp.MaintenanceSkipSlots = tr.MaintenanceSkipSlots
+		cp.MaintenanceInterval = tr.MaintenanceInterval
+		cp.GroupSignScheme = tr.GroupSignScheme
+		cp.GroupPubKeys = tr.GroupPubKeys
+		cp.NextMaintenanceTime = modules.NextMaintenanceTime(h.NumberU64(), h.Time, cp.NextMaintenanceTime,
+			int64(cp.MaintenanceInterval))
+	}
+	cp.MaintenanceFlag = maintenance
+
+	// 与 PropRepository.UpdateMediatorSchedule 相同
+	aSize := uint64(len(cp.ActiveMediators))
+	if h.NumberU64()%aSize == 0 {
+		mediators := common.Addresses(make([]common.Address, aSize))
+		copy(mediators, cp.ActiveMediators)
+		sort.Sort(mediators)
+		modules.ShuffleMediators(mediators, modules.ShuffleSeed(h.Hash()))
+		cp.ShuffledMediators = mediators
+	}
+}
@@ -0,0 +1,113 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/hexutil"
+	"github.com/palletone/go-palletone/common/trie"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+// 数据库中合约状态的值以StateVersion开头
+const stateVersionLength = 28
+
+var ErrNoStateCommitment = errors.New("unit has no contract state commitment")
+
+// ContractStateProof 合约的某个状态在单元头承诺的合约状态中存在或者不存在的证明
+type ContractStateProof struct {
+	UnitHash      common.Hash     `json:"unit_hash"`
+	UnitIndex     uint64          `json:"unit_index"`
+	ContractId    hexutil.Bytes   `json:"contract_id"`
+	Key           string          `json:"key"`
+	ContractRoot  common.Hash     `json:"contract_root"`  //合约的状态root
+	ContractProof []hexutil.Bytes `json:"contract_proof"` //合约root在单元头StateRoot中的证明
+	StateProof    []hexutil.Bytes `json:"state_proof"`    //状态在合约root中的证明
+}
+
+func toHexBytesList(list [][]byte) []hexutil.Bytes {
+	result := make([]hexutil.Bytes, 0, len(list))
+	for _, b := range list {
+		result = append(result, hexutil.Bytes(b))
+	}
+	return result
+}
+
+func toBytesList(list []hexutil.Bytes) [][]byte {
+	result := make([][]byte, 0, len(list))
+	for _, b := range list {
+		result = append(result, b)
+	}
+	return result
+}
+
+func NewContractStateProof(header *modules.Header, contractId []byte, key string, contractRoot common.Hash,
+	contractProof, stateProof [][]byte) *ContractStateProof {
+	return &ContractStateProof{
+		UnitHash:      header.Hash(),
+		UnitIndex:     header.NumberU64(),
+		ContractId:    contractId,
+		Key:           key,
+		ContractRoot:  contractRoot,
+		ContractProof: toHexBytesList(contractProof),
+		StateProof:    toHexBytesList(stateProof),
+	}
+}
+
+// 验证证明，返回header承诺的合约状态中的值，证明了不存在时返回nil
+func (p *ContractStateProof) Verify(header *modules.Header) (*modules.ContractStateValue, error) {
+	if header.Hash() != p.UnitHash {
+		return nil, fmt.Errorf("unit hash mismatch, proof:%s, header:%s", p.UnitHash.String(),
+			header.Hash().String())
+	}
+	if header.StateRoot == (common.Hash{}) {
+		return nil, ErrNoStateCommitment
+	}
+	value, err := verifyTrieProof(header.StateRoot, p.ContractId, toBytesList(p.ContractProof))
+	if err != nil {
+		return nil, err
+	}
+	//合约没有任何状态时，合约root是空trie的root
+	if value == nil {
+		if p.ContractRoot != new(trie.Trie).Hash() {
+			return nil, errors.New("contract is not included in the state root")
+		}
+	} else if !bytes.Equal(value, p.ContractRoot.Bytes()) {
+		return nil, errors.New("contract root mismatch")
+	}
+
+	value, err = verifyTrieProof(p.ContractRoot, []byte(p.Key), toBytesList(p.StateProof))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	if len(value) < stateVersionLength {
+		return nil, errors.New("invalid contract state value")
+	}
+	version := &modules.StateVersion{}
+	version.SetBytes(value[:stateVersionLength])
+	return &modules.ContractStateValue{Value: value[stateVersionLength:], Version: version}, nil
+}
@@ -0,0 +1,97 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyContractStateProof(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	statedb := storage.NewStateDb(db)
+	contractId := syscontract.DepositContractAddress.Bytes()
+	version := &modules.StateVersion{Height: modules.NewChainIndex(modules.PTNCOIN, 5), TxIndex: 2}
+	ws := []modules.ContractWriteSet{}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		ws = append(ws, *modules.NewWriteSet(key, []byte("value_"+key)))
+	}
+	assert.Nil(t, statedb.SaveContractStates(contractId, ws, version))
+	assert.Nil(t, statedb.CommitStateRoot())
+
+	tc := newTestChain(t, 3)
+	tc.produce(1, nil)
+	h := tc.state.Header
+	root, _ := statedb.GetStateRoot()
+	newProof := func(id []byte, key string) *ContractStateProof {
+		contractRoot, contractProof, stateProof, err := statedb.GetContractStateProof(root, id, key)
+		assert.Nil(t, err)
+		return NewContractStateProof(h, id, key, contractRoot, contractProof, stateProof)
+	}
+
+	//没有承诺的单元头
+	proof := newProof(contractId, "b")
+	_, err := proof.Verify(h)
+	assert.Equal(t, ErrNoStateCommitment, err)
+
+	h.StateRoot = root
+	tc.sign(h, h.Author())
+	proof = newProof(contractId, "b")
+	data, err := json.Marshal(proof)
+	assert.Nil(t, err)
+	proof = &ContractStateProof{}
+	assert.Nil(t, json.Unmarshal(data, proof))
+	state, err := proof.Verify(h)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value_b"), state.Value)
+	assert.Equal(t, version.String(), state.Version.String())
+
+	//不存在的状态和不存在的合约
+	state, err = newProof(contractId, "e").Verify(h)
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+	state, err = newProof(syscontract.SysConfigContractAddress.Bytes(), "a").Verify(h)
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+
+	//冒充其他合约的root
+	bad := *proof
+	bad.ContractId = syscontract.SysConfigContractAddress.Bytes()
+	_, err = bad.Verify(h)
+	assert.NotNil(t, err)
+	bad = *proof
+	bad.Key = "a"
+	state, err = bad.Verify(h)
+	assert.True(t, err != nil || state == nil)
+
+	//需要之后有足够的mediator确认
+	_, err = VerifyContractStateProof(tc.checkpoint, tc.headers, proof)
+	assert.Equal(t, ErrUnitNotStable, err)
+	tc.produce(1, nil)
+	state, err = VerifyContractStateProof(tc.checkpoint, tc.headers, proof)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value_b"), state.Value)
+}
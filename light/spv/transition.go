@@ -0,0 +1,155 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/hexutil"
+	"github.com/palletone/go-palletone/common/util"
+	"github.com/palletone/go-palletone/core/groupsign"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+// MediatorSetTransition 维护单元之后的活跃mediator集合和调度参数，
+// 由上一届活跃mediator签名，轻客户端据此跨过维护单元继续验证单元头链
+type MediatorSetTransition struct {
+	// 维护单元的hash
+	UnitHash             common.Hash              `json:"unit_hash"`
+	ActiveMediators      []common.Address         `json:"active_mediators"`
+	Threshold            int                      `json:"threshold"`
+	MediatorInterval     uint8                    `json:"mediator_interval"`
+	MaintenanceSkipSlots uint8                    `json:"maintenance_skip_slots"`
+	MaintenanceInterval  uint32                   `json:"maintenance_interval"`
+	GroupSignScheme      string                   `json:"group_sign_scheme,omitempty"`
+	GroupPubKeys         map[string]hexutil.Bytes `json:"group_pub_keys,omitempty"`
+
+	// 上一届mediator对换届信息的签名
+	Signatures []modules.Authentifier `json:"signatures,omitempty"`
+	// 上一届mediator对换届信息的群签名，群公钥需要是检查点中可信的群公钥
+	GroupPubKey hexutil.Bytes `json:"group_pub_key,omitempty"`
+	GroupSign   hexutil.Bytes `json:"group_sign,omitempty"`
+}
+
+type groupPubKeyEntry struct {
+	Mediator common.Address
+	PubKey   []byte
+}
+
+// Hash 返回换届信息中需要签名的内容的hash
+func (tr *MediatorSetTransition) Hash() common.Hash {
+	keys := make([]groupPubKeyEntry, 0, len(tr.GroupPubKeys))
+	for addrStr, pubKey := range tr.GroupPubKeys {
+		addr, _ := common.StringToAddress(addrStr)
+		keys = append(keys, groupPubKeyEntry{Mediator: addr, PubKey: pubKey})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Mediator.Less(keys[j].Mediator) })
+
+	return util.RlpHash([]interface{}{tr.UnitHash, tr.ActiveMediators, uint64(tr.Threshold), tr.MediatorInterval,
+		tr.MaintenanceSkipSlots, tr.MaintenanceInterval, tr.GroupSignScheme, keys})
+}
+
+// Sign 上一届的mediator对换届信息签名
+func (tr *MediatorSetTransition) Sign(pubKey []byte, sign func(msg []byte) ([]byte, error)) error {
+	signature, err := sign(tr.Hash().Bytes())
+	if err != nil {
+		return err
+	}
+	tr.Signatures = append(tr.Signatures, modules.Authentifier{PubKey: pubKey, Signature: signature})
+	return nil
+}
+
+func (tr *MediatorSetTransition) validate() error {
+	if len(tr.ActiveMediators) == 0 {
+		return errors.New("no active mediators in mediator set transition")
+	}
+	if tr.Threshold <= 0 || tr.Threshold > len(tr.ActiveMediators) {
+		return errors.New("invalid threshold of mediator set transition")
+	}
+	if tr.MediatorInterval == 0 || tr.MaintenanceInterval == 0 {
+		return errors.New("mediator interval or maintenance interval of mediator set transition is 0")
+	}
+	for addrStr := range tr.GroupPubKeys {
+		if _, err := common.StringToAddress(addrStr); err != nil {
+			return fmt.Errorf("invalid mediator %s of group public key", addrStr)
+		}
+	}
+	return nil
+}
+
+// 验证换届信息由检查点中的上一届mediator确认：
+// 有可信群公钥的有效群签名，或者至少有Threshold个不同的上一届mediator签名
+func (cp *Checkpoint) verifyTransition(tr *MediatorSetTransition) error {
+	if err := tr.validate(); err != nil {
+		return err
+	}
+	hash := tr.Hash()
+	if len(tr.GroupSign) > 0 && cp.verifyTransitionGroupSign(tr, hash) {
+		return nil
+	}
+
+	signed := make(map[common.Address]bool)
+	for _, sig := range tr.Signatures {
+		addr := crypto.PubkeyBytesToAddress(sig.PubKey)
+		if signed[addr] || !cp.isActiveMediator(addr) {
+			continue
+		}
+		if pass, _ := crypto.MyCryptoLib.Verify(sig.PubKey, sig.Signature, hash.Bytes()); pass {
+			signed[addr] = true
+		}
+	}
+	if len(signed) < cp.Threshold {
+		return fmt.Errorf("mediator set transition is signed by %d active mediators, %d are required",
+			len(signed), cp.Threshold)
+	}
+	return nil
+}
+
+func (cp *Checkpoint) verifyTransitionGroupSign(tr *MediatorSetTransition, hash common.Hash) bool {
+	trusted := false
+	for _, pubKey := range cp.GroupPubKeys {
+		if bytes.Equal(pubKey, tr.GroupPubKey) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return false
+	}
+	scheme, err := groupsign.Lookup(cp.GroupSignScheme)
+	if err != nil {
+		return false
+	}
+	return scheme.Verify(tr.GroupPubKey, hash.Bytes(), tr.GroupSign) == nil
+}
+
+func (cp *Checkpoint) isActiveMediator(addr common.Address) bool {
+	for _, med := range cp.ActiveMediators {
+		if med.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/hexutil"
+	"github.com/palletone/go-palletone/common/trie"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/light/les"
+)
+
+var ErrNoUtxoCommitment = errors.New("unit has no utxo commitment")
+
+// UtxoProof 某个outpoint在单元头承诺的UTXO集合中存在或者不存在的证明
+type UtxoProof struct {
+	UnitHash  common.Hash      `json:"unit_hash"`
+	UnitIndex uint64           `json:"unit_index"`
+	OutPoint  modules.OutPoint `json:"outpoint"`
+	Proof     []hexutil.Bytes  `json:"proof"`
+}
+
+func NewUtxoProof(header *modules.Header, outpoint *modules.OutPoint, proof [][]byte) *UtxoProof {
+	return &UtxoProof{
+		UnitHash:  header.Hash(),
+		UnitIndex: header.NumberU64(),
+		OutPoint:  *outpoint,
+		Proof:     toHexBytesList(proof),
+	}
+}
+
+// 验证证明，返回header承诺的UTXO集合中的utxo，证明了不存在时返回nil
+func (p *UtxoProof) Verify(header *modules.Header) (*modules.Utxo, error) {
+	if header.Hash() != p.UnitHash {
+		return nil, fmt.Errorf("unit hash mismatch, proof:%s, header:%s", p.UnitHash.String(),
+			header.Hash().String())
+	}
+	return VerifyUtxoProof(header, &p.OutPoint, toBytesList(p.Proof))
+}
+
+// VerifyUtxoProof 验证outpoint在header的UtxoRoot中的证明，证明了不存在时返回nil
+func VerifyUtxoProof(header *modules.Header, outpoint *modules.OutPoint, proof [][]byte) (*modules.Utxo, error) {
+	if header.UtxoRoot == (common.Hash{}) {
+		return nil, ErrNoUtxoCommitment
+	}
+	value, err := verifyTrieProof(header.UtxoRoot, outpoint.Bytes(), proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	utxo := new(modules.Utxo)
+	if err := rlp.DecodeBytes(value, utxo); err != nil {
+		return nil, err
+	}
+	return utxo, nil
+}
+
+// 验证key在root中的Merkle证明，返回key对应的值，证明了不存在时返回nil
+func verifyTrieProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	//空的trie
+	if len(proof) == 0 && root == new(trie.Trie).Hash() {
+		return nil, nil
+	}
+	nodes := make(les.NodeList, 0, len(proof))
+	for _, node := range proof {
+		nodes = append(nodes, rlp.RawValue(node))
+	}
+	value, err, _ := trie.VerifyProof(root, key, nodes.NodeSet())
+	return value, err
+}
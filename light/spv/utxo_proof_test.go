@@ -0,0 +1,96 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/ptndb"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/dag/storage"
+	"github.com/palletone/go-palletone/tokenengine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyUtxoProof(t *testing.T) {
+	db, _ := ptndb.NewMemDatabase()
+	utxodb := storage.NewUtxoDb(db, tokenengine.Instance)
+	header := modules.NewHeader(nil, 0, nil)
+	header.Number = modules.NewChainIndex(modules.PTNCOIN, 1)
+
+	//没有承诺的单元头
+	outpoint := modules.NewOutPoint(common.BytesToHash([]byte{1}), 0, 0)
+	_, err := VerifyUtxoProof(header, outpoint, nil)
+	assert.Equal(t, ErrNoUtxoCommitment, err)
+
+	//空的UTXO集合
+	header.UtxoRoot, _ = utxodb.GetUtxoRoot()
+	utxo, err := VerifyUtxoProof(header, outpoint, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, utxo)
+
+	view := make(map[modules.OutPoint]*modules.Utxo)
+	for i := 0; i < 20; i++ {
+		view[*modules.NewOutPoint(common.BytesToHash([]byte{byte(i)}), 0, 0)] = &modules.Utxo{
+			Amount: uint64(i + 1), Asset: modules.NewPTNAsset()}
+	}
+	assert.Nil(t, utxodb.SaveUtxoView(view))
+	header.UtxoRoot, _ = utxodb.GetUtxoRoot()
+
+	nodes, err := utxodb.GetUtxoProof(header.UtxoRoot, outpoint)
+	assert.Nil(t, err)
+	proof := NewUtxoProof(header, outpoint, nodes)
+	//证明可以通过json传递
+	data, err := json.Marshal(proof)
+	assert.Nil(t, err)
+	proof = &UtxoProof{}
+	assert.Nil(t, json.Unmarshal(data, proof))
+	utxo, err = proof.Verify(header)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), utxo.Amount)
+
+	//不存在的UTXO
+	absent := modules.NewOutPoint(common.BytesToHash([]byte{1}), 0, 1)
+	nodes, err = utxodb.GetUtxoProof(header.UtxoRoot, absent)
+	assert.Nil(t, err)
+	utxo, err = VerifyUtxoProof(header, absent, nodes)
+	assert.Nil(t, err)
+	assert.Nil(t, utxo)
+
+	//用存在的证明冒充其他outpoint的证明
+	proof.OutPoint = *modules.NewOutPoint(common.BytesToHash([]byte{2}), 0, 0)
+	utxo, err = proof.Verify(header)
+	assert.True(t, err != nil || utxo == nil)
+
+	//篡改证明
+	proof.OutPoint = *outpoint
+	last := proof.Proof[len(proof.Proof)-1]
+	last[len(last)-1] ^= 0xff
+	_, err = proof.Verify(header)
+	assert.NotNil(t, err)
+
+	//其他单元头
+	other := modules.CopyHeader(header)
+	other.Number = modules.NewChainIndex(modules.PTNCOIN, 2)
+	_, err = proof.Verify(other)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,250 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/hexutil"
+	"github.com/palletone/go-palletone/common/trie"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/core/groupsign"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/light/les"
+)
+
+var (
+	ErrTransitionRequired = errors.New("mediator set transition of the maintenance unit is required")
+	ErrUnitNotInChain     = errors.New("unit of proof is not in the verified header chain")
+	ErrUnitNotStable      = errors.New("unit of proof is not irreversible")
+)
+
+// TxProof 交易包含在某个单元中的Merkle证明
+type TxProof struct {
+	UnitHash  common.Hash     `json:"unit_hash"`
+	UnitIndex uint64          `json:"unit_index"`
+	TxHash    common.Hash     `json:"tx_hash"`
+	TxIndex   uint64          `json:"tx_index"`
+	Tx        hexutil.Bytes   `json:"tx"` //交易的rlp编码
+	Proof     []hexutil.Bytes `json:"proof"`
+}
+
+// 全节点为单元中的第txIndex个交易生成证明
+func NewTxProof(unit *modules.Unit, txIndex int) (*TxProof, error) {
+	if txIndex < 0 || txIndex >= len(unit.Txs) {
+		return nil, fmt.Errorf("tx index %d out of range", txIndex)
+	}
+	tri, _ := core.GetTrieInfo(unit.Txs)
+	key, _ := rlp.EncodeToBytes(uint(txIndex))
+	var nodes les.NodeList
+	if err := tri.Prove(key, 0, &nodes); err != nil {
+		return nil, err
+	}
+	proof := &TxProof{
+		UnitHash:  unit.Hash(),
+		UnitIndex: unit.NumberU64(),
+		TxHash:    unit.Txs[txIndex].Hash(),
+		TxIndex:   uint64(txIndex),
+		Tx:        unit.Txs.GetRlp(txIndex),
+		Proof:     make([]hexutil.Bytes, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		proof.Proof = append(proof.Proof, hexutil.Bytes(node))
+	}
+	return proof, nil
+}
+
+// 验证交易包含在header的TxRoot中，返回解码后的交易
+func (p *TxProof) Verify(header *modules.Header) (*modules.Transaction, error) {
+	if header.Hash() != p.UnitHash {
+		return nil, fmt.Errorf("unit hash mismatch, proof:%s, header:%s", p.UnitHash.String(),
+			header.Hash().String())
+	}
+	key, _ := rlp.EncodeToBytes(uint(p.TxIndex))
+	nodes := make(les.NodeList, 0, len(p.Proof))
+	for _, node := range p.Proof {
+		nodes = append(nodes, rlp.RawValue(node))
+	}
+	value, err, _ := trie.VerifyProof(header.TxRoot, key, nodes.NodeSet())
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || !bytes.Equal(value, p.Tx) {
+		return nil, errors.New("tx is not included in the unit")
+	}
+	tx := new(modules.Transaction)
+	if err := rlp.DecodeBytes(value, tx); err != nil {
+		return nil, err
+	}
+	if tx.Hash() != p.TxHash {
+		return nil, fmt.Errorf("tx hash mismatch, proof:%s, tx:%s", p.TxHash.String(), tx.Hash().String())
+	}
+	return tx, nil
+}
+
+// 验证单元的mediator签名
+func verifyAuthor(h *modules.Header) error {
+	if h.Authors.Empty() {
+		return errors.New("unit has no author")
+	}
+	hash := h.HashWithoutAuthor()
+	if pass, _ := crypto.MyCryptoLib.Verify(h.Authors.PubKey, h.Authors.Signature, hash.Bytes()); !pass {
+		return errors.New("invalid author signature")
+	}
+	return nil
+}
+
+// 单元有可信群公钥的有效群签名时返回true
+func (cp *Checkpoint) verifyGroupSign(h *modules.Header) bool {
+	if len(h.GroupSign) == 0 {
+		return false
+	}
+	pubKey, ok := cp.GroupPubKeys[h.Author().String()]
+	if !ok || !bytes.Equal(pubKey, h.GroupPubKey) {
+		return false
+	}
+	scheme, err := groupsign.Lookup(cp.GroupSignScheme)
+	if err != nil {
+		return false
+	}
+	return scheme.Verify(pubKey, h.Hash().Bytes(), h.GroupSign) == nil
+}
+
+// VerifyHeaders 从检查点开始依次验证单元头的连接关系、mediator签名以及是否符合mediator的调度顺序，
+// 遇到维护单元时用上一届mediator签名的换届信息更新活跃mediator，返回验证到最后一个单元时的检查点
+func (cp *Checkpoint) VerifyHeaders(headers []*modules.Header, transitions ...*MediatorSetTransition) (
+	*Checkpoint, error) {
+	if err := cp.Validate(); err != nil {
+		return nil, err
+	}
+	trs := make(map[common.Hash]*MediatorSetTransition, len(transitions))
+	for _, tr := range transitions {
+		trs[tr.UnitHash] = tr
+	}
+	next := cp.copy()
+	for _, h := range headers {
+		if err := next.verifyHeader(h, trs); err != nil {
+			return nil, fmt.Errorf("unit[%d] %s: %v", h.NumberU64(), h.Hash().String(), err)
+		}
+	}
+	return next, nil
+}
+
+func (cp *Checkpoint) verifyHeader(h *modules.Header, trs map[common.Hash]*MediatorSetTransition) error {
+	if h == nil || h.Number == nil {
+		return errors.New("header is nil")
+	}
+	prev := cp.Header
+	if len(h.ParentsHash) == 0 || h.ParentsHash[0] != prev.Hash() {
+		return errors.New("parent hash mismatch")
+	}
+	if h.NumberU64() != prev.NumberU64()+1 {
+		return errors.New("unit index is not continuous")
+	}
+	if h.Time <= prev.Time {
+		return errors.New("invalid unit timestamp")
+	}
+	if err := verifyAuthor(h); err != nil {
+		return err
+	}
+	author := h.Author()
+	slotNum := cp.slotAtTime(h.Time)
+	if slotNum == 0 {
+		return errors.New("invalid unit slot")
+	}
+	if scheduled := cp.scheduledMediator(slotNum); !scheduled.Equal(author) {
+		return fmt.Errorf("mediator %s produced unit at wrong time, scheduled mediator is %s",
+			author.String(), scheduled.String())
+	}
+	var tr *MediatorSetTransition
+	if cp.isMaintenance(h) {
+		tr = trs[h.Hash()]
+		if tr == nil {
+			return ErrTransitionRequired
+		}
+		if err := cp.verifyTransition(tr); err != nil {
+			return err
+		}
+	}
+	cp.apply(h, slotNum, tr)
+	return nil
+}
+
+// VerifyTxProof 验证从检查点开始的单元头链，再验证交易包含在链中的某个单元，
+// 并且该单元已经不可逆：有可信群公钥的群签名，或者之后有足够多的不同mediator生产了单元
+func VerifyTxProof(cp *Checkpoint, headers []*modules.Header, proof *TxProof,
+	transitions ...*MediatorSetTransition) (*modules.Transaction, error) {
+	header, err := cp.verifyStableHeader(headers, proof.UnitHash, transitions)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Verify(header)
+}
+
+// VerifyContractStateProof 验证检查点之后的单元头，以及合约状态在其中某个不可逆单元承诺的合约状态中的证明
+func VerifyContractStateProof(cp *Checkpoint, headers []*modules.Header, proof *ContractStateProof,
+	transitions ...*MediatorSetTransition) (*modules.ContractStateValue, error) {
+	header, err := cp.verifyStableHeader(headers, proof.UnitHash, transitions)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Verify(header)
+}
+
+// 验证检查点之后的单元头，返回其中hash为unitHash的不可逆单元
+func (cp *Checkpoint) verifyStableHeader(headers []*modules.Header, unitHash common.Hash,
+	transitions []*MediatorSetTransition) (*modules.Header, error) {
+	pos := -1
+	for i, h := range headers {
+		if h.Hash() == unitHash {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		if _, err := cp.VerifyHeaders(headers, transitions...); err != nil {
+			return nil, err
+		}
+		return nil, ErrUnitNotInChain
+	}
+	// 用生产该单元时的mediator集合判断单元是否不可逆
+	at, err := cp.VerifyHeaders(headers[:pos], transitions...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := at.VerifyHeaders(headers[pos:], transitions...); err != nil {
+		return nil, err
+	}
+	if !at.verifyGroupSign(headers[pos]) {
+		confirmed := make(map[common.Address]bool)
+		for _, h := range headers[pos:] {
+			confirmed[h.Author()] = true
+		}
+		if len(confirmed) < at.Threshold {
+			return nil, ErrUnitNotStable
+		}
+	}
+	return headers[pos], nil
+}
@@ -0,0 +1,347 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package spv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/hexutil"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/sign/bls"
+)
+
+const testInterval = 3
+
+type testChain struct {
+	t          *testing.T
+	keys       map[common.Address][]byte
+	checkpoint *Checkpoint
+	state      *Checkpoint
+	headers    []*modules.Header
+	units      []*modules.Unit
+
+	// 维护单元之后的活跃mediator，以及对换届信息签名的上一届mediator数量
+	nextMediators []common.Address
+	signers       int
+	transitions   []*MediatorSetTransition
+}
+
+func newTestChain(t *testing.T, mediatorCount int) *testChain {
+	tc := &testChain{t: t, keys: make(map[common.Address][]byte)}
+	mediators := tc.newMediators(mediatorCount)
+	genesis := modules.NewHeader(nil, 0, nil)
+	genesis.Number = modules.NewChainIndex(modules.PTNCOIN, 0)
+	genesis.Time = 1546300800
+	tc.checkpoint = &Checkpoint{
+		Header:               genesis,
+		MediatorInterval:     testInterval,
+		MaintenanceSkipSlots: 2,
+		NextMaintenanceTime:  uint32(genesis.Time + 3600),
+		MaintenanceInterval:  3600,
+		Threshold:            2,
+		ActiveMediators:      mediators,
+		ShuffledMediators:    mediators,
+	}
+	tc.state = tc.checkpoint.copy()
+	tc.nextMediators = mediators
+	tc.signers = 2
+	return tc
+}
+
+func (tc *testChain) newMediators(count int) []common.Address {
+	mediators := make([]common.Address, 0, count)
+	for i := 0; i < count; i++ {
+		prvKey, err := crypto.GenerateKey()
+		assert.Nil(tc.t, err)
+		addr := crypto.PubkeyBytesToAddress(crypto.CompressPubkey(&prvKey.PublicKey))
+		tc.keys[addr] = crypto.FromECDSA(prvKey)
+		mediators = append(mediators, addr)
+	}
+	return mediators
+}
+
+// 维护单元之后由上一届的前signers个mediator对换届信息签名
+func (tc *testChain) transition(h *modules.Header) *MediatorSetTransition {
+	tr := &MediatorSetTransition{
+		UnitHash:             h.Hash(),
+		ActiveMediators:      tc.nextMediators,
+		Threshold:            len(tc.nextMediators)/2 + 1,
+		MediatorInterval:     testInterval,
+		MaintenanceSkipSlots: 2,
+		MaintenanceInterval:  3600,
+	}
+	for _, med := range tc.state.ActiveMediators[:tc.signers] {
+		tc.signTransition(tr, med)
+	}
+	return tr
+}
+
+func (tc *testChain) signTransition(tr *MediatorSetTransition, signer common.Address) {
+	prvKey := tc.keys[signer]
+	pubKey := crypto.CompressPubkey(&crypto.ToECDSAUnsafe(prvKey).PublicKey)
+	err := tr.Sign(pubKey, func(msg []byte) ([]byte, error) { return crypto.MyCryptoLib.Sign(prvKey, msg) })
+	assert.Nil(tc.t, err)
+}
+
+// 由调度的mediator在下一个单元的第slotNum个slot生产单元
+func (tc *testChain) produce(slotNum uint64, txs modules.Transactions) *modules.Header {
+	prev := tc.state.Header
+	h := modules.NewHeader([]common.Hash{prev.Hash()}, 0, nil)
+	h.Number = modules.NewChainIndex(modules.PTNCOIN, prev.NumberU64()+1)
+	h.Time = modules.SlotTime(prev.Time, prev.NumberU64(), tc.state.MediatorInterval, tc.state.MaintenanceSkipSlots,
+		tc.state.MaintenanceFlag, uint32(slotNum))
+	h.TxRoot = core.DeriveSha(txs)
+	tc.sign(h, tc.state.scheduledMediator(slotNum))
+	var tr *MediatorSetTransition
+	if tc.state.isMaintenance(h) {
+		tr = tc.transition(h)
+		tc.transitions = append(tc.transitions, tr)
+	}
+	tc.state.apply(h, slotNum, tr)
+	tc.headers = append(tc.headers, h)
+	tc.units = append(tc.units, modules.NewUnit(h, txs))
+	return h
+}
+
+func (tc *testChain) sign(h *modules.Header, signer common.Address) {
+	prvKey := tc.keys[signer]
+	pubKey := crypto.CompressPubkey(&crypto.ToECDSAUnsafe(prvKey).PublicKey)
+	h.Authors = modules.Authentifier{}
+	sign, err := crypto.MyCryptoLib.Sign(prvKey, h.HashWithoutAuthor().Bytes())
+	assert.Nil(tc.t, err)
+	h.Authors = modules.Authentifier{PubKey: pubKey, Signature: sign}
+}
+
+func newTestTxs(count int) modules.Transactions {
+	txs := modules.Transactions{}
+	for i := 0; i < count; i++ {
+		pay := modules.NewPaymentPayload(nil, []*modules.Output{
+			modules.NewTxOut(uint64(i+1), []byte{byte(i)}, modules.NewPTNAsset())})
+		txs = append(txs, modules.NewTransaction([]*modules.Message{modules.NewMessage(modules.APP_PAYMENT, pay)}))
+	}
+	return txs
+}
+
+func TestVerifyHeaders(t *testing.T) {
+	tc := newTestChain(t, 3)
+	for i := 0; i < 7; i++ {
+		slotNum := uint64(1)
+		if i == 4 {
+			slotNum = 2 //错过一个slot
+		}
+		tc.produce(slotNum, nil)
+	}
+	next, err := tc.checkpoint.VerifyHeaders(tc.headers)
+	assert.Nil(t, err)
+	assert.Equal(t, tc.state.Header.Hash(), next.Header.Hash())
+	assert.Equal(t, uint64(8), next.CurrentASlot)
+	assert.Equal(t, tc.state.ShuffledMediators, next.ShuffledMediators)
+
+	//新的检查点可以继续验证后面的单元
+	tc.produce(1, nil)
+	_, err = next.VerifyHeaders(tc.headers[7:])
+	assert.Nil(t, err)
+
+	//单元不连续
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers[1:])
+	assert.NotNil(t, err)
+
+	//不是调度的mediator生产的单元
+	h := tc.headers[2]
+	for addr := range tc.keys {
+		if !addr.Equal(h.Author()) {
+			tc.sign(h, addr)
+			break
+		}
+	}
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers)
+	assert.NotNil(t, err)
+
+	//签名无效
+	tc.sign(h, tc.state.scheduledMediator(1))
+	h.Authors.Signature[5] ^= 0xff
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers)
+	assert.NotNil(t, err)
+}
+
+func TestVerifyHeaders_Maintenance(t *testing.T) {
+	tc := newTestChain(t, 3)
+	tc.checkpoint.NextMaintenanceTime = uint32(tc.checkpoint.Header.Time + 2*testInterval)
+	tc.state = tc.checkpoint.copy()
+	//维护之后换掉一个mediator
+	tc.nextMediators = append(tc.newMediators(1), tc.checkpoint.ActiveMediators[1:]...)
+	for i := 0; i < 8; i++ {
+		tc.produce(1, nil)
+	}
+	//第2个单元是维护单元，维护之后跳过了一些slot，第3个单元重新洗牌后由新的mediator集合调度
+	assert.Equal(t, 1, len(tc.transitions))
+	assert.Equal(t, tc.headers[1].Hash(), tc.transitions[0].UnitHash)
+	assert.Equal(t, int64(3*testInterval), tc.headers[2].Time-tc.headers[1].Time)
+	assert.ElementsMatch(t, tc.nextMediators, tc.state.ShuffledMediators)
+
+	//没有换届信息时不能跨过维护单元
+	_, err := tc.checkpoint.VerifyHeaders(tc.headers[:1])
+	assert.Nil(t, err)
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers)
+	assert.NotNil(t, err)
+
+	next, err := tc.checkpoint.VerifyHeaders(tc.headers, tc.transitions...)
+	assert.Nil(t, err)
+	assert.Equal(t, tc.state.Header.Hash(), next.Header.Hash())
+	assert.Equal(t, tc.nextMediators, next.ActiveMediators)
+	assert.Equal(t, tc.state.ShuffledMediators, next.ShuffledMediators)
+	assert.Equal(t, tc.state.NextMaintenanceTime, next.NextMaintenanceTime)
+	assert.True(t, next.NextMaintenanceTime > uint32(next.Header.Time))
+
+	//换届信息被篡改
+	tr := *tc.transitions[0]
+	tr.ActiveMediators = tc.newMediators(3)
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, &tr)
+	assert.NotNil(t, err)
+
+	//签名的上一届mediator不足Threshold个，新的mediator的签名不算
+	tr = *tc.transitions[0]
+	tr.Signatures = []modules.Authentifier{tr.Signatures[0]}
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, &tr)
+	assert.NotNil(t, err)
+	tc.signTransition(&tr, tc.nextMediators[0])
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, &tr)
+	assert.NotNil(t, err)
+	tc.signTransition(&tr, tc.checkpoint.ActiveMediators[0])
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, &tr)
+	assert.NotNil(t, err)
+	tc.signTransition(&tr, tc.checkpoint.ActiveMediators[2])
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, &tr)
+	assert.Nil(t, err)
+}
+
+func TestVerifyHeaders_TransitionGroupSign(t *testing.T) {
+	tc := newTestChain(t, 3)
+	tc.checkpoint.NextMaintenanceTime = uint32(tc.checkpoint.Header.Time + testInterval)
+	tc.state = tc.checkpoint.copy()
+	tc.signers = 0
+	for i := 0; i < 3; i++ {
+		tc.produce(1, nil)
+	}
+	_, err := tc.checkpoint.VerifyHeaders(tc.headers, tc.transitions...)
+	assert.NotNil(t, err)
+
+	//上一届mediator对换届信息的群签名
+	suite := bn256.NewSuiteG2()
+	prvKey, pubKey := bls.NewKeyPair(suite, suite.RandomStream())
+	tr := tc.transitions[0]
+	tr.GroupPubKey, _ = pubKey.MarshalBinary()
+	tr.GroupSign, err = bls.Sign(suite, prvKey, tr.Hash().Bytes())
+	assert.Nil(t, err)
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, tr)
+	assert.NotNil(t, err)
+
+	tc.checkpoint.GroupPubKeys = map[string]hexutil.Bytes{tc.checkpoint.ActiveMediators[0].String(): tr.GroupPubKey}
+	_, err = tc.checkpoint.VerifyHeaders(tc.headers, tr)
+	assert.Nil(t, err)
+}
+
+func TestVerifyTxProof(t *testing.T) {
+	tc := newTestChain(t, 3)
+	tc.produce(1, newTestTxs(1))
+	tc.produce(1, newTestTxs(20))
+	unit := tc.units[1]
+
+	proof, err := NewTxProof(unit, 13)
+	assert.Nil(t, err)
+	//证明可以通过json传递
+	data, err := json.Marshal(proof)
+	assert.Nil(t, err)
+	proof = &TxProof{}
+	assert.Nil(t, json.Unmarshal(data, proof))
+
+	tx, err := proof.Verify(unit.UnitHeader)
+	assert.Nil(t, err)
+	assert.Equal(t, unit.Txs[13].Hash(), tx.Hash())
+
+	//只有一个mediator确认，单元还不是不可逆的
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, proof)
+	assert.Equal(t, ErrUnitNotStable, err)
+	tc.produce(1, nil)
+	tx, err = VerifyTxProof(tc.checkpoint, tc.headers, proof)
+	assert.Nil(t, err)
+	assert.Equal(t, unit.Txs[13].Hash(), tx.Hash())
+
+	//篡改交易或者证明
+	bad := *proof
+	bad.TxIndex = 12
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, &bad)
+	assert.NotNil(t, err)
+	bad = *proof
+	bad.Tx = append(hexutil.Bytes{}, proof.Tx...)
+	bad.Tx[len(bad.Tx)-1] ^= 0xff
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, &bad)
+	assert.NotNil(t, err)
+	bad = *proof
+	bad.UnitHash = tc.headers[0].Hash()
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, &bad)
+	assert.NotNil(t, err)
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers[:1], proof)
+	assert.Equal(t, ErrUnitNotInChain, err)
+}
+
+func TestVerifyTxProof_GroupSign(t *testing.T) {
+	tc := newTestChain(t, 3)
+	tc.produce(1, nil)
+	h := tc.produce(1, newTestTxs(3))
+	proof, err := NewTxProof(tc.units[1], 2)
+	assert.Nil(t, err)
+
+	suite := bn256.NewSuiteG2()
+	prvKey, pubKey := bls.NewKeyPair(suite, suite.RandomStream())
+	h.GroupPubKey, _ = pubKey.MarshalBinary()
+	h.GroupSign, err = bls.Sign(suite, prvKey, h.Hash().Bytes())
+	assert.Nil(t, err)
+
+	//群公钥不可信时不采信群签名
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, proof)
+	assert.Equal(t, ErrUnitNotStable, err)
+
+	tc.checkpoint.GroupPubKeys = map[string]hexutil.Bytes{h.Author().String(): h.GroupPubKey}
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, proof)
+	assert.Nil(t, err)
+
+	h.GroupSign[3] ^= 0xff
+	_, err = VerifyTxProof(tc.checkpoint, tc.headers, proof)
+	assert.Equal(t, ErrUnitNotStable, err)
+}
+
+func TestShuffleMediators(t *testing.T) {
+	mediators := make([]common.Address, 0, 21)
+	for i := 0; i < 21; i++ {
+		mediators = append(mediators, crypto.PubkeyBytesToAddress([]byte{byte(i)}))
+	}
+	shuffled := make([]common.Address, len(mediators))
+	copy(shuffled, mediators)
+	modules.ShuffleMediators(shuffled, 0x1234567890abcdef)
+	assert.NotEqual(t, mediators, shuffled)
+	assert.ElementsMatch(t, mediators, shuffled)
+}
@@ -0,0 +1,184 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package light
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/p2p"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/light/spv"
+)
+
+// 一次请求最多包含的outpoint数量
+const maxUtxoProofs = 64
+
+type utxoProofData struct {
+	OutPoint modules.OutPoint
+	Proof    [][]byte
+}
+
+// 同一个单元头承诺的UTXO集合中的一批证明
+type utxoProofsData struct {
+	Header *modules.Header
+	Proofs []utxoProofData
+}
+
+type utxoProofResult struct {
+	proof *spv.UtxoProof
+	err   error
+}
+
+// 轻节点等待中的UTXO证明请求
+type UtxoProofSync struct {
+	reqs map[modules.OutPoint]chan *utxoProofResult
+	lock sync.Mutex
+}
+
+func NewUtxoProofSync() *UtxoProofSync {
+	return &UtxoProofSync{reqs: make(map[modules.OutPoint]chan *utxoProofResult)}
+}
+
+func (s *UtxoProofSync) add(outpoint modules.OutPoint) (chan *utxoProofResult, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.reqs[outpoint]; ok {
+		return nil, fmt.Errorf("utxo proof of %s is requesting", outpoint.String())
+	}
+	ch := make(chan *utxoProofResult, 1)
+	s.reqs[outpoint] = ch
+	return ch, nil
+}
+
+func (s *UtxoProofSync) forget(outpoint modules.OutPoint) {
+	s.lock.Lock()
+	delete(s.reqs, outpoint)
+	s.lock.Unlock()
+}
+
+func (s *UtxoProofSync) deliver(outpoint modules.OutPoint, result *utxoProofResult) {
+	s.lock.Lock()
+	ch, ok := s.reqs[outpoint]
+	delete(s.reqs, outpoint)
+	s.lock.Unlock()
+	if ok {
+		ch <- result
+	}
+}
+
+// 从随机的全节点获取outpoint的证明，并根据本地的单元头验证
+func (pm *ProtocolManager) GetUtxoProof(outpoint *modules.OutPoint) (*spv.UtxoProof, error) {
+	peers := pm.peers.AllPeers(pm.assetId)
+	if len(peers) <= 0 {
+		return nil, errors.New(CodeEmptyPeers)
+	}
+	ch, err := pm.utxoproofs.add(*outpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer pm.utxoproofs.forget(*outpoint)
+
+	rand.Seed(time.Now().UnixNano())
+	p := peers[rand.Intn(len(peers))]
+	if err := p.RequestUtxoProofs([]modules.OutPoint{*outpoint}); err != nil {
+		return nil, err
+	}
+
+	timeout := time.NewTimer(spvReqTimeout)
+	defer timeout.Stop()
+	select {
+	case result := <-ch:
+		return result.proof, result.err
+	case <-timeout.C:
+		return nil, errors.New(CodeTimeout)
+	}
+}
+
+func (pm *ProtocolManager) GetUtxoProofsMsg(msg p2p.Msg, p *peer) error {
+	if pm.server == nil {
+		return errors.New("this node can not service with utxo proof server")
+	}
+	var outpoints []modules.OutPoint
+	if err := msg.Decode(&outpoints); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if len(outpoints) > maxUtxoProofs {
+		outpoints = outpoints[:maxUtxoProofs]
+	}
+
+	resp := &utxoProofsData{}
+	for i := range outpoints {
+		header, proof, err := pm.dag.GetUtxoProof(&outpoints[i])
+		if err != nil {
+			log.Debug("Light PalletOne", "ProtocolManager->GetUtxoProofsMsg GetUtxoProof err", err)
+			break
+		}
+		//稳定单元变化时，后面的证明由轻节点重新请求
+		if resp.Header == nil {
+			resp.Header = header
+		} else if resp.Header.Hash() != header.Hash() {
+			break
+		}
+		resp.Proofs = append(resp.Proofs, utxoProofData{OutPoint: outpoints[i], Proof: proof})
+	}
+	if resp.Header == nil {
+		resp.Header = pm.dag.CurrentHeader(pm.assetId)
+	}
+	return p.SendUtxoProofs(resp)
+}
+
+func (pm *ProtocolManager) UtxoProofsMsg(msg p2p.Msg, p *peer) error {
+	if pm.server != nil {
+		return errors.New("this is server node")
+	}
+	var resp utxoProofsData
+	if err := msg.Decode(&resp); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if len(resp.Proofs) == 0 {
+		return nil
+	}
+
+	//只采信本地已经同步并验证过的单元头
+	hash := resp.Header.Hash()
+	header, err := pm.dag.GetHeaderByHash(hash)
+	if err != nil {
+		for _, data := range resp.Proofs {
+			pm.utxoproofs.deliver(data.OutPoint, &utxoProofResult{
+				err: fmt.Errorf("unit %s of utxo proof is unknown", hash.String())})
+		}
+		return nil
+	}
+	for _, data := range resp.Proofs {
+		if _, err := spv.VerifyUtxoProof(header, &data.OutPoint, data.Proof); err != nil {
+			log.Debug("Light PalletOne", "ProtocolManager->UtxoProofsMsg invalid proof", err, "p.id", p.id)
+			pm.utxoproofs.deliver(data.OutPoint, &utxoProofResult{err: err})
+			return errResp(ErrDecode, "invalid utxo proof of %s: %v", data.OutPoint.String(), err)
+		}
+		proof := spv.NewUtxoProof(header, &data.OutPoint, data.Proof)
+		pm.utxoproofs.deliver(data.OutPoint, &utxoProofResult{proof: proof})
+	}
+	return nil
+}
@@ -46,6 +46,7 @@ import (
 	"github.com/palletone/go-palletone/dag/txspool"
 	"github.com/palletone/go-palletone/internal/ptnapi"
 	"github.com/palletone/go-palletone/light/les"
+	"github.com/palletone/go-palletone/light/spv"
 	"github.com/palletone/go-palletone/ptn/downloader"
 	"github.com/palletone/go-palletone/ptnjson"
 	"github.com/palletone/go-palletone/ptnjson/statistics"
@@ -668,7 +669,7 @@ func (b *PtnApiBackend) ContractInvoke(deployId []byte, txid string, args [][]by
 	timeout time.Duration) ([]byte, error) {
 	log.Debugf("======>ContractInvoke:deployId[%s]txid[%s]", hex.EncodeToString(deployId), txid)
 	//channelId := "palletone"
-	unit, err := b.ptn.contract.Invoke(rwset.RwM, channelId, deployId, txid, args, timeout)
+	unit, err := b.ptn.contract.Invoke(rwset.RwM, channelId, deployId, txid, args, timeout, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -677,7 +678,7 @@ func (b *PtnApiBackend) ContractInvoke(deployId []byte, txid string, args [][]by
 
 func (b *PtnApiBackend) ContractQuery(contractId []byte, txid string, args [][]byte,
 	timeout time.Duration) (rspPayload []byte, err error) {
-	rsp, err := b.ptn.contract.Invoke(rwset.RwM, rwset.ChainId, contractId, txid, args, timeout)
+	rsp, err := b.ptn.contract.Invoke(rwset.RwM, rwset.ChainId, contractId, txid, args, timeout, 0)
 	rwset.RwM.CloseTxSimulator(rwset.ChainId, txid)
 	rwset.RwM.Close()
 
@@ -904,6 +905,14 @@ func (b *PtnApiBackend) SyncUTXOByAddr(addr string) string {
 	return "Error"
 }
 
+func (b *PtnApiBackend) GetUtxoProof(outpoint *modules.OutPoint) (*spv.UtxoProof, error) {
+	header, proof, err := b.ptn.dag.GetUtxoProof(outpoint)
+	if err != nil {
+		return nil, err
+	}
+	return spv.NewUtxoProof(header, outpoint, proof), nil
+}
+
 func (b *PtnApiBackend) StartCorsSync() (string, error) {
 	if b.ptn.corsServer != nil {
 		return b.ptn.corsServer.StartCorsSync()
@@ -152,6 +152,9 @@ func New(ctx *node.ServiceContext, config *Config, cache palletcache.ICache) (*P
 		return nil, err
 	}
 
+	if config.Jury.OutChainKeyStore != "" {
+		config.Jury.OutChainKeyStore = ctx.ResolvePath(config.Jury.OutChainKeyStore)
+	}
 	ptn.contractPorcessor, err = jury.NewContractProcessor(ptn, dag, nil, &config.Jury)
 	if err != nil {
 		log.Error("contract processor creat:", "error", err)
@@ -0,0 +1,337 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package ptn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/p2p"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/palletone/go-palletone/validator"
+)
+
+//压缩单元：单元头加上交易的短ID，接收方用本地交易池中的交易恢复单元，
+//缺少的交易向发送方请求，恢复失败时通过fetcher下载完整的单元
+const (
+	maxPendingCompactUnits = 64               // 等待缺少交易的压缩单元的最大数量
+	compactUnitTimeout     = 10 * time.Second // 等待缺少交易的最长时间
+	recentUnitsCacheSize   = 32               // 缓存最近广播的单元，用于响应缺少交易的请求
+)
+
+//prefilledTx 直接附带在压缩单元中的交易，Index是交易在单元中的序号
+type prefilledTx struct {
+	Index uint32
+	Tx    *modules.Transaction
+}
+
+//compactUnitData is the network packet for the compact unit propagation message.
+//按顺序依次填入Prefilled之外的位置
+type compactUnitData struct {
+	Header    *modules.Header
+	ShortIds  []uint64
+	Prefilled []*prefilledTx
+}
+
+//getUnitTxsData is the network packet for requesting missing transactions of a compact unit.
+type getUnitTxsData struct {
+	Hash    common.Hash
+	Indexes []uint32
+}
+
+//unitTxsData is the network packet for the transactions of a compact unit.
+type unitTxsData struct {
+	Hash common.Hash
+	Txs  []*modules.Transaction
+}
+
+//交易的短ID，以单元hash为key，防止预先构造ID冲突的交易
+func shortTxId(unitHash, txHash common.Hash) uint64 {
+	return binary.BigEndian.Uint64(crypto.Keccak256(unitHash.Bytes(), txHash.Bytes())[:8])
+}
+
+//newCompactUnit 创建压缩单元，coinbase和对方可能没有的交易直接附带
+func newCompactUnit(unit *modules.Unit, known func(hash common.Hash) bool) *compactUnitData {
+	hash := unit.Hash()
+	cu := &compactUnitData{Header: unit.Header()}
+	for i, tx := range unit.Txs {
+		txHash := tx.Hash()
+		if i == 0 || !known(txHash) {
+			cu.Prefilled = append(cu.Prefilled, &prefilledTx{Index: uint32(i), Tx: tx})
+			continue
+		}
+		cu.ShortIds = append(cu.ShortIds, shortTxId(hash, txHash))
+	}
+	return cu
+}
+
+//partialUnit 正在恢复的压缩单元，requestedFrom是请求缺少交易的节点，只接受该节点的回复
+type partialUnit struct {
+	header        *modules.Header
+	txs           []*modules.Transaction
+	missing       []uint32
+	receivedAt    time.Time
+	requestedFrom string
+}
+
+//newPartialUnit 用交易池中的交易恢复压缩单元，pool中短ID冲突的交易为nil，当作缺少的交易
+func newPartialUnit(cu *compactUnitData, pool map[uint64]*modules.Transaction) (*partialUnit, error) {
+	if cu.Header == nil || cu.Header.Number == nil {
+		return nil, fmt.Errorf("compact unit without header")
+	}
+	count := len(cu.ShortIds) + len(cu.Prefilled)
+	txs := make([]*modules.Transaction, count)
+	for _, p := range cu.Prefilled {
+		if p == nil || p.Tx == nil {
+			return nil, fmt.Errorf("nil prefilled transaction")
+		}
+		if int(p.Index) >= count || txs[p.Index] != nil {
+			return nil, fmt.Errorf("invalid prefilled transaction index %d", p.Index)
+		}
+		txs[p.Index] = p.Tx
+	}
+	pu := &partialUnit{header: cu.Header, txs: txs}
+	j := 0
+	for i := range txs {
+		if txs[i] != nil {
+			continue
+		}
+		if tx := pool[cu.ShortIds[j]]; tx != nil {
+			txs[i] = tx
+		} else {
+			pu.missing = append(pu.missing, uint32(i))
+		}
+		j++
+	}
+	return pu, nil
+}
+
+//fill 填入向发送方请求到的交易，顺序与missing相同
+func (pu *partialUnit) fill(txs []*modules.Transaction) error {
+	if len(txs) != len(pu.missing) {
+		return fmt.Errorf("expect %d transactions, got %d", len(pu.missing), len(txs))
+	}
+	for i, idx := range pu.missing {
+		if txs[i] == nil {
+			return fmt.Errorf("transaction %d is nil", idx)
+		}
+		pu.txs[idx] = txs[i]
+	}
+	pu.missing = nil
+	return nil
+}
+
+//unit 恢复出完整的单元，交易的Merkle根必须与单元头一致，否则可能是短ID冲突
+func (pu *partialUnit) unit() (*modules.Unit, error) {
+	if len(pu.missing) > 0 {
+		return nil, fmt.Errorf("%d transactions missing", len(pu.missing))
+	}
+	txs := modules.Transactions(pu.txs)
+	if root := core.DeriveSha(txs); root != pu.header.TxRoot {
+		return nil, fmt.Errorf("tx root mismatch, expect %s, got %s", pu.header.TxRoot.String(), root.String())
+	}
+	return modules.NewUnit(pu.header, txs), nil
+}
+
+//compactUnitSet 等待缺少交易的压缩单元
+type compactUnitSet struct {
+	lock    sync.Mutex
+	pending map[common.Hash]*partialUnit
+}
+
+func newCompactUnitSet() *compactUnitSet {
+	return &compactUnitSet{pending: make(map[common.Hash]*partialUnit)}
+}
+
+func (s *compactUnitSet) add(hash common.Hash, pu *partialUnit) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for h, p := range s.pending {
+		if time.Since(p.receivedAt) > compactUnitTimeout {
+			delete(s.pending, h)
+		}
+	}
+	//超过数量时丢弃最早的
+	for len(s.pending) >= maxPendingCompactUnits {
+		var oldest common.Hash
+		var oldestTime time.Time
+		for h, p := range s.pending {
+			if oldestTime.IsZero() || p.receivedAt.Before(oldestTime) {
+				oldest, oldestTime = h, p.receivedAt
+			}
+		}
+		delete(s.pending, oldest)
+	}
+	s.pending[hash] = pu
+}
+
+//取出向peer请求了缺少交易的压缩单元，其他节点发来的交易不影响等待中的单元
+func (s *compactUnitSet) take(hash common.Hash, peer string) *partialUnit {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	pu, ok := s.pending[hash]
+	if !ok || pu.requestedFrom != peer {
+		return nil
+	}
+	delete(s.pending, hash)
+	if time.Since(pu.receivedAt) > compactUnitTimeout {
+		return nil
+	}
+	return pu
+}
+
+//按短ID索引交易池中的交易，ID冲突的交易都不使用
+func (pm *ProtocolManager) txpoolShortIds(unitHash common.Hash) map[uint64]*modules.Transaction {
+	pool := pm.txpool.AllTxpoolTxs()
+	ids := make(map[uint64]*modules.Transaction, len(pool))
+	for hash, ptx := range pool {
+		if ptx == nil || ptx.Tx == nil {
+			continue
+		}
+		id := shortTxId(unitHash, hash)
+		if _, ok := ids[id]; ok {
+			ids[id] = nil
+			continue
+		}
+		ids[id] = ptx.Tx
+	}
+	return ids
+}
+
+func (pm *ProtocolManager) CompactUnitMsg(msg p2p.Msg, p *peer) error {
+	var data compactUnitData
+	if err := msg.Decode(&data); err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	if data.Header == nil || data.Header.Number == nil {
+		return errResp(ErrDecode, "compact unit without header")
+	}
+	if err := checkUnitTimestamp(data.Header); err != nil {
+		return err
+	}
+	//先做不依赖交易的检查，伪造的单元头不会触发交易池的计算和交易请求
+	if err := pm.checkCompactUnitHeader(data.Header); err != nil {
+		log.Debugf("Compact unit #%v from peer %s rejected: %s", data.Header.NumberU64(), p.id, err.Error())
+		p.Penalize(p2p.MisbehaviorInvalidHeader)
+		return nil
+	}
+	hash := data.Header.Hash()
+	p.MarkUnit(hash)
+	if pm.IsExistInCache(hash.Bytes()) {
+		p.SetHead(hash, data.Header.Number, nil)
+		return nil
+	}
+
+	pu, err := newPartialUnit(&data, pm.txpoolShortIds(hash))
+	if err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	pu.receivedAt = msg.ReceivedAt
+	log.Debugf("Received compact unit(%v) #%v, txs:%d, prefilled:%d, missing:%d", hash.TerminalString(),
+		data.Header.NumberU64(), len(pu.txs), len(data.Prefilled), len(pu.missing))
+	if len(pu.missing) == 0 {
+		return pm.completeCompactUnit(pu, p)
+	}
+	pu.requestedFrom = p.id
+	pm.compactUnits.add(hash, pu)
+	return p.RequestUnitTxs(hash, pu.missing)
+}
+
+//压缩单元的作者签名必须正确，并且作者是mediator
+func (pm *ProtocolManager) checkCompactUnitHeader(header *modules.Header) error {
+	if err := validator.ValidateUnitSignature(header); err != nil {
+		return err
+	}
+	if !pm.dag.IsMediator(header.Author()) {
+		return fmt.Errorf("author %s is not a mediator", header.Author().Str())
+	}
+	return nil
+}
+
+func (pm *ProtocolManager) GetUnitTxsMsg(msg p2p.Msg, p *peer) error {
+	var req getUnitTxsData
+	if err := msg.Decode(&req); err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	var unit *modules.Unit
+	if cached, ok := pm.recentUnits.Get(req.Hash); ok {
+		unit = cached.(*modules.Unit)
+	} else if u, err := pm.dag.GetUnitByHash(req.Hash); err == nil {
+		unit = u
+	}
+	//没有这个单元时返回空的交易列表，对方会下载完整的单元
+	txs := make([]*modules.Transaction, 0, len(req.Indexes))
+	if unit != nil {
+		if len(req.Indexes) > len(unit.Txs) {
+			return errResp(ErrDecode, "request %d transactions of unit with %d", len(req.Indexes), len(unit.Txs))
+		}
+		for _, idx := range req.Indexes {
+			if int(idx) >= len(unit.Txs) {
+				return errResp(ErrDecode, "transaction index %d out of range", idx)
+			}
+			txs = append(txs, unit.Txs[idx])
+		}
+	}
+	return p.SendUnitTxs(req.Hash, txs)
+}
+
+func (pm *ProtocolManager) UnitTxsMsg(msg p2p.Msg, p *peer) error {
+	var data unitTxsData
+	if err := msg.Decode(&data); err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	pu := pm.compactUnits.take(data.Hash, p.id)
+	if pu == nil {
+		log.Debugf("Received transactions of unknown compact unit(%v) from peer %s", data.Hash.TerminalString(),
+			p.id)
+		p.Penalize(p2p.MisbehaviorUnrequested)
+		return nil
+	}
+	if err := pu.fill(data.Txs); err != nil {
+		log.Debugf("Compact unit(%v) fill transactions error:%s", data.Hash.TerminalString(), err.Error())
+		pm.fetchFullUnit(pu, p)
+		return nil
+	}
+	return pm.completeCompactUnit(pu, p)
+}
+
+func (pm *ProtocolManager) completeCompactUnit(pu *partialUnit, p *peer) error {
+	unit, err := pu.unit()
+	if err != nil {
+		log.Debugf("Compact unit(%v) reconstruct error:%s", pu.header.Hash().TerminalString(), err.Error())
+		pm.fetchFullUnit(pu, p)
+		return nil
+	}
+	return pm.processNewUnit(unit, pu.receivedAt, p)
+}
+
+//恢复失败时通过fetcher下载完整的单元
+func (pm *ProtocolManager) fetchFullUnit(pu *partialUnit, p *peer) {
+	hash := pu.header.Hash()
+	if err := pm.fetcher.Notify(p.id, hash, pu.header.Number, time.Now(), p.RequestOneHeader,
+		p.RequestBodies); err != nil {
+		log.Debugf("Fetch full unit(%v) error:%s", hash.TerminalString(), err.Error())
+	}
+}
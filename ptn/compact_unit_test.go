@@ -0,0 +1,135 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package ptn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/dag/modules"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCompactTestUnit(count int) *modules.Unit {
+	txs := modules.Transactions{}
+	for i := 0; i < count; i++ {
+		out := modules.NewTxOut(uint64(i+1), []byte{}, modules.NewPTNAsset())
+		pay := modules.NewPaymentPayload(nil, []*modules.Output{out})
+		txs = append(txs, modules.NewTransaction([]*modules.Message{modules.NewMessage(modules.APP_PAYMENT, pay)}))
+	}
+	header := &modules.Header{
+		Number: &modules.ChainIndex{AssetID: modules.PTNCOIN, Index: 10},
+		TxRoot: core.DeriveSha(txs),
+		Time:   time.Now().Unix(),
+	}
+	return modules.NewUnit(header, txs)
+}
+
+func TestCompactUnit(t *testing.T) {
+	unit := newCompactTestUnit(5)
+	hash := unit.Hash()
+	//对方知道第1、2、4个交易
+	known := map[common.Hash]bool{unit.Txs[1].Hash(): true, unit.Txs[2].Hash(): true, unit.Txs[4].Hash(): true}
+	cu := newCompactUnit(unit, func(h common.Hash) bool { return known[h] })
+	assert.Equal(t, 3, len(cu.ShortIds))
+	assert.Equal(t, 2, len(cu.Prefilled))
+	assert.EqualValues(t, 0, cu.Prefilled[0].Index)
+	assert.EqualValues(t, 3, cu.Prefilled[1].Index)
+
+	data, err := rlp.EncodeToBytes(cu)
+	assert.Nil(t, err)
+	decoded := &compactUnitData{}
+	assert.Nil(t, rlp.DecodeBytes(data, decoded))
+	assert.Equal(t, hash, decoded.Header.Hash())
+
+	//交易池中只有第1、4个交易
+	pool := map[uint64]*modules.Transaction{
+		shortTxId(hash, unit.Txs[1].Hash()): unit.Txs[1],
+		shortTxId(hash, unit.Txs[4].Hash()): unit.Txs[4],
+	}
+	pu, err := newPartialUnit(decoded, pool)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{2}, pu.missing)
+	_, err = pu.unit()
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, pu.fill(nil))
+	assert.Nil(t, pu.fill([]*modules.Transaction{unit.Txs[2]}))
+	u, err := pu.unit()
+	assert.Nil(t, err)
+	assert.Equal(t, hash, u.Hash())
+	assert.Equal(t, 5, len(u.Txs))
+	for i, tx := range u.Txs {
+		assert.Equal(t, unit.Txs[i].Hash(), tx.Hash())
+	}
+}
+
+func TestCompactUnitTxRootMismatch(t *testing.T) {
+	unit := newCompactTestUnit(3)
+	other := newCompactTestUnit(4)
+	hash := unit.Hash()
+	cu := newCompactUnit(unit, func(h common.Hash) bool { return true })
+	//短ID对应到错误的交易时，Merkle根不一致
+	pool := map[uint64]*modules.Transaction{
+		shortTxId(hash, unit.Txs[1].Hash()): unit.Txs[1],
+		shortTxId(hash, unit.Txs[2].Hash()): other.Txs[3],
+	}
+	pu, err := newPartialUnit(cu, pool)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pu.missing))
+	_, err = pu.unit()
+	assert.NotNil(t, err)
+}
+
+func TestCompactUnitInvalid(t *testing.T) {
+	unit := newCompactTestUnit(2)
+	cu := newCompactUnit(unit, func(h common.Hash) bool { return false })
+	cu.Prefilled[1].Index = 5
+	_, err := newPartialUnit(cu, nil)
+	assert.NotNil(t, err)
+	cu.Prefilled[1].Index = 0
+	_, err = newPartialUnit(cu, nil)
+	assert.NotNil(t, err)
+	_, err = newPartialUnit(&compactUnitData{}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestCompactUnitSet(t *testing.T) {
+	set := newCompactUnitSet()
+	for i := 0; i < maxPendingCompactUnits+1; i++ {
+		set.add(common.BytesToHash([]byte{byte(i)}),
+			&partialUnit{receivedAt: time.Now().Add(time.Duration(i) * time.Millisecond)})
+	}
+	assert.Equal(t, maxPendingCompactUnits, len(set.pending))
+	//最早的被丢弃
+	assert.Nil(t, set.take(common.BytesToHash([]byte{0}), ""))
+	assert.NotNil(t, set.take(common.BytesToHash([]byte{1}), ""))
+	assert.Nil(t, set.take(common.BytesToHash([]byte{1}), ""))
+
+	set.add(common.Hash{}, &partialUnit{receivedAt: time.Now().Add(-compactUnitTimeout - time.Second)})
+	assert.Nil(t, set.take(common.Hash{}, ""))
+
+	//只接受请求过的节点发来的交易，其他节点的回复不影响等待中的单元
+	set.add(common.Hash{}, &partialUnit{receivedAt: time.Now(), requestedFrom: "peer1"})
+	assert.Nil(t, set.take(common.Hash{}, "peer2"))
+	assert.NotNil(t, set.take(common.Hash{}, "peer1"))
+}
@@ -39,6 +39,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/fsouza/go-dockerclient"
+	"github.com/hashicorp/golang-lru"
 	"github.com/palletone/go-palletone/common/crypto"
 	util2 "github.com/palletone/go-palletone/common/util"
 	"github.com/palletone/go-palletone/contracts/comm"
@@ -151,6 +152,12 @@ type ProtocolManager struct {
 
 	toGroupSignCh  chan modules.ToGroupSignEvent
 	toGroupSignSub event.Subscription
+
+	//压缩单元
+	compactUnits *compactUnitSet
+	recentUnits  *lru.Cache
+
+	txFetcher *txFetcher
 }
 
 // NewProtocolManager returns a new PalletOne sub protocol manager. The PalletOne sub protocol manages peers capable
@@ -178,7 +185,10 @@ func NewProtocolManager(mode downloader.SyncMode, networkId uint64, gasToken mod
 		contractProc:   contractProc,
 		lightSync:      uint32(1),
 		receivedCache:  freecache.NewCache(5 * 1024 * 1024),
+		compactUnits:   newCompactUnitSet(),
 	}
+	manager.recentUnits, _ = lru.New(recentUnitsCacheSize)
+	manager.txFetcher = newTxFetcher(manager.requestTxs, manager.txFetchTimeout)
 	symbol, _, _, _, _ := gasToken.ParseAssetId()
 	protocolName := symbol
 	//asset, err := modules.NewAsset(strings.ToUpper(gasToken), modules.AssetType_FungibleToken,
@@ -241,7 +251,8 @@ func NewProtocolManager(mode downloader.SyncMode, networkId uint64, gasToken mod
 	}
 
 	// Construct the different synchronization mechanisms
-	manager.downloader = downloader.New(mode, manager.eventMux, manager.removePeer, nil, dag, txpool)
+	manager.downloader = downloader.New(mode, manager.eventMux, manager.misbehavingPeerDropper(p2p.MisbehaviorTimeout),
+		nil, dag, txpool)
 	manager.fetcher = manager.newFetcher()
 
 	//manager.lightdownloader = downloader.New(downloader.LightSync, manager.eventMux, nil, nil, dag, txpool)
@@ -310,7 +321,23 @@ func (pm *ProtocolManager) newFetcher() *fetcher.Fetcher {
 		return account, err
 
 	}
-	return fetcher.New(pm.dag.IsHeaderExist, validatorFn, pm.BroadcastUnit, heighter, inserter, pm.removePeer)
+	return fetcher.New(pm.dag.IsHeaderExist, validatorFn, pm.BroadcastUnit, heighter, inserter,
+		pm.misbehavingPeerDropper(p2p.MisbehaviorInvalidUnit))
+}
+
+//penalizePeer 记录节点的不当行为，分数过高时节点被禁止连接
+func (pm *ProtocolManager) penalizePeer(id string, m p2p.Misbehavior) {
+	if p := pm.peers.Peer(id); p != nil {
+		p.Penalize(m)
+	}
+}
+
+//misbehavingPeerDropper 下载器和fetcher断开节点时先记录不当行为
+func (pm *ProtocolManager) misbehavingPeerDropper(m p2p.Misbehavior) func(id string) {
+	return func(id string) {
+		pm.penalizePeer(id, m)
+		pm.removePeer(id)
+	}
 }
 
 func (pm *ProtocolManager) removePeer(id string) {
@@ -323,6 +350,7 @@ func (pm *ProtocolManager) removePeer(id string) {
 
 	// Unregister the peer from the downloader and PalletOne peer set
 	pm.downloader.UnregisterPeer(id)
+	pm.txFetcher.dropPeer(id)
 	//pm.lightdownloader.UnregisterPeer(id)
 	if err := pm.peers.Unregister(id); err != nil {
 		log.Error("Peer removal failed", "peer", id, "err", err)
@@ -354,6 +382,7 @@ func (pm *ProtocolManager) Start(srvr *p2p.Server, maxPeers int, syncCh chan boo
 	pm.txSub = pm.txpool.SubscribeTxPreEvent(pm.txCh)
 	// 启动广播的goroutine
 	go pm.txBroadcastLoop()
+	go pm.txFetcher.loop()
 
 	// append by Albert·Gou
 	// broadcast new unit produced by mediator
@@ -404,8 +433,8 @@ func (pm *ProtocolManager) Start(srvr *p2p.Server, maxPeers int, syncCh chan boo
 		pm.ceSub = pm.consEngine.SubscribeCeEvent(pm.ceCh)
 		go pm.ceBroadcastLoop()
 	}
-	//  是否为linux系統
-	if runtime.GOOS == "linux" {
+	//  是否为linux系統，用户合约以子进程方式运行时不需要docker
+	if runtime.GOOS == "linux" && !contractcfg.GetConfig().IsProcessVm() {
 		//创建 docker client
 		client, err := util.NewDockerClient()
 		if err != nil {
@@ -483,6 +512,7 @@ func (pm *ProtocolManager) Stop() {
 
 	// Quit fetcher, txsyncLoop.
 	close(pm.quitSync)
+	pm.txFetcher.stop()
 
 	// Disconnect existing sessions.
 	// This also closes the gate for any new registrations on the peer set.
@@ -555,6 +585,11 @@ func (pm *ProtocolManager) LocalHandle(p *peer) error {
 		return err
 	}
 	defer pm.removePeer(p.id)
+	//ptn/2节点之间先公告交易hash
+	if p.version >= ptn2 {
+		go p.announceTxLoop()
+		defer p.close()
+	}
 
 	// Register the peer in the downloader. If the downloader considers it banned, we disconnect
 	if err := pm.downloader.RegisterPeer(p.id, p.version, p); err != nil {
@@ -576,12 +611,18 @@ func (pm *ProtocolManager) LocalHandle(p *peer) error {
 
 // handleMsg is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
-func (pm *ProtocolManager) handleMsg(p *peer) error {
+func (pm *ProtocolManager) handleMsg(p *peer) (err error) {
 	// Read the next message from the remote peer, and ensure it's fully consumed
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
 	}
+	//处理消息出错的节点会被断开，同时记录不当行为
+	defer func() {
+		if err != nil {
+			p.Penalize(p2p.MisbehaviorProtocolError)
+		}
+	}()
 	if msg.Size > ProtocolMaxMsgSize {
 		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, ProtocolMaxMsgSize)
 	}
@@ -660,6 +701,21 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	case msg.Code == GetLeafNodesMsg:
 		return pm.GetLeafNodesMsg(msg, p)
 
+	case p.version >= ptn2 && msg.Code == CompactUnitMsg:
+		return pm.CompactUnitMsg(msg, p)
+
+	case p.version >= ptn2 && msg.Code == GetUnitTxsMsg:
+		return pm.GetUnitTxsMsg(msg, p)
+
+	case p.version >= ptn2 && msg.Code == UnitTxsMsg:
+		return pm.UnitTxsMsg(msg, p)
+
+	case p.version >= ptn2 && msg.Code == NewTxHashesMsg:
+		return pm.NewTxHashesMsg(msg, p)
+
+	case p.version >= ptn2 && msg.Code == GetTxsMsg:
+		return pm.GetTxsMsg(msg, p)
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -672,6 +728,11 @@ func (pm *ProtocolManager) BroadcastTx(hash common.Hash, tx *modules.Transaction
 	peers := pm.peers.PeersWithoutTx(hash)
 	//FIXME include this again: peers = peers[:int(math.Sqrt(float64(len(peers))))]
 	for _, peer := range peers {
+		//支持ptn/2的节点只发送交易hash，由对方请求交易
+		if peer.version >= ptn2 {
+			peer.AsyncSendTxHashes([]common.Hash{hash})
+			continue
+		}
 		peer.SendTransactions(modules.Transactions{tx})
 	}
 	log.Trace("Broadcast transaction", "hash", hash, "recipients", len(peers))
@@ -697,16 +758,24 @@ func (pm *ProtocolManager) ContractReqLocalSend(event jury.ContractEvent) {
 
 // BroadcastUnit will either propagate a unit to a subset of it's peers, or
 // will only announce it's availability (depending what's requested).
+// 支持ptn/2的节点发送压缩单元，其他节点发送完整单元
 func (pm *ProtocolManager) BroadcastUnit(unit *modules.Unit, propagate bool) {
 	hash := unit.Hash()
+	pm.recentUnits.Add(hash, unit)
 	// If propagation is requested, send to a subset of the peer
-	data, err := rlp.EncodeToBytes(unit)
-	if err != nil {
-		log.Errorf("BroadcastUnit rlp encode err:%s", err.Error())
-	}
-
+	var data []byte
 	peers := pm.peers.PeersWithoutUnit(hash)
 	for _, peer := range peers {
+		if peer.version >= ptn2 {
+			peer.SendCompactUnit(unit)
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = rlp.EncodeToBytes(unit); err != nil {
+				log.Errorf("BroadcastUnit rlp encode err:%s", err.Error())
+			}
+		}
 		peer.SendNewRawUnit(unit, data)
 	}
 	log.Trace("BroadcastUnit Propagated block", "index:", unit.Header().Number.Index,
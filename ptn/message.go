@@ -363,15 +363,8 @@ func (pm *ProtocolManager) NewBlockMsg(msg p2p.Msg, p *peer) error {
 		return nil
 	}
 
-	// append by Albert·Gou
-	timestamp := time.Unix(unit.Timestamp(), 0)
-	//latency := time.Now().Sub(timestamp)
-	latency := time.Since(timestamp)
-	if latency < -5*time.Second {
-		errStr := fmt.Sprintf("Rejecting unit #%v with timestamp(%v) in the future signed by %v",
-			unit.NumberU64(), timestamp.Format("2006-01-02 15:04:05"), unit.Author().Str())
-		log.Debugf(errStr)
-		return fmt.Errorf(errStr)
+	if err := checkUnitTimestamp(unit.Header()); err != nil {
+		return err
 	}
 
 	unitHash := unit.Hash()
@@ -381,7 +374,27 @@ func (pm *ProtocolManager) NewBlockMsg(msg p2p.Msg, p *peer) error {
 		p.SetHead(unitHash, unit.Number(), nil)
 		return nil
 	}
+	return pm.processNewUnit(unit, msg.ReceivedAt, p)
+}
 
+// append by Albert·Gou
+func checkUnitTimestamp(header *modules.Header) error {
+	timestamp := time.Unix(header.Timestamp(), 0)
+	//latency := time.Now().Sub(timestamp)
+	latency := time.Since(timestamp)
+	if latency < -5*time.Second {
+		errStr := fmt.Sprintf("Rejecting unit #%v with timestamp(%v) in the future signed by %v",
+			header.NumberU64(), timestamp.Format("2006-01-02 15:04:05"), header.Author().Str())
+		log.Debugf(errStr)
+		return fmt.Errorf(errStr)
+	}
+	return nil
+}
+
+//processNewUnit 处理广播的新单元，完整单元和恢复出的压缩单元都由这里导入
+func (pm *ProtocolManager) processNewUnit(unit *modules.Unit, receivedAt time.Time, p *peer) error {
+	unitHash := unit.Hash()
+	timestamp := time.Unix(unit.Timestamp(), 0)
 	log.Infof("Received unit(%v) #%v parent(%v) @%v signed by %v", unitHash.TerminalString(),
 		unit.NumberU64(), unit.ParentHash()[0].TerminalString(), timestamp.Format("2006-01-02 15:04:05"),
 		unit.Author().Str())
@@ -416,7 +429,7 @@ func (pm *ProtocolManager) NewBlockMsg(msg p2p.Msg, p *peer) error {
 	rwset.RwM.Close()
 	unit.Txs = temptxs
 
-	unit.ReceivedAt = msg.ReceivedAt
+	unit.ReceivedAt = receivedAt
 	unit.ReceivedFrom = p
 
 	// Mark the peer as owning the block and schedule it for import
@@ -463,8 +476,11 @@ func (pm *ProtocolManager) TxMsg(msg p2p.Msg, p *peer) error {
 			return errResp(ErrDecode, "transaction %d is nil", i)
 		}
 		txHash := tx.Hash()
+		pm.txFetcher.delivered([]common.Hash{txHash})
+		//请求的交易是批量返回的，已经收到的交易跳过，继续处理后面的交易
 		if pm.IsExistInCache(txHash.Bytes()) {
-			return nil
+			p.MarkTransaction(txHash)
+			continue
 		}
 		if tx.IsContractTx() {
 			if pm.contractProc.IsSystemContractTx(tx) {
@@ -546,7 +562,14 @@ func (pm *ProtocolManager) GroupSigMsg(msg p2p.Msg, p *peer) error {
 		return nil
 	}
 
-	go pm.dag.SetUnitGroupSign(gSign.UnitHash, gSign.GroupSig, pm.txpool)
+	go func() {
+		err := pm.dag.SetUnitGroupSign(gSign.UnitHash, gSign.GroupSig, pm.txpool)
+		//同步中或者还没有收到单元时无法验证群签名，不算节点的错误
+		if err != nil && pm.dag.IsSynced() && pm.dag.IsHeaderExist(gSign.UnitHash) {
+			log.Debugf("Invalid group sign of unit(%v) from peer %v: %v", gSign.UnitHash.TerminalString(), p.id, err)
+			p.Penalize(p2p.MisbehaviorBadGroupSig)
+		}
+	}()
 	return nil
 }
 
@@ -87,6 +87,11 @@ type peer struct {
 	knownBlocks       set.Set // Set of block hashes known to be known by this peer
 	knownLightHeaders set.Set
 	knownGroupSig     set.Set // Set of block hashes known to be known by this peer
+
+	queuedTxAnns    chan common.Hash // 等待公告的交易hash
+	txAnnounceLimit *rateLimiter     // 接受交易公告的速率
+	txBandwidth     *rateLimiter     // 发送请求的交易的带宽
+	term            chan struct{}
 }
 
 func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
@@ -103,6 +108,10 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 		knownGroupSig:     set.NewSet(),
 		peermsg:           map[modules.AssetId]peerMsg{},
 		//lightpeermsg:      map[modules.AssetId]peerMsg{},
+		queuedTxAnns:    make(chan common.Hash, maxTxAnnounceQueue),
+		txAnnounceLimit: newRateLimiter(txPeerAnnounceRate, maxTxAnnounceQueue),
+		txBandwidth:     newRateLimiter(txPeerBandwidthRate, txPeerBandwidthRate),
+		term:            make(chan struct{}),
 	}
 }
 
@@ -228,6 +237,59 @@ func (p *peer) SendTransactions(txs modules.Transactions) error {
 	return p2p.Send(p.rw, TxMsg, txs)
 }
 
+// AsyncSendTxHashes queues transaction hashes to be announced to the peer in batches,
+// hashes are dropped if the queue is full.
+func (p *peer) AsyncSendTxHashes(hashes []common.Hash) {
+	for _, hash := range hashes {
+		select {
+		case p.queuedTxAnns <- hash:
+			p.knownTxs.Add(hash)
+		default:
+			log.Debug("Dropping transaction announcement", "peer", p.id, "hash", hash)
+		}
+	}
+}
+
+// RequestTxs fetches a batch of announced transactions from the peer.
+func (p *peer) RequestTxs(hashes []common.Hash) error {
+	log.Trace("Fetching batch of transactions", "peer", p.id, "count", len(hashes))
+	return p2p.Send(p.rw, GetTxsMsg, hashes)
+}
+
+// announceTxLoop sends queued transaction hashes every txAnnounceInterval.
+func (p *peer) announceTxLoop() {
+	ticker := time.NewTicker(txAnnounceInterval)
+	defer ticker.Stop()
+	var queue []common.Hash
+	for {
+		select {
+		case hash := <-p.queuedTxAnns:
+			queue = append(queue, hash)
+			if len(queue) > maxTxAnnounceQueue {
+				queue = queue[len(queue)-maxTxAnnounceQueue:]
+			}
+		case <-ticker.C:
+			for len(queue) > 0 {
+				n := len(queue)
+				if n > maxTxAnnounceBatch {
+					n = maxTxAnnounceBatch
+				}
+				if err := p2p.Send(p.rw, NewTxHashesMsg, queue[:n]); err != nil {
+					return
+				}
+				queue = queue[n:]
+			}
+			queue = nil
+		case <-p.term:
+			return
+		}
+	}
+}
+
+func (p *peer) close() {
+	close(p.term)
+}
+
 func (p *peer) SendContractTransaction(event jury.ContractEvent) error {
 	return p2p.Send(p.rw, ContractMsg, event)
 }
@@ -279,6 +341,25 @@ func (p *peer) SendNewRawUnit(unit *modules.Unit, data []byte) error {
 	return p2p.Send(p.rw, NewBlockMsg, data)
 }
 
+// SendCompactUnit propagates a unit as header and short transaction ids,
+// transactions the peer may not have are sent along with it.
+func (p *peer) SendCompactUnit(unit *modules.Unit) error {
+	p.knownBlocks.Add(unit.UnitHash)
+	cu := newCompactUnit(unit, func(hash common.Hash) bool { return p.knownTxs.Contains(hash) })
+	return p2p.Send(p.rw, CompactUnitMsg, cu)
+}
+
+// RequestUnitTxs fetches the missing transactions of a compact unit.
+func (p *peer) RequestUnitTxs(hash common.Hash, indexes []uint32) error {
+	log.Debug("Fetching missing transactions of compact unit", "hash", hash, "count", len(indexes))
+	return p2p.Send(p.rw, GetUnitTxsMsg, &getUnitTxsData{Hash: hash, Indexes: indexes})
+}
+
+// SendUnitTxs sends the requested transactions of a compact unit.
+func (p *peer) SendUnitTxs(hash common.Hash, txs []*modules.Transaction) error {
+	return p2p.Send(p.rw, UnitTxsMsg, &unitTxsData{Hash: hash, Txs: txs})
+}
+
 // SendLightHeader propagates an entire header to a remote partition peer.
 func (p *peer) SendLightHeader(header *modules.Header) error {
 	p.knownLightHeaders.Add(header.Hash())
@@ -27,16 +27,17 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	ptn1 = 1
+	ptn2 = 2 // 支持压缩单元
 )
 
 // Official short name of the protocol used during capability negotiation.
 var ProtocolName = "ptn"
 
 // Supported versions of the ptn protocol (first is primary).
-var ProtocolVersions = []uint{ptn1}
+var ProtocolVersions = []uint{ptn2, ptn1}
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{100, 8} //{17, 8}
+var ProtocolLengths = []uint64{100, 100} //{17, 8}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -62,6 +63,13 @@ const (
 	ElectionMsg        = 0x10
 	AdapterMsg         = 0x11
 
+	// Protocol messages belonging to ptn/2
+	CompactUnitMsg = 0x12
+	GetUnitTxsMsg  = 0x13
+	UnitTxsMsg     = 0x14
+	NewTxHashesMsg = 0x15
+	GetTxsMsg      = 0x16
+
 	GetNodeDataMsg = 0x20
 	NodeDataMsg    = 0x21
 	GetReceiptsMsg = 0x22
@@ -0,0 +1,309 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package ptn
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/common/p2p"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+//ptn/2的交易广播：先发送交易hash，对方没有的交易再来请求。
+//公告按间隔批量发送，每个节点的公告数量和发送的交易字节数都有速率限制
+const (
+	txAnnounceInterval  = 100 * time.Millisecond // 批量发送交易公告的间隔
+	maxTxAnnounceBatch  = 1024                   // 一个公告消息中最多的交易hash数
+	maxTxAnnounceQueue  = 4096                   // 每个节点等待公告的交易hash数，超过时丢弃最早的
+	maxTxFetch          = 256                    // 一次请求的最多交易数
+	maxTxAnnouncePeers  = 8                      // 每个交易最多记录的公告节点数
+	maxTxFetchTracked   = 65536                  // 最多跟踪的待请求交易数
+	txFetchTimeout      = 5 * time.Second        // 请求超时后向其他公告过的节点请求
+	txPeerAnnounceRate  = 2000                   // 每个节点每秒最多接受的交易公告数
+	txPeerBandwidthRate = 512 * 1024             // 每秒最多向每个节点发送的请求的交易字节数
+)
+
+//rateLimiter 令牌桶，每秒补充rate个令牌，最多存burst个
+type rateLimiter struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate, burst float64) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+//allow 有足够的令牌时取走n个令牌
+func (r *rateLimiter) allow(n float64) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+	if r.tokens < n {
+		return false
+	}
+	r.tokens -= n
+	return true
+}
+
+type txAnnounce struct {
+	peers    []string  // 公告过这个交易的节点
+	fetching string    // 正在请求的节点
+	deadline time.Time // 请求的超时时间
+}
+
+//txFetcher 记录其他节点公告的交易，向公告的节点请求，超时后换一个公告过的节点
+type txFetcher struct {
+	lock      sync.Mutex
+	announces map[common.Hash]*txAnnounce
+
+	request func(peer string, hashes []common.Hash) error
+	timeout func(peer string) // 节点没有在超时时间内返回请求的交易
+	quit    chan struct{}
+}
+
+func newTxFetcher(request func(peer string, hashes []common.Hash) error, timeout func(peer string)) *txFetcher {
+	return &txFetcher{
+		announces: make(map[common.Hash]*txAnnounce),
+		request:   request,
+		timeout:   timeout,
+		quit:      make(chan struct{}),
+	}
+}
+
+//notify 记录peer公告的本地没有的交易，没有在请求中的交易向peer请求
+func (f *txFetcher) notify(peer string, hashes []common.Hash) {
+	f.lock.Lock()
+	now := time.Now()
+	var fetch []common.Hash
+	for _, hash := range hashes {
+		ann, ok := f.announces[hash]
+		if !ok {
+			if len(f.announces) >= maxTxFetchTracked {
+				continue
+			}
+			ann = &txAnnounce{}
+			f.announces[hash] = ann
+		}
+		if len(ann.peers) < maxTxAnnouncePeers && !containsPeer(ann.peers, peer) {
+			ann.peers = append(ann.peers, peer)
+		}
+		if ann.fetching == "" {
+			ann.fetching, ann.deadline = peer, now.Add(txFetchTimeout)
+			fetch = append(fetch, hash)
+		}
+	}
+	f.lock.Unlock()
+	f.send(map[string][]common.Hash{peer: fetch})
+}
+
+//delivered 收到交易后不再请求
+func (f *txFetcher) delivered(hashes []common.Hash) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, hash := range hashes {
+		delete(f.announces, hash)
+	}
+}
+
+//dropPeer 节点断开后，正在向它请求的交易立即换其他节点
+func (f *txFetcher) dropPeer(peer string) {
+	f.lock.Lock()
+	for _, ann := range f.announces {
+		ann.peers = removePeerId(ann.peers, peer)
+		if ann.fetching == peer {
+			ann.deadline = time.Time{}
+		}
+	}
+	f.lock.Unlock()
+	f.expire()
+}
+
+//expire 超时的请求换下一个公告过的节点，没有其他节点时放弃
+func (f *txFetcher) expire() {
+	f.lock.Lock()
+	now := time.Now()
+	fetches := make(map[string][]common.Hash)
+	timeouts := make(map[string]bool)
+	for hash, ann := range f.announces {
+		if ann.fetching == "" || now.Before(ann.deadline) {
+			continue
+		}
+		//断开的节点已经从peers中删除，不算超时
+		if containsPeer(ann.peers, ann.fetching) {
+			timeouts[ann.fetching] = true
+		}
+		ann.peers = removePeerId(ann.peers, ann.fetching)
+		if len(ann.peers) == 0 {
+			delete(f.announces, hash)
+			continue
+		}
+		ann.fetching, ann.deadline = ann.peers[0], now.Add(txFetchTimeout)
+		fetches[ann.fetching] = append(fetches[ann.fetching], hash)
+	}
+	f.lock.Unlock()
+	for peer := range timeouts {
+		f.timeout(peer)
+	}
+	f.send(fetches)
+}
+
+func (f *txFetcher) send(fetches map[string][]common.Hash) {
+	for peer, hashes := range fetches {
+		for len(hashes) > 0 {
+			n := len(hashes)
+			if n > maxTxFetch {
+				n = maxTxFetch
+			}
+			if err := f.request(peer, hashes[:n]); err != nil {
+				log.Debug("Request transactions failed", "peer", peer, "err", err)
+			}
+			hashes = hashes[n:]
+		}
+	}
+}
+
+func (f *txFetcher) loop() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.expire()
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+func (f *txFetcher) stop() {
+	close(f.quit)
+}
+
+func containsPeer(peers []string, peer string) bool {
+	for _, p := range peers {
+		if p == peer {
+			return true
+		}
+	}
+	return false
+}
+
+func removePeerId(peers []string, peer string) []string {
+	for i, p := range peers {
+		if p == peer {
+			return append(peers[:i], peers[i+1:]...)
+		}
+	}
+	return peers
+}
+
+func (pm *ProtocolManager) requestTxs(id string, hashes []common.Hash) error {
+	p := pm.peers.Peer(id)
+	if p == nil {
+		return errNotRegistered
+	}
+	return p.RequestTxs(hashes)
+}
+
+func (pm *ProtocolManager) txFetchTimeout(id string) {
+	pm.penalizePeer(id, p2p.MisbehaviorTimeout)
+}
+
+//本地已经有的交易不需要请求
+func (pm *ProtocolManager) hasTx(hash common.Hash) bool {
+	if _, err := pm.receivedCache.Get(hash.Bytes()); err == nil {
+		return true
+	}
+	ptx, _ := pm.txpool.Get(hash)
+	return ptx != nil && ptx.Tx != nil
+}
+
+func (pm *ProtocolManager) NewTxHashesMsg(msg p2p.Msg, p *peer) error {
+	if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+		return nil
+	}
+	var hashes []common.Hash
+	if err := msg.Decode(&hashes); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if len(hashes) > maxTxAnnounceBatch {
+		return errResp(ErrMsgTooLarge, "%d transaction hashes > %d", len(hashes), maxTxAnnounceBatch)
+	}
+	for _, hash := range hashes {
+		p.MarkTransaction(hash)
+	}
+	//超过速率的公告直接丢弃，交易还会从其他节点或者单元中得到
+	if !p.txAnnounceLimit.allow(float64(len(hashes))) {
+		log.Debug("Transaction announcements over rate limit, dropped", "peer", p.id, "count", len(hashes))
+		return nil
+	}
+	unknown := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if !pm.hasTx(hash) {
+			unknown = append(unknown, hash)
+		}
+	}
+	if len(unknown) > 0 {
+		pm.txFetcher.notify(p.id, unknown)
+	}
+	return nil
+}
+
+func (pm *ProtocolManager) GetTxsMsg(msg p2p.Msg, p *peer) error {
+	var hashes []common.Hash
+	if err := msg.Decode(&hashes); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if len(hashes) > maxTxFetch {
+		return errResp(ErrMsgTooLarge, "%d transactions requested > %d", len(hashes), maxTxFetch)
+	}
+	//响应的大小受softResponseLimit和节点带宽限制，没有发送的交易对方超时后向其他节点请求
+	var (
+		txs  modules.Transactions
+		size common.StorageSize
+	)
+	for _, hash := range hashes {
+		ptx, _ := pm.txpool.Get(hash)
+		if ptx == nil || ptx.Tx == nil {
+			continue
+		}
+		txSize := ptx.Tx.Size()
+		if size+txSize > softResponseLimit || !p.txBandwidth.allow(float64(txSize)) {
+			break
+		}
+		txs = append(txs, ptx.Tx)
+		size += txSize
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+	return p.SendTransactions(txs)
+}
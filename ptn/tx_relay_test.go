@@ -0,0 +1,90 @@
+/*
+   This file is part of go-palletone.
+   go-palletone is free software: you can redistribute it and/or modify
+   it under the terms of the GNU General Public License as published by
+   the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+   go-palletone is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU General Public License for more details.
+   You should have received a copy of the GNU General Public License
+   along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package ptn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	r := newRateLimiter(10, 20)
+	assert.True(t, r.allow(15))
+	assert.False(t, r.allow(10))
+	assert.True(t, r.allow(5))
+	//0.5秒后补充5个令牌
+	r.last = r.last.Add(-500 * time.Millisecond)
+	assert.True(t, r.allow(5))
+	assert.False(t, r.allow(1))
+	//不超过burst
+	r.last = r.last.Add(-time.Hour)
+	assert.False(t, r.allow(21))
+	assert.True(t, r.allow(20))
+}
+
+func TestTxFetcher(t *testing.T) {
+	requests := make(map[string][]common.Hash)
+	timeouts := make(map[string]int)
+	f := newTxFetcher(func(peer string, hashes []common.Hash) error {
+		requests[peer] = append(requests[peer], hashes...)
+		return nil
+	}, func(peer string) {
+		timeouts[peer]++
+	})
+	h1, h2, h3 := common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2}), common.BytesToHash([]byte{3})
+
+	f.notify("A", []common.Hash{h1, h2})
+	assert.Equal(t, []common.Hash{h1, h2}, requests["A"])
+	//已经在向A请求的交易不再向B请求
+	f.notify("B", []common.Hash{h2, h3})
+	assert.Equal(t, []common.Hash{h3}, requests["B"])
+
+	//收到h1，A的请求超时后h2向B请求
+	f.delivered([]common.Hash{h1})
+	f.announces[h2].deadline = time.Now().Add(-time.Second)
+	f.expire()
+	assert.Equal(t, []common.Hash{h3, h2}, requests["B"])
+	assert.Equal(t, []string{"B"}, f.announces[h2].peers)
+	assert.Equal(t, map[string]int{"A": 1}, timeouts)
+
+	//B断开后没有其他节点公告过，不再请求，断开不算超时
+	f.dropPeer("B")
+	assert.Equal(t, 0, len(f.announces))
+	assert.Equal(t, 2, len(requests["B"]))
+	assert.Equal(t, map[string]int{"A": 1}, timeouts)
+}
+
+func TestTxFetcherBatch(t *testing.T) {
+	var batches [][]common.Hash
+	f := newTxFetcher(func(peer string, hashes []common.Hash) error {
+		batches = append(batches, hashes)
+		return nil
+	}, func(string) {})
+	hashes := make([]common.Hash, maxTxFetch+1)
+	for i := range hashes {
+		hashes[i] = common.BytesToHash([]byte{byte(i), byte(i >> 8), 1})
+	}
+	f.notify("A", hashes)
+	assert.Equal(t, 2, len(batches))
+	assert.Equal(t, maxTxFetch, len(batches[0]))
+	assert.Equal(t, 1, len(batches[1]))
+}
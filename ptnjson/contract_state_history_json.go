@@ -0,0 +1,54 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developers <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ *
+ */
+
+package ptnjson
+
+import (
+	"time"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+type ContractStateHistoryJson struct {
+	TxId      common.Hash `json:"tx_id"`
+	UnitHash  common.Hash `json:"unit_hash"`
+	UnitIndex uint64      `json:"unit_index"`
+	TxIndex   uint32      `json:"tx_index"`
+	Timestamp time.Time   `json:"timestamp"`
+	Value     string      `json:"value"`
+	IsDelete  bool        `json:"is_delete"`
+}
+
+func ConvertContractStateHistory2Json(history *modules.ContractStateHistory) *ContractStateHistoryJson {
+	json := &ContractStateHistoryJson{
+		TxId:      history.TxId,
+		UnitHash:  history.UnitHash,
+		Timestamp: time.Unix(int64(history.Timestamp), 0),
+		Value:     string(history.Value),
+		IsDelete:  history.IsDelete,
+	}
+	if history.Version != nil && history.Version.Height != nil {
+		json.UnitIndex = history.Version.Height.Index
+		json.TxIndex = history.Version.TxIndex
+	}
+	return json
+}
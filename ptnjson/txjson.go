@@ -29,6 +29,7 @@ import (
 
 	"encoding/hex"
 	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/dag/modules"
 )
 
@@ -49,6 +50,8 @@ type TxJson struct {
 	DeployRequest      *DeployRequestJson  `json:"deploy_request"`
 	InvokeRequest      *InvokeRequestJson  `json:"invoke_request"`
 	StopRequest        *StopRequestJson    `json:"stop_request"`
+	MediatorEvidence   *EvidenceJson       `json:"mediator_evidence"`
+	JurorEvidence      *JurorEvidenceJson  `json:"juror_evidence"`
 }
 type TxWithUnitInfoJson struct {
 	*TxJson
@@ -84,6 +87,7 @@ type InvokeJson struct {
 	Payload      string `json:"payload"`     // the contract execution result
 	ErrorCode    uint32 `json:"error_code"`
 	ErrorMessage string `json:"error_message"`
+	Cost         uint64 `json:"cost"`
 }
 type StopJson struct {
 	Number     int    `json:"row_number"`
@@ -104,6 +108,7 @@ type InvokeRequestJson struct {
 	ContractAddr string        `json:"contract_addr"`
 	Args         []string      `json:"arg_set"`
 	Timeout      time.Duration `json:"timeout"`
+	CostLimit    uint64        `json:"cost_limit"`
 }
 
 type InstallRequestJson struct {
@@ -140,6 +145,20 @@ type AccountStateJson struct {
 	Number   int    `json:"row_number"`
 	WriteSet string `json:"write_set"`
 }
+type EvidenceJson struct {
+	Number      int    `json:"row_number"`
+	Mediator    string `json:"mediator"`
+	Time        int64  `json:"time"`
+	Header1Hash string `json:"header1_hash"`
+	Header2Hash string `json:"header2_hash"`
+}
+type JurorEvidenceJson struct {
+	Number     int    `json:"row_number"`
+	Juror      string `json:"juror"`
+	RequestId  string `json:"request_id"`
+	AcceptedTx string `json:"accepted_tx"`
+	Signature  string `json:"signature"`
+}
 
 func ConvertTxWithUnitInfo2FullJson(tx *modules.TransactionWithUnitInfo,
 	utxoQuery modules.QueryUtxoFunc) *TxWithUnitInfoJson {
@@ -222,6 +241,14 @@ func ConvertTx2FullJson(tx *modules.Transaction,
 			acc := m.Payload.(*modules.AccountStateUpdatePayload)
 			txjson.AccountStateUpdate = convertAccountState2Json(acc)
 			txjson.AccountStateUpdate.Number = i
+		} else if m.App == modules.APP_MEDIATOR_EVIDENCE {
+			evidence := m.Payload.(*modules.MediatorEvidencePayload)
+			txjson.MediatorEvidence = convertEvidence2Json(evidence)
+			txjson.MediatorEvidence.Number = i
+		} else if m.App == modules.APP_JUROR_EVIDENCE {
+			evidence := m.Payload.(*modules.JurorEvidencePayload)
+			txjson.JurorEvidence = convertJurorEvidence2Json(evidence)
+			txjson.JurorEvidence.Number = i
 		}
 	}
 	if utxoQuery != nil {
@@ -300,6 +327,7 @@ func convertInvoke2Json(invoke *modules.ContractInvokePayload) *InvokeJson {
 	injson.Payload = string(invoke.Payload)
 	injson.ErrorCode = invoke.ErrMsg.Code
 	injson.ErrorMessage = invoke.ErrMsg.Message
+	injson.Cost = invoke.Cost
 
 	return injson
 }
@@ -336,6 +364,7 @@ func convertInvokeRequest2Json(req *modules.ContractInvokeRequestPayload) *Invok
 		reqJson.Args = append(reqJson.Args, string(arg))
 	}
 	reqJson.Timeout = time.Duration(req.Timeout) * time.Second
+	reqJson.CostLimit = req.CostLimit
 	return reqJson
 }
 
@@ -383,3 +412,28 @@ func convertAccountState2Json(accountState *modules.AccountStateUpdatePayload) *
 	jsonAcc.WriteSet = string(writeSet)
 	return jsonAcc
 }
+func convertEvidence2Json(evidence *modules.MediatorEvidencePayload) *EvidenceJson {
+	jsonEvi := &EvidenceJson{}
+	if evidence.Header1 != nil {
+		jsonEvi.Mediator = evidence.Header1.Author().String()
+		jsonEvi.Time = evidence.Header1.Time
+		jsonEvi.Header1Hash = evidence.Header1.Hash().String()
+	}
+	if evidence.Header2 != nil {
+		jsonEvi.Header2Hash = evidence.Header2.Hash().String()
+	}
+	return jsonEvi
+}
+func convertJurorEvidence2Json(evidence *modules.JurorEvidencePayload) *JurorEvidenceJson {
+	jsonEvi := &JurorEvidenceJson{
+		AcceptedTx: evidence.AcceptedTx.String(),
+		Signature:  evidence.Signature.String(),
+	}
+	if len(evidence.Signature.PubKey) > 0 {
+		jsonEvi.Juror = crypto.PubkeyBytesToAddress(evidence.Signature.PubKey).String()
+	}
+	if result, err := evidence.ResultTx(); err == nil {
+		jsonEvi.RequestId = result.RequestHash().String()
+	}
+	return jsonEvi
+}
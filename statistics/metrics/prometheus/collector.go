@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/palletone/go-palletone/statistics/metrics"
+)
+
+// quantiles are the percentiles exported for histograms and timers.
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// collector aggregates the metrics of a registry into the Prometheus text
+// exposition format.
+type collector struct {
+	buff *bytes.Buffer
+}
+
+func newCollector() *collector {
+	return &collector{buff: new(bytes.Buffer)}
+}
+
+// add writes a single metric of any supported type to the buffer, unknown
+// types (e.g. healthchecks) are silently skipped.
+func (c *collector) add(name string, i interface{}) {
+	name = mutateKey(name)
+	switch m := i.(type) {
+	case metrics.Counter:
+		c.writeSingle(name, "counter", m.Count())
+	case metrics.Gauge:
+		c.writeSingle(name, "gauge", m.Value())
+	case metrics.GaugeFloat64:
+		c.writeSingle(name, "gauge", m.Value())
+	case metrics.Meter:
+		c.writeSingle(name, "counter", m.Count())
+	case metrics.Histogram:
+		h := m.Snapshot()
+		c.writeSummary(name, h.Percentiles(quantiles), h.Sum(), h.Count())
+	case metrics.Timer:
+		t := m.Snapshot()
+		c.writeSummary(name, t.Percentiles(quantiles), t.Sum(), t.Count())
+	case metrics.ResettingTimer:
+		t := m.Snapshot()
+		values := t.Values()
+		if len(values) == 0 {
+			return
+		}
+		ps := make([]float64, len(quantiles))
+		for i, p := range t.Percentiles(percents(quantiles)) {
+			ps[i] = float64(p)
+		}
+		var sum int64
+		for _, v := range values {
+			sum += v
+		}
+		c.writeSummary(name, ps, sum, int64(len(values)))
+	}
+}
+
+func (c *collector) writeSingle(name, typ string, value interface{}) {
+	fmt.Fprintf(c.buff, "# TYPE %s %s\n", name, typ)
+	fmt.Fprintf(c.buff, "%s %v\n", name, value)
+}
+
+func (c *collector) writeSummary(name string, ps []float64, sum, count int64) {
+	fmt.Fprintf(c.buff, "# TYPE %s summary\n", name)
+	for i, q := range quantiles {
+		fmt.Fprintf(c.buff, "%s{quantile=\"%s\"} %v\n", name, strconv.FormatFloat(q, 'f', -1, 64), ps[i])
+	}
+	fmt.Fprintf(c.buff, "%s_sum %d\n", name, sum)
+	fmt.Fprintf(c.buff, "%s_count %d\n", name, count)
+}
+
+// percents converts quantiles to the 0-100 scale used by ResettingTimer.
+func percents(qs []float64) []float64 {
+	ps := make([]float64, len(qs))
+	for i, q := range qs {
+		ps[i] = q * 100
+	}
+	return ps
+}
+
+// mutateKey converts a registry name like "ptn/txpool/pending" into a valid
+// Prometheus metric name, all characters other than [a-zA-Z0-9_:] become '_'.
+func mutateKey(key string) string {
+	b := []byte(key)
+	for i, ch := range b {
+		valid := ch == '_' || ch == ':' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') ||
+			(i > 0 && ch >= '0' && ch <= '9')
+		if !valid {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
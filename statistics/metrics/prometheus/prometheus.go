@@ -0,0 +1,47 @@
+// Package prometheus exposes go-metrics registries in the Prometheus text
+// exposition format, so that the node can be scraped directly.
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/statistics/metrics"
+)
+
+// Handler returns an HTTP handler which dumps the metrics of the registry in
+// Prometheus format, metrics are sorted by name so the output is stable.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := []string{}
+		reg.Each(func(name string, i interface{}) {
+			names = append(names, name)
+		})
+		sort.Strings(names)
+
+		c := newCollector()
+		for _, name := range names {
+			if i := reg.Get(name); i != nil {
+				c.add(name, i)
+			}
+		}
+		w.Header().Add("Content-Type", "text/plain; version=0.0.4")
+		w.Header().Add("Content-Length", fmt.Sprint(c.buff.Len()))
+		w.Write(c.buff.Bytes())
+	})
+}
+
+// StartServer starts a stand-alone HTTP server serving the registry on
+// "/metrics", it is meant to be scraped by Prometheus.
+func StartServer(address string, reg metrics.Registry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(reg))
+	log.Info("Starting metrics server", "addr", fmt.Sprintf("http://%s/metrics", address))
+	go func() {
+		if err := http.ListenAndServe(address, mux); err != nil {
+			log.Error("Failure in running metrics server", "err", err)
+		}
+	}()
+}
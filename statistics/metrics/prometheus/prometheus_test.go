@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palletone/go-palletone/statistics/metrics"
+)
+
+func TestMutateKey(t *testing.T) {
+	cases := map[string]string{
+		"txpool/pending":            "txpool_pending",
+		"dag/memdag/stable-lag":     "dag_memdag_stable_lag",
+		"1st/metric":                "_st_metric",
+		"ptn/prop/txns/in/packets":  "ptn_prop_txns_in_packets",
+		"already_valid:name_123abc": "already_valid:name_123abc",
+	}
+	for key, want := range cases {
+		if got := mutateKey(key); got != want {
+			t.Errorf("mutateKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = false }()
+
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("test/counter", r).Inc(3)
+	metrics.NewRegisteredGauge("test/gauge", r).Update(7)
+	metrics.NewRegisteredMeter("test/meter", r).Mark(5)
+	timer := metrics.NewRegisteredTimer("test/timer", r)
+	timer.Update(2 * time.Second)
+	timer.Update(4 * time.Second)
+	r.Register("test/healthcheck", metrics.NewHealthcheck(func(metrics.Healthcheck) {}))
+
+	w := httptest.NewRecorder()
+	Handler(r).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	for _, line := range []string{
+		"# TYPE test_counter counter\ntest_counter 3\n",
+		"# TYPE test_gauge gauge\ntest_gauge 7\n",
+		"# TYPE test_meter counter\ntest_meter 5\n",
+		"# TYPE test_timer summary\n",
+		"test_timer{quantile=\"0.5\"} 3e+09\n",
+		"test_timer_sum 6000000000\n",
+		"test_timer_count 2\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q in output:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "healthcheck") {
+		t.Errorf("unsupported metric should be skipped:\n%s", body)
+	}
+	//按名称排序输出
+	if strings.Index(body, "test_counter") > strings.Index(body, "test_timer") {
+		t.Errorf("metrics are not sorted:\n%s", body)
+	}
+}
@@ -133,6 +133,14 @@ func (engine *TokenEngine) GenerateRedeemScript(needed byte, pubKeys [][]byte) [
 	return redeemScript
 }
 
+//生成策略账户的赎回脚本，花费时需要所有者签名，并且交易中对策略合约的spend调用执行成功
+//策略账户地址为该赎回脚本对应的P2SH地址
+func (engine *TokenEngine) GeneratePolicyRedeemScript(ownerPubKey []byte, policyContract common.Address) []byte {
+	redeemScript, _ := txscript.NewScriptBuilder().AddData(ownerPubKey).AddOp(txscript.OP_CHECKSIGVERIFY).
+		AddData(policyContract.Bytes()).AddOp(txscript.OP_CHECK_POLICY).Script()
+	return redeemScript
+}
+
 //根据地址产生对应的锁定脚本
 func (engine *TokenEngine) GenerateLockScript(address common.Address) []byte {
 
@@ -216,13 +224,14 @@ func (engine *TokenEngine) ScriptValidate(utxoLockScript []byte,
 		log.Error("Failed to create script: ", err)
 		return err
 	}
+	vm.SetFullTx(tx)
 	return vm.Execute()
 }
 
 //验证一个PaymentMessage的所有Input解锁脚本是否正确
 func (engine *TokenEngine) ScriptValidate1Msg(utxoLockScripts map[string][]byte,
 	pickupJuryRedeemScript PickupJuryRedeemScript,
-	tx *modules.Transaction, msgIdx int) error {
+	tx *modules.Transaction, fullTx *modules.Transaction, msgIdx int) error {
 	acc := &account{}
 	txCopy := tx
 	if tx.IsContractTx() {
@@ -250,6 +259,9 @@ func (engine *TokenEngine) ScriptValidate1Msg(utxoLockScripts map[string][]byte,
 
 			return err
 		}
+		if fullTx != nil {
+			vm.SetFullTx(fullTx)
+		}
 		err = vm.Execute()
 		if err != nil {
 			log.Warnf("Unlock script validate fail,tx[%s],MsgIdx[%d],In[%d],unlockScript:%x,utxoScript:%x, error:%s",
@@ -283,6 +295,11 @@ func (engine *TokenEngine) GetScriptSigners(tx *modules.Transaction, msgIdx, inp
 			redeemStr, _ := txscript.DisasmString(redeem)
 			log.Debug(redeemStr)
 			rops := strings.Fields(redeemStr)
+			if txscript.GetScriptClass(redeem) == txscript.PolicyTy { //策略账户只有所有者签名
+				pubkey, _ := hex.DecodeString(rops[0])
+				pubkeys = append(pubkeys, pubkey)
+				continue
+			}
 			for j, rop := range rops {
 				if j > 0 && j < len(rops)-2 {
 					pubkey, _ := hex.DecodeString(rop)
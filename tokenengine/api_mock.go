@@ -119,6 +119,20 @@ func (mr *MockITokenEngineMockRecorder) GenerateRedeemScript(needed, pubKeys int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRedeemScript", reflect.TypeOf((*MockITokenEngine)(nil).GenerateRedeemScript), needed, pubKeys)
 }
 
+// GeneratePolicyRedeemScript mocks base method
+func (m *MockITokenEngine) GeneratePolicyRedeemScript(ownerPubKey []byte, policyContract common.Address) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GeneratePolicyRedeemScript", ownerPubKey, policyContract)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// GeneratePolicyRedeemScript indicates an expected call of GeneratePolicyRedeemScript
+func (mr *MockITokenEngineMockRecorder) GeneratePolicyRedeemScript(ownerPubKey, policyContract interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePolicyRedeemScript", reflect.TypeOf((*MockITokenEngine)(nil).GeneratePolicyRedeemScript), ownerPubKey, policyContract)
+}
+
 // DisasmString mocks base method
 func (m *MockITokenEngine) DisasmString(script []byte) (string, error) {
 	m.ctrl.T.Helper()
@@ -223,15 +237,15 @@ func (mr *MockITokenEngineMockRecorder) ScriptValidate(utxoLockScript, pickupJur
 }
 
 // ScriptValidate1Msg mocks base method
-func (m *MockITokenEngine) ScriptValidate1Msg(utxoLockScripts map[string][]byte, pickupJuryRedeemScript PickupJuryRedeemScript, tx *modules.Transaction, msgIdx int) error {
+func (m *MockITokenEngine) ScriptValidate1Msg(utxoLockScripts map[string][]byte, pickupJuryRedeemScript PickupJuryRedeemScript, tx, fullTx *modules.Transaction, msgIdx int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ScriptValidate1Msg", utxoLockScripts, pickupJuryRedeemScript, tx, msgIdx)
+	ret := m.ctrl.Call(m, "ScriptValidate1Msg", utxoLockScripts, pickupJuryRedeemScript, tx, fullTx, msgIdx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ScriptValidate1Msg indicates an expected call of ScriptValidate1Msg
-func (mr *MockITokenEngineMockRecorder) ScriptValidate1Msg(utxoLockScripts, pickupJuryRedeemScript, tx, msgIdx interface{}) *gomock.Call {
+func (mr *MockITokenEngineMockRecorder) ScriptValidate1Msg(utxoLockScripts, pickupJuryRedeemScript, tx, fullTx, msgIdx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptValidate1Msg", reflect.TypeOf((*MockITokenEngine)(nil).ScriptValidate1Msg), utxoLockScripts, pickupJuryRedeemScript, tx, msgIdx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptValidate1Msg", reflect.TypeOf((*MockITokenEngine)(nil).ScriptValidate1Msg), utxoLockScripts, pickupJuryRedeemScript, tx, fullTx, msgIdx)
 }
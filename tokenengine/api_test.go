@@ -750,3 +750,65 @@ func BenchmarkScriptValidate(b *testing.B) {
 //	}
 //	t.Logf("Cost time:%v", time.Since(start))
 //}
+
+//构造一个策略账户，所有者签名后，只有交易中包含对策略合约的spend调用且执行成功时才能解锁
+func TestPolicyAccountSpend(t *testing.T) {
+	redeemScript := Instance.GeneratePolicyRedeemScript(pubKey1B, syscontract.PolicyContractAddress)
+	r, _ := Instance.DisasmString(redeemScript)
+	t.Logf("RedeemScript: %s\n", r)
+	account := crypto.ScriptToAddress(redeemScript)
+	lockScript := Instance.GenerateLockScript(account)
+
+	tx := &modules.Transaction{}
+	payment := &modules.PaymentPayload{}
+	utxoTxId := common.HexToHash("1111870aa8c894376dbd960a22171d0ad7be057a730e14d7103ed4a6dbb34873")
+	outPoint := modules.NewOutPoint(utxoTxId, 0, 0)
+	payment.AddTxIn(modules.NewTxIn(outPoint, []byte{}))
+	payment.AddTxOut(modules.NewTxOut(1, Instance.GenerateLockScript(address2), &modules.Asset{}))
+	tx.AddMessage(modules.NewMessage(modules.APP_PAYMENT, payment))
+	reqMsg := modules.NewMessage(modules.APP_CONTRACT_INVOKE_REQUEST, &modules.ContractInvokeRequestPayload{
+		ContractId: syscontract.PolicyContractAddress.Bytes(),
+		Args:       [][]byte{[]byte(modules.PolicySpend), []byte(account.String())},
+	})
+	tx.AddMessage(reqMsg)
+
+	getPubKeyFn := func(addr common.Address) ([]byte, error) {
+		return crypto.CompressPubkey(&prvKey1.PublicKey), nil
+	}
+	getSignFn := func(addr common.Address, msg []byte) ([]byte, error) {
+		assert.Equal(t, address1, addr)
+		return crypto.MyCryptoLib.Sign(prvKey1B, msg)
+	}
+	utxoLockScripts := map[modules.OutPoint][]byte{*outPoint: lockScript}
+	_, err := Instance.SignTxAllPaymentInput(tx, SigHashAll, utxoLockScripts, redeemScript, getPubKeyFn, getSignFn)
+	assert.Nil(t, err)
+	err = Instance.ScriptValidate(lockScript, nil, tx, 0, 0)
+	assert.Nil(t, err, fmt.Sprintf("validate error:%s", err))
+
+	//合约执行成功
+	invoke := &modules.ContractInvokePayload{ContractId: syscontract.PolicyContractAddress.Bytes()}
+	fullTx := &modules.Transaction{}
+	fullTx.AddMessage(tx.TxMessages[0])
+	fullTx.AddMessage(reqMsg)
+	fullTx.AddMessage(modules.NewMessage(modules.APP_CONTRACT_INVOKE, invoke))
+	assert.Nil(t, Instance.ScriptValidate(lockScript, nil, fullTx, 0, 0))
+	//合约拒绝了本次支出
+	invoke.ErrMsg = modules.ContractError{Code: 500, Message: "daily limit exceeded"}
+	assert.NotNil(t, Instance.ScriptValidate(lockScript, nil, fullTx, 0, 0))
+
+	//没有调用策略合约的交易不能解锁
+	tx2 := &modules.Transaction{}
+	tx2.AddMessage(modules.NewMessage(modules.APP_PAYMENT, &modules.PaymentPayload{
+		Inputs:  []*modules.Input{modules.NewTxIn(outPoint, []byte{})},
+		Outputs: payment.Outputs,
+	}))
+	_, err = Instance.SignTxAllPaymentInput(tx2, SigHashAll, utxoLockScripts, redeemScript, getPubKeyFn, getSignFn)
+	assert.Nil(t, err)
+	assert.NotNil(t, Instance.ScriptValidate(lockScript, nil, tx2, 0, 0))
+
+	//OP_CHECK_POLICY未激活时不能解锁，激活后用请求部分验证签名，用完整交易检查合约执行结果
+	invoke.ErrMsg = modules.ContractError{}
+	scripts := map[string][]byte{outPoint.String(): lockScript}
+	assert.NotNil(t, Instance.ScriptValidate1Msg(scripts, nil, fullTx.GetRequestTx(), nil, 0))
+	assert.Nil(t, Instance.ScriptValidate1Msg(scripts, nil, fullTx.GetRequestTx(), fullTx, 0))
+}
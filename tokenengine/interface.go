@@ -18,6 +18,8 @@ type ITokenEngine interface {
 	GetAddressFromScript(lockScript []byte) (common.Address, error)
 	//根据公钥列表和需要的签名数，获得赎回脚本
 	GenerateRedeemScript(needed byte, pubKeys [][]byte) []byte
+	//根据所有者公钥和策略合约地址，获得策略账户的赎回脚本
+	GeneratePolicyRedeemScript(ownerPubKey []byte, policyContract common.Address) []byte
 	//将一个脚本二进制解析为字符串形式
 	DisasmString(script []byte) (string, error)
 	//计算要对一个Tx的msgIdx和inputInx位置进行签名，对应的Hash
@@ -41,7 +43,8 @@ type ITokenEngine interface {
 		tx *modules.Transaction,
 		msgIdx, inputIndex int) error
 	//验证tx中的某个Payment message的所有input的解锁脚本是否正确
+	//fullTx为包含合约执行结果的完整交易，供OP_CHECK_POLICY检查，为nil表示OP_CHECK_POLICY还未激活
 	ScriptValidate1Msg(utxoLockScripts map[string][]byte,
 		pickupJuryRedeemScript PickupJuryRedeemScript,
-		tx *modules.Transaction, msgIdx int) error
+		tx *modules.Transaction, fullTx *modules.Transaction, msgIdx int) error
 }
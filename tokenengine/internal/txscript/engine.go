@@ -80,6 +80,7 @@ const (
 // Engine is the virtual machine that executes scripts.
 type Engine struct {
 	tx                     modules.Transaction
+	fullTx                 *modules.Transaction // 包含合约执行结果的完整交易，用于策略脚本检查，为nil时OP_CHECK_POLICY未激活
 	dstack                 stack // data stack
 	astack                 stack // alt stack
 	scripts                [][]parsedOpcode
@@ -577,6 +578,14 @@ func (vm *Engine) SetAltStack(data [][]byte) {
 	setStack(&vm.astack, data)
 }
 
+// SetFullTx sets the complete transaction, including the contract execution
+// results, that OP_CHECK_POLICY inspects when the signed tx is only the request
+// part of it. OP_CHECK_POLICY is treated as an invalid opcode until it is set,
+// so the caller decides when the opcode is activated.
+func (vm *Engine) SetFullTx(tx *modules.Transaction) {
+	vm.fullTx = tx
+}
+
 // NewEngine returns a new script engine for the provided public key script,
 // transaction, and input index.  The flags modify the behavior of the script
 // engine according to the description provided by each flag.
@@ -236,7 +236,7 @@ const (
 	OP_UNKNOWN198          = 0xc6 // 198
 	OP_UNKNOWN199          = 0xc7 // 199
 	OP_JURY_REDEEM_EQUAL   = 0xc8 // 200
-	OP_UNKNOWN201          = 0xc9 // 201
+	OP_CHECK_POLICY        = 0xc9 // 201
 	OP_UNKNOWN202          = 0xca // 202
 	OP_UNKNOWN203          = 0xcb // 203
 	OP_UNKNOWN204          = 0xcc // 204
@@ -522,7 +522,7 @@ var opcodeArray = [256]opcode{
 	OP_UNKNOWN198:        {OP_UNKNOWN198, "OP_UNKNOWN198", 1, opcodeInvalid},
 	OP_UNKNOWN199:        {OP_UNKNOWN199, "OP_UNKNOWN199", 1, opcodeInvalid},
 	OP_JURY_REDEEM_EQUAL: {OP_JURY_REDEEM_EQUAL, "OP_JURY_REDEEM_EQUAL", 1, opcodeCheckJuryRedeemEqual},
-	OP_UNKNOWN201:        {OP_UNKNOWN201, "OP_UNKNOWN201", 1, opcodeInvalid},
+	OP_CHECK_POLICY:      {OP_CHECK_POLICY, "OP_CHECK_POLICY", 1, opcodeCheckPolicy},
 	OP_UNKNOWN202:        {OP_UNKNOWN202, "OP_UNKNOWN202", 1, opcodeInvalid},
 	OP_UNKNOWN203:        {OP_UNKNOWN203, "OP_UNKNOWN203", 1, opcodeInvalid},
 	OP_UNKNOWN204:        {OP_UNKNOWN204, "OP_UNKNOWN204", 1, opcodeInvalid},
@@ -2199,3 +2199,66 @@ func opcodeCheckJuryRedeemEqual(op *parsedOpcode, vm *Engine) error {
 	vm.dstack.PushBool(result)
 	return nil
 }
+
+// opcodeCheckPolicy treats the top item on the data stack as the contract hash
+// of a policy contract, the script being executed must be the redeem script of
+// a policy account. It pushes true only when the full tx invokes the spend
+// function of that contract for this account, and the invoke result (if the tx
+// has been executed) reports no error.
+//
+// Stack transformation: [... contractHash] -> [... bool]
+func opcodeCheckPolicy(op *parsedOpcode, vm *Engine) error {
+	//未激活时与未定义的操作码相同
+	if vm.fullTx == nil {
+		return ErrStackInvalidOpcode
+	}
+	contractHash, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+	//只能在P2SH的赎回脚本中使用
+	if !vm.bip16 || vm.scriptIdx != 2 || len(vm.savedFirstStack) == 0 {
+		vm.dstack.PushBool(false)
+		return nil
+	}
+	redeemScript := vm.savedFirstStack[len(vm.savedFirstStack)-1]
+	account := common.NewAddress(crypto.Hash160(redeemScript), common.ScriptHash).String()
+
+	tx := vm.fullTx
+	requested := false
+	for _, msg := range tx.TxMessages {
+		if msg.App != modules.APP_CONTRACT_INVOKE_REQUEST {
+			continue
+		}
+		req, ok := msg.Payload.(*modules.ContractInvokeRequestPayload)
+		if !ok || !bytes.Equal(req.ContractId, contractHash) || len(req.Args) < 2 {
+			continue
+		}
+		if string(req.Args[0]) == modules.PolicySpend && string(req.Args[1]) == account {
+			requested = true
+			break
+		}
+	}
+	if !requested {
+		vm.dstack.PushBool(false)
+		return nil
+	}
+	//只有请求还未执行时，由合约执行结果决定交易能否打包
+	if tx.IsNewContractInvokeRequest() {
+		vm.dstack.PushBool(true)
+		return nil
+	}
+	result := false
+	for _, msg := range tx.TxMessages {
+		if msg.App != modules.APP_CONTRACT_INVOKE {
+			continue
+		}
+		invoke, ok := msg.Payload.(*modules.ContractInvokePayload)
+		if ok && bytes.Equal(invoke.ContractId, contractHash) {
+			result = invoke.ErrMsg.Code == 0
+			break
+		}
+	}
+	vm.dstack.PushBool(result)
+	return nil
+}
@@ -124,6 +124,9 @@ func TestOpcodeDisasm(t *testing.T) {
 		if opcodeVal == 200 {
 			expectedStr = "OP_JURY_REDEEM_EQUAL"
 		}
+		if opcodeVal == 201 {
+			expectedStr = "OP_CHECK_POLICY"
+		}
 		pop := parsedOpcode{opcode: &opcodeArray[opcodeVal], data: data}
 		gotStr := pop.print(true)
 		if gotStr != expectedStr {
@@ -188,6 +191,9 @@ func TestOpcodeDisasm(t *testing.T) {
 		if opcodeVal == 200 {
 			expectedStr = "OP_JURY_REDEEM_EQUAL"
 		}
+		if opcodeVal == 201 {
+			expectedStr = "OP_CHECK_POLICY"
+		}
 		pop := parsedOpcode{opcode: &opcodeArray[opcodeVal], data: data}
 		gotStr := pop.print(false)
 		if gotStr != expectedStr {
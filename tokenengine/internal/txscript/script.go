@@ -67,6 +67,15 @@ func isContractHash(pops []parsedOpcode) bool {
 		pops[1].opcode.value == OP_JURY_REDEEM_EQUAL
 }
 
+//<owner pubkey> OP_CHECKSIGVERIFY <policy contract hash> OP_CHECK_POLICY
+func isPolicy(pops []parsedOpcode) bool {
+	return len(pops) == 4 &&
+		(len(pops[0].data) == 33 || len(pops[0].data) == 65) &&
+		pops[1].opcode.value == OP_CHECKSIGVERIFY &&
+		pops[2].opcode.value == OP_DATA_20 &&
+		pops[3].opcode.value == OP_CHECK_POLICY
+}
+
 // IsPayToScriptHash returns true if the script is in the standard
 // pay-to-script-hash (P2SH) format, false otherwise.
 func IsPayToScriptHash(script []byte) bool {
@@ -155,6 +155,14 @@ func sign(tx *modules.Transaction, msgIdx, idx int,
 			return nil, class, nil, 0, err
 		}
 
+		return script, class, addresses, nrequired, nil
+	case PolicyTy:
+		script, err := p2pkSignatureScript(tx, msgIdx, idx, subScript, hashType,
+			kdb, addresses[0].Address)
+		if err != nil {
+			return nil, class, nil, 0, err
+		}
+
 		return script, class, addresses, nrequired, nil
 	case MultiSigTy:
 		script, _ := signMultiSig(tx, msgIdx, idx, subScript, hashType,
@@ -52,6 +52,7 @@ const (
 	MultiSigTy                        // Multi signature.
 	NullDataTy                        // Empty data-only (provably prunable).
 	ContractHashTy                    // Pay to contract hash.
+	PolicyTy                          // Owner signature plus policy contract check.
 )
 
 // scriptClassToName houses the human-readable strings which describe each
@@ -64,6 +65,7 @@ var scriptClassToName = []string{
 	MultiSigTy:     "multisig",
 	NullDataTy:     "nulldata",
 	ContractHashTy: "contracthash",
+	PolicyTy:       "policy",
 }
 
 // String implements the Stringer interface by returning the name of
@@ -163,6 +165,8 @@ func typeOfScript(pops []parsedOpcode) ScriptClass {
 		return MultiSigTy
 	} else if isNullData(pops) {
 		return NullDataTy
+	} else if isPolicy(pops) {
+		return PolicyTy
 	}
 	return NonStandardTy
 }
@@ -185,7 +189,7 @@ func GetScriptClass(script []byte) ScriptClass {
 // while finding out the type).
 func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
 	switch class {
-	case PubKeyTy:
+	case PubKeyTy, PolicyTy:
 		return 1
 
 	case PubKeyHashTy:
@@ -478,6 +482,14 @@ func ExtractPkScriptAddrs(pkScript []byte) (ScriptClass, []AddressOriginalData,
 			addrs = append(addrs, addr)
 		}
 
+	case PolicyTy:
+		// A policy redeem script is of the form:
+		//  <pubkey> OP_CHECKSIGVERIFY <contract hash> OP_CHECK_POLICY
+		// Only the owner pubkey needs to sign.
+		requiredSigs = 1
+		addr := NewAddressOriginalData(pops[0].data, PubKeyTy)
+		addrs = append(addrs, addr)
+
 	case NullDataTy:
 		// Null data transactions have no addresses or required
 		// signatures.
@@ -63,6 +63,7 @@ const (
 	TxValidationCode_INVALID_DOUBLE_SPEND         ValidationCode = 35
 	TxValidationCode_INVALID_TOKEN_STATUS         ValidationCode = 36
 	TxValidationCode_NOT_COMPARE_SIZE             ValidationCode = 37
+	TxValidationCode_INVALID_EVIDENCE             ValidationCode = 38
 	TxValidationCode_ORPHAN                       ValidationCode = 255
 
 	TxValidationCode_INVALID_OTHER_REASON         ValidationCode = 251
@@ -78,6 +79,8 @@ const (
 	UNIT_STATE_INVALID_HEADER_NUMBER     ValidationCode = 109
 	UNIT_STATE_INVALID_HEADER_TXROOT     ValidationCode = 110
 	UNIT_STATE_INVALID_HEADER_TIME       ValidationCode = 111
+	UNIT_STATE_INVALID_HEADER_UTXOROOT   ValidationCode = 112
+	UNIT_STATE_INVALID_HEADER_STATEROOT  ValidationCode = 113
 	UNIT_STATE_ORPHAN                    ValidationCode = 254
 )
 
@@ -120,6 +123,7 @@ var validationCode_name = map[byte]string{
 	35:  "DOUBLE_SPEND",
 	36:  "INVALID_TOKEN_STATUS",
 	37:  "NOT_COMPARE_SIZE",
+	38:  "INVALID_EVIDENCE",
 	101: "AUTHOR_SIGNATURE_PASSED",
 	102: "UNIT_STATE_INVALID_MEDIATOR_SCHEDULE",
 	103: "INVALID_AUTHOR_SIGNATURE",
@@ -131,6 +135,8 @@ var validationCode_name = map[byte]string{
 	109: "CHECK_HEADER_PASSED",
 	110: "UNIT_STATE_INVALID_HEADER_TXROOT",
 	111: "INVALID_HEADER_TIME",
+	112: "INVALID_HEADER_UTXOROOT",
+	113: "INVALID_HEADER_STATEROOT",
 	125: "OTHER_ERROR",
 
 	251: "NOT_VALIDATED",
@@ -51,6 +51,16 @@ type IUtxoQuery interface {
 	GetStxoEntry(outpoint *modules.OutPoint) (*modules.Stxo, error)
 }
 
+//可以查询UTXO集合承诺的IUtxoQuery才会验证单元头的UtxoRoot
+type IUtxoRootQuery interface {
+	GetUtxoRoot() (common.Hash, error)
+}
+
+//可以查询合约状态承诺的IStateQuery才会验证单元头的StateRoot
+type IStateRootQuery interface {
+	GetStateRoot() (common.Hash, error)
+}
+
 type IStateQuery interface {
 	GetContractTpl(tplId []byte) (*modules.ContractTemplate, error)
 	//获得系统配置的最低手续费要求
@@ -74,5 +84,6 @@ type IPropQuery interface {
 	GetSlotAtTime(when time.Time) uint32
 	GetScheduledMediator(slotNum uint32) common.Address
 	GetNewestUnitTimestamp(token modules.AssetId) (int64, error)
+	GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error)
 	GetChainParameters() *core.ChainParameters
 }
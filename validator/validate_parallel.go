@@ -0,0 +1,179 @@
+/*
+ *
+ *    This file is part of go-palletone.
+ *    go-palletone is free software: you can redistribute it and/or modify
+ *    it under the terms of the GNU General Public License as published by
+ *    the Free Software Foundation, either version 3 of the License, or
+ *    (at your option) any later version.
+ *    go-palletone is distributed in the hope that it will be useful,
+ *    but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *    GNU General Public License for more details.
+ *    You should have received a copy of the GNU General Public License
+ *    along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+ * /
+ *
+ *  * @author PalletOne core developer <dev@pallet.one>
+ *  * @date 2018-2019
+ *
+ */
+
+package validator
+
+import (
+	"sync"
+
+	"github.com/palletone/go-palletone/common"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+// 少于该数量的交易不值得并行验证
+const minParallelValidateTxs = 4
+
+// 单条交易的验证结果
+type txValidateResult struct {
+	validated bool
+	additions []*modules.Addition
+	code      ValidationCode
+}
+
+// 交易之间的依赖，花费同一个outpoint，或者读写同一个合约的同一个key
+type txDepKey struct {
+	outpoint   modules.OutPoint
+	contractId string
+	key        string
+}
+
+func contractDepKeys(tx *modules.Transaction) []txDepKey {
+	keys := []txDepKey{}
+	for _, msg := range tx.TxMessages {
+		if msg.App != modules.APP_CONTRACT_INVOKE {
+			continue
+		}
+		payload := msg.Payload.(*modules.ContractInvokePayload)
+		for _, rs := range payload.ReadSet {
+			cid := rs.ContractId
+			if len(cid) == 0 {
+				cid = payload.ContractId
+			}
+			keys = append(keys, txDepKey{contractId: string(cid), key: rs.Key})
+		}
+		for _, ws := range payload.WriteSet {
+			cid := ws.ContractId
+			if len(cid) == 0 {
+				cid = payload.ContractId
+			}
+			keys = append(keys, txDepKey{contractId: string(cid), key: ws.Key})
+		}
+	}
+	return keys
+}
+
+//按依赖关系将交易分组，有依赖的交易在同一组中并保持原有顺序，不同组之间可以并行验证
+//依赖包括：花费本组其他交易的输出，花费同一个outpoint，读写同一个合约key
+func groupDependentTxs(txs modules.Transactions) [][]int {
+	parent := make([]int, len(txs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		ri, rj := find(i), find(j)
+		if ri == rj {
+			return
+		}
+		//以较早的交易作为根，便于保持顺序
+		if ri < rj {
+			parent[rj] = ri
+		} else {
+			parent[ri] = rj
+		}
+	}
+
+	txIndex := make(map[common.Hash]int, len(txs))
+	for i, tx := range txs {
+		txIndex[tx.Hash()] = i
+	}
+	owners := make(map[txDepKey]int)
+	for i, tx := range txs {
+		keys := contractDepKeys(tx)
+		for _, outpoint := range tx.GetSpendOutpoints() {
+			if j, ok := txIndex[outpoint.TxHash]; ok {
+				union(i, j)
+			}
+			keys = append(keys, txDepKey{outpoint: *outpoint})
+		}
+		for _, key := range keys {
+			if j, ok := owners[key]; ok {
+				union(i, j)
+			} else {
+				owners[key] = i
+			}
+		}
+	}
+
+	groups := [][]int{}
+	groupIndex := make(map[int]int)
+	for i := range txs {
+		root := find(i)
+		gi, ok := groupIndex[root]
+		if !ok {
+			gi = len(groups)
+			groupIndex[root] = gi
+			groups = append(groups, []int{})
+		}
+		groups[gi] = append(groups[gi], i)
+	}
+	return groups
+}
+
+//用工作池并行验证没有依赖关系的交易，组内按顺序验证，遇到无效交易后跳过该组剩余交易
+//交易的新Utxo写入unitUtxo，供组内后续交易使用
+func (validate *Validate) validateTxsParallel(txs modules.Transactions, unitUtxo *sync.Map,
+	unitHeight uint64) []*txValidateResult {
+	results := make([]*txValidateResult, len(txs))
+	for i := range results {
+		results[i] = &txValidateResult{}
+	}
+	groups := groupDependentTxs(txs)
+
+	workers := validate.workers
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+	groupCh := make(chan []int, len(groups))
+	for _, g := range groups {
+		groupCh <- g
+	}
+	close(groupCh)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range groupCh {
+				for _, i := range g {
+					tx := txs[i]
+					additions, code, _ := validate.validateTxAtHeight(tx, true, unitHeight)
+					results[i] = &txValidateResult{validated: true, additions: additions, code: code}
+					if code != TxValidationCode_VALID {
+						break
+					}
+					for outPoint, utxo := range tx.GetNewUtxos() {
+						unitUtxo.Store(outPoint, utxo)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
@@ -39,7 +39,7 @@ import (
 //3. Unlock correct
 //4.Blacklist check, fromAddr toAddr must not in blacklist
 func (validate *Validate) validatePaymentPayload(tx *modules.Transaction, msgIdx int,
-	payment *modules.PaymentPayload, usedUtxo map[string]bool) ValidationCode {
+	payment *modules.PaymentPayload, usedUtxo map[string]bool, unitHeight uint64) ValidationCode {
 	txId := tx.Hash()
 	//if payment.LockTime > 0 {
 	//	// TODO check locktime
@@ -145,7 +145,12 @@ func (validate *Validate) validatePaymentPayload(tx *modules.Transaction, msgIdx
 
 		}
 		t1 := time.Now()
-		err := validate.tokenEngine.ScriptValidate1Msg(utxoScriptMap, validate.pickJuryFn, txForSign, msgIdx)
+		//策略账户的脚本需要检查完整交易中的合约执行结果
+		var fullTx *modules.Transaction
+		if validate.isPolicyScriptActive(unitHeight) {
+			fullTx = tx
+		}
+		err := validate.tokenEngine.ScriptValidate1Msg(utxoScriptMap, validate.pickJuryFn, txForSign, fullTx, msgIdx)
 		if err != nil {
 			return TxValidationCode_INVALID_PAYMMENT_INPUT
 		} else {
@@ -262,3 +267,13 @@ func (validate *Validate) generateJuryRedeemScript(jury *modules.ElectionNode) [
 	}
 	return validate.tokenEngine.GenerateRedeemScript(needed, pubKeys)
 }
+
+//OP_CHECK_POLICY从链参数PolicyScriptHeight开始启用，按待验证交易所在unit的高度判断
+//无法获得链参数时视为未启用，使用该操作码的脚本验证失败
+func (validate *Validate) isPolicyScriptActive(unitHeight uint64) bool {
+	cp := validate.chainParameters()
+	if cp == nil {
+		return false
+	}
+	return unitHeight >= cp.PolicyScriptHeight
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/core"
 	"github.com/palletone/go-palletone/dag/constants"
 	"github.com/palletone/go-palletone/dag/dagconfig"
 	"github.com/palletone/go-palletone/dag/modules"
@@ -45,8 +46,10 @@ Tx的第一条Msg必须是Payment
 	4.  如果是系统合约的请求和结果，必须重新运行合约，保证结果一致
 To validate one transaction
 如果isFullTx为false，意味着这个Tx还没有被陪审团处理完，所以结果部分的Payment不验证
+unitHeight为交易所在unit的高度，按高度激活的功能据此判断
 */
-func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool) (ValidationCode, []*modules.Addition) {
+func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool,
+	unitHeight uint64) (ValidationCode, []*modules.Addition) {
 	if len(tx.TxMessages) == 0 {
 		return TxValidationCode_INVALID_MSG, nil
 	}
@@ -61,6 +64,7 @@ func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool) (Va
 	hasRequestMsg := false
 	requestMsgIndex := 9999
 	isSysContractCall := false
+	costLimit := uint64(0)
 	usedUtxo := make(map[string]bool) //Cached all used utxo in this tx
 	for msgIdx, msg := range tx.TxMessages {
 		// check message type and payload
@@ -84,7 +88,7 @@ func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool) (Va
 			if msgIdx > requestMsgIndex && !isFullTx {
 				log.Debugf("Tx reqid[%s] is processing tx, don't need validate result payment", tx.RequestHash().String())
 			} else {
-				validateCode := validate.validatePaymentPayload(tx, msgIdx, payment, usedUtxo)
+				validateCode := validate.validatePaymentPayload(tx, msgIdx, payment, usedUtxo, unitHeight)
 				if validateCode != TxValidationCode_VALID {
 					if validateCode == TxValidationCode_ORPHAN {
 						isOrphanTx = true
@@ -112,6 +116,11 @@ func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool) (Va
 			}
 		case modules.APP_CONTRACT_INVOKE:
 			payload, _ := msg.Payload.(*modules.ContractInvokePayload)
+			//cost不能超过请求中的上限，系统合约不计价
+			if payload.Cost > costLimit {
+				log.Warnf("invoke cost[%d] exceeded the limit[%d]", payload.Cost, costLimit)
+				return TxValidationCode_INVALID_CONTRACT, txFee
+			}
 			validateCode := validate.validateContractState(payload.ContractId, &payload.ReadSet, &payload.WriteSet)
 			if validateCode != TxValidationCode_VALID {
 				return validateCode, txFee
@@ -158,6 +167,8 @@ func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool) (Va
 			contractId := payload.ContractId
 			if common.IsSystemContractAddress(contractId) {
 				isSysContractCall = true
+			} else if costLimit = payload.CostLimit; costLimit == 0 {
+				costLimit = validate.contractCosts().ContractCostLimit
 			}
 		case modules.APP_CONTRACT_STOP_REQUEST:
 			payload, _ := msg.Payload.(*modules.ContractStopRequestPayload)
@@ -193,6 +204,18 @@ func (validate *Validate) validateTx(tx *modules.Transaction, isFullTx bool) (Va
 		case modules.APP_ACCOUNT_UPDATE:
 			return validate.validateVoteMediatorTx(msg.Payload), txFee
 
+		case modules.APP_MEDIATOR_EVIDENCE:
+			validateCode := validate.validateMediatorEvidence(msg.Payload)
+			if validateCode != TxValidationCode_VALID {
+				return validateCode, txFee
+			}
+
+		case modules.APP_JUROR_EVIDENCE:
+			validateCode := validate.validateJurorEvidence(msg.Payload)
+			if validateCode != TxValidationCode_VALID {
+				return validateCode, txFee
+			}
+
 		default:
 			return TxValidationCode_UNKNOWN_TX_TYPE, txFee
 		}
@@ -253,6 +276,74 @@ func (v *Validate) validateVoteMediatorTx(payload interface{}) ValidationCode {
 	return TxValidationCode_VALID
 }
 
+//验证mediator重复出块的证据：两个header必须由同一个mediator在同一个slot签名
+func (v *Validate) validateMediatorEvidence(payload interface{}) ValidationCode {
+	evidence, ok := payload.(*modules.MediatorEvidencePayload)
+	if !ok {
+		log.Errorf("tx payload do not match type")
+		return TxValidationCode_UNSUPPORTED_TX_PAYLOAD
+	}
+
+	mediator, err := evidence.Verify()
+	if err != nil {
+		log.Warnf("invalid mediator evidence: %v", err.Error())
+		return TxValidationCode_INVALID_EVIDENCE
+	}
+
+	if v.statequery != nil && !v.statequery.GetMediators()[mediator] {
+		log.Warnf("the author(%v) of evidence is not mediator", mediator.String())
+		return TxValidationCode_INVALID_EVIDENCE
+	}
+
+	return TxValidationCode_VALID
+}
+
+//验证陪审员返回不一致结果的证据：签名有效，且与链上被接受的结果属于同一请求但内容不同
+func (v *Validate) validateJurorEvidence(payload interface{}) ValidationCode {
+	evidence, ok := payload.(*modules.JurorEvidencePayload)
+	if !ok {
+		log.Errorf("tx payload do not match type")
+		return TxValidationCode_UNSUPPORTED_TX_PAYLOAD
+	}
+
+	juror, err := evidence.Verify()
+	if err != nil {
+		log.Warnf("invalid juror evidence: %v", err.Error())
+		return TxValidationCode_INVALID_EVIDENCE
+	}
+
+	if v.dagquery != nil {
+		accepted, err := v.dagquery.GetTransactionOnly(evidence.AcceptedTx)
+		if err != nil {
+			log.Warnf("the accepted tx[%s] of juror evidence not found", evidence.AcceptedTx.String())
+			return TxValidationCode_INVALID_EVIDENCE
+		}
+		if err := evidence.CheckDivergent(accepted); err != nil {
+			log.Warnf("invalid juror evidence: %v", err.Error())
+			return TxValidationCode_INVALID_EVIDENCE
+		}
+	}
+
+	if v.statequery != nil {
+		result, _ := evidence.ResultTx()
+		jury, err := v.statequery.GetContractJury(result.ContractIdBytes())
+		if err != nil || !jury.IsJuror(juror) {
+			log.Warnf("the signer(%v) of evidence is not juror", juror.String())
+			return TxValidationCode_INVALID_EVIDENCE
+		}
+	}
+
+	return TxValidationCode_VALID
+}
+
+//用户合约计价的系统参数，没有设置propquery时使用默认值
+func (validate *Validate) contractCosts() core.ChainParametersBase {
+	if cp := validate.chainParameters(); cp != nil {
+		return cp.ChainParametersBase
+	}
+	return core.NewChainParametersBase()
+}
+
 //验证手续费是否合法，并返回手续费的分配情况
 func (validate *Validate) validateTxFee(tx *modules.Transaction) (bool, []*modules.Addition) {
 	if validate.utxoquery == nil {
@@ -274,20 +365,39 @@ func (validate *Validate) validateTxFee(tx *modules.Transaction) (bool, []*modul
 			return true, feeAllocate
 		}
 	}
-	if minFee.Amount > 0 { //需要验证最小手续费
+	//用户合约按执行的cost收取手续费，不低于最小手续费
+	needFee := minFee.Amount
+	if costFee := getContractCost(tx) * validate.contractCosts().ContractCostUnitFee; costFee > needFee {
+		needFee = costFee
+	}
+	if needFee > 0 { //需要验证最小手续费
 		total := uint64(0)
 		var feeAsset *modules.Asset
 		for _, a := range feeAllocate {
 			total += a.Amount
 			feeAsset = a.Asset
 		}
-		if feeAsset.String() != minFee.Asset.String() || total < minFee.Amount {
+		if feeAsset == nil || feeAsset.String() != minFee.Asset.String() || total < needFee {
+			log.Debugf("tx[%s] fee[%d] is less than needed[%d]", tx.Hash().String(), total, needFee)
 			return false, feeAllocate
 		}
 	}
 	return true, feeAllocate
 }
 
+//交易中所有合约调用结果的cost之和
+func getContractCost(tx *modules.Transaction) uint64 {
+	cost := uint64(0)
+	for _, msg := range tx.TxMessages {
+		if msg.App == modules.APP_CONTRACT_INVOKE {
+			if invoke, ok := msg.Payload.(*modules.ContractInvokePayload); ok {
+				cost += invoke.Cost
+			}
+		}
+	}
+	return cost
+}
+
 /**
 检查message的app与payload是否一致
 check messaage 'app' consistent with payload type
@@ -342,6 +452,14 @@ func validateMessageType(app modules.MessageType, payload interface{}) bool {
 		if app == modules.APP_CONTRACT_STOP {
 			return true
 		}
+	case *modules.MediatorEvidencePayload:
+		if app == modules.APP_MEDIATOR_EVIDENCE {
+			return true
+		}
+	case *modules.JurorEvidencePayload:
+		if app == modules.APP_JUROR_EVIDENCE {
+			return true
+		}
 
 	default:
 		log.Debug("The payload of message type is unexpected. ", "payload_type", t, "app type", app)
@@ -267,3 +267,13 @@ func TestValidateLargeInputPayment(t *testing.T) {
 	t.Logf("Validate send time:%s", time.Since(t1))
 	assert.Nil(t, err)
 }
+
+func TestGetContractCost(t *testing.T) {
+	tx := &modules.Transaction{}
+	tx.AddMessage(modules.NewMessage(modules.APP_CONTRACT_INVOKE_REQUEST,
+		&modules.ContractInvokeRequestPayload{ContractId: []byte("contract"), CostLimit: 1000}))
+	assert.Equal(t, uint64(0), getContractCost(tx))
+	tx.AddMessage(modules.NewMessage(modules.APP_CONTRACT_INVOKE,
+		&modules.ContractInvokePayload{ContractId: []byte("contract"), Cost: 800}))
+	assert.Equal(t, uint64(800), getContractCost(tx))
+}
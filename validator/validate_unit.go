@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/common/log"
 	"github.com/palletone/go-palletone/configure"
@@ -144,6 +145,11 @@ func (validate *Validate) validateMediatorSchedule(header *modules.Header) Valid
 	return TxValidationCode_VALID
 }
 
+//不基于数据库，只验证单元头中作者的签名，用于在处理单元的交易之前过滤伪造的单元
+func ValidateUnitSignature(h *modules.Header) error {
+	return NewValidateError(validateUnitSignature(h))
+}
+
 //不基于数据库，进行Unit最基本的验证
 func ValidateUnitBasic(unit *modules.Unit) error {
 	return NewValidateError(validateUnitBasic(unit))
@@ -223,6 +229,15 @@ func (validate *Validate) ValidateUnitExceptGroupSig(unit *modules.Unit) Validat
 		log.Debugf("Validate unit's header failed, root:[%#x],  unit.UnitHeader.TxRoot:[%#x], txs:[%#x]", root, unit.UnitHeader.TxRoot, unit.Txs.GetTxIds())
 		return UNIT_STATE_INVALID_HEADER_TXROOT
 	}
+	//validate utxo root and state root, 孤儿单元没有父单元之后的状态
+	if unitHeaderValidateResult != UNIT_STATE_ORPHAN {
+		if code := validate.validateUtxoRoot(unit.UnitHeader); code != TxValidationCode_VALID {
+			return code
+		}
+		if code := validate.validateStateRoot(unit.UnitHeader); code != TxValidationCode_VALID {
+			return code
+		}
+	}
 
 	// step2. check transactions in unit
 	medAdd := unit.Author()
@@ -232,7 +247,7 @@ func (validate *Validate) ValidateUnitExceptGroupSig(unit *modules.Unit) Validat
 		return UNIT_STATE_INVALID_AUTHOR_SIGNATURE
 	}
 
-	code := validate.validateTransactions(unit.Txs, unit.Timestamp(), med.GetRewardAdd())
+	code := validate.validateTransactions(unit.Txs, unit.Timestamp(), unit.NumberU64(), med)
 	if code != TxValidationCode_VALID {
 		msg := fmt.Sprintf("Validate unit(%s) transactions failed: %v", unit.UnitHash.String(), code)
 		log.Debug(msg)
@@ -327,3 +342,75 @@ func (validate *Validate) ValidateHeader(h *modules.Header) ValidationCode {
 	}
 	return TxValidationCode_VALID
 }
+
+func (validate *Validate) chainParameters() *core.ChainParameters {
+	if validate.propquery == nil {
+		return nil
+	}
+	return validate.propquery.GetChainParameters()
+}
+
+//未打包交易将要进入的unit高度，无法获得最新unit时返回0，按高度激活的功能视为未激活
+func (validate *Validate) nextUnitHeight() uint64 {
+	if validate.propquery == nil {
+		return 0
+	}
+	_, index, err := validate.propquery.GetNewestUnit(dagconfig.DagConfig.GetGasToken())
+	if err != nil || index == nil {
+		log.Debugf("GetNewestUnit failed, validate tx at height 0: %v", err)
+		return 0
+	}
+	return index.Index + 1
+}
+
+//单元头中的UtxoRoot必须是父单元之后的UTXO集合的承诺
+func (validate *Validate) validateUtxoRoot(header *modules.Header) ValidationCode {
+	if cp := validate.chainParameters(); cp == nil || !cp.IsUtxoCommitmentEnabled(header.NumberU64()) {
+		if header.UtxoRoot != (common.Hash{}) {
+			log.Debugf("Unit[%s] must not have utxo root before activation", header.Hash().String())
+			return UNIT_STATE_INVALID_HEADER_UTXOROOT
+		}
+		return TxValidationCode_VALID
+	}
+	query, ok := validate.utxoquery.(IUtxoRootQuery)
+	if !ok {
+		return TxValidationCode_VALID
+	}
+	root, err := query.GetUtxoRoot()
+	if err != nil {
+		log.Warnf("GetUtxoRoot failed:%s", err.Error())
+		return UNIT_STATE_INVALID_HEADER_UTXOROOT
+	}
+	if root != header.UtxoRoot {
+		log.Debugf("Validate unit's header failed, utxo root:[%#x], unit.UnitHeader.UtxoRoot:[%#x]",
+			root, header.UtxoRoot)
+		return UNIT_STATE_INVALID_HEADER_UTXOROOT
+	}
+	return TxValidationCode_VALID
+}
+
+//单元头中的StateRoot必须是父单元之后的合约状态的承诺
+func (validate *Validate) validateStateRoot(header *modules.Header) ValidationCode {
+	if cp := validate.chainParameters(); cp == nil || !cp.IsStateCommitmentEnabled(header.NumberU64()) {
+		if header.StateRoot != (common.Hash{}) {
+			log.Debugf("Unit[%s] must not have state root before activation", header.Hash().String())
+			return UNIT_STATE_INVALID_HEADER_STATEROOT
+		}
+		return TxValidationCode_VALID
+	}
+	query, ok := validate.statequery.(IStateRootQuery)
+	if !ok {
+		return TxValidationCode_VALID
+	}
+	root, err := query.GetStateRoot()
+	if err != nil {
+		log.Warnf("GetStateRoot failed:%s", err.Error())
+		return UNIT_STATE_INVALID_HEADER_STATEROOT
+	}
+	if root != header.StateRoot {
+		log.Debugf("Validate unit's header failed, state root:[%#x], unit.UnitHeader.StateRoot:[%#x]",
+			root, header.StateRoot)
+		return UNIT_STATE_INVALID_HEADER_STATEROOT
+	}
+	return TxValidationCode_VALID
+}
@@ -23,13 +23,17 @@ package validator
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/syscontract"
+	"github.com/palletone/go-palletone/core"
+	"github.com/palletone/go-palletone/core/groupsign"
 	"github.com/palletone/go-palletone/dag/dagconfig"
+	"github.com/palletone/go-palletone/dag/errors"
 	"github.com/palletone/go-palletone/dag/modules"
 	"github.com/palletone/go-palletone/dag/palletcache"
 	"github.com/palletone/go-palletone/dag/parameter"
 	"github.com/palletone/go-palletone/tokenengine"
+	"runtime"
 	"sync"
 )
 
@@ -40,6 +44,7 @@ type Validate struct {
 	propquery   IPropQuery
 	tokenEngine tokenengine.ITokenEngine
 	cache       *ValidatorCache
+	workers     int // 并行验证单元内交易的协程数，不大于1时逐条验证
 }
 
 const MAX_DATA_PAYLOAD_MAIN_DATA_SIZE = 128
@@ -54,6 +59,7 @@ func NewValidate(dagdb IDagQuery, utxoRep IUtxoQuery, statedb IStateQuery, propq
 		statequery:  statedb,
 		propquery:   propquery,
 		tokenEngine: tokenengine.Instance,
+		workers:     runtime.NumCPU(),
 	}
 }
 
@@ -76,9 +82,12 @@ func (validate *Validate) setUtxoQuery(q IUtxoQuery) {
 	validate.utxoquery = q
 }
 
-//逐条验证每一个Tx，并返回总手续费的分配情况，然后与Coinbase进行比较
-func (validate *Validate) validateTransactions(txs modules.Transactions, unitTime int64, unitAuthor common.Address) ValidationCode {
+//验证每一个Tx，并返回总手续费的分配情况，然后与Coinbase进行比较
+//没有依赖关系的交易会并行验证，但仍按交易顺序检查结果，返回的错误与逐条验证时相同
+func (validate *Validate) validateTransactions(txs modules.Transactions, unitTime int64, unitHeight uint64,
+	med *core.Mediator) ValidationCode {
 	ads := []*modules.Addition{}
+	unitAuthor := med.GetRewardAdd()
 
 	oldUtxoQuery := validate.utxoquery
 
@@ -88,6 +97,11 @@ func (validate *Validate) validateTransactions(txs modules.Transactions, unitTim
 	defer validate.setUtxoQuery(oldUtxoQuery)
 	spendOutpointMap := make(map[*modules.OutPoint]bool)
 	var coinbase *modules.Transaction
+	var results []*txValidateResult
+	if validate.workers > 1 && len(txs) > minParallelValidateTxs {
+		//第一条是Coinbase，不参与并行验证
+		results = append([]*txValidateResult{nil}, validate.validateTxsParallel(txs[1:], unitUtxo, unitHeight)...)
+	}
 	for txIndex, tx := range txs {
 		//先检查普通交易并计算手续费，最后检查Coinbase
 		txHash := tx.Hash()
@@ -100,7 +114,14 @@ func (validate *Validate) validateTransactions(txs modules.Transactions, unitTim
 			//每个单元的第一条交易比较特殊，是Coinbase交易，其包含增发和收集的手续费
 
 		}
-		txFeeAllocate, txCode, _ := validate.ValidateTx(tx, true)
+		var txFeeAllocate []*modules.Addition
+		var txCode ValidationCode
+		validated := results != nil && results[txIndex].validated
+		if validated {
+			txFeeAllocate, txCode = results[txIndex].additions, results[txIndex].code
+		} else {
+			txFeeAllocate, txCode, _ = validate.validateTxAtHeight(tx, true, unitHeight)
+		}
 		if txCode != TxValidationCode_VALID {
 			log.Debug("ValidateTx", "txhash", txHash, "error validate code", txCode)
 			return txCode
@@ -123,6 +144,10 @@ func (validate *Validate) validateTransactions(txs modules.Transactions, unitTim
 			ads = append(ads, a)
 		}
 
+		if validated {
+			//并行验证时已经写入
+			continue
+		}
 		for outPoint, utxo := range tx.GetNewUtxos() {
 			log.Debugf("Add tx utxo for key:%s", outPoint.String())
 			unitUtxo.Store(outPoint, utxo)
@@ -132,13 +157,13 @@ func (validate *Validate) validateTransactions(txs modules.Transactions, unitTim
 	}
 	//验证第一条交易
 	if len(txs) > 0 {
-		//附加上出块奖励
-		a := &modules.Addition{
-			Addr:   unitAuthor,
-			Amount: parameter.CurrentSysParameters.GenerateUnitReward,
-			Asset:  dagconfig.DagConfig.GetGasToken().ToAsset(),
+		//附加上出块奖励，按佣金比例与委托人分享
+		rewardAds, err := validate.computeGenerateUnitReward(med)
+		if err != nil {
+			log.Debugf("compute generate unit reward error:%s", err.Error())
+			return TxValidationCode_STATE_DATA_NOT_FOUND
 		}
-		ads = append(ads, a)
+		ads = append(ads, rewardAds...)
 		out := arrangeAdditionFeeList(ads)
 		log.DebugDynamic(func() string {
 			data, _ := json.Marshal(out)
@@ -162,6 +187,25 @@ func (validate *Validate) validateTransactions(txs modules.Transactions, unitTim
 	return TxValidationCode_VALID
 }
 
+func (validate *Validate) computeGenerateUnitReward(med *core.Mediator) ([]*modules.Addition, error) {
+	states, err := validate.statequery.GetContractStatesByPrefix(syscontract.DepositContractAddress.Bytes(),
+		modules.MediatorDelegationKeyPrefix(med.Address.String()))
+	if err != nil {
+		if !errors.IsNotFoundError(err) {
+			return nil, err
+		}
+		//没有任何委托
+		states = nil
+	}
+	delegations, err := modules.ParseMediatorDelegations(states)
+	if err != nil {
+		return nil, err
+	}
+	return modules.SplitMediatorReward(med.GetRewardAdd(), med.CommissionRate,
+		parameter.CurrentSysParameters.GenerateUnitReward, dagconfig.DagConfig.GetGasToken().ToAsset(),
+		delegations), nil
+}
+
 func arrangeAdditionFeeList(ads []*modules.Addition) []*modules.Addition {
 	if len(ads) <= 0 {
 		return nil
@@ -196,7 +240,13 @@ return all transactions' fee
 //	return NewValidateError(code)
 //}
 
+//未打包的交易按将要打包进的unit，即最新unit的下一个高度验证
 func (validate *Validate) ValidateTx(tx *modules.Transaction, isFullTx bool) ([]*modules.Addition, ValidationCode, error) {
+	return validate.validateTxAtHeight(tx, isFullTx, validate.nextUnitHeight())
+}
+
+func (validate *Validate) validateTxAtHeight(tx *modules.Transaction, isFullTx bool,
+	unitHeight uint64) ([]*modules.Addition, ValidationCode, error) {
 	txId := tx.Hash()
 	if txId.String() == "0x9c6e60e75aa59d253b156d102d6d314f21e57cdda923593346c98c30a841c64e" {
 		log.Warn("Invalid tx:0x9c6e60e75aa59d253b156d102d6d314f21e57cdda923593346c98c30a841c64e")
@@ -206,7 +256,7 @@ func (validate *Validate) ValidateTx(tx *modules.Transaction, isFullTx bool) ([]
 	if has {
 		return add, TxValidationCode_VALID, nil
 	}
-	code, addition := validate.validateTx(tx, isFullTx)
+	code, addition := validate.validateTx(tx, isFullTx, unitHeight)
 	if code == TxValidationCode_VALID {
 		validate.cache.AddTxValidateResult(txId, addition)
 		return addition, code, nil
@@ -223,10 +273,22 @@ func (validate *Validate) ValidateTx(tx *modules.Transaction, isFullTx bool) ([]
 	return addition, code, NewValidateError(code)
 }
 
-// todo
-// 验证群签名接口，需要验证群签的正确性和有效性
+// 验证群签名接口，使用链参数选定的群签名方案验证群签的正确性
 func (validate *Validate) ValidateUnitGroupSign(h *modules.Header) error {
-	return nil
+	if len(h.GetGroupPubKeyByte()) == 0 || len(h.GroupSign) == 0 {
+		return fmt.Errorf("the unit(%v) has no group public key or group signature", h.Hash().String())
+	}
+	name := ""
+	if validate.propquery != nil {
+		name = validate.propquery.GetChainParameters().GroupSignScheme
+	}
+	scheme, err := groupsign.Lookup(name)
+	if err != nil {
+		return err
+	}
+	hash := h.Hash()
+
+	return scheme.Verify(h.GetGroupPubKeyByte(), hash[:], h.GroupSign)
 }
 
 //验证一个DataPayment
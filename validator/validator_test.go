@@ -22,9 +22,11 @@ package validator
 
 import (
 	"encoding/hex"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/coocood/freecache"
 	"github.com/palletone/go-palletone/common"
 	"github.com/palletone/go-palletone/common/crypto"
 	"github.com/palletone/go-palletone/core"
@@ -51,7 +53,10 @@ func TestValidate_ValidateUnitTxs(t *testing.T) {
 	mockStatedbQuery := &mockStatedbQuery{}
 	validate := NewValidate(nil, utxoQuery, mockStatedbQuery, nil, newCache())
 	addr, _ := common.StringToAddress("P1HXNZReTByQHgWQNGMXotMyTkMG9XeEQfX")
-	code := validate.validateTransactions(txs, time.Now().Unix(), addr)
+	med := core.NewMediator()
+	med.Address = addr
+	med.RewardAdd = addr
+	code := validate.validateTransactions(txs, time.Now().Unix(), 1, med)
 	assert.Equal(t, code, TxValidationCode_VALID)
 }
 
@@ -239,6 +244,98 @@ func TestValidate_ValidateHeader(t *testing.T) {
 	assert.Equal(t, vresult, TxValidationCode_VALID)
 }
 
+type mockUtxoRootQuery struct {
+	mockUtxoQuery
+	root common.Hash
+}
+
+func (q *mockUtxoRootQuery) GetUtxoRoot() (common.Hash, error) {
+	return q.root, nil
+}
+
+type mockPropQuery struct {
+	IPropQuery
+	cp *core.ChainParameters
+}
+
+func (q *mockPropQuery) GetChainParameters() *core.ChainParameters {
+	return q.cp
+}
+
+func TestValidate_ValidateUtxoRoot(t *testing.T) {
+	header := newHeader(modules.Transactions{})
+	query := &mockUtxoRootQuery{root: common.HexToHash("0x1234")}
+	cp := core.NewChainParams()
+	v := NewValidate(nil, query, nil, &mockPropQuery{cp: &cp}, newCache())
+
+	//未启用时必须为空
+	assert.Equal(t, TxValidationCode_VALID, v.validateUtxoRoot(header))
+	header.UtxoRoot = query.root
+	assert.Equal(t, UNIT_STATE_INVALID_HEADER_UTXOROOT, v.validateUtxoRoot(header))
+
+	cp.UtxoCommitmentHeight = 1
+	assert.Equal(t, TxValidationCode_VALID, v.validateUtxoRoot(header))
+	header.UtxoRoot = common.HexToHash("0x5678")
+	assert.Equal(t, UNIT_STATE_INVALID_HEADER_UTXOROOT, v.validateUtxoRoot(header))
+}
+
+type mockStateRootQuery struct {
+	mockStatedbQuery
+	root common.Hash
+}
+
+func (q *mockStateRootQuery) GetStateRoot() (common.Hash, error) {
+	return q.root, nil
+}
+
+func TestValidate_ValidateStateRoot(t *testing.T) {
+	header := newHeader(modules.Transactions{})
+	query := &mockStateRootQuery{root: common.HexToHash("0x1234")}
+	cp := core.NewChainParams()
+	v := NewValidate(nil, nil, query, &mockPropQuery{cp: &cp}, newCache())
+
+	//未启用时必须为空
+	assert.Equal(t, TxValidationCode_VALID, v.validateStateRoot(header))
+	header.StateRoot = query.root
+	assert.Equal(t, UNIT_STATE_INVALID_HEADER_STATEROOT, v.validateStateRoot(header))
+
+	cp.StateCommitmentHeight = 1
+	assert.Equal(t, TxValidationCode_VALID, v.validateStateRoot(header))
+	header.StateRoot = common.HexToHash("0x5678")
+	assert.Equal(t, UNIT_STATE_INVALID_HEADER_STATEROOT, v.validateStateRoot(header))
+}
+
+type mockNewestUnitQuery struct {
+	mockPropQuery
+	index *modules.ChainIndex
+}
+
+func (q *mockNewestUnitQuery) GetNewestUnit(token modules.AssetId) (common.Hash, *modules.ChainIndex, error) {
+	if q.index == nil {
+		return common.Hash{}, nil, errors.ErrNotFound
+	}
+	return common.Hash{}, q.index, nil
+}
+
+func TestValidate_IsPolicyScriptActive(t *testing.T) {
+	//没有链参数时视为未启用
+	v := NewValidate(nil, nil, nil, nil, newCache())
+	assert.False(t, v.isPolicyScriptActive(100))
+	assert.Equal(t, uint64(0), v.nextUnitHeight())
+
+	cp := core.NewChainParams()
+	cp.PolicyScriptHeight = 10
+	query := &mockNewestUnitQuery{mockPropQuery: mockPropQuery{cp: &cp}}
+	v = NewValidate(nil, nil, nil, query, newCache())
+	assert.False(t, v.isPolicyScriptActive(9))
+	assert.True(t, v.isPolicyScriptActive(10))
+
+	//未打包的交易按最新unit的下一个高度判断，获取不到最新unit时按高度0
+	assert.Equal(t, uint64(0), v.nextUnitHeight())
+	query.index = &modules.ChainIndex{Index: 9}
+	assert.Equal(t, uint64(10), v.nextUnitHeight())
+}
+
 func TestSignAndVerifyATx(t *testing.T) {
 
 	privKeyBytes, _ := hex.DecodeString("2BE3B4B671FF5B8009E6876CCCC8808676C1C279EE824D0AB530294838DC1644")
@@ -301,3 +398,116 @@ func TestTime(t *testing.T) {
 	t.Log(ti.Format("2006-01-02 15:04:05"))
 	t.Log(ti.Unix())
 }
+
+//花费mockUtxoQuery中的第index个Utxo，付1Dao手续费
+func newIndependentTx(tb testing.TB, index uint32) *modules.Transaction {
+	pay1s := &modules.PaymentPayload{}
+	addr, _ := common.StringToAddress("P1HXNZReTByQHgWQNGMXotMyTkMG9XeEQfX")
+	lockScript := tokenengine.Instance.GenerateLockScript(addr)
+	pay1s.AddTxOut(modules.NewTxOut(99, lockScript, modules.NewPTNAsset()))
+	input := modules.Input{}
+	input.PreviousOutPoint = modules.NewOutPoint(common.HexToHash("1"), 0, index)
+	input.SignatureScript = []byte{}
+	pay1s.AddTxIn(&input)
+
+	tx := modules.NewTransaction([]*modules.Message{modules.NewMessage(modules.APP_PAYMENT, pay1s)})
+	lockScripts := map[modules.OutPoint][]byte{
+		*input.PreviousOutPoint: lockScript,
+	}
+	privKeyBytes, _ := hex.DecodeString("2BE3B4B671FF5B8009E6876CCCC8808676C1C279EE824D0AB530294838DC1644")
+	privKey, _ := crypto.ToECDSA(privKeyBytes)
+	getPubKeyFn := func(common.Address) ([]byte, error) {
+		return crypto.CompressPubkey(&privKey.PublicKey), nil
+	}
+	getSignFn := func(addr common.Address, msg []byte) ([]byte, error) {
+		return crypto.MyCryptoLib.Sign(privKeyBytes, msg)
+	}
+	_, err := tokenengine.Instance.SignTxAllPaymentInput(tx, 1, lockScripts, nil, getPubKeyFn, getSignFn)
+	if err != nil {
+		tb.Logf("Sign error:%s", err)
+	}
+	return tx
+}
+
+//Coinbase + count条互相独立的交易
+func newUnitTxs(tb testing.TB, count int) modules.Transactions {
+	coinbase := newCoinbaseTx()
+	coinbase.TxMessages[0].Payload.(*modules.PaymentPayload).Outputs[0].Value = uint64(count)
+	txs := modules.Transactions{coinbase}
+	for i := 0; i < count; i++ {
+		txs = append(txs, newIndependentTx(tb, uint32(i)))
+	}
+	return txs
+}
+
+func newTestMediator() *core.Mediator {
+	addr, _ := common.StringToAddress("P1HXNZReTByQHgWQNGMXotMyTkMG9XeEQfX")
+	med := core.NewMediator()
+	med.Address = addr
+	med.RewardAdd = addr
+	return med
+}
+
+func TestGroupDependentTxs(t *testing.T) {
+	tx1 := newTx1(t)
+	tx2 := newTx2(t, modules.NewOutPoint(tx1.Hash(), 0, 0))
+	tx3 := newIndependentTx(t, 3)
+	tx4 := newIndependentTx(t, 4)
+	//与tx3花费同一个outpoint
+	tx5 := newIndependentTx(t, 3)
+	tx5.TxMessages[0].Payload.(*modules.PaymentPayload).Outputs[0].Value = 98
+
+	groups := groupDependentTxs(modules.Transactions{tx1, tx3, tx2, tx4, tx5})
+	assert.Equal(t, [][]int{{0, 2}, {1, 4}, {3}}, groups)
+}
+
+func TestValidate_ValidateUnitTxsParallel(t *testing.T) {
+	defer func(reward uint64) {
+		parameter.CurrentSysParameters.GenerateUnitReward = reward
+	}(parameter.CurrentSysParameters.GenerateUnitReward)
+	parameter.CurrentSysParameters.GenerateUnitReward = 0
+	med := newTestMediator()
+	txs := newUnitTxs(t, 8)
+
+	validate := NewValidate(nil, &mockUtxoQuery{}, &mockStatedbQuery{}, nil, newCache())
+	validate.workers = 4
+	assert.Equal(t, TxValidationCode_VALID, validate.validateTransactions(txs, time.Now().Unix(), 1, med))
+
+	//第3条和第6条交易无效，并行验证与逐条验证都应返回第3条交易的错误
+	txs[3].TxMessages[0].Payload.(*modules.PaymentPayload).Inputs[0].SignatureScript = []byte{}
+	txs[6].TxMessages[0].Payload.(*modules.PaymentPayload).Outputs[0].Value = 1000
+	sequential := NewValidate(nil, &mockUtxoQuery{}, &mockStatedbQuery{}, nil, newCache())
+	sequential.workers = 1
+	code := sequential.validateTransactions(txs, time.Now().Unix(), 1, med)
+	assert.NotEqual(t, TxValidationCode_VALID, code)
+
+	parallel := NewValidate(nil, &mockUtxoQuery{}, &mockStatedbQuery{}, nil, newCache())
+	parallel.workers = 4
+	assert.Equal(t, code, parallel.validateTransactions(txs, time.Now().Unix(), 1, med))
+}
+
+func benchmarkValidateTransactions(b *testing.B, workers int) {
+	defer func(reward uint64) {
+		parameter.CurrentSysParameters.GenerateUnitReward = reward
+	}(parameter.CurrentSysParameters.GenerateUnitReward)
+	parameter.CurrentSysParameters.GenerateUnitReward = 0
+	med := newTestMediator()
+	txs := newUnitTxs(b, 200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		//每次使用新的缓存，避免直接命中已验证的结果
+		validate := NewValidate(nil, &mockUtxoQuery{}, &mockStatedbQuery{}, nil, freecache.NewCache(10*1024*1024))
+		validate.workers = workers
+		if code := validate.validateTransactions(txs, time.Now().Unix(), 1, med); code != TxValidationCode_VALID {
+			b.Fatalf("validate transactions failed, code:%v", code)
+		}
+	}
+}
+
+func BenchmarkValidateTransactions_Sequential(b *testing.B) {
+	benchmarkValidateTransactions(b, 1)
+}
+
+func BenchmarkValidateTransactions_Parallel(b *testing.B) {
+	benchmarkValidateTransactions(b, runtime.NumCPU())
+}
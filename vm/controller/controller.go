@@ -30,6 +30,8 @@ import (
 	"github.com/palletone/go-palletone/vm/ccintf"
 	"github.com/palletone/go-palletone/vm/dockercontroller"
 	"github.com/palletone/go-palletone/vm/inproccontroller"
+	"github.com/palletone/go-palletone/vm/processcontroller"
+	"github.com/palletone/go-palletone/vm/wasmcontroller"
 	"golang.org/x/net/context"
 )
 
@@ -53,8 +55,10 @@ var vmcontroller *VMController
 
 //constants for supported containers
 const (
-	DOCKER = "Docker"
-	SYSTEM = "System"
+	DOCKER  = "Docker"
+	SYSTEM  = "System"
+	WASM    = "Wasm"
+	PROCESS = "Process"
 )
 
 //NewVMController - creates/returns singleton
@@ -73,6 +77,10 @@ func (vmc *VMController) newVM(typ string) api.VM {
 		v = dockercontroller.NewDockerVM()
 	case SYSTEM:
 		v = &inproccontroller.InprocVM{}
+	case WASM:
+		v = wasmcontroller.NewWasmVM()
+	case PROCESS:
+		v = processcontroller.NewProcessVM()
 	default:
 		v = &dockercontroller.DockerVM{}
 	}
@@ -107,6 +107,19 @@ func (vm *InprocVM) Deploy(ctxt context.Context, ccid ccintf.CCID, args []string
 }
 
 func (ipc *inprocContainer) launchInProc(ctxt context.Context, id string, args []string, env []string, ccSupport ccintf.CCSupport) error {
+	if args == nil {
+		args = ipc.args
+	}
+	if env == nil {
+		env = ipc.env
+	}
+	return LaunchInProc(ctxt, id, args, env, ipc.chaincode, ccSupport, ipc.stopChan)
+}
+
+//LaunchInProc runs the chaincode in current process and connects it with the chaincode support by channels,
+//it returns when either side quits or stopChan receives
+func LaunchInProc(ctxt context.Context, id string, args []string, env []string, cc shim.Chaincode,
+	ccSupport ccintf.CCSupport, stopChan <-chan struct{}) error {
 	peerRcvCCSend := make(chan *pb.ChaincodeMessage)
 	ccRcvPeerSend := make(chan *pb.ChaincodeMessage)
 	var err error
@@ -115,13 +128,7 @@ func (ipc *inprocContainer) launchInProc(ctxt context.Context, id string, args [
 	go func() {
 		defer close(ccchan)
 		log.Debugf("chaincode started for %s", id)
-		if args == nil {
-			args = ipc.args
-		}
-		if env == nil {
-			env = ipc.env
-		}
-		err = _shimStartInProc(env, args, ipc.chaincode, ccRcvPeerSend, peerRcvCCSend)
+		err = _shimStartInProc(env, args, cc, ccRcvPeerSend, peerRcvCCSend)
 		if err != nil {
 			err = fmt.Errorf("chaincode-support ended with err: %s", err)
 			_logErrorf("%s", err)
@@ -150,7 +157,7 @@ func (ipc *inprocContainer) launchInProc(ctxt context.Context, id string, args [
 	case <-ccsupportchan:
 		close(ccRcvPeerSend)
 		log.Debugf("chaincode support %s quit", id)
-	case <-ipc.stopChan:
+	case <-stopChan:
 		close(ccRcvPeerSend)
 		close(peerRcvCCSend)
 		log.Debugf("chaincode %s stopped", id)
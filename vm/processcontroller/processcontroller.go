@@ -0,0 +1,365 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package processcontroller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/contractcfg"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	container "github.com/palletone/go-palletone/vm/api"
+	"github.com/palletone/go-palletone/vm/ccintf"
+	"golang.org/x/net/context"
+)
+
+const (
+	//ChaincodeBinName 构建包binpackage.tar中合约程序的文件名
+	ChaincodeBinName = "chaincode"
+	binPackageName   = "binpackage.tar"
+
+	peerAddressEnv = "CORE_CHAINCODE_PEER_ADDRESS"
+	peerSocketName = "peer.sock"
+)
+
+var vmRegExp = regexp.MustCompile("[^a-zA-Z0-9-_.]")
+
+type chaincodeProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+var (
+	instLock     sync.Mutex
+	instRegistry = make(map[string]*chaincodeProcess)
+)
+
+//ProcessVM 以子进程的方式运行用户合约，合约程序与docker中的相同，通过gRPC连接节点，
+//每个合约在沙箱中运行：单独的用户和namespace，根目录是合约的工作目录，并用rlimit限制资源
+type ProcessVM struct {
+}
+
+func NewProcessVM() *ProcessVM {
+	return &ProcessVM{}
+}
+
+//合约进程工作目录的根目录
+func baseDir() string {
+	if dir := contractcfg.GetConfig().UccProcessDir; dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "palletone_chaincodes")
+}
+
+func (vm *ProcessVM) workDir(ccid ccintf.CCID) string {
+	name, _ := vm.GetVMName(ccid, nil)
+	return filepath.Join(baseDir(), name)
+}
+
+//沙箱在执行合约程序前设置的rlimit，设置失败时不启动合约
+func limitArgs(cfg *contractcfg.Config, spec *pb.ChaincodeSpec) []string {
+	limits := []string{"core=0"}
+	memory := cfg.UccProcessMemory
+	if spec != nil && spec.Memory > 0 && (memory == 0 || spec.Memory < memory) {
+		memory = spec.Memory
+	}
+	if memory > 0 {
+		limits = append(limits, fmt.Sprintf("as=%d", memory))
+	}
+	if cfg.UccProcessCpuTime > 0 {
+		limits = append(limits, fmt.Sprintf("cpu=%d", cfg.UccProcessCpuTime))
+	}
+	if cfg.UccProcessMaxFiles > 0 {
+		limits = append(limits, fmt.Sprintf("nofile=%d", cfg.UccProcessMaxFiles))
+	}
+	return limits
+}
+
+//合约进程没有网络，节点在工作目录中监听unix socket，把连接转发到节点的合约服务地址
+func relayPeer(dir string, env []string) (net.Listener, []string, error) {
+	const prefix = peerAddressEnv + "="
+	peerAddr := ""
+	localEnv := make([]string, 0, len(env))
+	for _, e := range env {
+		if strings.HasPrefix(e, prefix) {
+			peerAddr = strings.TrimPrefix(e, prefix)
+			continue
+		}
+		localEnv = append(localEnv, e)
+	}
+	if peerAddr == "" {
+		return nil, nil, fmt.Errorf("%s is not set", peerAddressEnv)
+	}
+	lis, err := net.Listen("unix", filepath.Join(dir, peerSocketName))
+	if err != nil {
+		return nil, nil, err
+	}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go relay(conn, peerAddr)
+		}
+	}()
+	//沙箱中工作目录是根目录
+	return lis, append(localEnv, prefix+"unix:/"+peerSocketName), nil
+}
+
+func relay(conn net.Conn, peerAddr string) {
+	defer conn.Close()
+	peer, err := net.Dial("tcp", peerAddr)
+	if err != nil {
+		log.Debugf("chaincode process connect to peer %s error: %s", peerAddr, err)
+		return
+	}
+	defer peer.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(peer, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, peer)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+//从构建包(tar.gz)中取出binpackage.tar里的合约程序
+func unpackChaincode(reader io.Reader, dir string) error {
+	gr, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("failure opening chaincode package gzip stream: %s", err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in chaincode package", binPackageName)
+		}
+		if err != nil {
+			return err
+		}
+		if path.Clean(header.Name) != binPackageName {
+			continue
+		}
+		//docker导出的包中文件名是./chaincode
+		btr := tar.NewReader(tr)
+		for {
+			bheader, err := btr.Next()
+			if err == io.EOF {
+				return fmt.Errorf("%s not found in %s", ChaincodeBinName, binPackageName)
+			}
+			if err != nil {
+				return err
+			}
+			if path.Clean(bheader.Name) != ChaincodeBinName {
+				continue
+			}
+			bin, err := ioutil.ReadAll(btr)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(filepath.Join(dir, ChaincodeBinName), bin, 0700)
+		}
+	}
+}
+
+//把TLS等文件写到工作目录中，合约进程chroot到工作目录后路径与环境变量中的相同
+func uploadFiles(dir string, filesToUpload map[string][]byte) error {
+	for name, data := range filesToUpload {
+		local := filepath.Join(dir, filepath.FromSlash(strings.TrimLeft(name, "/")))
+		if !strings.HasPrefix(local, dir+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path %s", name)
+		}
+		if err := os.MkdirAll(filepath.Dir(local), 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(local, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Deploy 进程方式没有镜像，启动时从构建包中取出合约程序
+func (vm *ProcessVM) Deploy(ctxt context.Context, ccid ccintf.CCID, args []string, env []string, reader io.Reader) error {
+	return nil
+}
+
+//Start 编译合约并在工作目录中启动合约进程。args是docker容器中的启动命令，这里不使用
+func (vm *ProcessVM) Start(ctxt context.Context, ccid ccintf.CCID, args []string, env []string,
+	filesToUpload map[string][]byte, builder container.BuildSpecFactory, prelaunchFunc container.PrelaunchFunc) error {
+	instName, _ := vm.GetVMName(ccid, nil)
+	instLock.Lock()
+	_, running := instRegistry[instName]
+	instLock.Unlock()
+	if running {
+		return fmt.Errorf("chaincode running %s", instName)
+	}
+	if ccid.ChaincodeSpec.Type != pb.ChaincodeSpec_GOLANG && ccid.ChaincodeSpec.Type != pb.ChaincodeSpec_CAR {
+		return fmt.Errorf("process vm only supports golang chaincode: %s", instName)
+	}
+	if builder == nil {
+		return fmt.Errorf("no chaincode package of %s", instName)
+	}
+
+	dir := vm.workDir(ccid)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	reader, err := builder()
+	if err != nil {
+		return fmt.Errorf("failed to generate chaincode package of %s: %s", instName, err)
+	}
+	if err = unpackChaincode(reader, dir); err != nil {
+		return err
+	}
+	if err = uploadFiles(dir, filesToUpload); err != nil {
+		return err
+	}
+	if prelaunchFunc != nil {
+		if err = prelaunchFunc(); err != nil {
+			return err
+		}
+	}
+
+	lis, env, err := relayPeer(dir, env)
+	if err != nil {
+		return err
+	}
+	cfg := contractcfg.GetConfig()
+	cmd, err := sandboxCommand(dir, cfg, limitArgs(cfg, ccid.ChaincodeSpec))
+	if err != nil {
+		lis.Close()
+		return err
+	}
+	//不继承节点的环境变量
+	cmd.Env = append(env, "PATH=/usr/local/bin:/usr/bin:/bin", "HOME=/")
+	cmd.Stdout = &logWriter{name: instName}
+	cmd.Stderr = cmd.Stdout
+	if err = cmd.Start(); err != nil {
+		lis.Close()
+		return fmt.Errorf("start chaincode process %s error: %s", instName, err)
+	}
+
+	p := &chaincodeProcess{cmd: cmd, done: make(chan struct{})}
+	instLock.Lock()
+	instRegistry[instName] = p
+	instLock.Unlock()
+	go func() {
+		err := cmd.Wait()
+		lis.Close()
+		log.Infof("chaincode process %s exited: %v", instName, err)
+		instLock.Lock()
+		if instRegistry[instName] == p {
+			delete(instRegistry, instName)
+		}
+		instLock.Unlock()
+		close(p.done)
+	}()
+	log.Debugf("chaincode process %s started, pid %d", instName, cmd.Process.Pid)
+	return nil
+}
+
+//Stop 先通知合约进程退出，超时后强制结束
+func (vm *ProcessVM) Stop(ctxt context.Context, ccid ccintf.CCID, timeout uint, dontkill bool, dontremove bool) error {
+	instName, _ := vm.GetVMName(ccid, nil)
+	instLock.Lock()
+	p, ok := instRegistry[instName]
+	instLock.Unlock()
+	if ok {
+		if err := terminate(p.cmd, false); err != nil {
+			log.Debugf("terminate chaincode process %s: %s", instName, err)
+		}
+		select {
+		case <-p.done:
+		case <-time.After(time.Duration(timeout) * time.Second):
+			if !dontkill {
+				if err := terminate(p.cmd, true); err != nil {
+					log.Debugf("kill chaincode process %s: %s", instName, err)
+				}
+				<-p.done
+			}
+		}
+		log.Debugf("stopped chaincode process %s", instName)
+	} else {
+		log.Debugf("chaincode process %s not running", instName)
+	}
+	if !dontremove {
+		return os.RemoveAll(vm.workDir(ccid))
+	}
+	return nil
+}
+
+//Destroy 删除合约的工作目录
+func (vm *ProcessVM) Destroy(ctxt context.Context, ccid ccintf.CCID, force bool, noprune bool) error {
+	return os.RemoveAll(vm.workDir(ccid))
+}
+
+//GetVMName 名称同时用作工作目录名
+func (vm *ProcessVM) GetVMName(ccid ccintf.CCID, format func(string) (string, error)) (string, error) {
+	name := ccid.GetName()
+	if format != nil {
+		formattedName, err := format(name)
+		if err != nil {
+			return formattedName, err
+		}
+		name = formattedName
+	}
+	return vmRegExp.ReplaceAllString(name, "-"), nil
+}
+
+//把合约进程的输出按行写到节点日志中
+type logWriter struct {
+	name string
+	buf  bytes.Buffer
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			//不完整的行留到下次输出
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		log.Debugf("chaincode %s: %s", w.name, strings.TrimRight(line, "\n"))
+	}
+}
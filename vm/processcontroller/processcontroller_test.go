@@ -0,0 +1,214 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package processcontroller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/palletone/go-palletone/contracts/contractcfg"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+	"github.com/palletone/go-palletone/vm/ccintf"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+//与GenerateDockerBuild输出相同结构的构建包，合约程序用脚本代替
+func newBuilder(script string) func() (io.Reader, error) {
+	return func() (io.Reader, error) {
+		bin := bytes.NewBuffer(nil)
+		btw := tar.NewWriter(bin)
+		btw.WriteHeader(&tar.Header{Name: "./" + ChaincodeBinName, Mode: 0755, Size: int64(len(script))})
+		btw.Write([]byte(script))
+		btw.Close()
+
+		pkg := bytes.NewBuffer(nil)
+		gw := gzip.NewWriter(pkg)
+		tw := tar.NewWriter(gw)
+		tw.WriteHeader(&tar.Header{Name: "Dockerfile", Size: 0})
+		tw.WriteHeader(&tar.Header{Name: binPackageName, Size: int64(bin.Len())})
+		tw.Write(bin.Bytes())
+		tw.Close()
+		gw.Close()
+		return pkg, nil
+	}
+}
+
+func TestMain(m *testing.M) {
+	//测试程序也作为沙箱进程的入口
+	if reexec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestLimitArgs(t *testing.T) {
+	cfg := &contractcfg.Config{UccProcessMemory: 1 << 30, UccProcessCpuTime: 10, UccProcessMaxFiles: 64}
+	assert.Equal(t, []string{"core=0", "as=1073741824", "cpu=10", "nofile=64"}, limitArgs(cfg, nil))
+	//合约要求的内存更少时使用合约的限制
+	spec := &pb.ChaincodeSpec{Memory: 1 << 20}
+	assert.Contains(t, limitArgs(cfg, spec), "as=1048576")
+	assert.Equal(t, []string{"core=0"}, limitArgs(&contractcfg.Config{}, nil))
+}
+
+func TestUploadFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "processvm")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	err = uploadFiles(dir, map[string][]byte{"/etc/palletone/client.key": []byte("key")})
+	assert.Nil(t, err)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "etc", "palletone", "client.key"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("key"), data)
+
+	assert.NotNil(t, uploadFiles(dir, map[string][]byte{"../outside": nil}))
+}
+
+func TestRelayPeer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "processvm")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	peer, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer peer.Close()
+	go func() {
+		conn, err := peer.Accept()
+		if err == nil {
+			io.Copy(conn, conn)
+			conn.Close()
+		}
+	}()
+
+	_, _, err = relayPeer(dir, []string{"CORE_CHAINCODE_ID_NAME=sample:1.0"})
+	assert.NotNil(t, err)
+	lis, env, err := relayPeer(dir, []string{"CORE_CHAINCODE_ID_NAME=sample:1.0",
+		peerAddressEnv + "=" + peer.Addr().String()})
+	assert.Nil(t, err)
+	defer lis.Close()
+	assert.Equal(t, []string{"CORE_CHAINCODE_ID_NAME=sample:1.0", peerAddressEnv + "=unix:/peer.sock"}, env)
+
+	conn, err := net.Dial("unix", filepath.Join(dir, peerSocketName))
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestStartStop(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process vm is only supported on linux")
+	}
+	if os.Getuid() != 0 {
+		t.Skip("switching the chaincode user needs root")
+	}
+	dir, err := ioutil.TempDir("", "processvm")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	contractcfg.SetConfig(&contractcfg.Config{UccProcessDir: dir, UccProcessMaxFiles: 64,
+		UccProcessUid: 65534, UccProcessGid: 65534})
+	defer contractcfg.SetConfig(nil)
+
+	vm := NewProcessVM()
+	ccid := ccintf.CCID{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: "sample"},
+	}, Version: "1.0"}
+	//记录环境变量、rlimit、用户和文件系统的限制后一直运行
+	script := "#!/bin/sh\necho $CORE_CHAINCODE_ID_NAME $CORE_CHAINCODE_PEER_ADDRESS > env\n" +
+		"id -u > uid\ntest -S /peer.sock && echo socket > sock\n" +
+		"touch /usr/sandbox 2>/dev/null || echo readonly > rofs\nulimit -n > nofile\nexec sleep 60\n"
+	env := []string{"CORE_CHAINCODE_ID_NAME=sample:1.0", peerAddressEnv + "=127.0.0.1:21726"}
+	err = vm.Start(context.Background(), ccid, nil, env, nil, newBuilder(script), nil)
+	assert.Nil(t, err)
+
+	workDir := vm.workDir(ccid)
+	assert.True(t, strings.HasPrefix(workDir, dir))
+	var nofile []byte
+	for i := 0; i < 50; i++ {
+		if nofile, err = ioutil.ReadFile(filepath.Join(workDir, "nofile")); err == nil && len(nofile) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Equal(t, "64", strings.TrimSpace(string(nofile)))
+	readFile := func(name string) string {
+		data, _ := ioutil.ReadFile(filepath.Join(workDir, name))
+		return strings.TrimSpace(string(data))
+	}
+	assert.Equal(t, "sample:1.0 unix:/peer.sock", readFile("env"))
+	assert.Equal(t, "65534", readFile("uid"))
+	assert.Equal(t, "socket", readFile("sock"))
+	assert.Equal(t, "readonly", readFile("rofs"))
+	//合约进程不在节点的network namespace中
+	instLock.Lock()
+	pid := instRegistry["sample-1.0"].cmd.Process.Pid
+	instLock.Unlock()
+	selfNet, _ := os.Readlink("/proc/self/ns/net")
+	ccNet, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+	assert.Nil(t, err)
+	assert.NotEqual(t, selfNet, ccNet)
+	//合约进程没有任何能力，也不能通过exec获得
+	status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	assert.Nil(t, err)
+	for _, field := range []string{"CapInh", "CapPrm", "CapEff", "CapBnd", "CapAmb"} {
+		assert.Regexp(t, "(?m)^"+field+":\\s+0+$", string(status))
+	}
+	assert.Regexp(t, "(?m)^NoNewPrivs:\\s+1$", string(status))
+	//pivot_root后旧的根目录已经卸载，只能看到工作目录和挂载的系统目录
+	mountInfo, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/mountinfo", pid))
+	assert.Nil(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(string(mountInfo)), "\n") {
+		mountPoint := strings.Fields(line)[4]
+		assert.True(t, mountPoint == "/" || strings.HasPrefix(mountPoint, "/bin") ||
+			strings.HasPrefix(mountPoint, "/lib") || strings.HasPrefix(mountPoint, "/usr") ||
+			strings.HasPrefix(mountPoint, "/dev/"), mountPoint)
+	}
+
+	//重复启动
+	err = vm.Start(context.Background(), ccid, nil, nil, nil, newBuilder(script), nil)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, vm.Stop(context.Background(), ccid, 1, false, false))
+	instLock.Lock()
+	assert.Equal(t, 0, len(instRegistry))
+	instLock.Unlock()
+	_, err = os.Stat(workDir)
+	assert.True(t, os.IsNotExist(err))
+
+	//只支持golang合约
+	ccid.ChaincodeSpec.Type = pb.ChaincodeSpec_JAVA
+	err = vm.Start(context.Background(), ccid, nil, nil, nil, newBuilder(script), nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,290 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+package processcontroller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/palletone/go-palletone/contracts/contractcfg"
+)
+
+//重新执行节点程序进入沙箱，节点的main函数需要先调用reexec.Init
+const sandboxInitName = "palletone-chaincode-sandbox"
+
+//以只读方式挂载到合约根目录中的系统目录，动态链接的合约程序需要
+var systemDirs = []string{"/bin", "/lib", "/lib64", "/usr"}
+
+//挂载到合约根目录中的设备
+var systemDevices = []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom"}
+
+//旧的根目录在pivot_root后临时挂载的位置，随后卸载
+const oldRootName = ".oldroot"
+
+//prctl和capset的参数，syscall包中没有定义
+const (
+	prCapbsetRead        = 23
+	prCapbsetDrop        = 24
+	prSetSecurebits      = 28
+	prSetNoNewPrivs      = 38
+	prCapAmbient         = 47
+	prCapAmbientClearAll = 4
+	//SECBIT_NOROOT | SECBIT_NOROOT_LOCKED，uid为0的进程exec时也不获得能力
+	secbitNoRoot            = 0x3
+	linuxCapabilityVersion3 = 0x20080522
+)
+
+var rlimitResources = map[string]int{
+	"core":   syscall.RLIMIT_CORE,
+	"as":     syscall.RLIMIT_AS,
+	"cpu":    syscall.RLIMIT_CPU,
+	"nofile": syscall.RLIMIT_NOFILE,
+}
+
+func init() {
+	reexec.Register(sandboxInitName, sandboxInit)
+}
+
+//合约进程在新的mount、network、pid、ipc和uts namespace中运行，只能通过工作目录中的unix socket连接节点。
+//节点以root运行时合约进程使用配置的用户，否则在新的user namespace中以0号用户运行，两种情况下exec合约前都会去掉全部能力
+func sandboxCommand(dir string, cfg *contractcfg.Config, limits []string) (*exec.Cmd, error) {
+	uid, gid := cfg.UccProcessUid, cfg.UccProcessGid
+	attr := &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWNS | syscall.CLONE_NEWNET | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC |
+			syscall.CLONE_NEWUTS,
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+	if os.Getuid() == 0 {
+		//工作目录是合约进程的根目录，需要属于合约的用户
+		if err := chownDir(dir, int(uid), int(gid)); err != nil {
+			return nil, err
+		}
+	} else {
+		attr.Cloneflags |= syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+		attr.GidMappingsEnableSetgroups = false
+		uid, gid = 0, 0
+	}
+	args := append([]string{sandboxInitName, dir, strconv.FormatUint(uint64(uid), 10),
+		strconv.FormatUint(uint64(gid), 10)}, limits...)
+	cmd := reexec.Command(args...)
+	cmd.SysProcAttr = attr
+	cmd.Dir = dir
+	return cmd, nil
+}
+
+func chownDir(dir string, uid, gid int) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+//在沙箱进程中执行：设置rlimit，挂载系统目录，pivot_root到工作目录，切换用户并去掉全部能力后执行合约程序
+func sandboxInit() {
+	//切换用户的系统调用只对当前线程有效，exec之后整个进程使用该线程的用户
+	runtime.LockOSThread()
+	if err := enterSandbox(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "chaincode sandbox: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func enterSandbox(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("invalid sandbox arguments %v", args)
+	}
+	dir := args[0]
+	uid, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.ParseUint(args[2], 10, 32)
+	if err != nil {
+		return err
+	}
+	for _, limit := range args[3:] {
+		kv := strings.SplitN(limit, "=", 2)
+		resource, ok := rlimitResources[kv[0]]
+		if !ok || len(kv) != 2 {
+			return fmt.Errorf("invalid rlimit %s", limit)
+		}
+		value, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := syscall.Setrlimit(resource, &syscall.Rlimit{Cur: value, Max: value}); err != nil {
+			return fmt.Errorf("setrlimit %s: %s", kv[0], err)
+		}
+	}
+
+	//挂载只在合约进程的mount namespace中可见，pivot_root要求新的根目录是一个挂载点
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %s", err)
+	}
+	if err := syscall.Mount(dir, dir, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind %s: %s", dir, err)
+	}
+	for _, sys := range systemDirs {
+		if err := bindReadonly(dir, sys, true); err != nil {
+			return err
+		}
+	}
+	for _, dev := range systemDevices {
+		if err := bindReadonly(dir, dev, false); err != nil {
+			return err
+		}
+	}
+	if err := pivotRoot(dir); err != nil {
+		return err
+	}
+
+	//在切换用户前去掉能力边界集，之后任何exec都无法重新获得能力
+	if err := dropBoundingSet(); err != nil {
+		return err
+	}
+	if uint64(os.Getuid()) != uid || uint64(os.Getgid()) != gid {
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_SETGROUPS, 0, 0, 0); errno != 0 {
+			return fmt.Errorf("setgroups: %s", errno)
+		}
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_SETRESGID, uintptr(gid), uintptr(gid), uintptr(gid)); errno != 0 {
+			return fmt.Errorf("setgid: %s", errno)
+		}
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_SETRESUID, uintptr(uid), uintptr(uid), uintptr(uid)); errno != 0 {
+			return fmt.Errorf("setuid: %s", errno)
+		}
+	}
+	if err := clearCapabilities(); err != nil {
+		return err
+	}
+	return syscall.Exec("/"+ChaincodeBinName, []string{ChaincodeBinName}, os.Environ())
+}
+
+//把工作目录作为新的根目录，并卸载旧的根目录，合约进程中不再有任何路径可以访问节点的文件系统
+func pivotRoot(dir string) error {
+	oldRoot := filepath.Join(dir, oldRootName)
+	if err := os.MkdirAll(oldRoot, 0700); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(dir, oldRoot); err != nil {
+		return fmt.Errorf("pivot_root: %s", err)
+	}
+	if err := syscall.Chdir("/"); err != nil {
+		return err
+	}
+	if err := syscall.Unmount("/"+oldRootName, syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("unmount old root: %s", err)
+	}
+	return os.Remove("/" + oldRootName)
+}
+
+//清空能力边界集并锁定securebits，需要在CAP_SETPCAP还有效时调用
+func dropBoundingSet() error {
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetSecurebits, secbitNoRoot, 0); errno != 0 {
+		return fmt.Errorf("set securebits: %s", errno)
+	}
+	for capability := uintptr(0); ; capability++ {
+		//超过内核支持的最大能力时返回EINVAL
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prCapbsetRead, capability, 0); errno != 0 {
+			if errno == syscall.EINVAL {
+				return nil
+			}
+			return fmt.Errorf("read capability bounding set: %s", errno)
+		}
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prCapbsetDrop, capability, 0); errno != 0 {
+			return fmt.Errorf("drop capability %d: %s", capability, errno)
+		}
+	}
+}
+
+//去掉当前进程的全部能力，并禁止exec获得新的权限
+func clearCapabilities() error {
+	header := struct {
+		version uint32
+		pid     int32
+	}{version: linuxCapabilityVersion3}
+	data := [2]struct {
+		effective   uint32
+		permitted   uint32
+		inheritable uint32
+	}{}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPSET, uintptr(unsafe.Pointer(&header)),
+		uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return fmt.Errorf("capset: %s", errno)
+	}
+	//不支持ambient能力的内核返回EINVAL，此时也没有需要清除的ambient能力
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prCapAmbient, prCapAmbientClearAll, 0, 0, 0, 0)
+	if errno != 0 && errno != syscall.EINVAL {
+		return fmt.Errorf("clear ambient capabilities: %s", errno)
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("set no_new_privs: %s", errno)
+	}
+	return nil
+}
+
+//把系统中的目录或者设备以只读方式挂载到合约根目录的相同路径，不存在的跳过
+func bindReadonly(dir string, source string, isDir bool) error {
+	if _, err := os.Stat(source); err != nil {
+		return nil
+	}
+	target := filepath.Join(dir, source)
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY | syscall.MS_NOSUID)
+	if isDir {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return err
+		}
+		flags |= syscall.MS_NODEV
+	} else {
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, nil, 0644); err != nil {
+			return err
+		}
+	}
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("mount %s: %s", source, err)
+	}
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("remount %s readonly: %s", source, err)
+	}
+	return nil
+}
+
+//向整个进程组发送信号，合约创建的子进程也一起结束
+func terminate(cmd *exec.Cmd, kill bool) error {
+	sig := syscall.SIGTERM
+	if kill {
+		sig = syscall.SIGKILL
+	}
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
@@ -0,0 +1,256 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasmcontroller
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/go-interpreter/wagon/wasm/operators"
+	"github.com/palletone/go-palletone/common/log"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/palletone/go-palletone/dag/modules"
+)
+
+var errMemoryAccess = errors.New("wasm contract memory access out of bounds")
+
+//hostEnv 一次合约调用的宿主环境，把ChaincodeStubInterface的功能以函数的形式导入到合约中。
+//返回数据的函数把数据放在缓冲区中并返回长度，合约分配好内存后调用read_buffer取回，
+//没有数据或者出错时返回-1
+type hostEnv struct {
+	stub shim.ChaincodeStubInterface
+	//上一次返回的数据
+	buf []byte
+	//set_result设置的调用结果
+	result []byte
+	//set_error设置的错误信息
+	errMsg []byte
+	//第一个写操作的错误，有错误时调用失败
+	err error
+}
+
+type hostFunc struct {
+	name string
+	fn   interface{}
+}
+
+func newHostEnv(stub shim.ChaincodeStubInterface) *hostEnv {
+	return &hostEnv{stub: stub}
+}
+
+func (env *hostEnv) hostFuncs() []hostFunc {
+	return []hostFunc{
+		{"get_args_count", env.getArgsCount},
+		{"get_arg", env.getArg},
+		{"read_buffer", env.readBuffer},
+		{"get_state", env.getState},
+		{"put_state", env.putState},
+		{"del_state", env.delState},
+		{"get_state_by_prefix", env.getStateByPrefix},
+		{"get_tx_id", env.getTxId},
+		{"get_tx_timestamp", env.getTxTimestamp},
+		{"get_invoke_address", env.getInvokeAddress},
+		{"pay_out_token", env.payOutToken},
+		{"set_result", env.setResult},
+		{"set_error", env.setError},
+		{"log", env.log},
+	}
+}
+
+//把hostFuncs包装为合约可以导入的模块
+func (env *hostEnv) module() *wasm.Module {
+	m := wasm.NewModule()
+	m.Types = &wasm.SectionTypes{}
+	m.Export.Entries = make(map[string]wasm.ExportEntry)
+	for i, f := range env.hostFuncs() {
+		v := reflect.ValueOf(f.fn)
+		t := v.Type()
+		sig := wasm.FunctionSig{Form: wasm.TypeFunc}
+		//第一个参数是*instance
+		for j := 1; j < t.NumIn(); j++ {
+			sig.ParamTypes = append(sig.ParamTypes, valueType(t.In(j)))
+		}
+		for j := 0; j < t.NumOut(); j++ {
+			sig.ReturnTypes = append(sig.ReturnTypes, valueType(t.Out(j)))
+		}
+		m.Types.Entries = append(m.Types.Entries, sig)
+		m.FunctionIndexSpace = append(m.FunctionIndexSpace, wasm.Function{
+			Sig:  &sig,
+			Host: v,
+			Body: stubBody(sig),
+			Name: f.name,
+		})
+		m.Export.Entries[f.name] = wasm.ExportEntry{
+			FieldStr: f.name,
+			Kind:     wasm.ExternalFunction,
+			Index:    uint32(i),
+		}
+	}
+	return m
+}
+
+//导入函数在执行时调用Host，但是validate也会检查它的函数体，所以给一个返回零值的函数体
+func stubBody(sig wasm.FunctionSig) *wasm.FunctionBody {
+	code := []byte{}
+	for _, typ := range sig.ReturnTypes {
+		if typ == wasm.ValueTypeI64 {
+			code = append(code, operators.I64Const, 0)
+		} else {
+			code = append(code, operators.I32Const, 0)
+		}
+	}
+	return &wasm.FunctionBody{Code: code}
+}
+
+func valueType(t reflect.Type) wasm.ValueType {
+	if t.Kind() == reflect.Int64 {
+		return wasm.ValueTypeI64
+	}
+	return wasm.ValueTypeI32
+}
+
+//读取合约内存，越界时panic，由解释器转换为调用的错误
+func (env *hostEnv) read(in *instance, ptr, size int32) []byte {
+	if ptr < 0 || size < 0 || int64(ptr)+int64(size) > int64(len(in.mem)) {
+		panic(errMemoryAccess)
+	}
+	data := make([]byte, size)
+	copy(data, in.mem[ptr:])
+	return data
+}
+
+func (env *hostEnv) ret(data []byte) int32 {
+	env.buf = data
+	return int32(len(data))
+}
+
+func (env *hostEnv) fail(err error) int32 {
+	if env.err == nil {
+		env.err = err
+	}
+	return -1
+}
+
+func (env *hostEnv) getArgsCount(in *instance) int32 {
+	return int32(len(env.stub.GetArgs()))
+}
+
+func (env *hostEnv) getArg(in *instance, index int32) int32 {
+	args := env.stub.GetArgs()
+	if index < 0 || int(index) >= len(args) {
+		return -1
+	}
+	return env.ret(args[index])
+}
+
+func (env *hostEnv) readBuffer(in *instance, ptr int32) {
+	if ptr < 0 || int64(ptr)+int64(len(env.buf)) > int64(len(in.mem)) {
+		panic(errMemoryAccess)
+	}
+	copy(in.mem[ptr:], env.buf)
+}
+
+func (env *hostEnv) getState(in *instance, keyPtr, keyLen int32) int32 {
+	value, err := env.stub.GetState(string(env.read(in, keyPtr, keyLen)))
+	if err != nil || value == nil {
+		return -1
+	}
+	return env.ret(value)
+}
+
+func (env *hostEnv) putState(in *instance, keyPtr, keyLen, valuePtr, valueLen int32) int32 {
+	key := string(env.read(in, keyPtr, keyLen))
+	if err := env.stub.PutState(key, env.read(in, valuePtr, valueLen)); err != nil {
+		return env.fail(err)
+	}
+	return 0
+}
+
+func (env *hostEnv) delState(in *instance, keyPtr, keyLen int32) int32 {
+	if err := env.stub.DelState(string(env.read(in, keyPtr, keyLen))); err != nil {
+		return env.fail(err)
+	}
+	return 0
+}
+
+//结果是[]modules.KeyValue的json
+func (env *hostEnv) getStateByPrefix(in *instance, prefixPtr, prefixLen int32) int32 {
+	kvs, err := env.stub.GetStateByPrefix(string(env.read(in, prefixPtr, prefixLen)))
+	if err != nil {
+		return -1
+	}
+	if kvs == nil {
+		kvs = []*modules.KeyValue{}
+	}
+	data, err := json.Marshal(kvs)
+	if err != nil {
+		return -1
+	}
+	return env.ret(data)
+}
+
+func (env *hostEnv) getTxId(in *instance) int32 {
+	return env.ret([]byte(env.stub.GetTxID()))
+}
+
+//返回秒数
+func (env *hostEnv) getTxTimestamp(in *instance) int64 {
+	ts, err := env.stub.GetTxTimestamp(10)
+	if err != nil || ts == nil {
+		return -1
+	}
+	return ts.Seconds
+}
+
+func (env *hostEnv) getInvokeAddress(in *instance) int32 {
+	addr, err := env.stub.GetInvokeAddress()
+	if err != nil {
+		return -1
+	}
+	return env.ret([]byte(addr.String()))
+}
+
+func (env *hostEnv) payOutToken(in *instance, addrPtr, addrLen, assetPtr, assetLen int32, amount int64) int32 {
+	addr := string(env.read(in, addrPtr, addrLen))
+	asset, err := modules.StringToAsset(string(env.read(in, assetPtr, assetLen)))
+	if err != nil {
+		return env.fail(err)
+	}
+	if amount <= 0 {
+		return env.fail(errors.New("pay out amount must be positive"))
+	}
+	if err := env.stub.PayOutToken(addr, modules.NewAmountAsset(uint64(amount), asset), 0); err != nil {
+		return env.fail(err)
+	}
+	return 0
+}
+
+func (env *hostEnv) setResult(in *instance, ptr, size int32) {
+	env.result = env.read(in, ptr, size)
+}
+
+func (env *hostEnv) setError(in *instance, ptr, size int32) {
+	env.errMsg = env.read(in, ptr, size)
+}
+
+func (env *hostEnv) log(in *instance, ptr, size int32) {
+	log.Debugf("wasm contract[%s] log: %s", env.stub.GetTxID(), string(env.read(in, ptr, size)))
+}
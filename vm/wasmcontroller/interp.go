@@ -0,0 +1,790 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasmcontroller
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-interpreter/wagon/disasm"
+	"github.com/go-interpreter/wagon/wasm"
+	ops "github.com/go-interpreter/wagon/wasm/operators"
+)
+
+//解释执行WASM合约，只支持整数指令。每执行一条指令计1个单位，
+//累计的指令数在调用宿主函数前、每costFlushInterval条指令和执行结束时通过shim上报，
+//由TxSimulator按系统参数计入cost，各个陪审员执行同一请求得到的指令数相同
+const (
+	wasmPageSize = 65536
+
+	//合约函数的最大调用深度
+	maxCallDepth = 512
+	//每个函数的最大局部变量个数
+	maxFunctionLocals = 4096
+	//间接调用表的最大长度
+	maxTableSize = 65536
+
+	costFlushInterval = 10000
+)
+
+var (
+	errUnreachable        = errors.New("wasm contract reached unreachable instruction")
+	errCallStackExhausted = errors.New("wasm contract call stack exhausted")
+	errIntegerDivideZero  = errors.New("wasm contract integer divide by zero")
+	errIntegerOverflow    = errors.New("wasm contract integer overflow")
+	errUndefinedElement   = errors.New("wasm contract call undefined table element")
+	errIndirectCallType   = errors.New("wasm contract indirect call type mismatch")
+)
+
+//trap 终止合约的执行
+type trap struct {
+	err error
+}
+
+type instr struct {
+	op byte
+	//常量、局部/全局变量和函数的索引、分支深度、内存偏移或者块的返回值个数
+	imm uint64
+	//block、loop、if和else对应的end的位置
+	end int
+	//if对应的else的位置，没有else时为-1
+	els int
+	//br_table的分支深度，最后一个是默认分支
+	table []uint32
+}
+
+type function struct {
+	sig  *wasm.FunctionSig
+	host reflect.Value
+	//不包括参数的局部变量个数
+	locals int
+	code   []instr
+}
+
+type label struct {
+	arity  int
+	height int
+	cont   int
+	loop   bool
+}
+
+//instance 一次合约调用的WASM实例
+type instance struct {
+	env     *hostEnv
+	types   []wasm.FunctionSig
+	funcs   []function
+	globals []uint64
+	table   []int64
+	mem     []byte
+	stack   []uint64
+	depth   int
+
+	//还没有上报的指令数
+	pending uint64
+	//已执行的指令数
+	executed uint64
+	timedOut int32
+}
+
+func isFloatType(t wasm.ValueType) bool {
+	return t == wasm.ValueTypeF32 || t == wasm.ValueTypeF64
+}
+
+func checkSig(sig *wasm.FunctionSig) error {
+	for _, t := range append(append([]wasm.ValueType{}, sig.ParamTypes...), sig.ReturnTypes...) {
+		if isFloatType(t) {
+			return errors.New("wasm contract can not use float type")
+		}
+	}
+	return nil
+}
+
+//把函数体转换为解释器的指令，并找出每个块对应的else和end
+func compileFunc(fn *wasm.Function) (function, error) {
+	f := function{sig: fn.Sig}
+	if err := checkSig(fn.Sig); err != nil {
+		return f, err
+	}
+	if fn.IsHost() {
+		f.host = fn.Host
+		return f, nil
+	}
+	for _, local := range fn.Body.Locals {
+		if isFloatType(local.Type) {
+			return f, errors.New("wasm contract can not use float type")
+		}
+		f.locals += int(local.Count)
+		if f.locals > maxFunctionLocals {
+			return f, fmt.Errorf("wasm contract function has more than %d locals", maxFunctionLocals)
+		}
+	}
+	code, err := disasm.Disassemble(fn.Body.Code)
+	if err != nil {
+		return f, err
+	}
+	f.code = make([]instr, len(code))
+	var blocks []int
+	for i, ins := range code {
+		in := &f.code[i]
+		in.op = ins.Op.Code
+		in.els = -1
+		switch in.op {
+		case ops.Block, ops.Loop, ops.If:
+			if ins.Immediates[0].(wasm.BlockType) != wasm.BlockTypeEmpty {
+				in.imm = 1
+			}
+			blocks = append(blocks, i)
+		case ops.Else:
+			if len(blocks) == 0 || f.code[blocks[len(blocks)-1]].op != ops.If {
+				return f, errors.New("wasm contract has else without if")
+			}
+			f.code[blocks[len(blocks)-1]].els = i
+		case ops.End:
+			if len(blocks) == 0 {
+				return f, errors.New("wasm contract has unmatched end")
+			}
+			start := &f.code[blocks[len(blocks)-1]]
+			blocks = blocks[:len(blocks)-1]
+			start.end = i
+			if start.els >= 0 {
+				f.code[start.els].end = i
+			}
+		case ops.Br, ops.BrIf, ops.Call, ops.CallIndirect, ops.GetLocal, ops.SetLocal, ops.TeeLocal,
+			ops.GetGlobal, ops.SetGlobal:
+			in.imm = uint64(ins.Immediates[0].(uint32))
+		case ops.BrTable:
+			for _, v := range ins.Immediates[1:] {
+				in.table = append(in.table, v.(uint32))
+			}
+		case ops.I32Const:
+			in.imm = uint64(uint32(ins.Immediates[0].(int32)))
+		case ops.I64Const:
+			in.imm = uint64(ins.Immediates[0].(int64))
+		case ops.I32Load, ops.I64Load, ops.I32Load8s, ops.I32Load8u, ops.I32Load16s, ops.I32Load16u,
+			ops.I64Load8s, ops.I64Load8u, ops.I64Load16s, ops.I64Load16u, ops.I64Load32s, ops.I64Load32u,
+			ops.I32Store, ops.I64Store, ops.I32Store8, ops.I32Store16, ops.I64Store8, ops.I64Store16, ops.I64Store32:
+			in.imm = uint64(ins.Immediates[1].(uint32))
+		case ops.Unreachable, ops.Nop, ops.Return, ops.Drop, ops.Select, ops.CurrentMemory,
+			ops.I32Eqz, ops.I32Eq, ops.I32Ne, ops.I32LtS, ops.I32LtU, ops.I32GtS, ops.I32GtU,
+			ops.I32LeS, ops.I32LeU, ops.I32GeS, ops.I32GeU,
+			ops.I64Eqz, ops.I64Eq, ops.I64Ne, ops.I64LtS, ops.I64LtU, ops.I64GtS, ops.I64GtU,
+			ops.I64LeS, ops.I64LeU, ops.I64GeS, ops.I64GeU,
+			ops.I32Clz, ops.I32Ctz, ops.I32Popcnt, ops.I32Add, ops.I32Sub, ops.I32Mul, ops.I32DivS, ops.I32DivU,
+			ops.I32RemS, ops.I32RemU, ops.I32And, ops.I32Or, ops.I32Xor, ops.I32Shl, ops.I32ShrS, ops.I32ShrU,
+			ops.I32Rotl, ops.I32Rotr,
+			ops.I64Clz, ops.I64Ctz, ops.I64Popcnt, ops.I64Add, ops.I64Sub, ops.I64Mul, ops.I64DivS, ops.I64DivU,
+			ops.I64RemS, ops.I64RemU, ops.I64And, ops.I64Or, ops.I64Xor, ops.I64Shl, ops.I64ShrS, ops.I64ShrU,
+			ops.I64Rotl, ops.I64Rotr,
+			ops.I32WrapI64, ops.I64ExtendSI32, ops.I64ExtendUI32:
+		default:
+			//浮点运算在不同平台上的结果可能不一致
+			if strings.Contains(ins.Op.Name, "f32") || strings.Contains(ins.Op.Name, "f64") {
+				return f, fmt.Errorf("wasm contract can not use float instruction %s", ins.Op.Name)
+			}
+			return f, fmt.Errorf("wasm contract can not use instruction %s", ins.Op.Name)
+		}
+	}
+	if len(blocks) != 0 {
+		return f, errors.New("wasm contract has unterminated block")
+	}
+	return f, nil
+}
+
+func initExprInt32(m *wasm.Module, expr []byte) (int32, error) {
+	val, err := m.ExecInitExpr(expr)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := val.(int32)
+	if !ok {
+		return 0, errors.New("wasm contract offset must be i32")
+	}
+	return v, nil
+}
+
+//按模块创建实例，初始化内存、全局变量和间接调用表
+func newInstance(m *wasm.Module, env *hostEnv) (*instance, error) {
+	in := &instance{env: env}
+	if m.Types != nil {
+		in.types = m.Types.Entries
+	}
+	in.funcs = make([]function, len(m.FunctionIndexSpace))
+	for i := range m.FunctionIndexSpace {
+		f, err := compileFunc(&m.FunctionIndexSpace[i])
+		if err != nil {
+			return nil, err
+		}
+		in.funcs[i] = f
+	}
+
+	for _, global := range m.GlobalIndexSpace {
+		if isFloatType(global.Type.Type) {
+			return nil, errors.New("wasm contract can not use float type")
+		}
+		val, err := m.ExecInitExpr(global.Init)
+		if err != nil {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case int32:
+			in.globals = append(in.globals, uint64(uint32(v)))
+		case int64:
+			in.globals = append(in.globals, uint64(v))
+		default:
+			return nil, errors.New("wasm contract can not use float type")
+		}
+	}
+
+	if m.Table != nil && len(m.Table.Entries) > 0 {
+		size := m.Table.Entries[0].Limits.Initial
+		if size > maxTableSize {
+			return nil, fmt.Errorf("wasm contract table exceeds %d elements", maxTableSize)
+		}
+		in.table = make([]int64, size)
+		for i := range in.table {
+			in.table[i] = -1
+		}
+	}
+	if m.Elements != nil {
+		for _, elem := range m.Elements.Entries {
+			offset, err := initExprInt32(m, elem.Offset)
+			if err != nil {
+				return nil, err
+			}
+			if uint64(uint32(offset))+uint64(len(elem.Elems)) > uint64(len(in.table)) {
+				return nil, errors.New("wasm contract element segment out of bounds")
+			}
+			for i, index := range elem.Elems {
+				if int(index) >= len(in.funcs) {
+					return nil, errors.New("wasm contract element segment has invalid function")
+				}
+				in.table[int(uint32(offset))+i] = int64(index)
+			}
+		}
+	}
+
+	if m.Memory != nil && len(m.Memory.Entries) > 0 {
+		pages := m.Memory.Entries[0].Limits.Initial
+		if pages > maxMemoryPages {
+			return nil, fmt.Errorf("wasm contract memory exceeds %d pages", maxMemoryPages)
+		}
+		in.mem = make([]byte, int(pages)*wasmPageSize)
+	}
+	if m.Data != nil {
+		for _, data := range m.Data.Entries {
+			offset, err := initExprInt32(m, data.Offset)
+			if err != nil {
+				return nil, err
+			}
+			if uint64(uint32(offset))+uint64(len(data.Data)) > uint64(len(in.mem)) {
+				return nil, errors.New("wasm contract data segment out of bounds")
+			}
+			copy(in.mem[uint32(offset):], data.Data)
+		}
+	}
+	return in, nil
+}
+
+func (in *instance) trap(err error) {
+	panic(trap{err: err})
+}
+
+//执行函数，返回函数的结果
+func (in *instance) invoke(index int64, args ...uint64) (ret []uint64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case trap:
+				err = e.err
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	in.stack = append(in.stack[:0], args...)
+	in.depth = 0
+	in.call(uint32(index))
+	in.flush()
+	return in.stack, nil
+}
+
+//上报指令数，超过cost上限或者超时后终止执行
+func (in *instance) flush() {
+	if atomic.LoadInt32(&in.timedOut) == 1 {
+		in.trap(ErrExecutionTimeout)
+	}
+	if in.pending == 0 {
+		return
+	}
+	count := in.pending
+	in.pending = 0
+	in.executed += count
+	if err := in.env.stub.ChargeInstructions(count); err != nil {
+		in.trap(err)
+	}
+}
+
+func (in *instance) push(v uint64) {
+	in.stack = append(in.stack, v)
+}
+
+func (in *instance) pop() uint64 {
+	v := in.stack[len(in.stack)-1]
+	in.stack = in.stack[:len(in.stack)-1]
+	return v
+}
+
+func (in *instance) call(index uint32) {
+	fn := &in.funcs[index]
+	if fn.host.IsValid() {
+		in.callHost(fn)
+		return
+	}
+	in.depth++
+	if in.depth > maxCallDepth {
+		in.trap(errCallStackExhausted)
+	}
+	params := len(fn.sig.ParamTypes)
+	locals := make([]uint64, params+fn.locals)
+	copy(locals, in.stack[len(in.stack)-params:])
+	in.stack = in.stack[:len(in.stack)-params]
+	base := len(in.stack)
+	in.exec(fn, locals)
+
+	//return之后栈上可能还有多余的值
+	arity := len(fn.sig.ReturnTypes)
+	if len(in.stack)-base != arity {
+		copy(in.stack[base:], in.stack[len(in.stack)-arity:])
+		in.stack = in.stack[:base+arity]
+	}
+	in.depth--
+}
+
+//调用宿主函数前先上报指令数，宿主函数访问账本的cost由TxSimulator另外计算
+func (in *instance) callHost(fn *function) {
+	in.flush()
+	t := fn.host.Type()
+	params := len(fn.sig.ParamTypes)
+	args := make([]reflect.Value, params+1)
+	args[0] = reflect.ValueOf(in)
+	vals := in.stack[len(in.stack)-params:]
+	for i, v := range vals {
+		arg := reflect.New(t.In(i + 1)).Elem()
+		if arg.Kind() == reflect.Int64 {
+			arg.SetInt(int64(v))
+		} else {
+			arg.SetInt(int64(int32(uint32(v))))
+		}
+		args[i+1] = arg
+	}
+	in.stack = in.stack[:len(in.stack)-params]
+	for _, out := range fn.host.Call(args) {
+		if out.Kind() == reflect.Int64 {
+			in.push(uint64(out.Int()))
+		} else {
+			in.push(uint64(uint32(out.Int())))
+		}
+	}
+}
+
+func (in *instance) branch(labels []label, depth int, pc *int) ([]label, bool) {
+	if depth >= len(labels) {
+		return labels, false
+	}
+	l := labels[len(labels)-1-depth]
+	if l.arity > 0 {
+		v := in.stack[len(in.stack)-1]
+		in.stack = append(in.stack[:l.height], v)
+	} else {
+		in.stack = in.stack[:l.height]
+	}
+	*pc = l.cont
+	if l.loop {
+		return labels[:len(labels)-depth], true
+	}
+	return labels[:len(labels)-1-depth], true
+}
+
+func (in *instance) memIndex(offset uint64, size uint64) uint64 {
+	ea := uint64(uint32(in.pop())) + offset
+	if ea+size > uint64(len(in.mem)) {
+		in.trap(errMemoryAccess)
+	}
+	return ea
+}
+
+func (in *instance) load(offset, size uint64) []byte {
+	ea := in.memIndex(offset, size)
+	return in.mem[ea : ea+size]
+}
+
+func (in *instance) store(offset uint64, v uint64, size uint64) {
+	ea := in.memIndex(offset, size)
+	switch size {
+	case 1:
+		in.mem[ea] = byte(v)
+	case 2:
+		binary.LittleEndian.PutUint16(in.mem[ea:], uint16(v))
+	case 4:
+		binary.LittleEndian.PutUint32(in.mem[ea:], uint32(v))
+	default:
+		binary.LittleEndian.PutUint64(in.mem[ea:], v)
+	}
+}
+
+func b2u(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (in *instance) exec(fn *function, locals []uint64) {
+	code := fn.code
+	var labels []label
+	pc := 0
+	for pc < len(code) {
+		in.pending++
+		if in.pending >= costFlushInterval {
+			in.flush()
+		}
+		ins := &code[pc]
+		pc++
+		switch ins.op {
+		case ops.Unreachable:
+			in.trap(errUnreachable)
+		case ops.Nop:
+		case ops.Block:
+			labels = append(labels, label{arity: int(ins.imm), height: len(in.stack), cont: ins.end + 1})
+		case ops.Loop:
+			labels = append(labels, label{height: len(in.stack), cont: pc, loop: true})
+		case ops.If:
+			cond := uint32(in.pop())
+			if cond != 0 {
+				labels = append(labels, label{arity: int(ins.imm), height: len(in.stack), cont: ins.end + 1})
+			} else if ins.els >= 0 {
+				labels = append(labels, label{arity: int(ins.imm), height: len(in.stack), cont: ins.end + 1})
+				pc = ins.els + 1
+			} else {
+				pc = ins.end + 1
+			}
+		case ops.Else:
+			labels = labels[:len(labels)-1]
+			pc = ins.end + 1
+		case ops.End:
+			labels = labels[:len(labels)-1]
+		case ops.Br:
+			var ok bool
+			if labels, ok = in.branch(labels, int(ins.imm), &pc); !ok {
+				return
+			}
+		case ops.BrIf:
+			if uint32(in.pop()) != 0 {
+				var ok bool
+				if labels, ok = in.branch(labels, int(ins.imm), &pc); !ok {
+					return
+				}
+			}
+		case ops.BrTable:
+			i := uint64(uint32(in.pop()))
+			depth := ins.table[len(ins.table)-1]
+			if i < uint64(len(ins.table)-1) {
+				depth = ins.table[i]
+			}
+			var ok bool
+			if labels, ok = in.branch(labels, int(depth), &pc); !ok {
+				return
+			}
+		case ops.Return:
+			return
+		case ops.Call:
+			in.call(uint32(ins.imm))
+		case ops.CallIndirect:
+			i := uint64(uint32(in.pop()))
+			if i >= uint64(len(in.table)) || in.table[i] < 0 {
+				in.trap(errUndefinedElement)
+			}
+			index := uint32(in.table[i])
+			if ins.imm >= uint64(len(in.types)) || !sameSig(in.funcs[index].sig, &in.types[ins.imm]) {
+				in.trap(errIndirectCallType)
+			}
+			in.call(index)
+
+		case ops.Drop:
+			in.pop()
+		case ops.Select:
+			cond := uint32(in.pop())
+			v2 := in.pop()
+			if cond == 0 {
+				in.stack[len(in.stack)-1] = v2
+			}
+		case ops.GetLocal:
+			in.push(locals[ins.imm])
+		case ops.SetLocal:
+			locals[ins.imm] = in.pop()
+		case ops.TeeLocal:
+			locals[ins.imm] = in.stack[len(in.stack)-1]
+		case ops.GetGlobal:
+			in.push(in.globals[ins.imm])
+		case ops.SetGlobal:
+			in.globals[ins.imm] = in.pop()
+
+		case ops.I32Load:
+			in.push(uint64(binary.LittleEndian.Uint32(in.load(ins.imm, 4))))
+		case ops.I64Load:
+			in.push(binary.LittleEndian.Uint64(in.load(ins.imm, 8)))
+		case ops.I32Load8s:
+			in.push(uint64(uint32(int32(int8(in.load(ins.imm, 1)[0])))))
+		case ops.I32Load8u:
+			in.push(uint64(in.load(ins.imm, 1)[0]))
+		case ops.I32Load16s:
+			in.push(uint64(uint32(int32(int16(binary.LittleEndian.Uint16(in.load(ins.imm, 2)))))))
+		case ops.I32Load16u:
+			in.push(uint64(binary.LittleEndian.Uint16(in.load(ins.imm, 2))))
+		case ops.I64Load8s:
+			in.push(uint64(int64(int8(in.load(ins.imm, 1)[0]))))
+		case ops.I64Load8u:
+			in.push(uint64(in.load(ins.imm, 1)[0]))
+		case ops.I64Load16s:
+			in.push(uint64(int64(int16(binary.LittleEndian.Uint16(in.load(ins.imm, 2))))))
+		case ops.I64Load16u:
+			in.push(uint64(binary.LittleEndian.Uint16(in.load(ins.imm, 2))))
+		case ops.I64Load32s:
+			in.push(uint64(int64(int32(binary.LittleEndian.Uint32(in.load(ins.imm, 4))))))
+		case ops.I64Load32u:
+			in.push(uint64(binary.LittleEndian.Uint32(in.load(ins.imm, 4))))
+		case ops.I32Store, ops.I64Store32:
+			v := in.pop()
+			in.store(ins.imm, v, 4)
+		case ops.I64Store:
+			v := in.pop()
+			in.store(ins.imm, v, 8)
+		case ops.I32Store8, ops.I64Store8:
+			v := in.pop()
+			in.store(ins.imm, v, 1)
+		case ops.I32Store16, ops.I64Store16:
+			v := in.pop()
+			in.store(ins.imm, v, 2)
+		case ops.CurrentMemory:
+			in.push(uint64(len(in.mem) / wasmPageSize))
+
+		case ops.I32Const, ops.I64Const:
+			in.push(ins.imm)
+
+		case ops.I32Eqz:
+			in.push(b2u(uint32(in.pop()) == 0))
+		case ops.I64Eqz:
+			in.push(b2u(in.pop() == 0))
+		case ops.I32Clz:
+			in.push(uint64(bits.LeadingZeros32(uint32(in.pop()))))
+		case ops.I32Ctz:
+			in.push(uint64(bits.TrailingZeros32(uint32(in.pop()))))
+		case ops.I32Popcnt:
+			in.push(uint64(bits.OnesCount32(uint32(in.pop()))))
+		case ops.I64Clz:
+			in.push(uint64(bits.LeadingZeros64(in.pop())))
+		case ops.I64Ctz:
+			in.push(uint64(bits.TrailingZeros64(in.pop())))
+		case ops.I64Popcnt:
+			in.push(uint64(bits.OnesCount64(in.pop())))
+		case ops.I32WrapI64:
+			in.push(uint64(uint32(in.pop())))
+		case ops.I64ExtendSI32:
+			in.push(uint64(int64(int32(uint32(in.pop())))))
+		case ops.I64ExtendUI32:
+			in.push(uint64(uint32(in.pop())))
+
+		default:
+			b := in.pop()
+			a := in.pop()
+			in.push(binop(in, ins.op, a, b))
+		}
+	}
+}
+
+func sameSig(a, b *wasm.FunctionSig) bool {
+	if len(a.ParamTypes) != len(b.ParamTypes) || len(a.ReturnTypes) != len(b.ReturnTypes) {
+		return false
+	}
+	for i := range a.ParamTypes {
+		if a.ParamTypes[i] != b.ParamTypes[i] {
+			return false
+		}
+	}
+	for i := range a.ReturnTypes {
+		if a.ReturnTypes[i] != b.ReturnTypes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//二元的比较和算术指令
+func binop(in *instance, op byte, a, b uint64) uint64 {
+	x, y := uint32(a), uint32(b)
+	switch op {
+	case ops.I32Eq:
+		return b2u(x == y)
+	case ops.I32Ne:
+		return b2u(x != y)
+	case ops.I32LtS:
+		return b2u(int32(x) < int32(y))
+	case ops.I32LtU:
+		return b2u(x < y)
+	case ops.I32GtS:
+		return b2u(int32(x) > int32(y))
+	case ops.I32GtU:
+		return b2u(x > y)
+	case ops.I32LeS:
+		return b2u(int32(x) <= int32(y))
+	case ops.I32LeU:
+		return b2u(x <= y)
+	case ops.I32GeS:
+		return b2u(int32(x) >= int32(y))
+	case ops.I32GeU:
+		return b2u(x >= y)
+	case ops.I64Eq:
+		return b2u(a == b)
+	case ops.I64Ne:
+		return b2u(a != b)
+	case ops.I64LtS:
+		return b2u(int64(a) < int64(b))
+	case ops.I64LtU:
+		return b2u(a < b)
+	case ops.I64GtS:
+		return b2u(int64(a) > int64(b))
+	case ops.I64GtU:
+		return b2u(a > b)
+	case ops.I64LeS:
+		return b2u(int64(a) <= int64(b))
+	case ops.I64LeU:
+		return b2u(a <= b)
+	case ops.I64GeS:
+		return b2u(int64(a) >= int64(b))
+	case ops.I64GeU:
+		return b2u(a >= b)
+
+	case ops.I32Add:
+		return uint64(x + y)
+	case ops.I32Sub:
+		return uint64(x - y)
+	case ops.I32Mul:
+		return uint64(x * y)
+	case ops.I32DivS:
+		if y == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		if int32(x) == -1<<31 && int32(y) == -1 {
+			in.trap(errIntegerOverflow)
+		}
+		return uint64(uint32(int32(x) / int32(y)))
+	case ops.I32DivU:
+		if y == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		return uint64(x / y)
+	case ops.I32RemS:
+		if y == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		if int32(y) == -1 {
+			return 0
+		}
+		return uint64(uint32(int32(x) % int32(y)))
+	case ops.I32RemU:
+		if y == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		return uint64(x % y)
+	case ops.I32And:
+		return uint64(x & y)
+	case ops.I32Or:
+		return uint64(x | y)
+	case ops.I32Xor:
+		return uint64(x ^ y)
+	case ops.I32Shl:
+		return uint64(x << (y & 31))
+	case ops.I32ShrS:
+		return uint64(uint32(int32(x) >> (y & 31)))
+	case ops.I32ShrU:
+		return uint64(x >> (y & 31))
+	case ops.I32Rotl:
+		return uint64(bits.RotateLeft32(x, int(y&31)))
+	case ops.I32Rotr:
+		return uint64(bits.RotateLeft32(x, -int(y&31)))
+
+	case ops.I64Add:
+		return a + b
+	case ops.I64Sub:
+		return a - b
+	case ops.I64Mul:
+		return a * b
+	case ops.I64DivS:
+		if b == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		if int64(a) == -1<<63 && int64(b) == -1 {
+			in.trap(errIntegerOverflow)
+		}
+		return uint64(int64(a) / int64(b))
+	case ops.I64DivU:
+		if b == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		return a / b
+	case ops.I64RemS:
+		if b == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		if int64(b) == -1 {
+			return 0
+		}
+		return uint64(int64(a) % int64(b))
+	case ops.I64RemU:
+		if b == 0 {
+			in.trap(errIntegerDivideZero)
+		}
+		return a % b
+	case ops.I64And:
+		return a & b
+	case ops.I64Or:
+		return a | b
+	case ops.I64Xor:
+		return a ^ b
+	case ops.I64Shl:
+		return a << (b & 63)
+	case ops.I64ShrS:
+		return uint64(int64(a) >> (b & 63))
+	case ops.I64ShrU:
+		return a >> (b & 63)
+	case ops.I64Rotl:
+		return bits.RotateLeft64(a, int(b&63))
+	case ops.I64Rotr:
+		return bits.RotateLeft64(a, -int(b&63))
+	}
+	in.trap(fmt.Errorf("wasm contract can not use instruction %#x", op))
+	return 0
+}
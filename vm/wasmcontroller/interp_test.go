@@ -0,0 +1,72 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasmcontroller
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	env := newHostEnv(newMockStub(mockCtrl, nil, nil))
+	m, err := loadModule(readTestCode(t, "interp.wasm"), env)
+	assert.Nil(t, err)
+	in, err := newInstance(m, env)
+	assert.Nil(t, err)
+
+	call := func(name string, args ...uint64) ([]uint64, error) {
+		return in.invoke(int64(m.Export.Entries[name].Index), args...)
+	}
+	ret, err := call("fac", 20)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{2432902008176640000}, ret)
+	ret, _ = call("sum", 100)
+	assert.Equal(t, []uint64{5050}, ret)
+	for i, want := range []uint64{10, 20, 40, 40} {
+		ret, _ = call("switch", uint64(i))
+		assert.Equal(t, []uint64{want}, ret)
+	}
+	ret, _ = call("mem", 8, 0x0102030405060708)
+	assert.Equal(t, []uint64{0x0102030405060708}, ret)
+	assert.Equal(t, byte(0x08), in.mem[8])
+	ret, _ = call("div", uint64(uint32(0xfffffff6)), 3)
+	assert.Equal(t, []uint64{uint64(uint32(0xfffffffd))}, ret)
+	ret, _ = call("indirect", 1)
+	assert.Equal(t, []uint64{20}, ret)
+	ret, _ = call("incr")
+	assert.Equal(t, []uint64{6}, ret)
+	ret, _ = call("incr")
+	assert.Equal(t, []uint64{7}, ret)
+
+	//执行中的错误
+	_, err = call("div", 1, 0)
+	assert.Equal(t, errIntegerDivideZero, err)
+	_, err = call("div", 1<<31, uint64(uint32(0xffffffff)))
+	assert.Equal(t, errIntegerOverflow, err)
+	_, err = call("mem", 65536-4, 1)
+	assert.Equal(t, errMemoryAccess, err)
+	_, err = call("indirect", 2)
+	assert.Equal(t, errUndefinedElement, err)
+	_, err = call("recurse")
+	assert.Equal(t, errCallStackExhausted, err)
+}
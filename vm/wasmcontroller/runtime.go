@@ -0,0 +1,183 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasmcontroller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-interpreter/wagon/validate"
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/palletone/go-palletone/contracts/contractcfg"
+	"github.com/palletone/go-palletone/contracts/shim"
+	pb "github.com/palletone/go-palletone/core/vmContractPub/protos/peer"
+)
+
+//WASM合约的约定：导出无参数、返回i32的init和invoke函数，返回0表示成功，其他值表示失败；
+//只能从env模块导入hostFuncs中的函数，内存大小固定，不能使用memory.grow和浮点指令，也不能有start函数
+const (
+	hostModuleName = "env"
+	initFuncName   = "init"
+	invokeFuncName = "invoke"
+
+	//线性内存的上限，16M
+	maxMemoryPages = 256
+)
+
+var (
+	ErrExecutionTimeout = errors.New("wasm contract execution timeout")
+
+	//执行超时时间，为0时使用合约调用的超时配置
+	execTimeout time.Duration
+)
+
+//ValidateCode 检查WASM合约代码是否满足运行时的约定，在安装模板时调用
+func ValidateCode(code []byte) error {
+	m, err := loadModule(code, newHostEnv(nil))
+	if err != nil {
+		return err
+	}
+	if m.Start != nil {
+		return errors.New("wasm contract can not have start function")
+	}
+	if m.Memory == nil || len(m.Memory.Entries) != 1 {
+		return errors.New("wasm contract must have one linear memory")
+	}
+	if m.Memory.Entries[0].Limits.Initial > maxMemoryPages {
+		return fmt.Errorf("wasm contract memory exceeds %d pages", maxMemoryPages)
+	}
+	for _, name := range []string{initFuncName, invokeFuncName} {
+		if _, err := exportedFunc(m, name); err != nil {
+			return err
+		}
+	}
+	if err := validate.VerifyModule(m); err != nil {
+		return err
+	}
+	//检查解释器是否支持合约使用的指令，并初始化一次内存和间接调用表
+	_, err = newInstance(m, newHostEnv(nil))
+	return err
+}
+
+//解析合约代码，导入函数绑定到env，wagon对异常输入可能panic
+func loadModule(code []byte, env *hostEnv) (m *wasm.Module, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			m = nil
+			err = fmt.Errorf("invalid wasm contract: %v", r)
+		}
+	}()
+	m, err = wasm.ReadModule(bytes.NewReader(code), func(name string) (*wasm.Module, error) {
+		if name != hostModuleName {
+			return nil, fmt.Errorf("wasm contract can not import module %s", name)
+		}
+		return env.module(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid wasm contract: %s", err)
+	}
+	return m, nil
+}
+
+func exportedFunc(m *wasm.Module, name string) (int64, error) {
+	if m.Export == nil {
+		return 0, fmt.Errorf("wasm contract does not export %s", name)
+	}
+	entry, ok := m.Export.Entries[name]
+	if !ok || entry.Kind != wasm.ExternalFunction {
+		return 0, fmt.Errorf("wasm contract does not export %s", name)
+	}
+	fn := m.GetFunction(int(entry.Index))
+	if fn == nil || len(fn.Sig.ParamTypes) != 0 || len(fn.Sig.ReturnTypes) != 1 ||
+		fn.Sig.ReturnTypes[0] != wasm.ValueTypeI32 {
+		return 0, fmt.Errorf("wasm contract function %s must be () -> i32", name)
+	}
+	return int64(entry.Index), nil
+}
+
+//WasmChaincode 把WASM合约适配为shim.Chaincode，每次调用使用新的实例
+type WasmChaincode struct {
+	code []byte
+}
+
+func NewWasmChaincode(code []byte) (*WasmChaincode, error) {
+	if err := ValidateCode(code); err != nil {
+		return nil, err
+	}
+	return &WasmChaincode{code: code}, nil
+}
+
+func (cc *WasmChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return cc.run(stub, initFuncName)
+}
+
+func (cc *WasmChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	return cc.run(stub, invokeFuncName)
+}
+
+func (cc *WasmChaincode) run(stub shim.ChaincodeStubInterface, funcName string) pb.Response {
+	env := newHostEnv(stub)
+	m, err := loadModule(cc.code, env)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	index, err := exportedFunc(m, funcName)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	in, err := newInstance(m, env)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//超时后终止执行，防止宿主函数阻塞时一直占用节点；正常情况下先达到cost上限
+	timeout := execTimeout
+	if timeout == 0 {
+		timeout = contractcfg.GetConfig().ContractExecutetimeout
+	}
+	if timeout == 0 {
+		timeout = contractcfg.DefaultConfig.ContractExecutetimeout
+	}
+	timer := time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&in.timedOut, 1)
+	})
+	ret, err := in.invoke(index)
+	timer.Stop()
+
+	if err == ErrExecutionTimeout {
+		return shim.Error(ErrExecutionTimeout.Error())
+	}
+	if err != nil {
+		return shim.Error(fmt.Sprintf("wasm contract %s failed: %s", funcName, err))
+	}
+	//合约不能忽略写状态、转账等操作的错误
+	if env.err != nil {
+		return shim.Error(env.err.Error())
+	}
+	if code := uint32(ret[0]); code != 0 {
+		if env.errMsg != nil {
+			return shim.Error(string(env.errMsg))
+		}
+		return shim.Error(fmt.Sprintf("wasm contract %s returned %d", funcName, code))
+	}
+	return shim.Success(env.result)
+}
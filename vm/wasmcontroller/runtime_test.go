@@ -0,0 +1,170 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasmcontroller
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/palletone/go-palletone/contracts/shim"
+	"github.com/stretchr/testify/assert"
+)
+
+//testdata中的wasm由同名的wat编译得到
+func readTestCode(t *testing.T, name string) []byte {
+	code, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+//记录上报的指令数
+type mockStub struct {
+	*shim.MockChaincodeStubInterface
+	charged *uint64
+}
+
+func (stub *mockStub) ChargeInstructions(count uint64) error {
+	if stub.charged != nil {
+		*stub.charged += count
+	}
+	return nil
+}
+
+func newMockStub(mockCtrl *gomock.Controller, args [][]byte, db map[string][]byte) *mockStub {
+	stub := shim.NewMockChaincodeStubInterface(mockCtrl)
+	stub.EXPECT().GetArgs().Return(args).AnyTimes()
+	stub.EXPECT().PutState(gomock.Any(), gomock.Any()).DoAndReturn(func(key string, value []byte) error {
+		if key == "readonly" {
+			return errors.New("readonly")
+		}
+		db[key] = value
+		return nil
+	}).AnyTimes()
+	stub.EXPECT().GetState(gomock.Any()).DoAndReturn(func(key string) ([]byte, error) {
+		value, ok := db[key]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return value, nil
+	}).AnyTimes()
+	return &mockStub{MockChaincodeStubInterface: stub}
+}
+
+func TestWasmChaincode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	cc, err := NewWasmChaincode(readTestCode(t, "counter.wasm"))
+	assert.Nil(t, err)
+
+	db := make(map[string][]byte)
+	rsp := cc.Init(newMockStub(mockCtrl, nil, db))
+	assert.Equal(t, int32(shim.OK), rsp.Status)
+	assert.Equal(t, []byte("inited"), db["inited"])
+
+	rsp = cc.Invoke(newMockStub(mockCtrl, [][]byte{[]byte("a"), []byte("100")}, db))
+	assert.Equal(t, int32(shim.OK), rsp.Status)
+	assert.Equal(t, []byte("100"), rsp.Payload)
+	assert.Equal(t, []byte("100"), db["a"])
+
+	//合约设置的错误信息
+	rsp = cc.Invoke(newMockStub(mockCtrl, [][]byte{[]byte("a")}, db))
+	assert.Equal(t, int32(shim.ERROR), rsp.Status)
+	assert.Equal(t, "need key and value", rsp.Message)
+
+	//写状态失败时调用失败
+	rsp = cc.Invoke(newMockStub(mockCtrl, [][]byte{[]byte("readonly"), []byte("1")}, db))
+	assert.Equal(t, int32(shim.ERROR), rsp.Status)
+	assert.Equal(t, "readonly", rsp.Message)
+}
+
+func TestWasmChaincodeTimeout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	cc, err := NewWasmChaincode(readTestCode(t, "loop.wasm"))
+	assert.Nil(t, err)
+
+	execTimeout = 100 * time.Millisecond
+	defer func() { execTimeout = 0 }()
+	rsp := cc.Invoke(newMockStub(mockCtrl, nil, nil))
+	assert.Equal(t, int32(shim.ERROR), rsp.Status)
+	assert.Equal(t, ErrExecutionTimeout.Error(), rsp.Message)
+}
+
+func TestWasmChaincodeCostExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	cc, err := NewWasmChaincode(readTestCode(t, "loop.wasm"))
+	assert.Nil(t, err)
+
+	//死循环在达到cost上限时终止，与执行速度无关
+	errCost := errors.New("contract execution cost exceeded the limit")
+	charged := uint64(0)
+	stub := shim.NewMockChaincodeStubInterface(mockCtrl)
+	stub.EXPECT().ChargeInstructions(gomock.Any()).DoAndReturn(func(count uint64) error {
+		charged += count
+		if charged > 5*costFlushInterval {
+			return errCost
+		}
+		return nil
+	}).AnyTimes()
+	rsp := cc.Invoke(stub)
+	assert.Equal(t, int32(shim.ERROR), rsp.Status)
+	assert.Contains(t, rsp.Message, errCost.Error())
+	assert.Equal(t, uint64(6*costFlushInterval), charged)
+}
+
+func TestWasmChaincodeInstructionCount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	cc, err := NewWasmChaincode(readTestCode(t, "counter.wasm"))
+	assert.Nil(t, err)
+
+	//同一请求每次执行的指令数相同
+	counts := make([]uint64, 2)
+	for i := range counts {
+		stub := newMockStub(mockCtrl, [][]byte{[]byte("a"), []byte("100")}, make(map[string][]byte))
+		stub.charged = &counts[i]
+		rsp := cc.Invoke(stub)
+		assert.Equal(t, int32(shim.OK), rsp.Status)
+	}
+	assert.True(t, counts[0] > 0)
+	assert.Equal(t, counts[0], counts[1])
+}
+
+func TestValidateCode(t *testing.T) {
+	assert.Nil(t, ValidateCode(readTestCode(t, "counter.wasm")))
+	err := ValidateCode(readTestCode(t, "float.wasm"))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "float")
+	}
+	assert.NotNil(t, ValidateCode([]byte("not wasm")))
+
+	//缺少导出函数
+	code := readTestCode(t, "loop.wasm")
+	m, err := loadModule(code, newHostEnv(nil))
+	assert.Nil(t, err)
+	delete(m.Export.Entries, invokeFuncName)
+	_, err = exportedFunc(m, invokeFuncName)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,33 @@
+;; 把第一个参数作为key，第二个参数作为value保存，并返回读到的value
+(module
+  (import "env" "get_args_count" (func $get_args_count (result i32)))
+  (import "env" "get_arg" (func $get_arg (param i32) (result i32)))
+  (import "env" "read_buffer" (func $read_buffer (param i32)))
+  (import "env" "get_state" (func $get_state (param i32 i32) (result i32)))
+  (import "env" "put_state" (func $put_state (param i32 i32 i32 i32) (result i32)))
+  (import "env" "set_result" (func $set_result (param i32 i32)))
+  (import "env" "set_error" (func $set_error (param i32 i32)))
+  (memory 1)
+  (data (i32.const 0) "inited")
+  (data (i32.const 16) "need key and value")
+  (func $init (result i32)
+    (drop (call $put_state (i32.const 0) (i32.const 6) (i32.const 0) (i32.const 6)))
+    (i32.const 0))
+  (func $invoke (result i32) (local $klen i32) (local $vlen i32)
+    (if (i32.lt_u (call $get_args_count) (i32.const 2))
+      (then
+        (call $set_error (i32.const 16) (i32.const 18))
+        (return (i32.const 1))))
+    (local.set $klen (call $get_arg (i32.const 0)))
+    (call $read_buffer (i32.const 1024))
+    (local.set $vlen (call $get_arg (i32.const 1)))
+    (call $read_buffer (i32.const 2048))
+    (if (call $put_state (i32.const 1024) (local.get $klen) (i32.const 2048) (local.get $vlen))
+      (then (return (i32.const 2))))
+    (local.set $vlen (call $get_state (i32.const 1024) (local.get $klen)))
+    (call $read_buffer (i32.const 4096))
+    (call $set_result (i32.const 4096) (local.get $vlen))
+    (i32.const 0))
+  (export "memory" (memory 0))
+  (export "init" (func $init))
+  (export "invoke" (func $invoke)))
@@ -0,0 +1,8 @@
+;; 使用了浮点指令
+(module
+  (memory 1)
+  (func $init (result i32) (i32.const 0))
+  (func $invoke (result i32)
+    (i32.trunc_f32_s (f32.const 1)))
+  (export "init" (func $init))
+  (export "invoke" (func $invoke)))
@@ -0,0 +1,53 @@
+;; 解释器的测试，覆盖控制流、调用、内存和全局变量
+(module
+  (type $ret_i32 (func (result i32)))
+  (table 2 funcref)
+  (memory 1)
+  (global $g (mut i32) (i32.const 5))
+  (elem (i32.const 0) $ten $twenty)
+  (func $init (result i32) (i32.const 0))
+  (func $invoke (result i32) (i32.const 0))
+  (func $fac (param i64) (result i64)
+    (if (result i64) (i64.eqz (local.get 0))
+      (then (i64.const 1))
+      (else (i64.mul (local.get 0) (call $fac (i64.sub (local.get 0) (i64.const 1)))))))
+  (func $sum (param i32) (result i32) (local i32)
+    (block $done
+      (loop $l
+        (br_if $done (i32.eqz (local.get 0)))
+        (local.set 1 (i32.add (local.get 1) (local.get 0)))
+        (local.set 0 (i32.sub (local.get 0) (i32.const 1)))
+        (br $l)))
+    (local.get 1))
+  (func $switch (param i32) (result i32)
+    (block $d
+      (block $b
+        (block $a
+          (br_table $a $b $d (local.get 0)))
+        (return (i32.const 10)))
+      (return (i32.const 20)))
+    (i32.const 40))
+  (func $mem (param i32 i64) (result i64)
+    (i64.store (local.get 0) (local.get 1))
+    (i64.load (local.get 0)))
+  (func $div (param i32 i32) (result i32)
+    (i32.div_s (local.get 0) (local.get 1)))
+  (func $indirect (param i32) (result i32)
+    (call_indirect (type $ret_i32) (local.get 0)))
+  (func $ten (result i32) (i32.const 10))
+  (func $twenty (result i32) (i32.const 20))
+  (func $recurse (call $recurse))
+  (func $incr (result i32)
+    (global.set $g (i32.add (global.get $g) (i32.const 1)))
+    (global.get $g))
+  (export "init" (func $init))
+  (export "invoke" (func $invoke))
+  (export "fac" (func $fac))
+  (export "sum" (func $sum))
+  (export "switch" (func $switch))
+  (export "mem" (func $mem))
+  (export "div" (func $div))
+  (export "indirect" (func $indirect))
+  (export "recurse" (func $recurse))
+  (export "incr" (func $incr))
+  (export "memory" (memory 0)))
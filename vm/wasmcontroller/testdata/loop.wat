@@ -0,0 +1,9 @@
+;; invoke是死循环
+(module
+  (memory 1)
+  (func $init (result i32) (i32.const 0))
+  (func $invoke (result i32)
+    (loop $l (br $l))
+    (i32.const 0))
+  (export "init" (func $init))
+  (export "invoke" (func $invoke)))
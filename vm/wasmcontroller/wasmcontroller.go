@@ -0,0 +1,182 @@
+/*
+	This file is part of go-palletone.
+	go-palletone is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	go-palletone is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with go-palletone.  If not, see <http://www.gnu.org/licenses/>.
+*/
+/*
+ * @author PalletOne core developers <dev@pallet.one>
+ * @date 2018-2019
+ */
+
+package wasmcontroller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/palletone/go-palletone/common/log"
+	container "github.com/palletone/go-palletone/vm/api"
+	"github.com/palletone/go-palletone/vm/ccintf"
+	"github.com/palletone/go-palletone/vm/inproccontroller"
+	"golang.org/x/net/context"
+)
+
+//CodeFileName 构建包中合约代码的文件名
+const CodeFileName = "chaincode.wasm"
+
+type wasmContainer struct {
+	chaincode *WasmChaincode
+	stopChan  chan struct{}
+}
+
+var (
+	instLock     sync.Mutex
+	instRegistry = make(map[string]*wasmContainer)
+)
+
+//WasmVM 在节点进程中用内嵌的解释器运行WASM合约，不依赖docker
+type WasmVM struct {
+}
+
+func NewWasmVM() *WasmVM {
+	return &WasmVM{}
+}
+
+//从构建包(tar.gz)中读取合约代码
+func readCodePackage(reader io.Reader) ([]byte, error) {
+	gr, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failure opening wasm package gzip stream: %s", err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in wasm package", CodeFileName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == CodeFileName {
+			return ioutil.ReadAll(tr)
+		}
+	}
+}
+
+//Deploy 校验合约代码，WASM合约没有镜像，启动时才创建实例
+func (vm *WasmVM) Deploy(ctxt context.Context, ccid ccintf.CCID, args []string, env []string, reader io.Reader) error {
+	if reader == nil {
+		return nil
+	}
+	code, err := readCodePackage(reader)
+	if err != nil {
+		return err
+	}
+	return ValidateCode(code)
+}
+
+//Start 从构建包中加载合约，在当前进程中启动
+func (vm *WasmVM) Start(ctxt context.Context, ccid ccintf.CCID, args []string, env []string,
+	filesToUpload map[string][]byte, builder container.BuildSpecFactory, prelaunchFunc container.PrelaunchFunc) error {
+	instName, _ := vm.GetVMName(ccid, nil)
+	instLock.Lock()
+	_, running := instRegistry[instName]
+	instLock.Unlock()
+	if running {
+		return fmt.Errorf("chaincode running %s", instName)
+	}
+
+	if builder == nil {
+		return fmt.Errorf("no wasm package of %s", instName)
+	}
+	reader, err := builder()
+	if err != nil {
+		return fmt.Errorf("failed to generate wasm package of %s: %s", instName, err)
+	}
+	code, err := readCodePackage(reader)
+	if err != nil {
+		return err
+	}
+	cc, err := NewWasmChaincode(code)
+	if err != nil {
+		return err
+	}
+
+	ccSupport, ok := ctxt.Value(ccintf.GetCCHandlerKey()).(ccintf.CCSupport)
+	if !ok || ccSupport == nil {
+		return fmt.Errorf("in-process communication generator not supplied")
+	}
+	if prelaunchFunc != nil {
+		if err = prelaunchFunc(); err != nil {
+			return err
+		}
+	}
+
+	wc := &wasmContainer{chaincode: cc, stopChan: make(chan struct{})}
+	instLock.Lock()
+	instRegistry[instName] = wc
+	instLock.Unlock()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("caught panic from wasm chaincode %s: %v", instName, r)
+			}
+			instLock.Lock()
+			if instRegistry[instName] == wc {
+				delete(instRegistry, instName)
+			}
+			instLock.Unlock()
+		}()
+		err := inproccontroller.LaunchInProc(ctxt, instName, args, env, cc, ccSupport, wc.stopChan)
+		if err != nil {
+			log.Debugf("wasm chaincode %s quit: %s", instName, err)
+		}
+	}()
+	log.Debugf("wasm chaincode %s started", instName)
+	return nil
+}
+
+//Stop 停止合约实例
+func (vm *WasmVM) Stop(ctxt context.Context, ccid ccintf.CCID, timeout uint, dontkill bool, dontremove bool) error {
+	instName, _ := vm.GetVMName(ccid, nil)
+	instLock.Lock()
+	wc, ok := instRegistry[instName]
+	delete(instRegistry, instName)
+	instLock.Unlock()
+	if !ok {
+		return fmt.Errorf("%s not running", instName)
+	}
+	close(wc.stopChan)
+	return nil
+}
+
+//Destroy WASM合约没有镜像需要删除
+func (vm *WasmVM) Destroy(ctxt context.Context, ccid ccintf.CCID, force bool, noprune bool) error {
+	return nil
+}
+
+//GetVMName 与inproc一样只需要在进程内唯一
+func (vm *WasmVM) GetVMName(ccid ccintf.CCID, format func(string) (string, error)) (string, error) {
+	name := ccid.GetName()
+	if format != nil {
+		formattedName, err := format(name)
+		if err != nil {
+			return formattedName, err
+		}
+		name = formattedName
+	}
+	return name, nil
+}